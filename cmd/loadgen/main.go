@@ -0,0 +1,325 @@
+// Polecenie loadgen wgrywa do bazy duży, realistyczny zbiór danych testowych (domyślnie
+// 50 tys. książek, 10 tys. czytelników i 200 tys. wypożyczeń z ostatnich trzech lat),
+// żeby sprawdzić stronicowanie, pamięć podręczną i koszty zapytań w skali prawdziwej biblioteki.
+//
+// Dane trafiają do emulatora Firestore (FIRESTORE_EMULATOR_HOST) albo - z flagą -force -
+// do projektu wskazanego przez dane uwierzytelniające Firebase. Nigdy nie uruchamiaj
+// go na projekcie produkcyjnym: dane są dopisywane do istniejących kolekcji.
+//
+//	FIRESTORE_EMULATOR_HOST=localhost:8081 go run ./cmd/loadgen -books 50000 -users 10000 -loans 200000
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+)
+
+// batchSize to liczba dokumentów zapisywanych jednym wywołaniem (postęp jest logowany co partię)
+const batchSize = 5000
+
+func main() {
+	books := flag.Int("books", 50000, "liczba książek")
+	users := flag.Int("users", 10000, "liczba czytelników")
+	loans := flag.Int("loans", 200000, "liczba wypożyczeń")
+	tenantID := flag.String("tenant", "", "ID biblioteki sieci (puste = biblioteka główna)")
+	seed := flag.Int64("seed", 1, "ziarno generatora - ten sam seed daje te same dane")
+	force := flag.Bool("force", false, "zapis poza emulatorem Firestore (tylko projekt testowy!)")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("Brak pliku .env - używam zmiennych systemowych")
+	}
+
+	if os.Getenv("FIRESTORE_EMULATOR_HOST") == "" && !*force {
+		log.Fatal("Brak FIRESTORE_EMULATOR_HOST - dane testowe są zapisywane tylko do emulatora. " +
+			"Aby zapisać je w projekcie testowym, uruchom z flagą -force")
+	}
+
+	fbClient, err := firebase.InitFirebase()
+	if err != nil {
+		log.Fatalf("Błąd inicjalizacji Firebase: %v", err)
+	}
+	defer fbClient.Close()
+	if *tenantID != "" {
+		fbClient = fbClient.ForTenant(*tenantID)
+	}
+
+	settings, err := fbClient.GetSettings()
+	if err != nil {
+		log.Fatalf("Błąd odczytu ustawień biblioteki: %v", err)
+	}
+
+	g := &generator{rnd: rand.New(rand.NewSource(*seed)), now: time.Now(), settings: settings}
+	started := time.Now()
+
+	catalog := g.books(*books)
+	readers := g.users(*users)
+	history := g.loans(*loans, catalog, readers)
+
+	write("książek", catalog, fbClient.BulkCreateBooks)
+	write("czytelników", readers, fbClient.BulkCreateUsers)
+
+	// Wypożyczenia odwołują się do ID książek i czytelników, nadanych dopiero przy zapisie
+	for _, loan := range history {
+		loan.l.BookID = loan.book.ID
+		loan.l.UserID = loan.user.ID
+	}
+	write("wypożyczeń", loanDocs(history), fbClient.BulkCreateLoans)
+
+	log.Printf("Gotowe w %s: %d książek, %d czytelników, %d wypożyczeń", time.Since(started).Round(time.Second), len(catalog), len(readers), len(history))
+}
+
+// write zapisuje dokumenty partiami i loguje postęp
+func write[T any](label string, docs []T, create func([]T) error) {
+	for from := 0; from < len(docs); from += batchSize {
+		to := min(from+batchSize, len(docs))
+		if err := create(docs[from:to]); err != nil {
+			log.Fatalf("Błąd zapisu %s: %v", label, err)
+		}
+		log.Printf("Zapisano %d/%d %s", to, len(docs), label)
+	}
+}
+
+// generator tworzy dane z ustalonego ziarna
+type generator struct {
+	rnd      *rand.Rand
+	now      time.Time
+	settings *models.Settings
+}
+
+// category to dział katalogu z symbolem klasyfikacji i regałem
+type category struct {
+	name   string
+	symbol string
+	shelf  string
+}
+
+var categories = []category{
+	{"Powieść", "821.162.1-3", "A"},
+	{"Poezja", "821.162.1-1", "A"},
+	{"Powieść historyczna", "821.162.1-3", "B"},
+	{"Fantastyka", "821.162.1-3", "C"},
+	{"Kryminał", "821.162.1-3", "C"},
+	{"Literatura obca", "821.111-3", "D"},
+	{"Literatura dziecięca", "821.162.1-93", "F"},
+	{"Popularnonaukowe", "5", "E"},
+	{"Historia", "94", "G"},
+	{"Poradniki", "64", "H"},
+}
+
+var (
+	titleAdjectives = []string{"Ciche", "Zapomniane", "Ostatnie", "Długie", "Czerwone", "Nocne", "Stare", "Nowe", "Dalekie", "Złote", "Zimowe", "Wielkie"}
+	titleNouns      = []string{"lato", "miasto", "królestwo", "morze", "drzewo", "okno", "lustro", "niebo", "pole", "serce", "światło", "imię"}
+	titleSuffixes   = []string{"", "", "", " nad rzeką", " w górach", " o świcie", " i cień", " bez końca", " wśród gwiazd", " na krańcu świata"}
+	seriesNames     = []string{"Kroniki Północy", "Saga rodu Zawiszów", "Komisarz Wolski", "Akademia Magii", "Dzieje Mazowsza"}
+	publishers      = []string{"Ossolineum", "PIW", "Wydawnictwo Literackie", "Znak", "Rebis", "Zysk i S-ka", "Czarne", "Nasza Księgarnia", "Prószyński i S-ka", "Muza"}
+	firstNames      = []string{"Anna", "Maria", "Katarzyna", "Małgorzata", "Agnieszka", "Zofia", "Julia", "Jan", "Piotr", "Krzysztof", "Tomasz", "Paweł", "Michał", "Jakub", "Andrzej", "Ewa"}
+	lastNames       = []string{"Nowak", "Kowalski", "Wiśniewski", "Wójcik", "Kowalczyk", "Kamiński", "Lewandowski", "Zieliński", "Szymański", "Woźniak", "Dąbrowski", "Kozłowski", "Jankowski", "Mazur"}
+)
+
+// books tworzy katalog. Autorzy mają po kilka książek, część książek należy do serii,
+// a popularne tytuły mają więcej egzemplarzy.
+func (g *generator) books(n int) []*models.Book {
+	authors := make([]string, max(n/8, 1))
+	for i := range authors {
+		authors[i] = g.pick(firstNames) + " " + g.pick(lastNames)
+	}
+
+	books := make([]*models.Book, n)
+	for i := range books {
+		cat := categories[g.rnd.Intn(len(categories))]
+		author := authors[g.rnd.Intn(len(authors))]
+		title := g.pick(titleAdjectives) + " " + g.pick(titleNouns) + g.pick(titleSuffixes)
+		surname := []rune(author[strings.LastIndex(author, " ")+1:])
+		added := g.pastTime(5 * 365)
+		copies := 1 + g.rnd.Intn(3)
+		if g.rnd.Intn(10) == 0 {
+			copies += 3 + g.rnd.Intn(5)
+		}
+
+		book := &models.Book{
+			ISBN:            g.isbn(),
+			Title:           fmt.Sprintf("%s (%d)", title, i+1),
+			Author:          author,
+			Publisher:       g.pick(publishers),
+			PublicationYear: 1950 + g.rnd.Intn(g.now.Year()-1950+1),
+			Category:        cat.name,
+			Description:     fmt.Sprintf("%s - %s w opowieści autorstwa %s.", cat.name, strings.ToLower(title), author),
+			TotalCopies:     copies,
+			AvailableCopies: copies,
+			ShelfLocation:   fmt.Sprintf("%s-%02d", cat.shelf, 1+g.rnd.Intn(40)),
+			CallNumber:      cat.symbol + " " + string(surname[:min(3, len(surname))]),
+			CreatedAt:       added,
+			UpdatedAt:       added,
+		}
+		if g.rnd.Intn(20) == 0 {
+			book.Series = g.pick(seriesNames)
+			book.SeriesVolume = 1 + g.rnd.Intn(8)
+		}
+		books[i] = book
+	}
+	return books
+}
+
+// users tworzy czytelników zarejestrowanych w ciągu ostatnich pięciu lat
+func (g *generator) users(n int) []*models.User {
+	users := make([]*models.User, n)
+	for i := range users {
+		first, last := g.pick(firstNames), g.pick(lastNames)
+		registered := g.pastTime(5 * 365)
+		users[i] = &models.User{
+			Email:      fmt.Sprintf("%s.%s.%d@loadgen.example", asciiLower(first), asciiLower(last), i+1),
+			FirstName:  first,
+			LastName:   last,
+			Role:       models.RoleReader,
+			Phone:      fmt.Sprintf("+48 5%02d %03d %03d", g.rnd.Intn(100), g.rnd.Intn(1000), g.rnd.Intn(1000)),
+			IsActive:   true,
+			MaxLoans:   g.settings.MaxLoans,
+			CardNumber: fmt.Sprintf("9%011d", i+1),
+			CreatedAt:  registered,
+			UpdatedAt:  registered,
+		}
+	}
+	return users
+}
+
+// generatedLoan łączy wypożyczenie z książką i czytelnikiem przed nadaniem im ID
+type generatedLoan struct {
+	l    *models.Loan
+	book *models.Book
+	user *models.User
+}
+
+// loans tworzy historię wypożyczeń z ostatnich trzech lat. Popularność książek i aktywność
+// czytelników mają rozkład potęgowy (kilka procent tytułów to większość wypożyczeń).
+// Wypożyczenia z ostatniego okresu wypożyczenia są w części jeszcze aktywne - także
+// przeterminowane - a liczniki egzemplarzy i wypożyczeń czytelników są z nimi zgodne.
+func (g *generator) loans(n int, books []*models.Book, users []*models.User) []*generatedLoan {
+	if len(books) == 0 || len(users) == 0 {
+		return nil
+	}
+
+	loanPeriod := time.Duration(g.settings.LoanDays) * 24 * time.Hour
+	loans := make([]*generatedLoan, 0, n)
+	for len(loans) < n {
+		book := books[g.zipf(len(books))]
+		user := users[g.zipf(len(users))]
+		loanDate := g.pastTime(3 * 365)
+		if loanDate.Before(user.CreatedAt) {
+			loanDate = user.CreatedAt.Add(time.Duration(g.rnd.Int63n(int64(30 * 24 * time.Hour))))
+			if loanDate.After(g.now) {
+				continue
+			}
+		}
+		dueDate := loanDate.Add(loanPeriod)
+
+		loan := &models.Loan{
+			BookTitle: book.Title,
+			UserName:  user.FirstName + " " + user.LastName,
+			Status:    models.LoanStatusReturned,
+			LoanDate:  loanDate,
+			DueDate:   dueDate,
+			CreatedAt: loanDate,
+			UpdatedAt: loanDate,
+		}
+
+		// Zwrot: zwykle przed terminem, co dziesiąty po terminie (z karą 0,50 zł za dzień)
+		returned := loanDate.Add(time.Duration(g.rnd.Int63n(int64(loanPeriod))))
+		if g.rnd.Intn(10) == 0 {
+			late := 1 + g.rnd.Intn(30)
+			returned = dueDate.Add(time.Duration(late) * 24 * time.Hour)
+			loan.FineAmount = models.Money(50 * late)
+		}
+
+		if returned.After(g.now) {
+			// Wypożyczenie trwa - tylko jeśli są wolne egzemplarze i czytelnik nie wyczerpał limitu
+			if book.AvailableCopies == 0 || user.CurrentLoans >= user.MaxLoans {
+				continue
+			}
+			book.AvailableCopies--
+			user.CurrentLoans++
+			loan.Status = models.LoanStatusActive
+			loan.PickupCode = g.pickupCode()
+			loan.FineAmount = 0
+		} else {
+			loan.ReturnDate = &returned
+			loan.UpdatedAt = returned
+			if loan.FineAmount > 0 && g.rnd.Intn(4) == 0 {
+				user.TotalFines += loan.FineAmount // Część kar czeka na zapłatę
+			}
+		}
+
+		loans = append(loans, &generatedLoan{l: loan, book: book, user: user})
+	}
+
+	sort.Slice(loans, func(i, j int) bool {
+		return loans[i].l.LoanDate.Before(loans[j].l.LoanDate)
+	})
+	return loans
+}
+
+func loanDocs(loans []*generatedLoan) []*models.Loan {
+	docs := make([]*models.Loan, len(loans))
+	for i, loan := range loans {
+		docs[i] = loan.l
+	}
+	return docs
+}
+
+func (g *generator) pick(values []string) string {
+	return values[g.rnd.Intn(len(values))]
+}
+
+// pickupCode losuje kod odbioru w formacie z ustawień (z ziarna generatora, żeby dane były powtarzalne)
+func (g *generator) pickupCode() string {
+	format := g.settings.PickupCode()
+	charset := format.Charset()
+	code := make([]byte, format.Length)
+	for i := range code {
+		code[i] = charset[g.rnd.Intn(len(charset))]
+	}
+	return string(code)
+}
+
+// pastTime losuje chwilę z ostatnich days dni
+func (g *generator) pastTime(days int) time.Time {
+	return g.now.Add(-time.Duration(g.rnd.Int63n(int64(days) * int64(24*time.Hour))))
+}
+
+// zipf losuje indeks z przedziału [0, n) z rozkładem potęgowym (niskie indeksy częściej)
+func (g *generator) zipf(n int) int {
+	i := int(math.Pow(float64(n), g.rnd.Float64()*g.rnd.Float64()*1.2)) - 1
+	return min(max(i, 0), n-1)
+}
+
+// isbn losuje ISBN-13 z poprawną cyfrą kontrolną
+func (g *generator) isbn() string {
+	digits := fmt.Sprintf("97883%07d", g.rnd.Intn(10000000))
+	sum := 0
+	for i, d := range digits {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(d-'0') * weight
+	}
+	return fmt.Sprintf("%s%d", digits, (10-sum%10)%10)
+}
+
+// asciiLower zamienia polskie litery na ASCII do adresów email
+func asciiLower(s string) string {
+	return strings.NewReplacer("ą", "a", "ć", "c", "ę", "e", "ł", "l", "ń", "n", "ó", "o", "ś", "s", "ź", "z", "ż", "z",
+		"Ą", "a", "Ć", "c", "Ę", "e", "Ł", "l", "Ń", "n", "Ó", "o", "Ś", "s", "Ź", "z", "Ż", "z").Replace(strings.ToLower(s))
+}
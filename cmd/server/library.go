@@ -0,0 +1,565 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"library-management-system/internal/analytics"
+	"library-management-system/internal/api"
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/bots/telegram"
+	"library-management-system/internal/covers"
+	"library-management-system/internal/demo"
+	"library-management-system/internal/errorreport"
+	"library-management-system/internal/events"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/handlers"
+	"library-management-system/internal/lockers"
+	authmw "library-management-system/internal/middleware"
+	"library-management-system/internal/models"
+	"library-management-system/internal/moderation"
+	"library-management-system/internal/notifications"
+	"library-management-system/internal/printing"
+	"library-management-system/internal/repository"
+	"library-management-system/internal/search"
+	"library-management-system/internal/tenant"
+	"library-management-system/internal/tracing"
+	"library-management-system/internal/webpush"
+)
+
+// Limity rozmiaru treści żądań. Najdłuższe formularze (regulamin, szablony emaili, opisy
+// książek) mają kilkadziesiąt KB; import czytelników przyjmuje plik CSV do 2 MB.
+const (
+	formBodyLimit         = 1 << 20
+	readerImportBodyLimit = 2 << 20
+)
+
+// library to kompletna aplikacja jednej biblioteki. W sieci bibliotek każda z nich
+// ma własny router, indeks wyszukiwania i powiadomienia.
+type library struct {
+	router       chi.Router
+	searchIndex  *search.Index
+	catalogCache *authmw.CatalogCache
+}
+
+// newLibrary tworzy router biblioteki. Konsola sieci jest dostępna tylko
+// w bibliotece głównej (tenants != nil), integracja z paczkomatami tylko
+// przy ustawionym lockerCfg, powiadomienia push tylko przy ustawionym pushCfg,
+// a bot Telegrama (wspólny dla sieci) tylko gdy bot != nil. Błędy serwera trafiają
+// do reportera (Sentry / Error Reporting), gdy reporter != nil. Pamięć okładek
+// coverStore jest wspólna dla sieci.
+func newLibrary(fbClient *firebase.Client, baseURL string, staticHandler http.Handler, lockerCfg *lockers.Config, pushCfg *webpush.Config, bot *telegram.Bot, reporter *errorreport.Reporter, coverStore *covers.Store, tenants *tenant.Router) *library {
+	// Alerty zapisanych wyszukiwań (wymagają bazy danych)
+	var lockerService *lockers.Service
+	var dispatcher *notifications.Dispatcher
+	mailer := notifications.NewMailerFromEnv()
+	var pusher *webpush.Sender
+	if pushCfg != nil {
+		pusher = webpush.NewSender(pushCfg)
+	}
+	if fbClient != nil {
+		dispatcher = notifications.NewDispatcher(fbClient, mailer, pusher, bot, baseURL)
+		dispatcher.RegisterSavedSearchAlerts()
+		dispatcher.RegisterSubscriptionAlerts()
+		dispatcher.RegisterReservationAlerts()
+		dispatcher.RegisterDueSoonAlerts()
+		dispatcher.RegisterCommentMentions()
+		dispatcher.RegisterNewsletter()
+		dispatcher.RegisterCommunications()
+		log.Println("Powiadomienia zainicjalizowane")
+
+		if lockerCfg != nil {
+			lockerService = lockers.NewService(lockerCfg, fbClient, dispatcher)
+			lockerService.Register()
+		}
+
+		// Potwierdzenia na drukarce paragonów (adres drukarki w ustawieniach biblioteki)
+		printing.NewService(fbClient).Register()
+	}
+
+	// Inicjalizacja routera Chi
+	r := chi.NewRouter()
+
+	// Śledzenie żądań (OpenTelemetry) - span żądania jest rodzicem spanów operacji Firestore
+	r.Use(tracing.Middleware)
+
+	// Liczenie odczytów i zapisów Firestore na żądanie (raport najdroższych tras co noc)
+	r.Use(authmw.FirestoreCost)
+
+	// Middleware do logowania requestów
+	r.Use(middleware.RequestID)
+	r.Use(authmw.TrustedProxiesFromEnv().RealIP)
+	r.Use(middleware.Logger)
+	r.Use(authmw.Recoverer(reporter))
+	r.Use(middleware.Compress(5))
+
+	// Middleware sesji - dodaj sesję do kontekstu każdego żądania
+	r.Use(authmw.SessionMiddleware)
+
+	// Repozytoria katalogu, wypożyczeń, kont, rezerwacji i list lektur dla handlerów, które nie zależą
+	// od Firestore. Bez Firebase dane są w pamięci procesu (przykładowy katalog i konta demonstracyjne,
+	// zmiany giną po restarcie), a hasła sprawdza sam magazyn zamiast Firebase Authentication.
+	store := firebase.NewStore(fbClient)
+	var accounts repository.Accounts
+	if fbClient == nil {
+		memoryStore := demo.NewMemoryStore()
+		store, accounts = memoryStore, memoryStore
+	}
+
+	// Aktualne dane zalogowanego użytkownika dla szablonów (pobierane dopiero przy użyciu)
+	r.Use(handlers.NewViewerLoader(store, fbClient).Middleware)
+
+	// Limity rozmiaru treści żądań: formularze są małe, pliki CSV większe
+	r.Use(authmw.NewBodyLimits(formBodyLimit).
+		Set("/staff/users/import", readerImportBodyLimit).
+		Middleware)
+
+	// Powtórzone wysłanie formularza (np. podwójne kliknięcie) w ciągu kilku sekund
+	// nie wykonuje czynności drugi raz
+	r.Use(authmw.NewDuplicateGuard(3 * time.Second).Middleware)
+
+	// Kreator pierwszego uruchomienia - dopóki nie ma administratora, wszystkie strony prowadzą do /setup
+	var setupHandler *handlers.SetupHandler
+	if fbClient != nil {
+		setupHandler = handlers.NewSetupHandler(fbClient)
+		r.Use(setupHandler.Middleware)
+	}
+
+	// Serwowanie plików statycznych (CSS, JS)
+	// (wbudowane w plik wykonywalny, adresy z hashem treści przez funkcję szablonu "asset")
+	r.Handle("/static/*", staticHandler)
+
+	// ETagi publicznych stron katalogu - unieważniane przy każdej zmianie książek
+	catalogCache := authmw.NewCatalogCache()
+	events.Subscribe(events.BookChanged, func(e events.Event) {
+		if fbClient != nil && e.Tenant == fbClient.Tenant() {
+			catalogCache.Invalidate()
+		}
+	})
+
+	// Anonimowe statystyki przeglądania katalogu (zapisywane co minutę)
+	analyticsRecorder := analytics.NewRecorder(fbClient, time.Minute)
+	analyticsRecorder.Start()
+
+	// Indeks wyszukiwania jest unieważniany przez handlery zmieniające katalog i ogłoszenia
+	searchIndex := search.NewIndex(5*time.Minute, handlers.SearchIndexLoader(store, fbClient))
+
+	// Bot Telegrama wyszukuje w indeksie biblioteki, do której należy konto czytelnika
+	if bot != nil && fbClient != nil {
+		bot.AddLibrary(fbClient, searchIndex, baseURL)
+	}
+
+	// Inicjalizacja handlerów
+	indexHandler := handlers.NewIndexHandler(store, fbClient)
+	booksHandler := handlers.NewBooksHandler(store, fbClient, analyticsRecorder, searchIndex, baseURL)
+	authHandler := handlers.NewAuthHandler(fbClient, accounts)
+	staffHandler := handlers.NewStaffHandler(store, fbClient)
+	userHandler := handlers.NewUserHandler(store, fbClient)
+	pushHandler := handlers.NewPushHandler(fbClient, pushCfg)
+	telegramHandler := handlers.NewTelegramHandler(fbClient, bot)
+	notificationPrefsHandler := handlers.NewNotificationPrefsHandler(fbClient, pushCfg, bot)
+	calendarHandler := handlers.NewCalendarHandler(store, fbClient, baseURL)
+	catalogHandler := handlers.NewCatalogHandler(store, fbClient, searchIndex)
+	announcementsHandler := handlers.NewAnnouncementsHandler(fbClient, searchIndex)
+	searchHandler := handlers.NewSearchHandler(fbClient, searchIndex, analyticsRecorder)
+	suggestionsHandler := handlers.NewSuggestionsHandler(fbClient)
+	illHandler := handlers.NewILLHandler(fbClient, mailer)
+	emailTemplatesHandler := handlers.NewEmailTemplatesHandler(fbClient, mailer, baseURL)
+	notificationLogHandler := handlers.NewNotificationLogHandler(fbClient, dispatcher)
+	newsletterHandler := handlers.NewNewsletterHandler(fbClient, dispatcher)
+	regulationsHandler := handlers.NewRegulationsHandler(fbClient)
+	browseHandler := handlers.NewBrowseHandler(store, fbClient, searchIndex)
+	authorsHandler := handlers.NewAuthorsHandler(fbClient, searchIndex)
+	readingListsHandler := handlers.NewReadingListsHandler(store, fbClient, searchIndex, baseURL)
+	// Poza słowami z ustawień do moderacji trafiają komentarze z więcej niż dwoma linkami
+	commentsHandler := handlers.NewCommentsHandler(fbClient, moderation.MaxLinks(2))
+	badgesHandler := handlers.NewBadgesHandler(fbClient)
+	coversHandler := handlers.NewCoversHandler(fbClient, coverStore)
+	publicStatsHandler := handlers.NewPublicStatsHandler(fbClient)
+	offlineHandler := handlers.NewOfflineHandler(fbClient)
+	apiQuota := api.NewQuota(api.QuotaConfigFromEnv())
+	apiUsageHandler := handlers.NewAPIUsageHandler(fbClient, apiQuota)
+	selfCheckHandler := handlers.NewSelfCheckHandler(fbClient)
+	communicationsHandler := handlers.NewCommunicationsHandler(fbClient)
+	permalinkHandler := handlers.NewPermalinkHandler(store, fbClient, baseURL)
+	settingsHandler := handlers.NewSettingsHandler(fbClient)
+	cardHandler := handlers.NewCardHandler(fbClient, baseURL)
+	returnsHandler := handlers.NewReturnsHandler(store, fbClient)
+	weedingHandler := handlers.NewWeedingHandler(fbClient)
+	holdsReportHandler := handlers.NewHoldsReportHandler(fbClient)
+	annualReportHandler := handlers.NewAnnualReportHandler(fbClient)
+	staffActivityHandler := handlers.NewStaffActivityHandler(fbClient)
+	deskHandler := handlers.NewDeskHandler(fbClient)
+	deskHandler.Register()
+	finesHandler := handlers.NewFinesHandler(fbClient)
+	staffSearchHandler := handlers.NewStaffSearchHandler(fbClient, searchIndex)
+	auditHandler := handlers.NewAuditHandler(fbClient, searchIndex)
+
+	// Strona główna - publiczna
+	r.Get("/", indexHandler.ServeHTTP)
+
+	// Wyszukiwanie globalne
+	r.Get("/search", searchHandler.ShowResults)
+
+	// Propozycje zakupu książek (wysłanie wymaga logowania)
+	r.Get("/suggestions/new", suggestionsHandler.ShowForm)
+	r.With(authmw.RequireAuth, regulationsHandler.RequireAccepted).Post("/suggestions", suggestionsHandler.CreateSuggestion)
+
+	// Ogłoszenia - publiczne
+	r.Get("/announcements", announcementsHandler.ListPublished)
+	r.Get("/announcements/{id}", announcementsHandler.ShowAnnouncement)
+
+	// Listy lektur - publiczne
+	r.Get("/lists", readingListsHandler.ListPublished)
+	r.Get("/lists/{slug}", readingListsHandler.ShowList)
+	r.Get("/lists/shared/{token}", readingListsHandler.ShowSharedList)
+
+	// Regulamin - publiczny; akceptacja nowej wersji wymaga logowania
+	r.Get("/regulations", regulationsHandler.ShowRegulations)
+	r.With(authmw.RequireAuth).Get("/regulations/accept", regulationsHandler.ShowAccept)
+	r.With(authmw.RequireAuth).Post("/regulations/accept", regulationsHandler.Accept)
+
+	// Statystyki biblioteki - publiczne, z nocnego zrzutu
+	r.Get("/stats", publicStatsHandler.ShowStats)
+
+	// Aplikacja instalowana (PWA): manifest i strona offline ze zrzutem danych czytelnika.
+	// Service worker jest serwowany z katalogu głównego aplikacji, żeby obejmował wszystkie jej strony.
+	r.Get("/manifest.webmanifest", offlineHandler.Manifest)
+	r.Get("/sw.js", func(w http.ResponseWriter, r *http.Request) {
+		r = r.Clone(r.Context())
+		r.URL.Path = basepath.URL("/static/js/sw.js")
+		staticHandler.ServeHTTP(w, r)
+	})
+	r.Get("/offline", offlineHandler.ShowOfflinePage)
+	r.Get("/offline/snapshot.json", offlineHandler.Snapshot)
+
+	// Kalendarz iCal czytelnika - aplikacje kalendarza nie logują się, dostęp daje tajny token
+	r.Get("/calendar/{token}.ics", calendarHandler.Feed)
+
+	// Rezygnacja z newslettera linkiem z wiadomości - bez logowania, tajny token zamiast sesji
+	r.Get("/newsletter/unsubscribe/{token}", newsletterHandler.ShowUnsubscribe)
+	r.Post("/newsletter/unsubscribe/{token}", newsletterHandler.Unsubscribe)
+
+	// JSON API dla zewnętrznych integracji (klient: pkg/client)
+	r.Mount("/api/v1", api.NewHandler(fbClient, apiQuota, searchIndex).Routes())
+
+	// Zgłoszenia odbiorów z systemu paczkomatów (podpisane HMAC)
+	if lockerService != nil {
+		r.Post("/webhooks/lockers", lockerService.Webhook)
+	}
+
+	// Krótkie, stałe adresy książek (etykiety z kodami QR)
+	r.Get("/b/{code}", permalinkHandler.Redirect)
+	r.Get("/b/{code}/qr.png", permalinkHandler.QRCode)
+
+	// Pierwsze uruchomienie: ustawienia biblioteki i konto administratora
+	if setupHandler != nil {
+		r.Get("/setup", setupHandler.ShowSetup)
+		r.Post("/setup", setupHandler.CompleteSetup)
+	}
+
+	// Routy dla autoryzacji
+	r.Get("/login", authHandler.ShowLoginPage)
+	r.Post("/login", authHandler.HandleLogin)
+	r.Get("/register", authHandler.ShowRegisterPage)
+	r.Post("/register", authHandler.HandleRegister)
+	r.Post("/logout", authHandler.HandleLogout)
+
+	// Okładki książek pobrane z zewnętrznych adresów i zmniejszone (serwowane z własnej domeny)
+	r.Get("/covers/{id}", coversHandler.ServeCover)
+
+	// Grupy routów dla książek - publiczny katalog
+	r.Route("/books", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			// Szablony nie ustawiają Content-Type, a bez niego Compress nie kompresuje odpowiedzi
+			r.Use(middleware.SetHeader("Content-Type", "text/html; charset=utf-8"))
+			r.Use(catalogCache.Middleware)
+			r.Get("/", booksHandler.ListBooksHandler)
+			r.Get("/search", booksHandler.SearchBooksHandler)
+			r.Get("/authors/{letter}", browseHandler.ShowAuthors)
+			r.Get("/categories", browseHandler.ShowCategories)
+			r.Get("/categories/{slug}", browseHandler.ShowCategory)
+			r.Get("/series", browseHandler.ShowSeriesList)
+			r.Get("/series/{slug}", browseHandler.ShowSeries)
+			r.Get("/classification", browseHandler.ShowClassification)
+			r.Get("/classification/{symbol}", browseHandler.ShowClass)
+			r.Get("/{id}", booksHandler.ShowBookHandler)
+		})
+
+		// Udostępnianie - przekierowanie do serwisu i skopiowanie linku są liczone w statystykach
+		r.Get("/{id}/share/{channel}", booksHandler.ShareBook)
+		r.Post("/{id}/share/link", booksHandler.CopyBookLink)
+
+		// Wypożyczanie i rezerwacje (wymagają logowania i akceptacji regulaminu)
+		r.Group(func(r chi.Router) {
+			r.Use(authmw.RequireAuth)
+			r.Use(regulationsHandler.RequireAccepted)
+			r.Post("/{id}/borrow", booksHandler.BorrowBook)
+			r.Post("/{id}/reserve", booksHandler.ReserveBook)
+			r.Post("/{id}/comments", commentsHandler.AddComment)
+			r.Post("/{id}/comments/{commentID}/report", commentsHandler.ReportComment)
+			r.Post("/{id}/comments/{commentID}/vote", commentsHandler.VoteComment)
+		})
+	})
+
+	// Panel użytkownika (dla zalogowanych czytelników, którzy zaakceptowali regulamin)
+	r.Route("/user", func(r chi.Router) {
+		r.Use(authmw.RequireAuth)
+		r.Use(regulationsHandler.RequireAccepted)
+		r.Get("/", userHandler.ShowDashboard)
+		r.Get("/history", userHandler.ShowHistory)
+		r.Get("/reservations", userHandler.ShowReservations)
+		r.Post("/reservations/pause", userHandler.UpdateHoldPause)
+		r.Post("/reservations/{id}/borrow", userHandler.BorrowFromReservation)
+		r.Post("/reservations/{id}/cancel", userHandler.CancelReservation)
+		r.Get("/saved-searches", userHandler.ShowSavedSearches)
+		r.Post("/saved-searches", userHandler.CreateSavedSearch)
+		r.Post("/saved-searches/{id}/delete", userHandler.DeleteSavedSearch)
+		r.Get("/ill", illHandler.ShowUserRequests)
+		r.Post("/ill", illHandler.CreateUserRequest)
+		r.Get("/notifications", userHandler.ShowNotifications)
+		r.Get("/notifications/settings", notificationPrefsHandler.ShowPrefs)
+		r.Post("/notifications/settings", notificationPrefsHandler.UpdatePrefs)
+		r.Post("/subscriptions", userHandler.ToggleSubscription)
+		r.Get("/push", pushHandler.ShowDevices)
+		r.Post("/push", pushHandler.Subscribe)
+		r.Post("/push/settings", pushHandler.UpdateSettings)
+		r.Post("/push/{id}/delete", pushHandler.DeleteDevice)
+		r.Get("/telegram", telegramHandler.ShowTelegram)
+		r.Post("/telegram/link", telegramHandler.Link)
+		r.Post("/telegram/unlink", telegramHandler.Unlink)
+		r.Get("/calendar", calendarHandler.ShowCalendar)
+		r.Post("/calendar", calendarHandler.RenewToken)
+		r.Get("/card", cardHandler.ShowCard)
+		r.Get("/card/qr.png", cardHandler.QRCode)
+		r.Get("/pin", userHandler.ShowPIN)
+		r.Post("/pin", userHandler.UpdatePIN)
+		r.Get("/lists", readingListsHandler.ShowUserLists)
+		r.Post("/lists", readingListsHandler.CreateUserList)
+		r.Post("/lists/books", readingListsHandler.AddToUserList)
+		r.Get("/lists/{id}", readingListsHandler.EditUserList)
+		r.Post("/lists/{id}", readingListsHandler.UpdateUserList)
+		r.Post("/lists/{id}/books/{bookID}/delete", readingListsHandler.RemoveUserListBook)
+		r.Post("/lists/{id}/order", readingListsHandler.ReorderUserListBooks)
+		r.Post("/lists/{id}/share", readingListsHandler.RenewShareLink)
+		r.Post("/lists/{id}/delete", readingListsHandler.DeleteUserList)
+		r.Post("/badges", badgesHandler.UpdateOptOut)
+	})
+
+	// Panel personelu. Bibliotekarze obsługują wypożyczenia, odbiory i katalog;
+	// konta czytelników, kasa, raporty i ustawienia są tylko dla administratorów.
+	r.Route("/staff", func(r chi.Router) {
+		r.Use(authmw.RequireAuth)
+		r.Use(authmw.RequireAuthRole(models.RoleAdmin, models.RoleLibrarian))
+		r.Get("/", staffHandler.ShowDashboard)
+
+		// Panel wypożyczalni na dashboardzie (Server-Sent Events)
+		r.Get("/desk/stream", deskHandler.Stream)
+
+		// Szybkie wyszukiwanie (Ctrl+K): czytelnicy, książki, wypożyczenia i kody odbioru
+		r.Get("/search", staffSearchHandler.Search)
+
+		// Zarządzanie katalogiem
+		r.Get("/catalog", catalogHandler.ListBooks)
+		r.Get("/catalog/search", catalogHandler.SearchBooks)
+		r.Get("/catalog/new", catalogHandler.ShowNewBookForm)
+		r.Get("/catalog/labels", permalinkHandler.ShowLabelSheet)
+		r.Get("/catalog/labels.pdf", permalinkHandler.ShowLabelSheetPDF)
+		r.Post("/catalog", catalogHandler.CreateBook)
+		r.Get("/catalog/{id}/edit", catalogHandler.ShowEditBookForm)
+		r.Get("/catalog/{id}/label", permalinkHandler.ShowLabel)
+		r.Get("/catalog/{id}/history", catalogHandler.ShowBookHistory)
+		r.Put("/catalog/{id}", catalogHandler.UpdateBook)
+		r.Post("/catalog/{id}/withdraw", catalogHandler.WithdrawCopies)
+		r.Delete("/catalog/{id}", catalogHandler.DeleteBook)
+
+		// Hasła wzorcowe autorów
+		r.Get("/authors", authorsHandler.ListAuthors)
+		r.Post("/authors", authorsHandler.CreateAuthor)
+		r.Post("/authors/{id}", authorsHandler.UpdateAuthor)
+		r.Post("/authors/{id}/delete", authorsHandler.DeleteAuthor)
+
+		// Zarządzanie wypożyczeniami
+		r.Get("/loans", staffHandler.ShowLoans)
+		r.Post("/loans/{id}/return", staffHandler.ReturnLoan)
+		r.Post("/loans/{id}/remind", staffHandler.RemindLoan)
+
+		// Seryjne przyjmowanie zwrotów (wrzutnia)
+		r.Get("/returns", returnsHandler.ShowReturns)
+		r.Post("/returns", returnsHandler.ScanReturn)
+		r.Post("/returns/loans/{id}", returnsHandler.ReturnLoan)
+
+		// Potwierdzanie odbiorów
+		r.Get("/pending-pickups", staffHandler.ShowPendingPickups)
+		r.Get("/pending-pickups/lookup", staffHandler.LookupPickup)
+		r.Post("/loans/confirm-pickup", staffHandler.ConfirmPickup)
+
+		// Obsługa czytelnika przy ladzie: profil po zeskanowaniu karty, weryfikacja PIN-u
+		// i udostępnienia na miejscu. Zmiany konta, kasa i usuwanie są tylko dla administratorów.
+		r.Get("/card", cardHandler.OpenCard)
+		r.Get("/card/{number}", cardHandler.OpenCard)
+		r.Get("/users/{id}/edit", staffHandler.ShowEditUser)
+		r.Post("/users/{id}/verify-pin", staffHandler.VerifyUserPIN)
+		r.Post("/users/{id}/reading-room", staffHandler.IssueReadingRoomLoan)
+
+		// Listy lektur
+		r.Get("/lists", readingListsHandler.ListReadingLists)
+		r.Post("/lists", readingListsHandler.CreateReadingList)
+		r.Get("/lists/{id}", readingListsHandler.EditReadingList)
+		r.Post("/lists/{id}", readingListsHandler.UpdateReadingList)
+		r.Post("/lists/{id}/books", readingListsHandler.AddBook)
+		r.Post("/lists/{id}/books/{bookID}/delete", readingListsHandler.RemoveBook)
+		r.Post("/lists/{id}/order", readingListsHandler.ReorderBooks)
+		r.Post("/lists/{id}/delete", readingListsHandler.DeleteReadingList)
+
+		r.Get("/suggestions", suggestionsHandler.ListSuggestions)
+		r.Post("/suggestions/{id}/status", suggestionsHandler.UpdateStatus)
+		r.Post("/suggestions/books/{id}", suggestionsHandler.SuggestCopies)
+
+		// Zamówienia międzybiblioteczne
+		r.Get("/ill", illHandler.ListRequests)
+		r.Get("/ill/{id}", illHandler.ShowRequest)
+		r.Post("/ill/{id}", illHandler.UpdateRequest)
+		r.Post("/ill/{id}/email", illHandler.EmailPartner)
+		r.Post("/ill/{id}/letter", illHandler.RecordLetter)
+		r.Get("/ill/{id}/letter", illHandler.ShowLetter)
+		r.Post("/ill/{id}/messages", illHandler.RecordReply)
+		r.Get("/ill/partners", illHandler.ListPartners)
+		r.Post("/ill/partners", illHandler.CreatePartner)
+		r.Post("/ill/partners/{id}", illHandler.UpdatePartner)
+		r.Post("/ill/partners/{id}/delete", illHandler.DeletePartner)
+
+		// Tylko dla administratorów
+		r.Group(func(r chi.Router) {
+			r.Use(authmw.RequireAuthRole(models.RoleAdmin))
+
+			r.With(demo.Guard).Post("/loans/{id}/delete", staffHandler.TrashLoan)
+
+			// Zarządzanie użytkownikami
+			r.Get("/users", staffHandler.ShowUsers)
+			r.Get("/users/search", staffHandler.SearchUsers)
+			r.Get("/users/consents.csv", staffHandler.ExportConsents)
+			r.Get("/users/ils-export.zip", staffHandler.ExportILS)
+			r.Get("/users/sync", staffHandler.ShowUserSync)
+			r.Get("/users/import", staffHandler.ShowReaderImport)
+			r.With(demo.Guard).Post("/users/import", staffHandler.ImportReaders)
+			r.Get("/trash", staffHandler.ShowTrash)
+			r.With(demo.Guard).Post("/trash/{id}/restore", staffHandler.RestoreTrashItem)
+			r.With(demo.Guard).Post("/trash/{id}/purge", staffHandler.PurgeTrashItem)
+			r.Get("/audit-log", auditHandler.ShowAuditLog)
+			r.With(demo.Guard).Post("/audit-log/{id}/undo", auditHandler.UndoAuditEntry)
+			r.Get("/pending-users", staffHandler.ShowPendingUsers)
+			r.Post("/pending-users/{id}/approve", staffHandler.ApproveUser)
+			r.Post("/pending-users/{id}/reject", staffHandler.RejectUser)
+			r.With(demo.Guard).Post("/users/sync/profiles", staffHandler.ReconcileProfiles)
+			r.With(demo.Guard).Post("/users/sync/profiles/{id}", staffHandler.DeactivateProfile)
+			r.With(demo.Guard).Post("/users/sync/accounts/{uid}/delete", staffHandler.DeleteAuthAccount)
+			r.With(demo.Guard).Post("/users/{id}/update", staffHandler.UpdateUser)
+			r.With(demo.Guard).Post("/users/{id}/delete", staffHandler.TrashUser)
+			r.With(demo.Guard).Post("/users/{id}/password-reset", staffHandler.SendPasswordReset)
+			r.Post("/users/{id}/fine-payments", staffHandler.RecordFinePayment)
+
+			// Wpłaty kar przy ladzie, raport kasowy i umorzenia zbiorcze
+			r.Get("/fine-payments", finesHandler.ShowCashReport)
+			r.Get("/fine-payments/{id}/receipt", finesHandler.ShowReceipt)
+			r.Get("/fine-amnesty", finesHandler.ShowAmnesty)
+			r.With(demo.Guard).Post("/fine-amnesty", finesHandler.WaiveFines)
+
+			// Raporty
+			r.Get("/reports", staffHandler.ShowReports)
+			r.Get("/reports/weeding", weedingHandler.ShowReport)
+			r.Get("/reports/holds", holdsReportHandler.ShowReport)
+			r.Get("/reports/annual", annualReportHandler.ShowReport)
+			r.Get("/reports/staff-activity", staffActivityHandler.ShowReport)
+
+			// Ogłoszenia
+			r.Get("/announcements", announcementsHandler.ListAnnouncements)
+			r.Post("/announcements", announcementsHandler.CreateAnnouncement)
+			r.Post("/announcements/{id}", announcementsHandler.UpdateAnnouncement)
+			r.Post("/announcements/{id}/toggle", announcementsHandler.TogglePublished)
+			r.Delete("/announcements/{id}", announcementsHandler.DeleteAnnouncement)
+
+			// Moderacja komentarzy
+			r.Get("/comments", commentsHandler.ShowQueue)
+			r.Post("/comments/{id}/approve", commentsHandler.ApproveComment)
+			r.Post("/comments/{id}/hide", commentsHandler.HideComment)
+			r.Post("/comments/{id}/ban", commentsHandler.BanAuthor)
+			r.Post("/comments/bans/{userID}/delete", commentsHandler.UnbanCommenter)
+
+			// Katalog odznak
+			r.Get("/badges", badgesHandler.ShowBadges)
+			r.Post("/badges", badgesHandler.CreateBadge)
+			r.Post("/badges/defaults", badgesHandler.AddDefaultBadges)
+			r.Post("/badges/{id}", badgesHandler.UpdateBadge)
+			r.Post("/badges/{id}/delete", badgesHandler.DeleteBadge)
+
+			// Szablony wiadomości email do czytelników
+			r.Get("/templates", emailTemplatesHandler.ListTemplates)
+			r.Get("/templates/{key}", emailTemplatesHandler.ShowTemplate)
+			r.Post("/templates/{key}", emailTemplatesHandler.SaveTemplate)
+			r.Post("/templates/{key}/preview", emailTemplatesHandler.PreviewTemplate)
+			r.With(demo.Guard).Post("/templates/{key}/test", emailTemplatesHandler.SendTest)
+			r.Post("/templates/{key}/reset", emailTemplatesHandler.ResetTemplate)
+
+			// Dziennik wysyłek powiadomień
+			r.Get("/notifications", notificationLogHandler.ShowLog)
+			r.Post("/notifications/{id}/resend", notificationLogHandler.Resend)
+
+			// Podgląd comiesięcznego newslettera
+			r.Get("/newsletter", newsletterHandler.ShowNewsletter)
+
+			// Wiadomości personelu do grup czytelników
+			r.Get("/communications", communicationsHandler.ShowCommunications)
+			r.With(demo.Guard).Post("/communications", communicationsHandler.SendCommunication)
+
+			// Regulamin i jego wersje
+			r.Get("/regulations", regulationsHandler.ShowEditor)
+			r.Post("/regulations", regulationsHandler.Publish)
+
+			// Zużycie limitów JSON API
+			r.Get("/api-usage", apiUsageHandler.ShowUsage)
+
+			// Stanowiska samoobsługowe (tokeny API /api/v1/selfcheck)
+			r.Get("/selfcheck", selfCheckHandler.ListStations)
+			r.With(demo.Guard).Post("/selfcheck", selfCheckHandler.CreateStation)
+			r.With(demo.Guard).Post("/selfcheck/{id}/delete", selfCheckHandler.DeleteStation)
+
+			// Ustawienia biblioteki
+			r.Get("/settings", settingsHandler.ShowSettings)
+			r.With(demo.Guard).Post("/settings", settingsHandler.UpdateSettings)
+
+			// Konsola sieci bibliotek (tylko administratorzy biblioteki głównej)
+			if tenants != nil {
+				tenantsHandler := handlers.NewTenantsHandler(fbClient, tenants)
+				r.Get("/tenants", tenantsHandler.ListTenants)
+				r.With(demo.Guard).Post("/tenants", tenantsHandler.SaveTenant)
+			}
+		})
+	})
+
+	return &library{
+		router:       r,
+		searchIndex:  searchIndex,
+		catalogCache: catalogCache,
+	}
+}
+
+// tenantBaseURL zwraca publiczny adres biblioteki sieci - pierwszą z jej domen
+// z tym samym schematem, portem i prefiksem co adres biblioteki głównej
+func tenantBaseURL(baseURL string, t *models.Tenant) string {
+	u, err := url.Parse(baseURL)
+	if err != nil || len(t.Hostnames) == 0 {
+		return baseURL
+	}
+	host := t.Hostnames[0]
+	if port := u.Port(); port != "" {
+		host += ":" + port
+	}
+	u.Host = host
+	return u.String()
+}
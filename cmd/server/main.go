@@ -1,19 +1,36 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
 	"github.com/joho/godotenv"
 
+	"library-management-system/internal/assets"
+	"library-management-system/internal/badges"
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/bots/telegram"
+	"library-management-system/internal/covers"
+	"library-management-system/internal/demo"
+	"library-management-system/internal/errorreport"
+	"library-management-system/internal/events"
 	"library-management-system/internal/firebase"
-	"library-management-system/internal/handlers"
+	"library-management-system/internal/jobs"
+	"library-management-system/internal/lockers"
 	authmw "library-management-system/internal/middleware"
 	"library-management-system/internal/models"
+	"library-management-system/internal/publicstats"
 	"library-management-system/internal/session"
+	"library-management-system/internal/sip2"
+	"library-management-system/internal/tenant"
+	"library-management-system/internal/tracing"
+	"library-management-system/internal/webpush"
+	"library-management-system/static"
 )
 
 func main() {
@@ -32,7 +49,7 @@ func main() {
 	fbClient, err := firebase.InitFirebase()
 	if err != nil {
 		log.Printf("UWAGA: Firebase nie został zainicjalizowany: %v", err)
-		log.Println("Aplikacja będzie działać w trybie bez bazy danych")
+		log.Printf("Aplikacja będzie działać w trybie bez bazy danych: dane w pamięci, konta %s i %s z hasłem %s", demo.AdminEmail, demo.ReaderEmail, demo.Password)
 	} else {
 		log.Println("Firebase zainicjalizowany pomyślnie")
 	}
@@ -41,100 +58,388 @@ func main() {
 	session.Init()
 	log.Println("System sesji zainicjalizowany")
 
-	// Inicjalizacja routera Chi
-	r := chi.NewRouter()
+	// Wbudowany TLS (Let's Encrypt) - opcjonalny, bez niego serwer działa po HTTP
+	tlsCfg := tlsConfigFromEnv()
+	if tlsCfg != nil {
+		session.SetSecureCookies(true)
+	}
 
-	// Middleware do logowania requestów
-	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
-	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
+	// Prefiks URL przy wdrożeniu za reverse proxy (np. /biblioteka)
+	basepath.Set(os.Getenv("BASE_PATH"))
 
-	// Middleware sesji - dodaj sesję do kontekstu każdego żądania
-	r.Use(authmw.SessionMiddleware)
+	// Publiczny adres aplikacji - używany w linkach wysyłanych emailem
+	baseURL := os.Getenv("BASE_URL")
+	if baseURL == "" {
+		if tlsCfg != nil {
+			baseURL = "https://" + tlsCfg.Domains[0] + basepath.Prefix()
+		} else {
+			baseURL = "http://localhost:" + port + basepath.Prefix()
+		}
+	}
 
 	// Serwowanie plików statycznych (CSS, JS)
-	fileServer := http.FileServer(http.Dir("./static"))
-	r.Handle("/static/*", http.StripPrefix("/static/", fileServer))
-
-	// Inicjalizacja handlerów
-	indexHandler := handlers.NewIndexHandler()
-	booksHandler := handlers.NewBooksHandler(fbClient)
-	authHandler := handlers.NewAuthHandler()
-	staffHandler := handlers.NewStaffHandler(fbClient)
-	userHandler := handlers.NewUserHandler(fbClient)
-	catalogHandler := handlers.NewCatalogHandler()
-
-	// Strona główna - publiczna
-	r.Get("/", indexHandler.ServeHTTP)
-
-	// Routy dla autoryzacji
-	r.Get("/login", authHandler.ShowLoginPage)
-	r.Post("/login", authHandler.HandleLogin)
-	r.Get("/register", authHandler.ShowRegisterPage)
-	r.Post("/register", authHandler.HandleRegister)
-	r.Post("/logout", authHandler.HandleLogout)
-
-	// Grupy routów dla książek - publiczny katalog
-	r.Route("/books", func(r chi.Router) {
-		r.Get("/", booksHandler.ListBooksHandler)
-		r.Get("/search", booksHandler.SearchBooksHandler)
-		r.Get("/{id}", booksHandler.ShowBookHandler)
-
-		// Wypożyczanie i rezerwacje (wymagają logowania)
-		r.Group(func(r chi.Router) {
-			r.Use(authmw.RequireAuth)
-			r.Post("/{id}/borrow", booksHandler.BorrowBook)
-			r.Post("/{id}/reserve", booksHandler.ReserveBook)
+	// (wbudowane w plik wykonywalny, adresy z hashem treści przez funkcję szablonu "asset")
+	staticAssets, err := assets.Load(static.Files)
+	if err != nil {
+		log.Fatalf("Błąd ładowania plików statycznych: %v", err)
+	}
+	staticHandler := http.StripPrefix(basepath.URL("/static/"), staticAssets.Handler())
+
+	// Integracja z paczkomatami - opcjonalna
+	lockerCfg, err := lockers.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Błąd konfiguracji paczkomatów: %v", err)
+	}
+	if lockerCfg != nil {
+		log.Printf("Integracja z paczkomatami włączona (miejsce odbioru: %s)", lockerCfg.Location)
+	}
+
+	// Powiadomienia Web Push - opcjonalne (klucz VAPID)
+	pushCfg, err := webpush.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Błąd konfiguracji powiadomień push: %v", err)
+	}
+	if pushCfg != nil {
+		log.Println("Powiadomienia push włączone")
+	}
+
+	// Bot Telegrama - opcjonalny, jeden dla całej sieci bibliotek
+	var bot *telegram.Bot
+	if botCfg := telegram.ConfigFromEnv(); botCfg != nil && fbClient != nil {
+		if bot, err = telegram.NewBot(botCfg, fbClient); err != nil {
+			log.Printf("Błąd uruchamiania bota Telegrama - bot wyłączony: %v", err)
+		}
+	}
+
+	// Zgłaszanie błędów serwera do Sentry / Google Cloud Error Reporting - opcjonalne
+	reporter, err := errorreport.FromEnv(context.Background())
+	if err != nil {
+		log.Fatalf("Błąd konfiguracji zgłaszania błędów: %v", err)
+	}
+	if reporter != nil {
+		log.Printf("Zgłaszanie błędów serwera włączone (%s)", strings.Join(reporter.Services(), ", "))
+	}
+
+	// Okładki z zewnętrznych adresów są pobierane raz i przechowywane na dysku
+	coverStore := covers.NewStore(covers.CacheDirFromEnv())
+
+	// Śledzenie żądań i operacji Firestore (OpenTelemetry, eksport OTLP) - opcjonalne
+	if enabled, err := tracing.Init(context.Background()); err != nil {
+		log.Fatalf("Błąd konfiguracji śledzenia: %v", err)
+	} else if enabled {
+		log.Println("Śledzenie OpenTelemetry włączone")
+	}
+
+	// Sieć bibliotek - biblioteki wybierane po nazwie hosta, nieznane hosty obsługuje biblioteka główna
+	var tenants *tenant.Router
+	if fbClient != nil {
+		tenants = tenant.NewRouter(fbClient, func(c *firebase.Client, t *models.Tenant) http.Handler {
+			return newLibrary(c, tenantBaseURL(baseURL, t), staticHandler, lockerCfg, pushCfg, bot, reporter, coverStore, nil).router
+		})
+		tenants.StartRefresh(time.Minute)
+		if n := tenants.Tenants(); n > 0 {
+			log.Printf("Liczba bibliotek sieci: %d", n)
+		}
+	}
+
+	rootLibrary := newLibrary(fbClient, baseURL, staticHandler, lockerCfg, pushCfg, bot, reporter, coverStore, tenants)
+	if bot != nil {
+		bot.Start()
+	}
+
+	// Zadania okresowe
+	scheduler := jobs.NewScheduler()
+
+	// Tryb demonstracyjny - przykładowe dane wgrywane przy starcie i przywracane co noc
+	if os.Getenv("DEMO_MODE") == "true" {
+		if fbClient == nil {
+			log.Fatal("DEMO_MODE wymaga połączenia z Firebase")
+		}
+		demo.Enable()
+
+		resetDemo := func() error {
+			if err := demo.Reset(fbClient); err != nil {
+				return err
+			}
+			rootLibrary.searchIndex.Invalidate()
+			rootLibrary.catalogCache.Invalidate()
+			return nil
+		}
+		if err := resetDemo(); err != nil {
+			log.Fatalf("Błąd przygotowania wersji demonstracyjnej: %v", err)
+		}
+		scheduler.Daily("demo-reset", 3, 0, resetDemo)
+		log.Println("Tryb demonstracyjny włączony - dane są przywracane codziennie o 3:00")
+	}
+
+	// Książki udostępnione na miejscu wracają na półkę na koniec dnia (we wszystkich bibliotekach sieci)
+	if fbClient != nil {
+		scheduler.Daily("reading-room-returns", 23, 55, func() error {
+			return returnReadingRoomLoans(fbClient)
+		})
+
+		// Odznaki za czytanie przyznawane po nocnym przeliczeniu historii wypożyczeń
+		scheduler.Daily("badges", 2, 30, func() error {
+			return awardBadges(fbClient)
+		})
+
+		// Kary za wypożyczenia po terminie naliczane co noc - saldo czytelnika rośnie przed zwrotem
+		scheduler.Daily("overdue-fines", 0, 30, func() error {
+			return accrueOverdueFines(fbClient)
+		})
+
+		// Przypomnienia o terminie zwrotu (w aplikacji, emailem i przez push)
+		scheduler.Daily("due-soon", 9, 0, func() error {
+			return remindLoansDueSoon(fbClient)
+		})
+
+		// Newsletter za miniony miesiąc - pierwszego dnia miesiąca
+		scheduler.Daily("newsletter", 10, 0, func() error {
+			if time.Now().Day() != 1 {
+				return nil
+			}
+			return sendNewsletters(fbClient)
+		})
+
+		// Zrzut publicznych statystyk (/stats) za miniony dzień
+		scheduler.Daily("public-stats", 0, 15, func() error {
+			return snapshotPublicStats(fbClient)
+		})
+
+		// Anonimizacja wypożyczeń zwróconych dawniej niż okres przechowywania z ustawień
+		scheduler.Daily("loan-anonymization", 4, 0, func() error {
+			return anonymizeOldLoans(fbClient)
+		})
+
+		// Obiekty leżące w koszu dłużej niż okres przywracania są usuwane na stałe
+		scheduler.Daily("trash-purge", 4, 30, func() error {
+			return purgeExpiredTrash(fbClient)
+		})
+
+		// Najdroższe trasy i dzienne zużycie Firestore - przed północą, gdy liczniki dnia są zerowane
+		scheduler.Daily("firestore-cost-report", 23, 58, func() error {
+			authmw.LogFirestoreCosts(10)
+			return nil
+		})
+	}
+
+	scheduler.Start()
+
+	// Serwer SIP2 dla automatów samoobsługowych - opcjonalny, wspólny dla całej sieci bibliotek
+	if sipCfg := sip2.ConfigFromEnv(); sipCfg != nil && fbClient != nil {
+		sipServer := sip2.NewServer(sipCfg, func() ([]*firebase.Client, error) {
+			return libraryClients(fbClient)
 		})
-	})
-
-	// Panel użytkownika (dla zalogowanych czytelników)
-	r.Route("/user", func(r chi.Router) {
-		r.Use(authmw.RequireAuth)
-		r.Get("/", userHandler.ShowDashboard)
-		r.Get("/history", userHandler.ShowHistory)
-		r.Get("/reservations", userHandler.ShowReservations)
-		r.Post("/reservations/{id}/borrow", userHandler.BorrowFromReservation)
-		r.Post("/reservations/{id}/cancel", userHandler.CancelReservation)
-	})
-
-	// Panel personelu (tylko dla adminów)
-	r.Route("/staff", func(r chi.Router) {
-		r.Use(authmw.RequireAuth)
-		r.Use(authmw.RequireAuthRole(models.RoleAdmin))
-		r.Get("/", staffHandler.ShowDashboard)
-
-		// Zarządzanie katalogiem
-		r.Get("/catalog", catalogHandler.ListBooks)
-		r.Get("/catalog/search", catalogHandler.SearchBooks)
-		r.Get("/catalog/new", catalogHandler.ShowNewBookForm)
-		r.Post("/catalog", catalogHandler.CreateBook)
-		r.Get("/catalog/{id}/edit", catalogHandler.ShowEditBookForm)
-		r.Put("/catalog/{id}", catalogHandler.UpdateBook)
-		r.Delete("/catalog/{id}", catalogHandler.DeleteBook)
-
-		// Zarządzanie wypożyczeniami
-		r.Get("/loans", staffHandler.ShowLoans)
-		r.Post("/loans/{id}/return", staffHandler.ReturnLoan)
-
-		// Potwierdzanie odbiorów
-		r.Get("/pending-pickups", staffHandler.ShowPendingPickups)
-		r.Post("/loans/confirm-pickup", staffHandler.ConfirmPickup)
-
-		// Zarządzanie użytkownikami
-		r.Get("/users", staffHandler.ShowUsers)
-		r.Get("/users/search", staffHandler.SearchUsers)
-		r.Get("/users/{id}/edit", staffHandler.ShowEditUser)
-		r.Post("/users/{id}/update", staffHandler.UpdateUser)
-
-		// Raporty
-		r.Get("/reports", staffHandler.ShowReports)
-	})
+		go func() {
+			if err := sipServer.ListenAndServe(); err != nil {
+				log.Printf("Serwer SIP2 zatrzymany: %v", err)
+			}
+		}()
+	}
+
+	var app http.Handler = rootLibrary.router
+	if tenants != nil {
+		app = tenants.Handler(rootLibrary.router)
+	}
+
+	// Przy ustawionym BASE_PATH cała aplikacja jest dostępna pod prefiksem
+	handler := app
+	if basepath.Prefix() != "" {
+		root := chi.NewRouter()
+		root.Mount(basepath.Prefix(), app)
+		handler = root
+		log.Printf("Aplikacja dostępna pod prefiksem %s", basepath.Prefix())
+	}
 
 	// Start serwera
+	if tlsCfg != nil {
+		if err := serveTLS(tlsCfg, handler); err != nil {
+			log.Fatalf("Nie można uruchomić serwera: %v", err)
+		}
+		return
+	}
+
 	log.Printf("Serwer uruchomiony na porcie %s", port)
-	if err := http.ListenAndServe(":"+port, r); err != nil {
+	if err := http.ListenAndServe(":"+port, handler); err != nil {
 		log.Fatalf("Nie można uruchomić serwera: %v", err)
 	}
 }
+
+// libraryClients zwraca klientów biblioteki głównej i każdej aktywnej biblioteki sieci
+func libraryClients(root *firebase.Client) ([]*firebase.Client, error) {
+	clients := []*firebase.Client{root}
+	tenants, err := root.ListTenants()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tenants {
+		if t.Active {
+			clients = append(clients, root.ForTenant(t.ID))
+		}
+	}
+	return clients, nil
+}
+
+// returnReadingRoomLoans zwraca niezwrócone udostępnienia na miejscu w bibliotece głównej
+// i w każdej aktywnej bibliotece sieci
+func returnReadingRoomLoans(root *firebase.Client) error {
+	clients, err := libraryClients(root)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range clients {
+		returned, err := c.ReturnReadingRoomLoans()
+		if err != nil {
+			log.Printf("Błąd zwrotu udostępnień na miejscu (biblioteka %q): %v", c.Tenant(), err)
+			continue
+		}
+		if returned > 0 {
+			log.Printf("Automatycznie zwrócono %d udostępnień na miejscu (biblioteka %q)", returned, c.Tenant())
+		}
+	}
+	return nil
+}
+
+// awardBadges przyznaje czytelnikom odznaki we wszystkich bibliotekach sieci
+func awardBadges(root *firebase.Client) error {
+	clients, err := libraryClients(root)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range clients {
+		awarded, err := badges.Award(c, time.Now())
+		if err != nil {
+			log.Printf("Błąd przyznawania odznak (biblioteka %q): %v", c.Tenant(), err)
+			continue
+		}
+		if awarded > 0 {
+			log.Printf("Przyznano %d odznak (biblioteka %q)", awarded, c.Tenant())
+		}
+	}
+	return nil
+}
+
+// accrueOverdueFines nalicza kary za wypożyczenia po terminie w każdej bibliotece sieci
+func accrueOverdueFines(root *firebase.Client) error {
+	clients, err := libraryClients(root)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	for _, c := range clients {
+		store := firebase.NewStore(c)
+		n, err := jobs.AccrueOverdueFines(store.Loans(ctx))
+		if err != nil {
+			log.Printf("Błąd naliczania kar za przetrzymanie (biblioteka %q): %v", c.Tenant(), err)
+			continue
+		}
+		if n > 0 {
+			log.Printf("Naliczono kary za %d przetrzymanych wypożyczeń (biblioteka %q)", n, c.Tenant())
+		}
+	}
+	return nil
+}
+
+// remindLoansDueSoon przypomina o wypożyczeniach z terminem zwrotu za models.DueSoonDays
+// dni w każdej bibliotece sieci. Powiadomienia wysyłają dispatchery bibliotek.
+func remindLoansDueSoon(root *firebase.Client) error {
+	clients, err := libraryClients(root)
+	if err != nil {
+		return err
+	}
+
+	day := time.Now().AddDate(0, 0, models.DueSoonDays)
+	for _, c := range clients {
+		n, err := c.PublishLoansDueSoon(day)
+		if err != nil {
+			log.Printf("Błąd przypomnień o terminie zwrotu (biblioteka %q): %v", c.Tenant(), err)
+			continue
+		}
+		if n > 0 {
+			log.Printf("Przypomniano o %d wypożyczeniach (biblioteka %q)", n, c.Tenant())
+		}
+	}
+	return nil
+}
+
+// sendNewsletters zleca wysyłkę newslettera za miniony miesiąc w każdej bibliotece sieci.
+// Wydania składają i wysyłają dispatchery bibliotek (events.NewsletterDue).
+func sendNewsletters(root *firebase.Client) error {
+	clients, err := libraryClients(root)
+	if err != nil {
+		return err
+	}
+
+	month := time.Now().AddDate(0, -1, 0)
+	for _, c := range clients {
+		events.PublishFor(c.Tenant(), events.NewsletterDue, month)
+	}
+	return nil
+}
+
+// snapshotPublicStats zapisuje dzienny zrzut publicznych statystyk każdej biblioteki sieci
+func snapshotPublicStats(root *firebase.Client) error {
+	clients, err := libraryClients(root)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range clients {
+		if _, err := publicstats.Snapshot(c, time.Now()); err != nil {
+			log.Printf("Błąd zapisywania statystyk publicznych (biblioteka %q): %v", c.Tenant(), err)
+		}
+	}
+	return nil
+}
+
+// anonymizeOldLoans usuwa dane czytelników z dawno zwróconych wypożyczeń w każdej
+// bibliotece sieci, która ustawiła okres przechowywania
+func anonymizeOldLoans(root *firebase.Client) error {
+	clients, err := libraryClients(root)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, c := range clients {
+		settings, err := c.GetSettings()
+		if err != nil {
+			log.Printf("Błąd pobierania ustawień (biblioteka %q): %v", c.Tenant(), err)
+			continue
+		}
+		if settings.LoanRetentionYears == 0 {
+			continue
+		}
+
+		n, err := c.AnonymizeOldLoans(now.AddDate(-settings.LoanRetentionYears, 0, 0))
+		if err != nil {
+			log.Printf("Błąd anonimizacji wypożyczeń (biblioteka %q): %v", c.Tenant(), err)
+		}
+		if n > 0 {
+			log.Printf("Zanonimizowano %d wypożyczeń (biblioteka %q)", n, c.Tenant())
+		}
+	}
+	return nil
+}
+
+// purgeExpiredTrash usuwa na stałe przeterminowane obiekty z kosza każdej biblioteki sieci
+func purgeExpiredTrash(root *firebase.Client) error {
+	clients, err := libraryClients(root)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range clients {
+		n, err := c.PurgeExpiredTrash(time.Now())
+		if err != nil {
+			log.Printf("Błąd opróżniania kosza (biblioteka %q): %v", c.Tenant(), err)
+		}
+		if n > 0 {
+			log.Printf("Usunięto na stałe %d obiektów z kosza (biblioteka %q)", n, c.Tenant())
+		}
+	}
+	return nil
+}
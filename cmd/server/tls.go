@@ -0,0 +1,81 @@
+package main
+
+import (
+	"crypto/tls"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// tlsConfig to ustawienia wbudowanego TLS z certyfikatami Let's Encrypt
+type tlsConfig struct {
+	Domains  []string
+	CacheDir string
+	Email    string
+}
+
+// tlsConfigFromEnv czyta ustawienia TLS ze zmiennych środowiskowych.
+// Zwraca nil, gdy TLS_DOMAINS nie jest ustawione (serwer działa po HTTP, np. za reverse proxy).
+func tlsConfigFromEnv() *tlsConfig {
+	var domains []string
+	for _, domain := range strings.Split(os.Getenv("TLS_DOMAINS"), ",") {
+		if domain = strings.TrimSpace(domain); domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+	if len(domains) == 0 {
+		return nil
+	}
+
+	cacheDir := os.Getenv("TLS_CACHE_DIR")
+	if cacheDir == "" {
+		cacheDir = "certs"
+	}
+
+	return &tlsConfig{
+		Domains:  domains,
+		CacheDir: cacheDir,
+		Email:    os.Getenv("TLS_EMAIL"),
+	}
+}
+
+// serveTLS uruchamia serwer HTTPS (HTTP/2) na porcie 443 z certyfikatami z autocert
+// oraz serwer HTTP na porcie 80, który obsługuje wyzwania ACME i przekierowuje na HTTPS
+func serveTLS(cfg *tlsConfig, handler http.Handler) error {
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(cfg.Domains...),
+		Cache:      autocert.DirCache(cfg.CacheDir),
+		Email:      cfg.Email,
+	}
+
+	// TLSConfig z autocert zawiera "h2" w NextProtos, więc HTTP/2 jest negocjowane automatycznie
+	tlsCfg := manager.TLSConfig()
+	tlsCfg.MinVersion = tls.VersionTLS12
+
+	httpsServer := &http.Server{
+		Addr:              ":443",
+		Handler:           handler,
+		TLSConfig:         tlsCfg,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	httpServer := &http.Server{
+		Addr:              ":80",
+		Handler:           manager.HTTPHandler(nil),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil {
+			log.Fatalf("Nie można uruchomić serwera HTTP (port 80): %v", err)
+		}
+	}()
+
+	log.Printf("Serwer HTTPS uruchomiony dla domen: %s (certyfikaty w %s)", strings.Join(cfg.Domains, ", "), cfg.CacheDir)
+	return httpsServer.ListenAndServeTLS("", "")
+}
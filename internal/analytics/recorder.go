@@ -0,0 +1,135 @@
+package analytics
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+	"library-management-system/internal/search"
+)
+
+// maxTermLength ogranicza długość zapisywanej frazy
+const maxTermLength = 100
+
+// counterKey identyfikuje licznik w buforze
+type counterKey struct {
+	day  string
+	kind models.AnalyticsKind
+	key  string
+}
+
+// Recorder zbiera anonimowe statystyki przeglądania katalogu. Zdarzenia są sumowane
+// w pamięci i zapisywane do Firestore co flushInterval, żeby nie zapisywać bazy
+// przy każdym wyświetleniu strony. Nie zapisuje nic, co identyfikuje użytkownika.
+type Recorder struct {
+	fbClient      *firebase.Client
+	flushInterval time.Duration
+
+	mu     sync.Mutex
+	counts map[counterKey]int
+	labels map[counterKey]string
+}
+
+// NewRecorder tworzy rejestrator statystyk
+func NewRecorder(fbClient *firebase.Client, flushInterval time.Duration) *Recorder {
+	return &Recorder{
+		fbClient:      fbClient,
+		flushInterval: flushInterval,
+		counts:        make(map[counterKey]int),
+		labels:        make(map[counterKey]string),
+	}
+}
+
+// Start uruchamia okresowe zapisywanie statystyk w tle
+func (r *Recorder) Start() {
+	go func() {
+		ticker := time.NewTicker(r.flushInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			r.Flush()
+		}
+	}()
+}
+
+// RecordSearch zapisuje wyszukiwaną frazę oraz - gdy nic nie znaleziono - frazę bez wyników
+func (r *Recorder) RecordSearch(term string, results int) {
+	if r == nil {
+		return
+	}
+
+	// Klucz bez znaków diakrytycznych łączy "zółć" i "żółć", etykieta zachowuje polskie litery
+	label := strings.Join(strings.Fields(strings.ToLower(term)), " ")
+	if label == "" || looksPersonal(label) {
+		return
+	}
+	if len([]rune(label)) > maxTermLength {
+		label = string([]rune(label)[:maxTermLength])
+	}
+	key := search.Normalize(label)
+
+	r.add(models.AnalyticsSearch, key, label)
+	if results == 0 {
+		r.add(models.AnalyticsZeroResultSearch, key, label)
+	}
+}
+
+// RecordBookView zapisuje wyświetlenie strony książki
+func (r *Recorder) RecordBookView(book *models.Book) {
+	if r == nil || book == nil {
+		return
+	}
+	r.add(models.AnalyticsBookView, book.ID, book.Title)
+}
+
+// RecordShare zapisuje udostępnienie książki przez serwis channel (label to nazwa serwisu do raportu)
+func (r *Recorder) RecordShare(book *models.Book, channel, label string) {
+	if r == nil || book == nil {
+		return
+	}
+	r.add(models.AnalyticsBookShare, book.ID, book.Title)
+	r.add(models.AnalyticsShareChannel, channel, label)
+}
+
+// RecordShareVisit zapisuje wejście na stronę książki z udostępnionego linku
+func (r *Recorder) RecordShareVisit(book *models.Book) {
+	if r == nil || book == nil {
+		return
+	}
+	r.add(models.AnalyticsShareVisit, book.ID, book.Title)
+}
+
+func (r *Recorder) add(kind models.AnalyticsKind, key, label string) {
+	k := counterKey{day: time.Now().Format("2006-01-02"), kind: kind, key: key}
+
+	r.mu.Lock()
+	r.counts[k]++
+	r.labels[k] = label
+	r.mu.Unlock()
+}
+
+// Flush zapisuje zebrane liczniki do bazy
+func (r *Recorder) Flush() {
+	if r == nil || r.fbClient == nil {
+		return
+	}
+
+	r.mu.Lock()
+	counts, labels := r.counts, r.labels
+	r.counts = make(map[counterKey]int)
+	r.labels = make(map[counterKey]string)
+	r.mu.Unlock()
+
+	for k, n := range counts {
+		if err := r.fbClient.IncrementAnalyticsCounter(k.day, k.kind, k.key, labels[k], n); err != nil {
+			log.Printf("Błąd zapisywania statystyk: %v", err)
+		}
+	}
+}
+
+// looksPersonal odrzuca frazy, które mogą zawierać dane osobowe (np. email)
+func looksPersonal(term string) bool {
+	return strings.Contains(term, "@")
+}
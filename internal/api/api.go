@@ -0,0 +1,185 @@
+// Package api udostępnia JSON API (/api/v1) dla zewnętrznych integracji -
+// systemów szkolnych, kiosków, stanowisk samoobsługowych i klienta z pakietu pkg/client.
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+	"library-management-system/internal/search"
+)
+
+const (
+	defaultPerPage = 20
+	maxPerPage     = 100
+)
+
+// Handler obsługuje żądania JSON API
+type Handler struct {
+	fbClient    *firebase.Client
+	quota       *Quota
+	searchIndex *search.Index
+}
+
+// NewHandler tworzy nowy handler JSON API.
+// quota jest współdzielona ze stroną personelu pokazującą zużycie limitów,
+// a searchIndex (wyszukiwarka stron katalogu) jest unieważniany po zmianach książek.
+func NewHandler(fbClient *firebase.Client, quota *Quota, searchIndex *search.Index) *Handler {
+	return &Handler{fbClient: fbClient, quota: quota, searchIndex: searchIndex}
+}
+
+// Routes zwraca router z endpointami API w wersji 1 (montowany pod /api/v1)
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+	r.Use(h.requireDatabase)
+
+	// Stanowiska samoobsługowe mają własne tokeny i nie podlegają limitom klientów API
+	r.Route("/selfcheck", h.selfCheckRoutes)
+
+	r.Group(func(r chi.Router) {
+		r.Use(h.quota.Middleware)
+
+		r.Post("/auth/token", h.CreateToken)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(h.quota.Middleware)
+		r.Use(requireToken)
+
+		r.Delete("/auth/token", h.RevokeToken)
+		r.Get("/me", h.GetMe)
+
+		r.Get("/books", h.ListBooks)
+		r.Get("/books/{id}", h.GetBook)
+		r.Post("/books/{id}/loans", h.CreateLoan)
+		r.Post("/books/{id}/reservations", h.CreateReservation)
+
+		r.Get("/loans", h.ListLoans)
+		r.Get("/loans/{id}", h.GetLoan)
+		r.Post("/loans/{id}/renew", h.RenewLoan)
+
+		r.Get("/reservations", h.ListReservations)
+		r.Get("/reservations/{id}", h.GetReservation)
+		r.Delete("/reservations/{id}", h.CancelReservation)
+
+		// Zarządzanie katalogiem i zwrotami - personel (administratorzy i bibliotekarze)
+		r.Group(func(r chi.Router) {
+			r.Use(requireRole(models.RoleAdmin, models.RoleLibrarian))
+
+			r.Post("/books", h.CreateBook)
+			r.Put("/books/{id}", h.UpdateBook)
+			r.Delete("/books/{id}", h.DeleteBook)
+
+			r.Post("/loans/{id}/return", h.ReturnLoan)
+		})
+
+		// Zarządzanie kontami - tylko administratorzy
+		r.Group(func(r chi.Router) {
+			r.Use(requireRole(models.RoleAdmin))
+
+			r.Get("/users", h.ListUsers)
+			r.Get("/users/{id}", h.GetUser)
+			r.With(demoGuard).Post("/users", h.CreateUser)
+			r.With(demoGuard).Put("/users/{id}", h.UpdateUser)
+			r.With(demoGuard).Delete("/users/{id}", h.DeleteUser)
+		})
+	})
+
+	return r
+}
+
+// Page to strona wyników listy
+type Page struct {
+	Items   interface{} `json:"items"`
+	Page    int         `json:"page"`
+	PerPage int         `json:"per_page"`
+	Total   int         `json:"total"`
+}
+
+// errorResponse to treść odpowiedzi z błędem: komunikat dla użytkownika i stały kod
+// rodzaju błędu, na którym mogą polegać aplikacje klienckie
+type errorResponse struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+// errorCodes to kody błędów według statusu HTTP
+var errorCodes = map[int]string{
+	http.StatusBadRequest:          "bad_request",
+	http.StatusUnauthorized:        "unauthorized",
+	http.StatusForbidden:           "forbidden",
+	http.StatusNotFound:            "not_found",
+	http.StatusConflict:            "conflict",
+	http.StatusUnprocessableEntity: "validation_failed",
+	http.StatusTooManyRequests:     "rate_limited",
+	http.StatusInternalServerError: "internal_error",
+	http.StatusServiceUnavailable:  "unavailable",
+}
+
+// requireDatabase odrzuca żądania, gdy baza danych nie jest dostępna
+func (h *Handler) requireDatabase(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.fbClient == nil {
+			writeError(w, http.StatusServiceUnavailable, "Baza danych niedostępna")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// pagination odczytuje parametry page i per_page z zapytania
+func pagination(r *http.Request) (page, perPage int) {
+	page, _ = strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	perPage, _ = strconv.Atoi(r.URL.Query().Get("per_page"))
+	if perPage < 1 {
+		perPage = defaultPerPage
+	}
+	if perPage > maxPerPage {
+		perPage = maxPerPage
+	}
+
+	return page, perPage
+}
+
+// paginate wycina stronę z pełnej listy wyników. Strona za końcem listy jest pusta;
+// numer strony jest sprawdzany przed mnożeniem, bo ogromne ?page= przepełniłoby int.
+func paginate[T any](items []T, page, perPage int) []T {
+	if page < 1 || perPage < 1 || page > len(items)/perPage+1 {
+		return []T{}
+	}
+	start := (page - 1) * perPage
+	if start >= len(items) {
+		return []T{}
+	}
+	end := start + perPage
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[start:end]
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Błąd kodowania odpowiedzi API: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	code, ok := errorCodes[status]
+	if !ok {
+		code = "error"
+	}
+	writeJSON(w, status, errorResponse{Error: message, Code: code})
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPaginate(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+
+	tests := []struct {
+		name          string
+		page, perPage int
+		want          []int
+	}{
+		{"pierwsza strona", 1, 2, []int{1, 2}},
+		{"ostatnia niepełna strona", 3, 2, []int{5}},
+		{"strona za końcem", 4, 2, []int{}},
+		{"cała lista", 1, 100, []int{1, 2, 3, 4, 5}},
+		{"przepełnienie numeru strony", 922337203685477581, 10, []int{}},
+		{"największa strona", int(^uint(0) >> 1), maxPerPage, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := paginate(items, tt.page, tt.perPage); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("paginate(strona %d, po %d) = %v, oczekiwano %v", tt.page, tt.perPage, got, tt.want)
+			}
+		})
+	}
+}
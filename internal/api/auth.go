@@ -0,0 +1,158 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"library-management-system/internal/demo"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+	"library-management-system/internal/session"
+	"library-management-system/internal/tenant"
+)
+
+type contextKey string
+
+const sessionKey contextKey = "api_session"
+
+// tokenRequest to dane logowania do API
+type tokenRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// tokenResponse zawiera token dostępu do API
+type tokenResponse struct {
+	Token     string       `json:"token"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	User      *models.User `json:"user"`
+}
+
+// CreateToken loguje użytkownika i wydaje token dostępu (POST /api/v1/auth/token).
+// Token to identyfikator sesji - wygasa razem z nią.
+func (h *Handler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	var req tokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Nieprawidłowe dane JSON")
+		return
+	}
+	if req.Email == "" || req.Password == "" {
+		writeError(w, http.StatusBadRequest, "Email i hasło są wymagane")
+		return
+	}
+
+	firebaseUID, err := h.fbClient.Traced(r.Context()).VerifyPassword(req.Email, req.Password)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "Nieprawidłowy email lub hasło")
+		return
+	}
+
+	user, err := h.fbClient.Traced(r.Context()).GetUserByFirebaseUID(firebaseUID)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "Użytkownik nie istnieje w systemie")
+		return
+	}
+	if !user.IsActive {
+		writeError(w, http.StatusForbidden, "Konto zostało dezaktywowane")
+		return
+	}
+
+	sess, err := session.GetManager().CreateSession(user)
+	if err != nil {
+		log.Printf("Błąd tworzenia sesji API: %v", err)
+		writeError(w, http.StatusInternalServerError, "Błąd logowania")
+		return
+	}
+
+	log.Printf("Wydano token API: %s", user.Email)
+	writeJSON(w, http.StatusCreated, tokenResponse{Token: sess.ID, ExpiresAt: sess.ExpiresAt, User: user})
+}
+
+// RevokeToken unieważnia bieżący token (DELETE /api/v1/auth/token)
+func (h *Handler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	session.GetManager().DeleteSession(sessionFromContext(r.Context()).ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetMe zwraca profil zalogowanego użytkownika (GET /api/v1/me)
+func (h *Handler) GetMe(w http.ResponseWriter, r *http.Request) {
+	user, err := h.fbClient.Traced(r.Context()).GetUser(sessionFromContext(r.Context()).UserID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Użytkownik nie został znaleziony")
+		return
+	}
+	writeJSON(w, http.StatusOK, user)
+}
+
+// requireToken wymaga nagłówka "Authorization: Bearer <token>" z ważnym tokenem
+func requireToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "Brak tokenu dostępu")
+			return
+		}
+
+		// Token jest ważny tylko w bibliotece sieci, w której został wydany
+		sess, ok := session.GetManager().GetSession(token)
+		if !ok || sess.User.Tenant != tenant.FromContext(r.Context()) {
+			writeError(w, http.StatusUnauthorized, "Token jest nieprawidłowy lub wygasł")
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), sessionKey, sess)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// requireRole przepuszcza tylko tokeny kont z jedną z podanych ról (po requireToken)
+func requireRole(roles ...models.UserRole) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if sess := sessionFromContext(r.Context()); sess == nil || !slices.Contains(roles, sess.User.Role) {
+				writeError(w, http.StatusForbidden, "Brak uprawnień - wymagane konto personelu z odpowiednią rolą")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// bearerToken odczytuje token z nagłówka Authorization
+func bearerToken(r *http.Request) (string, bool) {
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	token = strings.TrimSpace(token)
+	return token, found && token != ""
+}
+
+// demoGuard blokuje w trybie demonstracyjnym zmiany kont użytkowników (jak demo.Guard w panelu personelu)
+func demoGuard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if demo.Enabled() {
+			writeError(w, http.StatusForbidden, "Ta funkcja jest wyłączona w wersji demonstracyjnej")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// recordAudit zapisuje zmianę wykonaną tokenem personelu w dzienniku zmian
+func recordAudit(r *http.Request, audit *firebase.AuditRecorder, action models.AuditAction, entityID, label string) {
+	if audit == nil {
+		return
+	}
+	if err := audit.Record(action, entityID, label, sessionFromContext(r.Context()).User); err != nil {
+		log.Printf("Błąd zapisu dziennika zmian (%s %s, API): %v", action, entityID, err)
+	}
+}
+
+// sessionFromContext zwraca sesję ustawioną przez requireToken
+func sessionFromContext(ctx context.Context) *session.Session {
+	sess, _ := ctx.Value(sessionKey).(*session.Session)
+	return sess
+}
@@ -0,0 +1,248 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+)
+
+// bookRequest to dane książki przesyłane przez personel (POST i PUT /api/v1/books)
+type bookRequest struct {
+	ISBN            string `json:"isbn"`
+	Title           string `json:"title"`
+	Author          string `json:"author"`
+	Publisher       string `json:"publisher"`
+	PublicationYear int    `json:"publication_year"`
+	Category        string `json:"category"`
+	CallNumber      string `json:"call_number"`
+	Series          string `json:"series"`
+	SeriesVolume    int    `json:"series_volume"`
+	Description     string `json:"description"`
+	TotalCopies     int    `json:"total_copies"`
+}
+
+// book zamienia żądanie na książkę (wszystkie egzemplarze dostępne)
+func (req *bookRequest) book() *models.Book {
+	return &models.Book{
+		ISBN:            strings.TrimSpace(req.ISBN),
+		Title:           strings.TrimSpace(req.Title),
+		Author:          strings.TrimSpace(req.Author),
+		Publisher:       req.Publisher,
+		PublicationYear: req.PublicationYear,
+		Category:        req.Category,
+		CallNumber:      req.CallNumber,
+		Series:          strings.TrimSpace(req.Series),
+		SeriesVolume:    req.SeriesVolume,
+		Description:     req.Description,
+		TotalCopies:     req.TotalCopies,
+		AvailableCopies: req.TotalCopies,
+	}
+}
+
+// validateBook sprawdza dane książki tak samo jak formularz katalogu.
+// Zwraca komunikat błędu albo pusty napis.
+func validateBook(book *models.Book) string {
+	switch {
+	case book.ISBN == "":
+		return "ISBN jest wymagany"
+	case book.Title == "":
+		return "Tytuł jest wymagany"
+	case book.Author == "":
+		return "Autor jest wymagany"
+	case book.TotalCopies < 1:
+		return "Liczba egzemplarzy musi być większa od 0"
+	case book.SeriesVolume < 0 || (book.SeriesVolume > 0 && book.Series == ""):
+		return "Numer tomu wymaga nazwy serii i musi być większy od 0"
+	}
+	if _, err := models.NormalizeCallNumber(book.CallNumber); err != nil {
+		return "Nieprawidłowa sygnatura: " + err.Error()
+	}
+	return ""
+}
+
+// ListBooks zwraca stronę katalogu (GET /api/v1/books?q=&category=&page=&per_page=)
+func (h *Handler) ListBooks(w http.ResponseWriter, r *http.Request) {
+	page, perPage := pagination(r)
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	category := r.URL.Query().Get("category")
+
+	// Bez filtrów stronicujemy w bazie; wyszukiwanie filtruje po stronie aplikacji
+	if query == "" && category == "" {
+		books, total, err := h.fbClient.Traced(r.Context()).ListBooksWithPagination(perPage, (page-1)*perPage, "title", "asc")
+		if err != nil {
+			log.Printf("Błąd pobierania książek (API): %v", err)
+			writeError(w, http.StatusInternalServerError, "Błąd pobierania książek")
+			return
+		}
+		if books == nil {
+			books = []*models.Book{}
+		}
+		writeJSON(w, http.StatusOK, Page{Items: books, Page: page, PerPage: perPage, Total: total})
+		return
+	}
+
+	var books []*models.Book
+	var err error
+	if query != "" {
+		books, err = h.fbClient.Traced(r.Context()).SearchBooks(query)
+	} else {
+		books, err = h.fbClient.Traced(r.Context()).GetBooksByCategory(category)
+	}
+	if err != nil {
+		log.Printf("Błąd wyszukiwania książek (API): %v", err)
+		writeError(w, http.StatusInternalServerError, "Błąd pobierania książek")
+		return
+	}
+
+	if query != "" && category != "" {
+		filtered := books[:0]
+		for _, book := range books {
+			if book.Category == category {
+				filtered = append(filtered, book)
+			}
+		}
+		books = filtered
+	}
+
+	writeJSON(w, http.StatusOK, Page{Items: paginate(books, page, perPage), Page: page, PerPage: perPage, Total: len(books)})
+}
+
+// GetBook zwraca szczegóły książki (GET /api/v1/books/{id})
+func (h *Handler) GetBook(w http.ResponseWriter, r *http.Request) {
+	book, err := h.fbClient.Traced(r.Context()).GetBook(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Książka nie została znaleziona")
+		return
+	}
+	writeJSON(w, http.StatusOK, book)
+}
+
+// CreateBook dodaje książkę do katalogu (POST /api/v1/books, tylko personel)
+func (h *Handler) CreateBook(w http.ResponseWriter, r *http.Request) {
+	var req bookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Nieprawidłowe dane JSON")
+		return
+	}
+	book := req.book()
+	if message := validateBook(book); message != "" {
+		writeError(w, http.StatusUnprocessableEntity, message)
+		return
+	}
+
+	existing, err := h.fbClient.Traced(r.Context()).GetBookByISBN(book.ISBN)
+	if err != nil {
+		log.Printf("Błąd sprawdzania ISBN (API): %v", err)
+		writeError(w, http.StatusInternalServerError, "Błąd sprawdzania ISBN")
+		return
+	}
+	if existing != nil {
+		writeError(w, http.StatusConflict, "Książka z tym ISBN już istnieje")
+		return
+	}
+
+	if err := h.fbClient.Traced(r.Context()).CreateBook(book); err != nil {
+		log.Printf("Błąd tworzenia książki (API): %v", err)
+		writeError(w, http.StatusInternalServerError, "Błąd zapisywania książki")
+		return
+	}
+	h.searchIndex.Invalidate()
+
+	writeJSON(w, http.StatusCreated, book)
+}
+
+// UpdateBook zmienia dane książki (PUT /api/v1/books/{id}, tylko personel).
+// Liczbę egzemplarzy można tylko zwiększyć - nowe egzemplarze od razu są dostępne.
+func (h *Handler) UpdateBook(w http.ResponseWriter, r *http.Request) {
+	bookID := chi.URLParam(r, "id")
+
+	existing, err := h.fbClient.Traced(r.Context()).GetBook(bookID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Książka nie została znaleziona")
+		return
+	}
+
+	var req bookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Nieprawidłowe dane JSON")
+		return
+	}
+	book := req.book()
+	if message := validateBook(book); message != "" {
+		writeError(w, http.StatusUnprocessableEntity, message)
+		return
+	}
+	if book.TotalCopies < existing.TotalCopies {
+		writeError(w, http.StatusConflict, "Liczbę egzemplarzy można zmniejszyć tylko przez wycofanie egzemplarzy w panelu personelu")
+		return
+	}
+	if book.ISBN != existing.ISBN {
+		other, err := h.fbClient.Traced(r.Context()).GetBookByISBN(book.ISBN)
+		if err != nil {
+			log.Printf("Błąd sprawdzania ISBN (API): %v", err)
+			writeError(w, http.StatusInternalServerError, "Błąd sprawdzania ISBN")
+			return
+		}
+		if other != nil {
+			writeError(w, http.StatusConflict, "Książka z tym ISBN już istnieje")
+			return
+		}
+	}
+
+	book.ID = bookID
+	book.AvailableCopies = existing.AvailableCopies + book.TotalCopies - existing.TotalCopies
+	book.ShelfLocation = existing.ShelfLocation
+	book.CoverImageURL = existing.CoverImageURL
+	book.CreatedAt = existing.CreatedAt
+
+	audit, err := h.fbClient.Traced(r.Context()).BeginAudit(firebase.AuditDoc{Collection: firebase.BooksCollection, ID: bookID})
+	if err != nil {
+		log.Printf("Błąd dziennika zmian przed edycją książki %s (API): %v", bookID, err)
+	}
+
+	if err := h.fbClient.Traced(r.Context()).UpdateBook(bookID, book); err != nil {
+		log.Printf("Błąd aktualizacji książki (API): %v", err)
+		writeError(w, http.StatusInternalServerError, "Błąd zapisywania książki")
+		return
+	}
+	h.searchIndex.Invalidate()
+	recordAudit(r, audit, models.AuditBookEdit, bookID, book.Title)
+
+	writeJSON(w, http.StatusOK, book)
+}
+
+// DeleteBook usuwa książkę bez aktywnych wypożyczeń (DELETE /api/v1/books/{id}, tylko personel)
+func (h *Handler) DeleteBook(w http.ResponseWriter, r *http.Request) {
+	bookID := chi.URLParam(r, "id")
+
+	if _, err := h.fbClient.Traced(r.Context()).GetBook(bookID); err != nil {
+		writeError(w, http.StatusNotFound, "Książka nie została znaleziona")
+		return
+	}
+
+	hasLoans, err := h.fbClient.Traced(r.Context()).HasActiveLoans(bookID)
+	if err != nil {
+		log.Printf("Błąd sprawdzania wypożyczeń (API): %v", err)
+		writeError(w, http.StatusInternalServerError, "Błąd sprawdzania wypożyczeń")
+		return
+	}
+	if hasLoans {
+		writeError(w, http.StatusConflict, "Nie można usunąć książki z aktywnymi wypożyczeniami")
+		return
+	}
+
+	if err := h.fbClient.Traced(r.Context()).DeleteBook(bookID); err != nil {
+		log.Printf("Błąd usuwania książki (API): %v", err)
+		writeError(w, http.StatusInternalServerError, "Błąd usuwania książki")
+		return
+	}
+	h.searchIndex.Invalidate()
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/models"
+)
+
+// ListLoans zwraca wypożyczenia zalogowanego użytkownika (GET /api/v1/loans?page=&per_page=)
+func (h *Handler) ListLoans(w http.ResponseWriter, r *http.Request) {
+	page, perPage := pagination(r)
+
+	loans, err := h.fbClient.Traced(r.Context()).GetUserLoans(sessionFromContext(r.Context()).UserID)
+	if err != nil {
+		log.Printf("Błąd pobierania wypożyczeń (API): %v", err)
+		writeError(w, http.StatusInternalServerError, "Błąd pobierania wypożyczeń")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Page{Items: paginate(loans, page, perPage), Page: page, PerPage: perPage, Total: len(loans)})
+}
+
+// CreateLoan zamawia książkę do odbioru (POST /api/v1/books/{id}/loans).
+// Odpowiedź zawiera kod odbioru, który czytelnik podaje w bibliotece.
+func (h *Handler) CreateLoan(w http.ResponseWriter, r *http.Request) {
+	sess := sessionFromContext(r.Context())
+	bookID := chi.URLParam(r, "id")
+
+	user, err := h.fbClient.Traced(r.Context()).GetUser(sess.UserID)
+	if err != nil {
+		log.Printf("Błąd pobierania użytkownika (API): %v", err)
+		writeError(w, http.StatusInternalServerError, "Błąd pobierania danych użytkownika")
+		return
+	}
+
+	if message := user.BorrowRefusal(); message != "" {
+		writeError(w, http.StatusConflict, message)
+		return
+	}
+
+	book, err := h.fbClient.Traced(r.Context()).GetBook(bookID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Książka nie została znaleziona")
+		return
+	}
+	if !book.IsAvailable() {
+		writeError(w, http.StatusConflict, "Książka jest obecnie niedostępna")
+		return
+	}
+
+	loan := &models.Loan{
+		BookID:    bookID,
+		UserID:    sess.UserID,
+		BookTitle: book.Title,
+		UserName:  user.FullName(),
+	}
+	if err := h.fbClient.Traced(r.Context()).CreateLoan(loan); err != nil {
+		log.Printf("Błąd tworzenia wypożyczenia (API): %v", err)
+		writeError(w, http.StatusInternalServerError, "Błąd wypożyczania książki")
+		return
+	}
+
+	if err := h.fbClient.Traced(r.Context()).UpdateBookAvailability(bookID, false); err != nil {
+		log.Printf("Błąd aktualizacji dostępności: %v", err)
+	}
+	if err := h.fbClient.Traced(r.Context()).UpdateUserLoansCount(sess.UserID, true); err != nil {
+		log.Printf("Błąd aktualizacji licznika wypożyczeń: %v", err)
+	}
+
+	writeJSON(w, http.StatusCreated, loan)
+}
+
+// GetLoan zwraca wypożyczenie (GET /api/v1/loans/{id}). Czytelnik widzi tylko własne.
+func (h *Handler) GetLoan(w http.ResponseWriter, r *http.Request) {
+	sess := sessionFromContext(r.Context())
+	loan, err := h.fbClient.Traced(r.Context()).GetLoan(chi.URLParam(r, "id"))
+	if err != nil || (loan.UserID != sess.UserID && !sess.User.IsStaff()) {
+		writeError(w, http.StatusNotFound, "Wypożyczenie nie zostało znalezione")
+		return
+	}
+	writeJSON(w, http.StatusOK, loan)
+}
+
+// RenewLoan przedłuża termin zwrotu wypożyczenia czytelnika (POST /api/v1/loans/{id}/renew)
+func (h *Handler) RenewLoan(w http.ResponseWriter, r *http.Request) {
+	sess := sessionFromContext(r.Context())
+	loanID := chi.URLParam(r, "id")
+
+	loan, err := h.fbClient.Traced(r.Context()).GetLoan(loanID)
+	if err != nil || loan.UserID != sess.UserID {
+		writeError(w, http.StatusNotFound, "Wypożyczenie nie zostało znalezione")
+		return
+	}
+
+	loan, err = h.fbClient.Traced(r.Context()).RenewLoan(loanID, sess.UserID)
+	if err != nil {
+		writeError(w, http.StatusConflict, "Nie można przedłużyć: "+err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, loan)
+}
+
+// returnResponse to wynik zwrotu przyjętego przez personel
+type returnResponse struct {
+	Loan *models.Loan `json:"loan"`
+	Fine models.Money `json:"fine"`
+	// Rezerwacja, która stała się gotowa do odbioru (null, gdy książka wraca na półkę)
+	NextReservation *models.Reservation `json:"next_reservation"`
+}
+
+// ReturnLoan przyjmuje zwrot wypożyczenia (POST /api/v1/loans/{id}/return, tylko personel)
+func (h *Handler) ReturnLoan(w http.ResponseWriter, r *http.Request) {
+	loanID := chi.URLParam(r, "id")
+
+	loan, err := h.fbClient.Traced(r.Context()).GetLoan(loanID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Wypożyczenie nie zostało znalezione")
+		return
+	}
+	if loan.Status == models.LoanStatusReturned {
+		writeError(w, http.StatusConflict, "Wypożyczenie zostało już zwrócone")
+		return
+	}
+
+	audit, err := h.fbClient.Traced(r.Context()).BeginReturnAudit(loanID)
+	if err != nil {
+		log.Printf("Błąd dziennika zmian przed zwrotem %s (API): %v", loanID, err)
+	}
+
+	result, err := h.fbClient.Traced(r.Context()).ReturnLoan(loanID)
+	if err != nil {
+		log.Printf("Błąd zwrotu książki (API): %v", err)
+		writeError(w, http.StatusInternalServerError, "Błąd zwrotu książki")
+		return
+	}
+	recordAudit(r, audit, models.AuditReturn, loanID, result.Loan.BookTitle+" - "+result.Loan.ReaderName())
+
+	writeJSON(w, http.StatusOK, returnResponse{Loan: result.Loan, Fine: result.Fine, NextReservation: result.NextReservation})
+}
@@ -0,0 +1,228 @@
+package api
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"library-management-system/internal/session"
+)
+
+// QuotaLimits to limity żądań dla jednego klienta (0 = bez limitu)
+type QuotaLimits struct {
+	PerMinute int
+	PerDay    int
+}
+
+// QuotaConfig to limity dla żądań z tokenem i anonimowych (liczonych po adresie IP)
+type QuotaConfig struct {
+	Token QuotaLimits
+	IP    QuotaLimits
+}
+
+// QuotaConfigFromEnv odczytuje limity ze zmiennych API_TOKEN_PER_MINUTE, API_TOKEN_PER_DAY,
+// API_IP_PER_MINUTE i API_IP_PER_DAY, z wartościami domyślnymi dla brakujących
+func QuotaConfigFromEnv() QuotaConfig {
+	return QuotaConfig{
+		Token: QuotaLimits{
+			PerMinute: envInt("API_TOKEN_PER_MINUTE", 120),
+			PerDay:    envInt("API_TOKEN_PER_DAY", 10000),
+		},
+		IP: QuotaLimits{
+			PerMinute: envInt("API_IP_PER_MINUTE", 30),
+			PerDay:    envInt("API_IP_PER_DAY", 1000),
+		},
+	}
+}
+
+func envInt(name string, fallback int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		log.Printf("Nieprawidłowa wartość %s=%q, używam %d", name, value, fallback)
+		return fallback
+	}
+	return n
+}
+
+// Rodzaje klientów API
+const (
+	ClientToken = "token"
+	ClientIP    = "ip"
+)
+
+// UsageEntry to zużycie limitów przez jednego klienta API
+type UsageEntry struct {
+	Kind        string
+	Label       string
+	MinuteCount int
+	DayCount    int
+	Rejected    int
+	Limits      QuotaLimits
+	LastSeen    time.Time
+}
+
+// usage to liczniki klienta z początkiem bieżącego okna minutowego
+type usage struct {
+	UsageEntry
+	minute time.Time
+}
+
+// maxQuotaClients to największa liczba klientów z licznikami w pamięci. Po jej
+// osiągnięciu liczniki najdawniej aktywnych klientów są usuwane, żeby żądania z wielu
+// adresów nie zajmowały pamięci do północy.
+const maxQuotaClients = 10000
+
+// Quota pilnuje limitów żądań do API. Liczniki są trzymane w pamięci
+// i zerują się po restarcie serwera.
+type Quota struct {
+	mu     sync.Mutex
+	config QuotaConfig
+	usage  map[string]*usage
+	day    string
+	now    func() time.Time
+}
+
+// NewQuota tworzy licznik limitów API
+func NewQuota(config QuotaConfig) *Quota {
+	return &Quota{
+		config: config,
+		usage:  make(map[string]*usage),
+		now:    time.Now,
+	}
+}
+
+// Middleware odrzuca żądania ponad limit odpowiedzią 429 z nagłówkiem Retry-After.
+// Żądania z ważnym tokenem liczone są na token, pozostałe na adres IP.
+func (q *Quota) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, kind, label, limits := q.identify(r)
+
+		allowed, remaining, retryAfter := q.take(key, kind, label, limits)
+		if limits.PerMinute > 0 {
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limits.PerMinute))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		}
+
+		if !allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			writeError(w, http.StatusTooManyRequests, "Przekroczono limit żądań - spróbuj ponownie później")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Usage zwraca zużycie limitów w bieżącej dobie, od najbardziej aktywnych klientów
+func (q *Quota) Usage() []UsageEntry {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.resetDay(q.now())
+
+	entries := make([]UsageEntry, 0, len(q.usage))
+	for _, u := range q.usage {
+		entries = append(entries, u.UsageEntry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].DayCount > entries[j].DayCount
+	})
+	return entries
+}
+
+// identify określa klucz klienta: token z ważną sesją albo adres IP. Adres pochodzi
+// z połączenia; z nagłówków X-Forwarded-For / X-Real-IP ustawia go tylko
+// middleware.TrustedProxies.RealIP dla połączeń od zaufanych serwerów pośredniczących.
+func (q *Quota) identify(r *http.Request) (key, kind, label string, limits QuotaLimits) {
+	if token, ok := bearerToken(r); ok {
+		if sess, ok := session.GetManager().GetSession(token); ok {
+			// Pełny token nie może trafić na stronę personelu
+			label = sess.User.Email + " (" + token[:min(8, len(token))] + "…)"
+			return ClientToken + ":" + token, ClientToken, label, q.config.Token
+		}
+	}
+
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	return ClientIP + ":" + ip, ClientIP, ip, q.config.IP
+}
+
+// take zalicza żądanie do limitów klienta. Zwraca czy żądanie jest dozwolone,
+// ile żądań zostało w bieżącej minucie i po jakim czasie warto ponowić próbę.
+func (q *Quota) take(key, kind, label string, limits QuotaLimits) (bool, int, time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	q.resetDay(now)
+
+	u, ok := q.usage[key]
+	if !ok {
+		if len(q.usage) >= maxQuotaClients {
+			q.evictIdle()
+		}
+		u = &usage{UsageEntry: UsageEntry{Kind: kind, Label: label, Limits: limits}}
+		q.usage[key] = u
+	}
+	u.LastSeen = now
+
+	minute := now.Truncate(time.Minute)
+	if !u.minute.Equal(minute) {
+		u.minute = minute
+		u.MinuteCount = 0
+	}
+
+	if limits.PerDay > 0 && u.DayCount >= limits.PerDay {
+		u.Rejected++
+		return false, 0, untilMidnight(now)
+	}
+	if limits.PerMinute > 0 && u.MinuteCount >= limits.PerMinute {
+		u.Rejected++
+		return false, 0, minute.Add(time.Minute).Sub(now)
+	}
+
+	u.MinuteCount++
+	u.DayCount++
+	return true, limits.PerMinute - u.MinuteCount, 0
+}
+
+// resetDay czyści liczniki po północy (wywoływane pod blokadą)
+func (q *Quota) resetDay(now time.Time) {
+	day := now.Format("2006-01-02")
+	if q.day != day {
+		q.day = day
+		q.usage = make(map[string]*usage)
+	}
+}
+
+// evictIdle usuwa liczniki dziesiątej części klientów, którzy najdłużej nie wysyłali
+// żądań (wywoływane pod blokadą). Usunięty klient zaczyna dobę od nowa - przy tylu
+// aktywnych adresach to mniejsze zło niż nieograniczony wzrost pamięci.
+func (q *Quota) evictIdle() {
+	keys := make([]string, 0, len(q.usage))
+	for key := range q.usage {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return q.usage[keys[i]].LastSeen.Before(q.usage[keys[j]].LastSeen)
+	})
+	for _, key := range keys[:len(keys)/10+1] {
+		delete(q.usage, key)
+	}
+}
+
+func untilMidnight(now time.Time) time.Duration {
+	year, month, day := now.Date()
+	return time.Date(year, month, day+1, 0, 0, 0, 0, now.Location()).Sub(now)
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestQuotaIgnoresSpoofedForwardedFor(t *testing.T) {
+	quota := NewQuota(QuotaConfig{IP: QuotaLimits{PerMinute: 2}})
+	handler := quota.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	codes := make([]int, 0, 3)
+	for i := 0; i < 3; i++ {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/books", nil)
+		r.RemoteAddr = "203.0.113.7:5123"
+		r.Header.Set("X-Forwarded-For", fmt.Sprintf("198.51.100.%d", i))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		codes = append(codes, w.Code)
+	}
+	if codes[2] != http.StatusTooManyRequests {
+		t.Errorf("kody odpowiedzi %v, oczekiwano 429 dla trzeciego żądania z tego samego połączenia", codes)
+	}
+}
+
+func TestQuotaEvictsIdleClients(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	quota := NewQuota(QuotaConfig{IP: QuotaLimits{PerDay: 100}})
+	quota.now = func() time.Time { return now }
+
+	for i := 0; i < maxQuotaClients; i++ {
+		now = now.Add(time.Millisecond)
+		quota.take(fmt.Sprintf("ip:%d", i), ClientIP, "", quota.config.IP)
+	}
+	now = now.Add(time.Millisecond)
+	quota.take("ip:nowy", ClientIP, "", quota.config.IP)
+
+	if n := len(quota.usage); n > maxQuotaClients {
+		t.Errorf("liczniki %d klientów, oczekiwano najwyżej %d", n, maxQuotaClients)
+	}
+	if _, ok := quota.usage["ip:0"]; ok {
+		t.Error("licznik najdawniej aktywnego klienta powinien zostać usunięty")
+	}
+	if _, ok := quota.usage[fmt.Sprintf("ip:%d", maxQuotaClients-1)]; !ok {
+		t.Error("licznik ostatnio aktywnego klienta został usunięty")
+	}
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/models"
+)
+
+// ListReservations zwraca rezerwacje zalogowanego użytkownika (GET /api/v1/reservations?page=&per_page=)
+func (h *Handler) ListReservations(w http.ResponseWriter, r *http.Request) {
+	page, perPage := pagination(r)
+
+	reservations, err := h.fbClient.Traced(r.Context()).GetUserReservations(sessionFromContext(r.Context()).UserID)
+	if err != nil {
+		log.Printf("Błąd pobierania rezerwacji (API): %v", err)
+		writeError(w, http.StatusInternalServerError, "Błąd pobierania rezerwacji")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Page{Items: paginate(reservations, page, perPage), Page: page, PerPage: perPage, Total: len(reservations)})
+}
+
+// CreateReservation rezerwuje książkę (POST /api/v1/books/{id}/reservations)
+func (h *Handler) CreateReservation(w http.ResponseWriter, r *http.Request) {
+	sess := sessionFromContext(r.Context())
+	bookID := chi.URLParam(r, "id")
+
+	user, err := h.fbClient.Traced(r.Context()).GetUser(sess.UserID)
+	if err != nil {
+		log.Printf("Błąd pobierania użytkownika (API): %v", err)
+		writeError(w, http.StatusInternalServerError, "Błąd pobierania danych użytkownika")
+		return
+	}
+	if !user.IsActive {
+		writeError(w, http.StatusForbidden, "Konto nieaktywne - skontaktuj się z biblioteką")
+		return
+	}
+	if user.PendingApproval {
+		writeError(w, http.StatusForbidden, "Konto czeka na zatwierdzenie - zgłoś się do biblioteki z dokumentem tożsamości")
+		return
+	}
+
+	book, err := h.fbClient.Traced(r.Context()).GetBook(bookID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Książka nie została znaleziona")
+		return
+	}
+
+	existing, err := h.fbClient.Traced(r.Context()).GetUserActiveReservations(sess.UserID)
+	if err != nil {
+		log.Printf("Błąd pobierania rezerwacji (API): %v", err)
+		writeError(w, http.StatusInternalServerError, "Błąd pobierania rezerwacji")
+		return
+	}
+	for _, res := range existing {
+		if res.BookID == bookID {
+			writeError(w, http.StatusConflict, "Masz już aktywną rezerwację tej książki")
+			return
+		}
+	}
+
+	reservation := &models.Reservation{
+		BookID:     bookID,
+		UserID:     sess.UserID,
+		BookTitle:  book.Title,
+		UserName:   user.FullName(),
+		Status:     models.ReservationStatusPending,
+		ExpiryDate: time.Now().AddDate(0, 0, 7), // 7 dni na odbiór gdy będzie dostępna
+	}
+	if err := h.fbClient.Traced(r.Context()).CreateReservation(reservation); err != nil {
+		log.Printf("Błąd tworzenia rezerwacji (API): %v", err)
+		writeError(w, http.StatusInternalServerError, "Błąd rezerwacji książki")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, reservation)
+}
+
+// GetReservation zwraca rezerwację (GET /api/v1/reservations/{id}). Czytelnik widzi tylko własne.
+func (h *Handler) GetReservation(w http.ResponseWriter, r *http.Request) {
+	sess := sessionFromContext(r.Context())
+	reservation, err := h.fbClient.Traced(r.Context()).GetReservation(chi.URLParam(r, "id"))
+	if err != nil || (reservation.UserID != sess.UserID && !sess.User.IsStaff()) {
+		writeError(w, http.StatusNotFound, "Rezerwacja nie została znaleziona")
+		return
+	}
+	writeJSON(w, http.StatusOK, reservation)
+}
+
+// CancelReservation anuluje rezerwację użytkownika (DELETE /api/v1/reservations/{id})
+func (h *Handler) CancelReservation(w http.ResponseWriter, r *http.Request) {
+	reservationID := chi.URLParam(r, "id")
+
+	reservation, err := h.fbClient.Traced(r.Context()).GetReservation(reservationID)
+	if err != nil || reservation.UserID != sessionFromContext(r.Context()).UserID {
+		writeError(w, http.StatusNotFound, "Rezerwacja nie została znaleziona")
+		return
+	}
+
+	if err := h.fbClient.Traced(r.Context()).CancelReservation(reservationID); err != nil {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,312 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/models"
+)
+
+const stationKey contextKey = "selfcheck_station"
+
+// patronRequest to dane czytelnika odczytane przez stanowisko: numer karty i PIN
+// (wymagany, jeśli czytelnik go ustawił)
+type patronRequest struct {
+	CardNumber string `json:"card_number"`
+	PIN        string `json:"pin"`
+}
+
+// itemRequest to operacja na zeskanowanej książce - kod z etykiety albo ISBN.
+// Przy zwrocie karta czytelnika jest potrzebna tylko wtedy, gdy wypożyczonych jest kilka egzemplarzy.
+type itemRequest struct {
+	patronRequest
+	ItemCode string `json:"item_code"`
+}
+
+// patronStatus to podsumowanie konta czytelnika wyświetlane na ekranie stanowiska
+type patronStatus struct {
+	ID           string       `json:"id"`
+	Name         string       `json:"name"`
+	CanBorrow    bool         `json:"can_borrow"`
+	Message      string       `json:"message,omitempty"` // Powód, dla którego czytelnik nie może wypożyczać
+	CurrentLoans int          `json:"current_loans"`
+	MaxLoans     int          `json:"max_loans"`
+	TotalFines   models.Money `json:"total_fines"`
+	ReadyHolds   []string     `json:"ready_holds"` // Tytuły czekające na półce rezerwacji
+}
+
+// checkinResponse to wynik zwrotu na stanowisku
+type checkinResponse struct {
+	Loan *models.Loan `json:"loan"`
+	Fine models.Money `json:"fine"`
+	// Książka czeka na kolejnego czytelnika - automat powinien odłożyć ją do osobnego pojemnika
+	Hold bool `json:"hold"`
+}
+
+// finesResponse to kary czytelnika: do zapłaty i naliczane za trwające wypożyczenia po terminie
+type finesResponse struct {
+	TotalFines models.Money   `json:"total_fines"`
+	Accruing   []accruingFine `json:"accruing"`
+	Formatted  string         `json:"formatted"` // Suma kar do zapłaty w walucie biblioteki
+}
+
+type accruingFine struct {
+	LoanID    string       `json:"loan_id"`
+	BookTitle string       `json:"book_title"`
+	DueDate   time.Time    `json:"due_date"`
+	Fine      models.Money `json:"fine"`
+}
+
+// selfCheckRoutes rejestruje endpointy stanowisk samoobsługowych (/api/v1/selfcheck)
+func (h *Handler) selfCheckRoutes(r chi.Router) {
+	r.Use(h.requireStation)
+
+	r.Post("/patron", h.IdentifyPatron)
+	r.Post("/fines", h.PatronFines)
+	r.Post("/checkout", h.SelfCheckout)
+	r.Post("/checkin", h.SelfCheckin)
+}
+
+// IdentifyPatron rozpoznaje czytelnika po karcie i zwraca stan jego konta (POST /api/v1/selfcheck/patron)
+func (h *Handler) IdentifyPatron(w http.ResponseWriter, r *http.Request) {
+	var req patronRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Nieprawidłowe dane JSON")
+		return
+	}
+	user, ok := h.patron(w, r, req)
+	if !ok {
+		return
+	}
+
+	status := patronStatus{
+		ID:           user.ID,
+		Name:         user.FullName(),
+		CanBorrow:    user.CanBorrow(),
+		Message:      user.BorrowRefusal(),
+		CurrentLoans: user.CurrentLoans,
+		MaxLoans:     user.MaxLoans,
+		TotalFines:   user.TotalFines,
+		ReadyHolds:   []string{},
+	}
+	reservations, err := h.fbClient.Traced(r.Context()).GetUserActiveReservations(user.ID)
+	if err != nil {
+		log.Printf("Błąd pobierania rezerwacji (stanowisko): %v", err)
+	}
+	for _, reservation := range reservations {
+		if reservation.Status == models.ReservationStatusReady {
+			status.ReadyHolds = append(status.ReadyHolds, reservation.BookTitle)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+// PatronFines zwraca kary czytelnika (POST /api/v1/selfcheck/fines)
+func (h *Handler) PatronFines(w http.ResponseWriter, r *http.Request) {
+	var req patronRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Nieprawidłowe dane JSON")
+		return
+	}
+	user, ok := h.patron(w, r, req)
+	if !ok {
+		return
+	}
+
+	loans, err := h.fbClient.Traced(r.Context()).GetUserActiveLoans(user.ID)
+	if err != nil {
+		log.Printf("Błąd pobierania wypożyczeń (stanowisko): %v", err)
+		writeError(w, http.StatusInternalServerError, "Błąd pobierania wypożyczeń")
+		return
+	}
+
+	resp := finesResponse{TotalFines: user.TotalFines, Accruing: []accruingFine{}}
+	for _, loan := range loans {
+		// Część kary naliczona przez nocne zadanie jest już w saldzie czytelnika
+		if fine := loan.CalculateFine() - loan.FineAmount; fine > 0 {
+			resp.Accruing = append(resp.Accruing, accruingFine{LoanID: loan.ID, BookTitle: loan.BookTitle, DueDate: loan.DueDate, Fine: fine})
+		}
+	}
+	if settings, err := h.fbClient.Traced(r.Context()).GetSettings(); err == nil {
+		resp.Formatted = settings.FormatMoney(user.TotalFines)
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// SelfCheckout wypożycza zeskanowaną książkę czytelnikowi (POST /api/v1/selfcheck/checkout)
+func (h *Handler) SelfCheckout(w http.ResponseWriter, r *http.Request) {
+	var req itemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Nieprawidłowe dane JSON")
+		return
+	}
+	user, ok := h.patron(w, r, req.patronRequest)
+	if !ok {
+		return
+	}
+	if message := user.BorrowRefusal(); message != "" {
+		writeError(w, http.StatusConflict, message)
+		return
+	}
+	book, ok := h.item(w, r, req.ItemCode)
+	if !ok {
+		return
+	}
+
+	loan, err := h.fbClient.Traced(r.Context()).SelfCheckout(stationFromContext(r.Context()), user, book)
+	if err != nil {
+		log.Printf("Błąd wypożyczenia na stanowisku (książka %s, czytelnik %s): %v", book.ID, user.ID, err)
+		writeError(w, http.StatusConflict, "Nie można wypożyczyć tej książki - zgłoś się do wypożyczalni")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, loan)
+}
+
+// SelfCheckin przyjmuje zwrot zeskanowanej książki (POST /api/v1/selfcheck/checkin)
+func (h *Handler) SelfCheckin(w http.ResponseWriter, r *http.Request) {
+	var req itemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Nieprawidłowe dane JSON")
+		return
+	}
+	book, ok := h.item(w, r, req.ItemCode)
+	if !ok {
+		return
+	}
+
+	loans, err := h.fbClient.Traced(r.Context()).GetBookActiveLoans(book.ID)
+	if err != nil {
+		log.Printf("Błąd pobierania wypożyczeń książki (stanowisko): %v", err)
+		writeError(w, http.StatusInternalServerError, "Błąd pobierania wypożyczeń")
+		return
+	}
+	if len(loans) > 1 && req.CardNumber != "" {
+		user, ok := h.patron(w, r, req.patronRequest)
+		if !ok {
+			return
+		}
+		var own []*models.Loan
+		for _, loan := range loans {
+			if loan.UserID == user.ID {
+				own = append(own, loan)
+			}
+		}
+		loans = own
+	}
+
+	switch {
+	case len(loans) == 0:
+		writeError(w, http.StatusNotFound, "Ta książka nie jest wypożyczona")
+		return
+	case len(loans) > 1:
+		writeError(w, http.StatusConflict, "Wypożyczonych jest kilka egzemplarzy - zeskanuj kartę czytelnika")
+		return
+	}
+
+	result, err := h.fbClient.Traced(r.Context()).SelfCheckin(stationFromContext(r.Context()), loans[0].ID)
+	if err != nil {
+		log.Printf("Błąd zwrotu na stanowisku (wypożyczenie %s): %v", loans[0].ID, err)
+		writeError(w, http.StatusInternalServerError, "Błąd zwrotu - zgłoś się do wypożyczalni")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, checkinResponse{Loan: result.Loan, Fine: result.Fine, Hold: result.NextReservation != nil})
+}
+
+// patron rozpoznaje czytelnika po numerze karty i PIN-ie. Gdy się nie udało, wysyła odpowiedź z błędem.
+func (h *Handler) patron(w http.ResponseWriter, r *http.Request, req patronRequest) (*models.User, bool) {
+	number := strings.TrimSpace(req.CardNumber)
+	if number == "" {
+		writeError(w, http.StatusBadRequest, "Numer karty jest wymagany")
+		return nil, false
+	}
+
+	user, err := h.fbClient.Traced(r.Context()).GetUserByCardNumber(number)
+	if err != nil {
+		log.Printf("Błąd wyszukiwania czytelnika po karcie (stanowisko): %v", err)
+		writeError(w, http.StatusInternalServerError, "Błąd wyszukiwania czytelnika")
+		return nil, false
+	}
+	if user == nil {
+		writeError(w, http.StatusNotFound, "Nie znaleziono karty czytelnika")
+		return nil, false
+	}
+
+	if user.HasPIN() {
+		verified, err := h.fbClient.Traced(r.Context()).VerifyUserPIN(user.ID, req.PIN)
+		if err != nil {
+			log.Printf("Błąd weryfikacji PIN-u (stanowisko): %v", err)
+			writeError(w, http.StatusInternalServerError, "Błąd weryfikacji PIN-u")
+			return nil, false
+		}
+		if !verified {
+			writeError(w, http.StatusUnauthorized, "Nieprawidłowy PIN")
+			return nil, false
+		}
+	}
+
+	return user, true
+}
+
+// item szuka książki po kodzie z etykiety (także całym adresie z kodu QR), a następnie po ISBN.
+// Gdy się nie udało, wysyła odpowiedź z błędem.
+func (h *Handler) item(w http.ResponseWriter, r *http.Request, code string) (*models.Book, bool) {
+	if strings.TrimSpace(code) == "" {
+		writeError(w, http.StatusBadRequest, "Kod książki jest wymagany")
+		return nil, false
+	}
+
+	book, err := h.fbClient.Traced(r.Context()).FindBookByCode(code)
+	if err != nil {
+		log.Printf("Błąd wyszukiwania książki po kodzie (stanowisko): %v", err)
+		writeError(w, http.StatusInternalServerError, "Błąd wyszukiwania książki")
+		return nil, false
+	}
+	if book == nil {
+		writeError(w, http.StatusNotFound, "Nie rozpoznano książki - zgłoś się do wypożyczalni")
+		return nil, false
+	}
+
+	return book, true
+}
+
+// requireStation wymaga nagłówka "Authorization: Bearer <token stanowiska>"
+// z tokenem stanowiska samoobsługowego tej biblioteki
+func (h *Handler) requireStation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "Brak tokenu stanowiska")
+			return
+		}
+
+		station, err := h.fbClient.Traced(r.Context()).GetSelfCheckStationByToken(token)
+		if err != nil {
+			log.Printf("Błąd weryfikacji tokenu stanowiska: %v", err)
+			writeError(w, http.StatusInternalServerError, "Błąd weryfikacji tokenu stanowiska")
+			return
+		}
+		if station == nil {
+			writeError(w, http.StatusUnauthorized, "Token stanowiska jest nieprawidłowy")
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), stationKey, station)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// stationFromContext zwraca stanowisko ustawione przez requireStation
+func stationFromContext(ctx context.Context) *models.SelfCheckStation {
+	station, _ := ctx.Value(stationKey).(*models.SelfCheckStation)
+	return station
+}
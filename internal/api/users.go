@@ -0,0 +1,201 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+	"library-management-system/internal/session"
+)
+
+// userCreateRequest to dane nowego konta zakładanego przez API
+type userCreateRequest struct {
+	Email     string          `json:"email"`
+	Password  string          `json:"password"`
+	FirstName string          `json:"first_name"`
+	LastName  string          `json:"last_name"`
+	Phone     string          `json:"phone"`
+	Role      models.UserRole `json:"role"`
+	MaxLoans  int             `json:"max_loans"`
+}
+
+// validate sprawdza dane nowego konta i zwraca komunikat błędu (pusty, gdy dane są poprawne)
+func (req *userCreateRequest) validate() string {
+	if req.Email == "" || req.FirstName == "" || req.LastName == "" || req.Password == "" {
+		return "Imię, nazwisko, email i hasło są wymagane"
+	}
+	if len(req.Password) < 6 {
+		return "Hasło musi mieć minimum 6 znaków"
+	}
+	if req.MaxLoans < 0 {
+		return "Nieprawidłowa liczba maksymalnych wypożyczeń"
+	}
+	if req.Role == "" {
+		req.Role = models.RoleReader
+	}
+	for _, role := range models.Roles {
+		if req.Role == role {
+			return ""
+		}
+	}
+	return "Nieprawidłowa rola użytkownika"
+}
+
+// userUpdateRequest to pola konta, które personel może zmienić przez API.
+// Pominięte pole zostaje bez zmian.
+type userUpdateRequest struct {
+	MaxLoans *int  `json:"max_loans"`
+	IsActive *bool `json:"is_active"`
+}
+
+// ListUsers zwraca konta użytkowników (GET /api/v1/users?page=&per_page=, tylko personel)
+func (h *Handler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	page, perPage := pagination(r)
+
+	users, err := h.fbClient.Traced(r.Context()).ListUsers()
+	if err != nil {
+		log.Printf("Błąd pobierania użytkowników (API): %v", err)
+		writeError(w, http.StatusInternalServerError, "Błąd pobierania użytkowników")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Page{Items: paginate(users, page, perPage), Page: page, PerPage: perPage, Total: len(users)})
+}
+
+// GetUser zwraca konto użytkownika (GET /api/v1/users/{id}, tylko personel)
+func (h *Handler) GetUser(w http.ResponseWriter, r *http.Request) {
+	user, err := h.fbClient.Traced(r.Context()).GetUser(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Nie znaleziono użytkownika")
+		return
+	}
+	writeJSON(w, http.StatusOK, user)
+}
+
+// CreateUser zakłada konto w Firebase Auth i profil użytkownika (POST /api/v1/users, tylko administrator).
+// Konto założone przez personel nie czeka na zatwierdzenie tożsamości.
+func (h *Handler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var req userCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Nieprawidłowe dane JSON")
+		return
+	}
+	req.Email = strings.TrimSpace(req.Email)
+	req.FirstName = strings.TrimSpace(req.FirstName)
+	req.LastName = strings.TrimSpace(req.LastName)
+	if message := req.validate(); message != "" {
+		writeError(w, http.StatusUnprocessableEntity, message)
+		return
+	}
+
+	params := (&auth.UserToCreate{}).
+		Email(req.Email).
+		Password(req.Password).
+		DisplayName(req.FirstName + " " + req.LastName)
+	account, err := h.fbClient.Auth.CreateUser(r.Context(), params)
+	if auth.IsEmailAlreadyExists(err) {
+		writeError(w, http.StatusConflict, "Użytkownik z tym adresem email już istnieje")
+		return
+	}
+	if err != nil {
+		log.Printf("Błąd tworzenia użytkownika w Firebase Auth (API): %v", err)
+		writeError(w, http.StatusUnprocessableEntity, "Nie udało się utworzyć konta - sprawdź adres email i hasło")
+		return
+	}
+
+	user := &models.User{
+		FirebaseUID: account.UID,
+		Email:       req.Email,
+		FirstName:   req.FirstName,
+		LastName:    req.LastName,
+		Phone:       strings.TrimSpace(req.Phone),
+		Role:        req.Role,
+		MaxLoans:    req.MaxLoans,
+	}
+	if err := h.fbClient.Traced(r.Context()).CreateUser(user); err != nil {
+		log.Printf("Błąd tworzenia użytkownika w Firestore (API): %v", err)
+		// Konto bez profilu nie mogłoby się zalogować, a blokowałoby adres email
+		h.fbClient.Auth.DeleteUser(r.Context(), account.UID)
+		writeError(w, http.StatusInternalServerError, "Błąd tworzenia konta użytkownika")
+		return
+	}
+	log.Printf("Konto %s (%s) założone przez %s (API)", user.Email, user.Role, sessionFromContext(r.Context()).User.Email)
+
+	writeJSON(w, http.StatusCreated, user)
+}
+
+// UpdateUser zmienia limit wypożyczeń i aktywność konta (PUT /api/v1/users/{id}, tylko personel)
+func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "id")
+
+	var req userUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Nieprawidłowe dane JSON")
+		return
+	}
+	if req.MaxLoans != nil && *req.MaxLoans < 1 {
+		writeError(w, http.StatusUnprocessableEntity, "Nieprawidłowa liczba maksymalnych wypożyczeń")
+		return
+	}
+
+	user, err := h.fbClient.Traced(r.Context()).GetUser(userID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Nie znaleziono użytkownika")
+		return
+	}
+	if req.MaxLoans != nil {
+		user.MaxLoans = *req.MaxLoans
+	}
+	if req.IsActive != nil {
+		user.IsActive = *req.IsActive
+	}
+	user.UpdatedAt = time.Now()
+
+	audit, err := h.fbClient.Traced(r.Context()).BeginAudit(firebase.AuditDoc{Collection: firebase.UsersCollection, ID: userID})
+	if err != nil {
+		log.Printf("Błąd dziennika zmian przed edycją użytkownika %s (API): %v", userID, err)
+	}
+
+	if err := h.fbClient.Traced(r.Context()).UpdateUser(userID, user); err != nil {
+		log.Printf("Błąd aktualizacji użytkownika (API): %v", err)
+		writeError(w, http.StatusInternalServerError, "Błąd zapisywania zmian")
+		return
+	}
+	recordAudit(r, audit, models.AuditUserEdit, userID, user.FullName()+" ("+user.Email+")")
+	if !user.IsActive {
+		// Dezaktywowany użytkownik nie powinien dalej korzystać z wydanych tokenów
+		session.GetManager().DeleteUserSessions(userID)
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
+
+// DeleteUser przenosi konto czytelnika do kosza (DELETE /api/v1/users/{id}, tylko personel)
+func (h *Handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	sess := sessionFromContext(r.Context())
+
+	user, err := h.fbClient.Traced(r.Context()).GetUser(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Nie znaleziono użytkownika")
+		return
+	}
+
+	if err := h.fbClient.Traced(r.Context()).TrashUser(user.ID, sess.User.Email); err != nil {
+		log.Printf("Błąd usuwania konta %s (API): %v", user.ID, err)
+		writeError(w, http.StatusConflict, "Nie udało się usunąć konta: "+err.Error())
+		return
+	}
+
+	// Usunięty czytelnik nie powinien dalej korzystać z otwartej sesji
+	session.GetManager().DeleteUserSessions(user.ID)
+	log.Printf("Konto %s (%s) przeniesione do kosza przez %s (API)", user.Email, user.ID, sess.User.Email)
+
+	w.WriteHeader(http.StatusNoContent)
+}
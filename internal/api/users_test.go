@@ -0,0 +1,41 @@
+package api
+
+import (
+	"testing"
+
+	"library-management-system/internal/models"
+)
+
+func TestUserCreateRequestValidate(t *testing.T) {
+	valid := func() userCreateRequest {
+		return userCreateRequest{Email: "anna@example.com", Password: "sekret1", FirstName: "Anna", LastName: "Nowak"}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*userCreateRequest)
+		ok     bool
+	}{
+		{"czytelnik bez roli", func(*userCreateRequest) {}, true},
+		{"bibliotekarz", func(req *userCreateRequest) { req.Role = models.RoleLibrarian }, true},
+		{"brak emaila", func(req *userCreateRequest) { req.Email = "" }, false},
+		{"krótkie hasło", func(req *userCreateRequest) { req.Password = "abc" }, false},
+		{"ujemny limit", func(req *userCreateRequest) { req.MaxLoans = -1 }, false},
+		{"nieznana rola", func(req *userCreateRequest) { req.Role = "superuser" }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid()
+			tt.modify(&req)
+			if message := req.validate(); (message == "") != tt.ok {
+				t.Errorf("validate() = %q, oczekiwano poprawnych danych: %v", message, tt.ok)
+			}
+		})
+	}
+
+	req := valid()
+	if req.validate(); req.Role != models.RoleReader {
+		t.Errorf("domyślna rola = %q, oczekiwano %q", req.Role, models.RoleReader)
+	}
+}
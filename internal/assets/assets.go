@@ -0,0 +1,115 @@
+// Package assets serwuje pliki statyczne pod nazwami z hashem treści
+// (np. css/app.3f2a9c1b0d.css), dzięki czemu mogą być cache'owane bezterminowo,
+// a po wdrożeniu nowej wersji przeglądarki pobiorą zmienione pliki.
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+
+	"library-management-system/internal/basepath"
+)
+
+// hashLength to liczba znaków hasha w nazwie pliku
+const hashLength = 10
+
+// Manifest mapuje nazwy plików na nazwy z hashem treści
+type Manifest struct {
+	fsys     fs.FS
+	hashed   map[string]string // css/app.css -> css/app.3f2a9c1b0d.css
+	original map[string]string // css/app.3f2a9c1b0d.css -> css/app.css
+	etags    map[string]string // css/app.css -> "3f2a9c1b0d"
+}
+
+var (
+	defaultMu       sync.RWMutex
+	defaultManifest *Manifest
+)
+
+// Load buduje manifest z systemu plików (wywoływane przy starcie serwera)
+// i ustawia go jako domyślny dla funkcji Path
+func Load(fsys fs.FS) (*Manifest, error) {
+	m := &Manifest{
+		fsys:     fsys,
+		hashed:   make(map[string]string),
+		original: make(map[string]string),
+		etags:    make(map[string]string),
+	}
+
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		hash := hex.EncodeToString(sum[:])[:hashLength]
+
+		ext := path.Ext(name)
+		hashedName := strings.TrimSuffix(name, ext) + "." + hash + ext
+
+		m.hashed[name] = hashedName
+		m.original[hashedName] = name
+		m.etags[name] = `"` + hash + `"`
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("błąd budowania manifestu plików statycznych: %w", err)
+	}
+
+	defaultMu.Lock()
+	defaultManifest = m
+	defaultMu.Unlock()
+
+	return m, nil
+}
+
+// Path zwraca adres pliku statycznego z hashem treści (do użycia w szablonach).
+// Nieznane pliki i brak manifestu dają zwykły adres /static/<name>.
+func Path(name string) string {
+	defaultMu.RLock()
+	m := defaultManifest
+	defaultMu.RUnlock()
+
+	if m != nil {
+		if hashed, ok := m.hashed[name]; ok {
+			return basepath.URL("/static/" + hashed)
+		}
+	}
+	return basepath.URL("/static/" + name)
+}
+
+// Handler serwuje pliki z manifestu (montowany pod /static/ z usuniętym prefiksem).
+// Nazwy z hashem dostają bezterminowy cache, zwykłe nazwy - krótki cache z ETagiem.
+func (m *Manifest) Handler() http.Handler {
+	fileServer := http.FileServer(http.FS(m.fsys))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/")
+
+		if original, ok := m.original[name]; ok {
+			w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+			w.Header().Set("ETag", m.etags[original])
+
+			r = r.Clone(r.Context())
+			r.URL.Path = "/" + original
+			fileServer.ServeHTTP(w, r)
+			return
+		}
+
+		if etag, ok := m.etags[name]; ok {
+			w.Header().Set("Cache-Control", "public, max-age=3600")
+			w.Header().Set("ETag", etag)
+		}
+		fileServer.ServeHTTP(w, r)
+	})
+}
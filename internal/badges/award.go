@@ -0,0 +1,117 @@
+// Package badges przyznaje czytelnikom odznaki za czytanie (pierwsze wypożyczenie,
+// dziesięć książek, różne kategorie, rok bez spóźnień). Warunki odznak określa katalog
+// prowadzony przez personel, a przyznawaniem zajmuje się nocne zadanie - dzięki temu
+// wypożyczanie i zwroty nie liczą statystyk czytelnika przy każdym żądaniu.
+package badges
+
+import (
+	"log"
+	"time"
+
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+)
+
+// Award przelicza statystyki wszystkich czytelników biblioteki i dopisuje im
+// zdobyte odznaki z aktywnego katalogu. Zwraca liczbę przyznanych odznak.
+// Historia wypożyczeń jest czytana jednym zapytaniem na przebieg.
+func Award(c *firebase.Client, now time.Time) (int, error) {
+	catalog, err := c.ListBadges()
+	if err != nil {
+		return 0, err
+	}
+	var active []*models.Badge
+	for _, badge := range catalog {
+		if badge.Active {
+			active = append(active, badge)
+		}
+	}
+	if len(active) == 0 {
+		return 0, nil
+	}
+
+	loans, err := c.ListLoans()
+	if err != nil {
+		return 0, err
+	}
+	bookIDs := make([]string, 0, len(loans))
+	for _, loan := range loans {
+		bookIDs = append(bookIDs, loan.BookID)
+	}
+	books, err := c.GetBooksByIDs(bookIDs)
+	if err != nil {
+		return 0, err
+	}
+	stats := readerStats(loans, books, now)
+
+	users, err := c.ListUsers()
+	if err != nil {
+		return 0, err
+	}
+
+	awarded := 0
+	for _, user := range users {
+		userStats, ok := stats[user.ID]
+		if !ok || user.BadgesOptOut {
+			continue
+		}
+
+		var earned []models.EarnedBadge
+		for _, badge := range active {
+			if !user.HasBadge(badge.ID) && badge.EarnedBy(userStats, now) {
+				earned = append(earned, models.EarnedBadge{BadgeID: badge.ID, AwardedAt: now})
+			}
+		}
+		if err := c.AwardBadges(user.ID, earned); err != nil {
+			log.Printf("Błąd przyznawania odznak czytelnikowi %s: %v", user.ID, err)
+			continue
+		}
+		awarded += len(earned)
+	}
+
+	return awarded, nil
+}
+
+// readerStats podsumowuje historię wypożyczeń każdego czytelnika. Udostępnienia
+// na miejscu i zamówienia nieodebrane nie liczą się do odznak.
+func readerStats(loans []*models.Loan, books map[string]*models.Book, now time.Time) map[string]*models.ReaderStats {
+	stats := make(map[string]*models.ReaderStats)
+	categories := make(map[string]map[string]bool)
+
+	for _, loan := range loans {
+		if loan.IsReadingRoom() || loan.Status == models.LoanStatusPendingPickup {
+			continue
+		}
+
+		s, ok := stats[loan.UserID]
+		if !ok {
+			s = &models.ReaderStats{}
+			stats[loan.UserID] = s
+			categories[loan.UserID] = make(map[string]bool)
+		}
+
+		s.Loans++
+		if s.FirstLoan.IsZero() || loan.LoanDate.Before(s.FirstLoan) {
+			s.FirstLoan = loan.LoanDate
+		}
+		if book, ok := books[loan.BookID]; ok && book.Category != "" {
+			categories[loan.UserID][book.Category] = true
+		}
+
+		// Spóźnienie liczy się od zwrotu po terminie, a przy trwającym - do dziś
+		var overdue time.Time
+		if loan.ReturnDate != nil && loan.ReturnDate.After(loan.DueDate) {
+			overdue = *loan.ReturnDate
+		} else if loan.ReturnDate == nil && now.After(loan.DueDate) {
+			overdue = now
+		}
+		if overdue.After(s.LastOverdue) {
+			s.LastOverdue = overdue
+		}
+	}
+
+	for userID, s := range stats {
+		s.Categories = len(categories[userID])
+	}
+	return stats
+}
@@ -0,0 +1,90 @@
+package barcode
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// Kody kreskowe Code 128 (zestaw znaków B) do etykiet książek. Zestaw B obejmuje
+// drukowalne znaki ASCII, czyli m.in. krótkie kody książek - czytnik przy ladzie
+// wpisuje zeskanowany kod tak samo jak ręcznie wpisany.
+
+// patterns to szerokości kolejnych kresek i przerw (w modułach) dla wartości 0-105
+var patterns = [...]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232",
+}
+
+const (
+	startB     = 104
+	stopWidths = "2331112"
+)
+
+// QuietZone to wymagany margines po obu stronach kodu (w modułach)
+const QuietZone = 10
+
+// Code128 koduje tekst i zwraca szerokości kolejnych kresek i przerw w modułach,
+// zaczynając od kreski (bez marginesów)
+func Code128(text string) ([]int, error) {
+	if text == "" {
+		return nil, fmt.Errorf("pusty tekst kodu kreskowego")
+	}
+
+	values := []int{startB}
+	checksum := startB
+	for i, r := range text {
+		if r < 32 || r > 127 {
+			return nil, fmt.Errorf("znak %q nie jest obsługiwany w kodzie kreskowym", r)
+		}
+		value := int(r) - 32
+		values = append(values, value)
+		checksum += (i + 1) * value
+	}
+	values = append(values, checksum%103)
+
+	var widths []int
+	for _, value := range values {
+		widths = appendWidths(widths, patterns[value])
+	}
+	return appendWidths(widths, stopWidths), nil
+}
+
+func appendWidths(widths []int, pattern string) []int {
+	for _, c := range pattern {
+		widths = append(widths, int(c-'0'))
+	}
+	return widths
+}
+
+// SVG zwraca kod kreskowy jako obraz SVG skalowany do szerokości kontenera.
+// Dla tekstu, którego nie da się zakodować, zwraca pusty ciąg.
+func SVG(text string) template.HTML {
+	widths, err := Code128(text)
+	if err != nil {
+		return ""
+	}
+
+	var bars strings.Builder
+	x := QuietZone
+	for i, w := range widths {
+		if i%2 == 0 {
+			fmt.Fprintf(&bars, `<rect x="%d" width="%d" height="40"/>`, x, w)
+		}
+		x += w
+	}
+	x += QuietZone
+
+	return template.HTML(fmt.Sprintf(
+		`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d 40" preserveAspectRatio="none" class="w-full h-full" role="img" aria-label="%s">%s</svg>`,
+		x, template.HTMLEscapeString(text), bars.String()))
+}
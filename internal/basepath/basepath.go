@@ -0,0 +1,50 @@
+// Package basepath przechowuje prefiks URL, pod którym działa aplikacja
+// (np. /biblioteka za reverse proxy). Wszystkie linki, przekierowania i cookie
+// budowane są przez URL, więc aplikacja działa zarówno pod / jak i pod prefiksem.
+package basepath
+
+import (
+	"net/http"
+	"strings"
+)
+
+// prefix bez końcowego ukośnika ("" gdy aplikacja działa w katalogu głównym)
+var prefix string
+
+// Set ustawia prefiks (wywoływane przy starcie serwera). "/", "" oraz "/biblioteka/"
+// są normalizowane do odpowiednio "" i "/biblioteka".
+func Set(p string) {
+	p = strings.Trim(strings.TrimSpace(p), "/")
+	if p == "" {
+		prefix = ""
+		return
+	}
+	prefix = "/" + p
+}
+
+// Prefix zwraca ustawiony prefiks ("" gdy aplikacja działa w katalogu głównym)
+func Prefix() string {
+	return prefix
+}
+
+// URL dodaje prefiks do lokalnej ścieżki (zaczynającej się od "/").
+// Pozostałe adresy (np. pełne URL-e zewnętrzne) są zwracane bez zmian.
+func URL(path string) string {
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") {
+		return path
+	}
+	return prefix + path
+}
+
+// CookiePath zwraca ścieżkę dla cookie aplikacji
+func CookiePath() string {
+	if prefix == "" {
+		return "/"
+	}
+	return prefix
+}
+
+// Redirect przekierowuje na lokalną ścieżkę z uwzględnieniem prefiksu
+func Redirect(w http.ResponseWriter, r *http.Request, path string, code int) {
+	http.Redirect(w, r, URL(path), code)
+}
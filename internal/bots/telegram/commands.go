@@ -0,0 +1,208 @@
+package telegram
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"library-management-system/internal/models"
+)
+
+// maxSearchResults ogranicza liczbę książek w odpowiedzi na /search
+const maxSearchResults = 5
+
+const helpText = `Polecenia:
+/search <fraza> - szukaj w katalogu
+/myloans - moje wypożyczenia
+/renew <numer> - przedłuż wypożyczenie (numer z /myloans)
+/reservations - moje rezerwacje
+/stop - odłącz ten czat od konta`
+
+const notLinkedText = "Ten czat nie jest połączony z kontem biblioteki. " +
+	"Połącz go na stronie Moje konto → Powiadomienia → Telegram."
+
+// handle odpowiada na wiadomość z czatu
+func (b *Bot) handle(chatID int64, text string) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return
+	}
+	// W poleceniach wybranych z menu Telegram może dopisać nazwę bota: /search@nazwa_bota
+	command, _, _ := strings.Cut(fields[0], "@")
+	args := strings.Join(fields[1:], " ")
+
+	var reply string
+	if command == "/start" && args != "" {
+		reply = b.link(chatID, args)
+	} else {
+		reply = b.run(chatID, command, args)
+	}
+
+	if err := b.Send(chatID, reply); err != nil {
+		log.Printf("Błąd wysyłania odpowiedzi bota: %v", err)
+	}
+}
+
+// link łączy czat z kontem kodem z linku t.me/<bot>?start=<kod>
+func (b *Bot) link(chatID int64, code string) string {
+	link, err := b.root.LinkTelegramChat(code, chatID)
+	if err != nil {
+		log.Printf("Błąd łączenia czatu Telegrama: %v", err)
+		return "Nie udało się połączyć konta: " + err.Error()
+	}
+
+	name := "biblioteki"
+	if lib := b.library(link.Tenant); lib != nil {
+		if settings, err := lib.fbClient.GetSettings(); err == nil {
+			name = settings.LibraryName
+		}
+	}
+	return "Połączono z kontem w: " + name + ". Będziesz tu dostawać powiadomienia.\n\n" + helpText
+}
+
+// run wykonuje polecenie w imieniu czytelnika połączonego z czatem
+func (b *Bot) run(chatID int64, command, args string) string {
+	link, err := b.root.GetTelegramLink(chatID)
+	if err != nil {
+		log.Printf("Błąd pobierania połączenia z Telegramem: %v", err)
+		return "Wystąpił błąd. Spróbuj ponownie za chwilę."
+	}
+	if link == nil {
+		return notLinkedText
+	}
+	lib := b.library(link.Tenant)
+	if lib == nil {
+		return "Biblioteka tego konta jest niedostępna."
+	}
+	user, err := lib.fbClient.GetUser(link.UserID)
+	if err != nil || user.TelegramChatID != chatID {
+		return notLinkedText
+	}
+
+	switch command {
+	case "/search":
+		return lib.search(args)
+	case "/myloans":
+		return lib.myLoans(user)
+	case "/renew":
+		return lib.renew(user, args)
+	case "/reservations":
+		return lib.reservations(user)
+	case "/stop":
+		if err := lib.fbClient.UnlinkTelegram(user.ID); err != nil {
+			log.Printf("Błąd odłączania Telegrama: %v", err)
+			return "Nie udało się odłączyć czatu."
+		}
+		return "Czat został odłączony od konta. Powiadomienia nie będą tu już wysyłane."
+	default:
+		return helpText
+	}
+}
+
+func (lib *library) search(query string) string {
+	if query == "" {
+		return "Podaj frazę, np. /search Lalka"
+	}
+	if err := lib.index.Refresh(); err != nil {
+		log.Printf("Błąd budowania indeksu wyszukiwania: %v", err)
+		return "Wyszukiwanie jest chwilowo niedostępne."
+	}
+
+	books := lib.index.Search(query).Books
+	if len(books) == 0 {
+		return "Nie znaleziono książek dla: " + query
+	}
+	if len(books) > maxSearchResults {
+		books = books[:maxSearchResults]
+	}
+
+	var sb strings.Builder
+	for _, book := range books {
+		fmt.Fprintf(&sb, "• %s - %s\n%s%s\n", book.Title, book.Subtitle, lib.baseURL, book.URL)
+	}
+	return sb.String()
+}
+
+// loans zwraca wypożyczenia czytelnika w kolejności terminów - numeracja z /myloans
+// jest używana przez /renew
+func (lib *library) loans(user *models.User) ([]*models.Loan, error) {
+	loans, err := lib.fbClient.GetUserActiveLoans(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(loans, func(i, j int) bool {
+		return loans[i].DueDate.Before(loans[j].DueDate)
+	})
+	return loans, nil
+}
+
+func (lib *library) myLoans(user *models.User) string {
+	loans, err := lib.loans(user)
+	if err != nil {
+		log.Printf("Błąd pobierania wypożyczeń: %v", err)
+		return "Nie udało się pobrać wypożyczeń."
+	}
+	if len(loans) == 0 {
+		return "Nie masz wypożyczonych książek."
+	}
+
+	var sb strings.Builder
+	for i, loan := range loans {
+		switch {
+		case loan.Status == models.LoanStatusPendingPickup:
+			fmt.Fprintf(&sb, "%d. %s - czeka na odbiór, kod: %s\n", i+1, loan.BookTitle, loan.PickupCode)
+		case loan.IsOverdue():
+			fmt.Fprintf(&sb, "%d. %s - termin minął %s!\n", i+1, loan.BookTitle, loan.DueDate.Format("02.01.2006"))
+		default:
+			fmt.Fprintf(&sb, "%d. %s - zwrot do %s\n", i+1, loan.BookTitle, loan.DueDate.Format("02.01.2006"))
+		}
+	}
+	sb.WriteString("\nAby przedłużyć, wyślij /renew <numer>.")
+	return sb.String()
+}
+
+func (lib *library) renew(user *models.User, args string) string {
+	loans, err := lib.loans(user)
+	if err != nil {
+		log.Printf("Błąd pobierania wypożyczeń: %v", err)
+		return "Nie udało się pobrać wypożyczeń."
+	}
+
+	n, err := strconv.Atoi(args)
+	if err != nil || n < 1 || n > len(loans) {
+		return "Podaj numer wypożyczenia z listy /myloans, np. /renew 1"
+	}
+
+	loan, err := lib.fbClient.RenewLoan(loans[n-1].ID, user.ID)
+	if err != nil {
+		return "Nie można przedłużyć: " + err.Error()
+	}
+	return "Przedłużono: " + loan.BookTitle + ". Nowy termin zwrotu: " + loan.DueDate.Format("02.01.2006") + "."
+}
+
+func (lib *library) reservations(user *models.User) string {
+	reservations, err := lib.fbClient.GetUserActiveReservations(user.ID)
+	if err != nil {
+		log.Printf("Błąd pobierania rezerwacji: %v", err)
+		return "Nie udało się pobrać rezerwacji."
+	}
+	if len(reservations) == 0 {
+		return "Nie masz aktywnych rezerwacji."
+	}
+
+	var sb strings.Builder
+	for _, r := range reservations {
+		if r.Status == models.ReservationStatusReady {
+			location := r.PickupLocation
+			if location == "" {
+				location = "wypożyczalnia"
+			}
+			fmt.Fprintf(&sb, "• %s - gotowa do odbioru do %s (%s)\n", r.BookTitle, r.ExpiryDate.Format("02.01.2006"), location)
+		} else {
+			fmt.Fprintf(&sb, "• %s - w kolejce\n", r.BookTitle)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,197 @@
+// Package telegram to opcjonalny bot Telegrama dla czytelników. Czytelnik łączy czat
+// z kontem jednorazowym linkiem ze strony konta, a potem dostaje w nim powiadomienia
+// i może sprawdzić wypożyczenia, przedłużyć je, przejrzeć rezerwacje i przeszukać katalog.
+// Bot jest jeden dla całej sieci bibliotek i pobiera wiadomości long pollingiem
+// (getUpdates), więc nie potrzebuje publicznego adresu webhooka.
+package telegram
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/search"
+)
+
+// apiURL to adres Bot API Telegrama
+const apiURL = "https://api.telegram.org/bot"
+
+// pollTimeout to czas, przez jaki getUpdates czeka na nowe wiadomości
+const pollTimeout = 50
+
+// Config to ustawienia bota
+type Config struct {
+	Token string // Token od @BotFather
+}
+
+// ConfigFromEnv wczytuje token bota z TELEGRAM_BOT_TOKEN. Bez tokenu bot jest wyłączony (nil).
+func ConfigFromEnv() *Config {
+	token := os.Getenv("TELEGRAM_BOT_TOKEN")
+	if token == "" {
+		return nil
+	}
+	return &Config{Token: token}
+}
+
+// library to biblioteka sieci obsługiwana przez bota
+type library struct {
+	fbClient *firebase.Client
+	index    *search.Index
+	baseURL  string
+}
+
+// Bot odbiera polecenia czytelników i wysyła im powiadomienia
+type Bot struct {
+	cfg      *Config
+	root     *firebase.Client // Klient biblioteki głównej - połączenia czatów leżą w katalogu głównym bazy
+	username string
+	http     *http.Client
+
+	mu        sync.RWMutex
+	libraries map[string]*library // Biblioteki sieci według ID (pusty = biblioteka główna)
+}
+
+// NewBot łączy się z Bot API i pobiera nazwę bota potrzebną do linków t.me
+func NewBot(cfg *Config, root *firebase.Client) (*Bot, error) {
+	b := &Bot{
+		cfg:       cfg,
+		root:      root,
+		http:      &http.Client{Timeout: (pollTimeout + 10) * time.Second},
+		libraries: make(map[string]*library),
+	}
+
+	var me struct {
+		Username string `json:"username"`
+	}
+	if err := b.call("getMe", map[string]interface{}{}, &me); err != nil {
+		return nil, err
+	}
+	b.username = me.Username
+
+	// Menu poleceń w aplikacji Telegram - bez niego bot też działa
+	commands := []map[string]string{
+		{"command": "search", "description": "Szukaj w katalogu"},
+		{"command": "myloans", "description": "Moje wypożyczenia"},
+		{"command": "renew", "description": "Przedłuż wypożyczenie"},
+		{"command": "reservations", "description": "Moje rezerwacje"},
+		{"command": "stop", "description": "Odłącz czat od konta"},
+	}
+	if err := b.call("setMyCommands", map[string]interface{}{"commands": commands}, nil); err != nil {
+		log.Printf("Błąd ustawiania menu poleceń bota: %v", err)
+	}
+	return b, nil
+}
+
+// Username zwraca nazwę bota (bez @)
+func (b *Bot) Username() string {
+	return b.username
+}
+
+// AddLibrary udostępnia botowi bibliotekę sieci: jej bazę, indeks wyszukiwania
+// i publiczny adres (do linków w odpowiedziach)
+func (b *Bot) AddLibrary(fbClient *firebase.Client, index *search.Index, baseURL string) {
+	b.mu.Lock()
+	b.libraries[fbClient.Tenant()] = &library{
+		fbClient: fbClient,
+		index:    index,
+		baseURL:  strings.TrimRight(baseURL, "/"),
+	}
+	b.mu.Unlock()
+}
+
+func (b *Bot) library(tenant string) *library {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.libraries[tenant]
+}
+
+// Start uruchamia odbieranie wiadomości w tle
+func (b *Bot) Start() {
+	go b.poll()
+	log.Printf("Bot Telegrama @%s uruchomiony", b.username)
+}
+
+// Send wysyła wiadomość tekstową na czat
+func (b *Bot) Send(chatID int64, text string) error {
+	return b.call("sendMessage", map[string]interface{}{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	}, nil)
+}
+
+type update struct {
+	UpdateID int64 `json:"update_id"`
+	Message  *struct {
+		Text string `json:"text"`
+		Chat struct {
+			ID   int64  `json:"id"`
+			Type string `json:"type"`
+		} `json:"chat"`
+	} `json:"message"`
+}
+
+// poll pobiera wiadomości w pętli. Po błędzie (np. braku sieci) czeka chwilę i próbuje ponownie.
+func (b *Bot) poll() {
+	var offset int64
+	for {
+		var updates []update
+		err := b.call("getUpdates", map[string]interface{}{
+			"offset":          offset,
+			"timeout":         pollTimeout,
+			"allowed_updates": []string{"message"},
+		}, &updates)
+		if err != nil {
+			log.Printf("Błąd pobierania wiadomości Telegrama: %v", err)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+
+		for _, u := range updates {
+			offset = u.UpdateID + 1
+			// Tylko prywatne czaty - w grupie powiadomienia czytelnika zobaczyliby inni
+			if u.Message == nil || u.Message.Chat.Type != "private" {
+				continue
+			}
+			b.handle(u.Message.Chat.ID, u.Message.Text)
+		}
+	}
+}
+
+// call wywołuje metodę Bot API i dekoduje pole result odpowiedzi do result (może być nil)
+func (b *Bot) call(method string, params map[string]interface{}, result interface{}) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	resp, err := b.http.Post(apiURL+b.cfg.Token+"/"+method, "application/json", bytes.NewReader(body))
+	if err != nil {
+		// Adres zawiera token - nie może trafić do logów
+		return fmt.Errorf("błąd połączenia z Telegramem (%s)", method)
+	}
+	defer resp.Body.Close()
+
+	var reply struct {
+		OK          bool            `json:"ok"`
+		Description string          `json:"description"`
+		Result      json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return fmt.Errorf("nieprawidłowa odpowiedź Telegrama (%s): %w", method, err)
+	}
+	if !reply.OK {
+		return fmt.Errorf("błąd Telegrama (%s): %s", method, reply.Description)
+	}
+	if result != nil {
+		return json.Unmarshal(reply.Result, result)
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+// Package covers pobiera okładki książek z zewnętrznych adresów (CoverImageURL),
+// zmniejsza je i przechowuje na dysku. Katalog serwuje okładki z własnej domeny
+// (bez mieszanej treści HTTP na stronach HTTPS i bez czekania na obce serwery),
+// a każdy zewnętrzny adres jest pobierany tylko raz.
+package covers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	// Formaty okładek obsługiwane przy dekodowaniu
+	_ "image/gif"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// fetchTimeout ogranicza pobieranie okładki z zewnętrznego serwera
+	fetchTimeout = 8 * time.Second
+	// maxSourceSize to największy przyjmowany plik okładki
+	maxSourceSize = 8 << 20
+	// maxSourcePixels chroni przed obrazami, które po zdekodowaniu zajęłyby setki MB
+	maxSourcePixels = 40_000_000
+	// failureTTL to czas, przez który nieudane pobranie nie jest ponawiane
+	failureTTL  = 15 * time.Minute
+	jpegQuality = 82
+)
+
+// Widths to szerokości okładek w pikselach dostępne w parametrze w adresu /covers/{id}
+var Widths = []int{160, 320, 640}
+
+// DefaultWidth to szerokość okładki, gdy adres jej nie podaje
+const DefaultWidth = 320
+
+// ValidWidth sprawdza czy szerokość jest jedną z dostępnych
+func ValidWidth(width int) bool {
+	for _, w := range Widths {
+		if w == width {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrUnavailable oznacza, że okładki nie udało się pobrać lub odczytać
+var ErrUnavailable = errors.New("okładka niedostępna")
+
+// CacheDirFromEnv zwraca katalog pamięci okładek z COVER_CACHE_DIR (domyślnie cache/covers)
+func CacheDirFromEnv() string {
+	if dir := os.Getenv("COVER_CACHE_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join("cache", "covers")
+}
+
+// Store pobiera okładki i przechowuje zmniejszone kopie w katalogu na dysku.
+// Pliki są nazwane skrótem adresu źródłowego, więc zmiana CoverImageURL książki
+// prowadzi do nowego pliku, a wspólny adres kilku wydań (lub bibliotek sieci) - do jednego.
+type Store struct {
+	dir   string
+	http  *http.Client
+	group singleflight.Group
+
+	mu       sync.Mutex
+	failures map[string]time.Time // Adres źródłowy -> czas nieudanego pobrania
+}
+
+// NewStore tworzy pamięć okładek w katalogu dir
+func NewStore(dir string) *Store {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, Control: publicOnly}
+	transport := &http.Transport{
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: fetchTimeout,
+		MaxIdleConnsPerHost:   4,
+		IdleConnTimeout:       90 * time.Second,
+	}
+	return &Store{
+		dir:      dir,
+		http:     &http.Client{Timeout: fetchTimeout, Transport: transport},
+		failures: make(map[string]time.Time),
+	}
+}
+
+// Key zwraca identyfikator okładki z adresu source w szerokości width (nazwa pliku i ETag)
+func Key(source string, width int) string {
+	sum := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(sum[:16]) + "-" + strconv.Itoa(width)
+}
+
+// Image zwraca okładkę z adresu source zmniejszoną do szerokości width jako JPEG.
+// Okładka jest pobierana przy pierwszym żądaniu, kolejne czytają plik z dysku.
+// Po nieudanym pobraniu przez failureTTL zwraca od razu ErrUnavailable.
+func (s *Store) Image(source string, width int) ([]byte, error) {
+	path := filepath.Join(s.dir, Key(source, width)+".jpg")
+	if data, err := os.ReadFile(path); err == nil {
+		return data, nil
+	}
+
+	s.mu.Lock()
+	failed, ok := s.failures[source]
+	if ok && time.Since(failed) > failureTTL {
+		delete(s.failures, source)
+		ok = false
+	}
+	s.mu.Unlock()
+	if ok {
+		return nil, ErrUnavailable
+	}
+
+	// Równoczesne żądania tej samej okładki (np. lista z wieloma wydaniami) pobierają ją raz
+	data, err, _ := s.group.Do(path, func() (any, error) {
+		data, err := s.fetch(source, width)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.save(path, data); err != nil {
+			// Okładkę i tak można wysłać - zapisze się przy następnym żądaniu
+			log.Printf("Błąd zapisu okładki %s: %v", path, err)
+		}
+		return data, nil
+	})
+	if err != nil {
+		s.mu.Lock()
+		s.failures[source] = time.Now()
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return data.([]byte), nil
+}
+
+// fetch pobiera obraz z adresu source i zmniejsza go do szerokości width
+func (s *Store) fetch(source string, width int) ([]byte, error) {
+	u, err := url.Parse(source)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("nieobsługiwany adres okładki %q", source)
+	}
+
+	// Pobranie nie zależy od żądania, które je rozpoczęło - wynik trafia do pamięci dla wszystkich
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "image/webp,image/jpeg,image/png,image/gif;q=0.8")
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("serwer okładki odpowiedział %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSourceSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxSourceSize {
+		return nil, fmt.Errorf("okładka większa niż %d MB", maxSourceSize>>20)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("nieobsługiwany format okładki: %w", err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxSourcePixels {
+		return nil, fmt.Errorf("nieprawidłowy rozmiar okładki %dx%d", cfg.Width, cfg.Height)
+	}
+	src, _, err := image.Decode(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("błąd dekodowania okładki: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, resize(src, width), &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// resize zmniejsza obraz do szerokości width z zachowaniem proporcji (mniejszych nie powiększa).
+// Przezroczyste tło (PNG, GIF) staje się białe, bo JPEG nie ma kanału alfa.
+func resize(src image.Image, width int) image.Image {
+	bounds := src.Bounds()
+	if bounds.Dx() < width {
+		width = bounds.Dx()
+	}
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+	return dst
+}
+
+// save zapisuje okładkę atomowo (plik tymczasowy i zmiana nazwy), żeby równoległy
+// odczyt nie trafił na niepełny plik
+func (s *Store) save(path string, data []byte) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.dir, ".cover-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// publicOnly nie pozwala łączyć się z adresami sieci wewnętrznej - adres okładki
+// wpisuje personel, ale nie może on posłużyć do odpytywania usług serwera (np. metadanych chmury)
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsUnspecified() || ip.IsMulticast() {
+		return fmt.Errorf("adres %s nie jest publiczny", host)
+	}
+	return nil
+}
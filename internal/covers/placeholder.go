@@ -0,0 +1,292 @@
+package covers
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Okładka zastępcza to tytuł i autor na kolorowym tle. Kolor i podział na wiersze zależą
+// tylko od tytułu i autora, więc ta sama książka ma zawsze tę samą okładkę, a wersje
+// SVG i PNG wyglądają tak samo (wiersze są dzielone według metryk fontów Go).
+
+// Układ okładki zastępczej w pikselach dla szerokości bazowej (PNG jest skalowany)
+const (
+	placeholderWidth  = 320
+	placeholderHeight = 480
+	placeholderMargin = 32
+	titleSize         = 30
+	titleLineHeight   = 38
+	titleTop          = 72 // Górna krawędź pierwszego wiersza tytułu
+	maxTitleLines     = 6
+	authorSize        = 20
+	authorLineHeight  = 26
+	authorBottom      = 420 // Linia bazowa ostatniego wiersza autora
+	maxAuthorLines    = 2
+)
+
+// placeholderColors to stonowane kolory tła, na których biały tekst jest czytelny
+var placeholderColors = []color.RGBA{
+	{0x1f, 0x3a, 0x5f, 0xff}, // granatowy
+	{0x7c, 0x2d, 0x12, 0xff}, // ceglasty
+	{0x14, 0x53, 0x2d, 0xff}, // butelkowa zieleń
+	{0x4c, 0x1d, 0x95, 0xff}, // fiolet
+	{0x83, 0x18, 0x43, 0xff}, // bordo
+	{0x0f, 0x4c, 0x5c, 0xff}, // morski
+	{0x71, 0x3f, 0x12, 0xff}, // brąz
+	{0x37, 0x41, 0x51, 0xff}, // grafit
+}
+
+// placeholderFonts to fonty Go (obejmują polskie znaki) wczytywane przy pierwszym użyciu
+var placeholderFonts = sync.OnceValues(func() ([2]*opentype.Font, error) {
+	bold, err := opentype.Parse(gobold.TTF)
+	if err != nil {
+		return [2]*opentype.Font{}, err
+	}
+	regular, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		return [2]*opentype.Font{}, err
+	}
+	return [2]*opentype.Font{bold, regular}, nil
+})
+
+// placeholder to rozmieszczenie tekstu okładki zastępczej
+type placeholder struct {
+	background color.RGBA
+	title      []string
+	author     []string
+}
+
+// PlaceholderKey zwraca identyfikator okładki zastępczej (nazwa pliku PNG i ETag)
+func PlaceholderKey(title, author string, width int) string {
+	return fmt.Sprintf("placeholder-%08x-%d", placeholderHash(title, author), width)
+}
+
+func placeholderHash(title, author string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(title))
+	h.Write([]byte{0})
+	h.Write([]byte(author))
+	return h.Sum32()
+}
+
+// newPlaceholder dzieli tytuł i autora na wiersze mieszczące się na okładce
+func newPlaceholder(title, author string) (*placeholder, error) {
+	fonts, err := placeholderFonts()
+	if err != nil {
+		return nil, fmt.Errorf("błąd wczytywania fontów okładki: %w", err)
+	}
+	titleFace, err := newFace(fonts[0], titleSize)
+	if err != nil {
+		return nil, err
+	}
+	defer titleFace.Close()
+	authorFace, err := newFace(fonts[1], authorSize)
+	if err != nil {
+		return nil, err
+	}
+	defer authorFace.Close()
+
+	if strings.TrimSpace(title) == "" {
+		title = "Bez tytułu"
+	}
+	textWidth := fixed.I(placeholderWidth - 2*placeholderMargin)
+	return &placeholder{
+		background: placeholderColors[placeholderHash(title, author)%uint32(len(placeholderColors))],
+		title:      wrapText(title, titleFace, textWidth, maxTitleLines),
+		author:     wrapText(author, authorFace, textWidth, maxAuthorLines),
+	}, nil
+}
+
+func newFace(f *opentype.Font, size float64) (font.Face, error) {
+	return opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingNone})
+}
+
+// wrapText dzieli tekst na najwyżej maxLines wierszy o szerokości do width.
+// Zbyt długie słowa są łamane, a tekst, który się nie zmieścił, kończy wielokropek.
+func wrapText(text string, face font.Face, width fixed.Int26_6, maxLines int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if font.MeasureString(face, candidate) <= width {
+			line = candidate
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		// Słowo dłuższe niż wiersz (np. ISBN albo długi wyraz złożony)
+		for font.MeasureString(face, word) > width {
+			runes := []rune(word)
+			if len(runes) == 1 {
+				break
+			}
+			n := len(runes) - 1
+			for n > 1 && font.MeasureString(face, string(runes[:n])) > width {
+				n--
+			}
+			lines = append(lines, string(runes[:n]))
+			word = string(runes[n:])
+		}
+		line = word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+
+	if len(lines) <= maxLines {
+		return lines
+	}
+	lines = lines[:maxLines]
+	last := []rune(lines[maxLines-1])
+	for len(last) > 0 && font.MeasureString(face, string(last)+"…") > width {
+		last = last[:len(last)-1]
+	}
+	lines[maxLines-1] = strings.TrimRight(string(last), " ") + "…"
+	return lines
+}
+
+// authorTop zwraca linię bazową pierwszego wiersza autora
+func (p *placeholder) authorTop() int {
+	return authorBottom - (len(p.author)-1)*authorLineHeight
+}
+
+// PlaceholderSVG zwraca okładkę zastępczą książki jako SVG
+func PlaceholderSVG(title, author string) ([]byte, error) {
+	p, err := newPlaceholder(title, author)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %[1]d %[2]d">`+"\n",
+		placeholderWidth, placeholderHeight)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#%02x%02x%02x"/>`+"\n",
+		placeholderWidth, placeholderHeight, p.background.R, p.background.G, p.background.B)
+	fmt.Fprintf(&buf, `<rect x="16" y="16" width="%d" height="%d" fill="none" stroke="#fff" stroke-opacity="0.35" stroke-width="2"/>`+"\n",
+		placeholderWidth-32, placeholderHeight-32)
+
+	writeLines := func(lines []string, size, top, lineHeight int, weight string) {
+		fmt.Fprintf(&buf, `<text font-family="Go, Helvetica, Arial, sans-serif" font-size="%d" font-weight="%s" fill="#fff" text-anchor="middle">`, size, weight)
+		for i, line := range lines {
+			fmt.Fprintf(&buf, `<tspan x="%d" y="%d">`, placeholderWidth/2, top+i*lineHeight)
+			xml.EscapeText(&buf, []byte(line))
+			buf.WriteString(`</tspan>`)
+		}
+		buf.WriteString("</text>\n")
+	}
+	writeLines(p.title, titleSize, titleTop+titleSize, titleLineHeight, "bold")
+	if len(p.author) > 0 {
+		fmt.Fprintf(&buf, `<line x1="%d" y1="%d" x2="%d" y2="%[2]d" stroke="#fff" stroke-opacity="0.6" stroke-width="2"/>`+"\n",
+			placeholderWidth/2-30, p.authorTop()-authorLineHeight-8, placeholderWidth/2+30)
+		writeLines(p.author, authorSize, p.authorTop(), authorLineHeight, "normal")
+	}
+	buf.WriteString("</svg>\n")
+	return buf.Bytes(), nil
+}
+
+// PlaceholderPNG zwraca okładkę zastępczą jako PNG o szerokości width (np. dla podglądów
+// linków w komunikatorach, które nie wyświetlają SVG). Wynik jest przechowywany na dysku.
+func (s *Store) PlaceholderPNG(title, author string, width int) ([]byte, error) {
+	path := filepath.Join(s.dir, PlaceholderKey(title, author, width)+".png")
+	if data, err := os.ReadFile(path); err == nil {
+		return data, nil
+	}
+
+	data, err, _ := s.group.Do(path, func() (any, error) {
+		data, err := renderPlaceholderPNG(title, author, width)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.save(path, data); err != nil {
+			log.Printf("Błąd zapisu okładki zastępczej %s: %v", path, err)
+		}
+		return data, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data.([]byte), nil
+}
+
+// renderPlaceholderPNG rysuje okładkę zastępczą w skali width/placeholderWidth
+func renderPlaceholderPNG(title, author string, width int) ([]byte, error) {
+	p, err := newPlaceholder(title, author)
+	if err != nil {
+		return nil, err
+	}
+	fonts, err := placeholderFonts()
+	if err != nil {
+		return nil, err
+	}
+	scale := float64(width) / placeholderWidth
+	px := func(v int) int { return int(float64(v)*scale + 0.5) }
+
+	img := image.NewRGBA(image.Rect(0, 0, width, px(placeholderHeight)))
+	draw.Draw(img, img.Bounds(), image.NewUniform(p.background), image.Point{}, draw.Src)
+
+	// Ramka jak w SVG: biała, półprzezroczysta
+	frame := image.NewUniform(color.NRGBA{0xff, 0xff, 0xff, 0x59})
+	border := max(1, px(2))
+	outer := image.Rect(px(16), px(16), width-px(16), px(placeholderHeight-16))
+	for _, r := range []image.Rectangle{
+		image.Rect(outer.Min.X, outer.Min.Y, outer.Max.X, outer.Min.Y+border),
+		image.Rect(outer.Min.X, outer.Max.Y-border, outer.Max.X, outer.Max.Y),
+		image.Rect(outer.Min.X, outer.Min.Y+border, outer.Min.X+border, outer.Max.Y-border),
+		image.Rect(outer.Max.X-border, outer.Min.Y+border, outer.Max.X, outer.Max.Y-border),
+	} {
+		draw.Draw(img, r, frame, image.Point{}, draw.Over)
+	}
+
+	drawLines := func(f *opentype.Font, lines []string, size, top, lineHeight int) error {
+		face, err := newFace(f, float64(size)*scale)
+		if err != nil {
+			return err
+		}
+		defer face.Close()
+		d := &font.Drawer{Dst: img, Src: image.White, Face: face}
+		for i, line := range lines {
+			x := (fixed.I(width) - d.MeasureString(line)) / 2
+			d.Dot = fixed.Point26_6{X: x, Y: fixed.I(px(top + i*lineHeight))}
+			d.DrawString(line)
+		}
+		return nil
+	}
+	if err := drawLines(fonts[0], p.title, titleSize, titleTop+titleSize, titleLineHeight); err != nil {
+		return nil, err
+	}
+	if len(p.author) > 0 {
+		y := px(p.authorTop() - authorLineHeight - 8)
+		line := image.Rect(px(placeholderWidth/2-30), y-border/2, px(placeholderWidth/2+30), y-border/2+border)
+		draw.Draw(img, line, image.NewUniform(color.NRGBA{0xff, 0xff, 0xff, 0x99}), image.Point{}, draw.Over)
+		if err := drawLines(fonts[1], p.author, authorSize, p.authorTop(), authorLineHeight); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,57 @@
+// Package demo obsługuje tryb demonstracyjny (DEMO_MODE): przykładowe dane wgrywane
+// przy starcie i resetowane co noc, oznaczenie interfejsu oraz blokadę zmian,
+// które zepsułyby wersję demonstracyjną kolejnym odwiedzającym.
+package demo
+
+import (
+	"fmt"
+	"net/http"
+
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/session"
+)
+
+// Konta demonstracyjne - dane logowania są wyświetlane na banerze
+const (
+	AdminEmail  = "admin@example.com"
+	ReaderEmail = "czytelnik@example.com"
+	Password    = "demo1234"
+)
+
+// enabled określa, czy aplikacja działa w trybie demonstracyjnym
+var enabled bool
+
+// Enable włącza tryb demonstracyjny (wywoływane przy starcie serwera)
+func Enable() {
+	enabled = true
+}
+
+// Enabled zwraca true w trybie demonstracyjnym
+func Enabled() bool {
+	return enabled
+}
+
+// Guard blokuje w trybie demonstracyjnym zmiany, które mogłyby zepsuć wersję demonstracyjną
+// (ustawienia biblioteki, konta użytkowników)
+func Guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if enabled {
+			http.Error(w, "Ta funkcja jest wyłączona w wersji demonstracyjnej", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Reset usuwa wszystkie dane, wylogowuje wszystkich użytkowników i wgrywa dane przykładowe
+func Reset(fbClient *firebase.Client) error {
+	if err := fbClient.WipeAllData(); err != nil {
+		return fmt.Errorf("błąd czyszczenia danych demonstracyjnych: %w", err)
+	}
+	session.GetManager().DeleteAllSessions()
+
+	if err := seed(fbClient); err != nil {
+		return fmt.Errorf("błąd wgrywania danych demonstracyjnych: %w", err)
+	}
+	return nil
+}
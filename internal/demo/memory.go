@@ -0,0 +1,39 @@
+package demo
+
+import (
+	"fmt"
+
+	"library-management-system/internal/models"
+	"library-management-system/internal/repository/memory"
+)
+
+// NewMemoryStore tworzy magazyn trybu bez bazy danych z przykładowym katalogiem, listą lektur
+// i kontami demonstracyjnymi (AdminEmail i ReaderEmail z hasłem Password)
+func NewMemoryStore() *memory.Store {
+	store := memory.NewStore(SampleBooks())
+
+	// Dane przykładowe są stałe - błąd oznacza pomyłkę w danych, jak w memory.NewStore
+	admin := &models.User{Email: AdminEmail, FirstName: "Anna", LastName: "Bibliotekarka", Role: models.RoleAdmin, IsActive: true}
+	reader := &models.User{Email: ReaderEmail, FirstName: "Jan", LastName: "Czytelnik", Role: models.RoleReader, IsActive: true}
+	for _, user := range []*models.User{admin, reader} {
+		if err := store.Register(user, Password); err != nil {
+			panic(fmt.Sprintf("demo: błąd tworzenia konta %s: %v", user.Email, err))
+		}
+	}
+
+	books, _ := store.ListBooks()
+	bookIDs := make(map[string]string, len(books))
+	for _, book := range books {
+		bookIDs[book.Title] = book.ID
+	}
+
+	list := sampleReadingList()
+	for _, title := range sampleListTitles {
+		list.BookIDs = append(list.BookIDs, bookIDs[title])
+	}
+	if err := store.CreateReadingList(list); err != nil {
+		panic(fmt.Sprintf("demo: błąd tworzenia listy lektur: %v", err))
+	}
+
+	return store
+}
@@ -0,0 +1,154 @@
+package demo
+
+import (
+	"fmt"
+	"time"
+
+	"firebase.google.com/go/v4/auth"
+
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+	"library-management-system/internal/publicstats"
+)
+
+// sampleBooks to katalog wersji demonstracyjnej
+var sampleBooks = []models.Book{
+	{Title: "Pan Tadeusz", Author: "Adam Mickiewicz", Publisher: "Ossolineum", PublicationYear: 1834, Category: "Poezja", TotalCopies: 3, ShelfLocation: "A-01", CallNumber: "821.162.1-1 Mic",
+		Description: "Epopeja narodowa - ostatni zajazd na Litwie w latach 1811-1812."},
+	{Title: "Lalka", Author: "Bolesław Prus", Publisher: "Ossolineum", PublicationYear: 1890, Category: "Powieść", TotalCopies: 2, ShelfLocation: "A-02", CallNumber: "821.162.1-3 Pru",
+		Description: "Historia Stanisława Wokulskiego na tle Warszawy drugiej połowy XIX wieku."},
+	{Title: "Quo vadis", Author: "Henryk Sienkiewicz", Publisher: "PIW", PublicationYear: 1896, Category: "Powieść historyczna", TotalCopies: 2, ShelfLocation: "A-03", CallNumber: "821.162.1-3 Sie",
+		Description: "Powieść o Rzymie czasów Nerona i prześladowaniach pierwszych chrześcijan."},
+	{Title: "Ogniem i mieczem", Author: "Henryk Sienkiewicz", Publisher: "PIW", PublicationYear: 1884, Category: "Powieść historyczna", TotalCopies: 1, ShelfLocation: "A-03", CallNumber: "821.162.1-3 Sie", Series: "Trylogia", SeriesVolume: 1,
+		Description: "Pierwsza część Trylogii - powstanie Chmielnickiego."},
+	{Title: "Chłopi", Author: "Władysław Reymont", Publisher: "Ossolineum", PublicationYear: 1904, Category: "Powieść", TotalCopies: 2, ShelfLocation: "A-04", CallNumber: "821.162.1-3 Rey",
+		Description: "Rok z życia wsi Lipce, nagrodzony Literacką Nagrodą Nobla."},
+	{Title: "Ferdydurke", Author: "Witold Gombrowicz", Publisher: "Wydawnictwo Literackie", PublicationYear: 1937, Category: "Powieść", TotalCopies: 1, ShelfLocation: "B-01", CallNumber: "821.162.1-3 Gom",
+		Description: "Groteskowa opowieść o trzydziestolatku cofniętym do szkolnej ławki."},
+	{Title: "Solaris", Author: "Stanisław Lem", Publisher: "Wydawnictwo Literackie", PublicationYear: 1961, Category: "Fantastyka", TotalCopies: 3, ShelfLocation: "C-01", CallNumber: "821.162.1-3 Lem",
+		Description: "Próba porozumienia z obcą inteligencją - żywym oceanem planety Solaris."},
+	{Title: "Cyberiada", Author: "Stanisław Lem", Publisher: "Wydawnictwo Literackie", PublicationYear: 1965, Category: "Fantastyka", TotalCopies: 2, ShelfLocation: "C-01", CallNumber: "821.162.1-3 Lem",
+		Description: "Przygody konstruktorów Trurla i Klapaucjusza."},
+	{Title: "Wiedźmin: Ostatnie życzenie", Author: "Andrzej Sapkowski", Publisher: "SuperNOWA", PublicationYear: 1993, Category: "Fantastyka", TotalCopies: 4, ShelfLocation: "C-02", CallNumber: "821.162.1-3 Sap", Series: "Wiedźmin", SeriesVolume: 1,
+		Description: "Zbiór opowiadań o Geralcie z Rivii."},
+	{Title: "Zbrodnia i kara", Author: "Fiodor Dostojewski", Publisher: "PIW", PublicationYear: 1866, Category: "Literatura obca", TotalCopies: 2, ShelfLocation: "D-01", CallNumber: "821.161.1-3 Dos",
+		Description: "Historia studenta Raskolnikowa i jego zbrodni."},
+	{Title: "Mistrz i Małgorzata", Author: "Michaił Bułhakow", Publisher: "Rebis", PublicationYear: 1967, Category: "Literatura obca", TotalCopies: 2, ShelfLocation: "D-02", CallNumber: "821.161.1-3 Buł",
+		Description: "Wizyta szatana w Moskwie lat trzydziestych."},
+	{Title: "Krótka historia czasu", Author: "Stephen Hawking", Publisher: "Zysk i S-ka", PublicationYear: 1988, Category: "Popularnonaukowe", TotalCopies: 1, ShelfLocation: "E-01", CallNumber: "524.8 Haw",
+		Description: "Od Wielkiego Wybuchu do czarnych dziur."},
+}
+
+// SampleBooks zwraca kopię katalogu wersji demonstracyjnej ze wszystkimi egzemplarzami
+// na półce (katalog startowy trybu bez bazy danych)
+func SampleBooks() []models.Book {
+	books := make([]models.Book, len(sampleBooks))
+	for i, book := range sampleBooks {
+		book.AvailableCopies = book.TotalCopies
+		books[i] = book
+	}
+	return books
+}
+
+// sampleListTitles to tytuły książek na przykładowej liście lektur, w kolejności listy
+var sampleListTitles = []string{"Chłopi", "Pan Tadeusz", "Lalka", "Quo vadis", "Ferdydurke"}
+
+// sampleReadingList zwraca przykładową listę lektur bez pozycji - ID książek nadaje
+// magazyn, więc pozycje dopisuje się według sampleListTitles po dodaniu katalogu
+func sampleReadingList() *models.ReadingList {
+	return &models.ReadingList{
+		Title:       "Klasyka polska",
+		Slug:        "klasyka-polska",
+		Description: "Lektury, od których warto zacząć - w kolejności polecanej przez bibliotekarzy.",
+		Published:   true,
+		ShowOnHome:  true,
+	}
+}
+
+// seed wgrywa ustawienia, konta demonstracyjne, katalog, listę lektur, ogłoszenie powitalne
+// i zrzut publicznych statystyk
+func seed(fbClient *firebase.Client) error {
+	settings := models.DefaultSettings()
+	settings.LibraryName = "Biblioteka demonstracyjna"
+	settings.HomeBlocks = []models.HomeBlock{models.HomeBlockAnnouncements, models.HomeBlockNewArrivals,
+		models.HomeBlockReadingLists, models.HomeBlockSearch, models.HomeBlockStats}
+	if err := fbClient.SaveSettings(settings); err != nil {
+		return err
+	}
+
+	admin := &models.User{Email: AdminEmail, FirstName: "Anna", LastName: "Bibliotekarka", Role: models.RoleAdmin}
+	reader := &models.User{Email: ReaderEmail, FirstName: "Jan", LastName: "Czytelnik", Role: models.RoleReader}
+	for _, user := range []*models.User{admin, reader} {
+		if err := createAccount(fbClient, user); err != nil {
+			return err
+		}
+	}
+
+	books := SampleBooks()
+	bookIDs := make(map[string]string, len(books))
+	for i := range books {
+		book := books[i]
+		if err := fbClient.CreateBook(&book); err != nil {
+			return fmt.Errorf("błąd tworzenia książki %q: %w", book.Title, err)
+		}
+		bookIDs[book.Title] = book.ID
+	}
+
+	list := sampleReadingList()
+	for _, title := range sampleListTitles {
+		list.BookIDs = append(list.BookIDs, bookIDs[title])
+	}
+	if err := fbClient.CreateReadingList(list); err != nil {
+		return fmt.Errorf("błąd tworzenia listy lektur: %w", err)
+	}
+
+	for _, badge := range models.DefaultBadges() {
+		if err := fbClient.CreateBadge(badge); err != nil {
+			return fmt.Errorf("błąd tworzenia odznaki %q: %w", badge.Name, err)
+		}
+	}
+
+	err := fbClient.CreateAnnouncement(&models.Announcement{
+		Title: "Witamy w wersji demonstracyjnej",
+		Body: "To jest **wersja demonstracyjna** systemu bibliotecznego.\n\n" +
+			"- zaloguj się jako czytelnik, aby wypożyczać i rezerwować książki\n" +
+			"- zaloguj się jako bibliotekarz, aby zarządzać katalogiem i wypożyczeniami\n\n" +
+			"Wszystkie dane są przywracane do stanu początkowego każdej nocy.",
+		Published:  true,
+		AuthorID:   admin.ID,
+		AuthorName: admin.FirstName + " " + admin.LastName,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Reset odbywa się po nocnym przeliczeniu statystyk, więc zrzut powstaje od razu
+	_, err = publicstats.Snapshot(fbClient, time.Now())
+	return err
+}
+
+// createAccount tworzy konto Firebase Auth i profil użytkownika z hasłem demonstracyjnym
+func createAccount(fbClient *firebase.Client, user *models.User) error {
+	params := (&auth.UserToCreate{}).
+		Email(user.Email).
+		Password(Password).
+		DisplayName(user.FirstName + " " + user.LastName)
+
+	ctx := fbClient.GetContext()
+	firebaseUser, err := fbClient.Auth.CreateUser(ctx, params)
+	if auth.IsEmailAlreadyExists(err) {
+		// Konto Auth bez profilu (np. po przerwanym resecie) - przywróć hasło demonstracyjne
+		existing, getErr := fbClient.Auth.GetUserByEmail(ctx, user.Email)
+		if getErr != nil {
+			return fmt.Errorf("błąd pobierania konta %s: %w", user.Email, getErr)
+		}
+		firebaseUser, err = fbClient.Auth.UpdateUser(ctx, existing.UID, (&auth.UserToUpdate{}).Password(Password))
+	}
+	if err != nil {
+		return fmt.Errorf("błąd tworzenia konta %s: %w", user.Email, err)
+	}
+
+	user.FirebaseUID = firebaseUser.UID
+	user.IsActive = true
+	return fbClient.CreateUser(user)
+}
@@ -0,0 +1,103 @@
+// Package errorreport wysyła błędy serwera (panic w handlerach) do zewnętrznej usługi
+// zbierania błędów: Sentry i/lub Google Cloud Error Reporting. Zgłoszenie zawiera stos
+// wywołań, dane żądania i ID zalogowanego użytkownika. Bez konfiguracji w zmiennych
+// środowiskowych błędy są tylko logowane.
+package errorreport
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+)
+
+// sendTimeout to czas na wysłanie jednego zgłoszenia do usługi
+const sendTimeout = 10 * time.Second
+
+// Event to jeden błąd serwera
+type Event struct {
+	Message string // Wartość przekazana do panic
+	Stack   string // Stos wywołań w formacie debug.Stack
+	Time    time.Time
+
+	// Kontekst żądania
+	Method     string
+	URL        string
+	UserAgent  string
+	Referer    string
+	RemoteAddr string
+	RequestID  string
+	Tenant     string // ID biblioteki sieci (pusty = biblioteka główna)
+	UserID     string // Pusty dla niezalogowanych
+}
+
+// sink to jedna usługa zbierania błędów
+type sink interface {
+	name() string
+	send(ctx context.Context, e *Event) error
+}
+
+// Reporter przekazuje błędy do skonfigurowanych usług. Wysyłka odbywa się w tle,
+// żeby odpowiedź 500 nie czekała na zewnętrzną usługę.
+type Reporter struct {
+	sinks []sink
+}
+
+// FromEnv tworzy reportera z SENTRY_DSN (Sentry, opcjonalnie SENTRY_ENVIRONMENT)
+// i ERROR_REPORTING_PROJECT (Google Cloud Error Reporting, opcjonalnie nazwa usługi
+// w ERROR_REPORTING_SERVICE). Bez żadnej z tych zmiennych zwraca nil.
+func FromEnv(ctx context.Context) (*Reporter, error) {
+	r := &Reporter{}
+	if dsn := os.Getenv("SENTRY_DSN"); dsn != "" {
+		environment := os.Getenv("SENTRY_ENVIRONMENT")
+		if environment == "" {
+			environment = "production"
+		}
+		s, err := newSentry(dsn, environment)
+		if err != nil {
+			return nil, err
+		}
+		r.sinks = append(r.sinks, s)
+	}
+	if project := os.Getenv("ERROR_REPORTING_PROJECT"); project != "" {
+		service := os.Getenv("ERROR_REPORTING_SERVICE")
+		if service == "" {
+			service = "library-management-system"
+		}
+		g, err := newGCP(ctx, project, service)
+		if err != nil {
+			return nil, err
+		}
+		r.sinks = append(r.sinks, g)
+	}
+
+	if len(r.sinks) == 0 {
+		return nil, nil
+	}
+	return r, nil
+}
+
+// Services zwraca nazwy skonfigurowanych usług (do logu przy starcie)
+func (r *Reporter) Services() []string {
+	names := make([]string, 0, len(r.sinks))
+	for _, s := range r.sinks {
+		names = append(names, s.name())
+	}
+	return names
+}
+
+// Report wysyła błąd w tle do wszystkich usług. Nil-safe - bez konfiguracji nic nie robi.
+func (r *Reporter) Report(e *Event) {
+	if r == nil {
+		return
+	}
+	for _, s := range r.sinks {
+		go func(s sink) {
+			ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+			defer cancel()
+			if err := s.send(ctx, e); err != nil {
+				log.Printf("Błąd zgłaszania błędu do %s: %v", s.name(), err)
+			}
+		}(s)
+	}
+}
@@ -0,0 +1,73 @@
+package errorreport
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"google.golang.org/api/clouderrorreporting/v1beta1"
+	"google.golang.org/api/option"
+)
+
+// gcp wysyła zdarzenia do Google Cloud Error Reporting
+type gcp struct {
+	project string
+	service *clouderrorreporting.ServiceContext
+	api     *clouderrorreporting.Service
+}
+
+// newGCP łączy się z Error Reporting na danych uwierzytelniających Firebase
+// (FIREBASE_CREDENTIALS_PATH / FIREBASE_CREDENTIALS_JSON), a bez nich na domyślnych
+// danych środowiska (np. konto usługi Cloud Run)
+func newGCP(ctx context.Context, project, service string) (*gcp, error) {
+	var opts []option.ClientOption
+	if path := os.Getenv("FIREBASE_CREDENTIALS_PATH"); path != "" {
+		opts = append(opts, option.WithCredentialsFile(path))
+	} else if credentialsJSON := os.Getenv("FIREBASE_CREDENTIALS_JSON"); credentialsJSON != "" {
+		opts = append(opts, option.WithCredentialsJSON([]byte(credentialsJSON)))
+	}
+
+	api, err := clouderrorreporting.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("błąd inicjalizacji Error Reporting: %w", err)
+	}
+	return &gcp{
+		project: project,
+		service: &clouderrorreporting.ServiceContext{Service: service},
+		api:     api,
+	}, nil
+}
+
+func (g *gcp) name() string {
+	return "Error Reporting"
+}
+
+// send zgłasza błąd. Error Reporting grupuje zdarzenia po stosie wywołań, który musi
+// być częścią komunikatu w formacie wypisywanym przez runtime Go przy panic.
+func (g *gcp) send(ctx context.Context, e *Event) error {
+	user := e.UserID
+	if user == "" {
+		user = "anonim"
+	}
+
+	event := &clouderrorreporting.ReportedErrorEvent{
+		EventTime:      e.Time.UTC().Format("2006-01-02T15:04:05.000Z"),
+		Message:        "panic: " + e.Message + "\n\n" + e.Stack,
+		ServiceContext: g.service,
+		Context: &clouderrorreporting.ErrorContext{
+			User: user,
+			HttpRequest: &clouderrorreporting.HttpRequestContext{
+				Method:             e.Method,
+				Url:                e.URL,
+				UserAgent:          e.UserAgent,
+				Referrer:           e.Referer,
+				RemoteIp:           e.RemoteAddr,
+				ResponseStatusCode: http.StatusInternalServerError,
+			},
+		},
+	}
+
+	_, err := g.api.Projects.Events.Report("projects/"+g.project, event).Context(ctx).Do()
+	return err
+}
@@ -0,0 +1,162 @@
+package errorreport
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// sentry wysyła zdarzenia do Sentry przez endpoint store (protokół w wersji 7)
+type sentry struct {
+	storeURL    string
+	auth        string
+	environment string
+	serverName  string
+	http        *http.Client
+}
+
+// newSentry odczytuje DSN w formacie https://KLUCZ@host/ID_PROJEKTU
+func newSentry(dsn, environment string) (*sentry, error) {
+	u, err := url.Parse(dsn)
+	if err != nil || u.User == nil || u.User.Username() == "" || u.Host == "" {
+		return nil, fmt.Errorf("nieprawidłowy SENTRY_DSN")
+	}
+	path := strings.Trim(u.Path, "/")
+	slash := strings.LastIndex(path, "/")
+	projectID := path[slash+1:]
+	if projectID == "" {
+		return nil, fmt.Errorf("nieprawidłowy SENTRY_DSN: brak ID projektu")
+	}
+	prefix := ""
+	if slash >= 0 {
+		prefix = "/" + path[:slash]
+	}
+
+	hostname, _ := os.Hostname()
+	return &sentry{
+		storeURL:    fmt.Sprintf("%s://%s%s/api/%s/store/", u.Scheme, u.Host, prefix, projectID),
+		auth:        fmt.Sprintf("Sentry sentry_version=7, sentry_client=library-management-system/1.0, sentry_key=%s", u.User.Username()),
+		environment: environment,
+		serverName:  hostname,
+		http:        &http.Client{},
+	}, nil
+}
+
+func (s *sentry) name() string {
+	return "Sentry"
+}
+
+func (s *sentry) send(ctx context.Context, e *Event) error {
+	body, err := json.Marshal(s.payload(e))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.storeURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("odpowiedź %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// payload buduje zdarzenie Sentry: wyjątek ze stosem, żądanie, użytkownik i tagi
+func (s *sentry) payload(e *Event) map[string]any {
+	id := make([]byte, 16)
+	rand.Read(id)
+
+	tags := map[string]string{}
+	if e.RequestID != "" {
+		tags["request_id"] = e.RequestID
+	}
+	if e.Tenant != "" {
+		tags["tenant"] = e.Tenant
+	}
+
+	payload := map[string]any{
+		"event_id":    hex.EncodeToString(id),
+		"timestamp":   e.Time.UTC().Format("2006-01-02T15:04:05Z"),
+		"level":       "error",
+		"platform":    "go",
+		"logger":      "http",
+		"environment": s.environment,
+		"server_name": s.serverName,
+		"tags":        tags,
+		"exception": map[string]any{
+			"values": []map[string]any{{
+				"type":       "panic",
+				"value":      e.Message,
+				"stacktrace": map[string]any{"frames": sentryFrames(e.Stack)},
+			}},
+		},
+		"request": map[string]any{
+			"method": e.Method,
+			"url":    e.URL,
+			"headers": map[string]string{
+				"User-Agent": e.UserAgent,
+				"Referer":    e.Referer,
+			},
+		},
+	}
+
+	user := map[string]string{"ip_address": e.RemoteAddr}
+	if e.UserID != "" {
+		user["id"] = e.UserID
+	}
+	payload["user"] = user
+	return payload
+}
+
+// sentryFrames zamienia stos z debug.Stack na ramki Sentry (od najstarszej wywołanej funkcji).
+// Stos ma pary linii: "pakiet.funkcja(argumenty)" i "\tplik:linia +0x..".
+func sentryFrames(stack string) []map[string]any {
+	lines := strings.Split(stack, "\n")
+	var frames []map[string]any
+	for i := 1; i+1 < len(lines); i += 2 {
+		function := lines[i]
+		if paren := strings.LastIndex(function, "("); paren > 0 {
+			function = function[:paren]
+		}
+		location := strings.TrimSpace(lines[i+1])
+		if space := strings.Index(location, " "); space > 0 {
+			location = location[:space]
+		}
+		file, lineNo := location, 0
+		if colon := strings.LastIndex(location, ":"); colon > 0 {
+			file = location[:colon]
+			lineNo, _ = strconv.Atoi(location[colon+1:])
+		}
+
+		frames = append(frames, map[string]any{
+			"function": function,
+			"filename": file,
+			"lineno":   lineNo,
+			"in_app":   strings.Contains(function, "library-management-system/"),
+		})
+	}
+
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+	return frames
+}
@@ -0,0 +1,91 @@
+package events
+
+import (
+	"log"
+	"sync"
+)
+
+// Type określa rodzaj zdarzenia w systemie
+type Type string
+
+const (
+	BookCreated   Type = "book.created"   // Dodano nową książkę do katalogu
+	BookAvailable Type = "book.available" // Książka znów ma dostępne egzemplarze
+	BookChanged   Type = "book.changed"   // Dowolna zmiana strony książki (także usunięcie, zmiana dostępności i dyskusji)
+
+	ReservationReady     Type = "reservation.ready"     // Zarezerwowana książka czeka na odbiór (payload: *models.Reservation)
+	ReservationSuspended Type = "reservation.suspended" // Egzemplarz ominął czytelnika z pełnym limitem wypożyczeń (payload: *models.Reservation)
+	LoanDueSoon          Type = "loan.due_soon"         // Zbliża się termin zwrotu wypożyczenia (payload: *models.Loan)
+	LoanPickedUp         Type = "loan.picked_up"        // Personel wydał zamówioną książkę przy ladzie (payload: *models.Loan)
+	LoanReturned         Type = "loan.returned"         // Przyjęto zwrot wypożyczenia (payload: *models.Loan z naliczoną karą)
+
+	CirculationChanged Type = "circulation.changed" // Zapisano wypożyczenie lub rezerwację (payload: ID dokumentu)
+
+	CommentPublished Type = "comment.published" // Komentarz pojawił się na stronie książki (payload: *models.Comment)
+
+	NewsletterDue Type = "newsletter.due" // Pora wysłać newsletter za miniony miesiąc (payload: time.Time - pierwszy dzień miesiąca)
+
+	CommunicationQueued Type = "communication.queued" // Personel zlecił wysyłkę wiadomości do grupy czytelników (payload: *models.Communication)
+)
+
+// Event reprezentuje zdarzenie publikowane w magistrali
+type Event struct {
+	Type    Type
+	Tenant  string // Biblioteka z sieci, której dotyczy zdarzenie (pusty dla biblioteki głównej)
+	Payload interface{}
+}
+
+// Handler obsługuje zdarzenie
+type Handler func(Event)
+
+// Bus to prosta magistrala zdarzeń w pamięci procesu
+type Bus struct {
+	handlers map[Type][]Handler
+	mu       sync.RWMutex
+}
+
+var defaultBus = NewBus()
+
+// NewBus tworzy nową magistralę zdarzeń
+func NewBus() *Bus {
+	return &Bus{
+		handlers: make(map[Type][]Handler),
+	}
+}
+
+// Subscribe rejestruje handler dla danego typu zdarzenia
+func (b *Bus) Subscribe(eventType Type, handler Handler) {
+	b.mu.Lock()
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+	b.mu.Unlock()
+}
+
+// Publish publikuje zdarzenie - handlery wykonywane są asynchronicznie,
+// aby nie blokować żądania HTTP, które wywołało zdarzenie
+func (b *Bus) Publish(event Event) {
+	b.mu.RLock()
+	handlers := append([]Handler(nil), b.handlers[event.Type]...)
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		go func(h Handler) {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("Panika w obsłudze zdarzenia %s: %v", event.Type, r)
+				}
+			}()
+			h(event)
+		}(handler)
+	}
+}
+
+// Subscribe rejestruje handler w domyślnej magistrali
+func Subscribe(eventType Type, handler Handler) {
+	defaultBus.Subscribe(eventType, handler)
+}
+
+// PublishFor publikuje zdarzenie wybranej biblioteki z sieci w domyślnej magistrali.
+// Subskrybenci działający w imieniu jednej biblioteki powinni pomijać zdarzenia innych.
+func PublishFor(tenant string, eventType Type, payload interface{}) {
+	defaultBus.Publish(Event{Type: eventType, Tenant: tenant, Payload: payload})
+}
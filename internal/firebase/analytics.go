@@ -0,0 +1,106 @@
+package firebase
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"library-management-system/internal/models"
+)
+
+const (
+	// AnalyticsCollection to nazwa kolekcji dziennych liczników statystyk w Firestore
+	AnalyticsCollection = "analytics_daily"
+)
+
+// IncrementAnalyticsCounter zwiększa dzienny licznik o n (tworzy dokument, jeśli nie istnieje)
+func (c *Client) IncrementAnalyticsCounter(day string, kind models.AnalyticsKind, key, label string, n int) error {
+	c, span := c.startSpan("IncrementAnalyticsCounter")
+	defer span.End()
+
+	if day == "" || key == "" || n <= 0 {
+		return fmt.Errorf("nieprawidłowy licznik statystyk")
+	}
+
+	// Klucz może zawierać dowolne znaki, więc ID dokumentu to jego skrót
+	sum := sha1.Sum([]byte(key))
+	docID := day + "_" + string(kind) + "_" + hex.EncodeToString(sum[:8])
+
+	_, err := c.collection(AnalyticsCollection).Doc(docID).Set(c.ctx, map[string]interface{}{
+		"day":   day,
+		"kind":  string(kind),
+		"key":   key,
+		"label": label,
+		"count": firestore.Increment(n),
+	}, firestore.MergeAll)
+	if err != nil {
+		return fmt.Errorf("błąd zapisywania statystyk: %w", err)
+	}
+
+	return nil
+}
+
+// GetTopAnalytics sumuje liczniki danego rodzaju od dnia sinceDay (RRRR-MM-DD) i zwraca limit największych.
+// Zapytanie wymaga indeksu złożonego (kind ASC, day ASC) - patrz firestore.indexes.json
+func (c *Client) GetTopAnalytics(kind models.AnalyticsKind, sinceDay string, limit int) ([]models.AnalyticsStat, error) {
+	c, span := c.startSpan("GetTopAnalytics")
+	defer span.End()
+
+	iter := c.collection(AnalyticsCollection).
+		Where("kind", "==", string(kind)).
+		Where("day", ">=", sinceDay).
+		Documents(c.ctx)
+	defer iter.Stop()
+
+	totals := make(map[string]*models.AnalyticsStat)
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("błąd iteracji po statystykach: %w", err)
+		}
+
+		var counter models.AnalyticsCounter
+		if err := doc.DataTo(&counter); err != nil {
+			return nil, fmt.Errorf("błąd parsowania statystyk: %w", err)
+		}
+
+		stat, ok := totals[counter.Key]
+		if !ok {
+			stat = &models.AnalyticsStat{Key: counter.Key}
+			totals[counter.Key] = stat
+		}
+		stat.Count += counter.Count
+		if counter.Label != "" {
+			stat.Label = counter.Label
+		}
+	}
+
+	stats := make([]models.AnalyticsStat, 0, len(totals))
+	for _, stat := range totals {
+		if stat.Label == "" {
+			stat.Label = stat.Key
+		}
+		stats = append(stats, *stat)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Count != stats[j].Count {
+			return stats[i].Count > stats[j].Count
+		}
+		return stats[i].Label < stats[j].Label
+	})
+
+	if limit > 0 && len(stats) > limit {
+		stats = stats[:limit]
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,193 @@
+package firebase
+
+import (
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"library-management-system/internal/models"
+)
+
+const (
+	// AnnouncementsCollection to nazwa kolekcji ogłoszeń w Firestore
+	AnnouncementsCollection = "announcements"
+)
+
+// GetAnnouncement pobiera ogłoszenie po ID
+func (c *Client) GetAnnouncement(id string) (*models.Announcement, error) {
+	c, span := c.startSpan("GetAnnouncement")
+	defer span.End()
+
+	if id == "" {
+		return nil, fmt.Errorf("ID ogłoszenia nie może być puste")
+	}
+
+	doc, err := c.collection(AnnouncementsCollection).Doc(id).Get(c.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania ogłoszenia: %w", err)
+	}
+
+	var announcement models.Announcement
+	if err := doc.DataTo(&announcement); err != nil {
+		return nil, fmt.Errorf("błąd parsowania danych ogłoszenia: %w", err)
+	}
+
+	announcement.ID = doc.Ref.ID
+	return &announcement, nil
+}
+
+// CreateAnnouncement tworzy nowe ogłoszenie
+func (c *Client) CreateAnnouncement(announcement *models.Announcement) error {
+	c, span := c.startSpan("CreateAnnouncement")
+	defer span.End()
+
+	if announcement == nil {
+		return fmt.Errorf("ogłoszenie nie może być nil")
+	}
+	if announcement.Title == "" {
+		return fmt.Errorf("tytuł ogłoszenia jest wymagany")
+	}
+
+	now := time.Now()
+	announcement.CreatedAt = now
+	announcement.UpdatedAt = now
+
+	docRef := c.collection(AnnouncementsCollection).NewDoc()
+	announcement.ID = docRef.ID
+
+	if _, err := docRef.Set(c.ctx, announcement); err != nil {
+		return fmt.Errorf("błąd zapisywania ogłoszenia: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateAnnouncement aktualizuje ogłoszenie
+func (c *Client) UpdateAnnouncement(id string, announcement *models.Announcement) error {
+	c, span := c.startSpan("UpdateAnnouncement")
+	defer span.End()
+
+	if id == "" {
+		return fmt.Errorf("ID ogłoszenia nie może być puste")
+	}
+	if announcement == nil {
+		return fmt.Errorf("ogłoszenie nie może być nil")
+	}
+
+	announcement.ID = id
+	announcement.UpdatedAt = time.Now()
+
+	if _, err := c.collection(AnnouncementsCollection).Doc(id).Set(c.ctx, announcement); err != nil {
+		return fmt.Errorf("błąd aktualizacji ogłoszenia: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAnnouncement usuwa ogłoszenie
+func (c *Client) DeleteAnnouncement(id string) error {
+	c, span := c.startSpan("DeleteAnnouncement")
+	defer span.End()
+
+	if id == "" {
+		return fmt.Errorf("ID ogłoszenia nie może być puste")
+	}
+
+	if _, err := c.collection(AnnouncementsCollection).Doc(id).Delete(c.ctx); err != nil {
+		return fmt.Errorf("błąd usuwania ogłoszenia: %w", err)
+	}
+
+	return nil
+}
+
+// ListAnnouncements pobiera wszystkie ogłoszenia (najnowsze pierwsze)
+func (c *Client) ListAnnouncements() ([]*models.Announcement, error) {
+	c, span := c.startSpan("ListAnnouncements")
+	defer span.End()
+
+	var announcements []*models.Announcement
+
+	iter := c.collection(AnnouncementsCollection).
+		OrderBy("created_at", firestore.Desc).
+		Documents(c.ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("błąd iteracji po ogłoszeniach: %w", err)
+		}
+
+		var announcement models.Announcement
+		if err := doc.DataTo(&announcement); err != nil {
+			return nil, fmt.Errorf("błąd parsowania ogłoszenia: %w", err)
+		}
+
+		announcement.ID = doc.Ref.ID
+		announcements = append(announcements, &announcement)
+	}
+
+	return announcements, nil
+}
+
+// GetPublishedAnnouncements pobiera najnowsze opublikowane ogłoszenia.
+// Zapytanie wymaga indeksu złożonego (published ASC, created_at DESC) - patrz firestore.indexes.json
+func (c *Client) GetPublishedAnnouncements(limit int) ([]*models.Announcement, error) {
+	c, span := c.startSpan("GetPublishedAnnouncements")
+	defer span.End()
+
+	query := c.collection(AnnouncementsCollection).
+		Where("published", "==", true).
+		OrderBy("created_at", firestore.Desc)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	return c.queryAnnouncements(query)
+}
+
+// GetAnnouncementsPublishedBetween pobiera opublikowane ogłoszenia utworzone w przedziale
+// [from, to) (najnowsze pierwsze). Korzysta z tego samego indeksu co GetPublishedAnnouncements.
+func (c *Client) GetAnnouncementsPublishedBetween(from, to time.Time) ([]*models.Announcement, error) {
+	c, span := c.startSpan("GetAnnouncementsPublishedBetween")
+	defer span.End()
+
+	return c.queryAnnouncements(c.collection(AnnouncementsCollection).
+		Where("published", "==", true).
+		Where("created_at", ">=", from).
+		Where("created_at", "<", to).
+		OrderBy("created_at", firestore.Desc))
+}
+
+// queryAnnouncements pobiera ogłoszenia pasujące do zapytania
+func (c *Client) queryAnnouncements(query firestore.Query) ([]*models.Announcement, error) {
+	var announcements []*models.Announcement
+
+	iter := query.Documents(c.ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("błąd iteracji po ogłoszeniach: %w", err)
+		}
+
+		var announcement models.Announcement
+		if err := doc.DataTo(&announcement); err != nil {
+			return nil, fmt.Errorf("błąd parsowania ogłoszenia: %w", err)
+		}
+
+		announcement.ID = doc.Ref.ID
+		announcements = append(announcements, &announcement)
+	}
+
+	return announcements, nil
+}
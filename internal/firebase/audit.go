@@ -0,0 +1,319 @@
+package firebase
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"library-management-system/internal/events"
+	"library-management-system/internal/models"
+)
+
+const (
+	// AuditLogCollection to nazwa kolekcji dziennika zmian personelu
+	AuditLogCollection = "audit_log"
+)
+
+// auditIgnoredFields to pola, których zmiana nie jest zapisywana w dzienniku -
+// znacznik czasu zmienia każdy zapis, a cofnięcie ustawia go na nowo
+var auditIgnoredFields = map[string]bool{"updated_at": true}
+
+// auditDocNames to nazwy dokumentów w komunikatach o konflikcie przy cofaniu (biernik)
+var auditDocNames = map[string]string{
+	BooksCollection:        "książkę",
+	UsersCollection:        "konto czytelnika",
+	LoansCollection:        "wypożyczenie",
+	ReservationsCollection: "rezerwację",
+}
+
+// AuditDoc wskazuje dokument, który zmieni czynność zapisywana w dzienniku zmian
+type AuditDoc struct {
+	Collection string
+	ID         string
+}
+
+// AuditRecorder przechowuje treść dokumentów sprzed czynności personelu,
+// żeby po czynności zapisać w dzienniku tylko zmienione pola
+type AuditRecorder struct {
+	c      *Client
+	docs   []AuditDoc
+	before []map[string]interface{}
+}
+
+// BeginAudit zapamiętuje bieżącą treść dokumentów przed czynnością personelu.
+// Bez klienta (tryb bez bazy danych) dziennik zmian nie jest prowadzony - zwraca nil.
+func (c *Client) BeginAudit(docs ...AuditDoc) (*AuditRecorder, error) {
+	if c == nil {
+		return nil, nil
+	}
+	c, span := c.startSpan("BeginAudit")
+	defer span.End()
+
+	recorder := &AuditRecorder{c: c, docs: docs}
+	snapshots, err := recorder.read()
+	if err != nil {
+		return nil, err
+	}
+	recorder.before = snapshots
+	return recorder, nil
+}
+
+// BeginReturnAudit zapamiętuje dokumenty, które zmienia zwrot wypożyczenia: wypożyczenie,
+// konto czytelnika, książkę i rezerwację, która po zwrocie czeka na odbiór
+func (c *Client) BeginReturnAudit(loanID string) (*AuditRecorder, error) {
+	if c == nil {
+		return nil, nil
+	}
+	c, span := c.startSpan("BeginReturnAudit")
+	defer span.End()
+
+	loan, err := c.GetLoan(loanID)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := []AuditDoc{
+		{Collection: LoansCollection, ID: loanID},
+		{Collection: UsersCollection, ID: loan.UserID},
+		{Collection: BooksCollection, ID: loan.BookID},
+	}
+	next, err := c.GetNextReservation(loan.BookID)
+	if err != nil {
+		return nil, fmt.Errorf("błąd sprawdzania rezerwacji: %w", err)
+	}
+	if next != nil {
+		docs = append(docs, AuditDoc{Collection: ReservationsCollection, ID: next.ID})
+	}
+	return c.BeginAudit(docs...)
+}
+
+// Record zapisuje w dzienniku zmian pola, które czynność zmieniła od BeginAudit.
+// Czynność bez żadnej zmiany nie trafia do dziennika.
+func (a *AuditRecorder) Record(action models.AuditAction, entityID, label string, staff *models.User) error {
+	after, err := a.read()
+	if err != nil {
+		return err
+	}
+
+	entry := &models.AuditEntry{
+		Action:     action,
+		EntityID:   entityID,
+		Label:      label,
+		StaffID:    staff.ID,
+		StaffEmail: staff.Email,
+		CreatedAt:  time.Now(),
+	}
+	for i, doc := range a.docs {
+		before, changed := auditDiff(a.before[i], after[i])
+		if len(before) == 0 && len(changed) == 0 {
+			continue
+		}
+		entry.Changes = append(entry.Changes, &models.AuditChange{
+			Collection: doc.Collection,
+			DocID:      doc.ID,
+			Before:     before,
+			After:      changed,
+		})
+	}
+	if len(entry.Changes) == 0 {
+		return nil
+	}
+
+	if _, _, err := a.c.collection(AuditLogCollection).Add(a.c.ctx, entry); err != nil {
+		return fmt.Errorf("błąd zapisywania dziennika zmian: %w", err)
+	}
+	return nil
+}
+
+// read pobiera treść śledzonych dokumentów (nieistniejący dokument to pusta mapa)
+func (a *AuditRecorder) read() ([]map[string]interface{}, error) {
+	refs := make([]*firestore.DocumentRef, len(a.docs))
+	for i, doc := range a.docs {
+		refs[i] = a.c.collection(doc.Collection).Doc(doc.ID)
+	}
+	snapshots, err := a.c.Firestore.GetAll(a.c.ctx, refs)
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania dokumentów do dziennika zmian: %w", err)
+	}
+
+	data := make([]map[string]interface{}, len(snapshots))
+	for i, snapshot := range snapshots {
+		data[i] = map[string]interface{}{}
+		if snapshot.Exists() {
+			data[i] = snapshot.Data()
+		}
+	}
+	return data, nil
+}
+
+// auditDiff zwraca wartości pól, które różnią się między before i after - osobno sprzed
+// i po zmianie. Pole, którego w dokumencie nie było, nie występuje w odpowiedniej mapie.
+func auditDiff(before, after map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	changedBefore := map[string]interface{}{}
+	changedAfter := map[string]interface{}{}
+	for _, field := range auditFields(before, after) {
+		oldValue, hadOld := before[field]
+		newValue, hasNew := after[field]
+		if hadOld == hasNew && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		if hadOld {
+			changedBefore[field] = oldValue
+		}
+		if hasNew {
+			changedAfter[field] = newValue
+		}
+	}
+	return changedBefore, changedAfter
+}
+
+// auditFields zwraca nazwy pól występujących w którejkolwiek z map, bez pól pomijanych w dzienniku
+func auditFields(maps ...map[string]interface{}) []string {
+	seen := map[string]bool{}
+	var fields []string
+	for _, m := range maps {
+		for field := range m {
+			if !seen[field] && !auditIgnoredFields[field] {
+				seen[field] = true
+				fields = append(fields, field)
+			}
+		}
+	}
+	return fields
+}
+
+// ListAuditLog pobiera ostatnie wpisy dziennika zmian (najnowsze pierwsze)
+func (c *Client) ListAuditLog(limit int) ([]*models.AuditEntry, error) {
+	c, span := c.startSpan("ListAuditLog")
+	defer span.End()
+
+	docs, err := c.collection(AuditLogCollection).
+		OrderBy("created_at", firestore.Desc).
+		Limit(limit).
+		Documents(c.ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania dziennika zmian: %w", err)
+	}
+
+	entries := make([]*models.AuditEntry, 0, len(docs))
+	for _, doc := range docs {
+		entry, err := auditEntryFromDoc(doc)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// UndoAuditEntry cofa czynność z dziennika zmian, przywracając zapisane wartości pól sprzed
+// czynności. Cofnięcie odmawia, gdy minął models.AuditUndoWindow albo gdy któreś z pól
+// zmieniło się od czasu czynności (np. czytelnik wypożyczył w międzyczasie inną książkę) -
+// nadpisanie ich zgubiłoby późniejsze zmiany.
+func (c *Client) UndoAuditEntry(id, undoneBy string) (*models.AuditEntry, error) {
+	c, span := c.startSpan("UndoAuditEntry")
+	defer span.End()
+
+	entryRef := c.collection(AuditLogCollection).Doc(id)
+
+	var entry *models.AuditEntry
+	err := c.Firestore.RunTransaction(c.ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(entryRef)
+		if status.Code(err) == codes.NotFound {
+			return fmt.Errorf("nie ma takiego wpisu w dzienniku zmian")
+		}
+		if err != nil {
+			return err
+		}
+		if entry, err = auditEntryFromDoc(doc); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if entry.UndoneAt != nil {
+			return fmt.Errorf("czynność została już cofnięta")
+		}
+		if !entry.CanUndo(now) {
+			return fmt.Errorf("minął czas na cofnięcie czynności (%.0f min)", models.AuditUndoWindow.Minutes())
+		}
+
+		// Transakcja Firestore wymaga wszystkich odczytów przed pierwszym zapisem
+		refs := make([]*firestore.DocumentRef, len(entry.Changes))
+		current := make([]map[string]interface{}, len(entry.Changes))
+		for i, change := range entry.Changes {
+			refs[i] = c.collection(change.Collection).Doc(change.DocID)
+			snapshot, err := tx.Get(refs[i])
+			if status.Code(err) == codes.NotFound {
+				return fmt.Errorf("nie można cofnąć - usunięto już %s", auditDocName(change.Collection))
+			}
+			if err != nil {
+				return err
+			}
+			current[i] = snapshot.Data()
+		}
+
+		for i, change := range entry.Changes {
+			for _, field := range auditFields(change.Before, change.After) {
+				currentValue, has := current[i][field]
+				afterValue, hadAfter := change.After[field]
+				if has != hadAfter || !reflect.DeepEqual(currentValue, afterValue) {
+					return fmt.Errorf("nie można cofnąć - od tej czynności zmieniono już %s", auditDocName(change.Collection))
+				}
+			}
+		}
+
+		for i, change := range entry.Changes {
+			var updates []firestore.Update
+			for _, field := range auditFields(change.Before, change.After) {
+				value, ok := change.Before[field]
+				if !ok {
+					value = firestore.Delete
+				}
+				updates = append(updates, firestore.Update{Path: field, Value: value})
+			}
+			if _, ok := current[i]["updated_at"]; ok {
+				updates = append(updates, firestore.Update{Path: "updated_at", Value: now})
+			}
+			if err := tx.Update(refs[i], updates); err != nil {
+				return err
+			}
+		}
+
+		entry.UndoneAt = &now
+		entry.UndoneBy = undoneBy
+		return tx.Update(entryRef, []firestore.Update{
+			{Path: "undone_at", Value: now},
+			{Path: "undone_by", Value: undoneBy},
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("błąd cofania czynności: %w", err)
+	}
+
+	if entry.Action == models.AuditReturn {
+		c.publish(events.CirculationChanged, entry.EntityID)
+	}
+	return entry, nil
+}
+
+// auditDocName zwraca nazwę dokumentu z kolekcji do komunikatu o błędzie
+func auditDocName(collection string) string {
+	if name, ok := auditDocNames[collection]; ok {
+		return name
+	}
+	return "dokument z kolekcji " + collection
+}
+
+func auditEntryFromDoc(doc *firestore.DocumentSnapshot) (*models.AuditEntry, error) {
+	var entry models.AuditEntry
+	if err := doc.DataTo(&entry); err != nil {
+		return nil, fmt.Errorf("błąd parsowania wpisu dziennika zmian: %w", err)
+	}
+	entry.ID = doc.Ref.ID
+	return &entry, nil
+}
@@ -0,0 +1,305 @@
+package firebase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"library-management-system/internal/events"
+	"library-management-system/internal/models"
+)
+
+const (
+	// AuthorsCollection to nazwa kolekcji haseł wzorcowych autorów w Firestore
+	AuthorsCollection = "authors"
+
+	// maxAuthorNames ogranicza liczbę nazw hasła (limit zapytań "in" i "array-contains-any")
+	maxAuthorNames = 30
+)
+
+// CreateAuthor dodaje hasło wzorcowe autora
+func (c *Client) CreateAuthor(author *models.Author) error {
+	c, span := c.startSpan("CreateAuthor")
+	defer span.End()
+
+	if err := c.validateAuthor(author); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	author.CreatedAt = now
+	author.UpdatedAt = now
+
+	docRef := c.collection(AuthorsCollection).NewDoc()
+	author.ID = docRef.ID
+
+	if _, err := docRef.Set(c.ctx, author); err != nil {
+		return fmt.Errorf("błąd zapisywania hasła autora: %w", err)
+	}
+
+	return nil
+}
+
+// GetAuthor pobiera hasło wzorcowe autora po ID
+func (c *Client) GetAuthor(id string) (*models.Author, error) {
+	c, span := c.startSpan("GetAuthor")
+	defer span.End()
+
+	if id == "" {
+		return nil, fmt.Errorf("ID hasła autora nie może być puste")
+	}
+
+	doc, err := c.collection(AuthorsCollection).Doc(id).Get(c.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania hasła autora: %w", err)
+	}
+
+	var author models.Author
+	if err := doc.DataTo(&author); err != nil {
+		return nil, fmt.Errorf("błąd parsowania hasła autora: %w", err)
+	}
+	author.ID = doc.Ref.ID
+
+	return &author, nil
+}
+
+// UpdateAuthor zapisuje nazwę preferowaną, warianty i notę hasła
+func (c *Client) UpdateAuthor(author *models.Author) error {
+	c, span := c.startSpan("UpdateAuthor")
+	defer span.End()
+
+	if author == nil || author.ID == "" {
+		return fmt.Errorf("ID hasła autora nie może być puste")
+	}
+	if err := c.validateAuthor(author); err != nil {
+		return err
+	}
+
+	author.UpdatedAt = time.Now()
+	if _, err := c.collection(AuthorsCollection).Doc(author.ID).Set(c.ctx, author); err != nil {
+		return fmt.Errorf("błąd aktualizacji hasła autora: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAuthor usuwa hasło wzorcowe. Książki zachowują ujednolicony zapis autora.
+func (c *Client) DeleteAuthor(id string) error {
+	c, span := c.startSpan("DeleteAuthor")
+	defer span.End()
+
+	if id == "" {
+		return fmt.Errorf("ID hasła autora nie może być puste")
+	}
+
+	if _, err := c.collection(AuthorsCollection).Doc(id).Delete(c.ctx); err != nil {
+		return fmt.Errorf("błąd usuwania hasła autora: %w", err)
+	}
+
+	return nil
+}
+
+// ListAuthors pobiera hasła wzorcowe posortowane według nazwy preferowanej
+func (c *Client) ListAuthors() ([]*models.Author, error) {
+	c, span := c.startSpan("ListAuthors")
+	defer span.End()
+
+	authors, err := c.listAuthors(c.collection(AuthorsCollection).Query)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(authors, func(i, j int) bool {
+		return strings.ToLower(authors[i].Name) < strings.ToLower(authors[j].Name)
+	})
+	return authors, nil
+}
+
+// FindAuthorByName zwraca hasło, którego nazwą preferowaną lub wariantem jest
+// podana nazwa, albo nil, jeśli autor nie ma hasła wzorcowego
+func (c *Client) FindAuthorByName(name string) (*models.Author, error) {
+	c, span := c.startSpan("FindAuthorByName")
+	defer span.End()
+
+	key := models.AuthorNameKey(name)
+	if key == "" {
+		return nil, nil
+	}
+
+	authors, err := c.listAuthors(c.collection(AuthorsCollection).
+		Where("name_keys", "array-contains", key).
+		Limit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(authors) == 0 {
+		return nil, nil
+	}
+	return authors[0], nil
+}
+
+// ApplyAuthor zamienia warianty zapisu na nazwę preferowaną w książkach
+// i subskrypcjach nowych tytułów autora. Zwraca liczbę zmienionych książek.
+func (c *Client) ApplyAuthor(author *models.Author) (int, error) {
+	c, span := c.startSpan("ApplyAuthor")
+	defer span.End()
+
+	variants := author.Variants
+	if len(variants) == 0 {
+		return 0, nil
+	}
+
+	docs, err := c.collection(BooksCollection).Where("author", "in", variants).Documents(c.ctx).GetAll()
+	if err != nil {
+		return 0, fmt.Errorf("błąd pobierania książek autora: %w", err)
+	}
+
+	now := time.Now()
+	for _, doc := range docs {
+		_, err := doc.Ref.Update(c.ctx, []firestore.Update{
+			{Path: "author", Value: author.Name},
+			{Path: "updated_at", Value: now},
+		})
+		if err != nil {
+			return 0, fmt.Errorf("błąd aktualizacji autora książki %s: %w", doc.Ref.ID, err)
+		}
+		c.publish(events.BookChanged, doc.Ref.ID)
+	}
+
+	if err := c.moveAuthorSubscriptions(variants, author.Name); err != nil {
+		return len(docs), err
+	}
+
+	return len(docs), nil
+}
+
+// moveAuthorSubscriptions przepisuje subskrypcje wariantów na nazwę preferowaną.
+// Czytelnik, który obserwuje już nazwę preferowaną, traci zdublowaną subskrypcję.
+func (c *Client) moveAuthorSubscriptions(variants []string, name string) error {
+	keys := make([]string, len(variants))
+	for i, variant := range variants {
+		keys[i] = models.SubscriptionKey(models.SubscriptionAuthor, variant)
+	}
+
+	subs, err := c.GetSubscriptionsByKeys(keys)
+	if err != nil {
+		return err
+	}
+
+	key := models.SubscriptionKey(models.SubscriptionAuthor, name)
+	for _, sub := range subs {
+		existing, err := c.FindUserSubscription(sub.UserID, key)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			if err := c.DeleteSubscription(sub.ID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		_, err = c.collection(SubscriptionsCollection).Doc(sub.ID).Update(c.ctx, []firestore.Update{
+			{Path: "value", Value: name},
+			{Path: "key", Value: key},
+		})
+		if err != nil {
+			return fmt.Errorf("błąd aktualizacji subskrypcji: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// validateAuthor porządkuje warianty (bez powtórzeń i nazwy preferowanej),
+// wylicza klucze nazw i sprawdza, czy żaden wariant nie należy do innego hasła
+func (c *Client) validateAuthor(author *models.Author) error {
+	if author == nil {
+		return fmt.Errorf("hasło autora nie może być nil")
+	}
+
+	author.Name = strings.Join(strings.Fields(author.Name), " ")
+	if models.AuthorNameKey(author.Name) == "" {
+		return fmt.Errorf("nazwa preferowana autora jest wymagana")
+	}
+
+	keys := []string{models.AuthorNameKey(author.Name)}
+	seen := map[string]bool{keys[0]: true}
+	var variants []string
+	for _, variant := range author.Variants {
+		variant = strings.Join(strings.Fields(variant), " ")
+		if variant == "" || variant == author.Name {
+			continue
+		}
+		// Zachowaj dokładny zapis wariantu - książki wyszukuje się po równości
+		if !containsString(variants, variant) {
+			variants = append(variants, variant)
+		}
+		if key := models.AuthorNameKey(variant); !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	if len(variants) >= maxAuthorNames || len(keys) > maxAuthorNames {
+		return fmt.Errorf("hasło może mieć najwyżej %d wariantów", maxAuthorNames-1)
+	}
+	author.Variants = variants
+	author.NameKeys = keys
+
+	others, err := c.listAuthors(c.collection(AuthorsCollection).Where("name_keys", "array-contains-any", keys))
+	if err != nil {
+		return err
+	}
+	for _, other := range others {
+		if other.ID == author.ID {
+			continue
+		}
+		for _, variant := range append([]string{author.Name}, variants...) {
+			if other.HasName(variant) {
+				return fmt.Errorf("nazwa %q należy już do hasła %q", variant, other.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *Client) listAuthors(query firestore.Query) ([]*models.Author, error) {
+	var authors []*models.Author
+
+	iter := query.Documents(c.ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("błąd iteracji po hasłach autorów: %w", err)
+		}
+
+		var author models.Author
+		if err := doc.DataTo(&author); err != nil {
+			return nil, fmt.Errorf("błąd parsowania hasła autora: %w", err)
+		}
+
+		author.ID = doc.Ref.ID
+		authors = append(authors, &author)
+	}
+
+	return authors, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,200 @@
+package firebase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"library-management-system/internal/models"
+)
+
+// BadgesCollection to nazwa kolekcji katalogu odznak w Firestore
+const BadgesCollection = "badges"
+
+// CreateBadge dodaje odznakę do katalogu
+func (c *Client) CreateBadge(badge *models.Badge) error {
+	c, span := c.startSpan("CreateBadge")
+	defer span.End()
+
+	if err := validateBadge(badge); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	badge.CreatedAt = now
+	badge.UpdatedAt = now
+
+	docRef := c.collection(BadgesCollection).NewDoc()
+	badge.ID = docRef.ID
+
+	if _, err := docRef.Set(c.ctx, badge); err != nil {
+		return fmt.Errorf("błąd zapisywania odznaki: %w", err)
+	}
+
+	return nil
+}
+
+// GetBadge pobiera odznakę z katalogu po ID
+func (c *Client) GetBadge(id string) (*models.Badge, error) {
+	c, span := c.startSpan("GetBadge")
+	defer span.End()
+
+	if id == "" {
+		return nil, fmt.Errorf("ID odznaki nie może być puste")
+	}
+
+	doc, err := c.collection(BadgesCollection).Doc(id).Get(c.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania odznaki: %w", err)
+	}
+
+	var badge models.Badge
+	if err := doc.DataTo(&badge); err != nil {
+		return nil, fmt.Errorf("błąd parsowania odznaki: %w", err)
+	}
+	badge.ID = doc.Ref.ID
+
+	return &badge, nil
+}
+
+// UpdateBadge zapisuje zmiany odznaki. Zmiana progu nie odbiera odznak już przyznanych.
+func (c *Client) UpdateBadge(badge *models.Badge) error {
+	c, span := c.startSpan("UpdateBadge")
+	defer span.End()
+
+	if badge == nil || badge.ID == "" {
+		return fmt.Errorf("ID odznaki nie może być puste")
+	}
+	if err := validateBadge(badge); err != nil {
+		return err
+	}
+
+	badge.UpdatedAt = time.Now()
+	if _, err := c.collection(BadgesCollection).Doc(badge.ID).Set(c.ctx, badge); err != nil {
+		return fmt.Errorf("błąd aktualizacji odznaki: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteBadge usuwa odznakę z katalogu. Przyznane egzemplarze znikają z dashboardów
+// czytelników, bo wyświetlane są tylko odznaki z katalogu.
+func (c *Client) DeleteBadge(id string) error {
+	c, span := c.startSpan("DeleteBadge")
+	defer span.End()
+
+	if id == "" {
+		return fmt.Errorf("ID odznaki nie może być puste")
+	}
+
+	if _, err := c.collection(BadgesCollection).Doc(id).Delete(c.ctx); err != nil {
+		return fmt.Errorf("błąd usuwania odznaki: %w", err)
+	}
+
+	return nil
+}
+
+// ListBadges pobiera katalog odznak uporządkowany według reguły i progu
+func (c *Client) ListBadges() ([]*models.Badge, error) {
+	c, span := c.startSpan("ListBadges")
+	defer span.End()
+
+	var badges []*models.Badge
+
+	iter := c.collection(BadgesCollection).Documents(c.ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("błąd iteracji po odznakach: %w", err)
+		}
+
+		var badge models.Badge
+		if err := doc.DataTo(&badge); err != nil {
+			return nil, fmt.Errorf("błąd parsowania odznaki: %w", err)
+		}
+
+		badge.ID = doc.Ref.ID
+		badges = append(badges, &badge)
+	}
+
+	sort.Slice(badges, func(i, j int) bool {
+		if badges[i].Rule != badges[j].Rule {
+			return badges[i].Rule < badges[j].Rule
+		}
+		return badges[i].Threshold < badges[j].Threshold
+	})
+	return badges, nil
+}
+
+// AwardBadges dopisuje czytelnikowi nowo zdobyte odznaki
+func (c *Client) AwardBadges(userID string, earned []models.EarnedBadge) error {
+	c, span := c.startSpan("AwardBadges")
+	defer span.End()
+
+	if len(earned) == 0 {
+		return nil
+	}
+
+	values := make([]interface{}, len(earned))
+	for i, badge := range earned {
+		values[i] = badge
+	}
+
+	_, err := c.collection(UsersCollection).Doc(userID).Update(c.ctx, []firestore.Update{
+		{Path: "badges", Value: firestore.ArrayUnion(values...)},
+	})
+	if err != nil {
+		return fmt.Errorf("błąd przyznawania odznak: %w", err)
+	}
+
+	return nil
+}
+
+// SetBadgesOptOut włącza lub wyłącza odznaki czytelnika. Rezygnacja usuwa też
+// odznaki już zdobyte - po ponownym włączeniu nocne zadanie przyzna je od nowa.
+func (c *Client) SetBadgesOptOut(userID string, optOut bool) error {
+	c, span := c.startSpan("SetBadgesOptOut")
+	defer span.End()
+
+	updates := []firestore.Update{
+		{Path: "badges_opt_out", Value: optOut},
+		{Path: "updated_at", Value: time.Now()},
+	}
+	if optOut {
+		updates = append(updates, firestore.Update{Path: "badges", Value: firestore.Delete})
+	}
+
+	if _, err := c.collection(UsersCollection).Doc(userID).Update(c.ctx, updates); err != nil {
+		return fmt.Errorf("błąd zapisywania ustawień odznak: %w", err)
+	}
+
+	return nil
+}
+
+func validateBadge(badge *models.Badge) error {
+	if badge == nil {
+		return fmt.Errorf("odznaka nie może być nil")
+	}
+
+	badge.Name = strings.TrimSpace(badge.Name)
+	if badge.Name == "" {
+		return fmt.Errorf("nazwa odznaki jest wymagana")
+	}
+	if !models.ValidBadgeRule(badge.Rule) {
+		return fmt.Errorf("nieznana reguła odznaki %q", badge.Rule)
+	}
+	if badge.Threshold < 1 {
+		return fmt.Errorf("próg odznaki musi być dodatni")
+	}
+
+	return nil
+}
@@ -3,13 +3,17 @@ package firebase
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"cloud.google.com/go/firestore"
+	"cloud.google.com/go/firestore/apiv1/firestorepb"
 	"google.golang.org/api/iterator"
 
+	"library-management-system/internal/events"
 	"library-management-system/internal/models"
+	"library-management-system/internal/search"
 )
 
 const (
@@ -19,11 +23,14 @@ const (
 
 // GetBook pobiera książkę po ID
 func (c *Client) GetBook(id string) (*models.Book, error) {
+	c, span := c.startSpan("GetBook")
+	defer span.End()
+
 	if id == "" {
 		return nil, fmt.Errorf("ID książki nie może być puste")
 	}
 
-	doc, err := c.Firestore.Collection(BooksCollection).Doc(id).Get(c.ctx)
+	doc, err := c.collection(BooksCollection).Doc(id).Get(c.ctx)
 	if err != nil {
 		return nil, fmt.Errorf("błąd pobierania książki: %w", err)
 	}
@@ -39,8 +46,43 @@ func (c *Client) GetBook(id string) (*models.Book, error) {
 	return &book, nil
 }
 
+// GetBooksByIDs pobiera kilka książek jednym zapytaniem. Zwraca mapę ID -> książka;
+// książek, których już nie ma w katalogu, brakuje w mapie.
+func (c *Client) GetBooksByIDs(ids []string) (map[string]*models.Book, error) {
+	c, span := c.startSpan("GetBooksByIDs")
+	defer span.End()
+
+	refs := c.docRefs(BooksCollection, ids)
+	if len(refs) == 0 {
+		return map[string]*models.Book{}, nil
+	}
+
+	docs, err := c.Firestore.GetAll(c.ctx, refs)
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania książek: %w", err)
+	}
+
+	books := make(map[string]*models.Book, len(docs))
+	for _, doc := range docs {
+		if !doc.Exists() {
+			continue
+		}
+		var book models.Book
+		if err := doc.DataTo(&book); err != nil {
+			return nil, fmt.Errorf("błąd parsowania danych książki: %w", err)
+		}
+		book.ID = doc.Ref.ID
+		books[book.ID] = &book
+	}
+
+	return books, nil
+}
+
 // CreateBook tworzy nową książkę w bazie
 func (c *Client) CreateBook(book *models.Book) error {
+	c, span := c.startSpan("CreateBook")
+	defer span.End()
+
 	if book == nil {
 		return fmt.Errorf("książka nie może być nil")
 	}
@@ -52,19 +94,33 @@ func (c *Client) CreateBook(book *models.Book) error {
 	if book.Author == "" {
 		return fmt.Errorf("autor książki jest wymagany")
 	}
+	if err := setCallNumber(book); err != nil {
+		return err
+	}
+	if err := c.setPreferredAuthor(book); err != nil {
+		return err
+	}
 
 	// Ustawienie timestamps
 	now := time.Now()
 	book.CreatedAt = now
 	book.UpdatedAt = now
 
+	if book.ShortCode == "" {
+		code, err := c.newShortCode()
+		if err != nil {
+			return err
+		}
+		book.ShortCode = code
+	}
+
 	// Jeśli nie ma ID, Firestore wygeneruje automatycznie
 	var docRef *firestore.DocumentRef
 	if book.ID == "" {
-		docRef = c.Firestore.Collection(BooksCollection).NewDoc()
+		docRef = c.collection(BooksCollection).NewDoc()
 		book.ID = docRef.ID
 	} else {
-		docRef = c.Firestore.Collection(BooksCollection).Doc(book.ID)
+		docRef = c.collection(BooksCollection).Doc(book.ID)
 	}
 
 	// Zapisz książkę
@@ -73,11 +129,16 @@ func (c *Client) CreateBook(book *models.Book) error {
 		return fmt.Errorf("błąd zapisywania książki: %w", err)
 	}
 
+	c.publish(events.BookCreated, book)
+	c.publish(events.BookChanged, book.ID)
 	return nil
 }
 
 // UpdateBook aktualizuje istniejącą książkę
 func (c *Client) UpdateBook(id string, book *models.Book) error {
+	c, span := c.startSpan("UpdateBook")
+	defer span.End()
+
 	if id == "" {
 		return fmt.Errorf("ID książki nie może być puste")
 	}
@@ -86,26 +147,43 @@ func (c *Client) UpdateBook(id string, book *models.Book) error {
 	}
 
 	// Sprawdź czy książka istnieje
-	_, err := c.GetBook(id)
+	existing, err := c.GetBook(id)
 	if err != nil {
 		return fmt.Errorf("książka nie istnieje: %w", err)
 	}
 
+	if err := setCallNumber(book); err != nil {
+		return err
+	}
+	if err := c.setPreferredAuthor(book); err != nil {
+		return err
+	}
+
 	// Aktualizuj timestamp
 	book.UpdatedAt = time.Now()
 	book.ID = id
+	// Krótki kod jest stały - wydrukowane etykiety muszą działać po edycji książki
+	book.ShortCode = existing.ShortCode
 
 	// Zapisz zmiany
-	_, err = c.Firestore.Collection(BooksCollection).Doc(id).Set(c.ctx, book)
+	_, err = c.collection(BooksCollection).Doc(id).Set(c.ctx, book)
 	if err != nil {
 		return fmt.Errorf("błąd aktualizacji książki: %w", err)
 	}
 
+	if !existing.IsAvailable() && book.IsAvailable() {
+		c.publish(events.BookAvailable, book)
+	}
+	c.publish(events.BookChanged, id)
+
 	return nil
 }
 
 // DeleteBook usuwa książkę z bazy
 func (c *Client) DeleteBook(id string) error {
+	c, span := c.startSpan("DeleteBook")
+	defer span.End()
+
 	if id == "" {
 		return fmt.Errorf("ID książki nie może być puste")
 	}
@@ -117,24 +195,31 @@ func (c *Client) DeleteBook(id string) error {
 	}
 
 	// Usuń książkę
-	_, err = c.Firestore.Collection(BooksCollection).Doc(id).Delete(c.ctx)
+	_, err = c.collection(BooksCollection).Doc(id).Delete(c.ctx)
 	if err != nil {
 		return fmt.Errorf("błąd usuwania książki: %w", err)
 	}
 
+	c.publish(events.BookChanged, id)
 	return nil
 }
 
 // ListBooks pobiera listę wszystkich książek
 func (c *Client) ListBooks() ([]*models.Book, error) {
+	c, span := c.startSpan("ListBooks")
+	defer span.End()
+
 	return c.ListBooksWithFilter(nil)
 }
 
 // ListBooksWithFilter pobiera listę książek z opcjonalnym filtrowaniem
 func (c *Client) ListBooksWithFilter(queryFn func(firestore.Query) firestore.Query) ([]*models.Book, error) {
+	c, span := c.startSpan("ListBooksWithFilter")
+	defer span.End()
+
 	var books []*models.Book
 
-	query := c.Firestore.Collection(BooksCollection).Query
+	query := c.collection(BooksCollection).Query
 
 	// Zastosuj filtr jeśli podano
 	if queryFn != nil {
@@ -172,6 +257,9 @@ func (c *Client) ListBooksWithFilter(queryFn func(firestore.Query) firestore.Que
 
 // SearchBooks wyszukuje książki po tytule, autorze lub ISBN
 func (c *Client) SearchBooks(searchTerm string) ([]*models.Book, error) {
+	c, span := c.startSpan("SearchBooks")
+	defer span.End()
+
 	if searchTerm == "" {
 		return c.ListBooks()
 	}
@@ -182,26 +270,31 @@ func (c *Client) SearchBooks(searchTerm string) ([]*models.Book, error) {
 		return nil, err
 	}
 
+	terms := search.Terms(searchTerm)
+	scores := make(map[string]int)
 	var results []*models.Book
-	searchLower := strings.ToLower(searchTerm)
 
+	// Dopasowanie ignoruje znaki diakrytyczne i drobne literówki ("sapkowsky" -> "Sapkowski")
 	for _, book := range allBooks {
-		titleLower := strings.ToLower(book.Title)
-		authorLower := strings.ToLower(book.Author)
-		isbnLower := strings.ToLower(book.ISBN)
-
-		if strings.Contains(titleLower, searchLower) ||
-			strings.Contains(authorLower, searchLower) ||
-			strings.Contains(isbnLower, searchLower) {
+		text := search.Normalize(book.Title + " " + book.Author + " " + book.ISBN)
+		if score := search.Score(text, terms); score > 0 {
+			scores[book.ID] = score
 			results = append(results, book)
 		}
 	}
 
+	sort.SliceStable(results, func(i, j int) bool {
+		return scores[results[i].ID] > scores[results[j].ID]
+	})
+
 	return results, nil
 }
 
 // SearchBooksAdvanced wyszukuje książki po wielu kryteriach
 func (c *Client) SearchBooksAdvanced(title, author, isbn string) ([]*models.Book, error) {
+	c, span := c.startSpan("SearchBooksAdvanced")
+	defer span.End()
+
 	// Pobierz wszystkie książki i filtruj po stronie aplikacji
 	allBooks, err := c.ListBooks()
 	if err != nil {
@@ -209,21 +302,18 @@ func (c *Client) SearchBooksAdvanced(title, author, isbn string) ([]*models.Book
 	}
 
 	var results []*models.Book
-	titleLower := strings.ToLower(title)
-	authorLower := strings.ToLower(author)
-	isbnLower := strings.ToLower(isbn)
 
 	for _, book := range allBooks {
 		match := true
 
-		// Sprawdź każde kryterium (AND logic)
-		if title != "" && !strings.Contains(strings.ToLower(book.Title), titleLower) {
+		// Sprawdź każde kryterium (AND logic), bez względu na znaki diakrytyczne
+		if title != "" && !search.ContainsFold(book.Title, title) {
 			match = false
 		}
-		if author != "" && !strings.Contains(strings.ToLower(book.Author), authorLower) {
+		if author != "" && !search.ContainsFold(book.Author, author) {
 			match = false
 		}
-		if isbn != "" && !strings.Contains(strings.ToLower(book.ISBN), isbnLower) {
+		if isbn != "" && !search.ContainsFold(book.ISBN, isbn) {
 			match = false
 		}
 
@@ -246,11 +336,14 @@ func contains(s, substr string) bool {
 
 // GetBookByISBN pobiera książkę po ISBN
 func (c *Client) GetBookByISBN(isbn string) (*models.Book, error) {
+	c, span := c.startSpan("GetBookByISBN")
+	defer span.End()
+
 	if isbn == "" {
 		return nil, fmt.Errorf("ISBN nie może być pusty")
 	}
 
-	iter := c.Firestore.Collection(BooksCollection).Where("isbn", "==", isbn).Limit(1).Documents(c.ctx)
+	iter := c.collection(BooksCollection).Where("isbn", "==", isbn).Limit(1).Documents(c.ctx)
 	defer iter.Stop()
 
 	doc, err := iter.Next()
@@ -270,16 +363,52 @@ func (c *Client) GetBookByISBN(isbn string) (*models.Book, error) {
 	return &book, nil
 }
 
+// GetBookEditions pobiera inne wydania tej samej książki (ten sam tytuł i autor)
+func (c *Client) GetBookEditions(book *models.Book) ([]*models.Book, error) {
+	c, span := c.startSpan("GetBookEditions")
+	defer span.End()
+
+	var editions []*models.Book
+
+	iter := c.collection(BooksCollection).Where("title", "==", book.Title).Documents(c.ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("błąd wyszukiwania wydań: %w", err)
+		}
+
+		var edition models.Book
+		if err := doc.DataTo(&edition); err != nil {
+			return nil, fmt.Errorf("błąd parsowania książki: %w", err)
+		}
+		edition.ID = doc.Ref.ID
+
+		if edition.ID != book.ID && search.SameWork(&edition, book) {
+			editions = append(editions, &edition)
+		}
+	}
+
+	return editions, nil
+}
+
 // HasActiveLoans sprawdza czy książka ma aktywne wypożyczenia
 func (c *Client) HasActiveLoans(bookID string) (bool, error) {
+	c, span := c.startSpan("HasActiveLoans")
+	defer span.End()
+
 	if bookID == "" {
 		return false, fmt.Errorf("ID książki nie może być puste")
 	}
 
 	// Sprawdź czy są aktywne wypożyczenia
-	iter := c.Firestore.Collection(LoansCollection).
+	iter := c.collection(LoansCollection).
 		Where("book_id", "==", bookID).
-		Where("status", "==", "active").
+		Where("status", "in", borrowedStatuses()).
 		Limit(1).
 		Documents(c.ctx)
 	defer iter.Stop()
@@ -295,11 +424,76 @@ func (c *Client) HasActiveLoans(bookID string) (bool, error) {
 	return true, nil
 }
 
+// inQueryLimit to liczba wartości przekazywana w jednym zapytaniu "in" Firestore
+const inQueryLimit = 10
+
+// GetBooksActivity zlicza bieżące wypożyczenia i rezerwacje dla listy książek
+// (kilka zapytań "in" zamiast osobnych zapytań dla każdej książki)
+func (c *Client) GetBooksActivity(bookIDs []string) (map[string]*models.BookActivity, error) {
+	c, span := c.startSpan("GetBooksActivity")
+	defer span.End()
+
+	activity := make(map[string]*models.BookActivity, len(bookIDs))
+	for _, id := range bookIDs {
+		activity[id] = &models.BookActivity{}
+	}
+
+	for start := 0; start < len(bookIDs); start += inQueryLimit {
+		chunk := bookIDs[start:min(start+inQueryLimit, len(bookIDs))]
+
+		for _, status := range []models.LoanStatus{models.LoanStatusActive, models.LoanStatusOverdue, models.LoanStatusPendingPickup} {
+			ids, err := c.bookIDsWithStatus(LoansCollection, chunk, string(status))
+			if err != nil {
+				return nil, fmt.Errorf("błąd zliczania wypożyczeń: %w", err)
+			}
+			for _, id := range ids {
+				activity[id].Loans++
+			}
+		}
+
+		ids, err := c.bookIDsWithStatus(ReservationsCollection, chunk, string(models.ReservationStatusPending))
+		if err != nil {
+			return nil, fmt.Errorf("błąd zliczania rezerwacji: %w", err)
+		}
+		for _, id := range ids {
+			activity[id].Reservations++
+		}
+	}
+
+	return activity, nil
+}
+
+// bookIDsWithStatus zwraca book_id każdego dokumentu kolekcji (wypożyczenia lub rezerwacji)
+// o danym statusie, należącego do jednej z książek - jedno ID na dokument
+func (c *Client) bookIDsWithStatus(collection string, bookIDs []string, status string) ([]string, error) {
+	docs, err := c.collection(collection).
+		Where("book_id", "in", bookIDs).
+		Where("status", "==", status).
+		Select("book_id").
+		Documents(c.ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		if id, err := doc.DataAt("book_id"); err == nil {
+			if s, ok := id.(string); ok {
+				ids = append(ids, s)
+			}
+		}
+	}
+	return ids, nil
+}
+
 // ListBooksWithPagination pobiera książki z paginacją i sortowaniem
 func (c *Client) ListBooksWithPagination(limit int, offset int, sortBy string, sortOrder string) ([]*models.Book, int, error) {
+	c, span := c.startSpan("ListBooksWithPagination")
+	defer span.End()
+
 	var books []*models.Book
 
-	query := c.Firestore.Collection(BooksCollection).Query
+	query := c.collection(BooksCollection).Query
 
 	// Sortowanie
 	direction := firestore.Asc
@@ -312,14 +506,20 @@ func (c *Client) ListBooksWithPagination(limit int, offset int, sortBy string, s
 		sortBy = "title"
 	}
 
+	// Sygnatury sortuje się według klucza kolejności półkowej, nie jako zwykły tekst.
+	// Książki zapisane przed dodaniem sygnatur nie mają klucza i Firestore je pomija,
+	// dopóki nie zostaną ponownie zapisane.
+	if sortBy == "call_number" {
+		sortBy = "call_number_key"
+	}
+
 	query = query.OrderBy(sortBy, direction)
 
 	// Pobierz całkowitą liczbę dokumentów dla paginacji
-	allDocs, err := c.Firestore.Collection(BooksCollection).Documents(c.ctx).GetAll()
+	totalCount, err := c.CountTotalBooks()
 	if err != nil {
-		return nil, 0, fmt.Errorf("błąd pobierania liczby książek: %w", err)
+		return nil, 0, err
 	}
-	totalCount := len(allDocs)
 
 	// Zastosuj limit i offset
 	query = query.Limit(limit).Offset(offset)
@@ -350,6 +550,9 @@ func (c *Client) ListBooksWithPagination(limit int, offset int, sortBy string, s
 
 // GetAvailableBooks pobiera tylko dostępne książki
 func (c *Client) GetAvailableBooks() ([]*models.Book, error) {
+	c, span := c.startSpan("GetAvailableBooks")
+	defer span.End()
+
 	return c.ListBooksWithFilter(func(q firestore.Query) firestore.Query {
 		return q.Where("available_copies", ">", 0)
 	})
@@ -357,6 +560,9 @@ func (c *Client) GetAvailableBooks() ([]*models.Book, error) {
 
 // GetBooksByCategory pobiera książki z danej kategorii
 func (c *Client) GetBooksByCategory(category string) ([]*models.Book, error) {
+	c, span := c.startSpan("GetBooksByCategory")
+	defer span.End()
+
 	if category == "" {
 		return c.ListBooks()
 	}
@@ -366,11 +572,134 @@ func (c *Client) GetBooksByCategory(category string) ([]*models.Book, error) {
 	})
 }
 
+// GetBooksBySeries pobiera książki z serii posortowane według numeru tomu
+// (kolejne wydania tego samego tomu według roku wydania)
+func (c *Client) GetBooksBySeries(series string) ([]*models.Book, error) {
+	c, span := c.startSpan("GetBooksBySeries")
+	defer span.End()
+
+	// Bez sortowania w zapytaniu - równość na jednym polu nie wymaga indeksu złożonego
+	books, err := c.queryBooks(c.collection(BooksCollection).Where("series", "==", series))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(books, func(i, j int) bool {
+		if books[i].SeriesVolume != books[j].SeriesVolume {
+			return books[i].SeriesVolume < books[j].SeriesVolume
+		}
+		return books[i].PublicationYear < books[j].PublicationYear
+	})
+	return books, nil
+}
+
+// GetBooksByClassification pobiera książki, których symbol klasyfikacji zaczyna się
+// od podanych cyfr (np. "821" obejmuje 821.162.1), w kolejności półkowej
+func (c *Client) GetBooksByClassification(digits string) ([]*models.Book, error) {
+	c, span := c.startSpan("GetBooksByClassification")
+	defer span.End()
+
+	// Po cyfrach symbolu w kluczu następuje kolejna cyfra, spacja albo koniec,
+	// więc wszystkie poddziały są mniejsze od prefiksu z dopisanym „~”
+	return c.queryBooks(c.collection(BooksCollection).
+		Where("call_number_key", ">=", digits).
+		Where("call_number_key", "<", digits+"~").
+		OrderBy("call_number_key", firestore.Asc))
+}
+
+// setPreferredAuthor zamienia wariant zapisu autora na nazwę preferowaną z hasła wzorcowego
+func (c *Client) setPreferredAuthor(book *models.Book) error {
+	author, err := c.FindAuthorByName(book.Author)
+	if err != nil {
+		return err
+	}
+	if author != nil {
+		book.Author = author.Name
+	}
+	return nil
+}
+
+// setCallNumber sprawdza sygnaturę książki i wylicza jej klucz kolejności półkowej
+func setCallNumber(book *models.Book) error {
+	callNumber, err := models.NormalizeCallNumber(book.CallNumber)
+	if err != nil {
+		return err
+	}
+	book.CallNumber = callNumber
+	book.CallNumberKey = models.CallNumberKey(callNumber)
+	return nil
+}
+
+// GetBooksAddedSince pobiera książki dodane do katalogu od podanej chwili (najstarsze pierwsze)
+func (c *Client) GetBooksAddedSince(from time.Time) ([]*models.Book, error) {
+	c, span := c.startSpan("GetBooksAddedSince")
+	defer span.End()
+
+	return c.queryBooks(c.collection(BooksCollection).
+		Where("created_at", ">=", from).
+		OrderBy("created_at", firestore.Asc))
+}
+
+// GetBooksAddedBetween pobiera książki dodane do katalogu w przedziale [from, to) (najstarsze pierwsze)
+func (c *Client) GetBooksAddedBetween(from, to time.Time) ([]*models.Book, error) {
+	c, span := c.startSpan("GetBooksAddedBetween")
+	defer span.End()
+
+	return c.queryBooks(c.collection(BooksCollection).
+		Where("created_at", ">=", from).
+		Where("created_at", "<", to).
+		OrderBy("created_at", firestore.Asc))
+}
+
+// GetNewestBooks pobiera limit ostatnio dodanych książek (najnowsze pierwsze)
+func (c *Client) GetNewestBooks(limit int) ([]*models.Book, error) {
+	c, span := c.startSpan("GetNewestBooks")
+	defer span.End()
+
+	return c.queryBooks(c.collection(BooksCollection).
+		OrderBy("created_at", firestore.Desc).
+		Limit(limit))
+}
+
+// queryBooks pobiera książki pasujące do zapytania
+func (c *Client) queryBooks(query firestore.Query) ([]*models.Book, error) {
+	var books []*models.Book
+
+	iter := query.Documents(c.ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("błąd iteracji po książkach: %w", err)
+		}
+
+		var book models.Book
+		if err := doc.DataTo(&book); err != nil {
+			return nil, fmt.Errorf("błąd parsowania książki: %w", err)
+		}
+
+		book.ID = doc.Ref.ID
+		books = append(books, &book)
+	}
+
+	return books, nil
+}
+
 // UpdateBookAvailability aktualizuje dostępność książki
 func (c *Client) UpdateBookAvailability(bookID string, increment bool) error {
-	docRef := c.Firestore.Collection(BooksCollection).Doc(bookID)
+	c, span := c.startSpan("UpdateBookAvailability")
+	defer span.End()
+
+	docRef := c.collection(BooksCollection).Doc(bookID)
+
+	var becameAvailable *models.Book
+	err := c.Firestore.RunTransaction(c.ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		becameAvailable = nil // Transakcja może być ponawiana
 
-	return c.Firestore.RunTransaction(c.ctx, func(ctx context.Context, tx *firestore.Transaction) error {
 		doc, err := tx.Get(docRef)
 		if err != nil {
 			return err
@@ -382,7 +711,12 @@ func (c *Client) UpdateBookAvailability(bookID string, increment bool) error {
 		}
 
 		if increment {
+			wasAvailable := book.IsAvailable()
 			book.IncrementAvailableCopies()
+			if !wasAvailable && book.IsAvailable() {
+				book.ID = doc.Ref.ID
+				becameAvailable = &book
+			}
 		} else {
 			if !book.IsAvailable() {
 				return fmt.Errorf("książka nie jest dostępna")
@@ -394,13 +728,32 @@ func (c *Client) UpdateBookAvailability(bookID string, increment bool) error {
 
 		return tx.Set(docRef, &book)
 	})
+	if err != nil {
+		return err
+	}
+
+	if becameAvailable != nil {
+		c.publish(events.BookAvailable, becameAvailable)
+	}
+	c.publish(events.BookChanged, bookID)
+
+	return nil
 }
 
 // CountTotalBooks zwraca całkowitą liczbę książek w systemie
+// (zapytanie agregujące - bez pobierania dokumentów)
 func (c *Client) CountTotalBooks() (int, error) {
-	docs, err := c.Firestore.Collection(BooksCollection).Documents(c.ctx).GetAll()
+	c, span := c.startSpan("CountTotalBooks")
+	defer span.End()
+
+	result, err := c.collection(BooksCollection).NewAggregationQuery().WithCount("all").Get(c.ctx)
 	if err != nil {
 		return 0, fmt.Errorf("błąd liczenia książek: %w", err)
 	}
-	return len(docs), nil
+
+	count, ok := result["all"].(*firestorepb.Value)
+	if !ok {
+		return 0, fmt.Errorf("błąd liczenia książek: nieoczekiwany wynik agregacji")
+	}
+	return int(count.GetIntegerValue()), nil
 }
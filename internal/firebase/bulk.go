@@ -0,0 +1,75 @@
+package firebase
+
+import (
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+
+	"library-management-system/internal/models"
+)
+
+// Zapisy hurtowe dla generatora danych testowych (cmd/loadgen). W odróżnieniu od
+// CreateBook/CreateUser/CreateLoan nie sprawdzają duplikatów, nie nadają kodów
+// i nie publikują zdarzeń - dokumenty muszą być kompletne przed zapisem.
+
+// BulkCreateBooks zapisuje książki przez BulkWriter, nadając im ID i klucz sygnatury
+func (c *Client) BulkCreateBooks(books []*models.Book) error {
+	c, span := c.startSpan("BulkCreateBooks")
+	defer span.End()
+
+	for _, book := range books {
+		if err := setCallNumber(book); err != nil {
+			return fmt.Errorf("książka %q: %w", book.Title, err)
+		}
+	}
+	return bulkCreate(c, BooksCollection, books, func(b *models.Book, id string) { b.ID = id })
+}
+
+// BulkCreateUsers zapisuje profile czytelników (bez kont Firebase Auth) przez BulkWriter
+func (c *Client) BulkCreateUsers(users []*models.User) error {
+	c, span := c.startSpan("BulkCreateUsers")
+	defer span.End()
+
+	return bulkCreate(c, UsersCollection, users, func(u *models.User, id string) { u.ID = id })
+}
+
+// BulkCreateLoans zapisuje wypożyczenia przez BulkWriter
+func (c *Client) BulkCreateLoans(loans []*models.Loan) error {
+	c, span := c.startSpan("BulkCreateLoans")
+	defer span.End()
+
+	return bulkCreate(c, LoansCollection, loans, func(l *models.Loan, id string) { l.ID = id })
+}
+
+// bulkCreate zapisuje dokumenty w kolekcji biblioteki. BulkWriter sam dzieli zapisy
+// na partie i ogranicza tempo, więc dziesiątki tysięcy dokumentów nie przekraczają limitów Firestore.
+func bulkCreate[T any](c *Client, collection string, docs []T, setID func(T, string)) error {
+	bw := c.Firestore.BulkWriter(c.ctx)
+	jobs := make([]*firestore.BulkWriterJob, 0, len(docs))
+	for _, doc := range docs {
+		ref := c.collection(collection).NewDoc()
+		setID(doc, ref.ID)
+		job, err := bw.Create(ref, doc)
+		if err != nil {
+			bw.End()
+			return fmt.Errorf("błąd zapisu do %s: %w", collection, err)
+		}
+		jobs = append(jobs, job)
+	}
+	bw.End()
+
+	failed := 0
+	var firstErr error
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("nie zapisano %d z %d dokumentów w %s: %w", failed, len(docs), collection, firstErr)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package firebase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"library-management-system/internal/models"
+)
+
+// GetUserByCalendarToken pobiera czytelnika po tokenie z adresu kalendarza iCal.
+// Zwraca nil, jeśli token nie należy do nikogo (np. został zmieniony).
+func (c *Client) GetUserByCalendarToken(token string) (*models.User, error) {
+	c, span := c.startSpan("GetUserByCalendarToken")
+	defer span.End()
+
+	if token == "" {
+		return nil, nil
+	}
+
+	iter := c.collection(UsersCollection).Where("calendar_token", "==", token).Limit(1).Documents(c.ctx)
+	defer iter.Stop()
+
+	doc, err := iter.Next()
+	if err == iterator.Done {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("błąd wyszukiwania czytelnika po tokenie kalendarza: %w", err)
+	}
+
+	var user models.User
+	if err := doc.DataTo(&user); err != nil {
+		return nil, fmt.Errorf("błąd parsowania danych użytkownika: %w", err)
+	}
+	user.ID = doc.Ref.ID
+	user.Tenant = c.tenant
+
+	return &user, nil
+}
+
+// RenewCalendarToken nadaje czytelnikowi nowy token kalendarza - dotychczasowy adres
+// przestaje działać
+func (c *Client) RenewCalendarToken(userID string) (string, error) {
+	c, span := c.startSpan("RenewCalendarToken")
+	defer span.End()
+
+	raw := make([]byte, 20)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("błąd generowania tokenu: %w", err)
+	}
+	token := hex.EncodeToString(raw)
+
+	_, err := c.collection(UsersCollection).Doc(userID).Update(c.ctx, []firestore.Update{
+		{Path: "calendar_token", Value: token},
+		{Path: "updated_at", Value: time.Now()},
+	})
+	if err != nil {
+		return "", fmt.Errorf("błąd zapisywania tokenu kalendarza: %w", err)
+	}
+
+	return token, nil
+}
@@ -9,11 +9,16 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"sync/atomic"
+	"time"
 
 	"cloud.google.com/go/firestore"
 	firebase "firebase.google.com/go/v4"
 	"firebase.google.com/go/v4/auth"
 	"google.golang.org/api/option"
+
+	"library-management-system/internal/events"
+	"library-management-system/internal/models"
 )
 
 // UserToCreate reprezentuje parametry do utworzenia użytkownika w Firebase Auth
@@ -43,12 +48,26 @@ type Client struct {
 	Auth      *auth.Client
 	Firestore *firestore.Client
 	ctx       context.Context
+
+	cache *clientCache // Wspólny dla kopii klienta (patrz Traced)
+
+	// Biblioteka (tenant) w sieci bibliotek - pusty tenant to biblioteka główna,
+	// której kolekcje leżą w katalogu głównym bazy
+	tenant    string
+	tenantDoc *firestore.DocumentRef
 }
 
-var (
-	// GlobalClient to globalna instancja klienta Firebase
-	GlobalClient *Client
-)
+// clientCache to dane biblioteki trzymane w pamięci
+type clientCache struct {
+	settings    atomic.Pointer[cachedSettings]     // Ustawienia biblioteki (patrz GetSettings)
+	regulations atomic.Pointer[models.Regulations] // Obowiązujący regulamin (patrz GetCurrentRegulations)
+}
+
+// cachedSettings to ustawienia biblioteki z chwilą odczytu z bazy
+type cachedSettings struct {
+	settings *models.Settings
+	loadedAt time.Time
+}
 
 // InitFirebase inicjalizuje klienta Firebase
 func InitFirebase() (*Client, error) {
@@ -65,7 +84,7 @@ func InitFirebase() (*Client, error) {
 			return nil, fmt.Errorf("plik credentials nie istnieje: %s", credentialsPath)
 		}
 		opt := option.WithCredentialsFile(credentialsPath)
-		app, err = firebase.NewApp(ctx, nil, opt)
+		app, err = firebase.NewApp(ctx, nil, append(usageOptions(), opt)...)
 		if err != nil {
 			return nil, fmt.Errorf("błąd inicjalizacji Firebase App: %w", err)
 		}
@@ -76,12 +95,15 @@ func InitFirebase() (*Client, error) {
 			return nil, fmt.Errorf("brak zmiennej środowiskowej FIREBASE_CREDENTIALS_PATH lub FIREBASE_CREDENTIALS_JSON")
 		}
 		opt := option.WithCredentialsJSON([]byte(credentialsJSON))
-		app, err = firebase.NewApp(ctx, nil, opt)
+		app, err = firebase.NewApp(ctx, nil, append(usageOptions(), opt)...)
 		if err != nil {
 			return nil, fmt.Errorf("błąd inicjalizacji Firebase App: %w", err)
 		}
 	}
 
+	// Dzienny budżet odczytów Firestore (FIRESTORE_DAILY_READ_BUDGET) - patrz usage.go
+	daily.setBudget(readBudgetFromEnv())
+
 	// Inicjalizacja Auth Client
 	authClient, err := app.Auth(ctx)
 	if err != nil {
@@ -99,15 +121,59 @@ func InitFirebase() (*Client, error) {
 		Auth:      authClient,
 		Firestore: firestoreClient,
 		ctx:       ctx,
+		cache:     &clientCache{},
 	}
 
-	// Ustaw globalnego klienta
-	GlobalClient = client
-
 	log.Println("Firebase zainicjalizowany pomyślnie")
 	return client, nil
 }
 
+// ForTenant zwraca klienta biblioteki z sieci, którego kolekcje są podkolekcjami
+// dokumentu tenants/{id}. Połączenia z Firebase są współdzielone z klientem głównym.
+func (c *Client) ForTenant(id string) *Client {
+	return &Client{
+		App:       c.App,
+		Auth:      c.Auth,
+		Firestore: c.Firestore,
+		ctx:       c.ctx,
+		cache:     &clientCache{},
+		tenant:    id,
+		tenantDoc: c.Firestore.Collection(TenantsCollection).Doc(id),
+	}
+}
+
+// Tenant zwraca ID biblioteki klienta (pusty dla biblioteki głównej)
+func (c *Client) Tenant() string {
+	return c.tenant
+}
+
+// collection zwraca kolekcję biblioteki klienta
+func (c *Client) collection(name string) *firestore.CollectionRef {
+	if c.tenantDoc != nil {
+		return c.tenantDoc.Collection(name)
+	}
+	return c.Firestore.Collection(name)
+}
+
+// docRefs zwraca referencje dokumentów kolekcji dla listy ID (bez pustych i powtórzonych)
+func (c *Client) docRefs(collection string, ids []string) []*firestore.DocumentRef {
+	seen := make(map[string]bool, len(ids))
+	refs := make([]*firestore.DocumentRef, 0, len(ids))
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		refs = append(refs, c.collection(collection).Doc(id))
+	}
+	return refs
+}
+
+// publish publikuje zdarzenie oznaczone biblioteką klienta
+func (c *Client) publish(eventType events.Type, payload interface{}) {
+	events.PublishFor(c.tenant, eventType, payload)
+}
+
 // Close zamyka połączenia z Firebase
 func (c *Client) Close() error {
 	if c.Firestore != nil {
@@ -123,6 +189,9 @@ func (c *Client) GetContext() context.Context {
 
 // VerifyPassword weryfikuje email i hasło używając Firebase Authentication REST API
 func (c *Client) VerifyPassword(email, password string) (string, error) {
+	c, span := c.startSpan("VerifyPassword")
+	defer span.End()
+
 	apiKey := os.Getenv("FIREBASE_WEB_API_KEY")
 	if apiKey == "" {
 		return "", fmt.Errorf("brak FIREBASE_WEB_API_KEY w zmiennych środowiskowych")
@@ -192,3 +261,47 @@ func (c *Client) VerifyPassword(email, password string) (string, error) {
 	// Zwróć Firebase UID
 	return authResp.LocalID, nil
 }
+
+// SendPasswordResetEmail prosi Firebase Authentication REST API o wysłanie na podany
+// adres emaila z linkiem do ustawienia nowego hasła. Email wysyła Firebase według
+// szablonu z konsoli projektu, więc link nie trafia do dziennika powiadomień biblioteki.
+func (c *Client) SendPasswordResetEmail(email string) error {
+	c, span := c.startSpan("SendPasswordResetEmail")
+	defer span.End()
+
+	apiKey := os.Getenv("FIREBASE_WEB_API_KEY")
+	if apiKey == "" {
+		return fmt.Errorf("brak FIREBASE_WEB_API_KEY w zmiennych środowiskowych")
+	}
+
+	url := fmt.Sprintf("https://identitytoolkit.googleapis.com/v1/accounts:sendOobCode?key=%s", apiKey)
+
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"requestType": "PASSWORD_RESET",
+		"email":       email,
+	})
+	if err != nil {
+		return fmt.Errorf("błąd tworzenia żądania: %w", err)
+	}
+
+	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("błąd połączenia z Firebase Auth: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errorResp struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if err := json.Unmarshal(body, &errorResp); err == nil && errorResp.Error.Message != "" {
+			return fmt.Errorf("błąd wysyłania emaila z ustawieniem hasła: %s", errorResp.Error.Message)
+		}
+		return fmt.Errorf("błąd wysyłania emaila z ustawieniem hasła (status: %d)", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,350 @@
+package firebase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"library-management-system/internal/events"
+	"library-management-system/internal/models"
+)
+
+// CommentsCollection to nazwa kolekcji komentarzy do książek w Firestore
+const CommentsCollection = "comments"
+
+// CreateComment zapisuje nowy komentarz. Opublikowany od razu komentarz zmienia
+// stronę książki i może wspominać innych czytelników.
+func (c *Client) CreateComment(comment *models.Comment) error {
+	c, span := c.startSpan("CreateComment")
+	defer span.End()
+
+	if comment == nil || comment.BookID == "" || comment.UserID == "" {
+		return fmt.Errorf("komentarz musi wskazywać książkę i autora")
+	}
+
+	comment.CreatedAt = time.Now()
+
+	docRef := c.collection(CommentsCollection).NewDoc()
+	comment.ID = docRef.ID
+
+	if _, err := docRef.Set(c.ctx, comment); err != nil {
+		return fmt.Errorf("błąd zapisywania komentarza: %w", err)
+	}
+
+	if comment.IsPublished() {
+		c.publish(events.BookChanged, comment.BookID)
+		c.publish(events.CommentPublished, comment)
+	}
+	return nil
+}
+
+// GetComment pobiera komentarz po ID
+func (c *Client) GetComment(id string) (*models.Comment, error) {
+	c, span := c.startSpan("GetComment")
+	defer span.End()
+
+	if id == "" {
+		return nil, fmt.Errorf("ID komentarza nie może być puste")
+	}
+
+	doc, err := c.collection(CommentsCollection).Doc(id).Get(c.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania komentarza: %w", err)
+	}
+
+	var comment models.Comment
+	if err := doc.DataTo(&comment); err != nil {
+		return nil, fmt.Errorf("błąd parsowania komentarza: %w", err)
+	}
+	comment.ID = doc.Ref.ID
+
+	return &comment, nil
+}
+
+// GetBookComments pobiera wszystkie komentarze książki (także oczekujące
+// i odrzucone) od najstarszego
+func (c *Client) GetBookComments(bookID string) ([]*models.Comment, error) {
+	c, span := c.startSpan("GetBookComments")
+	defer span.End()
+
+	if bookID == "" {
+		return nil, fmt.Errorf("ID książki nie może być puste")
+	}
+
+	comments, err := c.listComments(c.collection(CommentsCollection).Where("book_id", "==", bookID))
+	if err != nil {
+		return nil, err
+	}
+
+	// Sortowanie w Go - bez indeksu złożonego
+	sort.Slice(comments, func(i, j int) bool {
+		return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+	})
+	return comments, nil
+}
+
+// GetModerationQueue pobiera komentarze czekające na decyzję moderatora: wstrzymane
+// przy dodaniu i zgłoszone przez czytelników, od najstarszego
+func (c *Client) GetModerationQueue() ([]*models.Comment, error) {
+	c, span := c.startSpan("GetModerationQueue")
+	defer span.End()
+
+	pending, err := c.listComments(c.collection(CommentsCollection).Where("status", "==", string(models.CommentPending)))
+	if err != nil {
+		return nil, err
+	}
+	flagged, err := c.listComments(c.collection(CommentsCollection).Where("flagged", "==", true))
+	if err != nil {
+		return nil, err
+	}
+
+	// Komentarz ukryty po zgłoszeniach jest w obu wynikach
+	comments := pending
+	for _, comment := range flagged {
+		if comment.Status != models.CommentPending {
+			comments = append(comments, comment)
+		}
+	}
+
+	sort.Slice(comments, func(i, j int) bool {
+		return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+	})
+	return comments, nil
+}
+
+// ReportComment zapisuje zgłoszenie komentarza przez czytelnika. Ponowne zgłoszenie
+// przez tę samą osobę nic nie zmienia, a po CommentReportThreshold zgłoszeniach
+// komentarz znika ze strony do decyzji moderatora.
+func (c *Client) ReportComment(commentID, userID, reason string) (*models.Comment, error) {
+	c, span := c.startSpan("ReportComment")
+	defer span.End()
+
+	if !models.ValidCommentReportReason(reason) {
+		return nil, fmt.Errorf("wybierz powód zgłoszenia")
+	}
+	if commentID == "" {
+		return nil, fmt.Errorf("ID komentarza nie może być puste")
+	}
+
+	commentRef := c.collection(CommentsCollection).Doc(commentID)
+	var comment models.Comment
+	hidden := false
+
+	err := c.Firestore.RunTransaction(c.ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(commentRef)
+		if err != nil {
+			return fmt.Errorf("błąd pobierania komentarza: %w", err)
+		}
+		comment = models.Comment{}
+		if err := doc.DataTo(&comment); err != nil {
+			return fmt.Errorf("błąd parsowania komentarza: %w", err)
+		}
+		comment.ID = doc.Ref.ID
+		hidden = false
+
+		if !comment.IsPublished() {
+			return fmt.Errorf("komentarz nie jest już widoczny")
+		}
+		if comment.UserID == userID {
+			return fmt.Errorf("nie można zgłosić własnego komentarza")
+		}
+		if comment.ReportedBy(userID) {
+			return nil
+		}
+
+		comment.Reports = append(comment.Reports, models.CommentReport{
+			UserID:    userID,
+			Reason:    reason,
+			CreatedAt: time.Now(),
+		})
+		comment.Flagged = true
+		if open := len(comment.OpenReports()); open >= models.CommentReportThreshold {
+			comment.Status = models.CommentPending
+			comment.HoldReason = fmt.Sprintf("Zgłoszony przez %d czytelników", open)
+			hidden = true
+		}
+
+		return tx.Set(commentRef, &comment)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("błąd zgłaszania komentarza: %w", err)
+	}
+
+	if hidden {
+		c.publish(events.BookChanged, comment.BookID)
+	}
+	return &comment, nil
+}
+
+// VoteComment zapisuje ocenę przydatności komentarza przez czytelnika. Każdy czytelnik
+// ma jeden głos: inny głos zastępuje poprzedni, a ten sam głos oddany ponownie go wycofuje.
+func (c *Client) VoteComment(commentID, userID string, helpful bool) (*models.Comment, error) {
+	c, span := c.startSpan("VoteComment")
+	defer span.End()
+
+	if commentID == "" {
+		return nil, fmt.Errorf("ID komentarza nie może być puste")
+	}
+
+	commentRef := c.collection(CommentsCollection).Doc(commentID)
+	var comment models.Comment
+
+	err := c.Firestore.RunTransaction(c.ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(commentRef)
+		if err != nil {
+			return fmt.Errorf("błąd pobierania komentarza: %w", err)
+		}
+		comment = models.Comment{}
+		if err := doc.DataTo(&comment); err != nil {
+			return fmt.Errorf("błąd parsowania komentarza: %w", err)
+		}
+		comment.ID = doc.Ref.ID
+
+		if !comment.IsPublished() {
+			return fmt.Errorf("komentarz nie jest już widoczny")
+		}
+		if comment.UserID == userID {
+			return fmt.Errorf("nie można oceniać własnego komentarza")
+		}
+
+		votes := make([]models.CommentVote, 0, len(comment.Votes)+1)
+		withdrawn := false
+		for _, vote := range comment.Votes {
+			if vote.UserID != userID {
+				votes = append(votes, vote)
+			} else if vote.Helpful == helpful {
+				withdrawn = true
+			}
+		}
+		if !withdrawn {
+			votes = append(votes, models.CommentVote{
+				UserID:    userID,
+				Helpful:   helpful,
+				CreatedAt: time.Now(),
+			})
+		}
+		comment.Votes = votes
+
+		return tx.Update(commentRef, []firestore.Update{{Path: "votes", Value: votes}})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("błąd oceniania komentarza: %w", err)
+	}
+
+	c.publish(events.BookChanged, comment.BookID)
+	return &comment, nil
+}
+
+// ModerateComment zapisuje decyzję moderatora w sprawie komentarza razem z wpisem
+// dziennika moderacji. Blokada autora ukrywa komentarz i odbiera autorowi
+// możliwość komentowania.
+func (c *Client) ModerateComment(commentID string, action models.ModerationAction, moderator *models.User) (*models.Comment, error) {
+	c, span := c.startSpan("ModerateComment")
+	defer span.End()
+
+	status := models.CommentRejected
+	switch action {
+	case models.ModerationApprove:
+		status = models.CommentPublished
+	case models.ModerationHide, models.ModerationBan:
+	default:
+		return nil, fmt.Errorf("nieznana decyzja moderatora %q", action)
+	}
+	if commentID == "" {
+		return nil, fmt.Errorf("ID komentarza nie może być puste")
+	}
+
+	commentRef := c.collection(CommentsCollection).Doc(commentID)
+	decisionRef := c.collection(ModerationLogCollection).NewDoc()
+	var comment models.Comment
+	wasPublished, firstPublication := false, false
+
+	err := c.Firestore.RunTransaction(c.ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(commentRef)
+		if err != nil {
+			return fmt.Errorf("błąd pobierania komentarza: %w", err)
+		}
+		comment = models.Comment{}
+		if err := doc.DataTo(&comment); err != nil {
+			return fmt.Errorf("błąd parsowania komentarza: %w", err)
+		}
+		comment.ID = doc.Ref.ID
+		wasPublished = comment.IsPublished()
+		// Wzmianki powiadamiamy tylko przy pierwszej publikacji komentarza wstrzymanego przy dodaniu
+		firstPublication = !wasPublished && comment.ModeratedAt == nil && len(comment.Reports) == 0
+
+		now := time.Now()
+		decision := &models.ModerationDecision{
+			ID:             decisionRef.ID,
+			Action:         action,
+			CommentID:      comment.ID,
+			BookID:         comment.BookID,
+			AuthorID:       comment.UserID,
+			AuthorName:     comment.AuthorName,
+			CommentBody:    comment.Body,
+			ReportCount:    len(comment.OpenReports()),
+			ModeratorEmail: moderator.Email,
+			CreatedAt:      now,
+		}
+
+		comment.Status = status
+		comment.Flagged = false
+		comment.ModeratedBy = moderator.ID
+		comment.ModeratedAt = &now
+
+		if action == models.ModerationBan {
+			if err := tx.Update(c.collection(UsersCollection).Doc(comment.UserID), []firestore.Update{
+				{Path: "comment_banned", Value: true},
+				{Path: "updated_at", Value: now},
+			}); err != nil {
+				return err
+			}
+		}
+		if err := tx.Set(commentRef, &comment); err != nil {
+			return err
+		}
+		return tx.Set(decisionRef, decision)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("błąd zapisywania decyzji moderatora: %w", err)
+	}
+
+	if wasPublished != comment.IsPublished() {
+		c.publish(events.BookChanged, comment.BookID)
+	}
+	if firstPublication && comment.IsPublished() {
+		c.publish(events.CommentPublished, &comment)
+	}
+	return &comment, nil
+}
+
+func (c *Client) listComments(query firestore.Query) ([]*models.Comment, error) {
+	var comments []*models.Comment
+
+	iter := query.Documents(c.ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("błąd iteracji po komentarzach: %w", err)
+		}
+
+		var comment models.Comment
+		if err := doc.DataTo(&comment); err != nil {
+			return nil, fmt.Errorf("błąd parsowania komentarza: %w", err)
+		}
+
+		comment.ID = doc.Ref.ID
+		comments = append(comments, &comment)
+	}
+
+	return comments, nil
+}
@@ -0,0 +1,146 @@
+package firebase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"library-management-system/internal/events"
+	"library-management-system/internal/models"
+)
+
+// CommunicationsCollection to nazwa kolekcji wiadomości personelu do grup czytelników
+const CommunicationsCollection = "communications"
+
+// QueueCommunication zapisuje wiadomość do grupy czytelników i zleca jej wysyłkę
+// w tle (events.CommunicationQueued)
+func (c *Client) QueueCommunication(comm *models.Communication) error {
+	c, span := c.startSpan("QueueCommunication")
+	defer span.End()
+
+	comm.Subject = strings.TrimSpace(comm.Subject)
+	comm.Body = strings.TrimSpace(comm.Body)
+	if comm.Subject == "" || comm.Body == "" {
+		return fmt.Errorf("temat i treść wiadomości są wymagane")
+	}
+	if models.GetCommunicationAudience(comm.Audience) == nil {
+		return fmt.Errorf("nieznana grupa odbiorców %q", comm.Audience)
+	}
+
+	docRef := c.collection(CommunicationsCollection).NewDoc()
+	comm.ID = docRef.ID
+	comm.CreatedAt = time.Now()
+	if _, err := docRef.Set(c.ctx, comm); err != nil {
+		return fmt.Errorf("błąd zapisywania wiadomości: %w", err)
+	}
+
+	c.publish(events.CommunicationQueued, comm)
+	return nil
+}
+
+// UpdateCommunicationProgress zapisuje liczbę odbiorców i postęp wysyłki wiadomości.
+// Przy finished zapisuje też koniec wysyłki.
+func (c *Client) UpdateCommunicationProgress(id string, recipients, sent int, finished bool) error {
+	c, span := c.startSpan("UpdateCommunicationProgress")
+	defer span.End()
+
+	updates := []firestore.Update{
+		{Path: "recipients", Value: recipients},
+		{Path: "sent", Value: sent},
+	}
+	if finished {
+		updates = append(updates, firestore.Update{Path: "finished_at", Value: time.Now()})
+	}
+	if _, err := c.collection(CommunicationsCollection).Doc(id).Update(c.ctx, updates); err != nil {
+		return fmt.Errorf("błąd zapisywania postępu wysyłki: %w", err)
+	}
+	return nil
+}
+
+// ListCommunications pobiera ostatnie wiadomości do grup czytelników (najnowsze pierwsze)
+func (c *Client) ListCommunications(limit int) ([]*models.Communication, error) {
+	c, span := c.startSpan("ListCommunications")
+	defer span.End()
+
+	iter := c.collection(CommunicationsCollection).OrderBy("created_at", firestore.Desc).Limit(limit).Documents(c.ctx)
+	defer iter.Stop()
+
+	var comms []*models.Communication
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("błąd pobierania wiadomości: %w", err)
+		}
+
+		var comm models.Communication
+		if err := doc.DataTo(&comm); err != nil {
+			return nil, fmt.Errorf("błąd parsowania wiadomości: %w", err)
+		}
+		comm.ID = doc.Ref.ID
+		comms = append(comms, &comm)
+	}
+
+	return comms, nil
+}
+
+// GetCommunicationAudience pobiera czytelników z grupy odbiorców (alfabetycznie według nazwiska).
+// Konta nieaktywne i czekające na zatwierdzenie są pomijane.
+func (c *Client) GetCommunicationAudience(audience models.CommunicationAudience) ([]*models.User, error) {
+	c, span := c.startSpan("GetCommunicationAudience")
+	defer span.End()
+
+	var users []*models.User
+	switch audience {
+	case models.AudienceActiveReaders:
+		active, err := c.GetActiveUsers()
+		if err != nil {
+			return nil, err
+		}
+		users = active
+
+	case models.AudienceOverdueReaders:
+		loans, err := c.GetActiveLoans()
+		if err != nil {
+			return nil, err
+		}
+		var ids []string
+		seen := make(map[string]bool)
+		for _, loan := range loans {
+			if loan.IsOverdue() && !loan.IsReadingRoom() && !seen[loan.UserID] {
+				seen[loan.UserID] = true
+				ids = append(ids, loan.UserID)
+			}
+		}
+		byID, err := c.GetUsersByIDs(ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range byID {
+			users = append(users, user)
+		}
+
+	default:
+		return nil, fmt.Errorf("nieznana grupa odbiorców %q", audience)
+	}
+
+	var readers []*models.User
+	for _, user := range users {
+		if user.Role == models.RoleReader && user.IsActive && !user.PendingApproval {
+			readers = append(readers, user)
+		}
+	}
+	sort.Slice(readers, func(i, j int) bool {
+		if readers[i].LastName != readers[j].LastName {
+			return readers[i].LastName < readers[j].LastName
+		}
+		return readers[i].ID < readers[j].ID
+	})
+	return readers, nil
+}
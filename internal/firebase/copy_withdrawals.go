@@ -0,0 +1,131 @@
+package firebase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+
+	"library-management-system/internal/models"
+)
+
+const (
+	// CopyWithdrawalsCollection to nazwa kolekcji wycofanych egzemplarzy w Firestore
+	CopyWithdrawalsCollection = "copy_withdrawals"
+)
+
+// WithdrawCopies wycofuje egzemplarze z księgozbioru: zmniejsza liczbę wszystkich
+// i dostępnych egzemplarzy książki oraz zapisuje wpis historii w jednej transakcji.
+// Wycofać można tylko egzemplarze stojące na półce - wypożyczone muszą najpierw wrócić.
+func (c *Client) WithdrawCopies(withdrawal *models.CopyWithdrawal) error {
+	c, span := c.startSpan("WithdrawCopies")
+	defer span.End()
+
+	if withdrawal == nil {
+		return fmt.Errorf("wycofanie nie może być nil")
+	}
+	if withdrawal.BookID == "" {
+		return fmt.Errorf("ID książki nie może być puste")
+	}
+	if !models.ValidWithdrawalReason(withdrawal.Reason) {
+		return fmt.Errorf("nieznany powód wycofania")
+	}
+	if withdrawal.Count < 1 {
+		return fmt.Errorf("liczba wycofywanych egzemplarzy musi być większa od zera")
+	}
+	withdrawal.Notes = strings.TrimSpace(withdrawal.Notes)
+
+	bookRef := c.collection(BooksCollection).Doc(withdrawal.BookID)
+	withdrawalRef := c.collection(CopyWithdrawalsCollection).NewDoc()
+	withdrawal.ID = withdrawalRef.ID
+
+	err := c.Firestore.RunTransaction(c.ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(bookRef)
+		if err != nil {
+			return fmt.Errorf("błąd pobierania książki: %w", err)
+		}
+
+		var book models.Book
+		if err := doc.DataTo(&book); err != nil {
+			return fmt.Errorf("błąd parsowania danych książki: %w", err)
+		}
+
+		if withdrawal.Count > book.AvailableCopies {
+			return fmt.Errorf("dostępnych na półce egzemplarzy: %d - wypożyczone i odłożone dla czytelników trzeba najpierw odebrać", book.AvailableCopies)
+		}
+		if withdrawal.Count >= book.TotalCopies {
+			return fmt.Errorf("nie można wycofać wszystkich egzemplarzy - aby usunąć tytuł, usuń książkę z katalogu")
+		}
+
+		now := time.Now()
+		withdrawal.BookTitle = book.Title
+		withdrawal.CreatedAt = now
+
+		if err := tx.Update(bookRef, []firestore.Update{
+			{Path: "total_copies", Value: book.TotalCopies - withdrawal.Count},
+			{Path: "available_copies", Value: book.AvailableCopies - withdrawal.Count},
+			{Path: "updated_at", Value: now},
+		}); err != nil {
+			return err
+		}
+		return tx.Set(withdrawalRef, withdrawal)
+	})
+	if err != nil {
+		return fmt.Errorf("błąd wycofywania egzemplarzy: %w", err)
+	}
+
+	return nil
+}
+
+// GetBookWithdrawals pobiera historię wycofań egzemplarzy książki (najnowsze pierwsze)
+func (c *Client) GetBookWithdrawals(bookID string) ([]*models.CopyWithdrawal, error) {
+	c, span := c.startSpan("GetBookWithdrawals")
+	defer span.End()
+
+	docs, err := c.collection(CopyWithdrawalsCollection).
+		Where("book_id", "==", bookID).
+		Documents(c.ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania wycofanych egzemplarzy: %w", err)
+	}
+
+	withdrawals := make([]*models.CopyWithdrawal, 0, len(docs))
+	for _, doc := range docs {
+		var withdrawal models.CopyWithdrawal
+		if err := doc.DataTo(&withdrawal); err != nil {
+			return nil, fmt.Errorf("błąd parsowania wycofania: %w", err)
+		}
+		withdrawals = append(withdrawals, &withdrawal)
+	}
+
+	sort.Slice(withdrawals, func(i, j int) bool {
+		return withdrawals[i].CreatedAt.After(withdrawals[j].CreatedAt)
+	})
+	return withdrawals, nil
+}
+
+// GetWithdrawalsSince pobiera wycofania egzemplarzy od podanej daty
+func (c *Client) GetWithdrawalsSince(from time.Time) ([]*models.CopyWithdrawal, error) {
+	c, span := c.startSpan("GetWithdrawalsSince")
+	defer span.End()
+
+	docs, err := c.collection(CopyWithdrawalsCollection).
+		Where("created_at", ">=", from).
+		Documents(c.ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania wycofanych egzemplarzy: %w", err)
+	}
+
+	withdrawals := make([]*models.CopyWithdrawal, 0, len(docs))
+	for _, doc := range docs {
+		var withdrawal models.CopyWithdrawal
+		if err := doc.DataTo(&withdrawal); err != nil {
+			return nil, fmt.Errorf("błąd parsowania wycofania: %w", err)
+		}
+		withdrawals = append(withdrawals, &withdrawal)
+	}
+	return withdrawals, nil
+}
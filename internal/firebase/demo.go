@@ -0,0 +1,109 @@
+package firebase
+
+import (
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+	"firebase.google.com/go/v4/auth"
+	"google.golang.org/api/iterator"
+)
+
+// wipeBatchSize to liczba dokumentów usuwanych w jednym zapytaniu
+const wipeBatchSize = 200
+
+// WipeAllData usuwa wszystkie dane aplikacji z Firestore oraz konta Firebase Auth
+// powiązane z profilami użytkowników. Używane wyłącznie przez tryb demonstracyjny.
+func (c *Client) WipeAllData() error {
+	c, span := c.startSpan("WipeAllData")
+	defer span.End()
+
+	// Najpierw konta Auth - ich UID są zapisane w profilach, które zaraz zostaną usunięte
+	iter := c.collection(UsersCollection).Select("firebase_uid").Documents(c.ctx)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			iter.Stop()
+			return fmt.Errorf("błąd pobierania użytkowników: %w", err)
+		}
+		if uid, ok := doc.Data()["firebase_uid"].(string); ok && uid != "" {
+			if err := c.Auth.DeleteUser(c.ctx, uid); err != nil && !auth.IsUserNotFound(err) {
+				iter.Stop()
+				return fmt.Errorf("błąd usuwania konta Firebase Auth: %w", err)
+			}
+		}
+	}
+	iter.Stop()
+
+	collections := []string{
+		BooksCollection,
+		LoansCollection,
+		ReservationsCollection,
+		UsersCollection,
+		AnnouncementsCollection,
+		ReadingListsCollection,
+		UserReadingListsCollection,
+		CommentsCollection,
+		ModerationLogCollection,
+		BadgesCollection,
+		EmailTemplatesCollection,
+		PublicStatsCollection,
+		NotificationsCollection,
+		NotificationDeliveriesCollection,
+		NewsletterIssuesCollection,
+		RegulationsCollection,
+		RegulationsAcceptancesCollection,
+		PushSubscriptionsCollection,
+		TelegramLinksCollection,
+		TelegramLinkCodesCollection,
+		SubscriptionsCollection,
+		SavedSearchesCollection,
+		PurchaseSuggestionsCollection,
+		AnalyticsCollection,
+		TrashCollection,
+		AuditLogCollection,
+		SettingsCollection,
+	}
+	for _, name := range collections {
+		if err := c.deleteCollection(name); err != nil {
+			return err
+		}
+	}
+
+	c.cache.settings.Store(nil)
+	c.cache.regulations.Store(nil)
+	return nil
+}
+
+// deleteCollection usuwa wszystkie dokumenty kolekcji partiami
+func (c *Client) deleteCollection(name string) error {
+	for {
+		docs, err := c.collection(name).Limit(wipeBatchSize).Documents(c.ctx).GetAll()
+		if err != nil {
+			return fmt.Errorf("błąd pobierania dokumentów z %s: %w", name, err)
+		}
+		if len(docs) == 0 {
+			return nil
+		}
+
+		bulk := c.Firestore.BulkWriter(c.ctx)
+		jobs := make([]*firestore.BulkWriterJob, 0, len(docs))
+		for _, doc := range docs {
+			job, err := bulk.Delete(doc.Ref)
+			if err != nil {
+				bulk.End()
+				return fmt.Errorf("błąd usuwania dokumentu z %s: %w", name, err)
+			}
+			jobs = append(jobs, job)
+		}
+		bulk.End()
+
+		for _, job := range jobs {
+			if _, err := job.Results(); err != nil {
+				return fmt.Errorf("błąd usuwania dokumentu z %s: %w", name, err)
+			}
+		}
+	}
+}
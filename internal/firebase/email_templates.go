@@ -0,0 +1,106 @@
+package firebase
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"library-management-system/internal/models"
+)
+
+// EmailTemplatesCollection to nazwa kolekcji szablonów email zmienionych przez personel.
+// ID dokumentu to klucz rodzaju wiadomości.
+const EmailTemplatesCollection = "email_templates"
+
+// GetEmailTemplate pobiera szablon wiadomości. Jeśli personel go nie zmieniał,
+// zwraca treść domyślną.
+func (c *Client) GetEmailTemplate(key models.EmailTemplateKey) (*models.EmailTemplate, error) {
+	c, span := c.startSpan("GetEmailTemplate")
+	defer span.End()
+
+	kind := models.GetEmailTemplateKind(key)
+	if kind == nil {
+		return nil, fmt.Errorf("nieznany rodzaj wiadomości: %s", key)
+	}
+
+	doc, err := c.collection(EmailTemplatesCollection).Doc(string(key)).Get(c.ctx)
+	if status.Code(err) == codes.NotFound {
+		return kind.Default(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania szablonu wiadomości: %w", err)
+	}
+
+	var template models.EmailTemplate
+	if err := doc.DataTo(&template); err != nil {
+		return nil, fmt.Errorf("błąd parsowania szablonu wiadomości: %w", err)
+	}
+	template.Key = key
+	template.Custom = true
+
+	return &template, nil
+}
+
+// GetCustomEmailTemplates pobiera szablony zmienione przez personel według klucza
+func (c *Client) GetCustomEmailTemplates() (map[models.EmailTemplateKey]*models.EmailTemplate, error) {
+	c, span := c.startSpan("GetCustomEmailTemplates")
+	defer span.End()
+
+	docs, err := c.collection(EmailTemplatesCollection).Documents(c.ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania szablonów wiadomości: %w", err)
+	}
+
+	templates := make(map[models.EmailTemplateKey]*models.EmailTemplate)
+	for _, doc := range docs {
+		var template models.EmailTemplate
+		if err := doc.DataTo(&template); err != nil {
+			continue
+		}
+		template.Key = models.EmailTemplateKey(doc.Ref.ID)
+		template.Custom = true
+		templates[template.Key] = &template
+	}
+
+	return templates, nil
+}
+
+// SaveEmailTemplate zapisuje treść szablonu wiadomości
+func (c *Client) SaveEmailTemplate(template *models.EmailTemplate) error {
+	c, span := c.startSpan("SaveEmailTemplate")
+	defer span.End()
+
+	kind := models.GetEmailTemplateKind(template.Key)
+	if kind == nil {
+		return fmt.Errorf("nieznany rodzaj wiadomości: %s", template.Key)
+	}
+	if err := kind.Validate(template); err != nil {
+		return err
+	}
+
+	template.UpdatedAt = time.Now()
+	if _, err := c.collection(EmailTemplatesCollection).Doc(string(template.Key)).Set(c.ctx, template); err != nil {
+		return fmt.Errorf("błąd zapisywania szablonu wiadomości: %w", err)
+	}
+	template.Custom = true
+
+	return nil
+}
+
+// ResetEmailTemplate przywraca domyślną treść szablonu
+func (c *Client) ResetEmailTemplate(key models.EmailTemplateKey) error {
+	c, span := c.startSpan("ResetEmailTemplate")
+	defer span.End()
+
+	if models.GetEmailTemplateKind(key) == nil {
+		return fmt.Errorf("nieznany rodzaj wiadomości: %s", key)
+	}
+
+	if _, err := c.collection(EmailTemplatesCollection).Doc(string(key)).Delete(c.ctx); err != nil {
+		return fmt.Errorf("błąd usuwania szablonu wiadomości: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package firebase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"library-management-system/internal/models"
+)
+
+const (
+	// FinePaymentsCollection to nazwa kolekcji wpłat kar w Firestore
+	FinePaymentsCollection = "fine_payments"
+)
+
+// RecordFinePayment zapisuje wpłatę kary przyjętą przy ladzie i zmniejsza sumę kar
+// czytelnika. Zapis wpłaty i zmiana salda odbywają się w jednej transakcji.
+func (c *Client) RecordFinePayment(payment *models.FinePayment) error {
+	c, span := c.startSpan("RecordFinePayment")
+	defer span.End()
+
+	if payment == nil {
+		return fmt.Errorf("wpłata nie może być nil")
+	}
+	if payment.UserID == "" {
+		return fmt.Errorf("ID użytkownika nie może być puste")
+	}
+	if !models.ValidPaymentMethod(payment.Method) {
+		return fmt.Errorf("nieznany sposób płatności")
+	}
+	payment.ReceiptNumber = strings.TrimSpace(payment.ReceiptNumber)
+	if payment.ReceiptNumber == "" {
+		return fmt.Errorf("numer paragonu jest wymagany")
+	}
+
+	if payment.Amount <= 0 {
+		return fmt.Errorf("kwota wpłaty musi być większa od zera")
+	}
+
+	userRef := c.collection(UsersCollection).Doc(payment.UserID)
+	paymentRef := c.collection(FinePaymentsCollection).NewDoc()
+	payment.ID = paymentRef.ID
+
+	err := c.Firestore.RunTransaction(c.ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(userRef)
+		if err != nil {
+			return fmt.Errorf("błąd pobierania użytkownika: %w", err)
+		}
+
+		var user models.User
+		if err := doc.DataTo(&user); err != nil {
+			return fmt.Errorf("błąd parsowania danych użytkownika: %w", err)
+		}
+
+		if payment.Amount > user.TotalFines {
+			settings := c.loanPolicy()
+			return fmt.Errorf("kwota wpłaty (%s) przekracza sumę kar czytelnika (%s)",
+				settings.FormatMoney(payment.Amount), settings.FormatMoney(user.TotalFines))
+		}
+
+		now := time.Now()
+		payment.UserName = user.FullName()
+		payment.CreatedAt = now
+
+		if err := tx.Update(userRef, []firestore.Update{
+			{Path: "total_fines_gr", Value: user.TotalFines - payment.Amount},
+			{Path: "updated_at", Value: now},
+		}); err != nil {
+			return err
+		}
+		return tx.Set(paymentRef, payment)
+	})
+	if err != nil {
+		return fmt.Errorf("błąd zapisywania wpłaty: %w", err)
+	}
+
+	return nil
+}
+
+// GetFinePayment pobiera wpłatę kary po ID
+func (c *Client) GetFinePayment(id string) (*models.FinePayment, error) {
+	c, span := c.startSpan("GetFinePayment")
+	defer span.End()
+
+	if id == "" {
+		return nil, fmt.Errorf("ID wpłaty nie może być puste")
+	}
+
+	doc, err := c.collection(FinePaymentsCollection).Doc(id).Get(c.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania wpłaty: %w", err)
+	}
+
+	var payment models.FinePayment
+	if err := doc.DataTo(&payment); err != nil {
+		return nil, fmt.Errorf("błąd parsowania wpłaty: %w", err)
+	}
+
+	return &payment, nil
+}
+
+// ListFinePayments pobiera wpłaty kar przyjęte w przedziale [from, to) (najstarsze pierwsze)
+func (c *Client) ListFinePayments(from, to time.Time) ([]*models.FinePayment, error) {
+	c, span := c.startSpan("ListFinePayments")
+	defer span.End()
+
+	var payments []*models.FinePayment
+
+	iter := c.collection(FinePaymentsCollection).
+		Where("created_at", ">=", from).
+		Where("created_at", "<", to).
+		OrderBy("created_at", firestore.Asc).
+		Documents(c.ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("błąd iteracji po wpłatach: %w", err)
+		}
+
+		var payment models.FinePayment
+		if err := doc.DataTo(&payment); err != nil {
+			return nil, fmt.Errorf("błąd parsowania wpłaty: %w", err)
+		}
+
+		payments = append(payments, &payment)
+	}
+
+	return payments, nil
+}
@@ -0,0 +1,214 @@
+package firebase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"library-management-system/internal/models"
+)
+
+const (
+	// FineWaiversCollection to nazwa kolekcji dziennika umorzeń kar w Firestore
+	FineWaiversCollection = "fine_waivers"
+)
+
+// FindWaivableFines pobiera nieumorzone kary za wypożyczenia spełniające kryteria
+func (c *Client) FindWaivableFines(criteria models.FineWaiverCriteria) ([]*models.Loan, error) {
+	c, span := c.startSpan("FindWaivableFines")
+	defer span.End()
+
+	var loans []*models.Loan
+
+	// Kary ma niewielka część wypożyczeń - pozostałe kryteria sprawdzamy w pamięci
+	iter := c.collection(LoansCollection).
+		Where("fine_amount_gr", ">", 0).
+		Documents(c.ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("błąd iteracji po wypożyczeniach: %w", err)
+		}
+
+		var loan models.Loan
+		if err := doc.DataTo(&loan); err != nil {
+			return nil, fmt.Errorf("błąd parsowania wypożyczenia: %w", err)
+		}
+
+		// Zanonimizowane wypożyczenie nie ma już czytelnika, któremu można zmniejszyć saldo
+		if !loan.Anonymized && criteria.Matches(&loan) {
+			loans = append(loans, &loan)
+		}
+	}
+
+	return loans, nil
+}
+
+// WaiveFines umarza kary spełniające kryteria i zapisuje wpis w dzienniku umorzeń.
+// Saldo każdego czytelnika jest zmieniane w osobnej transakcji i nie spada poniżej zera
+// (część kar mogła zostać już zapłacona przy ladzie).
+func (c *Client) WaiveFines(criteria models.FineWaiverCriteria, reason, performedBy string) (*models.FineWaiver, error) {
+	c, span := c.startSpan("WaiveFines")
+	defer span.End()
+
+	if criteria.IsEmpty() {
+		return nil, fmt.Errorf("podaj co najmniej jedno kryterium umorzenia")
+	}
+
+	loans, err := c.FindWaivableFines(criteria)
+	if err != nil {
+		return nil, err
+	}
+
+	byUser := make(map[string][]string)
+	var userIDs []string
+	for _, loan := range loans {
+		if _, ok := byUser[loan.UserID]; !ok {
+			userIDs = append(userIDs, loan.UserID)
+		}
+		byUser[loan.UserID] = append(byUser[loan.UserID], loan.ID)
+	}
+
+	waiver := &models.FineWaiver{
+		Criteria:    criteria,
+		Reason:      reason,
+		PerformedBy: performedBy,
+		CreatedAt:   time.Now(),
+	}
+
+	var waiveErr error
+	for _, userID := range userIDs {
+		count, amount, err := c.waiveUserFines(userID, byUser[userID], criteria)
+		if err != nil {
+			waiveErr = fmt.Errorf("błąd umarzania kar czytelnika %s: %w", userID, err)
+			break
+		}
+		if count > 0 {
+			waiver.LoanCount += count
+			waiver.ReaderCount++
+			waiver.Amount += amount
+		}
+	}
+
+	// Wpis dziennika zapisujemy także po częściowym niepowodzeniu - opisuje to, co już umorzono
+	docRef := c.collection(FineWaiversCollection).NewDoc()
+	waiver.ID = docRef.ID
+	if _, err := docRef.Set(c.ctx, waiver); err != nil {
+		log.Printf("Błąd zapisu dziennika umorzenia (%d kar, %s): %v", waiver.LoanCount, waiver.Amount, err)
+		if waiveErr == nil {
+			waiveErr = fmt.Errorf("błąd zapisu dziennika umorzeń: %w", err)
+		}
+	}
+
+	return waiver, waiveErr
+}
+
+// waiveUserFines umarza wskazane kary jednego czytelnika. Zwraca liczbę umorzonych kar
+// i kwotę zdjętą z salda.
+func (c *Client) waiveUserFines(userID string, loanIDs []string, criteria models.FineWaiverCriteria) (int, models.Money, error) {
+	userRef := c.collection(UsersCollection).Doc(userID)
+
+	var count int
+	var amount models.Money
+	err := c.Firestore.RunTransaction(c.ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		count, amount = 0, 0 // Transakcja może być ponawiana
+
+		userDoc, err := tx.Get(userRef)
+		if err != nil {
+			return err
+		}
+		var user models.User
+		if err := userDoc.DataTo(&user); err != nil {
+			return err
+		}
+
+		// Wszystkie odczyty muszą poprzedzać zapisy w transakcji
+		var waived []*firestore.DocumentRef
+		var total models.Money
+		for _, loanID := range loanIDs {
+			loanRef := c.collection(LoansCollection).Doc(loanID)
+			loanDoc, err := tx.Get(loanRef)
+			if err != nil {
+				return err
+			}
+			var loan models.Loan
+			if err := loanDoc.DataTo(&loan); err != nil {
+				return err
+			}
+			// Kara mogła zostać umorzona w międzyczasie
+			if !criteria.Matches(&loan) {
+				continue
+			}
+			waived = append(waived, loanRef)
+			total += loan.FineAmount
+		}
+		if len(waived) == 0 {
+			return nil
+		}
+
+		now := time.Now()
+		for _, loanRef := range waived {
+			if err := tx.Update(loanRef, []firestore.Update{
+				{Path: "fine_waived", Value: true},
+				{Path: "updated_at", Value: now},
+			}); err != nil {
+				return err
+			}
+		}
+
+		amount = min(total, user.TotalFines)
+		count = len(waived)
+
+		return tx.Update(userRef, []firestore.Update{
+			{Path: "total_fines_gr", Value: user.TotalFines - amount},
+			{Path: "updated_at", Value: now},
+		})
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return count, amount, nil
+}
+
+// ListFineWaivers pobiera ostatnie wpisy dziennika umorzeń (najnowsze pierwsze)
+func (c *Client) ListFineWaivers(limit int) ([]*models.FineWaiver, error) {
+	c, span := c.startSpan("ListFineWaivers")
+	defer span.End()
+
+	var waivers []*models.FineWaiver
+
+	iter := c.collection(FineWaiversCollection).
+		OrderBy("created_at", firestore.Desc).
+		Limit(limit).
+		Documents(c.ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("błąd iteracji po umorzeniach: %w", err)
+		}
+
+		var waiver models.FineWaiver
+		if err := doc.DataTo(&waiver); err != nil {
+			return nil, fmt.Errorf("błąd parsowania umorzenia: %w", err)
+		}
+
+		waivers = append(waivers, &waiver)
+	}
+
+	return waivers, nil
+}
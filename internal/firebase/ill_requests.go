@@ -0,0 +1,170 @@
+package firebase
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"library-management-system/internal/models"
+)
+
+const (
+	// ILLRequestsCollection to nazwa kolekcji zamówień międzybibliotecznych w Firestore
+	ILLRequestsCollection = "ill_requests"
+)
+
+// CreateILLRequest zapisuje zamówienie międzybiblioteczne zgłoszone przez czytelnika
+func (c *Client) CreateILLRequest(request *models.ILLRequest) error {
+	c, span := c.startSpan("CreateILLRequest")
+	defer span.End()
+
+	if request == nil {
+		return fmt.Errorf("zamówienie nie może być nil")
+	}
+	if request.UserID == "" || request.Title == "" {
+		return fmt.Errorf("czytelnik i tytuł są wymagane")
+	}
+
+	now := time.Now()
+	request.Status = models.ILLStatusRequested
+	request.CreatedAt = now
+	request.UpdatedAt = now
+
+	docRef := c.collection(ILLRequestsCollection).NewDoc()
+	request.ID = docRef.ID
+
+	if _, err := docRef.Set(c.ctx, request); err != nil {
+		return fmt.Errorf("błąd zapisywania zamówienia: %w", err)
+	}
+
+	return nil
+}
+
+// GetILLRequest pobiera zamówienie międzybiblioteczne po ID
+func (c *Client) GetILLRequest(id string) (*models.ILLRequest, error) {
+	c, span := c.startSpan("GetILLRequest")
+	defer span.End()
+
+	if id == "" {
+		return nil, fmt.Errorf("ID zamówienia nie może być puste")
+	}
+
+	doc, err := c.collection(ILLRequestsCollection).Doc(id).Get(c.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania zamówienia: %w", err)
+	}
+
+	var request models.ILLRequest
+	if err := doc.DataTo(&request); err != nil {
+		return nil, fmt.Errorf("błąd parsowania zamówienia: %w", err)
+	}
+	request.ID = doc.Ref.ID
+
+	return &request, nil
+}
+
+// UpdateILLRequest zapisuje zmiany personelu w zamówieniu (status, partner, termin, opłata)
+func (c *Client) UpdateILLRequest(request *models.ILLRequest) error {
+	c, span := c.startSpan("UpdateILLRequest")
+	defer span.End()
+
+	if request == nil || request.ID == "" {
+		return fmt.Errorf("ID zamówienia nie może być puste")
+	}
+	if !models.ValidILLStatus(request.Status) {
+		return fmt.Errorf("nieobsługiwany status zamówienia %s", request.Status)
+	}
+	if request.Fee < 0 {
+		return fmt.Errorf("opłata nie może być ujemna")
+	}
+
+	request.UpdatedAt = time.Now()
+	if _, err := c.collection(ILLRequestsCollection).Doc(request.ID).Set(c.ctx, request); err != nil {
+		return fmt.Errorf("błąd aktualizacji zamówienia: %w", err)
+	}
+
+	return nil
+}
+
+// AddILLMessage dopisuje wpis korespondencji z biblioteką partnerską do zamówienia
+func (c *Client) AddILLMessage(id string, message models.ILLMessage) error {
+	c, span := c.startSpan("AddILLMessage")
+	defer span.End()
+
+	if id == "" {
+		return fmt.Errorf("ID zamówienia nie może być puste")
+	}
+
+	if message.CreatedAt.IsZero() {
+		message.CreatedAt = time.Now()
+	}
+
+	_, err := c.collection(ILLRequestsCollection).Doc(id).Update(c.ctx, []firestore.Update{
+		{Path: "messages", Value: firestore.ArrayUnion(message)},
+		{Path: "updated_at", Value: time.Now()},
+	})
+	if err != nil {
+		return fmt.Errorf("błąd zapisywania korespondencji: %w", err)
+	}
+
+	return nil
+}
+
+// ListILLRequests pobiera zamówienia międzybiblioteczne (najnowsze pierwsze)
+func (c *Client) ListILLRequests() ([]*models.ILLRequest, error) {
+	c, span := c.startSpan("ListILLRequests")
+	defer span.End()
+
+	return c.illRequests(c.collection(ILLRequestsCollection).OrderBy("created_at", firestore.Desc))
+}
+
+// GetUserILLRequests pobiera zamówienia czytelnika (najnowsze pierwsze)
+func (c *Client) GetUserILLRequests(userID string) ([]*models.ILLRequest, error) {
+	c, span := c.startSpan("GetUserILLRequests")
+	defer span.End()
+
+	if userID == "" {
+		return nil, fmt.Errorf("ID użytkownika nie może być puste")
+	}
+
+	requests, err := c.illRequests(c.collection(ILLRequestsCollection).Where("user_id", "==", userID))
+	if err != nil {
+		return nil, err
+	}
+
+	// Sortowanie w aplikacji - bez indeksu złożonego user_id + created_at
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].CreatedAt.After(requests[j].CreatedAt)
+	})
+	return requests, nil
+}
+
+func (c *Client) illRequests(query firestore.Query) ([]*models.ILLRequest, error) {
+	var requests []*models.ILLRequest
+
+	iter := query.Documents(c.ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("błąd iteracji po zamówieniach: %w", err)
+		}
+
+		var request models.ILLRequest
+		if err := doc.DataTo(&request); err != nil {
+			return nil, fmt.Errorf("błąd parsowania zamówienia: %w", err)
+		}
+
+		request.ID = doc.Ref.ID
+		requests = append(requests, &request)
+	}
+
+	return requests, nil
+}
@@ -0,0 +1,98 @@
+package firebase
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"library-management-system/internal/models"
+)
+
+const (
+	// cardNumberLength to liczba cyfr numeru karty - same cyfry, żeby numer dało się
+	// wpisać ręcznie i odczytać każdym czytnikiem kodów
+	cardNumberLength   = 12
+	cardNumberAttempts = 5
+)
+
+// GetUserByCardNumber pobiera czytelnika po numerze karty bibliotecznej
+func (c *Client) GetUserByCardNumber(number string) (*models.User, error) {
+	c, span := c.startSpan("GetUserByCardNumber")
+	defer span.End()
+
+	if number == "" {
+		return nil, fmt.Errorf("numer karty nie może być pusty")
+	}
+
+	iter := c.collection(UsersCollection).Where("card_number", "==", number).Limit(1).Documents(c.ctx)
+	defer iter.Stop()
+
+	doc, err := iter.Next()
+	if err == iterator.Done {
+		return nil, nil // Nie znaleziono czytelnika
+	}
+	if err != nil {
+		return nil, fmt.Errorf("błąd wyszukiwania czytelnika po numerze karty: %w", err)
+	}
+
+	var user models.User
+	if err := doc.DataTo(&user); err != nil {
+		return nil, fmt.Errorf("błąd parsowania danych użytkownika: %w", err)
+	}
+	user.ID = doc.Ref.ID
+	user.Tenant = c.tenant
+
+	return &user, nil
+}
+
+// EnsureUserCardNumber nadaje numer karty czytelnikowi, który jeszcze go nie ma
+func (c *Client) EnsureUserCardNumber(user *models.User) error {
+	c, span := c.startSpan("EnsureUserCardNumber")
+	defer span.End()
+
+	if user.CardNumber != "" {
+		return nil
+	}
+
+	number, err := c.newCardNumber()
+	if err != nil {
+		return err
+	}
+
+	_, err = c.collection(UsersCollection).Doc(user.ID).Update(c.ctx, []firestore.Update{
+		{Path: "card_number", Value: number},
+	})
+	if err != nil {
+		return fmt.Errorf("błąd zapisywania numeru karty: %w", err)
+	}
+
+	user.CardNumber = number
+	return nil
+}
+
+// newCardNumber losuje numer karty, który nie jest jeszcze przypisany do żadnego czytelnika.
+// Numer jest losowy, a nie kolejny, żeby nie dało się odgadnąć numerów innych czytelników.
+func (c *Client) newCardNumber() (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(cardNumberLength), nil)
+
+	for i := 0; i < cardNumberAttempts; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("błąd generowania numeru karty: %w", err)
+		}
+		number := fmt.Sprintf("%0*d", cardNumberLength, n)
+
+		existing, err := c.GetUserByCardNumber(number)
+		if err != nil {
+			return "", err
+		}
+		if existing == nil {
+			return number, nil
+		}
+	}
+
+	return "", fmt.Errorf("nie udało się wygenerować unikalnego numeru karty")
+}
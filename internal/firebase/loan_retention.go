@@ -0,0 +1,96 @@
+package firebase
+
+import (
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"library-management-system/internal/models"
+)
+
+// AnonymizeOldLoans usuwa dane czytelnika z wypożyczeń zwróconych przed cutoff: powiązanie
+// z kontem, imię i nazwisko, kod odbioru i notatki personelu. Książka, daty, rodzaj
+// wypożyczenia i kara zostają, więc raporty i statystyki nadal je liczą. Pomijane są
+// wypożyczenia z nieumorzoną karą czytelnika, który ma jeszcze coś do zapłaty - kara może
+// dotyczyć właśnie tego wypożyczenia. Zwraca liczbę zanonimizowanych wypożyczeń.
+func (c *Client) AnonymizeOldLoans(cutoff time.Time) (int, error) {
+	c, span := c.startSpan("AnonymizeOldLoans")
+	defer span.End()
+
+	iter := c.collection(LoansCollection).
+		Where("status", "==", string(models.LoanStatusReturned)).
+		Where("return_date", "<", cutoff).
+		Documents(c.ctx)
+	defer iter.Stop()
+
+	var loans []*models.Loan
+	var refs []*firestore.DocumentRef
+	var debtorIDs []string
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("błąd iteracji po wypożyczeniach: %w", err)
+		}
+
+		var loan models.Loan
+		if err := doc.DataTo(&loan); err != nil {
+			return 0, fmt.Errorf("błąd parsowania wypożyczenia: %w", err)
+		}
+		if loan.Anonymized {
+			continue
+		}
+		if loan.FineAmount > 0 && !loan.FineWaived {
+			debtorIDs = append(debtorIDs, loan.UserID)
+		}
+		loans = append(loans, &loan)
+		refs = append(refs, doc.Ref)
+	}
+	if len(loans) == 0 {
+		return 0, nil
+	}
+
+	debtors, err := c.GetUsersByIDs(debtorIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	bulk := c.Firestore.BulkWriter(c.ctx)
+	jobs := make([]*firestore.BulkWriterJob, 0, len(loans))
+	for i, loan := range loans {
+		if loan.FineAmount > 0 && !loan.FineWaived {
+			if user, ok := debtors[loan.UserID]; ok && user.TotalFines > 0 {
+				continue
+			}
+		}
+
+		job, err := bulk.Update(refs[i], []firestore.Update{
+			{Path: "user_id", Value: ""},
+			{Path: "user_name", Value: ""},
+			{Path: "pickup_code", Value: ""},
+			{Path: "notes", Value: ""},
+			{Path: "anonymized", Value: true},
+			{Path: "updated_at", Value: now},
+		})
+		if err != nil {
+			bulk.End()
+			return 0, fmt.Errorf("błąd anonimizacji wypożyczenia %s: %w", loan.ID, err)
+		}
+		jobs = append(jobs, job)
+	}
+	bulk.End()
+
+	anonymized := 0
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			return anonymized, fmt.Errorf("błąd anonimizacji wypożyczenia: %w", err)
+		}
+		anonymized++
+	}
+	return anonymized, nil
+}
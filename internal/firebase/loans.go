@@ -1,14 +1,16 @@
 package firebase
 
 import (
+	"context"
 	"fmt"
 	"log"
-	"math/rand"
+	"sort"
 	"time"
 
 	"cloud.google.com/go/firestore"
 	"google.golang.org/api/iterator"
 
+	"library-management-system/internal/events"
 	"library-management-system/internal/models"
 )
 
@@ -17,26 +19,16 @@ const (
 	LoansCollection = "loans"
 )
 
-// GeneratePickupCode generuje losowy 6-znakowy kod alfanumeryczny
-func GeneratePickupCode() string {
-	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	const codeLength = 6
-
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	code := make([]byte, codeLength)
-	for i := range code {
-		code[i] = charset[r.Intn(len(charset))]
-	}
-	return string(code)
-}
-
 // GetLoan pobiera wypożyczenie po ID
 func (c *Client) GetLoan(id string) (*models.Loan, error) {
+	c, span := c.startSpan("GetLoan")
+	defer span.End()
+
 	if id == "" {
 		return nil, fmt.Errorf("ID wypożyczenia nie może być puste")
 	}
 
-	doc, err := c.Firestore.Collection(LoansCollection).Doc(id).Get(c.ctx)
+	doc, err := c.collection(LoansCollection).Doc(id).Get(c.ctx)
 	if err != nil {
 		return nil, fmt.Errorf("błąd pobierania wypożyczenia: %w", err)
 	}
@@ -51,6 +43,9 @@ func (c *Client) GetLoan(id string) (*models.Loan, error) {
 
 // CreateLoan tworzy nowe wypożyczenie
 func (c *Client) CreateLoan(loan *models.Loan) error {
+	c, span := c.startSpan("CreateLoan")
+	defer span.End()
+
 	if loan == nil {
 		return fmt.Errorf("wypożyczenie nie może być nil")
 	}
@@ -66,7 +61,6 @@ func (c *Client) CreateLoan(loan *models.Loan) error {
 	loan.UpdatedAt = now
 	loan.LoanDate = now
 	loan.Status = models.LoanStatusPendingPickup
-	loan.PickupCode = GeneratePickupCode()
 
 	// DueDate zostanie ustawiony gdy admin potwierdzi odbiór
 	loan.DueDate = time.Time{}
@@ -74,22 +68,59 @@ func (c *Client) CreateLoan(loan *models.Loan) error {
 	// Wygeneruj ID
 	var docRef *firestore.DocumentRef
 	if loan.ID == "" {
-		docRef = c.Firestore.Collection(LoansCollection).NewDoc()
+		docRef = c.collection(LoansCollection).NewDoc()
 		loan.ID = docRef.ID
 	} else {
-		docRef = c.Firestore.Collection(LoansCollection).Doc(loan.ID)
+		docRef = c.collection(LoansCollection).Doc(loan.ID)
 	}
 
-	_, err := docRef.Set(c.ctx, loan)
+	// Kod odbioru musi być jedyny wśród książek czekających na odbiór - ConfirmPickup
+	// szuka wypożyczenia po samym kodzie. Sprawdzenie i zapis są w jednej transakcji.
+	format := c.loanPolicy().PickupCode()
+	err := c.Firestore.RunTransaction(c.ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		code, err := c.unusedPickupCode(tx, format)
+		if err != nil {
+			return err
+		}
+		loan.PickupCode = code
+		return tx.Set(docRef, loan)
+	})
 	if err != nil {
 		return fmt.Errorf("błąd zapisywania wypożyczenia: %w", err)
 	}
 
+	c.publish(events.CirculationChanged, loan.ID)
 	return nil
 }
 
+// unusedPickupCode losuje kod odbioru, którego nie ma żadne wypożyczenie czekające na odbiór
+func (c *Client) unusedPickupCode(tx *firestore.Transaction, format models.PickupCodeFormat) (string, error) {
+	for i := 0; i < models.PickupCodeAttempts; i++ {
+		code, err := format.Generate()
+		if err != nil {
+			return "", err
+		}
+
+		taken, err := tx.Documents(c.collection(LoansCollection).
+			Where("pickup_code", "==", code).
+			Where("status", "==", string(models.LoanStatusPendingPickup)).
+			Limit(1)).GetAll()
+		if err != nil {
+			return "", fmt.Errorf("błąd sprawdzania kodu odbioru: %w", err)
+		}
+		if len(taken) == 0 {
+			return code, nil
+		}
+	}
+
+	return "", fmt.Errorf("nie udało się wygenerować unikalnego kodu odbioru - wydłuż kody w ustawieniach")
+}
+
 // UpdateLoan aktualizuje wypożyczenie
 func (c *Client) UpdateLoan(id string, loan *models.Loan) error {
+	c, span := c.startSpan("UpdateLoan")
+	defer span.End()
+
 	if id == "" {
 		return fmt.Errorf("ID wypożyczenia nie może być puste")
 	}
@@ -105,135 +136,283 @@ func (c *Client) UpdateLoan(id string, loan *models.Loan) error {
 	loan.UpdatedAt = time.Now()
 	loan.ID = id
 
-	_, err = c.Firestore.Collection(LoansCollection).Doc(id).Set(c.ctx, loan)
+	_, err = c.collection(LoansCollection).Doc(id).Set(c.ctx, loan)
 	if err != nil {
 		return fmt.Errorf("błąd aktualizacji wypożyczenia: %w", err)
 	}
 
+	c.publish(events.CirculationChanged, id)
 	return nil
 }
 
-// ConfirmPickup potwierdza odbiór książki przez użytkownika
-func (c *Client) ConfirmPickup(pickupCode string) error {
-	if pickupCode == "" {
-		return fmt.Errorf("kod odbioru nie może być pusty")
-	}
+// GetLoanByPickupCode pobiera wypożyczenie oczekujące na odbiór po kodzie odbioru
+func (c *Client) GetLoanByPickupCode(pickupCode string) (*models.Loan, error) {
+	c, span := c.startSpan("GetLoanByPickupCode")
+	defer span.End()
 
-	// Znajdź wypożyczenie po kodzie odbioru
-	iter := c.Firestore.Collection(LoansCollection).
+	iter := c.collection(LoansCollection).
 		Where("pickup_code", "==", pickupCode).
 		Where("status", "==", string(models.LoanStatusPendingPickup)).
 		Limit(1).
 		Documents(c.ctx)
+	defer iter.Stop()
 
 	doc, err := iter.Next()
 	if err == iterator.Done {
-		return fmt.Errorf("nie znaleziono wypożyczenia z kodem %s", pickupCode)
+		return nil, nil // Brak wypożyczenia z tym kodem
 	}
 	if err != nil {
-		return fmt.Errorf("błąd wyszukiwania wypożyczenia: %w", err)
+		return nil, fmt.Errorf("błąd wyszukiwania wypożyczenia: %w", err)
 	}
 
 	var loan models.Loan
 	if err := doc.DataTo(&loan); err != nil {
-		return fmt.Errorf("błąd parsowania danych wypożyczenia: %w", err)
+		return nil, fmt.Errorf("błąd parsowania danych wypożyczenia: %w", err)
+	}
+	loan.ID = doc.Ref.ID
+
+	return &loan, nil
+}
+
+// ConfirmPickup potwierdza odbiór książki przez użytkownika przy ladzie
+func (c *Client) ConfirmPickup(pickupCode string) error {
+	c, span := c.startSpan("ConfirmPickup")
+	defer span.End()
+
+	loan, err := c.activateLoan(pickupCode)
+	if err != nil {
+		return err
+	}
+	c.publish(events.LoanPickedUp, loan)
+	return nil
+}
+
+// activateLoan wydaje zamówioną książkę: ustawia wypożyczenie z kodem odbioru jako aktywne
+// z terminem zwrotu (okres wypożyczenia z ustawień biblioteki)
+func (c *Client) activateLoan(pickupCode string) (*models.Loan, error) {
+	if pickupCode == "" {
+		return nil, fmt.Errorf("kod odbioru nie może być pusty")
+	}
+
+	loan, err := c.GetLoanByPickupCode(pickupCode)
+	if err != nil {
+		return nil, err
+	}
+	if loan == nil {
+		return nil, fmt.Errorf("nie znaleziono wypożyczenia z kodem %s", pickupCode)
 	}
 
-	// Ustaw status na active i ustaw termin zwrotu (14 dni od teraz)
 	now := time.Now()
 	loan.Status = models.LoanStatusActive
-	loan.DueDate = now.AddDate(0, 0, 14)
+	loan.DueDate = now.AddDate(0, 0, c.loanPolicy().LoanDays)
 	loan.UpdatedAt = now
 
 	// Zapisz zmiany
-	_, err = c.Firestore.Collection(LoansCollection).Doc(loan.ID).Set(c.ctx, &loan)
-	if err != nil {
-		return fmt.Errorf("błąd aktualizacji wypożyczenia: %w", err)
+	if _, err := c.collection(LoansCollection).Doc(loan.ID).Set(c.ctx, loan); err != nil {
+		return nil, fmt.Errorf("błąd aktualizacji wypożyczenia: %w", err)
 	}
+	c.publish(events.CirculationChanged, loan.ID)
 
 	log.Printf("Potwierdzono odbiór dla wypożyczenia %s (kod: %s)", loan.ID, pickupCode)
-	return nil
+	return loan, nil
 }
 
-// ReturnLoan obsługuje zwrot książki
-func (c *Client) ReturnLoan(loanID string) error {
+// RenewLoan przedłuża termin zwrotu wypożyczenia czytelnika o okres wypożyczenia
+// z ustawień biblioteki. Przedłużyć nie można wypożyczenia po terminie, po wyczerpaniu
+// limitu models.MaxRenewals ani książki, na którą czeka ktoś z kolejki rezerwacji.
+func (c *Client) RenewLoan(loanID, userID string) (*models.Loan, error) {
+	c, span := c.startSpan("RenewLoan")
+	defer span.End()
+
 	loan, err := c.GetLoan(loanID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if loan.UserID != userID {
+		return nil, fmt.Errorf("wypożyczenie należy do innego czytelnika")
+	}
+	if !loan.IsBorrowed() || loan.IsReadingRoom() {
+		return nil, fmt.Errorf("tego wypożyczenia nie można przedłużyć")
+	}
+	if loan.IsOverdue() {
+		return nil, fmt.Errorf("termin zwrotu już minął - przedłużenie jest możliwe tylko w bibliotece")
+	}
+	if loan.Renewals >= models.MaxRenewals {
+		return nil, fmt.Errorf("osiągnięto limit przedłużeń (%d)", models.MaxRenewals)
 	}
 
-	if loan.Status != models.LoanStatusActive {
-		return fmt.Errorf("wypożyczenie nie jest aktywne")
+	next, err := c.GetNextReservation(loan.BookID)
+	if err != nil {
+		return nil, err
+	}
+	if next != nil {
+		return nil, fmt.Errorf("na tę książkę czekają inni czytelnicy")
+	}
+
+	loan.DueDate = loan.DueDate.AddDate(0, 0, c.loanPolicy().LoanDays)
+	loan.Renewals++
+	loan.UpdatedAt = time.Now()
+
+	_, err = c.collection(LoansCollection).Doc(loan.ID).Update(c.ctx, []firestore.Update{
+		{Path: "due_date", Value: loan.DueDate},
+		{Path: "renewals", Value: loan.Renewals},
+		{Path: "updated_at", Value: loan.UpdatedAt},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("błąd przedłużania wypożyczenia: %w", err)
+	}
+	c.publish(events.CirculationChanged, loan.ID)
+
+	return loan, nil
+}
+
+// ReturnLoan obsługuje zwrot książki przy ladzie: nalicza karę za opóźnienie, zmniejsza licznik
+// wypożyczeń czytelnika i przekazuje książkę następnej osobie w kolejce rezerwacji
+func (c *Client) ReturnLoan(loanID string) (*models.ReturnResult, error) {
+	c, span := c.startSpan("ReturnLoan")
+	defer span.End()
+
+	result, err := c.returnLoan(loanID)
+	if err != nil {
+		return nil, err
+	}
+	c.publish(events.LoanReturned, result.Loan)
+	return result, nil
+}
+
+// returnLoan kończy wypożyczenie (wspólne dla zwrotów przy ladzie i na stanowiskach samoobsługowych)
+func (c *Client) returnLoan(loanID string) (*models.ReturnResult, error) {
+	loan, err := c.GetLoan(loanID)
+	if err != nil {
+		return nil, err
 	}
 
+	if !loan.IsBorrowed() {
+		return nil, fmt.Errorf("wypożyczenie nie jest aktywne")
+	}
+
+	// Oblicz karę jeśli jest opóźnienie (przed zmianą statusu - IsOverdue dotyczy trwających wypożyczeń).
+	// Część kary naliczona już przez nocne zadanie (FineAmount) jest w saldzie czytelnika.
+	// Umorzona kara już nie rośnie.
+	fine := loan.CalculateFine()
+	if loan.FineWaived {
+		fine = loan.FineAmount
+	}
+	fineIncrease := max(fine-loan.FineAmount, 0)
+
 	now := time.Now()
 	loan.ReturnDate = &now
 	loan.Status = models.LoanStatusReturned
+	loan.FineAmount = fine
 	loan.UpdatedAt = now
 
-	// Oblicz karę jeśli jest opóźnienie
-	if loan.IsOverdue() {
-		loan.FineAmount = loan.CalculateFine()
-	}
-
 	// Zaktualizuj status wypożyczenia
 	if err := c.UpdateLoan(loanID, loan); err != nil {
-		return fmt.Errorf("błąd aktualizacji wypożyczenia: %w", err)
+		return nil, fmt.Errorf("błąd aktualizacji wypożyczenia: %w", err)
 	}
 
-	// Zmniejsz licznik wypożyczeń użytkownika
+	// Zmniejsz licznik wypożyczeń użytkownika i dolicz karę
+	// (udostępnienia na miejscu nie wliczają się do limitu i nie mają kar)
 	user, err := c.GetUser(loan.UserID)
 	if err != nil {
-		return fmt.Errorf("błąd pobierania użytkownika: %w", err)
+		return nil, fmt.Errorf("błąd pobierania użytkownika: %w", err)
 	}
 
-	if user.CurrentLoans > 0 {
-		user.CurrentLoans--
+	if !loan.IsReadingRoom() && (user.CurrentLoans > 0 || fineIncrease > 0) {
+		if user.CurrentLoans > 0 {
+			user.CurrentLoans--
+		}
+		user.TotalFines += fineIncrease
 		user.UpdatedAt = now
 
 		if err := c.UpdateUser(loan.UserID, user); err != nil {
-			return fmt.Errorf("błąd aktualizacji licznika wypożyczeń użytkownika: %w", err)
+			return nil, fmt.Errorf("błąd aktualizacji licznika wypożyczeń użytkownika: %w", err)
 		}
 	}
 
+	result := &models.ReturnResult{Loan: loan, Fine: fine}
+
 	// Sprawdź czy są rezerwacje na tę książkę
 	nextReservation, err := c.GetNextReservation(loan.BookID)
 	if err != nil {
-		return fmt.Errorf("błąd sprawdzania rezerwacji: %w", err)
+		return nil, fmt.Errorf("błąd sprawdzania rezerwacji: %w", err)
 	}
 
 	if nextReservation != nil {
 		// Jest rezerwacja - oznacz jako gotową do odbioru (książka czeka na użytkownika)
 		log.Printf("Znaleziono rezerwację %s dla książki %s, zmieniam status na 'ready'", nextReservation.ID, loan.BookID)
-		if err := c.MarkReservationReady(nextReservation.ID); err != nil {
-			return fmt.Errorf("błąd aktywacji rezerwacji: %w", err)
+		if err := c.MarkReservationReady(nextReservation.ID, loan.BookID); err != nil {
+			return nil, fmt.Errorf("błąd aktywacji rezerwacji: %w", err)
 		}
 		log.Printf("Rezerwacja %s aktywowana pomyślnie", nextReservation.ID)
+		result.NextReservation = nextReservation
 		// NIE zwiększaj AvailableCopies - książka jest zarezerwowana
 	} else {
 		// Brak rezerwacji - zwróć książkę do katalogu
 		log.Printf("Brak rezerwacji dla książki %s, zwracam do katalogu", loan.BookID)
 		book, err := c.GetBook(loan.BookID)
 		if err != nil {
-			return fmt.Errorf("błąd pobierania książki: %w", err)
+			return nil, fmt.Errorf("błąd pobierania książki: %w", err)
 		}
 
 		book.AvailableCopies++
 		book.UpdatedAt = now
 
 		if err := c.UpdateBook(loan.BookID, book); err != nil {
-			return fmt.Errorf("błąd aktualizacji dostępności książki: %w", err)
+			return nil, fmt.Errorf("błąd aktualizacji dostępności książki: %w", err)
+		}
+
+		// Kolejka może nie być pusta - czekający czytelnicy mają pełny limit lub są na urlopie
+		if err := c.SuspendLimitedReservations(loan.BookID, time.Time{}); err != nil {
+			log.Printf("Błąd powiadamiania o pominiętych rezerwacjach książki %s: %v", loan.BookID, err)
 		}
 	}
 
-	return nil
+	// Czytelnik zwolnił miejsce w limicie - może dostać książkę, która go wcześniej ominęła
+	if !loan.IsReadingRoom() {
+		c.promotePostponedHolds(loan.UserID)
+	}
+
+	return result, nil
+}
+
+// GetBookActiveLoans pobiera aktywne (wydane czytelnikom) wypożyczenia książki
+func (c *Client) GetBookActiveLoans(bookID string) ([]*models.Loan, error) {
+	c, span := c.startSpan("GetBookActiveLoans")
+	defer span.End()
+
+	if bookID == "" {
+		return nil, fmt.Errorf("ID książki nie może być puste")
+	}
+
+	docs, err := c.collection(LoansCollection).
+		Where("book_id", "==", bookID).
+		Where("status", "in", borrowedStatuses()).
+		Documents(c.ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania wypożyczeń książki: %w", err)
+	}
+
+	loans := make([]*models.Loan, 0, len(docs))
+	for _, doc := range docs {
+		var loan models.Loan
+		if err := doc.DataTo(&loan); err != nil {
+			return nil, fmt.Errorf("błąd parsowania wypożyczenia: %w", err)
+		}
+		loans = append(loans, &loan)
+	}
+
+	return loans, nil
 }
 
 // ListLoans pobiera wszystkie wypożyczenia
 func (c *Client) ListLoans() ([]*models.Loan, error) {
+	c, span := c.startSpan("ListLoans")
+	defer span.End()
+
 	var loans []*models.Loan
 
-	iter := c.Firestore.Collection(LoansCollection).
+	iter := c.collection(LoansCollection).
 		OrderBy("loan_date", firestore.Desc).
 		Documents(c.ctx)
 	defer iter.Stop()
@@ -258,12 +437,121 @@ func (c *Client) ListLoans() ([]*models.Loan, error) {
 	return loans, nil
 }
 
-// GetActiveLoans pobiera aktywne wypożyczenia
+// GetPendingPickupLoans pobiera wypożyczenia oczekujące na odbiór, od najnowszego zamówienia
+func (c *Client) GetPendingPickupLoans() ([]*models.Loan, error) {
+	c, span := c.startSpan("GetPendingPickupLoans")
+	defer span.End()
+
+	var loans []*models.Loan
+
+	iter := c.collection(LoansCollection).
+		Where("status", "==", string(models.LoanStatusPendingPickup)).
+		Documents(c.ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("błąd iteracji po wypożyczeniach: %w", err)
+		}
+
+		var loan models.Loan
+		if err := doc.DataTo(&loan); err != nil {
+			return nil, fmt.Errorf("błąd parsowania wypożyczenia: %w", err)
+		}
+
+		loans = append(loans, &loan)
+	}
+
+	sort.Slice(loans, func(i, j int) bool {
+		return loans[i].LoanDate.After(loans[j].LoanDate)
+	})
+
+	return loans, nil
+}
+
+// GetBorrowedBookIDsSince zwraca ID książek wypożyczonych od podanej daty.
+// Pobierane jest tylko pole book_id, więc zapytanie jest tanie także dla długich okresów.
+func (c *Client) GetBorrowedBookIDsSince(since time.Time) (map[string]bool, error) {
+	c, span := c.startSpan("GetBorrowedBookIDsSince")
+	defer span.End()
+
+	docs, err := c.collection(LoansCollection).
+		Where("loan_date", ">=", since).
+		Select("book_id").
+		Documents(c.ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania wypożyczeń: %w", err)
+	}
+
+	borrowed := make(map[string]bool)
+	for _, doc := range docs {
+		if id, err := doc.DataAt("book_id"); err == nil {
+			if s, ok := id.(string); ok {
+				borrowed[s] = true
+			}
+		}
+	}
+	return borrowed, nil
+}
+
+// GetLoansBetween pobiera wypożyczenia rozpoczęte w przedziale [from, to)
+func (c *Client) GetLoansBetween(from, to time.Time) ([]*models.Loan, error) {
+	c, span := c.startSpan("GetLoansBetween")
+	defer span.End()
+
+	return c.loansInRange("loan_date", from, to)
+}
+
+// GetReturnsBetween pobiera wypożyczenia zwrócone w przedziale [from, to)
+func (c *Client) GetReturnsBetween(from, to time.Time) ([]*models.Loan, error) {
+	c, span := c.startSpan("GetReturnsBetween")
+	defer span.End()
+
+	return c.loansInRange("return_date", from, to)
+}
+
+func (c *Client) loansInRange(field string, from, to time.Time) ([]*models.Loan, error) {
+	docs, err := c.collection(LoansCollection).
+		Where(field, ">=", from).
+		Where(field, "<", to).
+		Documents(c.ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania wypożyczeń: %w", err)
+	}
+
+	loans := make([]*models.Loan, 0, len(docs))
+	for _, doc := range docs {
+		var loan models.Loan
+		if err := doc.DataTo(&loan); err != nil {
+			return nil, fmt.Errorf("błąd parsowania wypożyczenia: %w", err)
+		}
+		loans = append(loans, &loan)
+	}
+	return loans, nil
+}
+
+// borrowedStatuses zwraca models.BorrowedStatuses do zapytań "in"
+func borrowedStatuses() []string {
+	statuses := make([]string, len(models.BorrowedStatuses))
+	for i, status := range models.BorrowedStatuses {
+		statuses[i] = string(status)
+	}
+	return statuses
+}
+
+// GetActiveLoans pobiera aktywne wypożyczenia (także po terminie zwrotu)
 func (c *Client) GetActiveLoans() ([]*models.Loan, error) {
+	c, span := c.startSpan("GetActiveLoans")
+	defer span.End()
+
 	var loans []*models.Loan
 
-	iter := c.Firestore.Collection(LoansCollection).
-		Where("status", "==", string(models.LoanStatusActive)).
+	iter := c.collection(LoansCollection).
+		Where("status", "in", borrowedStatuses()).
 		Documents(c.ctx)
 	defer iter.Stop()
 
@@ -289,13 +577,16 @@ func (c *Client) GetActiveLoans() ([]*models.Loan, error) {
 
 // GetUserLoans pobiera wypożyczenia użytkownika
 func (c *Client) GetUserLoans(userID string) ([]*models.Loan, error) {
+	c, span := c.startSpan("GetUserLoans")
+	defer span.End()
+
 	if userID == "" {
 		return nil, fmt.Errorf("ID użytkownika nie może być puste")
 	}
 
 	var loans []*models.Loan
 
-	iter := c.Firestore.Collection(LoansCollection).
+	iter := c.collection(LoansCollection).
 		Where("user_id", "==", userID).
 		OrderBy("loan_date", firestore.Desc).
 		Documents(c.ctx)
@@ -323,13 +614,16 @@ func (c *Client) GetUserLoans(userID string) ([]*models.Loan, error) {
 
 // GetBookLoans pobiera wypożyczenia książki
 func (c *Client) GetBookLoans(bookID string) ([]*models.Loan, error) {
+	c, span := c.startSpan("GetBookLoans")
+	defer span.End()
+
 	if bookID == "" {
 		return nil, fmt.Errorf("ID książki nie może być puste")
 	}
 
 	var loans []*models.Loan
 
-	iter := c.Firestore.Collection(LoansCollection).
+	iter := c.collection(LoansCollection).
 		Where("book_id", "==", bookID).
 		OrderBy("loan_date", firestore.Desc).
 		Documents(c.ctx)
@@ -357,6 +651,9 @@ func (c *Client) GetBookLoans(bookID string) ([]*models.Loan, error) {
 
 // GetOverdueLoans pobiera przeterminowane wypożyczenia
 func (c *Client) GetOverdueLoans() ([]*models.Loan, error) {
+	c, span := c.startSpan("GetOverdueLoans")
+	defer span.End()
+
 	// Pobierz wszystkie aktywne wypożyczenia i filtruj po stronie aplikacji
 	activeLoans, err := c.GetActiveLoans()
 	if err != nil {
@@ -374,10 +671,116 @@ func (c *Client) GetOverdueLoans() ([]*models.Loan, error) {
 	return overdueLoans, nil
 }
 
+// AccrueLoanFine oznacza wypożyczenie po terminie statusem overdue i podnosi jego karę
+// do fine, doliczając przyrost do salda czytelnika w tej samej transakcji - kara trafia
+// na konto dokładnie raz, także gdy zadanie nocne uruchomi się dwa razy. Zwraca przyrost
+// kary (0, gdy była już naliczona, została umorzona albo książka została w międzyczasie zwrócona).
+func (c *Client) AccrueLoanFine(loanID string, fine models.Money) (models.Money, error) {
+	c, span := c.startSpan("AccrueLoanFine")
+	defer span.End()
+
+	if loanID == "" {
+		return 0, fmt.Errorf("ID wypożyczenia nie może być puste")
+	}
+
+	loanRef := c.collection(LoansCollection).Doc(loanID)
+
+	var increase models.Money
+	err := c.Firestore.RunTransaction(c.ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		increase = 0
+
+		doc, err := tx.Get(loanRef)
+		if err != nil {
+			return fmt.Errorf("błąd pobierania wypożyczenia: %w", err)
+		}
+
+		var loan models.Loan
+		if err := doc.DataTo(&loan); err != nil {
+			return fmt.Errorf("błąd parsowania wypożyczenia: %w", err)
+		}
+		if !loan.IsBorrowed() || loan.FineWaived {
+			return nil
+		}
+
+		now := time.Now()
+		updates := []firestore.Update{
+			{Path: "status", Value: string(models.LoanStatusOverdue)},
+			{Path: "updated_at", Value: now},
+		}
+		if fine > loan.FineAmount {
+			increase = fine - loan.FineAmount
+			updates = append(updates, firestore.Update{Path: "fine_amount_gr", Value: fine})
+		} else if loan.Status == models.LoanStatusOverdue {
+			return nil
+		}
+
+		if err := tx.Update(loanRef, updates); err != nil {
+			return err
+		}
+		if increase == 0 {
+			return nil
+		}
+		return tx.Update(c.collection(UsersCollection).Doc(loan.UserID), []firestore.Update{
+			{Path: "total_fines_gr", Value: firestore.Increment(int64(increase))},
+			{Path: "updated_at", Value: now},
+		})
+	})
+	if err != nil {
+		return 0, fmt.Errorf("błąd naliczania kary: %w", err)
+	}
+
+	return increase, nil
+}
+
+// PublishLoansDueSoon publikuje przypomnienie (events.LoanDueSoon) o każdym wypożyczeniu
+// z terminem zwrotu w dniu day. Zadanie uruchamiane raz dziennie przypomina więc
+// o każdym wypożyczeniu dokładnie raz. Zwraca liczbę przypomnień.
+func (c *Client) PublishLoansDueSoon(day time.Time) (int, error) {
+	c, span := c.startSpan("PublishLoansDueSoon")
+	defer span.End()
+
+	from := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	loans, err := c.loansInRange("due_date", from, from.AddDate(0, 0, 1))
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, loan := range loans {
+		if !loan.IsBorrowed() || loan.IsReadingRoom() {
+			continue
+		}
+		c.publish(events.LoanDueSoon, loan)
+		published++
+	}
+	return published, nil
+}
+
+// RemindLoan publikuje przypomnienie o terminie zwrotu (events.LoanDueSoon) jednego
+// wypożyczenia na prośbę personelu, np. przed terminem nocnego przypomnienia
+func (c *Client) RemindLoan(loanID string) (*models.Loan, error) {
+	c, span := c.startSpan("RemindLoan")
+	defer span.End()
+
+	loan, err := c.GetLoan(loanID)
+	if err != nil {
+		return nil, err
+	}
+	if !loan.IsBorrowed() || loan.IsReadingRoom() {
+		return nil, fmt.Errorf("wypożyczenie nie jest aktywne")
+	}
+
+	c.publish(events.LoanDueSoon, loan)
+	return loan, nil
+}
+
 // CountActiveLoans zwraca liczbę aktywnych wypożyczeń
 func (c *Client) CountActiveLoans() (int, error) {
-	docs, err := c.Firestore.Collection(LoansCollection).
-		Where("status", "==", string(models.LoanStatusActive)).
+	c, span := c.startSpan("CountActiveLoans")
+	defer span.End()
+
+	docs, err := c.collection(LoansCollection).
+		Where("status", "in", borrowedStatuses()).
 		Documents(c.ctx).GetAll()
 	if err != nil {
 		return 0, fmt.Errorf("błąd liczenia aktywnych wypożyczeń: %w", err)
@@ -387,6 +790,9 @@ func (c *Client) CountActiveLoans() (int, error) {
 
 // CountOverdueLoans zwraca liczbę przeterminowanych wypożyczeń
 func (c *Client) CountOverdueLoans() (int, error) {
+	c, span := c.startSpan("CountOverdueLoans")
+	defer span.End()
+
 	// Pobierz wszystkie aktywne wypożyczenia i filtruj po stronie aplikacji
 	activeLoans, err := c.GetActiveLoans()
 	if err != nil {
@@ -406,6 +812,9 @@ func (c *Client) CountOverdueLoans() (int, error) {
 
 // GetUserActiveLoans pobiera aktywne wypożyczenia konkretnego użytkownika (active i pending_pickup)
 func (c *Client) GetUserActiveLoans(userID string) ([]*models.Loan, error) {
+	c, span := c.startSpan("GetUserActiveLoans")
+	defer span.End()
+
 	if userID == "" {
 		return nil, fmt.Errorf("ID użytkownika nie może być puste")
 	}
@@ -413,7 +822,7 @@ func (c *Client) GetUserActiveLoans(userID string) ([]*models.Loan, error) {
 	var loans []*models.Loan
 
 	// Pobierz wszystkie wypożyczenia użytkownika
-	iter := c.Firestore.Collection(LoansCollection).
+	iter := c.collection(LoansCollection).
 		Where("user_id", "==", userID).
 		Documents(c.ctx)
 	defer iter.Stop()
@@ -432,8 +841,8 @@ func (c *Client) GetUserActiveLoans(userID string) ([]*models.Loan, error) {
 			return nil, fmt.Errorf("błąd parsowania wypożyczenia: %w", err)
 		}
 
-		// Dodaj tylko wypożyczenia aktywne lub oczekujące na odbiór
-		if loan.Status == models.LoanStatusActive || loan.Status == models.LoanStatusPendingPickup {
+		// Dodaj tylko wypożyczenia trwające (także po terminie) lub oczekujące na odbiór
+		if loan.IsBorrowed() || loan.Status == models.LoanStatusPendingPickup {
 			loans = append(loans, &loan)
 		}
 	}
@@ -444,13 +853,16 @@ func (c *Client) GetUserActiveLoans(userID string) ([]*models.Loan, error) {
 // GetUserLoanHistory pobiera historię wypożyczeń użytkownika (zwrócone książki)
 // GetUserLoanHistory pobiera historię wypożyczeń użytkownika (zwrócone książki)
 func (c *Client) GetUserLoanHistory(userID string) ([]*models.Loan, error) {
+	c, span := c.startSpan("GetUserLoanHistory")
+	defer span.End()
+
 	if userID == "" {
 		return nil, fmt.Errorf("ID użytkownika nie może być puste")
 	}
 
 	var loans []*models.Loan
 
-	iter := c.Firestore.Collection(LoansCollection).
+	iter := c.collection(LoansCollection).
 		Where("user_id", "==", userID).
 		Where("status", "==", string(models.LoanStatusReturned)).
 		Documents(c.ctx)
@@ -0,0 +1,79 @@
+package firebase
+
+import (
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+
+	"library-management-system/internal/models"
+)
+
+// SetReservationLocker zapisuje skrytkę przydzieloną gotowej rezerwacji
+func (c *Client) SetReservationLocker(reservationID, lockerID, code string) error {
+	c, span := c.startSpan("SetReservationLocker")
+	defer span.End()
+
+	_, err := c.collection(ReservationsCollection).Doc(reservationID).Update(c.ctx, []firestore.Update{
+		{Path: "locker_id", Value: lockerID},
+		{Path: "locker_code", Value: code},
+		{Path: "updated_at", Value: time.Now()},
+	})
+	if err != nil {
+		return fmt.Errorf("błąd zapisywania skrytki rezerwacji: %w", err)
+	}
+	return nil
+}
+
+// CompleteLockerPickup zamienia rezerwację odebraną ze skrytki w aktywne wypożyczenie.
+// Ponowne zgłoszenie tego samego odbioru (np. powtórzony webhook) niczego nie zmienia.
+func (c *Client) CompleteLockerPickup(reservationID string) error {
+	c, span := c.startSpan("CompleteLockerPickup")
+	defer span.End()
+
+	reservation, err := c.GetReservation(reservationID)
+	if err != nil {
+		return err
+	}
+	if reservation.Status == models.ReservationStatusCompleted {
+		return nil
+	}
+	if reservation.Status != models.ReservationStatusReady || reservation.LockerID == "" {
+		return fmt.Errorf("rezerwacja %s nie czeka w skrytce", reservationID)
+	}
+
+	user, err := c.GetUser(reservation.UserID)
+	if err != nil {
+		return err
+	}
+
+	loan := &models.Loan{
+		BookID:         reservation.BookID,
+		UserID:         reservation.UserID,
+		BookTitle:      reservation.BookTitle, // Denormalizacja
+		UserName:       user.FullName(),       // Denormalizacja
+		PickupLocation: reservation.PickupLocation,
+		Notes:          "Odebrano ze skrytki " + reservation.LockerID,
+	}
+	if err := c.CreateLoan(loan); err != nil {
+		return err
+	}
+	// Skrytka potwierdziła odbiór - wypożyczenie jest od razu aktywne
+	// (bez potwierdzenia na drukarce przy ladzie)
+	if _, err := c.activateLoan(loan.PickupCode); err != nil {
+		return err
+	}
+	if err := c.UpdateUserLoansCount(reservation.UserID, true); err != nil {
+		return err
+	}
+
+	// Czytelnik mógł odebrać książkę tuż po terminie, więc nie używamy CompleteReservation
+	now := time.Now()
+	reservation.Status = models.ReservationStatusCompleted
+	reservation.CompletedAt = &now
+	if err := c.UpdateReservation(reservationID, reservation); err != nil {
+		return err
+	}
+	c.promotePostponedHolds(reservation.UserID)
+	return nil
+}
@@ -0,0 +1,108 @@
+package firebase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"library-management-system/internal/models"
+)
+
+// ModerationLogCollection to nazwa kolekcji dziennika decyzji moderatorów w Firestore
+const ModerationLogCollection = "moderation_log"
+
+// UnbanCommenter zdejmuje czytelnikowi blokadę komentowania i zapisuje decyzję w dzienniku
+func (c *Client) UnbanCommenter(userID string, moderator *models.User) error {
+	c, span := c.startSpan("UnbanCommenter")
+	defer span.End()
+
+	user, err := c.GetUser(userID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	decisionRef := c.collection(ModerationLogCollection).NewDoc()
+	decision := &models.ModerationDecision{
+		ID:             decisionRef.ID,
+		Action:         models.ModerationUnban,
+		AuthorID:       user.ID,
+		AuthorName:     user.FullName(),
+		ModeratorEmail: moderator.Email,
+		CreatedAt:      now,
+	}
+
+	err = c.Firestore.RunTransaction(c.ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if err := tx.Update(c.collection(UsersCollection).Doc(user.ID), []firestore.Update{
+			{Path: "comment_banned", Value: false},
+			{Path: "updated_at", Value: now},
+		}); err != nil {
+			return err
+		}
+		return tx.Set(decisionRef, decision)
+	})
+	if err != nil {
+		return fmt.Errorf("błąd zdejmowania blokady komentowania: %w", err)
+	}
+
+	return nil
+}
+
+// GetCommentBannedUsers pobiera czytelników z blokadą komentowania
+func (c *Client) GetCommentBannedUsers() ([]*models.User, error) {
+	c, span := c.startSpan("GetCommentBannedUsers")
+	defer span.End()
+
+	docs, err := c.collection(UsersCollection).Where("comment_banned", "==", true).Documents(c.ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania zablokowanych czytelników: %w", err)
+	}
+
+	users := make([]*models.User, 0, len(docs))
+	for _, doc := range docs {
+		var user models.User
+		if err := doc.DataTo(&user); err != nil {
+			return nil, fmt.Errorf("błąd parsowania użytkownika: %w", err)
+		}
+		user.ID = doc.Ref.ID
+		users = append(users, &user)
+	}
+
+	return users, nil
+}
+
+// ListModerationLog pobiera ostatnie wpisy dziennika moderacji (najnowsze pierwsze)
+func (c *Client) ListModerationLog(limit int) ([]*models.ModerationDecision, error) {
+	c, span := c.startSpan("ListModerationLog")
+	defer span.End()
+
+	var decisions []*models.ModerationDecision
+
+	iter := c.collection(ModerationLogCollection).
+		OrderBy("created_at", firestore.Desc).
+		Limit(limit).
+		Documents(c.ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("błąd iteracji po dzienniku moderacji: %w", err)
+		}
+
+		var decision models.ModerationDecision
+		if err := doc.DataTo(&decision); err != nil {
+			return nil, fmt.Errorf("błąd parsowania wpisu dziennika moderacji: %w", err)
+		}
+
+		decisions = append(decisions, &decision)
+	}
+
+	return decisions, nil
+}
@@ -0,0 +1,175 @@
+package firebase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"library-management-system/internal/models"
+)
+
+// NewsletterIssuesCollection to nazwa kolekcji wysłanych wydań newslettera
+const NewsletterIssuesCollection = "newsletter_issues"
+
+// StartNewsletterIssue zapisuje początek wysyłki wydania. Zwraca false, jeśli wydanie
+// za ten miesiąc już wysłano (lub wysyła je inna instancja serwera).
+func (c *Client) StartNewsletterIssue(issue *models.NewsletterIssue) (bool, error) {
+	c, span := c.startSpan("StartNewsletterIssue")
+	defer span.End()
+
+	if issue == nil || issue.ID == "" {
+		return false, fmt.Errorf("wydanie newslettera musi mieć ID")
+	}
+
+	issue.StartedAt = time.Now()
+	_, err := c.collection(NewsletterIssuesCollection).Doc(issue.ID).Create(c.ctx, issue)
+	if status.Code(err) == codes.AlreadyExists {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("błąd zapisywania wydania newslettera: %w", err)
+	}
+
+	return true, nil
+}
+
+// FinishNewsletterIssue zapisuje liczbę odbiorców wysłanego wydania
+func (c *Client) FinishNewsletterIssue(id string, recipients int) error {
+	c, span := c.startSpan("FinishNewsletterIssue")
+	defer span.End()
+
+	_, err := c.collection(NewsletterIssuesCollection).Doc(id).Update(c.ctx, []firestore.Update{
+		{Path: "recipients", Value: recipients},
+		{Path: "finished_at", Value: time.Now()},
+	})
+	if err != nil {
+		return fmt.Errorf("błąd zapisywania wydania newslettera: %w", err)
+	}
+	return nil
+}
+
+// ListNewsletterIssues pobiera ostatnie wydania newslettera (najnowsze pierwsze)
+func (c *Client) ListNewsletterIssues(limit int) ([]*models.NewsletterIssue, error) {
+	c, span := c.startSpan("ListNewsletterIssues")
+	defer span.End()
+
+	iter := c.collection(NewsletterIssuesCollection).OrderBy("month", firestore.Desc).Limit(limit).Documents(c.ctx)
+	defer iter.Stop()
+
+	var issues []*models.NewsletterIssue
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("błąd pobierania wydań newslettera: %w", err)
+		}
+
+		var issue models.NewsletterIssue
+		if err := doc.DataTo(&issue); err != nil {
+			return nil, fmt.Errorf("błąd parsowania wydania newslettera: %w", err)
+		}
+		issue.ID = doc.Ref.ID
+		issues = append(issues, &issue)
+	}
+
+	return issues, nil
+}
+
+// GetNewsletterSubscribers pobiera czytelników, którzy zamówili newsletter emailem
+func (c *Client) GetNewsletterSubscribers() ([]*models.User, error) {
+	c, span := c.startSpan("GetNewsletterSubscribers")
+	defer span.End()
+
+	iter := c.collection(UsersCollection).
+		Where("notification_prefs."+string(models.NotifyNewsletter), "array-contains", string(models.DeliveryEmail)).
+		Documents(c.ctx)
+	defer iter.Stop()
+
+	var users []*models.User
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("błąd pobierania odbiorców newslettera: %w", err)
+		}
+
+		var user models.User
+		if err := doc.DataTo(&user); err != nil {
+			return nil, fmt.Errorf("błąd parsowania danych użytkownika: %w", err)
+		}
+		user.ID = doc.Ref.ID
+		user.Tenant = c.tenant
+		users = append(users, &user)
+	}
+
+	return users, nil
+}
+
+// GetUserByNewsletterToken pobiera czytelnika po tokenie z linku rezygnacji z newslettera.
+// Zwraca nil, jeśli token nie należy do nikogo.
+func (c *Client) GetUserByNewsletterToken(token string) (*models.User, error) {
+	c, span := c.startSpan("GetUserByNewsletterToken")
+	defer span.End()
+
+	if token == "" {
+		return nil, nil
+	}
+
+	iter := c.collection(UsersCollection).Where("newsletter_token", "==", token).Limit(1).Documents(c.ctx)
+	defer iter.Stop()
+
+	doc, err := iter.Next()
+	if err == iterator.Done {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("błąd wyszukiwania czytelnika po tokenie newslettera: %w", err)
+	}
+
+	var user models.User
+	if err := doc.DataTo(&user); err != nil {
+		return nil, fmt.Errorf("błąd parsowania danych użytkownika: %w", err)
+	}
+	user.ID = doc.Ref.ID
+	user.Tenant = c.tenant
+
+	return &user, nil
+}
+
+// EnsureNewsletterToken zwraca token linku rezygnacji z newslettera, nadając go
+// czytelnikowi przy pierwszej wysyłce. Token się nie zmienia, więc linki ze starszych
+// wydań nadal działają.
+func (c *Client) EnsureNewsletterToken(user *models.User) (string, error) {
+	c, span := c.startSpan("EnsureNewsletterToken")
+	defer span.End()
+
+	if user.NewsletterToken != "" {
+		return user.NewsletterToken, nil
+	}
+
+	raw := make([]byte, 20)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("błąd generowania tokenu: %w", err)
+	}
+	token := hex.EncodeToString(raw)
+
+	_, err := c.collection(UsersCollection).Doc(user.ID).Update(c.ctx, []firestore.Update{
+		{Path: "newsletter_token", Value: token},
+	})
+	if err != nil {
+		return "", fmt.Errorf("błąd zapisywania tokenu newslettera: %w", err)
+	}
+
+	user.NewsletterToken = token
+	return token, nil
+}
@@ -0,0 +1,114 @@
+package firebase
+
+import (
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"library-management-system/internal/models"
+)
+
+// NotificationDeliveriesCollection to nazwa kolekcji dziennika wysyłek powiadomień
+const NotificationDeliveriesCollection = "notification_deliveries"
+
+// CreateNotificationDelivery zapisuje wpis w dzienniku wysyłek
+func (c *Client) CreateNotificationDelivery(delivery *models.NotificationDelivery) error {
+	c, span := c.startSpan("CreateNotificationDelivery")
+	defer span.End()
+
+	if delivery == nil {
+		return fmt.Errorf("wpis dziennika wysyłek nie może być nil")
+	}
+
+	delivery.CreatedAt = time.Now()
+
+	docRef := c.collection(NotificationDeliveriesCollection).NewDoc()
+	delivery.ID = docRef.ID
+
+	if _, err := docRef.Set(c.ctx, delivery); err != nil {
+		return fmt.Errorf("błąd zapisywania wpisu dziennika wysyłek: %w", err)
+	}
+
+	return nil
+}
+
+// GetNotificationDelivery pobiera wpis dziennika wysyłek po ID
+func (c *Client) GetNotificationDelivery(id string) (*models.NotificationDelivery, error) {
+	c, span := c.startSpan("GetNotificationDelivery")
+	defer span.End()
+
+	if id == "" {
+		return nil, fmt.Errorf("ID wpisu nie może być puste")
+	}
+
+	doc, err := c.collection(NotificationDeliveriesCollection).Doc(id).Get(c.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania wpisu dziennika wysyłek: %w", err)
+	}
+
+	var delivery models.NotificationDelivery
+	if err := doc.DataTo(&delivery); err != nil {
+		return nil, fmt.Errorf("błąd parsowania wpisu dziennika wysyłek: %w", err)
+	}
+	delivery.ID = doc.Ref.ID
+
+	return &delivery, nil
+}
+
+// MarkNotificationDeliveryResent oznacza wpis jako ponowiony, żeby nie wysłać go drugi raz
+func (c *Client) MarkNotificationDeliveryResent(id string) error {
+	c, span := c.startSpan("MarkNotificationDeliveryResent")
+	defer span.End()
+
+	_, err := c.collection(NotificationDeliveriesCollection).Doc(id).Update(c.ctx, []firestore.Update{
+		{Path: "resent", Value: true},
+	})
+	if err != nil {
+		return fmt.Errorf("błąd oznaczania ponowionej wysyłki: %w", err)
+	}
+	return nil
+}
+
+// ListNotificationDeliveries pobiera ostatnie wpisy dziennika wysyłek (najnowsze pierwsze).
+// Zapytania z filtrami korzystają z indeksów złożonych (channel / status / user_id
+// z created_at DESC) - Firestore łączy je przy kilku filtrach naraz, patrz firestore.indexes.json.
+func (c *Client) ListNotificationDeliveries(filter models.DeliveryFilter, limit int) ([]*models.NotificationDelivery, error) {
+	c, span := c.startSpan("ListNotificationDeliveries")
+	defer span.End()
+
+	query := c.collection(NotificationDeliveriesCollection).Query
+	if filter.Channel != "" {
+		query = query.Where("channel", "==", string(filter.Channel))
+	}
+	if filter.Status != "" {
+		query = query.Where("status", "==", string(filter.Status))
+	}
+	if filter.UserID != "" {
+		query = query.Where("user_id", "==", filter.UserID)
+	}
+
+	iter := query.OrderBy("created_at", firestore.Desc).Limit(limit).Documents(c.ctx)
+	defer iter.Stop()
+
+	var deliveries []*models.NotificationDelivery
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("błąd pobierania dziennika wysyłek: %w", err)
+		}
+
+		var delivery models.NotificationDelivery
+		if err := doc.DataTo(&delivery); err != nil {
+			return nil, fmt.Errorf("błąd parsowania wpisu dziennika wysyłek: %w", err)
+		}
+		delivery.ID = doc.Ref.ID
+		deliveries = append(deliveries, &delivery)
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,120 @@
+package firebase
+
+import (
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"library-management-system/internal/models"
+)
+
+const (
+	// NotificationsCollection to nazwa kolekcji powiadomień w Firestore
+	NotificationsCollection = "notifications"
+)
+
+// CreateNotification zapisuje nowe powiadomienie dla użytkownika
+func (c *Client) CreateNotification(notification *models.Notification) error {
+	c, span := c.startSpan("CreateNotification")
+	defer span.End()
+
+	if notification == nil {
+		return fmt.Errorf("powiadomienie nie może być nil")
+	}
+	if notification.UserID == "" {
+		return fmt.Errorf("ID użytkownika jest wymagane")
+	}
+
+	notification.CreatedAt = time.Now()
+	notification.Read = false
+
+	docRef := c.collection(NotificationsCollection).NewDoc()
+	notification.ID = docRef.ID
+
+	if _, err := docRef.Set(c.ctx, notification); err != nil {
+		return fmt.Errorf("błąd zapisywania powiadomienia: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserNotifications pobiera powiadomienia użytkownika (najnowsze pierwsze).
+// Zapytanie wymaga indeksu złożonego (user_id ASC, created_at DESC) - patrz firestore.indexes.json
+func (c *Client) GetUserNotifications(userID string) ([]*models.Notification, error) {
+	c, span := c.startSpan("GetUserNotifications")
+	defer span.End()
+
+	if userID == "" {
+		return nil, fmt.Errorf("ID użytkownika nie może być puste")
+	}
+
+	var notifications []*models.Notification
+
+	iter := c.collection(NotificationsCollection).
+		Where("user_id", "==", userID).
+		OrderBy("created_at", firestore.Desc).
+		Limit(100).
+		Documents(c.ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("błąd iteracji po powiadomieniach: %w", err)
+		}
+
+		var notification models.Notification
+		if err := doc.DataTo(&notification); err != nil {
+			return nil, fmt.Errorf("błąd parsowania powiadomienia: %w", err)
+		}
+
+		notification.ID = doc.Ref.ID
+		notifications = append(notifications, &notification)
+	}
+
+	return notifications, nil
+}
+
+// CountUnreadNotifications zwraca liczbę nieprzeczytanych powiadomień użytkownika
+func (c *Client) CountUnreadNotifications(userID string) (int, error) {
+	c, span := c.startSpan("CountUnreadNotifications")
+	defer span.End()
+
+	docs, err := c.collection(NotificationsCollection).
+		Where("user_id", "==", userID).
+		Where("read", "==", false).
+		Documents(c.ctx).GetAll()
+	if err != nil {
+		return 0, fmt.Errorf("błąd liczenia powiadomień: %w", err)
+	}
+	return len(docs), nil
+}
+
+// MarkNotificationsRead oznacza wszystkie powiadomienia użytkownika jako przeczytane
+func (c *Client) MarkNotificationsRead(userID string) error {
+	c, span := c.startSpan("MarkNotificationsRead")
+	defer span.End()
+
+	docs, err := c.collection(NotificationsCollection).
+		Where("user_id", "==", userID).
+		Where("read", "==", false).
+		Documents(c.ctx).GetAll()
+	if err != nil {
+		return fmt.Errorf("błąd pobierania powiadomień: %w", err)
+	}
+
+	for _, doc := range docs {
+		if _, err := doc.Ref.Update(c.ctx, []firestore.Update{
+			{Path: "read", Value: true},
+		}); err != nil {
+			return fmt.Errorf("błąd aktualizacji powiadomienia: %w", err)
+		}
+	}
+
+	return nil
+}
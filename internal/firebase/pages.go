@@ -0,0 +1,95 @@
+package firebase
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+
+	"library-management-system/internal/models"
+)
+
+// pageCursor to pozycja ostatniego dokumentu strony: wartość pola sortowania i ID dokumentu.
+// ID rozstrzyga kolejność dokumentów o tej samej wartości (np. kilku wydań jednego tytułu).
+type pageCursor struct {
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// encode zwraca kursor w postaci bezpiecznej dla parametru URL
+func (p pageCursor) encode() string {
+	data, _ := json.Marshal(p)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodePageCursor odczytuje kursor z parametru URL
+func decodePageCursor(s string) (*pageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("nieprawidłowy kursor strony")
+	}
+	var cursor pageCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" {
+		return nil, fmt.Errorf("nieprawidłowy kursor strony")
+	}
+	return &cursor, nil
+}
+
+// queryPage pobiera jedną stronę zapytania posortowanego rosnąco po polu tekstowym field.
+// Zwraca dokumenty strony i kursor następnej strony (pusty na ostatniej stronie).
+// Zamiast Offset (który Firestore nalicza jako odczyt każdego pominiętego dokumentu)
+// strona zaczyna się za dokumentem z kursora after.
+func (c *Client) queryPage(query firestore.Query, field string, limit int, after string) ([]*firestore.DocumentSnapshot, string, error) {
+	query = query.OrderBy(field, firestore.Asc).OrderBy(firestore.DocumentID, firestore.Asc)
+	if after != "" {
+		cursor, err := decodePageCursor(after)
+		if err != nil {
+			return nil, "", err
+		}
+		query = query.StartAfter(cursor.Value, cursor.ID)
+	}
+
+	// Jeden dokument więcej pokazuje, czy istnieje następna strona
+	docs, err := query.Limit(limit + 1).Documents(c.ctx).GetAll()
+	if err != nil {
+		return nil, "", err
+	}
+	if len(docs) <= limit {
+		return docs, "", nil
+	}
+
+	docs = docs[:limit]
+	last := docs[limit-1]
+	value, _ := last.DataAt(field)
+	s, _ := value.(string)
+	return docs, pageCursor{Value: s, ID: last.Ref.ID}.encode(), nil
+}
+
+// ListBooksPage pobiera stronę katalogu w kolejności tytułów, opcjonalnie z jednej kategorii.
+// after to kursor zwrócony przez poprzednią stronę (pusty dla pierwszej strony).
+func (c *Client) ListBooksPage(category string, limit int, after string) ([]*models.Book, string, error) {
+	c, span := c.startSpan("ListBooksPage")
+	defer span.End()
+
+	query := c.collection(BooksCollection).Query
+	if category != "" {
+		query = query.Where("category", "==", category)
+	}
+
+	docs, next, err := c.queryPage(query, "title", limit, after)
+	if err != nil {
+		return nil, "", fmt.Errorf("błąd pobierania strony katalogu: %w", err)
+	}
+
+	books := make([]*models.Book, 0, len(docs))
+	for _, doc := range docs {
+		var book models.Book
+		if err := doc.DataTo(&book); err != nil {
+			return nil, "", fmt.Errorf("błąd parsowania książki: %w", err)
+		}
+		book.ID = doc.Ref.ID
+		books = append(books, &book)
+	}
+	return books, next, nil
+}
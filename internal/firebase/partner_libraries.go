@@ -0,0 +1,151 @@
+package firebase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/api/iterator"
+
+	"library-management-system/internal/models"
+)
+
+const (
+	// PartnerLibrariesCollection to nazwa kolekcji bibliotek partnerskich w Firestore
+	PartnerLibrariesCollection = "partner_libraries"
+)
+
+// CreatePartnerLibrary dodaje bibliotekę partnerską do katalogu
+func (c *Client) CreatePartnerLibrary(partner *models.PartnerLibrary) error {
+	c, span := c.startSpan("CreatePartnerLibrary")
+	defer span.End()
+
+	if err := validatePartnerLibrary(partner); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	partner.CreatedAt = now
+	partner.UpdatedAt = now
+
+	docRef := c.collection(PartnerLibrariesCollection).NewDoc()
+	partner.ID = docRef.ID
+
+	if _, err := docRef.Set(c.ctx, partner); err != nil {
+		return fmt.Errorf("błąd zapisywania biblioteki partnerskiej: %w", err)
+	}
+
+	return nil
+}
+
+// GetPartnerLibrary pobiera bibliotekę partnerską po ID
+func (c *Client) GetPartnerLibrary(id string) (*models.PartnerLibrary, error) {
+	c, span := c.startSpan("GetPartnerLibrary")
+	defer span.End()
+
+	if id == "" {
+		return nil, fmt.Errorf("ID biblioteki partnerskiej nie może być puste")
+	}
+
+	doc, err := c.collection(PartnerLibrariesCollection).Doc(id).Get(c.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania biblioteki partnerskiej: %w", err)
+	}
+
+	var partner models.PartnerLibrary
+	if err := doc.DataTo(&partner); err != nil {
+		return nil, fmt.Errorf("błąd parsowania biblioteki partnerskiej: %w", err)
+	}
+	partner.ID = doc.Ref.ID
+
+	return &partner, nil
+}
+
+// UpdatePartnerLibrary zapisuje dane kontaktowe i warunki wypożyczania partnera
+func (c *Client) UpdatePartnerLibrary(partner *models.PartnerLibrary) error {
+	c, span := c.startSpan("UpdatePartnerLibrary")
+	defer span.End()
+
+	if partner == nil || partner.ID == "" {
+		return fmt.Errorf("ID biblioteki partnerskiej nie może być puste")
+	}
+	if err := validatePartnerLibrary(partner); err != nil {
+		return err
+	}
+
+	partner.UpdatedAt = time.Now()
+	if _, err := c.collection(PartnerLibrariesCollection).Doc(partner.ID).Set(c.ctx, partner); err != nil {
+		return fmt.Errorf("błąd aktualizacji biblioteki partnerskiej: %w", err)
+	}
+
+	return nil
+}
+
+// DeletePartnerLibrary usuwa bibliotekę partnerską z katalogu. Zamówienia zachowują
+// zapisaną nazwę partnera i korespondencję.
+func (c *Client) DeletePartnerLibrary(id string) error {
+	c, span := c.startSpan("DeletePartnerLibrary")
+	defer span.End()
+
+	if id == "" {
+		return fmt.Errorf("ID biblioteki partnerskiej nie może być puste")
+	}
+
+	if _, err := c.collection(PartnerLibrariesCollection).Doc(id).Delete(c.ctx); err != nil {
+		return fmt.Errorf("błąd usuwania biblioteki partnerskiej: %w", err)
+	}
+
+	return nil
+}
+
+// ListPartnerLibraries pobiera katalog bibliotek partnerskich (alfabetycznie)
+func (c *Client) ListPartnerLibraries() ([]*models.PartnerLibrary, error) {
+	c, span := c.startSpan("ListPartnerLibraries")
+	defer span.End()
+
+	var partners []*models.PartnerLibrary
+
+	iter := c.collection(PartnerLibrariesCollection).Documents(c.ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("błąd iteracji po bibliotekach partnerskich: %w", err)
+		}
+
+		var partner models.PartnerLibrary
+		if err := doc.DataTo(&partner); err != nil {
+			return nil, fmt.Errorf("błąd parsowania biblioteki partnerskiej: %w", err)
+		}
+
+		partner.ID = doc.Ref.ID
+		partners = append(partners, &partner)
+	}
+
+	// Sortowanie w aplikacji - bez rozróżniania wielkości liter
+	sort.Slice(partners, func(i, j int) bool {
+		return strings.ToLower(partners[i].Name) < strings.ToLower(partners[j].Name)
+	})
+	return partners, nil
+}
+
+func validatePartnerLibrary(partner *models.PartnerLibrary) error {
+	if partner == nil {
+		return fmt.Errorf("biblioteka partnerska nie może być nil")
+	}
+	if strings.TrimSpace(partner.Name) == "" {
+		return fmt.Errorf("nazwa biblioteki jest wymagana")
+	}
+	if partner.LoanDays < 0 {
+		return fmt.Errorf("okres wypożyczenia nie może być ujemny")
+	}
+	if partner.Fee < 0 {
+		return fmt.Errorf("opłata nie może być ujemna")
+	}
+	return nil
+}
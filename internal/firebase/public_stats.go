@@ -0,0 +1,52 @@
+package firebase
+
+import (
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+
+	"library-management-system/internal/models"
+)
+
+// PublicStatsCollection to nazwa kolekcji dziennych zrzutów publicznych statystyk w Firestore
+const PublicStatsCollection = "public_stats"
+
+// SavePublicStats zapisuje dzienny zrzut statystyk (ponowny zapis tego samego dnia go zastępuje)
+func (c *Client) SavePublicStats(stats *models.PublicStats) error {
+	c, span := c.startSpan("SavePublicStats")
+	defer span.End()
+
+	if stats == nil || stats.Day == "" {
+		return fmt.Errorf("dzień zrzutu statystyk nie może być pusty")
+	}
+
+	if _, err := c.collection(PublicStatsCollection).Doc(stats.Day).Set(c.ctx, stats); err != nil {
+		return fmt.Errorf("błąd zapisywania statystyk publicznych: %w", err)
+	}
+
+	return nil
+}
+
+// GetLatestPublicStats pobiera najnowszy zrzut statystyk (nil, gdy jeszcze żadnego nie ma)
+func (c *Client) GetLatestPublicStats() (*models.PublicStats, error) {
+	c, span := c.startSpan("GetLatestPublicStats")
+	defer span.End()
+
+	docs, err := c.collection(PublicStatsCollection).
+		OrderBy("day", firestore.Desc).
+		Limit(1).
+		Documents(c.ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania statystyk publicznych: %w", err)
+	}
+	if len(docs) == 0 {
+		return nil, nil
+	}
+
+	var stats models.PublicStats
+	if err := docs[0].DataTo(&stats); err != nil {
+		return nil, fmt.Errorf("błąd parsowania statystyk publicznych: %w", err)
+	}
+
+	return &stats, nil
+}
@@ -0,0 +1,126 @@
+package firebase
+
+import (
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"library-management-system/internal/models"
+)
+
+const (
+	// PurchaseSuggestionsCollection to nazwa kolekcji propozycji zakupu w Firestore
+	PurchaseSuggestionsCollection = "purchase_suggestions"
+)
+
+// CreatePurchaseSuggestion zapisuje propozycję zakupu
+func (c *Client) CreatePurchaseSuggestion(suggestion *models.PurchaseSuggestion) error {
+	c, span := c.startSpan("CreatePurchaseSuggestion")
+	defer span.End()
+
+	if suggestion == nil {
+		return fmt.Errorf("propozycja nie może być nil")
+	}
+	if suggestion.Title == "" {
+		return fmt.Errorf("tytuł jest wymagany")
+	}
+
+	now := time.Now()
+	suggestion.Status = models.PurchaseSuggestionNew
+	suggestion.CreatedAt = now
+	suggestion.UpdatedAt = now
+
+	docRef := c.collection(PurchaseSuggestionsCollection).NewDoc()
+	suggestion.ID = docRef.ID
+
+	if _, err := docRef.Set(c.ctx, suggestion); err != nil {
+		return fmt.Errorf("błąd zapisywania propozycji: %w", err)
+	}
+
+	return nil
+}
+
+// UpdatePurchaseSuggestionStatus zmienia status propozycji zakupu
+func (c *Client) UpdatePurchaseSuggestionStatus(id string, status models.PurchaseSuggestionStatus) error {
+	c, span := c.startSpan("UpdatePurchaseSuggestionStatus")
+	defer span.End()
+
+	if id == "" {
+		return fmt.Errorf("ID propozycji nie może być puste")
+	}
+
+	_, err := c.collection(PurchaseSuggestionsCollection).Doc(id).Update(c.ctx, []firestore.Update{
+		{Path: "status", Value: status},
+		{Path: "updated_at", Value: time.Now()},
+	})
+	if err != nil {
+		return fmt.Errorf("błąd aktualizacji propozycji: %w", err)
+	}
+
+	return nil
+}
+
+// GetOpenSuggestionBookIDs zwraca ID książek z katalogu, dla których istnieje
+// nieodrzucona propozycja dokupienia egzemplarzy
+func (c *Client) GetOpenSuggestionBookIDs(bookIDs []string) (map[string]bool, error) {
+	c, span := c.startSpan("GetOpenSuggestionBookIDs")
+	defer span.End()
+
+	open := make(map[string]bool)
+	for start := 0; start < len(bookIDs); start += inQueryLimit {
+		chunk := bookIDs[start:min(start+inQueryLimit, len(bookIDs))]
+
+		docs, err := c.collection(PurchaseSuggestionsCollection).
+			Where("book_id", "in", chunk).
+			Documents(c.ctx).GetAll()
+		if err != nil {
+			return nil, fmt.Errorf("błąd pobierania propozycji: %w", err)
+		}
+
+		for _, doc := range docs {
+			var suggestion models.PurchaseSuggestion
+			if err := doc.DataTo(&suggestion); err != nil {
+				return nil, fmt.Errorf("błąd parsowania propozycji: %w", err)
+			}
+			if suggestion.Status != models.PurchaseSuggestionRejected {
+				open[suggestion.BookID] = true
+			}
+		}
+	}
+	return open, nil
+}
+
+// ListPurchaseSuggestions pobiera propozycje zakupu (najnowsze pierwsze)
+func (c *Client) ListPurchaseSuggestions() ([]*models.PurchaseSuggestion, error) {
+	c, span := c.startSpan("ListPurchaseSuggestions")
+	defer span.End()
+
+	var suggestions []*models.PurchaseSuggestion
+
+	iter := c.collection(PurchaseSuggestionsCollection).
+		OrderBy("created_at", firestore.Desc).
+		Documents(c.ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("błąd iteracji po propozycjach: %w", err)
+		}
+
+		var suggestion models.PurchaseSuggestion
+		if err := doc.DataTo(&suggestion); err != nil {
+			return nil, fmt.Errorf("błąd parsowania propozycji: %w", err)
+		}
+
+		suggestion.ID = doc.Ref.ID
+		suggestions = append(suggestions, &suggestion)
+	}
+
+	return suggestions, nil
+}
@@ -0,0 +1,115 @@
+package firebase
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/firestore"
+
+	"library-management-system/internal/models"
+)
+
+const (
+	// PushSubscriptionsCollection to nazwa kolekcji subskrypcji Web Push w Firestore
+	PushSubscriptionsCollection = "push_subscriptions"
+)
+
+// SavePushSubscription zapisuje subskrypcję Web Push. ID dokumentu wynika z adresu
+// usługi push, więc ponowna zgoda z tej samej przeglądarki (także po zalogowaniu
+// innego czytelnika) nadpisuje poprzednią zamiast tworzyć duplikat.
+func (c *Client) SavePushSubscription(sub *models.PushSubscription) error {
+	c, span := c.startSpan("SavePushSubscription")
+	defer span.End()
+
+	if sub == nil {
+		return fmt.Errorf("subskrypcja push nie może być nil")
+	}
+	if sub.UserID == "" || sub.Endpoint == "" {
+		return fmt.Errorf("ID użytkownika i adres usługi push są wymagane")
+	}
+
+	hash := sha256.Sum256([]byte(sub.Endpoint))
+	sub.ID = hex.EncodeToString(hash[:16])
+	sub.CreatedAt = time.Now()
+
+	if _, err := c.collection(PushSubscriptionsCollection).Doc(sub.ID).Set(c.ctx, sub); err != nil {
+		return fmt.Errorf("błąd zapisywania subskrypcji push: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserPushSubscriptions pobiera urządzenia czytelnika, od najnowszego
+func (c *Client) GetUserPushSubscriptions(userID string) ([]*models.PushSubscription, error) {
+	c, span := c.startSpan("GetUserPushSubscriptions")
+	defer span.End()
+
+	if userID == "" {
+		return nil, fmt.Errorf("ID użytkownika nie może być puste")
+	}
+
+	docs, err := c.collection(PushSubscriptionsCollection).Where("user_id", "==", userID).Documents(c.ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania subskrypcji push: %w", err)
+	}
+
+	subs := make([]*models.PushSubscription, 0, len(docs))
+	for _, doc := range docs {
+		var sub models.PushSubscription
+		if err := doc.DataTo(&sub); err != nil {
+			return nil, fmt.Errorf("błąd parsowania subskrypcji push: %w", err)
+		}
+		sub.ID = doc.Ref.ID
+		subs = append(subs, &sub)
+	}
+
+	sort.Slice(subs, func(i, j int) bool {
+		return subs[i].CreatedAt.After(subs[j].CreatedAt)
+	})
+	return subs, nil
+}
+
+// DeletePushSubscription usuwa urządzenie czytelnika. Cudzej subskrypcji nie usuwa.
+func (c *Client) DeletePushSubscription(userID, id string) error {
+	c, span := c.startSpan("DeletePushSubscription")
+	defer span.End()
+
+	if id == "" {
+		return fmt.Errorf("ID subskrypcji push nie może być puste")
+	}
+
+	ref := c.collection(PushSubscriptionsCollection).Doc(id)
+	doc, err := ref.Get(c.ctx)
+	if err != nil {
+		return fmt.Errorf("nie znaleziono subskrypcji push: %w", err)
+	}
+	if owner, _ := doc.DataAt("user_id"); owner != userID {
+		return fmt.Errorf("subskrypcja push należy do innego użytkownika")
+	}
+
+	if _, err := ref.Delete(c.ctx); err != nil {
+		return fmt.Errorf("błąd usuwania subskrypcji push: %w", err)
+	}
+
+	return nil
+}
+
+// SetPushOnly ustawia, czy powiadomienia dostarczone przez push
+// zastępują email
+func (c *Client) SetPushOnly(userID string, enabled bool) error {
+	c, span := c.startSpan("SetPushOnly")
+	defer span.End()
+
+	_, err := c.collection(UsersCollection).Doc(userID).Update(c.ctx, []firestore.Update{
+		{Path: "push_only", Value: enabled},
+		{Path: "updated_at", Value: time.Now()},
+	})
+	if err != nil {
+		return fmt.Errorf("błąd zapisywania ustawień powiadomień: %w", err)
+	}
+
+	return nil
+}
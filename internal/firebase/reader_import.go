@@ -0,0 +1,92 @@
+package firebase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"firebase.google.com/go/v4/auth"
+
+	"library-management-system/internal/models"
+)
+
+// CheckReaderImportDuplicates oznacza wiersze importu, których czytelnik ma już konto:
+// profil z tym samym emailem lub numerem karty w bibliotece albo konto Firebase Auth
+// z tym emailem (konta logowania są wspólne dla bibliotek sieci). Sprawdzane są tylko
+// wiersze bez błędów walidacji.
+func (c *Client) CheckReaderImportDuplicates(rows []*models.ReaderImportRow) error {
+	c, span := c.startSpan("CheckReaderImportDuplicates")
+	defer span.End()
+
+	for _, row := range rows {
+		if !row.Valid() {
+			continue
+		}
+
+		docs, err := c.collection(UsersCollection).Where("email", "==", row.Email).Limit(1).Documents(c.ctx).GetAll()
+		if err != nil {
+			return fmt.Errorf("błąd wyszukiwania czytelnika po emailu: %w", err)
+		}
+		if len(docs) > 0 {
+			row.AddProblem("czytelnik z tym emailem jest już w bazie")
+		} else if _, err := c.Auth.GetUserByEmail(c.ctx, row.Email); err == nil {
+			row.AddProblem("ten email ma już konto logowania (także w innej bibliotece sieci)")
+		} else if !auth.IsUserNotFound(err) {
+			return fmt.Errorf("błąd wyszukiwania konta Firebase Auth: %w", err)
+		}
+
+		if row.CardNumber != "" {
+			holder, err := c.GetUserByCardNumber(row.CardNumber)
+			if err != nil {
+				return err
+			}
+			if holder != nil {
+				row.AddProblem("numer karty ma już czytelnik %s", holder.FullName())
+			}
+		}
+	}
+	return nil
+}
+
+// ImportReader zakłada czytelnikowi z importu konto Firebase Auth i profil z numerem
+// karty i saldem ze starego systemu. Konto dostaje losowe hasło, którego nikt nie zna -
+// czytelnik ustawia własne przez link z emaila (SendPasswordResetEmail).
+func (c *Client) ImportReader(row *models.ReaderImportRow) (*models.User, error) {
+	c, span := c.startSpan("ImportReader")
+	defer span.End()
+
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("błąd generowania hasła: %w", err)
+	}
+
+	params := (&auth.UserToCreate{}).
+		Email(row.Email).
+		Password(hex.EncodeToString(raw)).
+		DisplayName(row.FirstName + " " + row.LastName)
+	account, err := c.Auth.CreateUser(c.ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("błąd tworzenia konta Firebase Auth: %w", err)
+	}
+
+	// Import prowadzi personel, więc konto nie czeka na zatwierdzenie tożsamości
+	user := &models.User{
+		FirebaseUID: account.UID,
+		Email:       row.Email,
+		FirstName:   row.FirstName,
+		LastName:    row.LastName,
+		Phone:       row.Phone,
+		Role:        models.RoleReader,
+		CardNumber:  row.CardNumber,
+		TotalFines:  row.Balance,
+	}
+	if err := c.CreateUser(user); err != nil {
+		// Konto bez profilu nie mogłoby się zalogować, a blokowałoby ponowny import
+		if delErr := c.Auth.DeleteUser(c.ctx, account.UID); delErr != nil {
+			return nil, fmt.Errorf("%w (nie usunięto konta Firebase Auth %s: %v)", err, account.UID, delErr)
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,361 @@
+package firebase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"library-management-system/internal/models"
+)
+
+const (
+	// ReadingListsCollection to nazwa kolekcji list lektur personelu w Firestore
+	ReadingListsCollection = "reading_lists"
+
+	// UserReadingListsCollection to nazwa kolekcji list lektur czytelników
+	UserReadingListsCollection = "user_reading_lists"
+)
+
+// CreateReadingList zapisuje nową listę lektur
+func (c *Client) CreateReadingList(list *models.ReadingList) error {
+	c, span := c.startSpan("CreateReadingList")
+	defer span.End()
+
+	if err := c.validateReadingList(list); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	list.CreatedAt = now
+	list.UpdatedAt = now
+
+	docRef := c.collection(ReadingListsCollection).NewDoc()
+	list.ID = docRef.ID
+
+	if _, err := docRef.Set(c.ctx, list); err != nil {
+		return fmt.Errorf("błąd zapisywania listy lektur: %w", err)
+	}
+
+	return nil
+}
+
+// GetReadingList pobiera listę lektur po ID
+func (c *Client) GetReadingList(id string) (*models.ReadingList, error) {
+	c, span := c.startSpan("GetReadingList")
+	defer span.End()
+
+	if id == "" {
+		return nil, fmt.Errorf("ID listy lektur nie może być puste")
+	}
+
+	doc, err := c.collection(ReadingListsCollection).Doc(id).Get(c.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania listy lektur: %w", err)
+	}
+
+	var list models.ReadingList
+	if err := doc.DataTo(&list); err != nil {
+		return nil, fmt.Errorf("błąd parsowania listy lektur: %w", err)
+	}
+	list.ID = doc.Ref.ID
+
+	return &list, nil
+}
+
+// GetReadingListBySlug pobiera listę lektur po adresie albo nil, jeśli jej nie ma
+func (c *Client) GetReadingListBySlug(slug string) (*models.ReadingList, error) {
+	c, span := c.startSpan("GetReadingListBySlug")
+	defer span.End()
+
+	if slug == "" {
+		return nil, nil
+	}
+
+	lists, err := c.listReadingLists(c.collection(ReadingListsCollection).
+		Where("slug", "==", slug).
+		Limit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(lists) == 0 {
+		return nil, nil
+	}
+	return lists[0], nil
+}
+
+// UpdateReadingList zapisuje zmiany listy lektur (opis, pozycje i ich kolejność)
+func (c *Client) UpdateReadingList(list *models.ReadingList) error {
+	c, span := c.startSpan("UpdateReadingList")
+	defer span.End()
+
+	if list == nil || list.ID == "" {
+		return fmt.Errorf("ID listy lektur nie może być puste")
+	}
+	if err := c.validateReadingList(list); err != nil {
+		return err
+	}
+
+	list.UpdatedAt = time.Now()
+	if _, err := c.collection(ReadingListsCollection).Doc(list.ID).Set(c.ctx, list); err != nil {
+		return fmt.Errorf("błąd aktualizacji listy lektur: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteReadingList usuwa listę lektur
+func (c *Client) DeleteReadingList(id string) error {
+	c, span := c.startSpan("DeleteReadingList")
+	defer span.End()
+
+	if id == "" {
+		return fmt.Errorf("ID listy lektur nie może być puste")
+	}
+
+	if _, err := c.collection(ReadingListsCollection).Doc(id).Delete(c.ctx); err != nil {
+		return fmt.Errorf("błąd usuwania listy lektur: %w", err)
+	}
+
+	return nil
+}
+
+// ListReadingLists pobiera wszystkie listy lektur posortowane według tytułu
+func (c *Client) ListReadingLists() ([]*models.ReadingList, error) {
+	c, span := c.startSpan("ListReadingLists")
+	defer span.End()
+
+	lists, err := c.listReadingLists(c.collection(ReadingListsCollection).Query)
+	if err != nil {
+		return nil, err
+	}
+
+	sortReadingListsByTitle(lists)
+	return lists, nil
+}
+
+// GetPublishedReadingLists pobiera opublikowane listy lektur posortowane według
+// tytułu (sortowanie w Go - bez indeksu złożonego)
+func (c *Client) GetPublishedReadingLists() ([]*models.ReadingList, error) {
+	c, span := c.startSpan("GetPublishedReadingLists")
+	defer span.End()
+
+	lists, err := c.listReadingLists(c.collection(ReadingListsCollection).Where("published", "==", true))
+	if err != nil {
+		return nil, err
+	}
+
+	sortReadingListsByTitle(lists)
+	return lists, nil
+}
+
+// validateReadingList sprawdza listę personelu: poprawność pozycji i to,
+// czy adres nie jest zajęty przez inną listę
+func (c *Client) validateReadingList(list *models.ReadingList) error {
+	if err := list.Normalize(); err != nil {
+		return err
+	}
+	if list.Slug == "" {
+		return fmt.Errorf("adres listy lektur musi zawierać litery lub cyfry")
+	}
+
+	other, err := c.GetReadingListBySlug(list.Slug)
+	if err != nil {
+		return err
+	}
+	if other != nil && other.ID != list.ID {
+		return fmt.Errorf("adres /lists/%s ma już lista %q", list.Slug, other.Title)
+	}
+
+	return nil
+}
+
+// CreateUserReadingList zapisuje nową listę czytelnika z losowym tokenem
+// linku do udostępniania
+func (c *Client) CreateUserReadingList(list *models.ReadingList) error {
+	c, span := c.startSpan("CreateUserReadingList")
+	defer span.End()
+
+	if err := list.Normalize(); err != nil {
+		return err
+	}
+	if list.OwnerID == "" {
+		return fmt.Errorf("lista czytelnika musi mieć właściciela")
+	}
+
+	existing, err := c.GetUserReadingLists(list.OwnerID)
+	if err != nil {
+		return err
+	}
+	if len(existing) >= models.MaxUserReadingLists {
+		return fmt.Errorf("można mieć najwyżej %d list", models.MaxUserReadingLists)
+	}
+
+	token, err := models.NewShareToken()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	list.ShareToken = token
+	list.CreatedAt = now
+	list.UpdatedAt = now
+
+	docRef := c.collection(UserReadingListsCollection).NewDoc()
+	list.ID = docRef.ID
+
+	if _, err := docRef.Set(c.ctx, list); err != nil {
+		return fmt.Errorf("błąd zapisywania listy: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserReadingList pobiera listę czytelnika po ID
+func (c *Client) GetUserReadingList(id string) (*models.ReadingList, error) {
+	c, span := c.startSpan("GetUserReadingList")
+	defer span.End()
+
+	if id == "" {
+		return nil, fmt.Errorf("ID listy nie może być puste")
+	}
+
+	doc, err := c.collection(UserReadingListsCollection).Doc(id).Get(c.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania listy: %w", err)
+	}
+
+	var list models.ReadingList
+	if err := doc.DataTo(&list); err != nil {
+		return nil, fmt.Errorf("błąd parsowania listy: %w", err)
+	}
+	list.ID = doc.Ref.ID
+
+	return &list, nil
+}
+
+// UpdateUserReadingList zapisuje zmiany listy czytelnika
+func (c *Client) UpdateUserReadingList(list *models.ReadingList) error {
+	c, span := c.startSpan("UpdateUserReadingList")
+	defer span.End()
+
+	if list == nil || list.ID == "" {
+		return fmt.Errorf("ID listy nie może być puste")
+	}
+	if err := list.Normalize(); err != nil {
+		return err
+	}
+
+	list.UpdatedAt = time.Now()
+	if _, err := c.collection(UserReadingListsCollection).Doc(list.ID).Set(c.ctx, list); err != nil {
+		return fmt.Errorf("błąd aktualizacji listy: %w", err)
+	}
+
+	return nil
+}
+
+// RenewShareToken nadaje liście nowy token - dotychczasowy link przestaje działać
+func (c *Client) RenewShareToken(list *models.ReadingList) error {
+	c, span := c.startSpan("RenewShareToken")
+	defer span.End()
+
+	token, err := models.NewShareToken()
+	if err != nil {
+		return err
+	}
+
+	list.ShareToken = token
+	return c.UpdateUserReadingList(list)
+}
+
+// DeleteUserReadingList usuwa listę czytelnika
+func (c *Client) DeleteUserReadingList(id string) error {
+	c, span := c.startSpan("DeleteUserReadingList")
+	defer span.End()
+
+	if id == "" {
+		return fmt.Errorf("ID listy nie może być puste")
+	}
+
+	if _, err := c.collection(UserReadingListsCollection).Doc(id).Delete(c.ctx); err != nil {
+		return fmt.Errorf("błąd usuwania listy: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserReadingLists pobiera listy czytelnika posortowane według tytułu
+func (c *Client) GetUserReadingLists(userID string) ([]*models.ReadingList, error) {
+	c, span := c.startSpan("GetUserReadingLists")
+	defer span.End()
+
+	if userID == "" {
+		return nil, fmt.Errorf("ID użytkownika nie może być puste")
+	}
+
+	lists, err := c.listReadingLists(c.collection(UserReadingListsCollection).Where("owner_id", "==", userID))
+	if err != nil {
+		return nil, err
+	}
+
+	sortReadingListsByTitle(lists)
+	return lists, nil
+}
+
+// GetReadingListByShareToken pobiera listę czytelnika po tokenie z linku albo
+// nil, jeśli takiej nie ma. O tym, czy link działa, decyduje flaga Shared.
+func (c *Client) GetReadingListByShareToken(token string) (*models.ReadingList, error) {
+	c, span := c.startSpan("GetReadingListByShareToken")
+	defer span.End()
+
+	if token == "" {
+		return nil, nil
+	}
+
+	lists, err := c.listReadingLists(c.collection(UserReadingListsCollection).
+		Where("share_token", "==", token).
+		Limit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(lists) == 0 {
+		return nil, nil
+	}
+	return lists[0], nil
+}
+
+func sortReadingListsByTitle(lists []*models.ReadingList) {
+	sort.Slice(lists, func(i, j int) bool {
+		return strings.ToLower(lists[i].Title) < strings.ToLower(lists[j].Title)
+	})
+}
+
+func (c *Client) listReadingLists(query firestore.Query) ([]*models.ReadingList, error) {
+	var lists []*models.ReadingList
+
+	iter := query.Documents(c.ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("błąd iteracji po listach lektur: %w", err)
+		}
+
+		var list models.ReadingList
+		if err := doc.DataTo(&list); err != nil {
+			return nil, fmt.Errorf("błąd parsowania listy lektur: %w", err)
+		}
+
+		list.ID = doc.Ref.ID
+		lists = append(lists, &list)
+	}
+
+	return lists, nil
+}
@@ -0,0 +1,203 @@
+package firebase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"library-management-system/internal/models"
+)
+
+const (
+	// RegulationsCollection to nazwa kolekcji wersji regulaminu (ID dokumentu = numer wersji)
+	RegulationsCollection = "regulations"
+	// RegulationsAcceptancesCollection to nazwa kolekcji akceptacji regulaminu przez czytelników
+	RegulationsAcceptancesCollection = "regulations_acceptances"
+)
+
+// GetCurrentRegulations zwraca obowiązującą (najnowszą) wersję regulaminu, z pamięci po
+// pierwszym odczycie - sprawdzana jest przy każdym żądaniu czytelnika. Zwraca nil, gdy
+// biblioteka nie opublikowała jeszcze regulaminu.
+func (c *Client) GetCurrentRegulations() (*models.Regulations, error) {
+	c, span := c.startSpan("GetCurrentRegulations")
+	defer span.End()
+
+	if cached := c.cache.regulations.Load(); cached != nil {
+		if cached.Version == 0 {
+			return nil, nil
+		}
+		return cached, nil
+	}
+
+	iter := c.collection(RegulationsCollection).OrderBy("version", firestore.Desc).Limit(1).Documents(c.ctx)
+	defer iter.Stop()
+
+	doc, err := iter.Next()
+	if err == iterator.Done {
+		// Pusta wersja w pamięci oznacza brak regulaminu
+		c.cache.regulations.Store(&models.Regulations{})
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania regulaminu: %w", err)
+	}
+
+	var regulations models.Regulations
+	if err := doc.DataTo(&regulations); err != nil {
+		return nil, fmt.Errorf("błąd parsowania regulaminu: %w", err)
+	}
+
+	c.cache.regulations.Store(&regulations)
+	return &regulations, nil
+}
+
+// GetRegulations pobiera wybraną wersję regulaminu (nil, gdy nie istnieje)
+func (c *Client) GetRegulations(version int) (*models.Regulations, error) {
+	c, span := c.startSpan("GetRegulations")
+	defer span.End()
+
+	doc, err := c.collection(RegulationsCollection).Doc(strconv.Itoa(version)).Get(c.ctx)
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania regulaminu: %w", err)
+	}
+
+	var regulations models.Regulations
+	if err := doc.DataTo(&regulations); err != nil {
+		return nil, fmt.Errorf("błąd parsowania regulaminu: %w", err)
+	}
+	return &regulations, nil
+}
+
+// ListRegulations pobiera wszystkie wersje regulaminu (najnowsze pierwsze)
+func (c *Client) ListRegulations() ([]*models.Regulations, error) {
+	c, span := c.startSpan("ListRegulations")
+	defer span.End()
+
+	docs, err := c.collection(RegulationsCollection).OrderBy("version", firestore.Desc).Documents(c.ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania wersji regulaminu: %w", err)
+	}
+
+	versions := make([]*models.Regulations, 0, len(docs))
+	for _, doc := range docs {
+		var regulations models.Regulations
+		if err := doc.DataTo(&regulations); err != nil {
+			return nil, fmt.Errorf("błąd parsowania regulaminu: %w", err)
+		}
+		versions = append(versions, &regulations)
+	}
+	return versions, nil
+}
+
+// PublishRegulations publikuje nową wersję regulaminu o numerze o jeden większym od
+// obowiązującej. Od tej chwili czytelnicy muszą ją zaakceptować.
+func (c *Client) PublishRegulations(body, changes string, publisher *models.User) (*models.Regulations, error) {
+	c, span := c.startSpan("PublishRegulations")
+	defer span.End()
+
+	if body == "" {
+		return nil, fmt.Errorf("treść regulaminu jest wymagana")
+	}
+
+	// Wersja z pamięci mogła zostać zastąpiona przez inną instancję serwera
+	c.cache.regulations.Store(nil)
+	current, err := c.GetCurrentRegulations()
+	if err != nil {
+		return nil, err
+	}
+
+	regulations := &models.Regulations{
+		Version:         1,
+		Body:            body,
+		Changes:         changes,
+		PublishedAt:     time.Now(),
+		PublishedBy:     publisher.ID,
+		PublishedByName: publisher.FullName(),
+	}
+	if current != nil {
+		if current.Body == body {
+			return nil, fmt.Errorf("treść nie różni się od obowiązującej wersji %d", current.Version)
+		}
+		regulations.Version = current.Version + 1
+	}
+
+	_, err = c.collection(RegulationsCollection).Doc(strconv.Itoa(regulations.Version)).Create(c.ctx, regulations)
+	if status.Code(err) == codes.AlreadyExists {
+		return nil, fmt.Errorf("wersja %d została właśnie opublikowana przez kogoś innego - odśwież stronę", regulations.Version)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("błąd publikowania regulaminu: %w", err)
+	}
+
+	c.cache.regulations.Store(regulations)
+	return regulations, nil
+}
+
+// AcceptRegulations zapisuje akceptację wersji regulaminu w profilu czytelnika
+// i w historii akceptacji
+func (c *Client) AcceptRegulations(user *models.User, version int, at time.Time) error {
+	c, span := c.startSpan("AcceptRegulations")
+	defer span.End()
+
+	acceptance := &models.RegulationsAcceptance{
+		UserID:     user.ID,
+		Version:    version,
+		AcceptedAt: at,
+	}
+	acceptanceRef := c.collection(RegulationsAcceptancesCollection).Doc(strconv.Itoa(version) + "_" + user.ID)
+
+	err := c.Firestore.RunTransaction(c.ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if err := tx.Update(c.collection(UsersCollection).Doc(user.ID), []firestore.Update{
+			{Path: "regulations_version", Value: version},
+			{Path: "regulations_accepted_at", Value: at},
+			{Path: "updated_at", Value: at},
+		}); err != nil {
+			return err
+		}
+		return tx.Set(acceptanceRef, acceptance)
+	})
+	if err != nil {
+		return fmt.Errorf("błąd zapisywania akceptacji regulaminu: %w", err)
+	}
+
+	user.RegulationsVersion = version
+	user.RegulationsAcceptedAt = &at
+	return nil
+}
+
+// GetUserRegulationsAcceptances pobiera historię akceptacji regulaminu przez czytelnika
+// (najnowsze pierwsze)
+func (c *Client) GetUserRegulationsAcceptances(userID string) ([]*models.RegulationsAcceptance, error) {
+	c, span := c.startSpan("GetUserRegulationsAcceptances")
+	defer span.End()
+
+	docs, err := c.collection(RegulationsAcceptancesCollection).Where("user_id", "==", userID).Documents(c.ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania akceptacji regulaminu: %w", err)
+	}
+
+	acceptances := make([]*models.RegulationsAcceptance, 0, len(docs))
+	for _, doc := range docs {
+		var acceptance models.RegulationsAcceptance
+		if err := doc.DataTo(&acceptance); err != nil {
+			return nil, fmt.Errorf("błąd parsowania akceptacji regulaminu: %w", err)
+		}
+		acceptances = append(acceptances, &acceptance)
+	}
+
+	// Sortowanie w pamięci - czytelnik ma najwyżej kilka akceptacji, a zapytanie nie wymaga indeksu złożonego
+	sort.Slice(acceptances, func(i, j int) bool {
+		return acceptances[i].Version > acceptances[j].Version
+	})
+	return acceptances, nil
+}
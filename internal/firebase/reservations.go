@@ -2,12 +2,17 @@ package firebase
 
 import (
 	"fmt"
+	"log"
+	"sort"
+	"strings"
 	"time"
 
 	"cloud.google.com/go/firestore"
 	"google.golang.org/api/iterator"
 
+	"library-management-system/internal/events"
 	"library-management-system/internal/models"
+	"library-management-system/internal/search"
 )
 
 const (
@@ -17,11 +22,14 @@ const (
 
 // GetReservation pobiera rezerwację po ID
 func (c *Client) GetReservation(id string) (*models.Reservation, error) {
+	c, span := c.startSpan("GetReservation")
+	defer span.End()
+
 	if id == "" {
 		return nil, fmt.Errorf("ID rezerwacji nie może być puste")
 	}
 
-	doc, err := c.Firestore.Collection(ReservationsCollection).Doc(id).Get(c.ctx)
+	doc, err := c.collection(ReservationsCollection).Doc(id).Get(c.ctx)
 	if err != nil {
 		return nil, fmt.Errorf("błąd pobierania rezerwacji: %w", err)
 	}
@@ -36,6 +44,9 @@ func (c *Client) GetReservation(id string) (*models.Reservation, error) {
 
 // CreateReservation tworzy nową rezerwację
 func (c *Client) CreateReservation(reservation *models.Reservation) error {
+	c, span := c.startSpan("CreateReservation")
+	defer span.End()
+
 	if reservation == nil {
 		return fmt.Errorf("rezerwacja nie może być nil")
 	}
@@ -52,18 +63,18 @@ func (c *Client) CreateReservation(reservation *models.Reservation) error {
 	reservation.ReservationDate = now
 	reservation.Status = models.ReservationStatusPending
 
-	// Domyślnie rezerwacja wygasa po 3 dniach od powiadomienia
+	// Domyślnie rezerwacja wygasa po czasie na odbiór z ustawień biblioteki
 	if reservation.ExpiryDate.IsZero() {
-		reservation.ExpiryDate = now.AddDate(0, 0, 3)
+		reservation.ExpiryDate = now.AddDate(0, 0, c.loanPolicy().PickupDays)
 	}
 
 	// Wygeneruj ID
 	var docRef *firestore.DocumentRef
 	if reservation.ID == "" {
-		docRef = c.Firestore.Collection(ReservationsCollection).NewDoc()
+		docRef = c.collection(ReservationsCollection).NewDoc()
 		reservation.ID = docRef.ID
 	} else {
-		docRef = c.Firestore.Collection(ReservationsCollection).Doc(reservation.ID)
+		docRef = c.collection(ReservationsCollection).Doc(reservation.ID)
 	}
 
 	_, err := docRef.Set(c.ctx, reservation)
@@ -71,11 +82,15 @@ func (c *Client) CreateReservation(reservation *models.Reservation) error {
 		return fmt.Errorf("błąd zapisywania rezerwacji: %w", err)
 	}
 
+	c.publish(events.CirculationChanged, reservation.ID)
 	return nil
 }
 
 // UpdateReservation aktualizuje rezerwację
 func (c *Client) UpdateReservation(id string, reservation *models.Reservation) error {
+	c, span := c.startSpan("UpdateReservation")
+	defer span.End()
+
 	if id == "" {
 		return fmt.Errorf("ID rezerwacji nie może być puste")
 	}
@@ -91,16 +106,20 @@ func (c *Client) UpdateReservation(id string, reservation *models.Reservation) e
 	reservation.UpdatedAt = time.Now()
 	reservation.ID = id
 
-	_, err = c.Firestore.Collection(ReservationsCollection).Doc(id).Set(c.ctx, reservation)
+	_, err = c.collection(ReservationsCollection).Doc(id).Set(c.ctx, reservation)
 	if err != nil {
 		return fmt.Errorf("błąd aktualizacji rezerwacji: %w", err)
 	}
 
+	c.publish(events.CirculationChanged, id)
 	return nil
 }
 
-// MarkReservationReady oznacza rezerwację jako gotową do odbioru
-func (c *Client) MarkReservationReady(reservationID string) error {
+// MarkReservationReady oznacza rezerwację jako gotową do odbioru egzemplarzem książki bookID
+func (c *Client) MarkReservationReady(reservationID, bookID string) error {
+	c, span := c.startSpan("MarkReservationReady")
+	defer span.End()
+
 	reservation, err := c.GetReservation(reservationID)
 	if err != nil {
 		return err
@@ -110,17 +129,52 @@ func (c *Client) MarkReservationReady(reservationID string) error {
 		return fmt.Errorf("rezerwacja nie jest w stanie oczekiwania")
 	}
 
+	// Czytelnik z pełnym limitem nie mógłby odebrać książki - egzemplarz dostaje następna
+	// osoba (GetNextReservation), a rezerwacja czeka w kolejce
+	user, err := c.GetUser(reservation.UserID)
+	if err != nil {
+		return err
+	}
+	if user.AtLoanLimit() {
+		return fmt.Errorf("czytelnik ma wypożyczone książki do limitu (%d)", user.MaxLoans)
+	}
+
+	// Czytelnik, który nie zastrzegł wydania, dostaje egzemplarz innego wydania
+	if reservation.BookID != bookID {
+		book, err := c.GetBook(bookID)
+		if err != nil {
+			return err
+		}
+		reservation.BookID = book.ID
+		reservation.BookTitle = book.Title
+	}
+
 	now := time.Now()
 	reservation.Status = models.ReservationStatusReady
 	reservation.NotifiedDate = &now
-	reservation.ExpiryDate = now.AddDate(0, 0, 3) // 3 dni na odbiór
+	reservation.SuspendedAt = nil
+	reservation.ExpiryDate = now.AddDate(0, 0, c.loanPolicy().PickupDays) // Czas na odbiór z ustawień biblioteki
 	reservation.UpdatedAt = now
 
-	return c.UpdateReservation(reservationID, reservation)
+	if err := c.UpdateReservation(reservationID, reservation); err != nil {
+		return err
+	}
+
+	c.publish(events.ReservationReady, reservation)
+
+	// Czytelnicy z pełnym limitem, którzy byli wcześniej w kolejce, dowiadują się, dlaczego
+	// egzemplarz ich ominął
+	if err := c.SuspendLimitedReservations(bookID, reservation.CreatedAt); err != nil {
+		log.Printf("Błąd powiadamiania o pominiętych rezerwacjach książki %s: %v", bookID, err)
+	}
+	return nil
 }
 
 // CompleteReservation realizuje rezerwację (zamienia na wypożyczenie)
 func (c *Client) CompleteReservation(reservationID string) error {
+	c, span := c.startSpan("CompleteReservation")
+	defer span.End()
+
 	reservation, err := c.GetReservation(reservationID)
 	if err != nil {
 		return err
@@ -130,14 +184,23 @@ func (c *Client) CompleteReservation(reservationID string) error {
 		return fmt.Errorf("rezerwacja nie może być zrealizowana")
 	}
 
+	now := time.Now()
 	reservation.Status = models.ReservationStatusCompleted
-	reservation.UpdatedAt = time.Now()
+	reservation.CompletedAt = &now
+	reservation.UpdatedAt = now
 
-	return c.UpdateReservation(reservationID, reservation)
+	if err := c.UpdateReservation(reservationID, reservation); err != nil {
+		return err
+	}
+	c.promotePostponedHolds(reservation.UserID)
+	return nil
 }
 
 // CancelReservation anuluje rezerwację
 func (c *Client) CancelReservation(reservationID string) error {
+	c, span := c.startSpan("CancelReservation")
+	defer span.End()
+
 	reservation, err := c.GetReservation(reservationID)
 	if err != nil {
 		return err
@@ -147,17 +210,27 @@ func (c *Client) CancelReservation(reservationID string) error {
 		return fmt.Errorf("nie można anulować zrealizowanej rezerwacji")
 	}
 
+	wasReady := reservation.Status == models.ReservationStatusReady
 	reservation.Status = models.ReservationStatusCancelled
 	reservation.UpdatedAt = time.Now()
 
-	return c.UpdateReservation(reservationID, reservation)
+	if err := c.UpdateReservation(reservationID, reservation); err != nil {
+		return err
+	}
+	if wasReady {
+		c.promotePostponedHolds(reservation.UserID)
+	}
+	return nil
 }
 
 // ListReservations pobiera wszystkie rezerwacje
 func (c *Client) ListReservations() ([]*models.Reservation, error) {
+	c, span := c.startSpan("ListReservations")
+	defer span.End()
+
 	var reservations []*models.Reservation
 
-	iter := c.Firestore.Collection(ReservationsCollection).
+	iter := c.collection(ReservationsCollection).
 		OrderBy("reservation_date", firestore.Desc).
 		Documents(c.ctx)
 	defer iter.Stop()
@@ -184,13 +257,16 @@ func (c *Client) ListReservations() ([]*models.Reservation, error) {
 
 // GetUserReservations pobiera rezerwacje użytkownika
 func (c *Client) GetUserReservations(userID string) ([]*models.Reservation, error) {
+	c, span := c.startSpan("GetUserReservations")
+	defer span.End()
+
 	if userID == "" {
 		return nil, fmt.Errorf("ID użytkownika nie może być puste")
 	}
 
 	var reservations []*models.Reservation
 
-	iter := c.Firestore.Collection(ReservationsCollection).
+	iter := c.collection(ReservationsCollection).
 		Where("user_id", "==", userID).
 		OrderBy("reservation_date", firestore.Desc).
 		Documents(c.ctx)
@@ -218,13 +294,16 @@ func (c *Client) GetUserReservations(userID string) ([]*models.Reservation, erro
 
 // GetBookReservations pobiera rezerwacje książki
 func (c *Client) GetBookReservations(bookID string) ([]*models.Reservation, error) {
+	c, span := c.startSpan("GetBookReservations")
+	defer span.End()
+
 	if bookID == "" {
 		return nil, fmt.Errorf("ID książki nie może być puste")
 	}
 
 	var reservations []*models.Reservation
 
-	iter := c.Firestore.Collection(ReservationsCollection).
+	iter := c.collection(ReservationsCollection).
 		Where("book_id", "==", bookID).
 		OrderBy("reservation_date", firestore.Asc).
 		Documents(c.ctx)
@@ -252,9 +331,12 @@ func (c *Client) GetBookReservations(bookID string) ([]*models.Reservation, erro
 
 // GetPendingReservations pobiera oczekujące rezerwacje
 func (c *Client) GetPendingReservations() ([]*models.Reservation, error) {
+	c, span := c.startSpan("GetPendingReservations")
+	defer span.End()
+
 	var reservations []*models.Reservation
 
-	iter := c.Firestore.Collection(ReservationsCollection).
+	iter := c.collection(ReservationsCollection).
 		Where("status", "==", string(models.ReservationStatusPending)).
 		OrderBy("reservation_date", firestore.Asc).
 		Documents(c.ctx)
@@ -280,11 +362,43 @@ func (c *Client) GetPendingReservations() ([]*models.Reservation, error) {
 	return reservations, nil
 }
 
+// GetCompletedReservations pobiera rezerwacje zamienione w wypożyczenia od since
+// (do statystyk czasu oczekiwania na książkę)
+func (c *Client) GetCompletedReservations(since time.Time) ([]*models.Reservation, error) {
+	c, span := c.startSpan("GetCompletedReservations")
+	defer span.End()
+
+	docs, err := c.collection(ReservationsCollection).
+		Where("status", "==", string(models.ReservationStatusCompleted)).
+		Documents(c.ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania zrealizowanych rezerwacji: %w", err)
+	}
+
+	var reservations []*models.Reservation
+	for _, doc := range docs {
+		var reservation models.Reservation
+		if err := doc.DataTo(&reservation); err != nil {
+			return nil, fmt.Errorf("błąd parsowania rezerwacji: %w", err)
+		}
+		reservation.ID = doc.Ref.ID
+
+		// Filtrowanie daty w Go - bez indeksu złożonego
+		if wait, ok := reservation.BorrowWait(); ok && !reservation.ReservationDate.Add(wait).Before(since) {
+			reservations = append(reservations, &reservation)
+		}
+	}
+	return reservations, nil
+}
+
 // GetReadyReservations pobiera gotowe do odbioru rezerwacje
 func (c *Client) GetReadyReservations() ([]*models.Reservation, error) {
+	c, span := c.startSpan("GetReadyReservations")
+	defer span.End()
+
 	var reservations []*models.Reservation
 
-	iter := c.Firestore.Collection(ReservationsCollection).
+	iter := c.collection(ReservationsCollection).
 		Where("status", "==", string(models.ReservationStatusReady)).
 		OrderBy("expiry_date", firestore.Asc).
 		Documents(c.ctx)
@@ -312,6 +426,9 @@ func (c *Client) GetReadyReservations() ([]*models.Reservation, error) {
 
 // GetUserActiveReservations pobiera aktywne rezerwacje użytkownika
 func (c *Client) GetUserActiveReservations(userID string) ([]*models.Reservation, error) {
+	c, span := c.startSpan("GetUserActiveReservations")
+	defer span.End()
+
 	if userID == "" {
 		return nil, fmt.Errorf("ID użytkownika nie może być puste")
 	}
@@ -319,7 +436,7 @@ func (c *Client) GetUserActiveReservations(userID string) ([]*models.Reservation
 	var reservations []*models.Reservation
 
 	// Pobierz wszystkie rezerwacje użytkownika i filtruj po stronie aplikacji
-	iter := c.Firestore.Collection(ReservationsCollection).
+	iter := c.collection(ReservationsCollection).
 		Where("user_id", "==", userID).
 		Documents(c.ctx)
 	defer iter.Stop()
@@ -348,18 +465,229 @@ func (c *Client) GetUserActiveReservations(userID string) ([]*models.Reservation
 	return reservations, nil
 }
 
-// GetNextReservation pobiera pierwszą oczekującą rezerwację dla książki (najstarsza pending)
+// GetNextReservation pobiera pierwszą oczekującą rezerwację (najstarsza pending), której można
+// przydzielić zwrócony egzemplarz książki: rezerwację tego wydania albo innego wydania tego
+// samego utworu, jeśli czytelnik nie zastrzegł konkretnego wydania.
+// Rezerwacje czytelników na urlopie, czytelników z wypożyczonymi książkami do limitu i takich,
+// którzy mają już Settings.MaxReadyHolds rezerwacji gotowych do odbioru, są pomijane,
+// ale zachowują swoje miejsce w kolejce.
 func (c *Client) GetNextReservation(bookID string) (*models.Reservation, error) {
+	c, span := c.startSpan("GetNextReservation")
+	defer span.End()
+
+	queue, err := c.reservationQueue(bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	maxReady := c.loanPolicy().MaxReadyHolds
+	for _, r := range queue {
+		user, err := c.GetUser(r.UserID)
+		if err != nil {
+			// Bez profilu nie da się sprawdzić urlopu - rezerwacja zachowuje pierwszeństwo
+			log.Printf("Błąd pobierania czytelnika %s rezerwacji %s: %v", r.UserID, r.ID, err)
+			return r, nil
+		}
+		if user.HoldsPausedAt(now) || user.AtLoanLimit() {
+			continue
+		}
+		if maxReady > 0 {
+			ready, err := c.countReadyHolds(r.UserID)
+			if err != nil {
+				return nil, err
+			}
+			if ready >= maxReady {
+				log.Printf("Rezerwacja %s czeka: czytelnik %s ma %d gotowych rezerwacji", r.ID, r.UserID, ready)
+				continue
+			}
+		}
+		return r, nil
+	}
+
+	// Wszyscy oczekujący są na urlopie, mają pełny limit albo pełną półkę odbiorów
+	return nil, nil
+}
+
+// reservationQueue zwraca oczekujące rezerwacje, którym można przydzielić egzemplarz
+// książki bookID, od najstarszej: rezerwacje tej książki, rekordów z tym samym ISBN
+// i - gdy czytelnik nie czeka na konkretne wydanie - innych wydań tego samego utworu
+func (c *Client) reservationQueue(bookID string) ([]*models.Reservation, error) {
 	if bookID == "" {
 		return nil, fmt.Errorf("ID książki nie może być puste")
 	}
 
-	// Pobierz wszystkie rezerwacje dla książki (bez OrderBy aby uniknąć composite index)
-	var pendingReservations []*models.Reservation
+	book, err := c.GetBook(bookID)
+	if err != nil {
+		return nil, err
+	}
 
-	iter := c.Firestore.Collection(ReservationsCollection).
-		Where("book_id", "==", bookID).
-		Documents(c.ctx)
+	// Zapytania bez OrderBy aby uniknąć composite index
+	queue, err := c.pendingReservations(c.collection(ReservationsCollection).Where("book_id", "==", bookID))
+	if err != nil {
+		return nil, err
+	}
+	queued := make(map[string]bool, len(queue))
+	for _, r := range queue {
+		queued[r.ID] = true
+	}
+
+	// Rezerwacje sprzed normalizacji klucza utworu mają klucz bez zwijania znaków diakrytycznych
+	workKey, legacyKey := search.WorkKey(book), legacyWorkKey(book)
+	workKeys := []string{workKey}
+	if legacyKey != workKey {
+		workKeys = append(workKeys, legacyKey)
+	}
+	related := []firestore.Query{c.collection(ReservationsCollection).Where("work_key", "in", workKeys)}
+	if isbnKey := search.ISBNKey(book.ISBN); isbnKey != "" {
+		related = append(related, c.collection(ReservationsCollection).Where("isbn_key", "==", isbnKey))
+	}
+	for _, query := range related {
+		candidates, err := c.pendingReservations(query)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range candidates {
+			if r.WorkKey == legacyKey {
+				r.WorkKey = workKey
+			}
+			if !queued[r.ID] && search.MatchesHold(r, book) {
+				queued[r.ID] = true
+				queue = append(queue, r)
+			}
+		}
+	}
+
+	// Sortuj po created_at (najstarsza pierwsza - FIFO)
+	sort.Slice(queue, func(i, j int) bool {
+		return queue[i].CreatedAt.Before(queue[j].CreatedAt)
+	})
+	return queue, nil
+}
+
+// legacyWorkKey zwraca klucz utworu w zapisie sprzed search.WorkKey (małe litery
+// i pojedyncze spacje, ale ze znakami diakrytycznymi)
+func legacyWorkKey(book *models.Book) string {
+	return strings.ToLower(strings.Join(strings.Fields(book.Author), " ") + "|" + strings.Join(strings.Fields(book.Title), " "))
+}
+
+// SuspendLimitedReservations informuje czytelników z kolejki książki bookID, którzy mają
+// wypożyczone książki do limitu, że zwrócony egzemplarz ich ominął. Rezerwacja dostaje
+// SuspendedAt, więc czytelnik jest powiadamiany raz, a nie przy każdym zwrocie.
+// Sprawdzane są rezerwacje złożone przed before - tylko te mogły dostać egzemplarz
+// (zerowe before oznacza całą kolejkę, np. gdy książka wróciła na półkę).
+func (c *Client) SuspendLimitedReservations(bookID string, before time.Time) error {
+	c, span := c.startSpan("SuspendLimitedReservations")
+	defer span.End()
+
+	queue, err := c.reservationQueue(bookID)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range queue {
+		if !before.IsZero() && !r.CreatedAt.Before(before) {
+			break
+		}
+		if r.SuspendedAt != nil {
+			continue
+		}
+		user, err := c.GetUser(r.UserID)
+		if err != nil || !user.AtLoanLimit() {
+			continue
+		}
+
+		now := time.Now()
+		if _, err := c.collection(ReservationsCollection).Doc(r.ID).Update(c.ctx, []firestore.Update{
+			{Path: "suspended_at", Value: now},
+			{Path: "updated_at", Value: now},
+		}); err != nil {
+			return fmt.Errorf("błąd zapisywania pominiętej rezerwacji: %w", err)
+		}
+		r.SuspendedAt = &now
+		c.publish(events.ReservationSuspended, r)
+	}
+	return nil
+}
+
+// countReadyHolds zwraca liczbę rezerwacji czytelnika gotowych do odbioru
+func (c *Client) countReadyHolds(userID string) (int, error) {
+	reservations, err := c.GetUserActiveReservations(userID)
+	if err != nil {
+		return 0, err
+	}
+	ready := 0
+	for _, r := range reservations {
+		if r.Status == models.ReservationStatusReady {
+			ready++
+		}
+	}
+	return ready, nil
+}
+
+// PromotePostponedHolds przydziela czytelnikowi książki, które ominęły go przez limit
+// gotowych rezerwacji (Settings.MaxReadyHolds) albo limit wypożyczeń, gdy zwolniło się
+// miejsce: oczekująca rezerwacja, której egzemplarz stoi na półce, a czytelnik jest pierwszy
+// w kolejce, staje się gotowa do odbioru. Zwraca liczbę przydzielonych rezerwacji.
+func (c *Client) PromotePostponedHolds(userID string) (int, error) {
+	c, span := c.startSpan("PromotePostponedHolds")
+	defer span.End()
+
+	reservations, err := c.GetUserActiveReservations(userID)
+	if err != nil {
+		return 0, err
+	}
+	sort.Slice(reservations, func(i, j int) bool {
+		return reservations[i].CreatedAt.Before(reservations[j].CreatedAt)
+	})
+
+	promoted := 0
+	for _, r := range reservations {
+		if r.Status != models.ReservationStatusPending {
+			continue
+		}
+		book, err := c.GetBook(r.BookID)
+		if err != nil || !book.IsAvailable() {
+			continue
+		}
+
+		// Limit i kolejność sprawdza GetNextReservation - po osiągnięciu limitu zwraca innego czytelnika
+		next, err := c.GetNextReservation(book.ID)
+		if err != nil {
+			return promoted, err
+		}
+		if next == nil || next.ID != r.ID {
+			continue
+		}
+
+		if err := c.UpdateBookAvailability(book.ID, false); err != nil {
+			// Ktoś wypożyczył ostatni egzemplarz - rezerwacja czeka na zwrot
+			continue
+		}
+		if err := c.MarkReservationReady(r.ID, book.ID); err != nil {
+			return promoted, err
+		}
+		promoted++
+	}
+	return promoted, nil
+}
+
+// promotePostponedHolds wywołuje PromotePostponedHolds po zwolnieniu miejsca na półce
+// odbiorów lub w limicie wypożyczeń - błąd nie cofa odbioru, anulowania ani zwrotu,
+// więc jest tylko zapisywany w logu
+func (c *Client) promotePostponedHolds(userID string) {
+	if n, err := c.PromotePostponedHolds(userID); err != nil {
+		log.Printf("Błąd przydzielania wstrzymanych rezerwacji czytelnika %s: %v", userID, err)
+	} else if n > 0 {
+		log.Printf("Przydzielono %d wstrzymanych rezerwacji czytelnika %s", n, userID)
+	}
+}
+
+// pendingReservations pobiera oczekujące rezerwacje spełniające zapytanie
+func (c *Client) pendingReservations(query firestore.Query) ([]*models.Reservation, error) {
+	var reservations []*models.Reservation
+
+	iter := query.Documents(c.ctx)
 	defer iter.Stop()
 
 	for {
@@ -378,22 +706,9 @@ func (c *Client) GetNextReservation(bookID string) (*models.Reservation, error)
 
 		// Filtruj tylko pending
 		if reservation.Status == models.ReservationStatusPending {
-			pendingReservations = append(pendingReservations, &reservation)
-		}
-	}
-
-	// Jeśli brak pending rezerwacji, zwróć nil
-	if len(pendingReservations) == 0 {
-		return nil, nil
-	}
-
-	// Sortuj po created_at (najstarsza pierwsza - FIFO)
-	var oldest *models.Reservation
-	for _, r := range pendingReservations {
-		if oldest == nil || r.CreatedAt.Before(oldest.CreatedAt) {
-			oldest = r
+			reservations = append(reservations, &reservation)
 		}
 	}
 
-	return oldest, nil
+	return reservations, nil
 }
@@ -0,0 +1,168 @@
+package firebase
+
+import (
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"library-management-system/internal/models"
+)
+
+const (
+	// SavedSearchesCollection to nazwa kolekcji zapisanych wyszukiwań w Firestore
+	SavedSearchesCollection = "saved_searches"
+)
+
+// GetSavedSearch pobiera zapisane wyszukiwanie po ID
+func (c *Client) GetSavedSearch(id string) (*models.SavedSearch, error) {
+	c, span := c.startSpan("GetSavedSearch")
+	defer span.End()
+
+	if id == "" {
+		return nil, fmt.Errorf("ID wyszukiwania nie może być puste")
+	}
+
+	doc, err := c.collection(SavedSearchesCollection).Doc(id).Get(c.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania wyszukiwania: %w", err)
+	}
+
+	var saved models.SavedSearch
+	if err := doc.DataTo(&saved); err != nil {
+		return nil, fmt.Errorf("błąd parsowania wyszukiwania: %w", err)
+	}
+
+	saved.ID = doc.Ref.ID
+	return &saved, nil
+}
+
+// CreateSavedSearch zapisuje wyszukiwanie czytelnika
+func (c *Client) CreateSavedSearch(saved *models.SavedSearch) error {
+	c, span := c.startSpan("CreateSavedSearch")
+	defer span.End()
+
+	if saved == nil {
+		return fmt.Errorf("wyszukiwanie nie może być nil")
+	}
+	if saved.UserID == "" || saved.Query == "" || saved.MatchKey == "" {
+		return fmt.Errorf("ID użytkownika, zapytanie i klucz dopasowania są wymagane")
+	}
+
+	saved.CreatedAt = time.Now()
+
+	docRef := c.collection(SavedSearchesCollection).NewDoc()
+	saved.ID = docRef.ID
+
+	if _, err := docRef.Set(c.ctx, saved); err != nil {
+		return fmt.Errorf("błąd zapisywania wyszukiwania: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSavedSearch usuwa zapisane wyszukiwanie
+func (c *Client) DeleteSavedSearch(id string) error {
+	c, span := c.startSpan("DeleteSavedSearch")
+	defer span.End()
+
+	if id == "" {
+		return fmt.Errorf("ID wyszukiwania nie może być puste")
+	}
+
+	if _, err := c.collection(SavedSearchesCollection).Doc(id).Delete(c.ctx); err != nil {
+		return fmt.Errorf("błąd usuwania wyszukiwania: %w", err)
+	}
+
+	return nil
+}
+
+// MarkSavedSearchNotified zapisuje powiadomienie o książce. Wpisy starsze niż keepSince
+// są usuwane, żeby mapa powiadomionych książek nie rosła bez końca.
+func (c *Client) MarkSavedSearchNotified(saved *models.SavedSearch, bookID string, keepSince time.Time) error {
+	c, span := c.startSpan("MarkSavedSearchNotified")
+	defer span.End()
+
+	now := time.Now()
+
+	notified := map[string]time.Time{bookID: now}
+	for id, at := range saved.NotifiedBooks {
+		if id != bookID && at.After(keepSince) {
+			notified[id] = at
+		}
+	}
+
+	_, err := c.collection(SavedSearchesCollection).Doc(saved.ID).Update(c.ctx, []firestore.Update{
+		{Path: "notified_books", Value: notified},
+		{Path: "last_notified_at", Value: now},
+	})
+	if err != nil {
+		return fmt.Errorf("błąd aktualizacji wyszukiwania: %w", err)
+	}
+
+	saved.NotifiedBooks = notified
+	saved.LastNotifiedAt = &now
+	return nil
+}
+
+// GetUserSavedSearches pobiera zapisane wyszukiwania użytkownika
+func (c *Client) GetUserSavedSearches(userID string) ([]*models.SavedSearch, error) {
+	c, span := c.startSpan("GetUserSavedSearches")
+	defer span.End()
+
+	if userID == "" {
+		return nil, fmt.Errorf("ID użytkownika nie może być puste")
+	}
+
+	return c.listSavedSearches(c.collection(SavedSearchesCollection).Where("user_id", "==", userID))
+}
+
+// GetSavedSearchesByMatchKeys pobiera zapisane wyszukiwania o podanych kluczach dopasowania.
+// Firestore ogranicza zapytanie "in" do 30 wartości, więc klucze są dzielone na partie.
+func (c *Client) GetSavedSearchesByMatchKeys(keys []string) ([]*models.SavedSearch, error) {
+	c, span := c.startSpan("GetSavedSearchesByMatchKeys")
+	defer span.End()
+
+	const batchSize = 30
+
+	var searches []*models.SavedSearch
+	for start := 0; start < len(keys); start += batchSize {
+		end := min(start+batchSize, len(keys))
+
+		batch, err := c.listSavedSearches(c.collection(SavedSearchesCollection).Where("match_key", "in", keys[start:end]))
+		if err != nil {
+			return nil, err
+		}
+		searches = append(searches, batch...)
+	}
+
+	return searches, nil
+}
+
+func (c *Client) listSavedSearches(query firestore.Query) ([]*models.SavedSearch, error) {
+	var searches []*models.SavedSearch
+
+	iter := query.Documents(c.ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("błąd iteracji po wyszukiwaniach: %w", err)
+		}
+
+		var saved models.SavedSearch
+		if err := doc.DataTo(&saved); err != nil {
+			return nil, fmt.Errorf("błąd parsowania wyszukiwania: %w", err)
+		}
+
+		saved.ID = doc.Ref.ID
+		searches = append(searches, &saved)
+	}
+
+	return searches, nil
+}
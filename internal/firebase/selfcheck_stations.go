@@ -0,0 +1,149 @@
+package firebase
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"library-management-system/internal/models"
+)
+
+const (
+	// SelfCheckStationsCollection to nazwa kolekcji stanowisk samoobsługowych w Firestore
+	SelfCheckStationsCollection = "selfcheck_stations"
+)
+
+// CreateSelfCheckStation dodaje stanowisko samoobsługowe i zwraca jego token. Token jest
+// pokazywany tylko raz - w bazie zostaje jego skrót.
+func (c *Client) CreateSelfCheckStation(name string) (*models.SelfCheckStation, string, error) {
+	c, span := c.startSpan("CreateSelfCheckStation")
+	defer span.End()
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", fmt.Errorf("nazwa stanowiska jest wymagana")
+	}
+
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, "", fmt.Errorf("błąd generowania tokenu: %w", err)
+	}
+	token := hex.EncodeToString(raw)
+
+	docRef := c.collection(SelfCheckStationsCollection).NewDoc()
+	station := &models.SelfCheckStation{
+		ID:        docRef.ID,
+		Name:      name,
+		TokenHash: stationTokenHash(token),
+		CreatedAt: time.Now(),
+	}
+	if _, err := docRef.Set(c.ctx, station); err != nil {
+		return nil, "", fmt.Errorf("błąd zapisywania stanowiska: %w", err)
+	}
+
+	return station, token, nil
+}
+
+// GetSelfCheckStationByToken pobiera stanowisko po tokenie.
+// Zwraca nil, jeśli token nie należy do żadnego stanowiska (np. zostało usunięte).
+func (c *Client) GetSelfCheckStationByToken(token string) (*models.SelfCheckStation, error) {
+	c, span := c.startSpan("GetSelfCheckStationByToken")
+	defer span.End()
+
+	if token == "" {
+		return nil, nil
+	}
+
+	iter := c.collection(SelfCheckStationsCollection).Where("token_hash", "==", stationTokenHash(token)).Limit(1).Documents(c.ctx)
+	defer iter.Stop()
+
+	doc, err := iter.Next()
+	if err == iterator.Done {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("błąd wyszukiwania stanowiska po tokenie: %w", err)
+	}
+
+	var station models.SelfCheckStation
+	if err := doc.DataTo(&station); err != nil {
+		return nil, fmt.Errorf("błąd parsowania stanowiska: %w", err)
+	}
+	station.ID = doc.Ref.ID
+
+	return &station, nil
+}
+
+// ListSelfCheckStations pobiera stanowiska samoobsługowe (alfabetycznie)
+func (c *Client) ListSelfCheckStations() ([]*models.SelfCheckStation, error) {
+	c, span := c.startSpan("ListSelfCheckStations")
+	defer span.End()
+
+	var stations []*models.SelfCheckStation
+
+	iter := c.collection(SelfCheckStationsCollection).Documents(c.ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("błąd iteracji po stanowiskach: %w", err)
+		}
+
+		var station models.SelfCheckStation
+		if err := doc.DataTo(&station); err != nil {
+			return nil, fmt.Errorf("błąd parsowania stanowiska: %w", err)
+		}
+
+		station.ID = doc.Ref.ID
+		stations = append(stations, &station)
+	}
+
+	sort.Slice(stations, func(i, j int) bool {
+		return strings.ToLower(stations[i].Name) < strings.ToLower(stations[j].Name)
+	})
+	return stations, nil
+}
+
+// DeleteSelfCheckStation usuwa stanowisko - jego token przestaje działać
+func (c *Client) DeleteSelfCheckStation(id string) error {
+	c, span := c.startSpan("DeleteSelfCheckStation")
+	defer span.End()
+
+	if id == "" {
+		return fmt.Errorf("ID stanowiska nie może być puste")
+	}
+
+	if _, err := c.collection(SelfCheckStationsCollection).Doc(id).Delete(c.ctx); err != nil {
+		return fmt.Errorf("błąd usuwania stanowiska: %w", err)
+	}
+
+	return nil
+}
+
+// touchSelfCheckStation zapisuje chwilę ostatniego wypożyczenia lub zwrotu na stanowisku
+func (c *Client) touchSelfCheckStation(id string, at time.Time) error {
+	_, err := c.collection(SelfCheckStationsCollection).Doc(id).Update(c.ctx, []firestore.Update{
+		{Path: "last_used_at", Value: at},
+	})
+	if err != nil {
+		return fmt.Errorf("błąd zapisywania użycia stanowiska: %w", err)
+	}
+	return nil
+}
+
+// stationTokenHash zwraca skrót tokenu stanowiska zapisywany w bazie
+func stationTokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,180 @@
+package firebase
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"library-management-system/internal/models"
+)
+
+const (
+	// SettingsCollection to nazwa kolekcji ustawień w Firestore
+	SettingsCollection = "settings"
+	// settingsDocID to ID jedynego dokumentu ustawień biblioteki
+	settingsDocID = "library"
+)
+
+// settingsTTL to czas, po którym ustawienia z pamięci są wczytywane ponownie - zmiany
+// zapisane na innej instancji serwera (kreator, ustawienia personelu) docierają w tym czasie
+const settingsTTL = time.Minute
+
+// GetSettings zwraca ustawienia biblioteki (z pamięci, odświeżane co settingsTTL).
+// Gdy dokument ustawień jeszcze nie istnieje, zwraca ustawienia domyślne.
+func (c *Client) GetSettings() (*models.Settings, error) {
+	// Szablony odczytują ustawienia wiele razy na stronę - odczyt z pamięci nie tworzy spanu
+	if cached := c.cache.settings.Load(); cached != nil && time.Since(cached.loadedAt) < settingsTTL {
+		return cached.settings, nil
+	}
+
+	c, span := c.startSpan("GetSettings")
+	defer span.End()
+
+	doc, err := c.collection(SettingsCollection).Doc(settingsDocID).Get(c.ctx)
+	if status.Code(err) == codes.NotFound {
+		settings := models.DefaultSettings()
+		c.cacheSettings(settings)
+		return settings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania ustawień: %w", err)
+	}
+
+	settings := models.DefaultSettings()
+	if err := doc.DataTo(settings); err != nil {
+		return nil, fmt.Errorf("błąd parsowania ustawień: %w", err)
+	}
+
+	c.cacheSettings(settings)
+	return settings, nil
+}
+
+// cacheSettings zapamiętuje ustawienia z chwilą odczytu
+func (c *Client) cacheSettings(settings *models.Settings) {
+	c.cache.settings.Store(&cachedSettings{settings: settings, loadedAt: time.Now()})
+}
+
+// SaveSettings zapisuje ustawienia biblioteki
+func (c *Client) SaveSettings(settings *models.Settings) error {
+	c, span := c.startSpan("SaveSettings")
+	defer span.End()
+
+	if settings.LibraryName == "" {
+		return fmt.Errorf("nazwa biblioteki jest wymagana")
+	}
+	if settings.LoanDays < 1 || settings.MaxLoans < 1 || settings.PickupDays < 1 {
+		return fmt.Errorf("okres wypożyczenia, limit wypożyczeń i czas na odbiór muszą być dodatnie")
+	}
+	if settings.MaxReadyHolds < 0 {
+		return fmt.Errorf("limit gotowych rezerwacji nie może być ujemny")
+	}
+
+	// Ustawienia zapisane przed dodaniem waluty i formatu kodów (oraz kreator /setup) przyjmują wartości domyślne
+	defaults := models.DefaultSettings()
+	if settings.Currency == "" {
+		settings.Currency = defaults.Currency
+	}
+	if settings.Locale == "" {
+		settings.Locale = defaults.Locale
+	}
+	if settings.PickupCodeAlphabet == "" {
+		settings.PickupCodeAlphabet = defaults.PickupCodeAlphabet
+	}
+	if settings.PickupCodeLength == 0 {
+		settings.PickupCodeLength = defaults.PickupCodeLength
+	}
+	if _, ok := models.FindCurrency(settings.Currency); !ok {
+		return fmt.Errorf("nieobsługiwana waluta %s", settings.Currency)
+	}
+	if !models.ValidLocale(settings.Locale) {
+		return fmt.Errorf("nieobsługiwany zapis kwot i dat %s", settings.Locale)
+	}
+	if !models.ValidPickupCodeAlphabet(settings.PickupCodeAlphabet) {
+		return fmt.Errorf("nieobsługiwany rodzaj kodów odbioru %s", settings.PickupCodeAlphabet)
+	}
+	if settings.PickupCodeLength < models.MinPickupCodeLength || settings.PickupCodeLength > models.MaxPickupCodeLength {
+		return fmt.Errorf("długość kodu odbioru musi wynosić od %d do %d znaków", models.MinPickupCodeLength, models.MaxPickupCodeLength)
+	}
+	if len(settings.HomeBlocks) == 0 {
+		settings.HomeBlocks = defaults.HomeBlocks
+	}
+	for _, block := range settings.HomeBlocks {
+		if !models.ValidHomeBlock(block) {
+			return fmt.Errorf("nieznany blok strony głównej %s", block)
+		}
+	}
+	if settings.CatalogPageSize == 0 {
+		settings.CatalogPageSize = defaults.CatalogPageSize
+	}
+	if !models.ValidCatalogPageSize(settings.CatalogPageSize) {
+		return fmt.Errorf("nieobsługiwana liczba książek na stronie katalogu %d", settings.CatalogPageSize)
+	}
+	if settings.LoanRetentionYears != 0 && settings.LoanRetentionYears < models.MinLoanRetentionYears {
+		return fmt.Errorf("okres przechowywania danych w wypożyczeniach musi wynosić co najmniej %d lata", models.MinLoanRetentionYears)
+	}
+	if settings.ReceiptPrinter != "" {
+		if _, _, err := net.SplitHostPort(settings.ReceiptPrinter); err != nil {
+			settings.ReceiptPrinter = net.JoinHostPort(settings.ReceiptPrinter, models.DefaultReceiptPrinterPort)
+		}
+		if _, port, err := net.SplitHostPort(settings.ReceiptPrinter); err != nil || port == "" {
+			return fmt.Errorf("nieprawidłowy adres drukarki paragonów %s", settings.ReceiptPrinter)
+		}
+	}
+	for _, day := range settings.OpenDays {
+		if day < int(time.Sunday) || day > int(time.Saturday) {
+			return fmt.Errorf("nieprawidłowy dzień otwarcia %d", day)
+		}
+	}
+	if len(settings.NavbarLinks) > models.MaxMenuLinks || len(settings.FooterLinks) > models.MaxMenuLinks {
+		return fmt.Errorf("pasek nawigacji i stopka mogą mieć najwyżej po %d odnośników", models.MaxMenuLinks)
+	}
+	for _, links := range [][]models.MenuLink{settings.NavbarLinks, settings.FooterLinks} {
+		for _, link := range links {
+			if err := link.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+
+	settings.UpdatedAt = time.Now()
+
+	_, err := c.collection(SettingsCollection).Doc(settingsDocID).Set(c.ctx, settings)
+	if err != nil {
+		return fmt.Errorf("błąd zapisywania ustawień: %w", err)
+	}
+
+	saved := *settings
+	c.cacheSettings(&saved)
+	return nil
+}
+
+// loanPolicy zwraca aktualne ustawienia lub domyślne, gdy odczyt się nie powiódł
+func (c *Client) loanPolicy() *models.Settings {
+	settings, err := c.GetSettings()
+	if err != nil {
+		return models.DefaultSettings()
+	}
+	return settings
+}
+
+// HasAdmin sprawdza, czy istnieje co najmniej jedno konto administratora
+func (c *Client) HasAdmin() (bool, error) {
+	c, span := c.startSpan("HasAdmin")
+	defer span.End()
+
+	iter := c.collection(UsersCollection).Where("role", "==", string(models.RoleAdmin)).Limit(1).Documents(c.ctx)
+	defer iter.Stop()
+
+	_, err := iter.Next()
+	if err == iterator.Done {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("błąd sprawdzania kont administratorów: %w", err)
+	}
+	return true, nil
+}
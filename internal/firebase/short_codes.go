@@ -0,0 +1,106 @@
+package firebase
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"library-management-system/internal/events"
+	"library-management-system/internal/models"
+)
+
+// shortCodeAttempts to liczba losowań krótkiego kodu, zanim nadanie kodu zgłosi błąd
+const shortCodeAttempts = 5
+
+// GetBookByShortCode pobiera książkę po krótkim kodzie permalinku
+func (c *Client) GetBookByShortCode(code string) (*models.Book, error) {
+	c, span := c.startSpan("GetBookByShortCode")
+	defer span.End()
+
+	if code == "" {
+		return nil, fmt.Errorf("kod nie może być pusty")
+	}
+
+	iter := c.collection(BooksCollection).Where("short_code", "==", code).Limit(1).Documents(c.ctx)
+	defer iter.Stop()
+
+	doc, err := iter.Next()
+	if err == iterator.Done {
+		return nil, nil // Nie znaleziono książki
+	}
+	if err != nil {
+		return nil, fmt.Errorf("błąd wyszukiwania książki po kodzie: %w", err)
+	}
+
+	var book models.Book
+	if err := doc.DataTo(&book); err != nil {
+		return nil, fmt.Errorf("błąd parsowania książki: %w", err)
+	}
+
+	book.ID = doc.Ref.ID
+	return &book, nil
+}
+
+// FindBookByCode szuka książki po zeskanowanym kodzie z etykiety, a następnie po ISBN.
+// Przyjmuje także cały adres z kodu QR. Zwraca nil, gdy żadna książka nie pasuje.
+func (c *Client) FindBookByCode(code string) (*models.Book, error) {
+	code = path.Base(strings.TrimSpace(code))
+	if code == "" || code == "." {
+		return nil, nil
+	}
+
+	book, err := c.GetBookByShortCode(strings.ToLower(code))
+	if err != nil || book != nil {
+		return book, err
+	}
+	return c.GetBookByISBN(code)
+}
+
+// EnsureBookShortCode nadaje krótki kod książce dodanej przed wprowadzeniem permalinków
+func (c *Client) EnsureBookShortCode(book *models.Book) error {
+	c, span := c.startSpan("EnsureBookShortCode")
+	defer span.End()
+
+	if book.ShortCode != "" {
+		return nil
+	}
+
+	code, err := c.newShortCode()
+	if err != nil {
+		return err
+	}
+
+	_, err = c.collection(BooksCollection).Doc(book.ID).Update(c.ctx, []firestore.Update{
+		{Path: "short_code", Value: code},
+	})
+	if err != nil {
+		return fmt.Errorf("błąd zapisywania kodu książki: %w", err)
+	}
+
+	book.ShortCode = code
+	c.publish(events.BookChanged, book.ID)
+	return nil
+}
+
+// newShortCode losuje kod, który nie jest jeszcze przypisany do żadnej książki
+func (c *Client) newShortCode() (string, error) {
+	for i := 0; i < shortCodeAttempts; i++ {
+		code, err := models.NewShortCode()
+		if err != nil {
+			return "", err
+		}
+
+		existing, err := c.GetBookByShortCode(code)
+		if err != nil {
+			return "", err
+		}
+		if existing == nil {
+			return code, nil
+		}
+	}
+
+	return "", fmt.Errorf("nie udało się wygenerować unikalnego kodu książki")
+}
@@ -0,0 +1,62 @@
+package firebase
+
+import (
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+
+	"library-management-system/internal/models"
+)
+
+const (
+	// StaffActivityCollection to nazwa kolekcji dziennych liczników czynności personelu w Firestore
+	StaffActivityCollection = "staff_activity_daily"
+)
+
+// IncrementStaffActivity zwiększa dzienny licznik czynności pracownika (tworzy dokument, jeśli nie istnieje)
+func (c *Client) IncrementStaffActivity(day string, staff *models.User, action models.StaffAction) error {
+	c, span := c.startSpan("IncrementStaffActivity")
+	defer span.End()
+
+	if day == "" || staff == nil || staff.ID == "" {
+		return fmt.Errorf("nieprawidłowy licznik czynności personelu")
+	}
+
+	docID := day + "_" + staff.ID + "_" + string(action)
+	_, err := c.collection(StaffActivityCollection).Doc(docID).Set(c.ctx, map[string]interface{}{
+		"day":        day,
+		"staff_id":   staff.ID,
+		"staff_name": staff.FullName(),
+		"action":     string(action),
+		"count":      firestore.Increment(1),
+	}, firestore.MergeAll)
+	if err != nil {
+		return fmt.Errorf("błąd zapisywania czynności personelu: %w", err)
+	}
+
+	return nil
+}
+
+// GetStaffActivity pobiera liczniki czynności personelu z dni [fromDay, toDay] (RRRR-MM-DD)
+func (c *Client) GetStaffActivity(fromDay, toDay string) ([]*models.StaffActivityCounter, error) {
+	c, span := c.startSpan("GetStaffActivity")
+	defer span.End()
+
+	docs, err := c.collection(StaffActivityCollection).
+		Where("day", ">=", fromDay).
+		Where("day", "<=", toDay).
+		Documents(c.ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania czynności personelu: %w", err)
+	}
+
+	counters := make([]*models.StaffActivityCounter, 0, len(docs))
+	for _, doc := range docs {
+		var counter models.StaffActivityCounter
+		if err := doc.DataTo(&counter); err != nil {
+			return nil, fmt.Errorf("błąd parsowania czynności personelu: %w", err)
+		}
+		counters = append(counters, &counter)
+	}
+	return counters, nil
+}
@@ -0,0 +1,49 @@
+package firebase
+
+import (
+	"context"
+
+	"library-management-system/internal/repository"
+)
+
+// Klient implementuje wszystkie repozytoria
+var (
+	_ repository.BookRepository        = (*Client)(nil)
+	_ repository.LoanRepository        = (*Client)(nil)
+	_ repository.UserRepository        = (*Client)(nil)
+	_ repository.ReservationRepository = (*Client)(nil)
+	_ repository.ReadingListRepository = (*Client)(nil)
+)
+
+// store udostępnia klienta Firestore przez interfejsy repozytoriów
+type store struct {
+	client *Client
+}
+
+// NewStore zwraca repozytoria oparte na Firestore (nil, gdy klient nie jest zainicjalizowany)
+func NewStore(c *Client) repository.Store {
+	if c == nil {
+		return nil
+	}
+	return store{client: c}
+}
+
+func (s store) Books(ctx context.Context) repository.BookRepository {
+	return s.client.Traced(ctx)
+}
+
+func (s store) Loans(ctx context.Context) repository.LoanRepository {
+	return s.client.Traced(ctx)
+}
+
+func (s store) Users(ctx context.Context) repository.UserRepository {
+	return s.client.Traced(ctx)
+}
+
+func (s store) Reservations(ctx context.Context) repository.ReservationRepository {
+	return s.client.Traced(ctx)
+}
+
+func (s store) ReadingLists(ctx context.Context) repository.ReadingListRepository {
+	return s.client.Traced(ctx)
+}
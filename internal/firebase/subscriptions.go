@@ -0,0 +1,132 @@
+package firebase
+
+import (
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"library-management-system/internal/models"
+)
+
+const (
+	// SubscriptionsCollection to nazwa kolekcji subskrypcji nowych tytułów w Firestore
+	SubscriptionsCollection = "subscriptions"
+)
+
+// CreateSubscription zapisuje subskrypcję nowych tytułów
+func (c *Client) CreateSubscription(sub *models.Subscription) error {
+	c, span := c.startSpan("CreateSubscription")
+	defer span.End()
+
+	if sub == nil {
+		return fmt.Errorf("subskrypcja nie może być nil")
+	}
+	if sub.UserID == "" || sub.Value == "" {
+		return fmt.Errorf("ID użytkownika i wartość subskrypcji są wymagane")
+	}
+	if sub.Type != models.SubscriptionAuthor && sub.Type != models.SubscriptionCategory {
+		return fmt.Errorf("nieprawidłowy typ subskrypcji: %s", sub.Type)
+	}
+
+	sub.Key = models.SubscriptionKey(sub.Type, sub.Value)
+	sub.CreatedAt = time.Now()
+
+	docRef := c.collection(SubscriptionsCollection).NewDoc()
+	sub.ID = docRef.ID
+
+	if _, err := docRef.Set(c.ctx, sub); err != nil {
+		return fmt.Errorf("błąd zapisywania subskrypcji: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSubscription usuwa subskrypcję
+func (c *Client) DeleteSubscription(id string) error {
+	c, span := c.startSpan("DeleteSubscription")
+	defer span.End()
+
+	if id == "" {
+		return fmt.Errorf("ID subskrypcji nie może być puste")
+	}
+
+	if _, err := c.collection(SubscriptionsCollection).Doc(id).Delete(c.ctx); err != nil {
+		return fmt.Errorf("błąd usuwania subskrypcji: %w", err)
+	}
+
+	return nil
+}
+
+// FindUserSubscription zwraca subskrypcję użytkownika o podanym kluczu lub nil, jeśli jej nie ma
+func (c *Client) FindUserSubscription(userID, key string) (*models.Subscription, error) {
+	c, span := c.startSpan("FindUserSubscription")
+	defer span.End()
+
+	subs, err := c.listSubscriptions(c.collection(SubscriptionsCollection).
+		Where("user_id", "==", userID).
+		Where("key", "==", key).
+		Limit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(subs) == 0 {
+		return nil, nil
+	}
+	return subs[0], nil
+}
+
+// GetUserSubscriptions pobiera subskrypcje użytkownika
+func (c *Client) GetUserSubscriptions(userID string) ([]*models.Subscription, error) {
+	c, span := c.startSpan("GetUserSubscriptions")
+	defer span.End()
+
+	if userID == "" {
+		return nil, fmt.Errorf("ID użytkownika nie może być puste")
+	}
+
+	return c.listSubscriptions(c.collection(SubscriptionsCollection).Where("user_id", "==", userID))
+}
+
+// GetSubscriptionsByKeys pobiera subskrypcje o podanych kluczach (maks. 30 - limit zapytania "in")
+func (c *Client) GetSubscriptionsByKeys(keys []string) ([]*models.Subscription, error) {
+	c, span := c.startSpan("GetSubscriptionsByKeys")
+	defer span.End()
+
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	if len(keys) > 30 {
+		return nil, fmt.Errorf("zbyt wiele kluczy subskrypcji: %d", len(keys))
+	}
+
+	return c.listSubscriptions(c.collection(SubscriptionsCollection).Where("key", "in", keys))
+}
+
+func (c *Client) listSubscriptions(query firestore.Query) ([]*models.Subscription, error) {
+	var subs []*models.Subscription
+
+	iter := query.Documents(c.ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("błąd iteracji po subskrypcjach: %w", err)
+		}
+
+		var sub models.Subscription
+		if err := doc.DataTo(&sub); err != nil {
+			return nil, fmt.Errorf("błąd parsowania subskrypcji: %w", err)
+		}
+
+		sub.ID = doc.Ref.ID
+		subs = append(subs, &sub)
+	}
+
+	return subs, nil
+}
@@ -0,0 +1,183 @@
+package firebase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"library-management-system/internal/models"
+)
+
+const (
+	// TelegramLinksCollection to nazwa kolekcji czatów z botem Telegrama połączonych z kontami.
+	// Bot jest jeden dla całej sieci, więc kolekcja leży zawsze w katalogu głównym bazy.
+	TelegramLinksCollection = "telegram_links"
+	// TelegramLinkCodesCollection to nazwa kolekcji jednorazowych kodów łączenia konta z czatem
+	// (także w katalogu głównym bazy)
+	TelegramLinkCodesCollection = "telegram_link_codes"
+)
+
+// telegramCodeTTL to czas ważności kodu łączenia konta z botem
+const telegramCodeTTL = 15 * time.Minute
+
+// telegramLinkCode to jednorazowy kod przekazywany botowi w linku t.me/<bot>?start=<kod>
+type telegramLinkCode struct {
+	Tenant    string    `firestore:"tenant"`
+	UserID    string    `firestore:"user_id"`
+	ExpiresAt time.Time `firestore:"expires_at"`
+}
+
+// CreateTelegramLinkCode tworzy jednorazowy kod, którym czytelnik łączy konto z botem Telegrama
+func (c *Client) CreateTelegramLinkCode(userID string) (string, error) {
+	c, span := c.startSpan("CreateTelegramLinkCode")
+	defer span.End()
+
+	if userID == "" {
+		return "", fmt.Errorf("ID użytkownika nie może być puste")
+	}
+
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("błąd generowania kodu: %w", err)
+	}
+	code := hex.EncodeToString(raw)
+
+	_, err := c.Firestore.Collection(TelegramLinkCodesCollection).Doc(code).Set(c.ctx, telegramLinkCode{
+		Tenant:    c.tenant,
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(telegramCodeTTL),
+	})
+	if err != nil {
+		return "", fmt.Errorf("błąd zapisywania kodu Telegrama: %w", err)
+	}
+
+	return code, nil
+}
+
+// LinkTelegramChat realizuje kod łączenia: zapisuje czat przy koncie czytelnika (zastępując
+// jego poprzedni czat) i usuwa kod. Wywoływane przez bota na kliencie biblioteki głównej.
+func (c *Client) LinkTelegramChat(code string, chatID int64) (*models.TelegramLink, error) {
+	c, span := c.startSpan("LinkTelegramChat")
+	defer span.End()
+
+	codeRef := c.Firestore.Collection(TelegramLinkCodesCollection).Doc(code)
+	var link *models.TelegramLink
+
+	err := c.Firestore.RunTransaction(c.ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(codeRef)
+		if status.Code(err) == codes.NotFound {
+			return fmt.Errorf("kod jest nieprawidłowy lub został już użyty")
+		}
+		if err != nil {
+			return fmt.Errorf("błąd pobierania kodu Telegrama: %w", err)
+		}
+		var pending telegramLinkCode
+		if err := doc.DataTo(&pending); err != nil {
+			return fmt.Errorf("błąd parsowania kodu Telegrama: %w", err)
+		}
+		if time.Now().After(pending.ExpiresAt) {
+			return fmt.Errorf("kod wygasł - wygeneruj nowy na stronie konta")
+		}
+
+		userRef := c.library(pending.Tenant).collection(UsersCollection).Doc(pending.UserID)
+		userDoc, err := tx.Get(userRef)
+		if err != nil {
+			return fmt.Errorf("nie znaleziono czytelnika: %w", err)
+		}
+		var user models.User
+		if err := userDoc.DataTo(&user); err != nil {
+			return fmt.Errorf("błąd parsowania czytelnika: %w", err)
+		}
+
+		link = &models.TelegramLink{ChatID: chatID, Tenant: pending.Tenant, UserID: pending.UserID, CreatedAt: time.Now()}
+		if user.TelegramChatID != 0 && user.TelegramChatID != chatID {
+			if err := tx.Delete(c.telegramLinkRef(user.TelegramChatID)); err != nil {
+				return err
+			}
+		}
+		if err := tx.Set(c.telegramLinkRef(chatID), link); err != nil {
+			return err
+		}
+		if err := tx.Update(userRef, []firestore.Update{
+			{Path: "telegram_chat_id", Value: chatID},
+			{Path: "updated_at", Value: time.Now()},
+		}); err != nil {
+			return err
+		}
+		return tx.Delete(codeRef)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return link, nil
+}
+
+// GetTelegramLink zwraca konto połączone z czatem lub nil, jeśli czat nie jest połączony
+func (c *Client) GetTelegramLink(chatID int64) (*models.TelegramLink, error) {
+	c, span := c.startSpan("GetTelegramLink")
+	defer span.End()
+
+	doc, err := c.telegramLinkRef(chatID).Get(c.ctx)
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania połączenia z Telegramem: %w", err)
+	}
+
+	var link models.TelegramLink
+	if err := doc.DataTo(&link); err != nil {
+		return nil, fmt.Errorf("błąd parsowania połączenia z Telegramem: %w", err)
+	}
+	return &link, nil
+}
+
+// UnlinkTelegram odłącza czat Telegrama od konta czytelnika
+func (c *Client) UnlinkTelegram(userID string) error {
+	c, span := c.startSpan("UnlinkTelegram")
+	defer span.End()
+
+	user, err := c.GetUser(userID)
+	if err != nil {
+		return err
+	}
+	if user.TelegramChatID == 0 {
+		return nil
+	}
+
+	err = c.Firestore.RunTransaction(c.ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if err := tx.Delete(c.telegramLinkRef(user.TelegramChatID)); err != nil {
+			return err
+		}
+		return tx.Update(c.collection(UsersCollection).Doc(userID), []firestore.Update{
+			{Path: "telegram_chat_id", Value: firestore.Delete},
+			{Path: "updated_at", Value: time.Now()},
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("błąd odłączania Telegrama: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) telegramLinkRef(chatID int64) *firestore.DocumentRef {
+	return c.Firestore.Collection(TelegramLinksCollection).Doc(strconv.FormatInt(chatID, 10))
+}
+
+// library zwraca klienta biblioteki sieci o podanym ID (pusty = biblioteka główna).
+// Wywoływane na kliencie biblioteki głównej.
+func (c *Client) library(tenant string) *Client {
+	if tenant == "" {
+		return c
+	}
+	return c.ForTenant(tenant)
+}
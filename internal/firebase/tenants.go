@@ -0,0 +1,81 @@
+package firebase
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/api/iterator"
+
+	"library-management-system/internal/models"
+)
+
+const (
+	// TenantsCollection to nazwa kolekcji bibliotek sieci w Firestore (zawsze w katalogu głównym bazy)
+	TenantsCollection = "tenants"
+)
+
+// tenantIDPattern ogranicza ID biblioteki do znaków bezpiecznych w ścieżkach Firestore
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,39}$`)
+
+// ListTenants pobiera wszystkie biblioteki sieci posortowane po nazwie
+func (c *Client) ListTenants() ([]*models.Tenant, error) {
+	c, span := c.startSpan("ListTenants")
+	defer span.End()
+
+	iter := c.Firestore.Collection(TenantsCollection).Documents(c.ctx)
+	defer iter.Stop()
+
+	var tenants []*models.Tenant
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("błąd pobierania bibliotek sieci: %w", err)
+		}
+
+		var tenant models.Tenant
+		if err := doc.DataTo(&tenant); err != nil {
+			return nil, fmt.Errorf("błąd parsowania biblioteki sieci: %w", err)
+		}
+		tenant.ID = doc.Ref.ID
+		tenants = append(tenants, &tenant)
+	}
+
+	sort.Slice(tenants, func(i, j int) bool {
+		return tenants[i].Name < tenants[j].Name
+	})
+	return tenants, nil
+}
+
+// SaveTenant tworzy lub aktualizuje bibliotekę sieci
+func (c *Client) SaveTenant(tenant *models.Tenant) error {
+	c, span := c.startSpan("SaveTenant")
+	defer span.End()
+
+	if !tenantIDPattern.MatchString(tenant.ID) {
+		return fmt.Errorf("identyfikator może zawierać tylko małe litery, cyfry i myślniki (2-40 znaków)")
+	}
+	if strings.TrimSpace(tenant.Name) == "" {
+		return fmt.Errorf("nazwa biblioteki jest wymagana")
+	}
+	if len(tenant.Hostnames) == 0 {
+		return fmt.Errorf("podaj co najmniej jedną domenę")
+	}
+
+	now := time.Now()
+	if tenant.CreatedAt.IsZero() {
+		tenant.CreatedAt = now
+	}
+	tenant.UpdatedAt = now
+
+	_, err := c.Firestore.Collection(TenantsCollection).Doc(tenant.ID).Set(c.ctx, tenant)
+	if err != nil {
+		return fmt.Errorf("błąd zapisywania biblioteki sieci: %w", err)
+	}
+	return nil
+}
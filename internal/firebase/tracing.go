@@ -0,0 +1,42 @@
+package firebase
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer tworzy spany operacji klienta (eksport włącza internal/tracing)
+var tracer = otel.Tracer("library-management-system/firebase")
+
+// Traced zwraca klienta, którego operacje są zapisywane jako spany potomne spanu
+// z ctx (zwykle spanu żądania HTTP) i doliczane do licznika Usage z ctx (patrz WithUsage).
+// Anulowanie ctx nie przerywa operacji - zapis rozpoczęty przed zerwaniem połączenia
+// przez przeglądarkę kończy się normalnie.
+func (c *Client) Traced(ctx context.Context) *Client {
+	if c == nil {
+		return nil
+	}
+	traced := *c
+	traced.ctx = trace.ContextWithSpan(c.ctx, trace.SpanFromContext(ctx))
+	if u := usageFromContext(ctx); u != nil {
+		traced.ctx = context.WithValue(traced.ctx, usageKey{}, u)
+	}
+	return &traced
+}
+
+// startSpan rozpoczyna span operacji i zwraca kopię klienta, której zapytania
+// (także w wywołanych metodach klienta) należą do tego spanu
+func (c *Client) startSpan(operation string) (*Client, trace.Span) {
+	ctx, span := tracer.Start(c.ctx, "firebase."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "firestore"),
+			attribute.String("library.tenant", c.tenant),
+		))
+	traced := *c
+	traced.ctx = ctx
+	return &traced, span
+}
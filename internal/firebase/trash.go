@@ -0,0 +1,256 @@
+package firebase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"firebase.google.com/go/v4/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"library-management-system/internal/models"
+)
+
+const (
+	// TrashCollection to nazwa kolekcji kosza (ID dokumentu = rodzaj_ID obiektu)
+	TrashCollection = "trash"
+)
+
+// trashSources mapuje rodzaj obiektu w koszu na kolekcję, z której pochodzi
+var trashSources = map[models.TrashKind]string{
+	models.TrashKindUser: UsersCollection,
+	models.TrashKindLoan: LoansCollection,
+}
+
+// TrashUser przenosi konto czytelnika do kosza i blokuje logowanie do konta Firebase Auth.
+// Konto z wypożyczeniami, karami lub rezerwacjami trzeba najpierw rozliczyć. Wypożyczenia
+// czytelnika zostają w historii z imieniem i nazwiskiem zapisanym w chwili wypożyczenia.
+func (c *Client) TrashUser(userID, deletedBy string) error {
+	c, span := c.startSpan("TrashUser")
+	defer span.End()
+
+	user, err := c.GetUser(userID)
+	if err != nil {
+		return err
+	}
+	if user.IsStaff() {
+		return fmt.Errorf("nie można usunąć konta personelu")
+	}
+	reservations, err := c.GetUserActiveReservations(userID)
+	if err != nil {
+		return err
+	}
+	if len(reservations) > 0 {
+		return fmt.Errorf("czytelnik ma aktywne rezerwacje (%d) - anuluj je przed usunięciem konta", len(reservations))
+	}
+
+	label := user.FullName() + " (" + user.Email + ")"
+	err = c.moveToTrash(models.TrashKindUser, userID, label, deletedBy, func(doc *firestore.DocumentSnapshot) error {
+		var current models.User
+		if err := doc.DataTo(&current); err != nil {
+			return fmt.Errorf("błąd parsowania danych użytkownika: %w", err)
+		}
+		if current.CurrentLoans > 0 {
+			return fmt.Errorf("czytelnik ma wypożyczone książki (%d) - przyjmij zwroty przed usunięciem konta", current.CurrentLoans)
+		}
+		if current.TotalFines > 0 {
+			return fmt.Errorf("czytelnik ma nieopłacone kary - przyjmij wpłatę lub umórz je przed usunięciem konta")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if user.FirebaseUID != "" {
+		if _, err := c.Auth.UpdateUser(c.ctx, user.FirebaseUID, (&auth.UserToUpdate{}).Disabled(true)); err != nil && !auth.IsUserNotFound(err) {
+			return fmt.Errorf("konto przeniesiono do kosza, ale nie zablokowano logowania: %w", err)
+		}
+	}
+	return nil
+}
+
+// TrashLoan przenosi zwrócone wypożyczenie do kosza (np. wpisane omyłkowo). Wypożyczeń
+// w toku i z nieumorzoną karą nie można usunąć - zmieniłoby to dostępność książki
+// i saldo czytelnika.
+func (c *Client) TrashLoan(loanID, deletedBy string) error {
+	c, span := c.startSpan("TrashLoan")
+	defer span.End()
+
+	loan, err := c.GetLoan(loanID)
+	if err != nil {
+		return err
+	}
+
+	label := loan.BookTitle + " - " + loan.ReaderName() + ", " + loan.LoanDate.Format("2006-01-02")
+	return c.moveToTrash(models.TrashKindLoan, loanID, label, deletedBy, func(doc *firestore.DocumentSnapshot) error {
+		var current models.Loan
+		if err := doc.DataTo(&current); err != nil {
+			return fmt.Errorf("błąd parsowania wypożyczenia: %w", err)
+		}
+		if current.Status != models.LoanStatusReturned {
+			return fmt.Errorf("można usunąć tylko zwrócone wypożyczenie")
+		}
+		if current.FineAmount > 0 && !current.FineWaived {
+			return fmt.Errorf("wypożyczenie ma nieumorzoną karę - umórz ją przed usunięciem")
+		}
+		return nil
+	})
+}
+
+// moveToTrash w jednej transakcji zapisuje kopię dokumentu w koszu i usuwa oryginał.
+// check sprawdza aktualną treść dokumentu, czy można go usunąć.
+func (c *Client) moveToTrash(kind models.TrashKind, id, label, deletedBy string, check func(*firestore.DocumentSnapshot) error) error {
+	sourceRef := c.collection(trashSources[kind]).Doc(id)
+	trashRef := c.collection(TrashCollection).Doc(string(kind) + "_" + id)
+
+	return c.Firestore.RunTransaction(c.ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(sourceRef)
+		if err != nil {
+			return fmt.Errorf("błąd pobierania obiektu do usunięcia: %w", err)
+		}
+		if err := check(doc); err != nil {
+			return err
+		}
+
+		if err := tx.Set(trashRef, &models.TrashItem{
+			Kind:      kind,
+			EntityID:  id,
+			Label:     label,
+			Data:      doc.Data(),
+			DeletedAt: time.Now(),
+			DeletedBy: deletedBy,
+		}); err != nil {
+			return err
+		}
+		return tx.Delete(sourceRef)
+	})
+}
+
+// ListTrash pobiera obiekty w koszu (ostatnio usunięte pierwsze)
+func (c *Client) ListTrash() ([]*models.TrashItem, error) {
+	c, span := c.startSpan("ListTrash")
+	defer span.End()
+
+	docs, err := c.collection(TrashCollection).OrderBy("deleted_at", firestore.Desc).Documents(c.ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania kosza: %w", err)
+	}
+
+	items := make([]*models.TrashItem, 0, len(docs))
+	for _, doc := range docs {
+		item, err := trashItemFromDoc(doc)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// RestoreTrashItem przywraca obiekt z kosza pod jego dawnym ID, a przywróconemu kontu
+// odblokowuje logowanie
+func (c *Client) RestoreTrashItem(id string) (*models.TrashItem, error) {
+	c, span := c.startSpan("RestoreTrashItem")
+	defer span.End()
+
+	trashRef := c.collection(TrashCollection).Doc(id)
+
+	var item *models.TrashItem
+	err := c.Firestore.RunTransaction(c.ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(trashRef)
+		if status.Code(err) == codes.NotFound {
+			return fmt.Errorf("obiektu nie ma w koszu")
+		}
+		if err != nil {
+			return err
+		}
+		if item, err = trashItemFromDoc(doc); err != nil {
+			return err
+		}
+
+		collection, ok := trashSources[item.Kind]
+		if !ok {
+			return fmt.Errorf("nieznany rodzaj obiektu %s", item.Kind)
+		}
+		if err := tx.Create(c.collection(collection).Doc(item.EntityID), item.Data); err != nil {
+			return err
+		}
+		return tx.Delete(trashRef)
+	})
+	if status.Code(err) == codes.AlreadyExists {
+		return nil, fmt.Errorf("obiekt o tym ID już istnieje - nie można go przywrócić")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("błąd przywracania z kosza: %w", err)
+	}
+
+	if uid, _ := item.Data["firebase_uid"].(string); item.Kind == models.TrashKindUser && uid != "" {
+		if _, err := c.Auth.UpdateUser(c.ctx, uid, (&auth.UserToUpdate{}).Disabled(false)); err != nil {
+			return item, fmt.Errorf("konto przywrócono, ale nie odblokowano logowania: %w", err)
+		}
+	}
+	return item, nil
+}
+
+// PurgeTrashItem usuwa obiekt z kosza na stałe, a usuniętemu kontu także konto Firebase Auth
+func (c *Client) PurgeTrashItem(id string) (*models.TrashItem, error) {
+	c, span := c.startSpan("PurgeTrashItem")
+	defer span.End()
+
+	doc, err := c.collection(TrashCollection).Doc(id).Get(c.ctx)
+	if status.Code(err) == codes.NotFound {
+		return nil, fmt.Errorf("obiektu nie ma w koszu")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania obiektu z kosza: %w", err)
+	}
+	item, err := trashItemFromDoc(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Najpierw konto Auth - jego UID jest zapisany tylko w kopii profilu
+	if uid, _ := item.Data["firebase_uid"].(string); item.Kind == models.TrashKindUser && uid != "" {
+		if err := c.Auth.DeleteUser(c.ctx, uid); err != nil && !auth.IsUserNotFound(err) {
+			return nil, fmt.Errorf("błąd usuwania konta Firebase Auth: %w", err)
+		}
+	}
+	if _, err := doc.Ref.Delete(c.ctx); err != nil {
+		return nil, fmt.Errorf("błąd usuwania obiektu z kosza: %w", err)
+	}
+	return item, nil
+}
+
+// PurgeExpiredTrash usuwa na stałe obiekty, które leżą w koszu dłużej niż
+// models.TrashRetentionDays. Zwraca liczbę usuniętych obiektów.
+func (c *Client) PurgeExpiredTrash(now time.Time) (int, error) {
+	c, span := c.startSpan("PurgeExpiredTrash")
+	defer span.End()
+
+	cutoff := now.AddDate(0, 0, -models.TrashRetentionDays)
+	docs, err := c.collection(TrashCollection).Where("deleted_at", "<", cutoff).Documents(c.ctx).GetAll()
+	if err != nil {
+		return 0, fmt.Errorf("błąd pobierania kosza: %w", err)
+	}
+
+	purged := 0
+	for _, doc := range docs {
+		if _, err := c.PurgeTrashItem(doc.Ref.ID); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+func trashItemFromDoc(doc *firestore.DocumentSnapshot) (*models.TrashItem, error) {
+	var item models.TrashItem
+	if err := doc.DataTo(&item); err != nil {
+		return nil, fmt.Errorf("błąd parsowania obiektu z kosza: %w", err)
+	}
+	item.ID = doc.Ref.ID
+	return &item, nil
+}
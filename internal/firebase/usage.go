@@ -0,0 +1,195 @@
+package firebase
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"cloud.google.com/go/firestore/apiv1/firestorepb"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+)
+
+// Usage zlicza odczyty i zapisy dokumentów Firestore w jednym żądaniu HTTP.
+// Liczone są dokumenty zwrócone przez zapytania i pobrania (także nieistniejące,
+// za które Firestore też pobiera opłatę) oraz zapisy w commitach.
+type Usage struct {
+	reads  atomic.Int64
+	writes atomic.Int64
+}
+
+// Reads zwraca liczbę odczytanych dokumentów
+func (u *Usage) Reads() int64 {
+	return u.reads.Load()
+}
+
+// Writes zwraca liczbę zapisanych dokumentów
+func (u *Usage) Writes() int64 {
+	return u.writes.Load()
+}
+
+type usageKey struct{}
+
+// WithUsage dołącza do kontekstu licznik operacji. Zliczane są operacje klienta
+// zwróconego przez Traced z tym kontekstem.
+func WithUsage(ctx context.Context) (context.Context, *Usage) {
+	u := &Usage{}
+	return context.WithValue(ctx, usageKey{}, u), u
+}
+
+func usageFromContext(ctx context.Context) *Usage {
+	u, _ := ctx.Value(usageKey{}).(*Usage)
+	return u
+}
+
+// dailyUsage to odczyty i zapisy całego projektu Firestore (wszystkich bibliotek sieci)
+// od północy czasu serwera, porównywane z dziennym budżetem odczytów
+type dailyUsage struct {
+	mu     sync.Mutex
+	day    string
+	reads  int64
+	writes int64
+	budget int64 // 0 = bez budżetu
+	warned int   // Najwyższy zgłoszony już próg budżetu w procentach
+}
+
+// budgetThresholds to progi dziennego budżetu odczytów (w procentach), przy których logowane jest ostrzeżenie
+var budgetThresholds = []int{80, 100}
+
+var daily = &dailyUsage{}
+
+// readBudgetFromEnv wczytuje dzienny budżet odczytów z FIRESTORE_DAILY_READ_BUDGET
+func readBudgetFromEnv() int64 {
+	value := os.Getenv("FIRESTORE_DAILY_READ_BUDGET")
+	if value == "" {
+		return 0
+	}
+	budget, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || budget < 0 {
+		log.Printf("UWAGA: nieprawidłowy FIRESTORE_DAILY_READ_BUDGET %q - budżet odczytów wyłączony", value)
+		return 0
+	}
+	return budget
+}
+
+func (d *dailyUsage) setBudget(budget int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.budget = budget
+}
+
+// DailyUsage zwraca odczyty i zapisy dokumentów od północy oraz dzienny budżet odczytów
+func DailyUsage() (reads, writes, budget int64) {
+	daily.mu.Lock()
+	defer daily.mu.Unlock()
+	daily.rollover(time.Now())
+	return daily.reads, daily.writes, daily.budget
+}
+
+// rollover zeruje liczniki po zmianie dnia (wywoływane z zablokowanym mu)
+func (d *dailyUsage) rollover(now time.Time) {
+	day := now.Format("2006-01-02")
+	if d.day == day {
+		return
+	}
+	if d.day != "" {
+		log.Printf("Firestore %s: %d odczytów, %d zapisów dokumentów", d.day, d.reads, d.writes)
+	}
+	d.day, d.reads, d.writes, d.warned = day, 0, 0, 0
+}
+
+func (d *dailyUsage) add(reads, writes int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.rollover(time.Now())
+	d.reads += reads
+	d.writes += writes
+
+	if d.budget == 0 {
+		return
+	}
+	for _, threshold := range budgetThresholds {
+		if threshold > d.warned && d.reads*100 >= d.budget*int64(threshold) {
+			d.warned = threshold
+			log.Printf("UWAGA: odczyty Firestore osiągnęły %d%% dziennego budżetu (%d z %d)", threshold, d.reads, d.budget)
+		}
+	}
+}
+
+// record dolicza operacje do licznika żądania (jeśli jest w kontekście) i do licznika dziennego
+func record(ctx context.Context, reads, writes int64) {
+	if reads == 0 && writes == 0 {
+		return
+	}
+	if u := usageFromContext(ctx); u != nil {
+		u.reads.Add(reads)
+		u.writes.Add(writes)
+	}
+	daily.add(reads, writes)
+}
+
+// usageOptions dołącza do połączenia z Firestore interceptory zliczające dokumenty
+func usageOptions() []option.ClientOption {
+	return []option.ClientOption{
+		option.WithGRPCDialOption(grpc.WithChainUnaryInterceptor(countUnary)),
+		option.WithGRPCDialOption(grpc.WithChainStreamInterceptor(countStream)),
+	}
+}
+
+// countUnary liczy zapisy w commitach i odczyty list dokumentów
+func countUnary(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	if err != nil {
+		return err
+	}
+	switch m := req.(type) {
+	case *firestorepb.CommitRequest:
+		record(ctx, 0, int64(len(m.GetWrites())))
+	case *firestorepb.BatchWriteRequest:
+		record(ctx, 0, int64(len(m.GetWrites())))
+	}
+	if list, ok := reply.(*firestorepb.ListDocumentsResponse); ok {
+		record(ctx, int64(len(list.GetDocuments())), 0)
+	}
+	return nil
+}
+
+// countStream liczy dokumenty odebrane z pobrań (BatchGetDocuments) i zapytań (RunQuery)
+func countStream(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &countingStream{ClientStream: stream, ctx: ctx}, nil
+}
+
+type countingStream struct {
+	grpc.ClientStream
+	ctx context.Context
+}
+
+func (s *countingStream) RecvMsg(m any) error {
+	if err := s.ClientStream.RecvMsg(m); err != nil {
+		return err
+	}
+	switch resp := m.(type) {
+	case *firestorepb.BatchGetDocumentsResponse:
+		if resp.GetFound() != nil || resp.GetMissing() != "" {
+			record(s.ctx, 1, 0)
+		}
+	case *firestorepb.RunQueryResponse:
+		if resp.GetDocument() != nil {
+			record(s.ctx, 1, 0)
+		}
+	case *firestorepb.RunAggregationQueryResponse:
+		// Agregacja (np. COUNT) kosztuje odczyt za każde rozpoczęte 1000 dokumentów - liczymy minimum
+		if resp.GetResult() != nil {
+			record(s.ctx, 1, 0)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package firebase
+
+import (
+	"fmt"
+	"time"
+
+	"firebase.google.com/go/v4/auth"
+	"google.golang.org/api/iterator"
+
+	"library-management-system/internal/models"
+)
+
+// FindOrphanedUsers porównuje konta Firebase Auth z profilami w Firestore.
+// Wymaga przejrzenia obu zbiorów w całości, więc jest uruchamiane tylko na żądanie personelu.
+func (c *Client) FindOrphanedUsers() (*models.UserSyncReport, error) {
+	c, span := c.startSpan("FindOrphanedUsers")
+	defer span.End()
+
+	authAccounts := make(map[string]models.AuthAccount)
+
+	iter := c.Auth.Users(c.ctx, "")
+	for {
+		record, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("błąd pobierania kont Firebase Auth: %w", err)
+		}
+
+		authAccounts[record.UID] = models.AuthAccount{
+			UID:       record.UID,
+			Email:     record.Email,
+			CreatedAt: time.UnixMilli(record.UserMetadata.CreationTimestamp),
+		}
+	}
+
+	profiles, err := c.ListUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.UserSyncReport{CheckedAt: time.Now()}
+	for _, profile := range profiles {
+		if _, ok := authAccounts[profile.FirebaseUID]; !ok {
+			report.OrphanedProfiles = append(report.OrphanedProfiles, profile)
+		}
+	}
+
+	// Konta Firebase Auth są wspólne dla całej sieci bibliotek - konto bez profilu
+	// w tej bibliotece może należeć do czytelnika innej
+	linked, err := c.linkedAuthUIDs()
+	if err != nil {
+		return nil, err
+	}
+	for uid, account := range authAccounts {
+		if !linked[uid] {
+			report.OrphanedAccounts = append(report.OrphanedAccounts, account)
+		}
+	}
+
+	return report, nil
+}
+
+// linkedAuthUIDs zwraca UID kont Firebase Auth, które mają profil w dowolnej bibliotece sieci
+func (c *Client) linkedAuthUIDs() (map[string]bool, error) {
+	iter := c.Firestore.CollectionGroup(UsersCollection).Select("firebase_uid").Documents(c.ctx)
+	defer iter.Stop()
+
+	uids := make(map[string]bool)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("błąd pobierania profili użytkowników: %w", err)
+		}
+		if uid, ok := doc.Data()["firebase_uid"].(string); ok {
+			uids[uid] = true
+		}
+	}
+	return uids, nil
+}
+
+// AuthAccountHasProfile sprawdza, czy konto Firebase Auth ma profil w dowolnej bibliotece sieci
+func (c *Client) AuthAccountHasProfile(uid string) (bool, error) {
+	c, span := c.startSpan("AuthAccountHasProfile")
+	defer span.End()
+
+	docs, err := c.Firestore.CollectionGroup(UsersCollection).Where("firebase_uid", "==", uid).Limit(1).Documents(c.ctx).GetAll()
+	if err != nil {
+		return false, fmt.Errorf("błąd wyszukiwania profilu użytkownika: %w", err)
+	}
+	return len(docs) > 0, nil
+}
+
+// AuthAccountExists sprawdza czy konto Firebase Auth o podanym UID istnieje
+func (c *Client) AuthAccountExists(uid string) (bool, error) {
+	c, span := c.startSpan("AuthAccountExists")
+	defer span.End()
+
+	_, err := c.Auth.GetUser(c.ctx, uid)
+	if err == nil {
+		return true, nil
+	}
+	if auth.IsUserNotFound(err) {
+		return false, nil
+	}
+	return false, fmt.Errorf("błąd pobierania konta Firebase Auth: %w", err)
+}
+
+// DeleteAuthAccount usuwa konto Firebase Auth
+func (c *Client) DeleteAuthAccount(uid string) error {
+	c, span := c.startSpan("DeleteAuthAccount")
+	defer span.End()
+
+	if err := c.Auth.DeleteUser(c.ctx, uid); err != nil {
+		return fmt.Errorf("błąd usuwania konta Firebase Auth: %w", err)
+	}
+	return nil
+}
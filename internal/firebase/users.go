@@ -2,9 +2,12 @@ package firebase
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
 	"time"
 
 	"cloud.google.com/go/firestore"
+	"golang.org/x/crypto/bcrypt"
 	"google.golang.org/api/iterator"
 
 	"library-management-system/internal/models"
@@ -15,13 +18,19 @@ const (
 	UsersCollection = "users"
 )
 
+// pinPattern to format PIN-u do weryfikacji tożsamości przez telefon
+var pinPattern = regexp.MustCompile(`^[0-9]{4}$`)
+
 // GetUser pobiera użytkownika po ID
 func (c *Client) GetUser(id string) (*models.User, error) {
+	c, span := c.startSpan("GetUser")
+	defer span.End()
+
 	if id == "" {
 		return nil, fmt.Errorf("ID użytkownika nie może być puste")
 	}
 
-	doc, err := c.Firestore.Collection(UsersCollection).Doc(id).Get(c.ctx)
+	doc, err := c.collection(UsersCollection).Doc(id).Get(c.ctx)
 	if err != nil {
 		return nil, fmt.Errorf("błąd pobierania użytkownika: %w", err)
 	}
@@ -30,17 +39,54 @@ func (c *Client) GetUser(id string) (*models.User, error) {
 	if err := doc.DataTo(&user); err != nil {
 		return nil, fmt.Errorf("błąd parsowania danych użytkownika: %w", err)
 	}
+	user.Tenant = c.tenant
 
 	return &user, nil
 }
 
+// GetUsersByIDs pobiera kilku użytkowników jednym zapytaniem. Zwraca mapę ID -> użytkownik;
+// usuniętych kont brakuje w mapie.
+func (c *Client) GetUsersByIDs(ids []string) (map[string]*models.User, error) {
+	c, span := c.startSpan("GetUsersByIDs")
+	defer span.End()
+
+	refs := c.docRefs(UsersCollection, ids)
+	if len(refs) == 0 {
+		return map[string]*models.User{}, nil
+	}
+
+	docs, err := c.Firestore.GetAll(c.ctx, refs)
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania użytkowników: %w", err)
+	}
+
+	users := make(map[string]*models.User, len(docs))
+	for _, doc := range docs {
+		if !doc.Exists() {
+			continue
+		}
+		var user models.User
+		if err := doc.DataTo(&user); err != nil {
+			return nil, fmt.Errorf("błąd parsowania danych użytkownika: %w", err)
+		}
+		user.ID = doc.Ref.ID
+		user.Tenant = c.tenant
+		users[user.ID] = &user
+	}
+
+	return users, nil
+}
+
 // GetUserByFirebaseUID pobiera użytkownika po Firebase UID
 func (c *Client) GetUserByFirebaseUID(uid string) (*models.User, error) {
+	c, span := c.startSpan("GetUserByFirebaseUID")
+	defer span.End()
+
 	if uid == "" {
 		return nil, fmt.Errorf("Firebase UID nie może być pusty")
 	}
 
-	iter := c.Firestore.Collection(UsersCollection).
+	iter := c.collection(UsersCollection).
 		Where("firebase_uid", "==", uid).
 		Limit(1).
 		Documents(c.ctx)
@@ -58,12 +104,16 @@ func (c *Client) GetUserByFirebaseUID(uid string) (*models.User, error) {
 	if err := doc.DataTo(&user); err != nil {
 		return nil, fmt.Errorf("błąd parsowania danych użytkownika: %w", err)
 	}
+	user.Tenant = c.tenant
 
 	return &user, nil
 }
 
 // CreateUser tworzy nowego użytkownika
 func (c *Client) CreateUser(user *models.User) error {
+	c, span := c.startSpan("CreateUser")
+	defer span.End()
+
 	if user == nil {
 		return fmt.Errorf("użytkownik nie może być nil")
 	}
@@ -86,28 +136,32 @@ func (c *Client) CreateUser(user *models.User) error {
 		user.Role = models.RoleReader
 	}
 	if user.MaxLoans == 0 {
-		user.MaxLoans = 5 // Domyślnie 5 wypożyczeń
+		user.MaxLoans = c.loanPolicy().MaxLoans // Domyślny limit z ustawień biblioteki
 	}
 
 	// Wygeneruj ID jeśli nie ma
 	var docRef *firestore.DocumentRef
 	if user.ID == "" {
-		docRef = c.Firestore.Collection(UsersCollection).NewDoc()
+		docRef = c.collection(UsersCollection).NewDoc()
 		user.ID = docRef.ID
 	} else {
-		docRef = c.Firestore.Collection(UsersCollection).Doc(user.ID)
+		docRef = c.collection(UsersCollection).Doc(user.ID)
 	}
 
 	_, err := docRef.Set(c.ctx, user)
 	if err != nil {
 		return fmt.Errorf("błąd zapisywania użytkownika: %w", err)
 	}
+	user.Tenant = c.tenant
 
 	return nil
 }
 
 // UpdateUser aktualizuje dane użytkownika
 func (c *Client) UpdateUser(id string, user *models.User) error {
+	c, span := c.startSpan("UpdateUser")
+	defer span.End()
+
 	if id == "" {
 		return fmt.Errorf("ID użytkownika nie może być puste")
 	}
@@ -124,7 +178,7 @@ func (c *Client) UpdateUser(id string, user *models.User) error {
 	user.UpdatedAt = time.Now()
 	user.ID = id
 
-	_, err = c.Firestore.Collection(UsersCollection).Doc(id).Set(c.ctx, user)
+	_, err = c.collection(UsersCollection).Doc(id).Set(c.ctx, user)
 	if err != nil {
 		return fmt.Errorf("błąd aktualizacji użytkownika: %w", err)
 	}
@@ -134,6 +188,9 @@ func (c *Client) UpdateUser(id string, user *models.User) error {
 
 // DeleteUser usuwa użytkownika
 func (c *Client) DeleteUser(id string) error {
+	c, span := c.startSpan("DeleteUser")
+	defer span.End()
+
 	if id == "" {
 		return fmt.Errorf("ID użytkownika nie może być puste")
 	}
@@ -143,7 +200,7 @@ func (c *Client) DeleteUser(id string) error {
 		return fmt.Errorf("użytkownik nie istnieje: %w", err)
 	}
 
-	_, err = c.Firestore.Collection(UsersCollection).Doc(id).Delete(c.ctx)
+	_, err = c.collection(UsersCollection).Doc(id).Delete(c.ctx)
 	if err != nil {
 		return fmt.Errorf("błąd usuwania użytkownika: %w", err)
 	}
@@ -153,9 +210,12 @@ func (c *Client) DeleteUser(id string) error {
 
 // ListUsers pobiera listę wszystkich użytkowników
 func (c *Client) ListUsers() ([]*models.User, error) {
+	c, span := c.startSpan("ListUsers")
+	defer span.End()
+
 	var users []*models.User
 
-	iter := c.Firestore.Collection(UsersCollection).
+	iter := c.collection(UsersCollection).
 		OrderBy("last_name", firestore.Asc).
 		Documents(c.ctx)
 	defer iter.Stop()
@@ -182,9 +242,12 @@ func (c *Client) ListUsers() ([]*models.User, error) {
 
 // GetActiveUsers pobiera tylko aktywnych użytkowników
 func (c *Client) GetActiveUsers() ([]*models.User, error) {
+	c, span := c.startSpan("GetActiveUsers")
+	defer span.End()
+
 	var users []*models.User
 
-	iter := c.Firestore.Collection(UsersCollection).
+	iter := c.collection(UsersCollection).
 		Where("is_active", "==", true).
 		OrderBy("last_name", firestore.Asc).
 		Documents(c.ctx)
@@ -210,12 +273,15 @@ func (c *Client) GetActiveUsers() ([]*models.User, error) {
 	return users, nil
 }
 
-// UpdateUserFines aktualizuje sumę kar użytkownika
-func (c *Client) UpdateUserFines(userID string, amount float64) error {
-	docRef := c.Firestore.Collection(UsersCollection).Doc(userID)
+// UpdateUserFines dolicza kwotę do sumy kar użytkownika (ujemna kwota ją zmniejsza)
+func (c *Client) UpdateUserFines(userID string, amount models.Money) error {
+	c, span := c.startSpan("UpdateUserFines")
+	defer span.End()
+
+	docRef := c.collection(UsersCollection).Doc(userID)
 
 	_, err := docRef.Update(c.ctx, []firestore.Update{
-		{Path: "total_fines", Value: firestore.Increment(amount)},
+		{Path: "total_fines_gr", Value: firestore.Increment(int64(amount))},
 		{Path: "updated_at", Value: time.Now()},
 	})
 
@@ -228,7 +294,10 @@ func (c *Client) UpdateUserFines(userID string, amount float64) error {
 
 // UpdateUserLoansCount aktualizuje liczbę aktywnych wypożyczeń użytkownika
 func (c *Client) UpdateUserLoansCount(userID string, increment bool) error {
-	docRef := c.Firestore.Collection(UsersCollection).Doc(userID)
+	c, span := c.startSpan("UpdateUserLoansCount")
+	defer span.End()
+
+	docRef := c.collection(UsersCollection).Doc(userID)
 
 	delta := 1
 	if !increment {
@@ -249,9 +318,180 @@ func (c *Client) UpdateUserLoansCount(userID string, increment bool) error {
 
 // CountTotalUsers zwraca całkowitą liczbę użytkowników w systemie
 func (c *Client) CountTotalUsers() (int, error) {
-	docs, err := c.Firestore.Collection(UsersCollection).Documents(c.ctx).GetAll()
+	c, span := c.startSpan("CountTotalUsers")
+	defer span.End()
+
+	docs, err := c.collection(UsersCollection).Documents(c.ctx).GetAll()
 	if err != nil {
 		return 0, fmt.Errorf("błąd liczenia użytkowników: %w", err)
 	}
 	return len(docs), nil
 }
+
+// CountReadersRegisteredBetween zwraca liczbę czytelników zarejestrowanych w przedziale [from, to)
+func (c *Client) CountReadersRegisteredBetween(from, to time.Time) (int, error) {
+	c, span := c.startSpan("CountReadersRegisteredBetween")
+	defer span.End()
+
+	docs, err := c.collection(UsersCollection).
+		Where("created_at", ">=", from).
+		Where("created_at", "<", to).
+		Select("role").
+		Documents(c.ctx).GetAll()
+	if err != nil {
+		return 0, fmt.Errorf("błąd liczenia czytelników: %w", err)
+	}
+
+	count := 0
+	for _, doc := range docs {
+		if role, err := doc.DataAt("role"); err == nil {
+			if r, _ := role.(string); !models.UserRole(r).IsStaff() {
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
+// SetUserPIN zapisuje hash PIN-u czytelnika (pusty PIN usuwa go)
+func (c *Client) SetUserPIN(userID, pin string) error {
+	c, span := c.startSpan("SetUserPIN")
+	defer span.End()
+
+	hash := ""
+	if pin != "" {
+		if !pinPattern.MatchString(pin) {
+			return fmt.Errorf("PIN musi składać się z 4 cyfr")
+		}
+		hashed, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.DefaultCost)
+		if err != nil {
+			return fmt.Errorf("błąd hashowania PIN-u: %w", err)
+		}
+		hash = string(hashed)
+	}
+
+	_, err := c.collection(UsersCollection).Doc(userID).Update(c.ctx, []firestore.Update{
+		{Path: "pin_hash", Value: hash},
+		{Path: "updated_at", Value: time.Now()},
+	})
+	if err != nil {
+		return fmt.Errorf("błąd zapisywania PIN-u: %w", err)
+	}
+
+	return nil
+}
+
+// SetUserHoldPause ustawia urlop czytelnika od from do until (włącznie).
+// Zerowe daty usuwają urlop.
+func (c *Client) SetUserHoldPause(userID string, from, until time.Time) error {
+	c, span := c.startSpan("SetUserHoldPause")
+	defer span.End()
+
+	updates := []firestore.Update{
+		{Path: "hold_paused_from", Value: firestore.Delete},
+		{Path: "hold_paused_until", Value: firestore.Delete},
+		{Path: "updated_at", Value: time.Now()},
+	}
+
+	if !from.IsZero() || !until.IsZero() {
+		if from.IsZero() || until.IsZero() {
+			return fmt.Errorf("podaj początek i koniec urlopu")
+		}
+		if until.Before(from) {
+			return fmt.Errorf("koniec urlopu jest wcześniejszy niż początek")
+		}
+		if until.AddDate(0, 0, 1).Before(time.Now()) {
+			return fmt.Errorf("urlop już się zakończył")
+		}
+		updates[0].Value = from
+		updates[1].Value = until
+	}
+
+	_, err := c.collection(UsersCollection).Doc(userID).Update(c.ctx, updates)
+	if err != nil {
+		return fmt.Errorf("błąd zapisywania urlopu: %w", err)
+	}
+
+	return nil
+}
+
+// SaveNotificationSettings zapisuje kanały powiadomień, zgody i zapis dat wybrany przez czytelnika
+func (c *Client) SaveNotificationSettings(user *models.User) error {
+	c, span := c.startSpan("SaveNotificationSettings")
+	defer span.End()
+
+	_, err := c.collection(UsersCollection).Doc(user.ID).Update(c.ctx, []firestore.Update{
+		{Path: "notification_prefs", Value: user.NotificationPrefs},
+		{Path: "consents", Value: user.Consents},
+		{Path: "locale", Value: user.Locale},
+		{Path: "updated_at", Value: time.Now()},
+	})
+	if err != nil {
+		return fmt.Errorf("błąd zapisywania ustawień powiadomień: %w", err)
+	}
+
+	return nil
+}
+
+// GetPendingUsers pobiera konta czekające na zatwierdzenie przez personel (najstarsze pierwsze)
+func (c *Client) GetPendingUsers() ([]*models.User, error) {
+	c, span := c.startSpan("GetPendingUsers")
+	defer span.End()
+
+	docs, err := c.collection(UsersCollection).Where("pending_approval", "==", true).Documents(c.ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("błąd pobierania kont do zatwierdzenia: %w", err)
+	}
+
+	users := make([]*models.User, 0, len(docs))
+	for _, doc := range docs {
+		var user models.User
+		if err := doc.DataTo(&user); err != nil {
+			return nil, fmt.Errorf("błąd parsowania użytkownika: %w", err)
+		}
+		user.ID = doc.Ref.ID
+		user.Tenant = c.tenant
+		users = append(users, &user)
+	}
+
+	// Sortowanie w pamięci - kolejka jest krótka, a zapytanie nie wymaga indeksu złożonego
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].CreatedAt.Before(users[j].CreatedAt)
+	})
+	return users, nil
+}
+
+// SetUserApproval zatwierdza konto czekające na weryfikację albo je odrzuca. Odrzucone
+// konto zostaje dezaktywowane - personel może je później włączyć w edycji użytkownika.
+func (c *Client) SetUserApproval(userID string, approved bool) error {
+	c, span := c.startSpan("SetUserApproval")
+	defer span.End()
+
+	updates := []firestore.Update{
+		{Path: "pending_approval", Value: false},
+		{Path: "updated_at", Value: time.Now()},
+	}
+	if !approved {
+		updates = append(updates, firestore.Update{Path: "is_active", Value: false})
+	}
+
+	if _, err := c.collection(UsersCollection).Doc(userID).Update(c.ctx, updates); err != nil {
+		return fmt.Errorf("błąd zapisywania decyzji o koncie: %w", err)
+	}
+	return nil
+}
+
+// VerifyUserPIN sprawdza PIN podany przez czytelnika (false, gdy czytelnik nie ustawił PIN-u)
+func (c *Client) VerifyUserPIN(userID, pin string) (bool, error) {
+	c, span := c.startSpan("VerifyUserPIN")
+	defer span.End()
+
+	user, err := c.GetUser(userID)
+	if err != nil {
+		return false, err
+	}
+	if !user.HasPIN() {
+		return false, nil
+	}
+	return bcrypt.CompareHashAndPassword([]byte(user.PINHash), []byte(pin)) == nil, nil
+}
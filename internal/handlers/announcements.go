@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/middleware"
+	"library-management-system/internal/models"
+	"library-management-system/internal/search"
+)
+
+// AnnouncementsHandler obsługuje ogłoszenia - publiczne strony i zarządzanie w panelu personelu
+type AnnouncementsHandler struct {
+	listTemplate       *template.Template
+	publicListTemplate *template.Template
+	detailTemplate     *template.Template
+	fbClient           *firebase.Client
+	searchIndex        *search.Index
+}
+
+// NewAnnouncementsHandler tworzy nowy handler ogłoszeń
+func NewAnnouncementsHandler(fbClient *firebase.Client, searchIndex *search.Index) *AnnouncementsHandler {
+	listTmpl, err := template.New("announcements.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/announcements.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/announcements.html: %v", err)
+	}
+
+	publicListTmpl, err := template.New("list.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/announcements/list.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu announcements/list.html: %v", err)
+	}
+
+	detailTmpl, err := template.New("detail.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/announcements/detail.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu announcements/detail.html: %v", err)
+	}
+
+	return &AnnouncementsHandler{
+		listTemplate:       listTmpl,
+		publicListTemplate: publicListTmpl,
+		detailTemplate:     detailTmpl,
+		fbClient:           fbClient,
+		searchIndex:        searchIndex,
+	}
+}
+
+// ListPublished wyświetla wszystkie opublikowane ogłoszenia (GET /announcements)
+func (h *AnnouncementsHandler) ListPublished(w http.ResponseWriter, r *http.Request) {
+	if h.publicListTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	data := NewPageData(r)
+
+	if h.fbClient != nil {
+		announcements, err := h.fbClient.Traced(r.Context()).GetPublishedAnnouncements(0)
+		if err != nil {
+			log.Printf("Błąd pobierania ogłoszeń: %v", err)
+			data["Error"] = "Błąd pobierania ogłoszeń z bazy danych"
+		}
+		data["Announcements"] = announcements
+	}
+
+	if err := h.publicListTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania ogłoszeń: %v", err)
+		http.Error(w, "Błąd renderowania strony", http.StatusInternalServerError)
+	}
+}
+
+// ShowAnnouncement wyświetla pojedyncze opublikowane ogłoszenie (GET /announcements/{id})
+func (h *AnnouncementsHandler) ShowAnnouncement(w http.ResponseWriter, r *http.Request) {
+	if h.detailTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return
+	}
+
+	announcement, err := h.fbClient.Traced(r.Context()).GetAnnouncement(chi.URLParam(r, "id"))
+	if err != nil || !announcement.Published {
+		http.Error(w, "Ogłoszenie nie zostało znalezione", http.StatusNotFound)
+		return
+	}
+
+	data := NewPageData(r)
+	data["Announcement"] = announcement
+
+	if err := h.detailTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania ogłoszenia: %v", err)
+		http.Error(w, "Błąd renderowania strony", http.StatusInternalServerError)
+	}
+}
+
+// ListAnnouncements wyświetla listę ogłoszeń (GET /staff/announcements)
+func (h *AnnouncementsHandler) ListAnnouncements(w http.ResponseWriter, r *http.Request) {
+	if h.listTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	data := NewPageData(r)
+
+	if h.fbClient != nil {
+		announcements, err := h.fbClient.Traced(r.Context()).ListAnnouncements()
+		if err != nil {
+			log.Printf("Błąd pobierania ogłoszeń: %v", err)
+			data["Error"] = "Błąd pobierania ogłoszeń z bazy danych"
+		}
+		data["Announcements"] = announcements
+
+		if editID := r.URL.Query().Get("edit"); editID != "" {
+			editing, err := h.fbClient.Traced(r.Context()).GetAnnouncement(editID)
+			if err != nil {
+				log.Printf("Błąd pobierania ogłoszenia do edycji: %v", err)
+				data["Error"] = "Nie znaleziono ogłoszenia do edycji"
+			} else {
+				data["Editing"] = editing
+			}
+		}
+	}
+
+	if err := h.listTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania ogłoszeń: %v", err)
+		http.Error(w, "Błąd renderowania strony", http.StatusInternalServerError)
+	}
+}
+
+// CreateAnnouncement tworzy nowe ogłoszenie (POST /staff/announcements)
+func (h *AnnouncementsHandler) CreateAnnouncement(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Błąd parsowania formularza", http.StatusBadRequest)
+		return
+	}
+
+	title := strings.TrimSpace(r.FormValue("title"))
+	if title == "" {
+		http.Error(w, "Tytuł jest wymagany", http.StatusBadRequest)
+		return
+	}
+
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return
+	}
+
+	announcement := &models.Announcement{
+		Title:      title,
+		Body:       r.FormValue("body"),
+		Published:  r.FormValue("published") == "true",
+		AuthorID:   session.UserID,
+		AuthorName: session.User.FullName(),
+	}
+
+	if err := h.fbClient.Traced(r.Context()).CreateAnnouncement(announcement); err != nil {
+		log.Printf("Błąd tworzenia ogłoszenia: %v", err)
+		http.Error(w, "Błąd zapisywania ogłoszenia", http.StatusInternalServerError)
+		return
+	}
+	h.searchIndex.Invalidate()
+
+	basepath.Redirect(w, r, "/staff/announcements", http.StatusSeeOther)
+}
+
+// UpdateAnnouncement zapisuje zmiany w ogłoszeniu (POST /staff/announcements/{id})
+func (h *AnnouncementsHandler) UpdateAnnouncement(w http.ResponseWriter, r *http.Request) {
+	announcementID := chi.URLParam(r, "id")
+	if announcementID == "" {
+		http.Error(w, "Brak ID ogłoszenia", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Błąd parsowania formularza", http.StatusBadRequest)
+		return
+	}
+
+	title := strings.TrimSpace(r.FormValue("title"))
+	if title == "" {
+		http.Error(w, "Tytuł jest wymagany", http.StatusBadRequest)
+		return
+	}
+
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return
+	}
+
+	announcement, err := h.fbClient.Traced(r.Context()).GetAnnouncement(announcementID)
+	if err != nil {
+		log.Printf("Błąd pobierania ogłoszenia: %v", err)
+		http.Error(w, "Nie znaleziono ogłoszenia", http.StatusNotFound)
+		return
+	}
+
+	announcement.Title = title
+	announcement.Body = r.FormValue("body")
+	announcement.Published = r.FormValue("published") == "true"
+
+	if err := h.fbClient.Traced(r.Context()).UpdateAnnouncement(announcementID, announcement); err != nil {
+		log.Printf("Błąd aktualizacji ogłoszenia: %v", err)
+		http.Error(w, "Błąd zapisywania ogłoszenia", http.StatusInternalServerError)
+		return
+	}
+	h.searchIndex.Invalidate()
+
+	basepath.Redirect(w, r, "/staff/announcements", http.StatusSeeOther)
+}
+
+// TogglePublished przełącza widoczność ogłoszenia (POST /staff/announcements/{id}/toggle)
+func (h *AnnouncementsHandler) TogglePublished(w http.ResponseWriter, r *http.Request) {
+	announcementID := chi.URLParam(r, "id")
+	if announcementID == "" {
+		http.Error(w, "Brak ID ogłoszenia", http.StatusBadRequest)
+		return
+	}
+
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return
+	}
+
+	announcement, err := h.fbClient.Traced(r.Context()).GetAnnouncement(announcementID)
+	if err != nil {
+		log.Printf("Błąd pobierania ogłoszenia: %v", err)
+		http.Error(w, "Nie znaleziono ogłoszenia", http.StatusNotFound)
+		return
+	}
+
+	announcement.Published = !announcement.Published
+	if err := h.fbClient.Traced(r.Context()).UpdateAnnouncement(announcementID, announcement); err != nil {
+		log.Printf("Błąd aktualizacji ogłoszenia: %v", err)
+		http.Error(w, "Błąd zapisywania ogłoszenia", http.StatusInternalServerError)
+		return
+	}
+	h.searchIndex.Invalidate()
+
+	basepath.Redirect(w, r, "/staff/announcements", http.StatusSeeOther)
+}
+
+// DeleteAnnouncement usuwa ogłoszenie (DELETE /staff/announcements/{id})
+func (h *AnnouncementsHandler) DeleteAnnouncement(w http.ResponseWriter, r *http.Request) {
+	announcementID := chi.URLParam(r, "id")
+	if announcementID == "" {
+		http.Error(w, "Brak ID ogłoszenia", http.StatusBadRequest)
+		return
+	}
+
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.fbClient.Traced(r.Context()).DeleteAnnouncement(announcementID); err != nil {
+		log.Printf("Błąd usuwania ogłoszenia: %v", err)
+		http.Error(w, "Błąd usuwania ogłoszenia", http.StatusInternalServerError)
+		return
+	}
+	h.searchIndex.Invalidate()
+
+	// Zwróć pustą odpowiedź (element zostanie usunięty przez htmx)
+	w.WriteHeader(http.StatusOK)
+}
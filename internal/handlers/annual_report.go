@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+)
+
+// Grupy literatury w sprawozdaniu rocznym biblioteki publicznej (GUS K-03)
+const (
+	statsGroupAdultFiction    = "Literatura piękna dla dorosłych"
+	statsGroupChildrenFiction = "Literatura piękna dla dzieci i młodzieży"
+	statsGroupNonFiction      = "Literatura popularnonaukowa i pozostała"
+)
+
+var statsGroups = []string{statsGroupAdultFiction, statsGroupChildrenFiction, statsGroupNonFiction}
+
+// AnnualReportHandler obsługuje roczne sprawozdanie statystyczne biblioteki
+type AnnualReportHandler struct {
+	reportTemplate *template.Template
+	fbClient       *firebase.Client
+}
+
+// StatisticsRow to jeden wiersz zestawienia (etykieta i wartość)
+type StatisticsRow struct {
+	Label string
+	Value int
+}
+
+// AnnualStatistics to dane do sprawozdania rocznego w podziale wymaganym przez GUS
+// (w zakresie, w jakim system je zbiera - bez podziału czytelników według wieku i zajęcia)
+type AnnualStatistics struct {
+	Year          int
+	Loans         int
+	LoansByGroup  []StatisticsRow
+	LoansByMonth  []StatisticsRow
+	ActiveReaders int // Czytelnicy, którzy wypożyczyli w ciągu roku
+	NewReaders    int // Nowo zarejestrowani czytelnicy
+	Visits        int // Odwiedziny w wypożyczalni: dni, w których czytelnik wypożyczył lub zwrócił książkę
+	InLibraryUses int // Udostępnienia na miejscu (czytelnia)
+	// Wypożyczenia z danego roku pozbawione danych czytelnika po okresie przechowywania -
+	// liczą się do wypożyczeń, ale nie do czytelników i odwiedzin
+	AnonymizedLoans int
+
+	CollectionTitles  int // Stan księgozbioru na koniec roku
+	CollectionVolumes int
+	AddedVolumes      int // Wpływy w ciągu roku
+	WithdrawnVolumes  int // Ubytki w ciągu roku
+}
+
+// NewAnnualReportHandler tworzy handler sprawozdania rocznego
+func NewAnnualReportHandler(fbClient *firebase.Client) *AnnualReportHandler {
+	reportTmpl, err := template.New("annual_report.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/annual_report.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/annual_report.html: %v", err)
+	}
+
+	return &AnnualReportHandler{
+		reportTemplate: reportTmpl,
+		fbClient:       fbClient,
+	}
+}
+
+// ShowReport wyświetla sprawozdanie za wybrany rok (GET /staff/reports/annual?year=2025,
+// domyślnie poprzedni rok). Z parametrem format=csv zwraca plik do przepisania do formularza.
+func (h *AnnualReportHandler) ShowReport(w http.ResponseWriter, r *http.Request) {
+	if h.reportTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	year := now.Year() - 1
+	if value := r.URL.Query().Get("year"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 2000 || parsed > now.Year() {
+			http.Error(w, "Nieprawidłowy rok", http.StatusBadRequest)
+			return
+		}
+		year = parsed
+	}
+
+	stats, err := h.statistics(year, now.Location())
+	if err != nil {
+		log.Printf("Błąd przygotowania sprawozdania za %d: %v", year, err)
+		http.Error(w, "Błąd przygotowania sprawozdania", http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "csv" {
+		h.writeCSV(w, stats)
+		return
+	}
+
+	var years []int
+	for y := now.Year(); y > now.Year()-5; y-- {
+		years = append(years, y)
+	}
+
+	data := NewPageData(r)
+	data["Stats"] = stats
+	data["Years"] = years
+	data["InProgress"] = year == now.Year()
+
+	if err := h.reportTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania sprawozdania rocznego: %v", err)
+	}
+}
+
+// statistics zbiera dane za rok z wypożyczeń, rejestracji, katalogu i wycofanych egzemplarzy
+func (h *AnnualReportHandler) statistics(year int, loc *time.Location) (*AnnualStatistics, error) {
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+	to := from.AddDate(1, 0, 0)
+
+	loans, err := h.fbClient.GetLoansBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+	returns, err := h.fbClient.GetReturnsBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+	newReaders, err := h.fbClient.CountReadersRegisteredBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+	books, err := h.fbClient.ListBooks()
+	if err != nil {
+		return nil, err
+	}
+	withdrawals, err := h.fbClient.GetWithdrawalsSince(from)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := annualStatistics(year, from, to, loans, returns, books, withdrawals)
+	stats.NewReaders = newReaders
+	return stats, nil
+}
+
+// annualStatistics liczy wskaźniki sprawozdania. Stan księgozbioru jest odtwarzany
+// z bieżącego katalogu: do egzemplarzy książek dodanych przed końcem roku doliczane są
+// egzemplarze wycofane później (książki usunięte z katalogu nie są uwzględniane).
+func annualStatistics(year int, from, to time.Time, loans, returns []*models.Loan, books []*models.Book, withdrawals []*models.CopyWithdrawal) *AnnualStatistics {
+	stats := &AnnualStatistics{Year: year}
+
+	categories := make(map[string]string, len(books))
+	for _, book := range books {
+		categories[book.ID] = book.Category
+	}
+
+	byGroup := make(map[string]int)
+	byMonth := make([]int, 12)
+	readers := make(map[string]bool)
+	visits := make(map[string]bool)
+	for _, loan := range loans {
+		if loan.Status == models.LoanStatusPendingPickup {
+			continue // Książka nie została jeszcze wydana
+		}
+		if loan.Anonymized {
+			stats.AnonymizedLoans++
+		} else {
+			visits[loan.UserID+"|"+loan.LoanDate.In(from.Location()).Format("2006-01-02")] = true
+		}
+		if loan.IsReadingRoom() {
+			stats.InLibraryUses++
+			continue
+		}
+		stats.Loans++
+		byGroup[statisticsGroup(categories[loan.BookID])]++
+		byMonth[loan.LoanDate.In(from.Location()).Month()-1]++
+		if !loan.Anonymized {
+			readers[loan.UserID] = true
+		}
+	}
+	for _, loan := range returns {
+		if loan.Anonymized {
+			continue
+		}
+		visits[loan.UserID+"|"+loan.ReturnDate.In(from.Location()).Format("2006-01-02")] = true
+	}
+	stats.ActiveReaders = len(readers)
+	stats.Visits = len(visits)
+
+	for _, group := range statsGroups {
+		stats.LoansByGroup = append(stats.LoansByGroup, StatisticsRow{Label: group, Value: byGroup[group]})
+	}
+	for i, month := range models.MonthNames {
+		stats.LoansByMonth = append(stats.LoansByMonth, StatisticsRow{Label: month, Value: byMonth[i]})
+	}
+
+	withdrawnLater := make(map[string]int)
+	for _, withdrawal := range withdrawals {
+		if withdrawal.CreatedAt.Before(to) {
+			stats.WithdrawnVolumes += withdrawal.Count
+		} else {
+			withdrawnLater[withdrawal.BookID] += withdrawal.Count
+		}
+	}
+	withdrawnSince := make(map[string]int)
+	for _, withdrawal := range withdrawals {
+		withdrawnSince[withdrawal.BookID] += withdrawal.Count
+	}
+
+	for _, book := range books {
+		if !book.CreatedAt.Before(to) {
+			continue
+		}
+		stats.CollectionTitles++
+		stats.CollectionVolumes += book.TotalCopies + withdrawnLater[book.ID]
+		if !book.CreatedAt.Before(from) {
+			stats.AddedVolumes += book.TotalCopies + withdrawnSince[book.ID]
+		}
+	}
+
+	return stats
+}
+
+// statisticsGroup przypisuje kategorię katalogu do grupy literatury ze sprawozdania
+func statisticsGroup(category string) string {
+	switch category {
+	case "Beletrystyka", "Fantastyka", "Kryminał", "Romans", "Literatura piękna", "Komiks":
+		return statsGroupAdultFiction
+	case "Dla dzieci":
+		return statsGroupChildrenFiction
+	default:
+		return statsGroupNonFiction
+	}
+}
+
+// writeCSV zapisuje sprawozdanie w formacie otwieranym bezpośrednio przez polskiego Excela
+func (h *AnnualReportHandler) writeCSV(w http.ResponseWriter, stats *AnnualStatistics) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="sprawozdanie-`+strconv.Itoa(stats.Year)+`.csv"`)
+	w.Write([]byte("\xef\xbb\xbf"))
+
+	writer := csv.NewWriter(w)
+	writer.Comma = ';'
+	row := func(section, label string, value int) {
+		writer.Write([]string{section, label, strconv.Itoa(value)})
+	}
+
+	writer.Write([]string{"Dział", "Wskaźnik", "Wartość"})
+	row("Czytelnicy", "Czytelnicy wypożyczający w ciągu roku", stats.ActiveReaders)
+	row("Czytelnicy", "Nowo zarejestrowani", stats.NewReaders)
+	row("Odwiedziny", "Odwiedziny w wypożyczalni", stats.Visits)
+	row("Wypożyczenia", "Wypożyczenia na zewnątrz (razem)", stats.Loans)
+	row("Udostępnienia", "Udostępnienia na miejscu", stats.InLibraryUses)
+	for _, r := range stats.LoansByGroup {
+		row("Wypożyczenia", r.Label, r.Value)
+	}
+	for _, r := range stats.LoansByMonth {
+		row("Wypożyczenia według miesięcy", r.Label, r.Value)
+	}
+	row("Księgozbiór", "Tytuły na koniec roku", stats.CollectionTitles)
+	row("Księgozbiór", "Woluminy na koniec roku", stats.CollectionVolumes)
+	row("Księgozbiór", "Wpływy (woluminy)", stats.AddedVolumes)
+	row("Księgozbiór", "Ubytki (woluminy)", stats.WithdrawnVolumes)
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Printf("Błąd zapisu sprawozdania CSV: %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+
+	"library-management-system/internal/api"
+	"library-management-system/internal/firebase"
+)
+
+// APIUsageHandler pokazuje personelowi zużycie limitów JSON API
+type APIUsageHandler struct {
+	usageTemplate *template.Template
+	quota         *api.Quota
+}
+
+// NewAPIUsageHandler tworzy handler strony zużycia API
+func NewAPIUsageHandler(fbClient *firebase.Client, quota *api.Quota) *APIUsageHandler {
+	usageTmpl, err := template.New("api_usage.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/api_usage.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/api_usage.html: %v", err)
+	}
+
+	return &APIUsageHandler{
+		usageTemplate: usageTmpl,
+		quota:         quota,
+	}
+}
+
+// ShowUsage wyświetla zużycie limitów per token i adres IP (GET /staff/api-usage)
+func (h *APIUsageHandler) ShowUsage(w http.ResponseWriter, r *http.Request) {
+	if h.usageTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	data := NewPageData(r)
+	data["Usage"] = h.quota.Usage()
+
+	if err := h.usageTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania zużycia API: %v", err)
+		http.Error(w, "Błąd renderowania strony", http.StatusInternalServerError)
+	}
+}
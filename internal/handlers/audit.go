@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/middleware"
+	"library-management-system/internal/models"
+	"library-management-system/internal/search"
+)
+
+// auditLogLimit to liczba ostatnich wpisów dziennika zmian na stronie
+const auditLogLimit = 100
+
+// AuditHandler obsługuje dziennik zmian personelu i cofanie ostatnich czynności
+type AuditHandler struct {
+	template    *template.Template
+	fbClient    *firebase.Client
+	searchIndex *search.Index
+}
+
+// NewAuditHandler tworzy handler dziennika zmian. Cofnięcie edycji książki
+// unieważnia indeks wyszukiwania, tak jak sama edycja.
+func NewAuditHandler(fbClient *firebase.Client, searchIndex *search.Index) *AuditHandler {
+	tmpl, err := template.New("audit_log.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/audit_log.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/audit_log.html: %v", err)
+	}
+
+	return &AuditHandler{
+		template:    tmpl,
+		fbClient:    fbClient,
+		searchIndex: searchIndex,
+	}
+}
+
+// ShowAuditLog wyświetla ostatnie czynności personelu z przyciskiem cofnięcia (GET /staff/audit-log)
+func (h *AuditHandler) ShowAuditLog(w http.ResponseWriter, r *http.Request) {
+	if h.template == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	data := NewPageData(r)
+	data["Message"] = r.URL.Query().Get("msg")
+	data["Error"] = r.URL.Query().Get("error")
+	data["UndoMinutes"] = int(models.AuditUndoWindow.Minutes())
+	data["Now"] = time.Now()
+
+	if h.fbClient == nil {
+		data["Error"] = "Baza danych niedostępna"
+	} else {
+		entries, err := h.fbClient.Traced(r.Context()).ListAuditLog(auditLogLimit)
+		if err != nil {
+			log.Printf("Błąd pobierania dziennika zmian: %v", err)
+			data["Error"] = "Błąd pobierania dziennika zmian z bazy danych"
+		}
+		data["Entries"] = entries
+	}
+
+	if err := h.template.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania dziennika zmian: %v", err)
+	}
+}
+
+// UndoAuditEntry cofa czynność z dziennika zmian (POST /staff/audit-log/{id}/undo)
+func (h *AuditHandler) UndoAuditEntry(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	sess := middleware.GetSessionFromContext(r.Context())
+	entry, err := h.fbClient.Traced(r.Context()).UndoAuditEntry(chi.URLParam(r, "id"), sess.User.Email)
+	if err != nil {
+		log.Printf("Błąd cofania czynności: %v", err)
+		redirectToAuditLog(w, r, "error", err.Error())
+		return
+	}
+	if entry.Action == models.AuditBookEdit {
+		h.searchIndex.Invalidate()
+	}
+
+	log.Printf("Cofnięto %s %s (wpis %s) przez %s", entry.Action, entry.EntityID, entry.ID, sess.User.Email)
+	redirectToAuditLog(w, r, "msg", "Cofnięto: "+entry.ActionLabel()+" - "+entry.Label)
+}
+
+// returnAuditLabel opisuje zwrot w dzienniku zmian
+func returnAuditLabel(loan *models.Loan) string {
+	return loan.BookTitle + " - " + loan.ReaderName()
+}
+
+func redirectToAuditLog(w http.ResponseWriter, r *http.Request, key, message string) {
+	basepath.Redirect(w, r, "/staff/audit-log?"+key+"="+url.QueryEscape(message), http.StatusSeeOther)
+}
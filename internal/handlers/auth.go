@@ -4,9 +4,12 @@ import (
 	"html/template"
 	"log"
 	"net/http"
+	"time"
 
+	"library-management-system/internal/basepath"
 	"library-management-system/internal/firebase"
 	"library-management-system/internal/models"
+	"library-management-system/internal/repository"
 	"library-management-system/internal/session"
 
 	"firebase.google.com/go/v4/auth"
@@ -16,16 +19,19 @@ import (
 type AuthHandler struct {
 	loginTemplate    *template.Template
 	registerTemplate *template.Template
+	fbClient         *firebase.Client
+	accounts         repository.Accounts // Konta z hasłem sprawdzanym lokalnie (tryb bez bazy danych)
 }
 
-// NewAuthHandler tworzy nowy handler autoryzacji
-func NewAuthHandler() *AuthHandler {
-	loginTmpl, err := template.ParseFiles("internal/templates/auth/login.html")
+// NewAuthHandler tworzy nowy handler autoryzacji. Bez Firebase hasła sprawdza accounts
+// (nil, gdy logowanie jest możliwe tylko przez Firebase Authentication).
+func NewAuthHandler(fbClient *firebase.Client, accounts repository.Accounts) *AuthHandler {
+	loginTmpl, err := template.New("login.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/auth/login.html")
 	if err != nil {
 		log.Printf("Błąd ładowania szablonu login.html: %v", err)
 	}
 
-	registerTmpl, err := template.ParseFiles("internal/templates/auth/register.html")
+	registerTmpl, err := template.New("register.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/auth/register.html")
 	if err != nil {
 		log.Printf("Błąd ładowania szablonu register.html: %v", err)
 	}
@@ -33,6 +39,8 @@ func NewAuthHandler() *AuthHandler {
 	return &AuthHandler{
 		loginTemplate:    loginTmpl,
 		registerTemplate: registerTmpl,
+		fbClient:         fbClient,
+		accounts:         accounts,
 	}
 }
 
@@ -56,7 +64,7 @@ func (h *AuthHandler) ShowLoginPage(w http.ResponseWriter, r *http.Request) {
 // HandleLogin obsługuje logowanie (POST /login)
 func (h *AuthHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
 		return
 	}
 
@@ -68,25 +76,9 @@ func (h *AuthHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Sprawdź czy Firebase jest zainicjalizowany
-	if firebase.GlobalClient == nil {
-		h.renderLoginError(w, "System autoryzacji nie jest dostępny")
-		return
-	}
-
-	// Weryfikuj email i hasło przez Firebase Authentication REST API
-	firebaseUID, err := firebase.GlobalClient.VerifyPassword(email, password)
-	if err != nil {
-		log.Printf("Błąd weryfikacji hasła: %v", err)
-		h.renderLoginError(w, err.Error())
-		return
-	}
-
-	// Pobierz użytkownika z Firestore po Firebase UID
-	dbUser, err := firebase.GlobalClient.GetUserByFirebaseUID(firebaseUID)
-	if err != nil {
-		log.Printf("Użytkownik nie znaleziony w bazie: %v", err)
-		h.renderLoginError(w, "Użytkownik nie istnieje w systemie")
+	dbUser, errorMsg := h.authenticate(r, email, password)
+	if dbUser == nil {
+		h.renderLoginError(w, errorMsg)
 		return
 	}
 
@@ -108,12 +100,61 @@ func (h *AuthHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("Użytkownik zalogowany: %s (%s)", email, dbUser.Role)
 
-	// Przekieruj w zależności od roli
-	if dbUser.Role == models.RoleAdmin {
-		http.Redirect(w, r, "/staff", http.StatusSeeOther)
-	} else {
-		http.Redirect(w, r, "/books", http.StatusSeeOther)
+	basepath.Redirect(w, r, h.landingPage(dbUser), http.StatusSeeOther)
+}
+
+// authenticate sprawdza email i hasło: przez Firebase Authentication albo, w trybie bez
+// bazy danych, w kontach lokalnych. Zwraca konto albo komunikat błędu dla formularza.
+func (h *AuthHandler) authenticate(r *http.Request, email, password string) (*models.User, string) {
+	if h.fbClient == nil {
+		if h.accounts == nil {
+			return nil, "System autoryzacji nie jest dostępny"
+		}
+		user, err := h.accounts.Authenticate(email, password)
+		if err != nil {
+			log.Printf("Błąd weryfikacji hasła: %v", err)
+			return nil, "Błąd logowania"
+		}
+		if user == nil {
+			return nil, "Nieprawidłowy email lub hasło"
+		}
+		return user, ""
 	}
+
+	// Weryfikuj email i hasło przez Firebase Authentication REST API
+	firebaseUID, err := h.fbClient.Traced(r.Context()).VerifyPassword(email, password)
+	if err != nil {
+		log.Printf("Błąd weryfikacji hasła: %v", err)
+		return nil, err.Error()
+	}
+
+	// Pobierz użytkownika z Firestore po Firebase UID
+	dbUser, err := h.fbClient.Traced(r.Context()).GetUserByFirebaseUID(firebaseUID)
+	if err != nil {
+		log.Printf("Użytkownik nie znaleziony w bazie: %v", err)
+		return nil, "Użytkownik nie istnieje w systemie"
+	}
+	return dbUser, ""
+}
+
+// landingPage zwraca stronę, na którą trafia użytkownik po zalogowaniu: czytelnik, który
+// nie zaakceptował obowiązującej wersji regulaminu, zaczyna od akceptacji, pozostali
+// trafiają na stronę zależną od roli
+func (h *AuthHandler) landingPage(user *models.User) string {
+	// Regulaminy są w Firestore - bez bazy danych nie ma czego akceptować
+	if h.fbClient != nil {
+		current, err := h.fbClient.GetCurrentRegulations()
+		if err != nil {
+			log.Printf("Błąd sprawdzania regulaminu: %v", err)
+		} else if user.NeedsRegulations(current) {
+			return "/regulations/accept"
+		}
+	}
+
+	if user.IsStaff() {
+		return "/staff"
+	}
+	return "/books"
 }
 
 // ShowRegisterPage wyświetla stronę rejestracji (GET /register)
@@ -124,7 +165,8 @@ func (h *AuthHandler) ShowRegisterPage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	data := map[string]interface{}{
-		"Error": nil,
+		"Error":        nil,
+		"ConsentTypes": models.ConsentTypes,
 	}
 
 	if err := h.registerTemplate.Execute(w, data); err != nil {
@@ -136,7 +178,7 @@ func (h *AuthHandler) ShowRegisterPage(w http.ResponseWriter, r *http.Request) {
 // HandleRegister obsługuje rejestrację (POST /register)
 func (h *AuthHandler) HandleRegister(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Redirect(w, r, "/register", http.StatusSeeOther)
+		basepath.Redirect(w, r, "/register", http.StatusSeeOther)
 		return
 	}
 
@@ -158,42 +200,23 @@ func (h *AuthHandler) HandleRegister(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Sprawdź czy Firebase jest zainicjalizowany
-	if firebase.GlobalClient == nil {
-		h.renderRegisterError(w, "System autoryzacji nie jest dostępny")
-		return
-	}
-
-	// Utwórz użytkownika w Firebase Auth
-	params := (&auth.UserToCreate{}).
-		Email(email).
-		Password(password).
-		DisplayName(firstName + " " + lastName)
-
-	firebaseUser, err := firebase.GlobalClient.Auth.CreateUser(r.Context(), params)
-	if err != nil {
-		log.Printf("Błąd tworzenia użytkownika w Firebase Auth: %v", err)
-		h.renderRegisterError(w, "Użytkownik z tym adresem email już istnieje lub hasło jest za słabe")
-		return
+	user := &models.User{
+		Email:     email,
+		FirstName: firstName,
+		LastName:  lastName,
+		Phone:     phone,
+		Role:      models.RoleReader,
+		IsActive:  true,
 	}
 
-	// Utwórz użytkownika w Firestore
-	user := &models.User{
-		FirebaseUID: firebaseUser.UID,
-		Email:       email,
-		FirstName:   firstName,
-		LastName:    lastName,
-		Phone:       phone,
-		Role:        models.RoleReader,
-		IsActive:    true,
-		MaxLoans:    5,
+	// Przy rejestracji odnotowujemy decyzję o każdej zgodzie, także odmowę
+	now := time.Now()
+	for _, consent := range models.ConsentTypes {
+		user.RecordConsent(consent.Type, r.FormValue("consent_"+string(consent.Type)) == "1", models.ConsentSourceRegistration, now)
 	}
 
-	if err := firebase.GlobalClient.CreateUser(user); err != nil {
-		log.Printf("Błąd tworzenia użytkownika w Firestore: %v", err)
-		// Próba usunięcia użytkownika z Auth jeśli nie udało się dodać do Firestore
-		firebase.GlobalClient.Auth.DeleteUser(r.Context(), firebaseUser.UID)
-		h.renderRegisterError(w, "Błąd tworzenia konta użytkownika")
+	if errorMsg := h.createAccount(r, user, password); errorMsg != "" {
+		h.renderRegisterError(w, errorMsg)
 		return
 	}
 
@@ -203,15 +226,58 @@ func (h *AuthHandler) HandleRegister(w http.ResponseWriter, r *http.Request) {
 	sess, err := session.GetManager().CreateSession(user)
 	if err != nil {
 		log.Printf("Błąd tworzenia sesji: %v", err)
-		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
 		return
 	}
 
 	session.SetSessionCookie(w, sess.ID)
 	log.Printf("Użytkownik automatycznie zalogowany po rejestracji")
 
-	// Przekieruj na stronę książek
-	http.Redirect(w, r, "/books", http.StatusSeeOther)
+	basepath.Redirect(w, r, h.landingPage(user), http.StatusSeeOther)
+}
+
+// createAccount zakłada konto nowego czytelnika: w Firebase Auth i Firestore albo, w trybie
+// bez bazy danych, w kontach lokalnych. Zwraca komunikat błędu dla formularza (pusty, gdy się udało).
+func (h *AuthHandler) createAccount(r *http.Request, user *models.User, password string) string {
+	if h.fbClient == nil {
+		if h.accounts == nil {
+			return "System autoryzacji nie jest dostępny"
+		}
+		if err := h.accounts.Register(user, password); err != nil {
+			log.Printf("Błąd tworzenia konta lokalnego: %v", err)
+			return "Użytkownik z tym adresem email już istnieje"
+		}
+		return ""
+	}
+
+	// Utwórz użytkownika w Firebase Auth
+	params := (&auth.UserToCreate{}).
+		Email(user.Email).
+		Password(password).
+		DisplayName(user.FirstName + " " + user.LastName)
+
+	firebaseUser, err := h.fbClient.Auth.CreateUser(r.Context(), params)
+	if err != nil {
+		log.Printf("Błąd tworzenia użytkownika w Firebase Auth: %v", err)
+		return "Użytkownik z tym adresem email już istnieje lub hasło jest za słabe"
+	}
+	user.FirebaseUID = firebaseUser.UID
+
+	// Gdy biblioteka weryfikuje tożsamość nowych czytelników, konto czeka na zatwierdzenie przez personel
+	if settings, err := h.fbClient.Traced(r.Context()).GetSettings(); err != nil {
+		log.Printf("Błąd pobierania ustawień: %v", err)
+	} else {
+		user.PendingApproval = settings.RequireApproval
+	}
+
+	// Utwórz użytkownika w Firestore
+	if err := h.fbClient.Traced(r.Context()).CreateUser(user); err != nil {
+		log.Printf("Błąd tworzenia użytkownika w Firestore: %v", err)
+		// Próba usunięcia użytkownika z Auth jeśli nie udało się dodać do Firestore
+		h.fbClient.Auth.DeleteUser(r.Context(), firebaseUser.UID)
+		return "Błąd tworzenia konta użytkownika"
+	}
+	return ""
 }
 
 func (h *AuthHandler) renderLoginError(w http.ResponseWriter, errorMsg string) {
@@ -234,7 +300,8 @@ func (h *AuthHandler) renderRegisterError(w http.ResponseWriter, errorMsg string
 	}
 
 	data := map[string]interface{}{
-		"Error": errorMsg,
+		"Error":        errorMsg,
+		"ConsentTypes": models.ConsentTypes,
 	}
 
 	h.registerTemplate.Execute(w, data)
@@ -249,5 +316,5 @@ func (h *AuthHandler) HandleLogout(w http.ResponseWriter, r *http.Request) {
 
 	session.ClearSessionCookie(w)
 	log.Println("Użytkownik wylogowany")
-	http.Redirect(w, r, "/", http.StatusSeeOther)
+	basepath.Redirect(w, r, "/", http.StatusSeeOther)
 }
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"library-management-system/internal/models"
+	"library-management-system/internal/repository/memory"
+)
+
+func TestLoginWithMemoryAccounts(t *testing.T) {
+	store := memory.NewStore(nil)
+	for _, user := range []*models.User{
+		{Email: "admin@example.com", FirstName: "Anna", LastName: "Nowak", Role: models.RoleAdmin, IsActive: true},
+		{Email: "jan@example.com", FirstName: "Jan", LastName: "Kowalski", Role: models.RoleReader, IsActive: true},
+		{Email: "ewa@example.com", FirstName: "Ewa", LastName: "Wiśniewska", Role: models.RoleReader},
+	} {
+		if err := store.Register(user, "sekret1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := NewAuthHandler(nil, store)
+	h.loginTemplate = nil // Komunikat błędu trafia wtedy do treści odpowiedzi
+
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		want     string // Strona po zalogowaniu albo komunikat błędu
+	}{
+		{"administrator", "admin@example.com", "sekret1", "/staff"},
+		{"czytelnik", "jan@example.com", "sekret1", "/books"},
+		{"złe hasło", "jan@example.com", "sekret2", "Nieprawidłowy email lub hasło"},
+		{"konto dezaktywowane", "ewa@example.com", "sekret1", "Konto zostało dezaktywowane"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{"email": {tt.email}, "password": {tt.password}}
+			r := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+			h.HandleLogin(w, r)
+
+			if strings.HasPrefix(tt.want, "/") {
+				if w.Code != http.StatusSeeOther || w.Header().Get("Location") != tt.want {
+					t.Fatalf("kod %d, przekierowanie %q; oczekiwano %q", w.Code, w.Header().Get("Location"), tt.want)
+				}
+				if !strings.Contains(w.Header().Get("Set-Cookie"), "session_id=") {
+					t.Error("brak ciasteczka sesji po zalogowaniu")
+				}
+				return
+			}
+			if got := strings.TrimSpace(w.Body.String()); got != tt.want {
+				t.Errorf("odpowiedź %q, oczekiwano %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+	"library-management-system/internal/search"
+)
+
+// AuthorsHandler obsługuje kartotekę haseł wzorcowych autorów
+type AuthorsHandler struct {
+	template    *template.Template
+	fbClient    *firebase.Client
+	searchIndex *search.Index
+}
+
+// NewAuthorsHandler tworzy nowy handler haseł wzorcowych. Propozycje duplikatów
+// wylicza się z nazw autorów we współdzielonym indeksie wyszukiwania.
+func NewAuthorsHandler(fbClient *firebase.Client, searchIndex *search.Index) *AuthorsHandler {
+	tmpl, err := template.New("authors.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/authors.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/authors.html: %v", err)
+	}
+
+	return &AuthorsHandler{
+		template:    tmpl,
+		fbClient:    fbClient,
+		searchIndex: searchIndex,
+	}
+}
+
+// authorDuplicate to grupa zapisów z katalogu, które prawdopodobnie oznaczają
+// tego samego autora (to samo nazwisko i inicjał imienia)
+type authorDuplicate struct {
+	Names     []search.AuthorCount
+	Preferred string // Zapis z największą liczbą tytułów
+}
+
+// ListAuthors wyświetla hasła wzorcowe, propozycje scalenia i formularz hasła
+// (GET /staff/authors?edit=)
+func (h *AuthorsHandler) ListAuthors(w http.ResponseWriter, r *http.Request) {
+	data := NewPageData(r)
+
+	if h.fbClient != nil {
+		if editID := r.URL.Query().Get("edit"); editID != "" {
+			editing, err := h.fbClient.Traced(r.Context()).GetAuthor(editID)
+			if err != nil {
+				log.Printf("Błąd pobierania hasła autora do edycji: %v", err)
+				data["Error"] = "Nie znaleziono hasła autora do edycji"
+			} else {
+				data["Editing"] = editing
+			}
+		}
+
+		if r.URL.Query().Get("done") == "saved" {
+			data["Notice"] = "Hasło zostało zapisane. Ujednolicono zapis autora w książkach: " + r.URL.Query().Get("books")
+		}
+	}
+
+	h.renderAuthors(w, data)
+}
+
+// CreateAuthor zakłada hasło albo scala podane nazwy z istniejącym hasłem
+// (POST /staff/authors). Formularz propozycji scalenia wysyła nazwę preferowaną
+// i wszystkie zapisy z grupy jako warianty.
+func (h *AuthorsHandler) CreateAuthor(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	author := readAuthorForm(r, &models.Author{})
+
+	// Jeśli któraś z nazw ma już hasło, nowe nazwy trafiają do niego, a wybrana
+	// nazwa preferowana zastępuje dotychczasową
+	var existing *models.Author
+	var err error
+	for _, name := range append([]string{author.Name}, author.Variants...) {
+		existing, err = h.fbClient.Traced(r.Context()).FindAuthorByName(name)
+		if err != nil || existing != nil {
+			break
+		}
+	}
+
+	if err == nil && existing != nil {
+		existing.Variants = append(append(existing.Variants, existing.Name), author.Variants...)
+		existing.Name = author.Name
+		if author.Note != "" {
+			existing.Note = author.Note
+		}
+		author = existing
+		err = h.fbClient.Traced(r.Context()).UpdateAuthor(author)
+	} else if err == nil {
+		err = h.fbClient.Traced(r.Context()).CreateAuthor(author)
+	}
+	if err != nil {
+		h.authorFormError(w, r, author, err)
+		return
+	}
+
+	h.applyAuthor(w, r, author)
+}
+
+// UpdateAuthor zapisuje zmiany hasła (POST /staff/authors/{id}). Poprzednia
+// nazwa preferowana zostaje wariantem, więc książki z nią też są przepisywane.
+func (h *AuthorsHandler) UpdateAuthor(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	author, err := h.fbClient.Traced(r.Context()).GetAuthor(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("Błąd pobierania hasła autora: %v", err)
+		http.Error(w, "Nie znaleziono hasła autora", http.StatusNotFound)
+		return
+	}
+
+	previous := author.Name
+	readAuthorForm(r, author)
+	author.Variants = append(author.Variants, previous)
+
+	if err := h.fbClient.Traced(r.Context()).UpdateAuthor(author); err != nil {
+		h.authorFormError(w, r, author, err)
+		return
+	}
+
+	h.applyAuthor(w, r, author)
+}
+
+// DeleteAuthor usuwa hasło wzorcowe (POST /staff/authors/{id}/delete)
+func (h *AuthorsHandler) DeleteAuthor(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := h.fbClient.Traced(r.Context()).DeleteAuthor(chi.URLParam(r, "id")); err != nil {
+		log.Printf("Błąd usuwania hasła autora: %v", err)
+		http.Error(w, "Błąd usuwania hasła autora", http.StatusInternalServerError)
+		return
+	}
+
+	basepath.Redirect(w, r, "/staff/authors", http.StatusSeeOther)
+}
+
+// applyAuthor przepisuje książki na nazwę preferowaną zapisanego hasła
+func (h *AuthorsHandler) applyAuthor(w http.ResponseWriter, r *http.Request, author *models.Author) {
+	changed, err := h.fbClient.Traced(r.Context()).ApplyAuthor(author)
+	if changed > 0 {
+		h.searchIndex.Invalidate()
+		recordStaffActivity(h.fbClient, r, models.StaffActionCatalogEdit)
+	}
+	if err != nil {
+		log.Printf("Błąd ujednolicania autora %s: %v", author.Name, err)
+		h.authorFormError(w, r, author, err)
+		return
+	}
+
+	basepath.Redirect(w, r, "/staff/authors?done=saved&books="+strconv.Itoa(changed), http.StatusSeeOther)
+}
+
+// authorFormError wyświetla stronę ponownie z wpisanymi danymi i komunikatem błędu
+func (h *AuthorsHandler) authorFormError(w http.ResponseWriter, r *http.Request, author *models.Author, err error) {
+	data := NewPageData(r)
+	data["Editing"] = author
+	data["Error"] = "Nie udało się zapisać hasła autora: " + err.Error()
+	w.WriteHeader(http.StatusBadRequest)
+	h.renderAuthors(w, data)
+}
+
+// renderAuthors uzupełnia listę haseł i propozycje duplikatów, po czym renderuje stronę
+func (h *AuthorsHandler) renderAuthors(w http.ResponseWriter, data TemplateData) {
+	if h.template == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	if h.fbClient != nil {
+		authors, err := h.fbClient.ListAuthors()
+		if err != nil {
+			log.Printf("Błąd pobierania haseł autorów: %v", err)
+			data["Error"] = "Błąd pobierania haseł autorów z bazy danych"
+		}
+		data["Authors"] = authors
+
+		if err := h.searchIndex.Refresh(); err != nil {
+			log.Printf("Błąd budowania indeksu wyszukiwania: %v", err)
+		} else {
+			data["Duplicates"] = authorDuplicates(h.searchIndex.Browse().Authors())
+		}
+	}
+
+	if err := h.template.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania haseł autorów: %v", err)
+	}
+}
+
+// authorDuplicates grupuje zapisy autorów z katalogu po nazwisku i inicjale imienia,
+// np. "J.R.R. Tolkien" i "Tolkien, John Ronald Reuel"
+func authorDuplicates(authors []search.AuthorCount) []authorDuplicate {
+	groups := make(map[string][]search.AuthorCount)
+	var order []string
+	for _, author := range authors {
+		words := strings.Fields(search.Fold(models.AuthorNameKey(author.Name)))
+		if len(words) == 0 {
+			continue
+		}
+		key := words[len(words)-1]
+		if len(words) > 1 {
+			key += " " + string([]rune(words[0])[:1])
+		}
+		if groups[key] == nil {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], author)
+	}
+
+	var duplicates []authorDuplicate
+	for _, key := range order {
+		names := groups[key]
+		if len(names) < 2 {
+			continue
+		}
+		preferred := names[0]
+		for _, name := range names[1:] {
+			if name.Count > preferred.Count {
+				preferred = name
+			}
+		}
+		sort.Slice(names, func(i, j int) bool { return names[i].Name < names[j].Name })
+		duplicates = append(duplicates, authorDuplicate{Names: names, Preferred: preferred.Name})
+	}
+	return duplicates
+}
+
+// readAuthorForm przepisuje pola formularza do hasła. Warianty pochodzą z pola
+// tekstowego (jeden w wierszu) i z pól "variant" formularza scalania.
+func readAuthorForm(r *http.Request, author *models.Author) *models.Author {
+	author.Name = strings.TrimSpace(r.FormValue("name"))
+	author.Note = strings.TrimSpace(r.FormValue("note"))
+
+	author.Variants = append(strings.Split(r.FormValue("variants"), "\n"), r.Form["variant"]...)
+	return author
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/middleware"
+	"library-management-system/internal/models"
+)
+
+// BadgesHandler obsługuje katalog odznak personelu i rezygnację czytelnika z odznak
+type BadgesHandler struct {
+	staffTemplate *template.Template
+	fbClient      *firebase.Client
+}
+
+// NewBadgesHandler tworzy nowy handler odznak
+func NewBadgesHandler(fbClient *firebase.Client) *BadgesHandler {
+	staffTmpl, err := template.New("badges.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/badges.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/badges.html: %v", err)
+	}
+
+	return &BadgesHandler{
+		staffTemplate: staffTmpl,
+		fbClient:      fbClient,
+	}
+}
+
+// UpdateOptOut włącza lub wyłącza odznaki czytelnika (POST /user/badges)
+func (h *BadgesHandler) UpdateOptOut(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	session := middleware.GetSessionFromContext(r.Context())
+	if err := h.fbClient.Traced(r.Context()).SetBadgesOptOut(session.UserID, r.FormValue("opt_out") == "on"); err != nil {
+		log.Printf("Błąd zapisywania ustawień odznak %s: %v", session.UserID, err)
+		http.Error(w, "Nie udało się zapisać ustawień odznak", http.StatusInternalServerError)
+		return
+	}
+
+	basepath.Redirect(w, r, "/user#badges", http.StatusSeeOther)
+}
+
+// ShowBadges wyświetla katalog odznak (GET /staff/badges)
+func (h *BadgesHandler) ShowBadges(w http.ResponseWriter, r *http.Request) {
+	data := NewPageData(r)
+	if r.URL.Query().Get("done") == "saved" {
+		data["Notice"] = "Katalog odznak został zapisany"
+	}
+	h.render(w, data)
+}
+
+// CreateBadge dodaje odznakę do katalogu (POST /staff/badges)
+func (h *BadgesHandler) CreateBadge(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	badge := readBadgeForm(r, &models.Badge{})
+	if err := h.fbClient.Traced(r.Context()).CreateBadge(badge); err != nil {
+		h.renderError(w, r, badge, "Nie udało się dodać odznaki: "+err.Error())
+		return
+	}
+
+	basepath.Redirect(w, r, "/staff/badges?done=saved", http.StatusSeeOther)
+}
+
+// AddDefaultBadges dodaje do pustego katalogu podstawowy zestaw odznak
+// (POST /staff/badges/defaults)
+func (h *BadgesHandler) AddDefaultBadges(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	existing, err := h.fbClient.Traced(r.Context()).ListBadges()
+	if err != nil {
+		log.Printf("Błąd pobierania katalogu odznak: %v", err)
+		http.Error(w, "Błąd pobierania katalogu odznak", http.StatusInternalServerError)
+		return
+	}
+	if len(existing) > 0 {
+		basepath.Redirect(w, r, "/staff/badges", http.StatusSeeOther)
+		return
+	}
+
+	for _, badge := range models.DefaultBadges() {
+		if err := h.fbClient.Traced(r.Context()).CreateBadge(badge); err != nil {
+			log.Printf("Błąd dodawania odznaki %q: %v", badge.Name, err)
+			http.Error(w, "Nie udało się dodać odznak", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	basepath.Redirect(w, r, "/staff/badges?done=saved", http.StatusSeeOther)
+}
+
+// UpdateBadge zapisuje zmiany odznaki (POST /staff/badges/{id})
+func (h *BadgesHandler) UpdateBadge(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	badge, err := h.fbClient.Traced(r.Context()).GetBadge(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Nie znaleziono odznaki", http.StatusNotFound)
+		return
+	}
+
+	if err := h.fbClient.Traced(r.Context()).UpdateBadge(readBadgeForm(r, badge)); err != nil {
+		h.renderError(w, r, nil, "Nie udało się zapisać odznaki: "+err.Error())
+		return
+	}
+
+	basepath.Redirect(w, r, "/staff/badges?done=saved", http.StatusSeeOther)
+}
+
+// DeleteBadge usuwa odznakę z katalogu (POST /staff/badges/{id}/delete)
+func (h *BadgesHandler) DeleteBadge(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := h.fbClient.Traced(r.Context()).DeleteBadge(chi.URLParam(r, "id")); err != nil {
+		log.Printf("Błąd usuwania odznaki: %v", err)
+		http.Error(w, "Nie udało się usunąć odznaki", http.StatusInternalServerError)
+		return
+	}
+
+	basepath.Redirect(w, r, "/staff/badges?done=saved", http.StatusSeeOther)
+}
+
+func (h *BadgesHandler) renderError(w http.ResponseWriter, r *http.Request, form *models.Badge, message string) {
+	data := NewPageData(r)
+	data["Form"] = form
+	data["Error"] = message
+	w.WriteHeader(http.StatusBadRequest)
+	h.render(w, data)
+}
+
+func (h *BadgesHandler) render(w http.ResponseWriter, data TemplateData) {
+	if h.staffTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	if h.fbClient != nil {
+		badges, err := h.fbClient.ListBadges()
+		if err != nil {
+			log.Printf("Błąd pobierania katalogu odznak: %v", err)
+			data["Error"] = "Błąd pobierania odznak z bazy danych"
+		}
+		data["Badges"] = badges
+	}
+	data["Rules"] = models.BadgeRules
+
+	if err := h.staffTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania katalogu odznak: %v", err)
+	}
+}
+
+// readBadgeForm przepisuje pola formularza do odznaki
+func readBadgeForm(r *http.Request, badge *models.Badge) *models.Badge {
+	badge.Name = strings.TrimSpace(r.FormValue("name"))
+	badge.Description = strings.TrimSpace(r.FormValue("description"))
+	badge.Icon = strings.TrimSpace(r.FormValue("icon"))
+	badge.Rule = models.BadgeRule(r.FormValue("rule"))
+	badge.Threshold = formInt(r, "threshold")
+	badge.Active = r.FormValue("active") == "on"
+	return badge
+}
+
+// badgeView to odznaka z katalogu na dashboardzie czytelnika
+type badgeView struct {
+	*models.Badge
+	Earned *models.EarnedBadge // nil - odznaka jeszcze do zdobycia
+}
+
+// userBadges zestawia aktywne odznaki katalogu z odznakami czytelnika:
+// najpierw zdobyte, potem do zdobycia
+func userBadges(fbClient *firebase.Client, user *models.User) ([]badgeView, error) {
+	catalog, err := fbClient.ListBadges()
+	if err != nil {
+		return nil, err
+	}
+
+	earned := make(map[string]*models.EarnedBadge, len(user.Badges))
+	for i := range user.Badges {
+		earned[user.Badges[i].BadgeID] = &user.Badges[i]
+	}
+
+	var won, upcoming []badgeView
+	for _, badge := range catalog {
+		if !badge.Active {
+			continue
+		}
+		if e, ok := earned[badge.ID]; ok {
+			won = append(won, badgeView{Badge: badge, Earned: e})
+		} else {
+			upcoming = append(upcoming, badgeView{Badge: badge})
+		}
+	}
+	return append(won, upcoming...), nil
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/models"
+)
+
+// BookHistoryEvent to pojedyncze zdarzenie na osi czasu obiegu książki
+type BookHistoryEvent struct {
+	Date   time.Time
+	Kind   string // loan, return, reservation, withdrawal, created - do wyboru koloru znacznika
+	Title  string
+	Detail string
+}
+
+// BookCirculation to statystyki obiegu książki pomocne przy selekcji księgozbioru
+type BookCirculation struct {
+	Loans            int
+	LoansLastYear    int
+	Reservations     int
+	LastLoan         *time.Time
+	AverageLoanDays  int     // Średni czas wypożyczenia (tylko zwrócone)
+	LoansPerYear     float64 // Wypożyczenia na rok od dodania do katalogu (krótszy okres liczony jako rok)
+	LoansPerCopyYear float64 // To samo w przeliczeniu na egzemplarz
+	// Średni czas od rezerwacji do wypożyczenia (nil bez zrealizowanych rezerwacji)
+	HoldWait *HoldWait
+}
+
+// ShowBookHistory wyświetla pełną historię obiegu książki (GET /staff/catalog/{id}/history)
+func (h *CatalogHandler) ShowBookHistory(w http.ResponseWriter, r *http.Request) {
+	if h.historyTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	bookID := chi.URLParam(r, "id")
+	book, err := h.store.Books(r.Context()).GetBook(bookID)
+	if err != nil {
+		log.Printf("Błąd pobierania książki: %v", err)
+		http.Error(w, "Książka nie została znaleziona", http.StatusNotFound)
+		return
+	}
+
+	loans, err := h.store.Loans(r.Context()).GetBookLoans(bookID)
+	if err != nil {
+		log.Printf("Błąd pobierania wypożyczeń książki %s: %v", bookID, err)
+		http.Error(w, "Błąd pobierania historii", http.StatusInternalServerError)
+		return
+	}
+	reservations, err := h.store.Reservations(r.Context()).GetBookReservations(bookID)
+	if err != nil {
+		log.Printf("Błąd pobierania rezerwacji książki %s: %v", bookID, err)
+		http.Error(w, "Błąd pobierania historii", http.StatusInternalServerError)
+		return
+	}
+	withdrawals, err := h.store.Books(r.Context()).GetBookWithdrawals(bookID)
+	if err != nil {
+		log.Printf("Błąd pobierania wycofanych egzemplarzy książki %s: %v", bookID, err)
+		http.Error(w, "Błąd pobierania historii", http.StatusInternalServerError)
+		return
+	}
+
+	data := NewPageData(r)
+	data["Book"] = book
+	data["Stats"] = bookCirculation(book, loans, reservations, time.Now())
+	data["Events"] = bookHistoryEvents(book, loans, reservations, withdrawals)
+
+	if err := h.historyTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania historii książki: %v", err)
+	}
+}
+
+// bookCirculation liczy statystyki obiegu na podstawie wszystkich wypożyczeń książki
+func bookCirculation(book *models.Book, loans []*models.Loan, reservations []*models.Reservation, now time.Time) *BookCirculation {
+	stats := &BookCirculation{
+		Reservations: len(reservations),
+		HoldWait:     holdWaits(reservations)[book.ID],
+	}
+
+	since := book.CreatedAt
+	var returned, loanDays int
+	for _, loan := range loans {
+		if loan.Status == models.LoanStatusPendingPickup {
+			continue // Książka nie trafiła jeszcze do czytelnika
+		}
+		stats.Loans++
+		if loan.LoanDate.After(now.AddDate(-1, 0, 0)) {
+			stats.LoansLastYear++
+		}
+		if stats.LastLoan == nil || loan.LoanDate.After(*stats.LastLoan) {
+			date := loan.LoanDate
+			stats.LastLoan = &date
+		}
+		if since.IsZero() || loan.LoanDate.Before(since) {
+			since = loan.LoanDate // Książki zaimportowane z historią sprzed dodania do katalogu
+		}
+		if loan.ReturnDate != nil {
+			returned++
+			loanDays += int(loan.ReturnDate.Sub(loan.LoanDate).Hours() / 24)
+		}
+	}
+
+	if returned > 0 {
+		stats.AverageLoanDays = loanDays / returned
+	}
+
+	years := now.Sub(since).Hours() / 24 / 365
+	if since.IsZero() || years < 1 {
+		years = 1
+	}
+	stats.LoansPerYear = float64(stats.Loans) / years
+	if book.TotalCopies > 0 {
+		stats.LoansPerCopyYear = stats.LoansPerYear / float64(book.TotalCopies)
+	}
+
+	return stats
+}
+
+// bookHistoryEvents składa wypożyczenia, zwroty, rezerwacje i wycofania egzemplarzy
+// w jedną oś czasu (najnowsze pierwsze)
+func bookHistoryEvents(book *models.Book, loans []*models.Loan, reservations []*models.Reservation, withdrawals []*models.CopyWithdrawal) []BookHistoryEvent {
+	var events []BookHistoryEvent
+
+	if !book.CreatedAt.IsZero() {
+		events = append(events, BookHistoryEvent{Date: book.CreatedAt, Kind: "created", Title: "Dodano do katalogu"})
+	}
+
+	for _, loan := range loans {
+		if loan.Status == models.LoanStatusPendingPickup {
+			events = append(events, BookHistoryEvent{Date: loan.CreatedAt, Kind: "loan", Title: "Zamówienie do odbioru", Detail: loan.ReaderName()})
+			continue
+		}
+		events = append(events, BookHistoryEvent{
+			Date:   loan.LoanDate,
+			Kind:   "loan",
+			Title:  "Wypożyczenie",
+			Detail: loan.ReaderName() + ", termin zwrotu " + loan.DueDate.Format("2006-01-02"),
+		})
+		if loan.ReturnDate != nil {
+			detail := loan.ReaderName()
+			if loan.ReturnDate.After(loan.DueDate) {
+				detail += ", po terminie"
+			}
+			events = append(events, BookHistoryEvent{Date: *loan.ReturnDate, Kind: "return", Title: "Zwrot", Detail: detail})
+		}
+	}
+
+	for _, reservation := range reservations {
+		events = append(events, BookHistoryEvent{
+			Date:   reservation.ReservationDate,
+			Kind:   "reservation",
+			Title:  "Rezerwacja (" + reservationStatusLabel(reservation.Status) + ")",
+			Detail: reservation.UserName,
+		})
+	}
+
+	for _, withdrawal := range withdrawals {
+		detail := withdrawal.RecordedBy
+		if withdrawal.Notes != "" {
+			detail = withdrawal.Notes + " - " + detail
+		}
+		events = append(events, BookHistoryEvent{
+			Date:   withdrawal.CreatedAt,
+			Kind:   "withdrawal",
+			Title:  "Wycofano egzemplarze: " + withdrawal.ReasonLabel() + " (" + strconv.Itoa(withdrawal.Count) + " egz.)",
+			Detail: detail,
+		})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Date.After(events[j].Date)
+	})
+	return events
+}
+
+func reservationStatusLabel(status models.ReservationStatus) string {
+	switch status {
+	case models.ReservationStatusPending:
+		return "oczekująca"
+	case models.ReservationStatusReady:
+		return "gotowa do odbioru"
+	case models.ReservationStatusCompleted:
+		return "zrealizowana"
+	case models.ReservationStatusCancelled:
+		return "anulowana"
+	default:
+		return "wygasła"
+	}
+}
@@ -1,41 +1,44 @@
 package handlers
 
 import (
-	"encoding/json"
 	"html/template"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 
+	"library-management-system/internal/analytics"
+	"library-management-system/internal/basepath"
 	"library-management-system/internal/firebase"
 	"library-management-system/internal/middleware"
 	"library-management-system/internal/models"
+	"library-management-system/internal/repository"
+	"library-management-system/internal/search"
 )
 
 // BooksHandler obsługuje operacje na książkach
 type BooksHandler struct {
 	catalogTemplate *template.Template
 	detailTemplate  *template.Template
-	fbClient        *firebase.Client
+	store           repository.Store
+	fbClient        *firebase.Client // Funkcje spoza repozytoriów: ustawienia, komentarze, listy, subskrypcje
+	analytics       *analytics.Recorder
+	searchIndex     *search.Index
+	baseURL         string
 }
 
-// NewBooksHandler tworzy nowy handler dla książek
-func NewBooksHandler(fbClient *firebase.Client) *BooksHandler {
-	funcMap := template.FuncMap{
-		"sub": func(a, b int) int {
-			return a - b
-		},
-	}
-
-	catalogTmpl, err := template.ParseFiles("internal/templates/catalog.html")
+// NewBooksHandler tworzy nowy handler dla książek.
+// baseURL jest potrzebny do pełnych adresów w podglądach udostępnianych linków.
+func NewBooksHandler(store repository.Store, fbClient *firebase.Client, recorder *analytics.Recorder, searchIndex *search.Index, baseURL string) *BooksHandler {
+	catalogTmpl, err := template.New("catalog.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/catalog.html")
 	if err != nil {
 		log.Printf("Błąd ładowania szablonu catalog.html: %v", err)
 	}
 
-	detailTmpl, err := template.New("detail.html").Funcs(funcMap).ParseFiles("internal/templates/books/detail.html")
+	detailTmpl, err := template.New("detail.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/books/detail.html")
 	if err != nil {
 		log.Printf("Błąd ładowania szablonu detail.html: %v", err)
 	}
@@ -43,16 +46,19 @@ func NewBooksHandler(fbClient *firebase.Client) *BooksHandler {
 	return &BooksHandler{
 		catalogTemplate: catalogTmpl,
 		detailTemplate:  detailTmpl,
+		store:           store,
 		fbClient:        fbClient,
+		analytics:       recorder,
+		searchIndex:     searchIndex,
+		baseURL:         strings.TrimRight(baseURL, "/"),
 	}
 }
 
 // ListBooksHandler zwraca listę książek (GET /books)
 func (h *BooksHandler) ListBooksHandler(w http.ResponseWriter, r *http.Request) {
 	// Sprawdź czy Firebase jest zainicjalizowany
-	if firebase.GlobalClient == nil {
-		session := middleware.GetSessionFromContext(r.Context())
-		data := NewTemplateData(session)
+	if h.store == nil {
+		data := NewPageData(r)
 		data["Error"] = "Firebase nie został zainicjalizowany. Sprawdź konfigurację."
 		data["Books"] = nil
 		if h.catalogTemplate != nil {
@@ -64,35 +70,61 @@ func (h *BooksHandler) ListBooksHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Pobierz parametry wyszukiwania
-	search := r.URL.Query().Get("search")
+	rawQuery := r.URL.Query().Get("search")
 	title := r.URL.Query().Get("title")
 	author := r.URL.Query().Get("author")
 	isbn := r.URL.Query().Get("isbn")
 	category := r.URL.Query().Get("category")
 	availableOnly := r.URL.Query().Get("available") == "true"
 
+	// Wariant zapisu autora prowadzi do strony autora pod nazwą preferowaną
+	if author != "" && h.fbClient != nil {
+		record, err := h.fbClient.Traced(r.Context()).FindAuthorByName(author)
+		if err != nil {
+			log.Printf("Błąd wyszukiwania hasła autora %s: %v", author, err)
+		} else if record != nil && record.Name != author {
+			query := r.URL.Query()
+			query.Set("author", record.Name)
+			basepath.Redirect(w, r, "/books?"+query.Encode(), http.StatusFound)
+			return
+		}
+	}
+
+	// Katalog ładuje się stronami; kolejne strony (parametr after) doczytuje htmx przy przewijaniu
+	pageSize := models.DefaultSettings().CatalogPageSize
+	if h.fbClient != nil {
+		if settings, err := h.fbClient.Traced(r.Context()).GetSettings(); err == nil {
+			pageSize = settings.CatalogPageSize
+		}
+	}
+	after := r.URL.Query().Get("after")
+
 	var books []*models.Book
+	var next string
 	var err error
+	paged := false // Zapytanie Firestore zwróciło już jedną stronę
 
 	// Wykonaj odpowiednie zapytanie
-	// Proste wyszukiwanie po wszystkim
-	if search != "" {
-		books, err = firebase.GlobalClient.SearchBooks(search)
+	// Proste wyszukiwanie po wszystkim (z opcjonalnymi filtrami pole:wartość)
+	if query := search.ParseQuery(rawQuery); query.HasFilters() {
+		books, err = h.store.Books(r.Context()).ListBooks()
+		books = query.Filter(books)
+	} else if rawQuery != "" {
+		books, err = h.store.Books(r.Context()).SearchBooks(rawQuery)
 	} else if title != "" || author != "" || isbn != "" {
 		// Zaawansowane wyszukiwanie
-		books, err = firebase.GlobalClient.SearchBooksAdvanced(title, author, isbn)
-	} else if category != "" {
-		books, err = firebase.GlobalClient.GetBooksByCategory(category)
+		books, err = h.store.Books(r.Context()).SearchBooksAdvanced(title, author, isbn)
 	} else if availableOnly {
-		books, err = firebase.GlobalClient.GetAvailableBooks()
+		books, err = h.store.Books(r.Context()).GetAvailableBooks()
 	} else {
-		books, err = firebase.GlobalClient.ListBooks()
+		// Cały katalog i kategorie stronicuje zapytanie Firestore (kursor w after)
+		books, next, err = h.store.Books(r.Context()).ListBooksPage(category, pageSize, after)
+		paged = true
 	}
 
 	if err != nil {
 		log.Printf("Błąd pobierania książek: %v", err)
-		session := middleware.GetSessionFromContext(r.Context())
-		data := NewTemplateData(session)
+		data := NewPageData(r)
 		data["Error"] = "Błąd pobierania książek z bazy danych: " + err.Error()
 		data["Books"] = nil
 		if h.catalogTemplate != nil {
@@ -103,8 +135,22 @@ func (h *BooksHandler) ListBooksHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Wyniki wyszukiwania są w pamięci w całości - stronicowanie po pozycji (numer w after)
+	total := len(books)
+	if !paged {
+		books, next = pageSlice(books, pageSize, after)
+	}
+
+	// Statystyki wyszukiwań (bez ruchu personelu), tylko przy pierwszej stronie
+	session := middleware.GetSessionFromContext(r.Context())
+	if !isStaff(session) && after == "" {
+		if term := strings.TrimSpace(strings.Join([]string{rawQuery, title, author, isbn}, " ")); term != "" {
+			h.analytics.RecordSearch(term, total)
+		}
+	}
+
 	// Renderuj stronę z katalogiem
-	h.renderCatalogPage(w, r, books)
+	h.renderCatalogPage(w, r, books, next)
 }
 
 // ShowBookHandler wyświetla szczegóły książki (GET /books/{id})
@@ -115,13 +161,20 @@ func (h *BooksHandler) ShowBookHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	book, err := firebase.GlobalClient.GetBook(bookID)
+	book, err := h.store.Books(r.Context()).GetBook(bookID)
 	if err != nil {
 		log.Printf("Błąd pobierania książki: %v", err)
 		http.Error(w, "Książka nie została znaleziona", http.StatusNotFound)
 		return
 	}
 
+	if !isStaff(middleware.GetSessionFromContext(r.Context())) {
+		h.analytics.RecordBookView(book)
+		if r.URL.Query().Get("ref") == shareRef {
+			h.analytics.RecordShareVisit(book)
+		}
+	}
+
 	// TODO: Renderuj szablon szczegółów książki
 	h.renderBookDetails(w, r, book)
 }
@@ -135,51 +188,48 @@ func (h *BooksHandler) CreateBookHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Tylko admin może dodawać książki
-	if !user.IsAdmin() {
+	// Tylko personel może dodawać książki
+	if !user.IsStaff() {
 		http.Error(w, "Brak uprawnień", http.StatusForbidden)
 		return
 	}
 
-	// Parsuj dane z formularza lub JSON
-	var book models.Book
+	// Parsuj dane z formularza (klienci JSON korzystają z /api/v1/books)
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Błąd parsowania formularza", http.StatusBadRequest)
+		return
+	}
 
-	contentType := r.Header.Get("Content-Type")
-	if contentType == "application/json" {
-		if err := json.NewDecoder(r.Body).Decode(&book); err != nil {
-			http.Error(w, "Nieprawidłowe dane JSON", http.StatusBadRequest)
-			return
-		}
-	} else {
-		// Parsuj dane z formularza
-		if err := r.ParseForm(); err != nil {
-			http.Error(w, "Błąd parsowania formularza", http.StatusBadRequest)
-			return
-		}
+	book := models.Book{
+		ISBN:          r.FormValue("isbn"),
+		Title:         r.FormValue("title"),
+		Author:        r.FormValue("author"),
+		Publisher:     r.FormValue("publisher"),
+		Category:      r.FormValue("category"),
+		Description:   r.FormValue("description"),
+		ShelfLocation: r.FormValue("shelf_location"),
+		CallNumber:    r.FormValue("call_number"),
+		Series:        strings.TrimSpace(r.FormValue("series")),
+		CoverImageURL: r.FormValue("cover_image_url"),
+	}
 
-		book = models.Book{
-			ISBN:          r.FormValue("isbn"),
-			Title:         r.FormValue("title"),
-			Author:        r.FormValue("author"),
-			Publisher:     r.FormValue("publisher"),
-			Category:      r.FormValue("category"),
-			Description:   r.FormValue("description"),
-			ShelfLocation: r.FormValue("shelf_location"),
-			CoverImageURL: r.FormValue("cover_image_url"),
+	// Konwertuj wartości numeryczne
+	if pubYear := r.FormValue("publication_year"); pubYear != "" {
+		if year, err := strconv.Atoi(pubYear); err == nil {
+			book.PublicationYear = year
 		}
+	}
 
-		// Konwertuj wartości numeryczne
-		if pubYear := r.FormValue("publication_year"); pubYear != "" {
-			if year, err := strconv.Atoi(pubYear); err == nil {
-				book.PublicationYear = year
-			}
+	if total := r.FormValue("total_copies"); total != "" {
+		if copies, err := strconv.Atoi(total); err == nil {
+			book.TotalCopies = copies
+			book.AvailableCopies = copies // Początkowo wszystkie egzemplarze są dostępne
 		}
+	}
 
-		if total := r.FormValue("total_copies"); total != "" {
-			if copies, err := strconv.Atoi(total); err == nil {
-				book.TotalCopies = copies
-				book.AvailableCopies = copies // Początkowo wszystkie egzemplarze są dostępne
-			}
+	if volume := r.FormValue("series_volume"); volume != "" {
+		if number, err := strconv.Atoi(volume); err == nil {
+			book.SeriesVolume = number
 		}
 	}
 
@@ -190,7 +240,7 @@ func (h *BooksHandler) CreateBookHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Zapisz książkę
-	if err := firebase.GlobalClient.CreateBook(&book); err != nil {
+	if err := h.store.Books(r.Context()).CreateBook(&book); err != nil {
 		log.Printf("Błąd tworzenia książki: %v", err)
 		http.Error(w, "Błąd tworzenia książki", http.StatusInternalServerError)
 		return
@@ -201,14 +251,9 @@ func (h *BooksHandler) CreateBookHandler(w http.ResponseWriter, r *http.Request)
 		// Dla htmx - zwróć fragment HTML z nową książką
 		w.Header().Set("HX-Trigger", "bookCreated")
 		h.renderBookCard(w, &book)
-	} else if contentType == "application/json" {
-		// Dla JSON API - zwróć dane książki
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusCreated)
-		json.NewEncoder(w).Encode(book)
 	} else {
 		// Przekieruj na stronę książki
-		http.Redirect(w, r, "/books/"+book.ID, http.StatusSeeOther)
+		basepath.Redirect(w, r, "/books/"+book.ID, http.StatusSeeOther)
 	}
 }
 
@@ -227,72 +272,73 @@ func (h *BooksHandler) UpdateBookHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if !user.IsAdmin() {
+	if !user.IsStaff() {
 		http.Error(w, "Brak uprawnień", http.StatusForbidden)
 		return
 	}
 
 	// Pobierz istniejącą książkę
-	existingBook, err := firebase.GlobalClient.GetBook(bookID)
+	existingBook, err := h.store.Books(r.Context()).GetBook(bookID)
 	if err != nil {
 		http.Error(w, "Książka nie została znaleziona", http.StatusNotFound)
 		return
 	}
 
-	// Parsuj nowe dane
-	var book models.Book
+	// Parsuj dane z formularza (klienci JSON korzystają z /api/v1/books/{id})
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Błąd parsowania formularza", http.StatusBadRequest)
+		return
+	}
 
-	contentType := r.Header.Get("Content-Type")
-	if contentType == "application/json" {
-		if err := json.NewDecoder(r.Body).Decode(&book); err != nil {
-			http.Error(w, "Nieprawidłowe dane JSON", http.StatusBadRequest)
-			return
-		}
-	} else {
-		// Parsuj dane z formularza
-		if err := r.ParseForm(); err != nil {
-			http.Error(w, "Błąd parsowania formularza", http.StatusBadRequest)
-			return
-		}
+	book := *existingBook // Zachowaj istniejące dane
 
-		book = *existingBook // Zachowaj istniejące dane
+	// Aktualizuj tylko podane pola
+	if isbn := r.FormValue("isbn"); isbn != "" {
+		book.ISBN = isbn
+	}
+	if title := r.FormValue("title"); title != "" {
+		book.Title = title
+	}
+	if author := r.FormValue("author"); author != "" {
+		book.Author = author
+	}
+	if publisher := r.FormValue("publisher"); publisher != "" {
+		book.Publisher = publisher
+	}
+	if category := r.FormValue("category"); category != "" {
+		book.Category = category
+	}
+	if description := r.FormValue("description"); description != "" {
+		book.Description = description
+	}
+	if shelfLocation := r.FormValue("shelf_location"); shelfLocation != "" {
+		book.ShelfLocation = shelfLocation
+	}
+	if callNumber := r.FormValue("call_number"); callNumber != "" {
+		book.CallNumber = callNumber
+	}
+	if series := strings.TrimSpace(r.FormValue("series")); series != "" {
+		book.Series = series
+	}
+	if coverImage := r.FormValue("cover_image_url"); coverImage != "" {
+		book.CoverImageURL = coverImage
+	}
 
-		// Aktualizuj tylko podane pola
-		if isbn := r.FormValue("isbn"); isbn != "" {
-			book.ISBN = isbn
-		}
-		if title := r.FormValue("title"); title != "" {
-			book.Title = title
-		}
-		if author := r.FormValue("author"); author != "" {
-			book.Author = author
-		}
-		if publisher := r.FormValue("publisher"); publisher != "" {
-			book.Publisher = publisher
-		}
-		if category := r.FormValue("category"); category != "" {
-			book.Category = category
-		}
-		if description := r.FormValue("description"); description != "" {
-			book.Description = description
-		}
-		if shelfLocation := r.FormValue("shelf_location"); shelfLocation != "" {
-			book.ShelfLocation = shelfLocation
-		}
-		if coverImage := r.FormValue("cover_image_url"); coverImage != "" {
-			book.CoverImageURL = coverImage
+	if pubYear := r.FormValue("publication_year"); pubYear != "" {
+		if year, err := strconv.Atoi(pubYear); err == nil {
+			book.PublicationYear = year
 		}
+	}
 
-		if pubYear := r.FormValue("publication_year"); pubYear != "" {
-			if year, err := strconv.Atoi(pubYear); err == nil {
-				book.PublicationYear = year
-			}
+	if total := r.FormValue("total_copies"); total != "" {
+		if copies, err := strconv.Atoi(total); err == nil {
+			book.TotalCopies = copies
 		}
+	}
 
-		if total := r.FormValue("total_copies"); total != "" {
-			if copies, err := strconv.Atoi(total); err == nil {
-				book.TotalCopies = copies
-			}
+	if volume := r.FormValue("series_volume"); volume != "" {
+		if number, err := strconv.Atoi(volume); err == nil {
+			book.SeriesVolume = number
 		}
 	}
 
@@ -300,7 +346,7 @@ func (h *BooksHandler) UpdateBookHandler(w http.ResponseWriter, r *http.Request)
 	book.CreatedAt = existingBook.CreatedAt
 
 	// Aktualizuj książkę
-	if err := firebase.GlobalClient.UpdateBook(bookID, &book); err != nil {
+	if err := h.store.Books(r.Context()).UpdateBook(bookID, &book); err != nil {
 		log.Printf("Błąd aktualizacji książki: %v", err)
 		http.Error(w, "Błąd aktualizacji książki", http.StatusInternalServerError)
 		return
@@ -310,11 +356,8 @@ func (h *BooksHandler) UpdateBookHandler(w http.ResponseWriter, r *http.Request)
 	if r.Header.Get("HX-Request") == "true" {
 		w.Header().Set("HX-Trigger", "bookUpdated")
 		h.renderBookCard(w, &book)
-	} else if contentType == "application/json" {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(book)
 	} else {
-		http.Redirect(w, r, "/books/"+book.ID, http.StatusSeeOther)
+		basepath.Redirect(w, r, "/books/"+book.ID, http.StatusSeeOther)
 	}
 }
 
@@ -333,13 +376,13 @@ func (h *BooksHandler) DeleteBookHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if !user.IsAdmin() {
-		http.Error(w, "Brak uprawnień - tylko administrator może usuwać książki", http.StatusForbidden)
+	if !user.IsStaff() {
+		http.Error(w, "Brak uprawnień - tylko personel może usuwać książki", http.StatusForbidden)
 		return
 	}
 
 	// Usuń książkę
-	if err := firebase.GlobalClient.DeleteBook(bookID); err != nil {
+	if err := h.store.Books(r.Context()).DeleteBook(bookID); err != nil {
 		log.Printf("Błąd usuwania książki: %v", err)
 		http.Error(w, "Błąd usuwania książki", http.StatusInternalServerError)
 		return
@@ -356,11 +399,11 @@ func (h *BooksHandler) DeleteBookHandler(w http.ResponseWriter, r *http.Request)
 
 // Funkcje pomocnicze do renderowania
 
+// renderBooksFragment renderuje same karty książek (fragment HTML dla htmx).
+// Dane w JSON udostępnia wyłącznie API (/api/v1/books).
 func (h *BooksHandler) renderBooksFragment(w http.ResponseWriter, books []*models.Book) {
-	// Renderuj tylko fragment HTML z listą książek dla htmx
 	if h.catalogTemplate == nil {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(books)
+		http.Error(w, "Szablon katalogu nie został załadowany", http.StatusInternalServerError)
 		return
 	}
 
@@ -368,25 +411,55 @@ func (h *BooksHandler) renderBooksFragment(w http.ResponseWriter, books []*model
 		"Books": books,
 	}
 
-	if err := h.catalogTemplate.Execute(w, data); err != nil {
+	if err := h.catalogTemplate.ExecuteTemplate(w, "books-page", data); err != nil {
 		log.Printf("Błąd renderowania fragmentu książek: %v", err)
 		http.Error(w, "Błąd renderowania", http.StatusInternalServerError)
 	}
 }
 
-func (h *BooksHandler) renderCatalogPage(w http.ResponseWriter, r *http.Request, books []*models.Book) {
+// pageSlice zwraca stronę wyników od pozycji after (liczba) i pozycję następnej strony
+// (pustą na ostatniej stronie)
+func pageSlice(books []*models.Book, size int, after string) ([]*models.Book, string) {
+	start, _ := strconv.Atoi(after)
+	if start < 0 || start > len(books) {
+		start = len(books)
+	}
+	end := start + size
+	if end >= len(books) {
+		return books[start:], ""
+	}
+	return books[start:end], strconv.Itoa(end)
+}
+
+func (h *BooksHandler) renderCatalogPage(w http.ResponseWriter, r *http.Request, books []*models.Book, next string) {
 	if h.catalogTemplate == nil {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(books)
+		http.Error(w, "Szablon katalogu nie został załadowany", http.StatusInternalServerError)
 		return
 	}
 
 	session := middleware.GetSessionFromContext(r.Context())
-	data := NewTemplateData(session)
+	data := NewPageData(r)
 	data["Books"] = books
 	data["Error"] = nil
 	data["SearchQuery"] = r.URL.Query().Get("search")
 
+	// Adres następnej strony dla „Pokaż więcej” i doczytywania przy przewijaniu
+	if next != "" {
+		query := r.URL.Query()
+		query.Set("after", next)
+		data["NextURL"] = basepath.URL("/books?" + query.Encode())
+	}
+
+	// Kolejne strony (htmx) to same karty książek dopisywane na koniec listy
+	w.Header().Add("Vary", "HX-Request")
+	if r.Header.Get("HX-Request") == "true" && r.URL.Query().Get("after") != "" {
+		if err := h.catalogTemplate.ExecuteTemplate(w, "books-page", data); err != nil {
+			log.Printf("Błąd renderowania strony katalogu: %v", err)
+			http.Error(w, "Błąd renderowania", http.StatusInternalServerError)
+		}
+		return
+	}
+
 	// Parametry zaawansowanego wyszukiwania
 	searchParams := map[string]string{
 		"Title":    r.URL.Query().Get("title"),
@@ -396,6 +469,33 @@ func (h *BooksHandler) renderCatalogPage(w http.ResponseWriter, r *http.Request,
 	}
 	data["Search"] = searchParams
 
+	// Brak wyników - podpowiedz podobne tytuły i autorów
+	if len(books) == 0 && data["SearchQuery"] != "" && r.URL.Query().Get("after") == "" {
+		if err := h.searchIndex.Refresh(); err != nil {
+			log.Printf("Błąd budowania indeksu wyszukiwania: %v", err)
+		} else {
+			data["Suggestions"] = h.searchIndex.Suggest(r.URL.Query().Get("search"), 5)
+		}
+	}
+
+	// Przy filtrowaniu po samym autorze lub kategorii można obserwować nowe tytuły
+	if session != nil && h.fbClient != nil {
+		var subType models.SubscriptionType
+		var value string
+		switch {
+		case searchParams["Author"] != "" && searchParams["Title"] == "" && searchParams["ISBN"] == "":
+			subType, value = models.SubscriptionAuthor, searchParams["Author"]
+		case searchParams["Category"] != "":
+			subType, value = models.SubscriptionCategory, searchParams["Category"]
+		}
+		if value != "" {
+			data["SubscriptionType"] = string(subType)
+			data["SubscriptionValue"] = value
+			data["Subscribed"] = h.isSubscribed(session.UserID, subType, value)
+			data["CurrentURL"] = r.URL.RequestURI()
+		}
+	}
+
 	if err := h.catalogTemplate.Execute(w, data); err != nil {
 		log.Printf("Błąd renderowania katalogu: %v", err)
 		http.Error(w, "Błąd renderowania", http.StatusInternalServerError)
@@ -404,47 +504,129 @@ func (h *BooksHandler) renderCatalogPage(w http.ResponseWriter, r *http.Request,
 
 func (h *BooksHandler) renderBookDetails(w http.ResponseWriter, r *http.Request, book *models.Book) {
 	if h.detailTemplate == nil {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(book)
+		http.Error(w, "Szablon szczegółów książki nie został załadowany", http.StatusInternalServerError)
 		return
 	}
 
 	session := middleware.GetSessionFromContext(r.Context())
-	data := NewTemplateData(session)
+	data := NewPageData(r)
 	data["Book"] = book
 
+	// Podgląd linku w komunikatorach (Open Graph) i przyciski udostępniania.
+	// Okładka w PNG, bo podglądy nie wyświetlają okładek zastępczych w SVG.
+	data["CanonicalURL"] = h.baseURL + "/books/" + book.ID
+	data["ShareURL"] = h.bookShareURL(book)
+	data["ShareDescription"] = shareDescription(book)
+	data["ShareImage"] = h.baseURL + "/covers/" + book.ID + "?w=640&format=png"
+	data["ShareChannels"] = shareChannels
+
 	// Sprawdź czy użytkownik może wypożyczyć
-	if session != nil && h.fbClient != nil {
-		user, err := h.fbClient.GetUser(session.UserID)
+	if session != nil && h.store != nil {
+		user, err := h.store.Users(r.Context()).GetUser(session.UserID)
 		if err == nil {
 			data["CanBorrow"] = user.CanBorrow()
+			data["CommentBanned"] = user.CommentBanned
 			if !user.CanBorrow() {
 				if user.CurrentLoans >= user.MaxLoans {
 					data["BorrowError"] = "Osiągnięto maksymalny limit wypożyczeń"
 				} else if !user.IsActive {
 					data["BorrowError"] = "Konto nieaktywne - skontaktuj się z biblioteką"
+				} else if user.PendingApproval {
+					data["BorrowError"] = "Konto czeka na zatwierdzenie - zgłoś się do biblioteki z dokumentem tożsamości"
 				}
 			}
 		}
 	}
 
+	// Inne wydania - czytelnik może wypożyczyć dostępne albo zastrzec rezerwację do tego wydania
+	if h.store != nil {
+		editions, err := h.store.Books(r.Context()).GetBookEditions(book)
+		if err != nil {
+			log.Printf("Błąd pobierania wydań książki %s: %v", book.ID, err)
+		}
+		data["Editions"] = editions
+	}
+
+	// Seria: numer tomu, sąsiednie tomy i skrót do rezerwacji następnego
+	if book.Series != "" && book.SeriesVolume > 0 && h.store != nil {
+		volumes, err := h.store.Books(r.Context()).GetBooksBySeries(book.Series)
+		if err != nil {
+			log.Printf("Błąd pobierania serii %s: %v", book.Series, err)
+		}
+		data["SeriesSlug"] = search.Slugify(book.Series)
+		data["SeriesTotal"] = seriesTotal(volumes)
+		data["PreviousVolume"] = seriesVolume(volumes, book.SeriesVolume-1)
+		data["NextVolume"] = seriesVolume(volumes, book.SeriesVolume+1)
+	}
+
+	// Miejsca odbioru do wyboru przy rezerwacji
+	if session != nil && h.fbClient != nil {
+		if settings, err := h.fbClient.Traced(r.Context()).GetSettings(); err == nil {
+			data["PickupLocations"] = settings.PickupLocations
+		}
+	}
+
+	// Stan subskrypcji nowych tytułów autora i kategorii
+	if session != nil && h.fbClient != nil {
+		data["AuthorSubscribed"] = h.isSubscribed(session.UserID, models.SubscriptionAuthor, book.Author)
+		data["CategorySubscribed"] = h.isSubscribed(session.UserID, models.SubscriptionCategory, book.Category)
+	}
+
+	// Listy czytelnika do dodania książki; ?listed= wskazuje listę, na którą właśnie trafiła
+	if session != nil {
+		lists, err := h.store.ReadingLists(r.Context()).GetUserReadingLists(session.UserID)
+		if err != nil {
+			log.Printf("Błąd pobierania list czytelnika: %v", err)
+		}
+		data["UserLists"] = lists
+		for _, list := range lists {
+			if list.ID == r.URL.Query().Get("listed") {
+				data["ListedOn"] = list
+			}
+		}
+	}
+
+	// Dyskusja: ?comment=pending potwierdza komentarz wstrzymany do moderacji,
+	// a ?comment=reported przyjęcie zgłoszenia
+	data["CommentNotice"] = r.URL.Query().Get("comment")
+	if h.fbClient != nil {
+		comments, err := h.fbClient.Traced(r.Context()).GetBookComments(book.ID)
+		if err != nil {
+			log.Printf("Błąd pobierania komentarzy książki %s: %v", book.ID, err)
+		}
+		data["Comments"] = commentThreads(comments, session)
+		data["CommentCount"] = publishedComments(comments)
+	}
+
 	if err := h.detailTemplate.Execute(w, data); err != nil {
 		log.Printf("Błąd renderowania szczegółów książki: %v", err)
 		http.Error(w, "Błąd renderowania strony", http.StatusInternalServerError)
 	}
 }
 
+// isSubscribed sprawdza czy użytkownik obserwuje autora lub kategorię
+func (h *BooksHandler) isSubscribed(userID string, subType models.SubscriptionType, value string) bool {
+	if value == "" {
+		return false
+	}
+	sub, err := h.fbClient.FindUserSubscription(userID, models.SubscriptionKey(subType, value))
+	if err != nil {
+		log.Printf("Błąd sprawdzania subskrypcji: %v", err)
+		return false
+	}
+	return sub != nil
+}
+
+// renderBookCard renderuje kartę dodanej lub zmienionej książki (odpowiedź htmx)
 func (h *BooksHandler) renderBookCard(w http.ResponseWriter, book *models.Book) {
-	// TODO: Renderuj kartę książki dla htmx
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(book)
+	h.renderBooksFragment(w, []*models.Book{book})
 }
 
 // SearchBooksHandler obsługuje wyszukiwanie książek (GET /books/search)
 func (h *BooksHandler) SearchBooksHandler(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("q")
 
-	if firebase.GlobalClient == nil {
+	if h.store == nil {
 		http.Error(w, "Firebase nie został zainicjalizowany", http.StatusInternalServerError)
 		return
 	}
@@ -453,9 +635,9 @@ func (h *BooksHandler) SearchBooksHandler(w http.ResponseWriter, r *http.Request
 	var err error
 
 	if query != "" {
-		books, err = firebase.GlobalClient.SearchBooks(query)
+		books, err = h.store.Books(r.Context()).SearchBooks(query)
 	} else {
-		books, err = firebase.GlobalClient.ListBooks()
+		books, err = h.store.Books(r.Context()).ListBooks()
 	}
 
 	if err != nil {
@@ -481,13 +663,13 @@ func (h *BooksHandler) BorrowBook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if h.fbClient == nil {
+	if h.store == nil {
 		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
 		return
 	}
 
 	// Pobierz użytkownika
-	user, err := h.fbClient.GetUser(session.UserID)
+	user, err := h.store.Users(r.Context()).GetUser(session.UserID)
 	if err != nil {
 		log.Printf("Błąd pobierania użytkownika: %v", err)
 		http.Error(w, "Błąd pobierania danych użytkownika", http.StatusInternalServerError)
@@ -501,13 +683,15 @@ func (h *BooksHandler) BorrowBook(w http.ResponseWriter, r *http.Request) {
 			errMsg = "Osiągnięto maksymalny limit wypożyczeń"
 		} else if !user.IsActive {
 			errMsg = "Konto nieaktywne - skontaktuj się z biblioteką"
+		} else if user.PendingApproval {
+			errMsg = "Konto czeka na zatwierdzenie - zgłoś się do biblioteki z dokumentem tożsamości"
 		}
 		w.Write([]byte(`<div class="bg-red-100 border border-red-400 text-red-700 px-4 py-3 rounded text-sm">` + errMsg + `</div>`))
 		return
 	}
 
 	// Pobierz książkę
-	book, err := h.fbClient.GetBook(bookID)
+	book, err := h.store.Books(r.Context()).GetBook(bookID)
 	if err != nil {
 		log.Printf("Błąd pobierania książki: %v", err)
 		http.Error(w, "Książka nie została znaleziona", http.StatusNotFound)
@@ -528,20 +712,20 @@ func (h *BooksHandler) BorrowBook(w http.ResponseWriter, r *http.Request) {
 		UserName:  user.FirstName + " " + user.LastName, // Denormalizacja
 	}
 
-	if err := h.fbClient.CreateLoan(loan); err != nil {
+	if err := h.store.Loans(r.Context()).CreateLoan(loan); err != nil {
 		log.Printf("Błąd tworzenia wypożyczenia: %v", err)
 		http.Error(w, "Błąd wypożyczania książki", http.StatusInternalServerError)
 		return
 	}
 
 	// Zmniejsz dostępne egzemplarze
-	if err := h.fbClient.UpdateBookAvailability(bookID, false); err != nil {
+	if err := h.store.Books(r.Context()).UpdateBookAvailability(bookID, false); err != nil {
 		log.Printf("Błąd aktualizacji dostępności: %v", err)
 		// Wypożyczenie zostało utworzone, ale nie udało się zaktualizować dostępności
 	}
 
 	// Zwiększ licznik wypożyczeń użytkownika
-	if err := h.fbClient.UpdateUserLoansCount(session.UserID, true); err != nil {
+	if err := h.store.Users(r.Context()).UpdateUserLoansCount(session.UserID, true); err != nil {
 		log.Printf("Błąd aktualizacji licznika wypożyczeń: %v", err)
 	}
 
@@ -551,7 +735,7 @@ func (h *BooksHandler) BorrowBook(w http.ResponseWriter, r *http.Request) {
 			<p class="font-bold">Zamówienie utworzone!</p>
 			<p class="text-2xl font-mono font-bold my-2">Kod odbioru: ` + loan.PickupCode + `</p>
 			<p>Podaj ten kod w bibliotece, aby odebrać książkę.</p>
-			<a href="/user" class="text-green-800 underline mt-2 inline-block">Zobacz moje wypożyczenia</a>
+			<a href="` + basepath.URL("/user") + `" class="text-green-800 underline mt-2 inline-block">Zobacz moje wypożyczenia</a>
 		</div>
 	`))
 }
@@ -570,13 +754,13 @@ func (h *BooksHandler) ReserveBook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if h.fbClient == nil {
+	if h.store == nil {
 		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
 		return
 	}
 
 	// Pobierz użytkownika
-	user, err := h.fbClient.GetUser(session.UserID)
+	user, err := h.store.Users(r.Context()).GetUser(session.UserID)
 	if err != nil {
 		log.Printf("Błąd pobierania użytkownika: %v", err)
 		http.Error(w, "Błąd pobierania danych użytkownika", http.StatusInternalServerError)
@@ -588,27 +772,62 @@ func (h *BooksHandler) ReserveBook(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(`<div class="bg-red-100 border border-red-400 text-red-700 px-4 py-3 rounded text-sm">Konto nieaktywne - skontaktuj się z biblioteką</div>`))
 		return
 	}
+	if user.PendingApproval {
+		w.Write([]byte(`<div class="bg-red-100 border border-red-400 text-red-700 px-4 py-3 rounded text-sm">Konto czeka na zatwierdzenie - zgłoś się do biblioteki z dokumentem tożsamości</div>`))
+		return
+	}
 
-	// Sprawdź czy użytkownik nie ma już rezerwacji tej książki
-	existingReservations, err := h.fbClient.GetUserReservations(session.UserID)
+	book, err := h.store.Books(r.Context()).GetBook(bookID)
+	if err != nil {
+		log.Printf("Błąd pobierania książki: %v", err)
+		http.Error(w, "Książka nie została znaleziona", http.StatusNotFound)
+		return
+	}
+
+	// Sprawdź czy użytkownik nie ma już rezerwacji tej książki (także innego wydania lub rekordu z tym samym ISBN)
+	workKey, isbnKey := search.WorkKey(book), search.ISBNKey(book.ISBN)
+	existingReservations, err := h.store.Reservations(r.Context()).GetUserReservations(session.UserID)
 	if err == nil {
 		for _, res := range existingReservations {
-			if res.BookID == bookID && (res.Status == models.ReservationStatusPending || res.Status == models.ReservationStatusReady) {
+			sameBook := res.BookID == bookID || res.WorkKey == workKey || (isbnKey != "" && res.ISBNKey == isbnKey)
+			if sameBook && (res.Status == models.ReservationStatusPending || res.Status == models.ReservationStatusReady) {
 				w.Write([]byte(`<div class="bg-yellow-100 border border-yellow-400 text-yellow-700 px-4 py-3 rounded text-sm">Masz już aktywną rezerwację tej książki</div>`))
 				return
 			}
 		}
 	}
 
-	// Utwórz rezerwację
-	reservation := &models.Reservation{
-		BookID:     bookID,
-		UserID:     session.UserID,
-		Status:     models.ReservationStatusPending,
-		ExpiryDate: time.Now().AddDate(0, 0, 7), // 7 dni na odbiór gdy będzie dostępna
+	// Miejsce odbioru musi być jednym z miejsc z ustawień biblioteki
+	pickupLocation := r.FormValue("pickup_location")
+	settings := models.DefaultSettings()
+	if h.fbClient != nil {
+		settings, err = h.fbClient.Traced(r.Context()).GetSettings()
+		if err != nil {
+			log.Printf("Błąd pobierania ustawień: %v", err)
+			http.Error(w, "Błąd rezerwacji książki", http.StatusInternalServerError)
+			return
+		}
+	}
+	if len(settings.PickupLocations) > 0 && !settings.HasPickupLocation(pickupLocation) {
+		w.Write([]byte(`<div class="bg-red-100 border border-red-400 text-red-700 px-4 py-3 rounded text-sm">Wybierz miejsce odbioru</div>`))
+		return
 	}
 
-	if err := h.fbClient.CreateReservation(reservation); err != nil {
+	// Utwórz rezerwację
+	reservation := &models.Reservation{
+		BookID:         bookID,
+		UserID:         session.UserID,
+		BookTitle:      book.Title,      // Denormalizacja
+		UserName:       user.FullName(), // Denormalizacja
+		Status:         models.ReservationStatusPending,
+		ExpiryDate:     time.Now().AddDate(0, 0, 7), // 7 dni na odbiór gdy będzie dostępna
+		PickupLocation: pickupLocation,
+		WorkKey:        workKey,
+		ISBNKey:        isbnKey,
+		EditionOnly:    r.FormValue("edition_only") == "on",
+	}
+
+	if err := h.store.Reservations(r.Context()).CreateReservation(reservation); err != nil {
 		log.Printf("Błąd tworzenia rezerwacji: %v", err)
 		http.Error(w, "Błąd rezerwacji książki", http.StatusInternalServerError)
 		return
@@ -618,8 +837,16 @@ func (h *BooksHandler) ReserveBook(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(`
 		<div class="bg-green-100 border border-green-400 text-green-700 px-4 py-3 rounded text-sm">
 			<p class="font-bold">Książka zarezerwowana!</p>
-			<p>Powiadomimy Cię, gdy będzie dostępna</p>
-			<a href="/user/reservations" class="text-green-800 underline mt-2 inline-block">Zobacz moje rezerwacje</a>
+			<p>Powiadomimy Cię, gdy będzie dostępna` + pickupLocationNote(pickupLocation) + `</p>
+			<a href="` + basepath.URL("/user/reservations") + `" class="text-green-800 underline mt-2 inline-block">Zobacz moje rezerwacje</a>
 		</div>
 	`))
 }
+
+// pickupLocationNote zwraca dopisek z miejscem odbioru do komunikatu o rezerwacji
+func pickupLocationNote(location string) string {
+	if location == "" {
+		return ""
+	}
+	return " - odbiór: " + template.HTMLEscapeString(location)
+}
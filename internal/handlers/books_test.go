@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/middleware"
+	"library-management-system/internal/models"
+	"library-management-system/internal/repository/memory"
+	"library-management-system/internal/session"
+)
+
+// reserve wysyła rezerwację książki bookID przez handler z sesją czytelnika i zwraca treść odpowiedzi
+func reserve(t *testing.T, h *BooksHandler, user *models.User, bookID string) string {
+	t.Helper()
+
+	sess, err := session.GetManager().CreateSession(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest(http.MethodPost, "/books/"+bookID+"/reserve", nil)
+	r.AddCookie(&http.Cookie{Name: "session_id", Value: sess.ID})
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("id", bookID)
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeCtx))
+
+	w := httptest.NewRecorder()
+	middleware.SessionMiddleware(http.HandlerFunc(h.ReserveBook)).ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("rezerwacja %s: kod %d: %s", bookID, w.Code, w.Body.String())
+	}
+	return w.Body.String()
+}
+
+func TestReserveBookWithMemoryStore(t *testing.T) {
+	store := memory.NewStore([]models.Book{
+		{ID: "lodz", ISBN: "83-240-1234-X", Title: "Łódź", Author: "Julian Tuwim", TotalCopies: 1},
+		{ID: "lodz-dup", ISBN: "978-83-240-1234-3", Title: "Lodz", Author: "J. Tuwim", TotalCopies: 1},
+		{ID: "wiersze", ISBN: "9788373271234", Title: "Wiersze", Author: "Julian Tuwim", TotalCopies: 1},
+	})
+	user := &models.User{ID: "u1", FirstName: "Anna", LastName: "Nowak", Role: models.RoleReader, IsActive: true}
+	if err := store.UpdateUser(user.ID, user); err != nil {
+		t.Fatal(err)
+	}
+	h := &BooksHandler{store: store}
+
+	if body := reserve(t, h, user, "lodz"); !strings.Contains(body, "Książka zarezerwowana") {
+		t.Fatalf("pierwsza rezerwacja nie powiodła się: %s", body)
+	}
+
+	reservations, err := store.GetUserReservations(user.ID)
+	if err != nil || len(reservations) != 1 {
+		t.Fatalf("rezerwacje czytelnika: %v, %v; oczekiwano jednej", reservations, err)
+	}
+	if got := reservations[0]; got.ISBNKey != "9788324012343" || got.WorkKey != "julian tuwim|lodz" {
+		t.Errorf("klucze rezerwacji: ISBN %q, utwór %q", got.ISBNKey, got.WorkKey)
+	}
+
+	// Zdublowany rekord z tym samym ISBN to ta sama książka
+	if body := reserve(t, h, user, "lodz-dup"); !strings.Contains(body, "Masz już aktywną rezerwację") {
+		t.Errorf("rezerwacja zdublowanego rekordu powinna zostać odrzucona: %s", body)
+	}
+	if body := reserve(t, h, user, "wiersze"); !strings.Contains(body, "Książka zarezerwowana") {
+		t.Errorf("rezerwacja innego utworu nie powiodła się: %s", body)
+	}
+
+	// Zwrócony egzemplarz zdublowanego rekordu trafia do czytelnika z kolejki
+	next, err := store.GetNextReservation("lodz-dup")
+	if err != nil || next == nil || next.BookID != "lodz" {
+		t.Errorf("GetNextReservation(lodz-dup) = %+v, %v; oczekiwano rezerwacji książki lodz", next, err)
+	}
+}
@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+	"library-management-system/internal/repository"
+	"library-management-system/internal/search"
+)
+
+// BrowseHandler obsługuje przeglądanie katalogu według autorów, kategorii, serii i klasyfikacji
+type BrowseHandler struct {
+	authorsTemplate        *template.Template
+	categoriesTemplate     *template.Template
+	seriesTemplate         *template.Template
+	classificationTemplate *template.Template
+	store                  repository.Store
+	searchIndex            *search.Index
+}
+
+// NewBrowseHandler tworzy nowy handler przeglądania katalogu.
+// Liczniki autorów, kategorii, serii i klas pochodzą ze współdzielonego indeksu wyszukiwania,
+// a fbClient służy tylko funkcjom szablonów (nazwa biblioteki, waluta).
+func NewBrowseHandler(store repository.Store, fbClient *firebase.Client, searchIndex *search.Index) *BrowseHandler {
+	authorsTmpl, err := template.New("authors.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/books/authors.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu books/authors.html: %v", err)
+	}
+
+	categoriesTmpl, err := template.New("categories.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/books/categories.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu books/categories.html: %v", err)
+	}
+
+	seriesTmpl, err := template.New("series.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/books/series.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu books/series.html: %v", err)
+	}
+
+	classificationTmpl, err := template.New("classification.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/books/classification.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu books/classification.html: %v", err)
+	}
+
+	return &BrowseHandler{
+		authorsTemplate:        authorsTmpl,
+		categoriesTemplate:     categoriesTmpl,
+		seriesTemplate:         seriesTmpl,
+		classificationTemplate: classificationTmpl,
+		store:                  store,
+		searchIndex:            searchIndex,
+	}
+}
+
+// ShowAuthors wyświetla autorów na wybraną literę nazwiska (GET /books/authors/{letter})
+func (h *BrowseHandler) ShowAuthors(w http.ResponseWriter, r *http.Request) {
+	if h.authorsTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	browse, ok := h.loadBrowse(w)
+	if !ok {
+		return
+	}
+
+	letter := strings.ToUpper(chi.URLParam(r, "letter"))
+	if !browse.HasLetter(letter) {
+		http.Error(w, "Nieznana litera", http.StatusNotFound)
+		return
+	}
+
+	data := NewPageData(r)
+	data["Letter"] = letter
+	data["Letters"] = browse.Letters
+	data["Authors"] = browse.AuthorsByLetter(letter)
+
+	if err := h.authorsTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania listy autorów: %v", err)
+		http.Error(w, "Błąd renderowania strony", http.StatusInternalServerError)
+	}
+}
+
+// ShowCategories wyświetla listę kategorii (GET /books/categories)
+func (h *BrowseHandler) ShowCategories(w http.ResponseWriter, r *http.Request) {
+	h.renderCategories(w, r, "")
+}
+
+// ShowCategory wyświetla książki z wybranej kategorii (GET /books/categories/{slug})
+func (h *BrowseHandler) ShowCategory(w http.ResponseWriter, r *http.Request) {
+	h.renderCategories(w, r, chi.URLParam(r, "slug"))
+}
+
+func (h *BrowseHandler) renderCategories(w http.ResponseWriter, r *http.Request, slug string) {
+	if h.categoriesTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	browse, ok := h.loadBrowse(w)
+	if !ok {
+		return
+	}
+
+	data := NewPageData(r)
+	data["Categories"] = browse.Categories
+
+	if slug != "" {
+		category, found := browse.CategoryBySlug(slug)
+		if !found {
+			http.Error(w, "Kategoria nie została znaleziona", http.StatusNotFound)
+			return
+		}
+
+		// Zapytanie po równości pola category korzysta z indeksu pojedynczego pola
+		books, err := h.store.Books(r.Context()).GetBooksByCategory(category.Name)
+		if err != nil {
+			log.Printf("Błąd pobierania książek z kategorii %s: %v", category.Name, err)
+			data["Error"] = "Błąd pobierania książek z bazy danych"
+		}
+		sort.Slice(books, func(i, j int) bool {
+			return search.Fold(books[i].Title) < search.Fold(books[j].Title)
+		})
+
+		data["Current"] = category
+		data["Books"] = books
+	}
+
+	if err := h.categoriesTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania kategorii: %v", err)
+		http.Error(w, "Błąd renderowania strony", http.StatusInternalServerError)
+	}
+}
+
+// ShowSeriesList wyświetla listę serii (GET /books/series)
+func (h *BrowseHandler) ShowSeriesList(w http.ResponseWriter, r *http.Request) {
+	h.renderSeries(w, r, "")
+}
+
+// ShowSeries wyświetla tomy serii w kolejności (GET /books/series/{slug})
+func (h *BrowseHandler) ShowSeries(w http.ResponseWriter, r *http.Request) {
+	h.renderSeries(w, r, chi.URLParam(r, "slug"))
+}
+
+func (h *BrowseHandler) renderSeries(w http.ResponseWriter, r *http.Request, slug string) {
+	if h.seriesTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	browse, ok := h.loadBrowse(w)
+	if !ok {
+		return
+	}
+
+	data := NewPageData(r)
+	data["SeriesList"] = browse.Series
+
+	if slug != "" {
+		series, found := browse.SeriesBySlug(slug)
+		if !found {
+			http.Error(w, "Seria nie została znaleziona", http.StatusNotFound)
+			return
+		}
+
+		books, err := h.store.Books(r.Context()).GetBooksBySeries(series.Name)
+		if err != nil {
+			log.Printf("Błąd pobierania książek z serii %s: %v", series.Name, err)
+			data["Error"] = "Błąd pobierania książek z bazy danych"
+		}
+
+		data["Current"] = series
+		data["Books"] = books
+		data["SeriesTotal"] = seriesTotal(books)
+	}
+
+	if err := h.seriesTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania serii: %v", err)
+		http.Error(w, "Błąd renderowania strony", http.StatusInternalServerError)
+	}
+}
+
+// seriesTotal zwraca liczbę tomów serii - najwyższy numer tomu w katalogu
+func seriesTotal(books []*models.Book) int {
+	total := 0
+	for _, book := range books {
+		if book.SeriesVolume > total {
+			total = book.SeriesVolume
+		}
+	}
+	return total
+}
+
+// seriesVolume wybiera z serii tom o podanym numerze; przy kilku wydaniach
+// pierwsze dostępne. Zwraca nil, jeśli tomu nie ma w katalogu.
+func seriesVolume(books []*models.Book, volume int) *models.Book {
+	var found *models.Book
+	for _, book := range books {
+		if book.SeriesVolume != volume {
+			continue
+		}
+		if found == nil || (!found.IsAvailable() && book.IsAvailable()) {
+			found = book
+		}
+	}
+	return found
+}
+
+// classSubdivision to poddział bieżącej klasy z liczbą tytułów
+type classSubdivision struct {
+	Symbol string
+	Count  int
+}
+
+// ShowClassification wyświetla klasy główne UKD (GET /books/classification)
+func (h *BrowseHandler) ShowClassification(w http.ResponseWriter, r *http.Request) {
+	h.renderClassification(w, r, "")
+}
+
+// ShowClass wyświetla książki z klasy lub poddziału w kolejności półkowej
+// (GET /books/classification/{symbol}, np. 821.162)
+func (h *BrowseHandler) ShowClass(w http.ResponseWriter, r *http.Request) {
+	digits := strings.ReplaceAll(chi.URLParam(r, "symbol"), ".", "")
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		http.Error(w, "Nieprawidłowy symbol klasyfikacji", http.StatusNotFound)
+		return
+	}
+	h.renderClassification(w, r, digits)
+}
+
+func (h *BrowseHandler) renderClassification(w http.ResponseWriter, r *http.Request, digits string) {
+	if h.classificationTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	browse, ok := h.loadBrowse(w)
+	if !ok {
+		return
+	}
+
+	data := NewPageData(r)
+	data["Classes"] = browse.Classes
+
+	if digits != "" {
+		books, err := h.store.Books(r.Context()).GetBooksByClassification(digits)
+		if err != nil {
+			log.Printf("Błąd pobierania książek z klasy %s: %v", digits, err)
+			data["Error"] = "Błąd pobierania książek z bazy danych"
+		}
+
+		// Poddziały o jedną cyfrę dłuższe, np. 821.1 ... 821.9 dla 821
+		counts := make(map[string]int)
+		var subdivisions []classSubdivision
+		for _, book := range books {
+			if bookDigits := models.ClassificationDigits(book.CallNumber); len(bookDigits) > len(digits) {
+				symbol := models.FormatClassification(bookDigits[:len(digits)+1])
+				if counts[symbol] == 0 {
+					subdivisions = append(subdivisions, classSubdivision{Symbol: symbol})
+				}
+				counts[symbol]++
+			}
+		}
+		for i := range subdivisions {
+			subdivisions[i].Count = counts[subdivisions[i].Symbol]
+		}
+
+		// Okruszki: klasy nadrzędne od klasy głównej
+		var parents []string
+		for i := 1; i < len(digits); i++ {
+			parents = append(parents, models.FormatClassification(digits[:i]))
+		}
+
+		data["Symbol"] = models.FormatClassification(digits)
+		data["ClassName"] = models.UKDClassName(digits)
+		data["Parents"] = parents
+		data["Subdivisions"] = subdivisions
+		data["Books"] = books
+	}
+
+	if err := h.classificationTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania klasyfikacji: %v", err)
+		http.Error(w, "Błąd renderowania strony", http.StatusInternalServerError)
+	}
+}
+
+// loadBrowse odświeża indeks i zwraca zestawienia; przy błędzie wysyła odpowiedź
+func (h *BrowseHandler) loadBrowse(w http.ResponseWriter) (*search.Browse, bool) {
+	if h.store == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	if err := h.searchIndex.Refresh(); err != nil {
+		log.Printf("Błąd budowania indeksu wyszukiwania: %v", err)
+		http.Error(w, "Przeglądanie katalogu jest chwilowo niedostępne", http.StatusServiceUnavailable)
+		return nil, false
+	}
+
+	return h.searchIndex.Browse(), true
+}
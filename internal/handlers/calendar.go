@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/middleware"
+	"library-management-system/internal/models"
+	"library-management-system/internal/repository"
+)
+
+// CalendarHandler obsługuje kalendarz iCal czytelnika: terminy zwrotu wypożyczeń
+// i ostatnie dni odbioru rezerwacji. Aplikacje kalendarza nie logują się do biblioteki,
+// więc kalendarz jest dostępny pod adresem z tajnym tokenem, który czytelnik może zmienić.
+// Kalendarz nie zawiera wydarzeń biblioteki - system nie prowadzi jeszcze zapisów na wydarzenia.
+type CalendarHandler struct {
+	calendarTemplate *template.Template
+	store            repository.Store
+	fbClient         *firebase.Client // Tokeny kalendarza i nazwa biblioteki
+	baseURL          string
+}
+
+// NewCalendarHandler tworzy handler kalendarza.
+// baseURL jest potrzebny, bo aplikacja kalendarza dostaje pełny adres subskrypcji.
+func NewCalendarHandler(store repository.Store, fbClient *firebase.Client, baseURL string) *CalendarHandler {
+	calendarTmpl, err := template.New("calendar.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/user/calendar.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu user/calendar.html: %v", err)
+	}
+
+	return &CalendarHandler{
+		calendarTemplate: calendarTmpl,
+		store:            store,
+		fbClient:         fbClient,
+		baseURL:          strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// ShowCalendar wyświetla adres subskrypcji kalendarza (GET /user/calendar)
+func (h *CalendarHandler) ShowCalendar(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if h.calendarTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	data := NewPageData(r)
+	data["Saved"] = r.URL.Query().Get("saved")
+
+	// Token pobieramy z bazy - sesja przechowuje kopię profilu z chwili logowania
+	if h.fbClient != nil {
+		user, err := h.fbClient.Traced(r.Context()).GetUser(session.UserID)
+		if err != nil {
+			log.Printf("Błąd pobierania użytkownika: %v", err)
+			data["Error"] = "Błąd pobierania adresu kalendarza"
+		} else if user.CalendarToken != "" {
+			feedURL := h.baseURL + "/calendar/" + user.CalendarToken + ".ics"
+			data["FeedURL"] = feedURL
+			if u, err := url.Parse(feedURL); err == nil {
+				// html/template odrzuca nieznane schematy adresów - adres budujemy sami, więc jest bezpieczny
+				u.Scheme = "webcal"
+				data["WebcalURL"] = template.URL(u.String())
+			}
+		}
+	}
+
+	if err := h.calendarTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania strony kalendarza: %v", err)
+	}
+}
+
+// RenewToken tworzy adres kalendarza albo zastępuje dotychczasowy nowym (POST /user/calendar)
+func (h *CalendarHandler) RenewToken(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Nie jesteś zalogowany", http.StatusUnauthorized)
+		return
+	}
+
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	if _, err := h.fbClient.Traced(r.Context()).RenewCalendarToken(session.UserID); err != nil {
+		log.Printf("Błąd tworzenia adresu kalendarza: %v", err)
+		http.Error(w, "Błąd tworzenia adresu kalendarza", http.StatusInternalServerError)
+		return
+	}
+
+	basepath.Redirect(w, r, "/user/calendar?saved=renewed", http.StatusSeeOther)
+}
+
+// Feed zwraca kalendarz czytelnika w formacie iCalendar (GET /calendar/{token}.ics)
+func (h *CalendarHandler) Feed(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	user, err := h.fbClient.Traced(r.Context()).GetUserByCalendarToken(chi.URLParam(r, "token"))
+	if err != nil {
+		log.Printf("Błąd pobierania kalendarza: %v", err)
+		http.Error(w, "Błąd pobierania kalendarza", http.StatusInternalServerError)
+		return
+	}
+	if user == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	loans, err := h.store.Loans(r.Context()).GetUserActiveLoans(user.ID)
+	if err != nil {
+		log.Printf("Błąd pobierania wypożyczeń do kalendarza: %v", err)
+		http.Error(w, "Błąd pobierania kalendarza", http.StatusInternalServerError)
+		return
+	}
+	reservations, err := h.store.Reservations(r.Context()).GetUserActiveReservations(user.ID)
+	if err != nil {
+		log.Printf("Błąd pobierania rezerwacji do kalendarza: %v", err)
+		http.Error(w, "Błąd pobierania kalendarza", http.StatusInternalServerError)
+		return
+	}
+
+	host := "biblioteka"
+	if u, err := url.Parse(h.baseURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	cal := newICalendar(libraryName(h.fbClient)+" - moje terminy", time.Now())
+
+	for _, loan := range loans {
+		if !loan.IsBorrowed() || loan.IsReadingRoom() {
+			continue
+		}
+		cal.addDay("loan-"+loan.ID+"@"+host, loan.DueDate,
+			"Zwrot książki: "+loan.BookTitle,
+			"Termin zwrotu wypożyczenia. "+h.baseURL+"/user", "")
+	}
+	for _, reservation := range reservations {
+		if reservation.Status != models.ReservationStatusReady {
+			continue
+		}
+		location := reservation.PickupLocation
+		if location == "" {
+			location = "wypożyczalnia"
+		}
+		cal.addDay("reservation-"+reservation.ID+"@"+host, reservation.ExpiryDate,
+			"Ostatni dzień odbioru: "+reservation.BookTitle,
+			"Zarezerwowana książka czeka na odbiór. "+h.baseURL+"/user/reservations", location)
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "private, max-age=900")
+	w.Write([]byte(cal.String()))
+}
+
+// iCalendar buduje kalendarz w formacie RFC 5545 z wydarzeniami całodniowymi
+type iCalendar struct {
+	lines []string
+	stamp string
+}
+
+func newICalendar(name string, now time.Time) *iCalendar {
+	return &iCalendar{
+		stamp: now.UTC().Format("20060102T150405Z"),
+		lines: []string{
+			"BEGIN:VCALENDAR",
+			"VERSION:2.0",
+			"PRODID:-//library-management-system//kalendarz czytelnika//PL",
+			"CALSCALE:GREGORIAN",
+			"METHOD:PUBLISH",
+			"X-WR-CALNAME:" + icalText(name),
+			"REFRESH-INTERVAL;VALUE=DURATION:PT6H",
+			"X-PUBLISHED-TTL:PT6H",
+		},
+	}
+}
+
+// addDay dodaje wydarzenie całodniowe w dniu day (wg strefy czasowej serwera)
+func (c *iCalendar) addDay(uid string, day time.Time, summary, description, location string) {
+	c.lines = append(c.lines,
+		"BEGIN:VEVENT",
+		"UID:"+uid,
+		"DTSTAMP:"+c.stamp,
+		"DTSTART;VALUE=DATE:"+day.Format("20060102"),
+		"DTEND;VALUE=DATE:"+day.AddDate(0, 0, 1).Format("20060102"),
+		"SUMMARY:"+icalText(summary),
+		"DESCRIPTION:"+icalText(description),
+	)
+	if location != "" {
+		c.lines = append(c.lines, "LOCATION:"+icalText(location))
+	}
+	c.lines = append(c.lines, "TRANSP:TRANSPARENT", "END:VEVENT")
+}
+
+// String zwraca kalendarz z liniami zawiniętymi do 75 bajtów i zakończonymi CRLF
+func (c *iCalendar) String() string {
+	var sb strings.Builder
+	for _, line := range append(c.lines, "END:VCALENDAR") {
+		limit := 75
+		for len(line) > limit {
+			cut := limit
+			for !utf8Start(line[cut]) {
+				cut-- // Nie dziel znaku UTF-8 między linie
+			}
+			sb.WriteString(line[:cut] + "\r\n ")
+			line = line[cut:]
+			limit = 74 // Linia kontynuacji zaczyna się od spacji
+		}
+		sb.WriteString(line + "\r\n")
+	}
+	return sb.String()
+}
+
+// icalText zabezpiecza tekst wartości (RFC 5545, 3.3.11)
+func icalText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+func utf8Start(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	qrcode "github.com/skip2/go-qrcode"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/middleware"
+	"library-management-system/internal/models"
+)
+
+// CardHandler obsługuje cyfrową kartę biblioteczną czytelnika. Kod QR na karcie
+// zawiera adres profilu w panelu personelu, więc bibliotekarz może go zeskanować
+// telefonem albo czytnikiem kodów w polu "Zeskanuj kartę" na liście użytkowników.
+type CardHandler struct {
+	cardTemplate *template.Template
+	fbClient     *firebase.Client
+	baseURL      string
+}
+
+// NewCardHandler tworzy handler kart bibliotecznych.
+// baseURL jest potrzebny, bo kod QR musi zawierać pełny adres serwisu.
+func NewCardHandler(fbClient *firebase.Client, baseURL string) *CardHandler {
+	cardTmpl, err := template.New("card.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/user/card.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu user/card.html: %v", err)
+	}
+
+	return &CardHandler{
+		cardTemplate: cardTmpl,
+		fbClient:     fbClient,
+		baseURL:      strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// ShowCard wyświetla kartę biblioteczną zalogowanego czytelnika (GET /user/card)
+func (h *CardHandler) ShowCard(w http.ResponseWriter, r *http.Request) {
+	if h.cardTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	data := NewPageData(r)
+	data["CardNumber"] = user.CardNumber
+
+	if err := h.cardTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania karty bibliotecznej: %v", err)
+		http.Error(w, "Błąd renderowania strony", http.StatusInternalServerError)
+	}
+}
+
+// QRCode zwraca kod QR karty zalogowanego czytelnika (GET /user/card/qr.png)
+func (h *CardHandler) QRCode(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	png, err := qrcode.Encode(h.baseURL+"/staff/card/"+user.CardNumber, qrcode.Medium, qrCodeSize)
+	if err != nil {
+		log.Printf("Błąd generowania kodu QR: %v", err)
+		http.Error(w, "Błąd generowania kodu QR", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	w.Write(png)
+}
+
+// OpenCard otwiera profil czytelnika po zeskanowaniu karty
+// (GET /staff/card/{number} z kodu QR lub GET /staff/card?number= z formularza).
+// Formularz przyjmuje także cały adres z kodu QR - czytnik kodów wpisuje go jako tekst.
+func (h *CardHandler) OpenCard(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return
+	}
+
+	number := chi.URLParam(r, "number")
+	if number == "" {
+		number = path.Base(strings.TrimSpace(r.URL.Query().Get("number")))
+	}
+
+	user, err := h.fbClient.Traced(r.Context()).GetUserByCardNumber(number)
+	if err != nil {
+		log.Printf("Błąd wyszukiwania karty %s: %v", number, err)
+		http.Error(w, "Błąd wyszukiwania karty", http.StatusInternalServerError)
+		return
+	}
+	if user == nil {
+		http.Error(w, "Nie znaleziono czytelnika z tą kartą", http.StatusNotFound)
+		return
+	}
+
+	basepath.Redirect(w, r, "/staff/users/"+user.ID+"/edit", http.StatusSeeOther)
+}
+
+// currentUser pobiera aktualny profil zalogowanego czytelnika i nadaje mu numer karty,
+// jeśli jeszcze go nie ma (sesja przechowuje kopię profilu z chwili logowania)
+func (h *CardHandler) currentUser(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	sess := middleware.GetSessionFromContext(r.Context())
+	if sess == nil {
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
+		return nil, false
+	}
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	user, err := h.fbClient.Traced(r.Context()).GetUser(sess.User.ID)
+	if err != nil {
+		log.Printf("Błąd pobierania użytkownika %s: %v", sess.User.ID, err)
+		http.Error(w, "Błąd pobierania profilu", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	if err := h.fbClient.Traced(r.Context()).EnsureUserCardNumber(user); err != nil {
+		log.Printf("Błąd nadawania numeru karty użytkownikowi %s: %v", user.ID, err)
+		http.Error(w, "Błąd nadawania numeru karty", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	return user, true
+}
@@ -5,36 +5,37 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 
+	"library-management-system/internal/basepath"
 	"library-management-system/internal/firebase"
 	"library-management-system/internal/middleware"
 	"library-management-system/internal/models"
+	"library-management-system/internal/repository"
+	"library-management-system/internal/search"
 )
 
 // CatalogHandler obsługuje zarządzanie katalogiem książek
 type CatalogHandler struct {
-	listTemplate *template.Template
-	formTemplate *template.Template
+	listTemplate    *template.Template
+	formTemplate    *template.Template
+	historyTemplate *template.Template
+	searchIndex     *search.Index
+	store           repository.Store
+	fbClient        *firebase.Client // Dziennik zmian i statystyki czynności personelu
 }
 
 // NewCatalogHandler tworzy nowy handler katalogu
-func NewCatalogHandler() *CatalogHandler {
-	funcMap := template.FuncMap{
-		"sub": func(a, b int) int {
-			return a - b
-		},
-		"add": func(a, b int) int {
-			return a + b
-		},
-		"mkRange": func(start, end int) []int {
-			result := make([]int, end-start+1)
-			for i := range result {
-				result[i] = start + i
-			}
-			return result
-		},
+func NewCatalogHandler(store repository.Store, fbClient *firebase.Client, searchIndex *search.Index) *CatalogHandler {
+	funcMap := templateFuncs(fbClient)
+	funcMap["mkRange"] = func(start, end int) []int {
+		result := make([]int, end-start+1)
+		for i := range result {
+			result[i] = start + i
+		}
+		return result
 	}
 
 	listTmpl, err := template.New("catalog_list.html").Funcs(funcMap).ParseFiles("internal/templates/staff/catalog_list.html")
@@ -42,14 +43,23 @@ func NewCatalogHandler() *CatalogHandler {
 		log.Printf("Błąd ładowania szablonu catalog_list.html: %v", err)
 	}
 
-	formTmpl, err := template.ParseFiles("internal/templates/staff/catalog_form.html")
+	formTmpl, err := template.New("catalog_form.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/catalog_form.html")
 	if err != nil {
 		log.Printf("Błąd ładowania szablonu catalog_form.html: %v", err)
 	}
 
+	historyTmpl, err := template.New("book_history.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/book_history.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu book_history.html: %v", err)
+	}
+
 	return &CatalogHandler{
-		listTemplate: listTmpl,
-		formTemplate: formTmpl,
+		listTemplate:    listTmpl,
+		formTemplate:    formTmpl,
+		historyTemplate: historyTmpl,
+		searchIndex:     searchIndex,
+		store:           store,
+		fbClient:        fbClient,
 	}
 }
 
@@ -78,7 +88,7 @@ func (h *CatalogHandler) ListBooks(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Pobierz książki z paginacją
-	books, totalCount, err := firebase.GlobalClient.ListBooksWithPagination(limit, offset, sortBy, sortOrder)
+	books, totalCount, err := h.store.Books(r.Context()).ListBooksWithPagination(limit, offset, sortBy, sortOrder)
 	if err != nil {
 		log.Printf("Błąd pobierania książek: %v", err)
 		http.Error(w, "Błąd pobierania książek", http.StatusInternalServerError)
@@ -88,9 +98,9 @@ func (h *CatalogHandler) ListBooks(w http.ResponseWriter, r *http.Request) {
 	// Oblicz liczbę stron
 	totalPages := (totalCount + limit - 1) / limit
 
-	session := middleware.GetSessionFromContext(r.Context())
-	data := NewTemplateData(session)
+	data := NewPageData(r)
 	data["Books"] = books
+	data["Activity"] = h.booksActivity(r, books)
 	data["CurrentPage"] = page
 	data["TotalPages"] = totalPages
 	data["TotalCount"] = totalCount
@@ -109,7 +119,7 @@ func (h *CatalogHandler) SearchBooks(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("Wyszukiwanie: query='%s'", query)
 
-	books, err := firebase.GlobalClient.SearchBooks(query)
+	books, err := h.store.Books(r.Context()).SearchBooks(query)
 	if err != nil {
 		log.Printf("Błąd wyszukiwania książek: %v", err)
 		http.Error(w, "Błąd wyszukiwania", http.StatusInternalServerError)
@@ -118,9 +128,9 @@ func (h *CatalogHandler) SearchBooks(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("Znaleziono %d książek dla zapytania '%s'", len(books), query)
 
-	session := middleware.GetSessionFromContext(r.Context())
-	data := NewTemplateData(session)
+	data := NewPageData(r)
 	data["Books"] = books
+	data["Activity"] = h.booksActivity(r, books)
 	data["SearchQuery"] = query
 
 	// Renderuj tylko fragment tabeli dla htmx
@@ -134,8 +144,7 @@ func (h *CatalogHandler) ShowNewBookForm(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	session := middleware.GetSessionFromContext(r.Context())
-	data := NewTemplateData(session)
+	data := NewPageData(r)
 	data["Action"] = "create"
 	data["Book"] = &models.Book{}
 	data["Categories"] = getBookCategories()
@@ -161,7 +170,7 @@ func (h *CatalogHandler) CreateBook(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Sprawdź czy ISBN już istnieje
-	existingBook, err := firebase.GlobalClient.GetBookByISBN(isbn)
+	existingBook, err := h.store.Books(r.Context()).GetBookByISBN(isbn)
 	if err != nil {
 		log.Printf("Błąd sprawdzania ISBN: %v", err)
 		h.renderFormError(w, r, "Błąd sprawdzania ISBN", nil)
@@ -175,6 +184,7 @@ func (h *CatalogHandler) CreateBook(w http.ResponseWriter, r *http.Request) {
 	// Parsuj pozostałe dane
 	totalCopies, _ := strconv.Atoi(r.FormValue("total_copies"))
 	publicationYear, _ := strconv.Atoi(r.FormValue("publication_year"))
+	seriesVolume, _ := strconv.Atoi(r.FormValue("series_volume"))
 
 	book := &models.Book{
 		ISBN:            isbn,
@@ -183,6 +193,9 @@ func (h *CatalogHandler) CreateBook(w http.ResponseWriter, r *http.Request) {
 		Publisher:       r.FormValue("publisher"),
 		PublicationYear: publicationYear,
 		Category:        r.FormValue("category"),
+		CallNumber:      r.FormValue("call_number"),
+		Series:          strings.TrimSpace(r.FormValue("series")),
+		SeriesVolume:    seriesVolume,
 		Description:     r.FormValue("description"),
 		TotalCopies:     totalCopies,
 		AvailableCopies: totalCopies, // Na początku wszystkie dostępne
@@ -201,16 +214,26 @@ func (h *CatalogHandler) CreateBook(w http.ResponseWriter, r *http.Request) {
 		h.renderFormError(w, r, "Liczba egzemplarzy musi być większa od 0", book)
 		return
 	}
+	if _, err := models.NormalizeCallNumber(book.CallNumber); err != nil {
+		h.renderFormError(w, r, "Nieprawidłowa sygnatura: "+err.Error(), book)
+		return
+	}
+	if book.SeriesVolume < 0 || (book.SeriesVolume > 0 && book.Series == "") {
+		h.renderFormError(w, r, "Numer tomu wymaga nazwy serii i musi być większy od 0", book)
+		return
+	}
 
 	// Zapisz książkę
-	if err := firebase.GlobalClient.CreateBook(book); err != nil {
+	if err := h.store.Books(r.Context()).CreateBook(book); err != nil {
 		log.Printf("Błąd tworzenia książki: %v", err)
 		h.renderFormError(w, r, "Błąd zapisywania książki: "+err.Error(), book)
 		return
 	}
+	h.searchIndex.Invalidate()
+	recordStaffActivity(h.fbClient, r, models.StaffActionCatalogEdit)
 
 	// Przekieruj do listy książek (htmx)
-	w.Header().Set("HX-Redirect", "/staff/catalog")
+	w.Header().Set("HX-Redirect", basepath.URL("/staff/catalog"))
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -227,19 +250,14 @@ func (h *CatalogHandler) ShowEditBookForm(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	book, err := firebase.GlobalClient.GetBook(bookID)
+	book, err := h.store.Books(r.Context()).GetBook(bookID)
 	if err != nil {
 		log.Printf("Błąd pobierania książki: %v", err)
 		http.Error(w, "Książka nie została znaleziona", http.StatusNotFound)
 		return
 	}
 
-	session := middleware.GetSessionFromContext(r.Context())
-	data := NewTemplateData(session)
-	data["Action"] = "edit"
-	data["Book"] = book
-	data["Categories"] = getBookCategories()
-
+	data := h.editFormData(r, book)
 	if err := h.formTemplate.Execute(w, data); err != nil {
 		log.Printf("Błąd renderowania formularza: %v", err)
 		http.Error(w, "Błąd renderowania strony", http.StatusInternalServerError)
@@ -260,7 +278,7 @@ func (h *CatalogHandler) UpdateBook(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Pobierz istniejącą książkę
-	existingBook, err := firebase.GlobalClient.GetBook(bookID)
+	existingBook, err := h.store.Books(r.Context()).GetBook(bookID)
 	if err != nil {
 		log.Printf("Błąd pobierania książki: %v", err)
 		http.Error(w, "Książka nie została znaleziona", http.StatusNotFound)
@@ -270,15 +288,12 @@ func (h *CatalogHandler) UpdateBook(w http.ResponseWriter, r *http.Request) {
 	// Parsuj dane
 	totalCopies, _ := strconv.Atoi(r.FormValue("total_copies"))
 	publicationYear, _ := strconv.Atoi(r.FormValue("publication_year"))
+	seriesVolume, _ := strconv.Atoi(r.FormValue("series_volume"))
 
-	// Oblicz różnicę w dostępnych egzemplarzach
-	copiesDiff := totalCopies - existingBook.TotalCopies
-	newAvailableCopies := existingBook.AvailableCopies + copiesDiff
-	if newAvailableCopies < 0 {
-		newAvailableCopies = 0
-	}
-	if newAvailableCopies > totalCopies {
-		newAvailableCopies = totalCopies
+	// Nowe egzemplarze od razu trafiają na półkę
+	newAvailableCopies := existingBook.AvailableCopies
+	if totalCopies > existingBook.TotalCopies {
+		newAvailableCopies += totalCopies - existingBook.TotalCopies
 	}
 
 	book := &models.Book{
@@ -289,6 +304,9 @@ func (h *CatalogHandler) UpdateBook(w http.ResponseWriter, r *http.Request) {
 		Publisher:       r.FormValue("publisher"),
 		PublicationYear: publicationYear,
 		Category:        r.FormValue("category"),
+		CallNumber:      r.FormValue("call_number"),
+		Series:          strings.TrimSpace(r.FormValue("series")),
+		SeriesVolume:    seriesVolume,
 		Description:     r.FormValue("description"),
 		TotalCopies:     totalCopies,
 		AvailableCopies: newAvailableCopies,
@@ -308,19 +326,87 @@ func (h *CatalogHandler) UpdateBook(w http.ResponseWriter, r *http.Request) {
 		h.renderFormError(w, r, "Liczba egzemplarzy musi być większa od 0", book)
 		return
 	}
+	if _, err := models.NormalizeCallNumber(book.CallNumber); err != nil {
+		h.renderFormError(w, r, "Nieprawidłowa sygnatura: "+err.Error(), book)
+		return
+	}
+	if book.SeriesVolume < 0 || (book.SeriesVolume > 0 && book.Series == "") {
+		h.renderFormError(w, r, "Numer tomu wymaga nazwy serii i musi być większy od 0", book)
+		return
+	}
+	if book.TotalCopies < existingBook.TotalCopies {
+		// Zmniejszenie liczby egzemplarzy wymaga wycofania konkretnych egzemplarzy z półki
+		h.renderFormError(w, r, "Liczbę egzemplarzy można zmniejszyć tylko przez wycofanie egzemplarzy (poniżej formularza)", book)
+		return
+	}
+
+	audit, err := h.fbClient.Traced(r.Context()).BeginAudit(firebase.AuditDoc{Collection: firebase.BooksCollection, ID: bookID})
+	if err != nil {
+		log.Printf("Błąd dziennika zmian przed edycją książki %s: %v", bookID, err)
+	}
 
 	// Aktualizuj książkę
-	if err := firebase.GlobalClient.UpdateBook(bookID, book); err != nil {
+	if err := h.store.Books(r.Context()).UpdateBook(bookID, book); err != nil {
 		log.Printf("Błąd aktualizacji książki: %v", err)
 		h.renderFormError(w, r, "Błąd zapisywania książki: "+err.Error(), book)
 		return
 	}
+	h.searchIndex.Invalidate()
+	recordStaffActivity(h.fbClient, r, models.StaffActionCatalogEdit)
+	recordAudit(r, audit, models.AuditBookEdit, bookID, book.Title)
 
 	// Przekieruj do listy książek
-	w.Header().Set("HX-Redirect", "/staff/catalog")
+	w.Header().Set("HX-Redirect", basepath.URL("/staff/catalog"))
 	w.WriteHeader(http.StatusOK)
 }
 
+// WithdrawCopies wycofuje egzemplarze z księgozbioru (POST /staff/catalog/{id}/withdraw)
+func (h *CatalogHandler) WithdrawCopies(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if h.formTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	bookID := chi.URLParam(r, "id")
+	count, _ := strconv.Atoi(r.FormValue("count"))
+	withdrawal := &models.CopyWithdrawal{
+		BookID:     bookID,
+		Count:      count,
+		Reason:     models.WithdrawalReason(r.FormValue("reason")),
+		Notes:      r.FormValue("notes"),
+		RecordedBy: session.User.Email,
+	}
+
+	if err := h.store.Books(r.Context()).WithdrawCopies(withdrawal); err != nil {
+		log.Printf("Błąd wycofywania egzemplarzy książki %s: %v", bookID, err)
+
+		book, getErr := h.store.Books(r.Context()).GetBook(bookID)
+		if getErr != nil {
+			log.Printf("Błąd pobierania książki: %v", getErr)
+			http.Error(w, "Książka nie została znaleziona", http.StatusNotFound)
+			return
+		}
+
+		data := h.editFormData(r, book)
+		data["WithdrawError"] = "Nie udało się wycofać egzemplarzy: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		if err := h.formTemplate.Execute(w, data); err != nil {
+			log.Printf("Błąd renderowania formularza: %v", err)
+		}
+		return
+	}
+	h.searchIndex.Invalidate()
+	recordStaffActivity(h.fbClient, r, models.StaffActionCatalogEdit)
+
+	basepath.Redirect(w, r, "/staff/catalog/"+bookID+"/edit", http.StatusSeeOther)
+}
+
 // DeleteBook usuwa książkę (DELETE /staff/catalog/{id})
 func (h *CatalogHandler) DeleteBook(w http.ResponseWriter, r *http.Request) {
 	bookID := chi.URLParam(r, "id")
@@ -330,7 +416,7 @@ func (h *CatalogHandler) DeleteBook(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Sprawdź czy są aktywne wypożyczenia
-	hasLoans, err := firebase.GlobalClient.HasActiveLoans(bookID)
+	hasLoans, err := h.store.Books(r.Context()).HasActiveLoans(bookID)
 	if err != nil {
 		log.Printf("Błąd sprawdzania wypożyczeń: %v", err)
 		http.Error(w, "Błąd sprawdzania wypożyczeń", http.StatusInternalServerError)
@@ -338,18 +424,18 @@ func (h *CatalogHandler) DeleteBook(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if hasLoans {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusConflict)
-		w.Write([]byte(`{"error": "Nie można usunąć książki z aktywnymi wypożyczeniami"}`))
+		http.Error(w, "Nie można usunąć książki z aktywnymi wypożyczeniami", http.StatusConflict)
 		return
 	}
 
 	// Usuń książkę
-	if err := firebase.GlobalClient.DeleteBook(bookID); err != nil {
+	if err := h.store.Books(r.Context()).DeleteBook(bookID); err != nil {
 		log.Printf("Błąd usuwania książki: %v", err)
 		http.Error(w, "Błąd usuwania książki", http.StatusInternalServerError)
 		return
 	}
+	h.searchIndex.Invalidate()
+	recordStaffActivity(h.fbClient, r, models.StaffActionCatalogEdit)
 
 	// Zwróć sukces dla htmx
 	w.WriteHeader(http.StatusOK)
@@ -357,19 +443,68 @@ func (h *CatalogHandler) DeleteBook(w http.ResponseWriter, r *http.Request) {
 
 // Funkcje pomocnicze
 
+// editFormData przygotowuje dane formularza edycji: liczby wypożyczeń i rezerwacji
+// oraz historię wycofanych egzemplarzy. Błędy są tylko logowane.
+func (h *CatalogHandler) editFormData(r *http.Request, book *models.Book) TemplateData {
+	data := NewPageData(r)
+	data["Action"] = "edit"
+	data["Book"] = book
+	data["Categories"] = getBookCategories()
+
+	activity, err := h.store.Books(r.Context()).GetBooksActivity([]string{book.ID})
+	if err != nil {
+		log.Printf("Błąd zliczania wypożyczeń książki %s: %v", book.ID, err)
+	} else {
+		data["Activity"] = activity[book.ID]
+	}
+
+	withdrawals, err := h.store.Books(r.Context()).GetBookWithdrawals(book.ID)
+	if err != nil {
+		log.Printf("Błąd pobierania wycofanych egzemplarzy książki %s: %v", book.ID, err)
+	}
+	data["Withdrawals"] = withdrawals
+
+	return data
+}
+
+// booksActivity pobiera liczby wypożyczeń i rezerwacji dla wierszy katalogu.
+// Błąd nie blokuje listy - wiersze wyświetlają się wtedy bez tych liczb.
+func (h *CatalogHandler) booksActivity(r *http.Request, books []*models.Book) map[string]*models.BookActivity {
+	ids := make([]string, len(books))
+	for i, book := range books {
+		ids[i] = book.ID
+	}
+
+	activity, err := h.store.Books(r.Context()).GetBooksActivity(ids)
+	if err != nil {
+		log.Printf("Błąd zliczania wypożyczeń i rezerwacji: %v", err)
+		return map[string]*models.BookActivity{}
+	}
+	return activity
+}
+
 func (h *CatalogHandler) renderBooksTable(w http.ResponseWriter, data map[string]interface{}) {
 	// Prosty szablon tabeli dla htmx
 	tmpl := `
 	{{range .Books}}
 	<tr>
+		<td class="pl-6 py-4"><input type="checkbox" name="id" value="{{.ID}}" form="labels-form"></td>
 		<td class="px-6 py-4 whitespace-nowrap">{{.Title}}</td>
 		<td class="px-6 py-4 whitespace-nowrap">{{.Author}}</td>
 		<td class="px-6 py-4 whitespace-nowrap">{{.ISBN}}</td>
+		<td class="px-6 py-4 whitespace-nowrap font-mono">{{.CallNumber}}</td>
 		<td class="px-6 py-4 whitespace-nowrap">{{.Category}}</td>
-		<td class="px-6 py-4 whitespace-nowrap">{{.AvailableCopies}}/{{.TotalCopies}}</td>
+		<td class="px-6 py-4 whitespace-nowrap">
+			{{.AvailableCopies}}/{{.TotalCopies}}
+			{{with index $.Activity .ID}}{{if or .Loans .Reservations}}
+			<div class="text-xs text-gray-500">Wypożyczone: {{.Loans}}{{if .Reservations}}, w kolejce: {{.Reservations}}{{end}}</div>
+			{{end}}{{end}}
+		</td>
 		<td class="px-6 py-4 whitespace-nowrap text-sm">
-			<a href="/staff/catalog/{{.ID}}/edit" class="text-blue-600 hover:text-blue-900 mr-3">Edytuj</a>
-			<button hx-delete="/staff/catalog/{{.ID}}" 
+			<a href="{{url "/staff/catalog/"}}{{.ID}}/edit" class="text-blue-600 hover:text-blue-900 mr-3">Edytuj</a>
+			<a href="{{url "/staff/catalog/"}}{{.ID}}/label" class="text-blue-600 hover:text-blue-900 mr-3">Etykieta</a>
+			<a href="{{url "/staff/catalog/"}}{{.ID}}/history" class="text-blue-600 hover:text-blue-900 mr-3">Historia</a>
+			<button hx-delete="{{url "/staff/catalog/"}}{{.ID}}" 
 					hx-confirm="Czy na pewno chcesz usunąć tę książkę?"
 					hx-target="closest tr"
 					hx-swap="outerHTML swap:1s"
@@ -378,12 +513,12 @@ func (h *CatalogHandler) renderBooksTable(w http.ResponseWriter, data map[string
 	</tr>
 	{{else}}
 	<tr>
-		<td colspan="6" class="px-6 py-4 text-center text-gray-500">Brak wyników</td>
+		<td colspan="8" class="px-6 py-4 text-center text-gray-500">Brak wyników</td>
 	</tr>
 	{{end}}
 	`
 
-	t, err := template.New("table").Parse(tmpl)
+	t, err := template.New("table").Funcs(templateFuncs(h.fbClient)).Parse(tmpl)
 	if err != nil {
 		log.Printf("Błąd parsowania szablonu: %v", err)
 		http.Error(w, "Błąd renderowania", http.StatusInternalServerError)
@@ -402,10 +537,13 @@ func (h *CatalogHandler) renderFormError(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
-	session := middleware.GetSessionFromContext(r.Context())
-	data := NewTemplateData(session)
+	data := NewPageData(r)
 	data["Error"] = errorMsg
 	data["Book"] = book
+	data["Action"] = "create"
+	if book != nil && book.ID != "" {
+		data["Action"] = "edit"
+	}
 	data["Categories"] = getBookCategories()
 
 	w.WriteHeader(http.StatusBadRequest)
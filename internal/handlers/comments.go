@@ -0,0 +1,428 @@
+package handlers
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/middleware"
+	"library-management-system/internal/models"
+	"library-management-system/internal/moderation"
+	"library-management-system/internal/session"
+)
+
+// moderationLogSize to liczba ostatnich decyzji pokazywanych pod kolejką moderacji
+const moderationLogSize = 50
+
+// CommentsHandler obsługuje dyskusje pod książkami, zgłoszenia komentarzy
+// i kolejkę moderacji
+type CommentsHandler struct {
+	queueTemplate *template.Template
+	fbClient      *firebase.Client
+	filter        moderation.Filter
+}
+
+// NewCommentsHandler tworzy handler komentarzy. Każdy komentarz sprawdza lista słów
+// z ustawień biblioteki, a następnie filter (może być nil) - komentarz wstrzymany
+// przez któryś z nich czeka na decyzję moderatora.
+func NewCommentsHandler(fbClient *firebase.Client, filter moderation.Filter) *CommentsHandler {
+	queueTmpl, err := template.New("comments.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/comments.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/comments.html: %v", err)
+	}
+
+	return &CommentsHandler{
+		queueTemplate: queueTmpl,
+		fbClient:      fbClient,
+		filter:        filter,
+	}
+}
+
+// AddComment dodaje komentarz lub odpowiedź pod książką (POST /books/{id}/comments)
+func (h *CommentsHandler) AddComment(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	session := middleware.GetSessionFromContext(r.Context())
+	book, err := h.fbClient.Traced(r.Context()).GetBook(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Nie znaleziono książki", http.StatusNotFound)
+		return
+	}
+
+	user, err := h.fbClient.Traced(r.Context()).GetUser(session.UserID)
+	if err != nil {
+		log.Printf("Błąd pobierania użytkownika %s: %v", session.UserID, err)
+		http.Error(w, "Błąd pobierania konta", http.StatusInternalServerError)
+		return
+	}
+	if !user.IsActive {
+		http.Error(w, "Konto nieaktywne - skontaktuj się z biblioteką", http.StatusForbidden)
+		return
+	}
+	if user.CommentBanned {
+		http.Error(w, "Biblioteka zablokowała Ci możliwość komentowania", http.StatusForbidden)
+		return
+	}
+
+	body := strings.TrimSpace(r.FormValue("body"))
+	if body == "" {
+		http.Error(w, "Komentarz nie może być pusty", http.StatusBadRequest)
+		return
+	}
+	if utf8.RuneCountInString(body) > models.MaxCommentLength {
+		http.Error(w, fmt.Sprintf("Komentarz może mieć najwyżej %d znaków", models.MaxCommentLength), http.StatusBadRequest)
+		return
+	}
+
+	parentID := r.FormValue("parent_id")
+	if parentID != "" {
+		parent, err := h.fbClient.Traced(r.Context()).GetComment(parentID)
+		if err != nil || parent.BookID != book.ID || !parent.IsPublished() {
+			http.Error(w, "Nie można odpowiedzieć na ten komentarz", http.StatusBadRequest)
+			return
+		}
+	}
+
+	comment := &models.Comment{
+		BookID:       book.ID,
+		ParentID:     parentID,
+		UserID:       user.ID,
+		AuthorName:   models.CommentAuthorName(user),
+		AuthorHandle: models.CommentHandle(user),
+		Body:         body,
+		Status:       models.CommentPublished,
+	}
+	if verdict := h.check(body); verdict.Hold {
+		comment.Status = models.CommentPending
+		comment.HoldReason = verdict.Reason
+	}
+
+	if err := h.fbClient.Traced(r.Context()).CreateComment(comment); err != nil {
+		log.Printf("Błąd zapisywania komentarza do %s: %v", book.ID, err)
+		http.Error(w, "Nie udało się dodać komentarza", http.StatusInternalServerError)
+		return
+	}
+
+	if comment.IsPublished() {
+		basepath.Redirect(w, r, "/books/"+book.ID+"#comment-"+comment.ID, http.StatusSeeOther)
+		return
+	}
+	basepath.Redirect(w, r, "/books/"+book.ID+"?comment=pending#comment-"+comment.ID, http.StatusSeeOther)
+}
+
+// ReportComment zgłasza komentarz jako niestosowny
+// (POST /books/{id}/comments/{commentID}/report)
+func (h *CommentsHandler) ReportComment(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	session := middleware.GetSessionFromContext(r.Context())
+	comment, err := h.fbClient.Traced(r.Context()).ReportComment(chi.URLParam(r, "commentID"), session.UserID, r.FormValue("reason"))
+	if err != nil {
+		log.Printf("Błąd zgłaszania komentarza: %v", err)
+		http.Error(w, "Nie udało się zgłosić komentarza: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	basepath.Redirect(w, r, "/books/"+comment.BookID+"?comment=reported#comments", http.StatusSeeOther)
+}
+
+// VoteComment zapisuje ocenę przydatności komentarza (POST /books/{id}/comments/{commentID}/vote,
+// pole vote: helpful albo unhelpful)
+func (h *CommentsHandler) VoteComment(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	var helpful bool
+	switch r.FormValue("vote") {
+	case "helpful":
+		helpful = true
+	case "unhelpful":
+		helpful = false
+	default:
+		http.Error(w, "Nieprawidłowa ocena", http.StatusBadRequest)
+		return
+	}
+
+	session := middleware.GetSessionFromContext(r.Context())
+	comment, err := h.fbClient.Traced(r.Context()).VoteComment(chi.URLParam(r, "commentID"), session.UserID, helpful)
+	if err != nil {
+		log.Printf("Błąd oceniania komentarza: %v", err)
+		http.Error(w, "Nie udało się ocenić komentarza: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	basepath.Redirect(w, r, "/books/"+comment.BookID+"#comment-"+comment.ID, http.StatusSeeOther)
+}
+
+// check sprawdza treść komentarza ustawieniami moderacji biblioteki i filtrem handlera
+func (h *CommentsHandler) check(body string) moderation.Verdict {
+	settings, err := h.fbClient.GetSettings()
+	if err != nil {
+		log.Printf("Błąd pobierania ustawień moderacji: %v", err)
+		return moderation.Verdict{Hold: true, Reason: "Nie udało się sprawdzić ustawień moderacji"}
+	}
+	if settings.CommentPremoderation {
+		return moderation.Verdict{Hold: true, Reason: "Wszystkie komentarze wymagają zatwierdzenia"}
+	}
+
+	return moderation.Chain(moderation.WordList(settings.BlockedWords), h.filter).Check(body)
+}
+
+// ShowQueue wyświetla komentarze wstrzymane i zgłoszone, zablokowanych czytelników
+// i dziennik ostatnich decyzji (GET /staff/comments)
+func (h *CommentsHandler) ShowQueue(w http.ResponseWriter, r *http.Request) {
+	if h.queueTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	data := NewPageData(r)
+	switch r.URL.Query().Get("done") {
+	case string(models.ModerationApprove):
+		data["Notice"] = "Komentarz pozostaje na stronie książki"
+	case string(models.ModerationHide):
+		data["Notice"] = "Komentarz został ukryty"
+	case string(models.ModerationBan):
+		data["Notice"] = "Komentarz został ukryty, a jego autor nie może już komentować"
+	case string(models.ModerationUnban):
+		data["Notice"] = "Blokada komentowania została zdjęta"
+	}
+
+	if h.fbClient != nil {
+		comments, err := h.fbClient.Traced(r.Context()).GetModerationQueue()
+		if err != nil {
+			log.Printf("Błąd pobierania kolejki moderacji: %v", err)
+			data["Error"] = "Błąd pobierania komentarzy z bazy danych"
+		}
+
+		ids := make([]string, 0, len(comments))
+		for _, comment := range comments {
+			ids = append(ids, comment.BookID)
+		}
+		books, err := h.fbClient.Traced(r.Context()).GetBooksByIDs(ids)
+		if err != nil {
+			log.Printf("Błąd pobierania książek kolejki moderacji: %v", err)
+		}
+
+		banned, err := h.fbClient.Traced(r.Context()).GetCommentBannedUsers()
+		if err != nil {
+			log.Printf("Błąd pobierania zablokowanych czytelników: %v", err)
+		}
+
+		decisions, err := h.fbClient.Traced(r.Context()).ListModerationLog(moderationLogSize)
+		if err != nil {
+			log.Printf("Błąd pobierania dziennika moderacji: %v", err)
+		}
+
+		data["Comments"] = comments
+		data["Books"] = books
+		data["Banned"] = banned
+		data["Decisions"] = decisions
+	}
+
+	if err := h.queueTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania kolejki moderacji: %v", err)
+	}
+}
+
+// ApproveComment publikuje wstrzymany komentarz albo odrzuca zgłoszenia
+// opublikowanego (POST /staff/comments/{id}/approve)
+func (h *CommentsHandler) ApproveComment(w http.ResponseWriter, r *http.Request) {
+	h.moderate(w, r, models.ModerationApprove)
+}
+
+// HideComment ukrywa komentarz - przycisk na stronie książki wysyła from=book
+// i wraca do dyskusji (POST /staff/comments/{id}/hide)
+func (h *CommentsHandler) HideComment(w http.ResponseWriter, r *http.Request) {
+	h.moderate(w, r, models.ModerationHide)
+}
+
+// BanAuthor ukrywa komentarz i blokuje jego autorowi komentowanie
+// (POST /staff/comments/{id}/ban)
+func (h *CommentsHandler) BanAuthor(w http.ResponseWriter, r *http.Request) {
+	h.moderate(w, r, models.ModerationBan)
+}
+
+// UnbanCommenter zdejmuje czytelnikowi blokadę komentowania
+// (POST /staff/comments/bans/{userID}/delete)
+func (h *CommentsHandler) UnbanCommenter(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	session := middleware.GetSessionFromContext(r.Context())
+	if err := h.fbClient.Traced(r.Context()).UnbanCommenter(chi.URLParam(r, "userID"), session.User); err != nil {
+		log.Printf("Błąd zdejmowania blokady komentowania: %v", err)
+		http.Error(w, "Nie udało się zdjąć blokady", http.StatusInternalServerError)
+		return
+	}
+
+	basepath.Redirect(w, r, "/staff/comments?done="+string(models.ModerationUnban), http.StatusSeeOther)
+}
+
+func (h *CommentsHandler) moderate(w http.ResponseWriter, r *http.Request, action models.ModerationAction) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	session := middleware.GetSessionFromContext(r.Context())
+	comment, err := h.fbClient.Traced(r.Context()).ModerateComment(chi.URLParam(r, "id"), action, session.User)
+	if err != nil {
+		log.Printf("Błąd moderacji komentarza: %v", err)
+		http.Error(w, "Nie udało się zapisać decyzji", http.StatusInternalServerError)
+		return
+	}
+
+	if r.FormValue("from") == "book" {
+		basepath.Redirect(w, r, "/books/"+comment.BookID+"#comments", http.StatusSeeOther)
+		return
+	}
+	basepath.Redirect(w, r, "/staff/comments?done="+string(action), http.StatusSeeOther)
+}
+
+// commentNode to komentarz w drzewie dyskusji na stronie książki
+type commentNode struct {
+	*models.Comment
+	Replies []*commentNode
+	// Removed oznacza komentarz niewidoczny dla oglądającego, pokazany jako
+	// zaślepka, bo ma widoczne odpowiedzi
+	Removed     bool
+	CanReply    bool
+	CanModerate bool
+	// Zgłaszanie: czytelnik zgłasza cudzy komentarz raz, wybierając powód z listy
+	CanReport     bool
+	Reported      bool
+	ReportReasons []string
+	// Ocena przydatności: czytelnik głosuje na cudze opublikowane komentarze,
+	// MyVote to jego obecny głos ("helpful", "unhelpful" lub pusty)
+	Helpful   int
+	Unhelpful int
+	CanVote   bool
+	MyVote    string
+	// Locale to zapis dat wybrany przez oglądającego - szablon komentarza nie widzi danych strony
+	Locale string
+}
+
+// commentThreads układa komentarze książki w drzewo wątków widoczne dla danej sesji:
+// opublikowane dla wszystkich, oczekujące dla autora i personelu. Odpowiedzi na
+// komentarz, którego nie ma (np. z innej książki), trafiają na najwyższy poziom.
+// Wątki są ułożone od najprzydatniejszych (według ocen czytelników), a odpowiedzi
+// w wątku - od najstarszej.
+func commentThreads(comments []*models.Comment, sess *session.Session) []*commentNode {
+	staff := isStaff(sess)
+	locale := ""
+	if sess != nil && sess.User != nil {
+		locale = sess.User.Locale
+	}
+
+	nodes := make(map[string]*commentNode, len(comments))
+	for _, comment := range comments {
+		node := &commentNode{
+			Comment:     comment,
+			CanReply:    sess != nil && comment.IsPublished(),
+			CanModerate: staff,
+			Locale:      locale,
+		}
+		if sess != nil && comment.IsPublished() && comment.UserID != sess.UserID {
+			node.Reported = comment.ReportedBy(sess.UserID)
+			node.CanReport = !node.Reported
+			node.ReportReasons = models.CommentReportReasons
+			node.CanVote = true
+			if vote := comment.VoteOf(sess.UserID); vote != nil {
+				node.MyVote = "unhelpful"
+				if vote.Helpful {
+					node.MyVote = "helpful"
+				}
+			}
+		}
+		node.Helpful, node.Unhelpful = comment.HelpfulVotes()
+		nodes[comment.ID] = node
+	}
+
+	var roots []*commentNode
+	for _, comment := range comments {
+		node := nodes[comment.ID]
+		if parent, ok := nodes[comment.ParentID]; ok && comment.ParentID != comment.ID {
+			parent.Replies = append(parent.Replies, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	visible := func(comment *models.Comment) bool {
+		switch comment.Status {
+		case models.CommentPublished:
+			return true
+		case models.CommentPending:
+			return staff || (sess != nil && sess.UserID == comment.UserID)
+		}
+		return false
+	}
+
+	var prune func([]*commentNode) []*commentNode
+	prune = func(list []*commentNode) []*commentNode {
+		var kept []*commentNode
+		for _, node := range list {
+			node.Replies = prune(node.Replies)
+			if !visible(node.Comment) {
+				if len(node.Replies) == 0 {
+					continue
+				}
+				node.Removed = true
+				node.CanReply = false
+				node.CanModerate = false
+				node.CanReport = false
+				node.Reported = false
+				node.CanVote = false
+				node.MyVote = ""
+			}
+			kept = append(kept, node)
+		}
+		return kept
+	}
+
+	threads := prune(roots)
+	// Stabilne sortowanie zachowuje kolejność od najstarszego przy równej ocenie
+	sort.SliceStable(threads, func(i, j int) bool {
+		return threadScore(threads[i]) > threadScore(threads[j])
+	})
+	return threads
+}
+
+// threadScore zwraca ocenę przydatności wątku - zaślepka usuniętego komentarza
+// nie przenosi jego głosów na górę dyskusji
+func threadScore(node *commentNode) int {
+	if node.Removed {
+		return 0
+	}
+	return node.HelpfulScore()
+}
+
+// publishedComments liczy komentarze widoczne dla wszystkich
+func publishedComments(comments []*models.Comment) int {
+	count := 0
+	for _, comment := range comments {
+		if comment.IsPublished() {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/middleware"
+	"library-management-system/internal/models"
+)
+
+// communicationsLimit to liczba ostatnich wiadomości na stronie wiadomości do czytelników
+const communicationsLimit = 20
+
+// CommunicationsHandler obsługuje wiadomości personelu do grup czytelników
+type CommunicationsHandler struct {
+	communicationsTemplate *template.Template
+	fbClient               *firebase.Client
+}
+
+// NewCommunicationsHandler tworzy handler wiadomości do czytelników
+func NewCommunicationsHandler(fbClient *firebase.Client) *CommunicationsHandler {
+	communicationsTmpl, err := template.New("communications.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/communications.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/communications.html: %v", err)
+	}
+
+	return &CommunicationsHandler{
+		communicationsTemplate: communicationsTmpl,
+		fbClient:               fbClient,
+	}
+}
+
+// audienceOption to grupa odbiorców w formularzu z aktualną liczbą czytelników
+type audienceOption struct {
+	models.CommunicationAudienceInfo
+	Recipients int
+}
+
+// ShowCommunications wyświetla formularz wiadomości do grupy czytelników i ostatnie
+// wysłane wiadomości z postępem wysyłki (GET /staff/communications)
+func (h *CommunicationsHandler) ShowCommunications(w http.ResponseWriter, r *http.Request) {
+	data := NewPageData(r)
+	data["Queued"] = r.URL.Query().Get("queued") == "1"
+	h.render(w, r, data)
+}
+
+// SendCommunication zleca wysyłkę wiadomości do wybranej grupy czytelników (POST /staff/communications).
+// Wiadomość trafia do czytelników w tle, partiami - postęp widać na liście wiadomości.
+func (h *CommunicationsHandler) SendCommunication(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	session := middleware.GetSessionFromContext(r.Context())
+	comm := &models.Communication{
+		Subject:  r.FormValue("subject"),
+		Body:     r.FormValue("body"),
+		Audience: models.CommunicationAudience(r.FormValue("audience")),
+	}
+	if session != nil && session.User != nil {
+		comm.SentBy = session.User.FullName()
+		comm.SentByID = session.User.ID
+	}
+
+	if err := h.fbClient.Traced(r.Context()).QueueCommunication(comm); err != nil {
+		log.Printf("Błąd zlecania wysyłki wiadomości do czytelników: %v", err)
+		data := NewPageData(r)
+		data["Error"] = "Nie udało się wysłać wiadomości: " + err.Error()
+		data["Form"] = comm
+		w.WriteHeader(http.StatusBadRequest)
+		h.render(w, r, data)
+		return
+	}
+
+	basepath.Redirect(w, r, "/staff/communications?queued=1", http.StatusSeeOther)
+}
+
+func (h *CommunicationsHandler) render(w http.ResponseWriter, r *http.Request, data TemplateData) {
+	if h.communicationsTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	if h.fbClient != nil {
+		var audiences []audienceOption
+		for _, info := range models.CommunicationAudiences {
+			recipients, err := h.fbClient.Traced(r.Context()).GetCommunicationAudience(info.Audience)
+			if err != nil {
+				log.Printf("Błąd pobierania odbiorców grupy %s: %v", info.Audience, err)
+			}
+			audiences = append(audiences, audienceOption{CommunicationAudienceInfo: info, Recipients: len(recipients)})
+		}
+		data["Audiences"] = audiences
+
+		comms, err := h.fbClient.Traced(r.Context()).ListCommunications(communicationsLimit)
+		if err != nil {
+			log.Printf("Błąd pobierania wiadomości do czytelników: %v", err)
+			data["Error"] = "Błąd pobierania wysłanych wiadomości"
+		}
+		data["Communications"] = comms
+	}
+
+	if err := h.communicationsTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania wiadomości do czytelników: %v", err)
+	}
+}
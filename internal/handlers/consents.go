@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"log"
+	"net/http"
+	"time"
+
+	"library-management-system/internal/models"
+)
+
+// ExportConsents eksportuje zgody wszystkich czytelników z datą i miejscem ostatniej decyzji
+// do rejestru czynności przetwarzania (GET /staff/users/consents.csv). Plik otwiera się
+// bezpośrednio w polskim Excelu (średnik jako separator, BOM UTF-8).
+func (h *StaffHandler) ExportConsents(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	users, err := h.fbClient.Traced(r.Context()).ListUsers()
+	if err != nil {
+		log.Printf("Błąd pobierania użytkowników do eksportu zgód: %v", err)
+		http.Error(w, "Błąd pobierania użytkowników z bazy danych", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="zgody-`+time.Now().Format("2006-01-02")+`.csv"`)
+	w.Write([]byte("\xef\xbb\xbf"))
+
+	writer := csv.NewWriter(w)
+	writer.Comma = ';'
+
+	header := []string{"ID", "Imię", "Nazwisko", "Email", "Telefon", "Data rejestracji"}
+	for _, consent := range models.ConsentTypes {
+		header = append(header, consent.Label+" - zgoda", consent.Label+" - data", consent.Label+" - źródło")
+	}
+	writer.Write(header)
+
+	for _, user := range users {
+		if user.Role != models.RoleReader {
+			continue
+		}
+		row := []string{user.ID, user.FirstName, user.LastName, user.Email, user.Phone, user.CreatedAt.Format("2006-01-02")}
+		for _, info := range models.ConsentTypes {
+			consent := user.Consent(info.Type)
+			switch {
+			case consent.UpdatedAt.IsZero():
+				row = append(row, "brak decyzji", "", "")
+			case consent.Granted:
+				row = append(row, "tak", consent.UpdatedAt.Format("2006-01-02 15:04:05"), consent.Source.Label())
+			default:
+				row = append(row, "nie", consent.UpdatedAt.Format("2006-01-02 15:04:05"), consent.Source.Label())
+			}
+		}
+		writer.Write(row)
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Printf("Błąd zapisu eksportu zgód CSV: %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/covers"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+)
+
+// CoversHandler serwuje okładki książek z własnej domeny
+type CoversHandler struct {
+	fbClient *firebase.Client
+	store    *covers.Store
+}
+
+// NewCoversHandler tworzy handler okładek korzystający ze wspólnej pamięci okładek
+func NewCoversHandler(fbClient *firebase.Client, store *covers.Store) *CoversHandler {
+	return &CoversHandler{
+		fbClient: fbClient,
+		store:    store,
+	}
+}
+
+// ServeCover zwraca okładkę książki zmniejszoną do szerokości z parametru w
+// (GET /covers/{id}?w=320). Książka bez okładki albo z okładką, której nie udało się
+// pobrać, dostaje okładkę zastępczą z tytułem i autorem - jako SVG, a z parametrem
+// format=png jako PNG (podglądy linków w komunikatorach nie wyświetlają SVG).
+func (h *CoversHandler) ServeCover(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	width := covers.DefaultWidth
+	if value := r.URL.Query().Get("w"); value != "" {
+		width, _ = strconv.Atoi(value)
+		if !covers.ValidWidth(width) {
+			http.Error(w, "Nieobsługiwana szerokość okładki", http.StatusBadRequest)
+			return
+		}
+	}
+
+	book, err := h.fbClient.Traced(r.Context()).GetBook(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Książka nie znaleziona", http.StatusNotFound)
+		return
+	}
+
+	if book.CoverImageURL == "" {
+		h.servePlaceholder(w, r, book, width, time.Hour)
+		return
+	}
+
+	data, err := h.store.Image(book.CoverImageURL, width)
+	if err != nil {
+		log.Printf("Okładka książki %s: %v", book.ID, err)
+		// Krótki czas ważności pozwala pokazać prawdziwą okładkę, gdy jej serwer znów odpowiada
+		h.servePlaceholder(w, r, book, width, 5*time.Minute)
+		return
+	}
+
+	// Adres okładki jest stały dla książki, a ETag zmienia się razem z CoverImageURL -
+	// po godzinie przeglądarka sprawdza, czy okładki nie zmieniono
+	w.Header().Set("Content-Type", "image/jpeg")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Header().Set("ETag", `"`+covers.Key(book.CoverImageURL, width)+`"`)
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+}
+
+// servePlaceholder wysyła okładkę zastępczą książki ważną w przeglądarce przez maxAge
+func (h *CoversHandler) servePlaceholder(w http.ResponseWriter, r *http.Request, book *models.Book, width int, maxAge time.Duration) {
+	var data []byte
+	var err error
+	contentType := "image/svg+xml"
+	etag := covers.PlaceholderKey(book.Title, book.Author, 0)
+	if r.URL.Query().Get("format") == "png" {
+		contentType = "image/png"
+		etag = covers.PlaceholderKey(book.Title, book.Author, width)
+		data, err = h.store.PlaceholderPNG(book.Title, book.Author, width)
+	} else {
+		data, err = covers.PlaceholderSVG(book.Title, book.Author)
+	}
+	if err != nil {
+		log.Printf("Błąd tworzenia okładki zastępczej książki %s: %v", book.ID, err)
+		http.Error(w, "Błąd tworzenia okładki", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(maxAge.Seconds())))
+	w.Header().Set("ETag", `"`+etag+`"`)
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+}
@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"library-management-system/internal/events"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+)
+
+// deskRefreshInterval to odstęp odświeżania panelu bez zmian w wypożyczeniach
+// (mijają terminy zwrotów i rezerwacji, zmienia się dzień)
+const deskRefreshInterval = time.Minute
+
+// DeskHandler obsługuje panel wypożyczalni na dashboardzie personelu: zwroty na dziś
+// i najbliższy dzień otwarcia, czytelników do kontaktu i wygasające rezerwacje.
+// Otwarte strony dostają nową treść panelu przez Server-Sent Events.
+type DeskHandler struct {
+	deskTemplate *template.Template
+	fbClient     *firebase.Client
+
+	mu      sync.Mutex
+	clients map[chan struct{}]bool
+}
+
+// DeskContact to czytelnik z przetrzymanymi książkami, z którym trzeba się skontaktować
+type DeskContact struct {
+	User  *models.User
+	Loans []*models.Loan
+}
+
+// DeskSummary to zawartość panelu wypożyczalni
+type DeskSummary struct {
+	Today       time.Time
+	NextOpenDay time.Time
+	OpenToday   bool
+
+	DueToday      []*models.Loan        // Termin zwrotu mija dziś
+	DueNext       []*models.Loan        // Termin mija przed końcem najbliższego dnia otwarcia
+	Contacts      []*DeskContact        // Czytelnicy z książkami po terminie
+	ExpiringHolds []*models.Reservation // Rezerwacje, których nie da się odebrać po dzisiejszym dniu
+	UpdatedAt     time.Time
+}
+
+// NextOpenDayIsTomorrow sprawdza czy najbliższy dzień otwarcia to jutro
+func (s *DeskSummary) NextOpenDayIsTomorrow() bool {
+	return s.NextOpenDay.Equal(s.Today.AddDate(0, 0, 1))
+}
+
+// NewDeskHandler tworzy handler panelu wypożyczalni
+func NewDeskHandler(fbClient *firebase.Client) *DeskHandler {
+	deskTmpl, err := template.New("desk.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/desk.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/desk.html: %v", err)
+	}
+
+	return &DeskHandler{
+		deskTemplate: deskTmpl,
+		fbClient:     fbClient,
+		clients:      make(map[chan struct{}]bool),
+	}
+}
+
+// Register subskrybuje zdarzenia zmieniające zawartość panelu
+func (h *DeskHandler) Register() {
+	for _, eventType := range []events.Type{events.CirculationChanged, events.ReservationReady} {
+		events.Subscribe(eventType, func(e events.Event) {
+			if h.fbClient != nil && e.Tenant == h.fbClient.Tenant() {
+				h.notify()
+			}
+		})
+	}
+}
+
+// notify powiadamia otwarte strony o zmianie. Kolejne powiadomienia przed odświeżeniem
+// panelu łączą się w jedno.
+func (h *DeskHandler) notify() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.clients {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (h *DeskHandler) subscribe() chan struct{} {
+	ch := make(chan struct{}, 1)
+	h.mu.Lock()
+	h.clients[ch] = true
+	h.mu.Unlock()
+	return ch
+}
+
+func (h *DeskHandler) unsubscribe(ch chan struct{}) {
+	h.mu.Lock()
+	delete(h.clients, ch)
+	h.mu.Unlock()
+}
+
+// summary zbiera zawartość panelu. Błędy są logowane, a panel pokazuje to, co udało się pobrać.
+func (h *DeskHandler) summary() *DeskSummary {
+	now := time.Now()
+	settings := models.DefaultSettings()
+	if s, err := h.fbClient.GetSettings(); err == nil {
+		settings = s
+	} else {
+		log.Printf("Błąd pobierania ustawień: %v", err)
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	summary := &DeskSummary{
+		Today:       today,
+		NextOpenDay: settings.NextOpenDay(today),
+		OpenToday:   settings.IsOpenWeekday(int(today.Weekday())),
+		UpdatedAt:   now,
+	}
+	tomorrow := today.AddDate(0, 0, 1)
+	afterNextOpenDay := summary.NextOpenDay.AddDate(0, 0, 1)
+
+	loans, err := h.fbClient.GetActiveLoans()
+	if err != nil {
+		log.Printf("Błąd pobierania aktywnych wypożyczeń: %v", err)
+	}
+
+	overdue := make(map[string][]*models.Loan)
+	for _, loan := range loans {
+		if loan.IsReadingRoom() {
+			continue // Udostępnienia na miejscu wracają automatycznie na koniec dnia
+		}
+		switch {
+		case loan.DueDate.Before(today):
+			overdue[loan.UserID] = append(overdue[loan.UserID], loan)
+		case loan.DueDate.Before(tomorrow):
+			summary.DueToday = append(summary.DueToday, loan)
+		case loan.DueDate.Before(afterNextOpenDay):
+			summary.DueNext = append(summary.DueNext, loan)
+		}
+	}
+	sortLoansByDueDate(summary.DueToday)
+	sortLoansByDueDate(summary.DueNext)
+	summary.Contacts = h.contacts(overdue)
+
+	// Gotowa rezerwacja wygasająca przed najbliższym dniem otwarcia musi zostać odebrana dziś
+	reservations, err := h.fbClient.GetReadyReservations()
+	if err != nil {
+		log.Printf("Błąd pobierania gotowych rezerwacji: %v", err)
+	}
+	for _, reservation := range reservations {
+		if !reservation.ExpiryDate.Before(today) && reservation.ExpiryDate.Before(summary.NextOpenDay) {
+			summary.ExpiringHolds = append(summary.ExpiringHolds, reservation)
+		}
+	}
+
+	return summary
+}
+
+// contacts zwraca czytelników z książkami po terminie (najdłużej przetrzymujący najpierw)
+func (h *DeskHandler) contacts(overdue map[string][]*models.Loan) []*DeskContact {
+	if len(overdue) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(overdue))
+	for id := range overdue {
+		ids = append(ids, id)
+	}
+	users, err := h.fbClient.GetUsersByIDs(ids)
+	if err != nil {
+		log.Printf("Błąd pobierania czytelników do kontaktu: %v", err)
+		users = map[string]*models.User{}
+	}
+
+	contacts := make([]*DeskContact, 0, len(overdue))
+	for id, loans := range overdue {
+		sortLoansByDueDate(loans)
+		user := users[id]
+		if user == nil {
+			user = &models.User{ID: id, FirstName: loans[0].UserName}
+		}
+		contacts = append(contacts, &DeskContact{User: user, Loans: loans})
+	}
+	sort.Slice(contacts, func(i, j int) bool {
+		return contacts[i].Loans[0].DueDate.Before(contacts[j].Loans[0].DueDate)
+	})
+	return contacts
+}
+
+func sortLoansByDueDate(loans []*models.Loan) {
+	sort.Slice(loans, func(i, j int) bool {
+		return loans[i].DueDate.Before(loans[j].DueDate)
+	})
+}
+
+// Stream wysyła aktualną treść panelu jako zdarzenia SSE "desk" (GET /staff/desk/stream):
+// od razu po połączeniu, po każdej zmianie wypożyczeń lub rezerwacji i co minutę
+func (h *DeskHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	if h.deskTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Strumieniowanie nie jest obsługiwane", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // Nginx nie buforuje strumienia
+
+	changes := h.subscribe()
+	defer h.unsubscribe(changes)
+
+	ticker := time.NewTicker(deskRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := h.writeEvent(w); err != nil {
+			log.Printf("Błąd wysyłania panelu wypożyczalni: %v", err)
+			return
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-changes:
+		case <-ticker.C:
+		}
+	}
+}
+
+// writeEvent renderuje panel i zapisuje go jako jedno zdarzenie SSE
+// (każda linia HTML w osobnym polu data)
+func (h *DeskHandler) writeEvent(w http.ResponseWriter) error {
+	var buf bytes.Buffer
+	if err := h.deskTemplate.ExecuteTemplate(&buf, "desk", h.summary()); err != nil {
+		return err
+	}
+
+	var event strings.Builder
+	event.WriteString("event: desk\n")
+	for _, line := range strings.Split(buf.String(), "\n") {
+		event.WriteString("data: " + strings.TrimRight(line, "\r") + "\n")
+	}
+	event.WriteString("\n")
+
+	_, err := fmt.Fprint(w, event.String())
+	return err
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/middleware"
+	"library-management-system/internal/models"
+	"library-management-system/internal/notifications"
+)
+
+// EmailTemplatesHandler obsługuje edycję szablonów wiadomości email wysyłanych czytelnikom
+type EmailTemplatesHandler struct {
+	listTemplate *template.Template
+	editTemplate *template.Template
+	fbClient     *firebase.Client
+	mailer       *notifications.Mailer
+	baseURL      string
+}
+
+// NewEmailTemplatesHandler tworzy handler szablonów wiadomości. mailer wysyła wiadomości
+// testowe, a baseURL służy do przykładowego linku w podglądzie.
+func NewEmailTemplatesHandler(fbClient *firebase.Client, mailer *notifications.Mailer, baseURL string) *EmailTemplatesHandler {
+	listTmpl, err := template.New("email_templates.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/email_templates.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/email_templates.html: %v", err)
+	}
+
+	editTmpl, err := template.New("email_template_edit.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/email_template_edit.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/email_template_edit.html: %v", err)
+	}
+
+	return &EmailTemplatesHandler{
+		listTemplate: listTmpl,
+		editTemplate: editTmpl,
+		fbClient:     fbClient,
+		mailer:       mailer,
+		baseURL:      strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// emailTemplateRow to rodzaj wiadomości na liście szablonów
+type emailTemplateRow struct {
+	*models.EmailTemplateKind
+	Custom *models.EmailTemplate // nil - treść domyślna
+}
+
+// ListTemplates wyświetla rodzaje wiadomości i informację, które zmieniono (GET /staff/templates)
+func (h *EmailTemplatesHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	if h.listTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	data := NewPageData(r)
+	switch r.URL.Query().Get("done") {
+	case "saved":
+		data["Notice"] = "Szablon wiadomości został zapisany"
+	case "reset":
+		data["Notice"] = "Przywrócono domyślną treść wiadomości"
+	}
+
+	var custom map[models.EmailTemplateKey]*models.EmailTemplate
+	if h.fbClient != nil {
+		var err error
+		if custom, err = h.fbClient.Traced(r.Context()).GetCustomEmailTemplates(); err != nil {
+			log.Printf("Błąd pobierania szablonów wiadomości: %v", err)
+			data["Error"] = "Błąd pobierania szablonów z bazy danych"
+		}
+	}
+
+	rows := make([]emailTemplateRow, 0, len(models.EmailTemplateKinds))
+	for i := range models.EmailTemplateKinds {
+		kind := &models.EmailTemplateKinds[i]
+		rows = append(rows, emailTemplateRow{EmailTemplateKind: kind, Custom: custom[kind.Key]})
+	}
+	data["Templates"] = rows
+
+	if err := h.listTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania listy szablonów wiadomości: %v", err)
+	}
+}
+
+// ShowTemplate wyświetla formularz szablonu z podglądem zapisanej treści (GET /staff/templates/{key})
+func (h *EmailTemplatesHandler) ShowTemplate(w http.ResponseWriter, r *http.Request) {
+	kind := models.GetEmailTemplateKind(models.EmailTemplateKey(chi.URLParam(r, "key")))
+	if kind == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	form := kind.Default()
+	if h.fbClient != nil {
+		saved, err := h.fbClient.Traced(r.Context()).GetEmailTemplate(kind.Key)
+		if err != nil {
+			log.Printf("Błąd pobierania szablonu wiadomości %s: %v", kind.Key, err)
+			http.Error(w, "Błąd pobierania szablonu wiadomości", http.StatusInternalServerError)
+			return
+		}
+		form = saved
+	}
+
+	data := NewPageData(r)
+	h.renderEdit(w, r, data, kind, form)
+}
+
+// SaveTemplate zapisuje treść szablonu (POST /staff/templates/{key})
+func (h *EmailTemplatesHandler) SaveTemplate(w http.ResponseWriter, r *http.Request) {
+	kind := models.GetEmailTemplateKind(models.EmailTemplateKey(chi.URLParam(r, "key")))
+	if kind == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	session := middleware.GetSessionFromContext(r.Context())
+	form := readEmailTemplateForm(r, kind)
+	form.UpdatedBy = session.User.FirstName + " " + session.User.LastName
+
+	if err := h.fbClient.Traced(r.Context()).SaveEmailTemplate(form); err != nil {
+		data := NewPageData(r)
+		data["Error"] = "Nie udało się zapisać szablonu: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		h.renderEdit(w, r, data, kind, form)
+		return
+	}
+
+	basepath.Redirect(w, r, "/staff/templates?done=saved", http.StatusSeeOther)
+}
+
+// PreviewTemplate pokazuje niezapisaną treść z formularza z przykładowymi wartościami
+// zmiennych (POST /staff/templates/{key}/preview)
+func (h *EmailTemplatesHandler) PreviewTemplate(w http.ResponseWriter, r *http.Request) {
+	kind := models.GetEmailTemplateKind(models.EmailTemplateKey(chi.URLParam(r, "key")))
+	if kind == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	form := readEmailTemplateForm(r, kind)
+	data := NewPageData(r)
+	if err := kind.Validate(form); err != nil {
+		data["Error"] = err.Error()
+	}
+	h.renderEdit(w, r, data, kind, form)
+}
+
+// SendTest wysyła treść z formularza z przykładowymi wartościami na adres zalogowanej
+// osoby z personelu (POST /staff/templates/{key}/test)
+func (h *EmailTemplatesHandler) SendTest(w http.ResponseWriter, r *http.Request) {
+	kind := models.GetEmailTemplateKind(models.EmailTemplateKey(chi.URLParam(r, "key")))
+	if kind == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	session := middleware.GetSessionFromContext(r.Context())
+	form := readEmailTemplateForm(r, kind)
+	data := NewPageData(r)
+
+	err := kind.Validate(form)
+	if err == nil && !h.mailer.IsConfigured() {
+		err = fmt.Errorf("wysyłka email nie jest skonfigurowana (SMTP_HOST)")
+	}
+	if err == nil {
+		subject, body := form.Render(h.exampleVars(r, kind))
+		err = h.mailer.Send(session.User.Email, "[TEST] "+subject, body)
+	}
+	if err != nil {
+		log.Printf("Błąd wysyłania wiadomości testowej %s: %v", kind.Key, err)
+		data["Error"] = "Nie udało się wysłać wiadomości testowej: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+	} else {
+		data["Notice"] = "Wiadomość testowa została wysłana na adres " + session.User.Email
+	}
+	h.renderEdit(w, r, data, kind, form)
+}
+
+// ResetTemplate przywraca domyślną treść wiadomości (POST /staff/templates/{key}/reset)
+func (h *EmailTemplatesHandler) ResetTemplate(w http.ResponseWriter, r *http.Request) {
+	kind := models.GetEmailTemplateKind(models.EmailTemplateKey(chi.URLParam(r, "key")))
+	if kind == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := h.fbClient.Traced(r.Context()).ResetEmailTemplate(kind.Key); err != nil {
+		log.Printf("Błąd przywracania szablonu wiadomości %s: %v", kind.Key, err)
+		http.Error(w, "Nie udało się przywrócić domyślnej treści", http.StatusInternalServerError)
+		return
+	}
+
+	basepath.Redirect(w, r, "/staff/templates?done=reset", http.StatusSeeOther)
+}
+
+func (h *EmailTemplatesHandler) renderEdit(w http.ResponseWriter, r *http.Request, data TemplateData, kind *models.EmailTemplateKind, form *models.EmailTemplate) {
+	if h.editTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	subject, body := form.Render(h.exampleVars(r, kind))
+	data["Kind"] = kind
+	data["Form"] = form
+	data["PreviewSubject"] = subject
+	data["PreviewBody"] = body
+	data["CanEmail"] = h.mailer.IsConfigured()
+
+	if err := h.editTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania szablonu wiadomości: %v", err)
+	}
+}
+
+// exampleVars to przykładowe wartości zmiennych - imię i nazwa biblioteki są prawdziwe,
+// żeby podgląd wyglądał jak wiadomość, którą dostanie czytelnik
+func (h *EmailTemplatesHandler) exampleVars(r *http.Request, kind *models.EmailTemplateKind) map[string]string {
+	vars := kind.Examples()
+	if session := middleware.GetSessionFromContext(r.Context()); session != nil && session.User != nil {
+		vars["imie"] = session.User.FirstName
+	}
+	vars["biblioteka"] = libraryName(h.fbClient)
+	vars["link"] = h.baseURL + "/user"
+	return vars
+}
+
+// readEmailTemplateForm odczytuje temat i treść szablonu z formularza
+func readEmailTemplateForm(r *http.Request, kind *models.EmailTemplateKind) *models.EmailTemplate {
+	return &models.EmailTemplate{
+		Key:     kind.Key,
+		Subject: strings.TrimSpace(r.FormValue("subject")),
+		// Przeglądarki wysyłają treść pola textarea z końcami linii CRLF
+		Body: strings.TrimSpace(strings.ReplaceAll(r.FormValue("body"), "\r\n", "\n")),
+	}
+}
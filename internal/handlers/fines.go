@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/middleware"
+	"library-management-system/internal/models"
+)
+
+// FinesHandler obsługuje rozliczanie kar: pokwitowania wpłat przy ladzie, dzienny
+// raport kasowy do uzgodnienia z kasą fiskalną i terminalem oraz umorzenia zbiorcze
+type FinesHandler struct {
+	receiptTemplate    *template.Template
+	cashReportTemplate *template.Template
+	amnestyTemplate    *template.Template
+	fbClient           *firebase.Client
+}
+
+// CashReportTotal to suma wpłat jednym sposobem płatności
+type CashReportTotal struct {
+	Label  string
+	Count  int
+	Amount models.Money
+}
+
+// NewFinesHandler tworzy handler rozliczeń kar
+func NewFinesHandler(fbClient *firebase.Client) *FinesHandler {
+	receiptTmpl, err := template.New("fine_receipt.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/fine_receipt.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/fine_receipt.html: %v", err)
+	}
+
+	cashReportTmpl, err := template.New("cash_report.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/cash_report.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/cash_report.html: %v", err)
+	}
+
+	amnestyTmpl, err := template.New("fine_amnesty.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/fine_amnesty.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/fine_amnesty.html: %v", err)
+	}
+
+	return &FinesHandler{
+		receiptTemplate:    receiptTmpl,
+		cashReportTemplate: cashReportTmpl,
+		amnestyTemplate:    amnestyTmpl,
+		fbClient:           fbClient,
+	}
+}
+
+// ShowReceipt wyświetla pokwitowanie wpłaty do wydruku (GET /staff/fine-payments/{id}/receipt)
+func (h *FinesHandler) ShowReceipt(w http.ResponseWriter, r *http.Request) {
+	if h.receiptTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return
+	}
+
+	payment, err := h.fbClient.Traced(r.Context()).GetFinePayment(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("Błąd pobierania wpłaty: %v", err)
+		http.Error(w, "Nie znaleziono wpłaty", http.StatusNotFound)
+		return
+	}
+
+	data := NewPageData(r)
+	data["Payment"] = payment
+
+	if err := h.receiptTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania pokwitowania: %v", err)
+	}
+}
+
+// ShowCashReport wyświetla wpłaty kar z jednego dnia z sumami dla gotówki i terminala
+// (GET /staff/fine-payments?date=2006-01-02, domyślnie dzisiaj)
+func (h *FinesHandler) ShowCashReport(w http.ResponseWriter, r *http.Request) {
+	if h.cashReportTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if value := r.URL.Query().Get("date"); value != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", value, now.Location())
+		if err != nil {
+			http.Error(w, "Nieprawidłowa data", http.StatusBadRequest)
+			return
+		}
+		day = parsed
+	}
+
+	payments, err := h.fbClient.Traced(r.Context()).ListFinePayments(day, day.AddDate(0, 0, 1))
+	if err != nil {
+		log.Printf("Błąd pobierania wpłat: %v", err)
+		http.Error(w, "Błąd pobierania wpłat", http.StatusInternalServerError)
+		return
+	}
+
+	cash := &CashReportTotal{Label: "Gotówka"}
+	terminal := &CashReportTotal{Label: "Karta (terminal)"}
+	var total models.Money
+	for _, payment := range payments {
+		t := cash
+		if payment.Method == models.PaymentTerminal {
+			t = terminal
+		}
+		t.Count++
+		t.Amount += payment.Amount
+		total += payment.Amount
+	}
+
+	data := NewPageData(r)
+	data["Day"] = day
+	data["PrevDay"] = day.AddDate(0, 0, -1).Format("2006-01-02")
+	if next := day.AddDate(0, 0, 1); !next.After(now) {
+		data["NextDay"] = next.Format("2006-01-02")
+	}
+	data["Payments"] = payments
+	data["Totals"] = []*CashReportTotal{cash, terminal}
+	data["Total"] = total
+
+	if err := h.cashReportTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania raportu kasowego: %v", err)
+	}
+}
+
+// ShowAmnesty wyświetla narzędzie umorzeń zbiorczych (GET /staff/fine-amnesty).
+// Gdy podano kryteria, pokazuje podgląd kar, które zostaną umorzone.
+func (h *FinesHandler) ShowAmnesty(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return
+	}
+
+	data := NewPageData(r)
+	data["Waived"] = r.URL.Query().Get("waived") == "1"
+	data["Form"] = fineWaiverForm(r)
+
+	criteria, err := parseFineWaiverCriteria(r)
+	if err != nil {
+		data["Error"] = err.Error()
+	} else if !criteria.IsEmpty() {
+		loans, err := h.fbClient.Traced(r.Context()).FindWaivableFines(criteria)
+		if err != nil {
+			log.Printf("Błąd wyszukiwania kar do umorzenia: %v", err)
+			http.Error(w, "Błąd wyszukiwania kar", http.StatusInternalServerError)
+			return
+		}
+
+		readers := make(map[string]bool)
+		var total models.Money
+		for _, loan := range loans {
+			readers[loan.UserID] = true
+			total += loan.FineAmount
+		}
+		data["Preview"] = true
+		data["Criteria"] = criteria
+		data["Loans"] = loans
+		data["ReaderCount"] = len(readers)
+		data["Total"] = total
+	}
+
+	h.renderAmnesty(w, data)
+}
+
+// WaiveFines umarza kary spełniające kryteria po potwierdzeniu podglądu (POST /staff/fine-amnesty)
+func (h *FinesHandler) WaiveFines(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return
+	}
+
+	criteria, err := parseFineWaiverCriteria(r)
+	if err == nil && r.FormValue("confirm") != "on" {
+		err = fmt.Errorf("potwierdź umorzenie kar")
+	}
+	if err == nil {
+		var waiver *models.FineWaiver
+		waiver, err = h.fbClient.Traced(r.Context()).WaiveFines(criteria, strings.TrimSpace(r.FormValue("reason")), session.User.Email)
+		if waiver != nil {
+			log.Printf("Umorzenie kar: %d kar, %d czytelników, kwota %s, wykonał %s", waiver.LoanCount, waiver.ReaderCount, waiver.Amount, session.User.Email)
+		}
+	}
+	if err != nil {
+		data := NewPageData(r)
+		data["Form"] = fineWaiverForm(r)
+		data["Error"] = "Nie udało się umorzyć kar: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		h.renderAmnesty(w, data)
+		return
+	}
+
+	basepath.Redirect(w, r, "/staff/fine-amnesty?waived=1", http.StatusSeeOther)
+}
+
+func (h *FinesHandler) renderAmnesty(w http.ResponseWriter, data TemplateData) {
+	if h.amnestyTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	waivers, err := h.fbClient.ListFineWaivers(20)
+	if err != nil {
+		log.Printf("Błąd pobierania dziennika umorzeń: %v", err)
+	}
+	data["Waivers"] = waivers
+
+	if err := h.amnestyTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania umorzeń kar: %v", err)
+	}
+}
+
+// fineWaiverForm zwraca wartości pól formularza kryteriów (do ponownego wypełnienia)
+func fineWaiverForm(r *http.Request) map[string]string {
+	return map[string]string{
+		"MaxAmount":    r.FormValue("max_amount"),
+		"ReturnedFrom": r.FormValue("returned_from"),
+		"ReturnedTo":   r.FormValue("returned_to"),
+		"Reason":       r.FormValue("reason"),
+	}
+}
+
+// parseFineWaiverCriteria odczytuje kryteria umorzenia z formularza
+// (max_amount, returned_from i returned_to w formacie 2006-01-02)
+func parseFineWaiverCriteria(r *http.Request) (models.FineWaiverCriteria, error) {
+	var criteria models.FineWaiverCriteria
+
+	if value := strings.TrimSpace(r.FormValue("max_amount")); value != "" {
+		amount, err := models.ParseMoney(value)
+		if err != nil || amount <= 0 {
+			return criteria, fmt.Errorf("nieprawidłowa kwota")
+		}
+		criteria.MaxAmount = amount
+	}
+
+	for _, field := range []struct {
+		name   string
+		target *time.Time
+	}{
+		{"returned_from", &criteria.ReturnedFrom},
+		{"returned_to", &criteria.ReturnedTo},
+	} {
+		if value := r.FormValue(field.name); value != "" {
+			day, err := time.ParseInLocation("2006-01-02", value, time.Local)
+			if err != nil {
+				return criteria, fmt.Errorf("nieprawidłowa data")
+			}
+			*field.target = day
+		}
+	}
+
+	if !criteria.ReturnedFrom.IsZero() && !criteria.ReturnedTo.IsZero() && criteria.ReturnedTo.Before(criteria.ReturnedFrom) {
+		return criteria, fmt.Errorf("data końcowa jest wcześniejsza niż początkowa")
+	}
+
+	return criteria, nil
+}
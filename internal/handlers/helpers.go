@@ -1,32 +1,213 @@
 package handlers
 
 import (
+	"html/template"
+	"log"
+	"net/http"
+	"time"
+
+	"library-management-system/internal/assets"
+	"library-management-system/internal/barcode"
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/demo"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/markdown"
+	"library-management-system/internal/middleware"
 	"library-management-system/internal/models"
+	"library-management-system/internal/nav"
 	"library-management-system/internal/session"
 )
 
 // TemplateData zawiera wspólne dane dla wszystkich szablonów
 type TemplateData map[string]interface{}
 
-// NewTemplateData tworzy nowe dane szablonu z automatycznym dodaniem użytkownika
+// NewTemplateData tworzy nowe dane szablonu z automatycznym dodaniem użytkownika z sesji.
+// Handlery stron używają NewPageData, które dodaje też aktualne dane użytkownika z bazy.
 func NewTemplateData(sess *session.Session) TemplateData {
 	data := make(TemplateData)
 
 	if sess != nil {
 		data["User"] = sess.User
 		data["IsLoggedIn"] = true
-		data["IsAdmin"] = sess.User.Role == models.RoleAdmin
+		data["IsAdmin"] = sess.User.IsAdmin()
+		data["IsStaff"] = sess.User.IsStaff()
+		data["Locale"] = sess.User.Locale
 	} else {
 		data["User"] = nil
 		data["IsLoggedIn"] = false
 		data["IsAdmin"] = false
+		data["IsStaff"] = false
+		data["Locale"] = ""
 	}
 
 	return data
 }
 
+// isStaff sprawdza czy sesja należy do pracownika biblioteki (administratora lub bibliotekarza)
+func isStaff(sess *session.Session) bool {
+	return sess != nil && sess.User != nil && sess.User.IsStaff()
+}
+
+// recordStaffActivity zlicza czynność pracownika z bieżącej sesji do statystyk personelu.
+// Zapis odbywa się w tle, żeby nie spowalniać pracy przy ladzie; błąd jest tylko logowany.
+func recordStaffActivity(fbClient *firebase.Client, r *http.Request, action models.StaffAction) {
+	sess := middleware.GetSessionFromContext(r.Context())
+	if fbClient == nil || !isStaff(sess) {
+		return
+	}
+
+	day := time.Now().Format("2006-01-02")
+	go func() {
+		if err := fbClient.IncrementStaffActivity(day, sess.User, action); err != nil {
+			log.Printf("Błąd zapisu czynności personelu: %v", err)
+		}
+	}()
+}
+
+// recordAudit zapisuje w dzienniku zmian czynność pracownika z bieżącej sesji, żeby można ją
+// było cofnąć. audit to stan dokumentów sprzed czynności (nil, gdy nie udało się go pobrać).
+// Czynność jest już wykonana, więc błąd zapisu jest tylko logowany.
+func recordAudit(r *http.Request, audit *firebase.AuditRecorder, action models.AuditAction, entityID, label string) {
+	sess := middleware.GetSessionFromContext(r.Context())
+	if audit == nil || !isStaff(sess) {
+		return
+	}
+
+	if err := audit.Record(action, entityID, label, sess.User); err != nil {
+		log.Printf("Błąd zapisu dziennika zmian (%s %s): %v", action, entityID, err)
+	}
+}
+
 // Set ustawia wartość w danych szablonu
 func (t TemplateData) Set(key string, value interface{}) TemplateData {
 	t[key] = value
 	return t
 }
+
+// templateFuncs zwraca funkcje pomocnicze wspólne dla wszystkich szablonów
+// (fbClient to klient biblioteki, której nazwę pokazują szablony)
+func templateFuncs(fbClient *firebase.Client) template.FuncMap {
+	return template.FuncMap{
+		"sub": func(a, b int) int {
+			return a - b
+		},
+		"add": func(a, b int) int {
+			return a + b
+		},
+		"markdown": markdown.Render,
+		"barcode":  barcode.SVG,
+		"asset":    assets.Path,
+		"url":      basepath.URL,
+		"libraryName": func() string {
+			return libraryName(fbClient)
+		},
+		"demoBanner":  demoBanner,
+		"staffSearch": staffSearch,
+		"pwaHead":     pwaHead,
+		"staffNav": func(page *nav.Page) template.HTML {
+			return staffNav(page, fbClient != nil && fbClient.Tenant() == "")
+		},
+		"readerNav":   readerNav,
+		"breadcrumbs": breadcrumbs,
+		"menuLinks": func() template.HTML {
+			return menuLinks(fbClient)
+		},
+		"footerLinks": func() template.HTML {
+			return footerLinks(fbClient)
+		},
+		"money": func(m models.Money) string {
+			return formatMoney(fbClient, m)
+		},
+		// Daty przyjmują time.Time lub *time.Time; brak daty (nil, zero) daje pusty tekst.
+		// Strony czytelnika podają drugim argumentem zapis wybrany przez czytelnika ($.Locale),
+		// bez niego daty są w zapisie z ustawień biblioteki.
+		"formatDate": func(t interface{}, locale ...string) string {
+			return formatTime(fbClient, t, locale, (*models.Settings).FormatDate)
+		},
+		"formatDateTime": func(t interface{}, locale ...string) string {
+			return formatTime(fbClient, t, locale, (*models.Settings).FormatDateTime)
+		},
+		"relativeTime": func(t interface{}, locale ...string) string {
+			return formatTime(fbClient, t, locale, (*models.Settings).RelativeTime)
+		},
+	}
+}
+
+// demoBanner zwraca baner wersji demonstracyjnej z danymi logowania (pusty poza trybem demo)
+func demoBanner() template.HTML {
+	if !demo.Enabled() {
+		return ""
+	}
+	return template.HTML(`<div class="bg-yellow-300 text-yellow-900 text-sm text-center px-4 py-2 print:hidden">` +
+		`<strong>Wersja demonstracyjna</strong> - dane są przywracane każdej nocy. ` +
+		`Bibliotekarz: ` + demo.AdminEmail + `, czytelnik: ` + demo.ReaderEmail + `, hasło: ` + demo.Password +
+		`</div>`)
+}
+
+// pwaHead zwraca znaczniki aplikacji instalowanej: manifest, kolor paska i skrypt
+// rejestrujący service worker. Wstawiane w nagłówku stron odwiedzanych przez czytelników.
+func pwaHead() template.HTML {
+	manifest := template.HTMLEscapeString(basepath.URL("/manifest.webmanifest"))
+	script := template.HTMLEscapeString(assets.Path("js/pwa.js"))
+	base := template.HTMLEscapeString(basepath.URL("/"))
+	return template.HTML(`<link rel="manifest" href="` + manifest + `">` +
+		`<meta name="theme-color" content="#1f2937">` +
+		`<script src="` + script + `" data-base="` + base + `" defer></script>`)
+}
+
+// staffSearch zwraca przycisk i okno szybkiego wyszukiwania personelu (Ctrl+K).
+// Wstawiane w pasku bocznym stron panelu personelu.
+func staffSearch() template.HTML {
+	action := template.HTMLEscapeString(basepath.URL("/staff/search"))
+	script := template.HTMLEscapeString(assets.Path("js/staff-search.js"))
+	return template.HTML(`<button type="button" data-staff-search-open class="w-full mb-4 flex items-center justify-between px-4 py-2 text-sm text-gray-500 border border-gray-300 rounded-lg hover:bg-gray-50">` +
+		`<span>Szukaj...</span><kbd class="font-mono text-xs">Ctrl+K</kbd></button>` +
+		`<dialog id="staff-search" class="w-full max-w-2xl rounded-lg shadow-xl p-0 backdrop:bg-black/40">` +
+		`<form method="GET" action="` + action + `" class="p-4 border-b border-gray-200">` +
+		`<input type="search" name="q" autocomplete="off" placeholder="Czytelnik, karta, tytuł, ISBN, kod z etykiety lub kod odbioru" ` +
+		`class="w-full px-3 py-2 border border-gray-300 rounded-lg focus:ring-2 focus:ring-gray-500 focus:border-transparent"></form>` +
+		`<div id="staff-search-results" class="p-4 max-h-96 overflow-y-auto"></div>` +
+		`<p class="px-4 py-2 text-xs text-gray-400 border-t border-gray-200">↑↓ wybór wyniku, Enter otwiera, Esc zamyka</p>` +
+		`</dialog><script src="` + script + `" defer></script>`)
+}
+
+// formatMoney zwraca kwotę w walucie i zapisie z ustawień biblioteki
+func formatMoney(fbClient *firebase.Client, m models.Money) string {
+	return librarySettings(fbClient).FormatMoney(m)
+}
+
+// formatTime zwraca datę w zapisie wybranym przez czytelnika (locale z szablonu) lub z ustawień biblioteki
+func formatTime(fbClient *firebase.Client, value interface{}, locale []string, format func(*models.Settings, time.Time, string) string) string {
+	var t time.Time
+	switch v := value.(type) {
+	case time.Time:
+		t = v
+	case *time.Time:
+		if v != nil {
+			t = *v
+		}
+	}
+	if t.IsZero() {
+		return ""
+	}
+	preferred := ""
+	if len(locale) > 0 {
+		preferred = locale[0]
+	}
+	return format(librarySettings(fbClient), t, preferred)
+}
+
+// libraryName zwraca nazwę biblioteki z ustawień (lub domyślną, gdy baza jest niedostępna)
+func libraryName(fbClient *firebase.Client) string {
+	return librarySettings(fbClient).LibraryName
+}
+
+// librarySettings zwraca ustawienia biblioteki (lub domyślne, gdy baza jest niedostępna)
+func librarySettings(fbClient *firebase.Client) *models.Settings {
+	if fbClient != nil {
+		if settings, err := fbClient.GetSettings(); err == nil {
+			return settings
+		}
+	}
+	return models.DefaultSettings()
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+)
+
+const (
+	holdsDefaultQueue = 3  // Domyślny próg długości kolejki
+	holdsDefaultWait  = 14 // Domyślny próg średniego oczekiwania w dniach
+
+	// holdsPerCopyTarget to docelowa liczba oczekujących na jeden egzemplarz,
+	// na jej podstawie wyliczana jest proponowana liczba nowych egzemplarzy
+	holdsPerCopyTarget = 2
+
+	// holdsWaitMonths to okres, z którego raport liczy czas od rezerwacji do wypożyczenia
+	holdsWaitMonths = 12
+)
+
+// HoldsReportHandler obsługuje raport najczęściej rezerwowanych tytułów,
+// podpowiadający dokupienie egzemplarzy
+type HoldsReportHandler struct {
+	holdsTemplate *template.Template
+	fbClient      *firebase.Client
+}
+
+// HoldsReportRow to tytuł z długą kolejką rezerwacji
+type HoldsReportRow struct {
+	Book        *models.Book
+	Queue       int // Oczekujące rezerwacje
+	AverageWait int // Średni dotychczasowy czas oczekiwania w dniach
+	ExtraCopies int // Proponowana liczba nowych egzemplarzy
+	Suggested   bool
+	BorrowWait  *HoldWait // Oczekiwanie czytelników, którzy już wypożyczyli (nil bez takich rezerwacji)
+}
+
+// HoldWait to czas oczekiwania od rezerwacji do wypożyczenia tytułu
+type HoldWait struct {
+	Borrowed    int // Rezerwacje zamienione w wypożyczenia
+	AverageDays int
+}
+
+// holdWaits liczy średni czas od rezerwacji do wypożyczenia każdej książki
+// ze zrealizowanych rezerwacji
+func holdWaits(reservations []*models.Reservation) map[string]*HoldWait {
+	totals := make(map[string]time.Duration)
+	waits := make(map[string]*HoldWait)
+	for _, reservation := range reservations {
+		wait, ok := reservation.BorrowWait()
+		if !ok {
+			continue
+		}
+		w, ok := waits[reservation.BookID]
+		if !ok {
+			w = &HoldWait{}
+			waits[reservation.BookID] = w
+		}
+		w.Borrowed++
+		totals[reservation.BookID] += wait
+	}
+	for bookID, w := range waits {
+		w.AverageDays = int(totals[bookID].Hours() / 24 / float64(w.Borrowed))
+	}
+	return waits
+}
+
+// NewHoldsReportHandler tworzy handler raportu rezerwacji
+func NewHoldsReportHandler(fbClient *firebase.Client) *HoldsReportHandler {
+	holdsTmpl, err := template.New("holds_report.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/holds_report.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/holds_report.html: %v", err)
+	}
+
+	return &HoldsReportHandler{
+		holdsTemplate: holdsTmpl,
+		fbClient:      fbClient,
+	}
+}
+
+// ShowReport wyświetla tytuły, których kolejka rezerwacji lub średnie oczekiwanie
+// przekracza progi (GET /staff/reports/holds?queue=3&wait=14)
+func (h *HoldsReportHandler) ShowReport(w http.ResponseWriter, r *http.Request) {
+	if h.holdsTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return
+	}
+
+	queue := queryInt(r, "queue", holdsDefaultQueue)
+	wait := queryInt(r, "wait", holdsDefaultWait)
+
+	rows, err := h.rows(queue, wait, time.Now())
+	if err != nil {
+		log.Printf("Błąd przygotowania raportu rezerwacji: %v", err)
+		http.Error(w, "Błąd przygotowania raportu", http.StatusInternalServerError)
+		return
+	}
+
+	data := NewPageData(r)
+	data["Queue"] = queue
+	data["Wait"] = wait
+	data["Rows"] = rows
+	data["PerCopy"] = holdsPerCopyTarget
+	data["WaitMonths"] = holdsWaitMonths
+
+	if err := h.holdsTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania raportu rezerwacji: %v", err)
+	}
+}
+
+// rows grupuje oczekujące rezerwacje według książek i zostawia tytuły ponad progami
+// (najdłuższe kolejki pierwsze)
+func (h *HoldsReportHandler) rows(minQueue, minWait int, now time.Time) ([]*HoldsReportRow, error) {
+	reservations, err := h.fbClient.GetPendingReservations()
+	if err != nil {
+		return nil, err
+	}
+
+	waits := make(map[string][]int)
+	for _, reservation := range reservations {
+		days := int(now.Sub(reservation.ReservationDate).Hours() / 24)
+		waits[reservation.BookID] = append(waits[reservation.BookID], days)
+	}
+
+	var ids []string
+	byBook := make(map[string]*HoldsReportRow)
+	for bookID, days := range waits {
+		total := 0
+		for _, d := range days {
+			total += d
+		}
+		row := &HoldsReportRow{Queue: len(days), AverageWait: total / len(days)}
+		if row.Queue >= minQueue || row.AverageWait >= minWait {
+			byBook[bookID] = row
+			ids = append(ids, bookID)
+		}
+	}
+
+	books, err := h.fbClient.GetBooksByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	suggested, err := h.fbClient.GetOpenSuggestionBookIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	completed, err := h.fbClient.GetCompletedReservations(now.AddDate(0, -holdsWaitMonths, 0))
+	if err != nil {
+		return nil, err
+	}
+	borrowWaits := holdWaits(completed)
+
+	rows := make([]*HoldsReportRow, 0, len(byBook))
+	for bookID, row := range byBook {
+		book, ok := books[bookID]
+		if !ok {
+			continue // Książka usunięta z katalogu
+		}
+		row.Book = book
+		row.Suggested = suggested[bookID]
+		row.BorrowWait = borrowWaits[bookID]
+		row.ExtraCopies = max(1, (row.Queue+holdsPerCopyTarget-1)/holdsPerCopyTarget-book.TotalCopies)
+		rows = append(rows, row)
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Queue != rows[j].Queue {
+			return rows[i].Queue > rows[j].Queue
+		}
+		return rows[i].AverageWait > rows[j].AverageWait
+	})
+	return rows, nil
+}
+
+// queryInt odczytuje dodatnią liczbę z parametru adresu (wartość domyślna przy braku lub błędzie)
+func queryInt(r *http.Request, name string, fallback int) int {
+	if value, err := strconv.Atoi(r.URL.Query().Get(name)); err == nil && value > 0 {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,531 @@
+package handlers
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/middleware"
+	"library-management-system/internal/models"
+	"library-management-system/internal/notifications"
+)
+
+// ILLHandler obsługuje zamówienia międzybiblioteczne: czytelnik zgłasza tytuł, którego
+// biblioteka nie ma, a personel prowadzi zamówienie i korespondencję z biblioteką partnerską
+type ILLHandler struct {
+	userTemplate     *template.Template
+	listTemplate     *template.Template
+	requestTemplate  *template.Template
+	letterTemplate   *template.Template
+	partnersTemplate *template.Template
+	fbClient         *firebase.Client
+	mailer           *notifications.Mailer
+}
+
+// NewILLHandler tworzy handler zamówień międzybibliotecznych
+// (mailer wysyła zamówienia do bibliotek partnerskich)
+func NewILLHandler(fbClient *firebase.Client, mailer *notifications.Mailer) *ILLHandler {
+	userTmpl, err := template.New("ill.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/user/ill.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu user/ill.html: %v", err)
+	}
+
+	listTmpl, err := template.New("ill.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/ill.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/ill.html: %v", err)
+	}
+
+	requestTmpl, err := template.New("ill_request.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/ill_request.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/ill_request.html: %v", err)
+	}
+
+	letterTmpl, err := template.New("ill_letter.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/ill_letter.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/ill_letter.html: %v", err)
+	}
+
+	partnersTmpl, err := template.New("ill_partners.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/ill_partners.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/ill_partners.html: %v", err)
+	}
+
+	return &ILLHandler{
+		userTemplate:     userTmpl,
+		listTemplate:     listTmpl,
+		requestTemplate:  requestTmpl,
+		letterTemplate:   letterTmpl,
+		partnersTemplate: partnersTmpl,
+		fbClient:         fbClient,
+		mailer:           mailer,
+	}
+}
+
+// ShowUserRequests wyświetla zamówienia czytelnika i formularz nowego zamówienia (GET /user/ill?title=)
+func (h *ILLHandler) ShowUserRequests(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if h.userTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	data := NewPageData(r)
+	data["Title"] = r.URL.Query().Get("title")
+	data["Sent"] = r.URL.Query().Get("sent") == "1"
+
+	if h.fbClient != nil {
+		requests, err := h.fbClient.Traced(r.Context()).GetUserILLRequests(session.UserID)
+		if err != nil {
+			log.Printf("Błąd pobierania zamówień międzybibliotecznych: %v", err)
+			data["Error"] = "Błąd pobierania zamówień"
+		}
+		data["Requests"] = requests
+	}
+
+	if err := h.userTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania zamówień międzybibliotecznych: %v", err)
+	}
+}
+
+// CreateUserRequest zapisuje zamówienie czytelnika (POST /user/ill)
+func (h *ILLHandler) CreateUserRequest(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	title := strings.TrimSpace(r.FormValue("title"))
+	if title == "" {
+		http.Error(w, "Tytuł jest wymagany", http.StatusBadRequest)
+		return
+	}
+
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return
+	}
+
+	request := &models.ILLRequest{
+		UserID:   session.UserID,
+		UserName: session.User.FullName(),
+		Title:    title,
+		Author:   strings.TrimSpace(r.FormValue("author")),
+		ISBN:     strings.TrimSpace(r.FormValue("isbn")),
+		Note:     strings.TrimSpace(r.FormValue("note")),
+	}
+
+	if err := h.fbClient.Traced(r.Context()).CreateILLRequest(request); err != nil {
+		log.Printf("Błąd zapisywania zamówienia międzybibliotecznego: %v", err)
+		http.Error(w, "Błąd zapisywania zamówienia", http.StatusInternalServerError)
+		return
+	}
+
+	basepath.Redirect(w, r, "/user/ill?sent=1", http.StatusSeeOther)
+}
+
+// ListRequests wyświetla zamówienia w panelu personelu (GET /staff/ill?all=1).
+// Domyślnie pokazywane są tylko zamówienia w toku.
+func (h *ILLHandler) ListRequests(w http.ResponseWriter, r *http.Request) {
+	if h.listTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	showAll := r.URL.Query().Get("all") == "1"
+
+	data := NewPageData(r)
+	data["ShowAll"] = showAll
+
+	if h.fbClient != nil {
+		requests, err := h.fbClient.Traced(r.Context()).ListILLRequests()
+		if err != nil {
+			log.Printf("Błąd pobierania zamówień międzybibliotecznych: %v", err)
+			data["Error"] = "Błąd pobierania zamówień z bazy danych"
+		}
+
+		var shown []*models.ILLRequest
+		for _, request := range requests {
+			if showAll || request.IsOpen() {
+				shown = append(shown, request)
+			}
+		}
+		data["Requests"] = shown
+	}
+
+	if err := h.listTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania zamówień międzybibliotecznych: %v", err)
+	}
+}
+
+// illNotices to komunikaty po przekierowaniu na stronę zamówienia (parametr ?done=)
+var illNotices = map[string]string{
+	"saved":   "Zamówienie zostało zapisane",
+	"emailed": "Zamówienie zostało wysłane emailem do biblioteki partnerskiej",
+	"logged":  "Odpowiedź partnera została zapisana w korespondencji",
+}
+
+// ShowRequest wyświetla zamówienie do edycji wraz z korespondencją (GET /staff/ill/{id})
+func (h *ILLHandler) ShowRequest(w http.ResponseWriter, r *http.Request) {
+	if h.requestTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	request, err := h.fbClient.Traced(r.Context()).GetILLRequest(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("Błąd pobierania zamówienia międzybibliotecznego: %v", err)
+		http.Error(w, "Nie znaleziono zamówienia", http.StatusNotFound)
+		return
+	}
+
+	data := h.requestData(r, request)
+	data["Notice"] = illNotices[r.URL.Query().Get("done")]
+	h.renderRequest(w, data)
+}
+
+// UpdateRequest zapisuje status, bibliotekę partnerską, termin zwrotu i opłatę (POST /staff/ill/{id})
+func (h *ILLHandler) UpdateRequest(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	request, err := h.fbClient.Traced(r.Context()).GetILLRequest(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("Błąd pobierania zamówienia międzybibliotecznego: %v", err)
+		http.Error(w, "Nie znaleziono zamówienia", http.StatusNotFound)
+		return
+	}
+
+	request.Status = models.ILLStatus(r.FormValue("status"))
+	request.FeePaid = r.FormValue("fee_paid") == "on"
+	request.StaffNotes = strings.TrimSpace(r.FormValue("staff_notes"))
+
+	var formErr error
+	if partnerID := r.FormValue("partner_library_id"); partnerID != request.PartnerLibraryID {
+		request.PartnerLibraryID, request.PartnerLibrary = "", ""
+		if partnerID != "" {
+			var partner *models.PartnerLibrary
+			if partner, formErr = h.fbClient.Traced(r.Context()).GetPartnerLibrary(partnerID); formErr == nil {
+				request.PartnerLibraryID, request.PartnerLibrary = partner.ID, partner.Name
+			}
+		}
+	}
+	if formErr == nil {
+		request.DueDate, formErr = formDate(r, "due_date")
+	}
+	request.Fee = 0
+	if fee := strings.TrimSpace(r.FormValue("fee")); formErr == nil && fee != "" {
+		request.Fee, formErr = models.ParseMoney(fee)
+	}
+	if formErr == nil {
+		formErr = h.fbClient.Traced(r.Context()).UpdateILLRequest(request)
+	}
+	if formErr != nil {
+		data := h.requestData(r, request)
+		data["Error"] = "Nie udało się zapisać zamówienia: " + formErr.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		h.renderRequest(w, data)
+		return
+	}
+
+	basepath.Redirect(w, r, "/staff/ill/"+request.ID+"?done=saved", http.StatusSeeOther)
+}
+
+// EmailPartner wysyła standardowe zamówienie do biblioteki partnerskiej i zapisuje je
+// w korespondencji (POST /staff/ill/{id}/email)
+func (h *ILLHandler) EmailPartner(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	request, err := h.fbClient.Traced(r.Context()).GetILLRequest(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("Błąd pobierania zamówienia międzybibliotecznego: %v", err)
+		http.Error(w, "Nie znaleziono zamówienia", http.StatusNotFound)
+		return
+	}
+
+	data := h.requestData(r, request)
+	partner, _ := data["Partner"].(*models.PartnerLibrary)
+
+	var sendErr error
+	switch {
+	case !h.mailer.IsConfigured():
+		sendErr = fmt.Errorf("wysyłka email nie jest skonfigurowana (SMTP_HOST)")
+	case partner == nil:
+		sendErr = fmt.Errorf("wybierz bibliotekę partnerską z katalogu")
+	case partner.Email == "":
+		sendErr = fmt.Errorf("biblioteka %s nie ma adresu email - wydrukuj list", partner.Name)
+	}
+
+	if sendErr == nil {
+		subject, body := h.partnerMessage(request, partner, session.User)
+		if sendErr = h.mailer.Send(partner.Email, subject, body); sendErr == nil {
+			sendErr = h.fbClient.Traced(r.Context()).AddILLMessage(request.ID, models.ILLMessage{
+				Channel:   models.ILLChannelEmail,
+				Recipient: partner.Email,
+				Subject:   subject,
+				Body:      body,
+				Author:    session.User.Email,
+			})
+		}
+	}
+	if sendErr != nil {
+		log.Printf("Błąd wysyłania zamówienia międzybibliotecznego %s: %v", request.ID, sendErr)
+		data["Error"] = "Nie udało się wysłać zamówienia: " + sendErr.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		h.renderRequest(w, data)
+		return
+	}
+
+	basepath.Redirect(w, r, "/staff/ill/"+request.ID+"?done=emailed", http.StatusSeeOther)
+}
+
+// RecordLetter zapisuje list do biblioteki partnerskiej w korespondencji i otwiera
+// go do wydruku (POST /staff/ill/{id}/letter)
+func (h *ILLHandler) RecordLetter(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	request, err := h.fbClient.Traced(r.Context()).GetILLRequest(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("Błąd pobierania zamówienia międzybibliotecznego: %v", err)
+		http.Error(w, "Nie znaleziono zamówienia", http.StatusNotFound)
+		return
+	}
+
+	partner := h.requestPartner(request)
+	subject, body := h.partnerMessage(request, partner, session.User)
+	if err := h.fbClient.Traced(r.Context()).AddILLMessage(request.ID, models.ILLMessage{
+		Channel:   models.ILLChannelLetter,
+		Recipient: request.PartnerLibrary,
+		Subject:   subject,
+		Body:      body,
+		Author:    session.User.Email,
+	}); err != nil {
+		log.Printf("Błąd zapisywania listu do zamówienia %s: %v", request.ID, err)
+		http.Error(w, "Błąd zapisywania korespondencji", http.StatusInternalServerError)
+		return
+	}
+
+	basepath.Redirect(w, r, "/staff/ill/"+request.ID+"/letter", http.StatusSeeOther)
+}
+
+// ShowLetter wyświetla list do biblioteki partnerskiej do wydruku lub zapisu jako PDF
+// (GET /staff/ill/{id}/letter). Pokazywany jest ostatni zapisany list.
+func (h *ILLHandler) ShowLetter(w http.ResponseWriter, r *http.Request) {
+	if h.letterTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	request, err := h.fbClient.Traced(r.Context()).GetILLRequest(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("Błąd pobierania zamówienia międzybibliotecznego: %v", err)
+		http.Error(w, "Nie znaleziono zamówienia", http.StatusNotFound)
+		return
+	}
+
+	var letter *models.ILLMessage
+	for i := range request.Messages {
+		if request.Messages[i].Channel == models.ILLChannelLetter {
+			letter = &request.Messages[i]
+		}
+	}
+	if letter == nil {
+		basepath.Redirect(w, r, "/staff/ill/"+request.ID, http.StatusSeeOther)
+		return
+	}
+
+	data := NewPageData(r)
+	data["Request"] = request
+	data["Partner"] = h.requestPartner(request)
+	data["Letter"] = letter
+	if err := h.letterTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania listu zamówienia: %v", err)
+	}
+}
+
+// RecordReply zapisuje w korespondencji odpowiedź otrzymaną od biblioteki partnerskiej
+// (POST /staff/ill/{id}/messages)
+func (h *ILLHandler) RecordReply(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	body := strings.TrimSpace(r.FormValue("body"))
+	if body == "" {
+		http.Error(w, "Treść odpowiedzi jest wymagana", http.StatusBadRequest)
+		return
+	}
+
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	request, err := h.fbClient.Traced(r.Context()).GetILLRequest(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("Błąd pobierania zamówienia międzybibliotecznego: %v", err)
+		http.Error(w, "Nie znaleziono zamówienia", http.StatusNotFound)
+		return
+	}
+
+	if err := h.fbClient.Traced(r.Context()).AddILLMessage(request.ID, models.ILLMessage{
+		Channel:   models.ILLChannelIncoming,
+		Recipient: request.PartnerLibrary,
+		Subject:   strings.TrimSpace(r.FormValue("subject")),
+		Body:      body,
+		Author:    session.User.Email,
+	}); err != nil {
+		log.Printf("Błąd zapisywania odpowiedzi do zamówienia %s: %v", request.ID, err)
+		http.Error(w, "Błąd zapisywania korespondencji", http.StatusInternalServerError)
+		return
+	}
+
+	basepath.Redirect(w, r, "/staff/ill/"+request.ID+"?done=logged", http.StatusSeeOther)
+}
+
+// requestData przygotowuje dane strony zamówienia: katalog partnerów, wybranego partnera
+// i podgląd standardowej wiadomości
+func (h *ILLHandler) requestData(r *http.Request, request *models.ILLRequest) TemplateData {
+	session := middleware.GetSessionFromContext(r.Context())
+	data := NewPageData(r)
+	data["Request"] = request
+	data["CanEmail"] = h.mailer.IsConfigured()
+
+	partners, err := h.fbClient.Traced(r.Context()).ListPartnerLibraries()
+	if err != nil {
+		log.Printf("Błąd pobierania bibliotek partnerskich: %v", err)
+	}
+	data["Partners"] = partners
+
+	partner := h.requestPartner(request)
+	if partner != nil {
+		data["Partner"] = partner
+	}
+
+	var staff *models.User
+	if session != nil {
+		staff = session.User
+	}
+	data["MessageSubject"], data["MessageBody"] = h.partnerMessage(request, partner, staff)
+	return data
+}
+
+// requestPartner zwraca bibliotekę partnerską zamówienia (nil, gdy nie wybrano jej
+// z katalogu lub została z niego usunięta)
+func (h *ILLHandler) requestPartner(request *models.ILLRequest) *models.PartnerLibrary {
+	if request.PartnerLibraryID == "" {
+		return nil
+	}
+	partner, err := h.fbClient.GetPartnerLibrary(request.PartnerLibraryID)
+	if err != nil {
+		log.Printf("Błąd pobierania biblioteki partnerskiej %s: %v", request.PartnerLibraryID, err)
+		return nil
+	}
+	return partner
+}
+
+// partnerMessage składa standardową treść zamówienia do biblioteki partnerskiej,
+// wspólną dla emaila i drukowanego listu
+func (h *ILLHandler) partnerMessage(request *models.ILLRequest, partner *models.PartnerLibrary, staff *models.User) (string, string) {
+	library := libraryName(h.fbClient)
+	subject := fmt.Sprintf("Zamówienie międzybiblioteczne nr %s - %s", request.ID, request.Title)
+
+	var b strings.Builder
+	b.WriteString("Szanowni Państwo,\n\n")
+	fmt.Fprintf(&b, "%s uprzejmie prosi o wypożyczenie w ramach wypożyczeń międzybibliotecznych:\n\n", library)
+	fmt.Fprintf(&b, "Tytuł: %s\n", request.Title)
+	if request.Author != "" {
+		fmt.Fprintf(&b, "Autor: %s\n", request.Author)
+	}
+	if request.ISBN != "" {
+		fmt.Fprintf(&b, "ISBN: %s\n", request.ISBN)
+	}
+	fmt.Fprintf(&b, "Numer zamówienia: %s\n", request.ID)
+
+	if partner != nil && (partner.LoanDays > 0 || partner.Fee > 0 || partner.Terms != "") {
+		b.WriteString("\nZamówienie realizujemy na Państwa warunkach wypożyczania:\n")
+		if partner.LoanDays > 0 {
+			fmt.Fprintf(&b, "- okres wypożyczenia: %d dni\n", partner.LoanDays)
+		}
+		if partner.Fee > 0 {
+			fmt.Fprintf(&b, "- opłata: %s\n", formatMoney(h.fbClient, partner.Fee))
+		}
+		if partner.Terms != "" {
+			fmt.Fprintf(&b, "- %s\n", partner.Terms)
+		}
+	}
+
+	b.WriteString("\nProsimy o informację o możliwości realizacji zamówienia, z podaniem numeru zamówienia w odpowiedzi.\n\n")
+	b.WriteString("Z poważaniem\n")
+	if staff != nil && strings.TrimSpace(staff.FullName()) != "" {
+		fmt.Fprintf(&b, "%s\n", staff.FullName())
+	}
+	b.WriteString(library + "\n")
+	return subject, b.String()
+}
+
+func (h *ILLHandler) renderRequest(w http.ResponseWriter, data TemplateData) {
+	if h.requestTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+	data["Statuses"] = models.ILLStatuses
+	if err := h.requestTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania zamówienia międzybibliotecznego: %v", err)
+	}
+}
+
+// formDate odczytuje datę z pola typu date jako koniec wskazanego dnia (nil dla pustego pola)
+func formDate(r *http.Request, name string) (*time.Time, error) {
+	value := strings.TrimSpace(r.FormValue(name))
+	if value == "" {
+		return nil, nil
+	}
+
+	day, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("nieprawidłowa data %s", value)
+	}
+	end := day.Add(24*time.Hour - time.Second)
+	return &end, nil
+}
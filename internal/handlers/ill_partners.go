@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/models"
+)
+
+// ListPartners wyświetla katalog bibliotek partnerskich z formularzem dodawania
+// lub edycji (GET /staff/ill/partners?edit=)
+func (h *ILLHandler) ListPartners(w http.ResponseWriter, r *http.Request) {
+	if h.partnersTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	data := NewPageData(r)
+
+	if h.fbClient != nil {
+		partners, err := h.fbClient.Traced(r.Context()).ListPartnerLibraries()
+		if err != nil {
+			log.Printf("Błąd pobierania bibliotek partnerskich: %v", err)
+			data["Error"] = "Błąd pobierania bibliotek partnerskich z bazy danych"
+		}
+		data["Partners"] = partners
+
+		if editID := r.URL.Query().Get("edit"); editID != "" {
+			editing, err := h.fbClient.Traced(r.Context()).GetPartnerLibrary(editID)
+			if err != nil {
+				log.Printf("Błąd pobierania biblioteki partnerskiej do edycji: %v", err)
+				data["Error"] = "Nie znaleziono biblioteki partnerskiej do edycji"
+			} else {
+				data["Editing"] = editing
+			}
+		}
+	}
+
+	h.renderPartners(w, data)
+}
+
+// CreatePartner dodaje bibliotekę partnerską (POST /staff/ill/partners)
+func (h *ILLHandler) CreatePartner(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	partner := &models.PartnerLibrary{}
+	err := readPartnerForm(r, partner)
+	if err == nil {
+		err = h.fbClient.Traced(r.Context()).CreatePartnerLibrary(partner)
+	}
+	if err != nil {
+		h.partnerFormError(w, r, partner, err)
+		return
+	}
+
+	basepath.Redirect(w, r, "/staff/ill/partners", http.StatusSeeOther)
+}
+
+// UpdatePartner zapisuje dane kontaktowe i warunki partnera (POST /staff/ill/partners/{id})
+func (h *ILLHandler) UpdatePartner(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	partner, err := h.fbClient.Traced(r.Context()).GetPartnerLibrary(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("Błąd pobierania biblioteki partnerskiej: %v", err)
+		http.Error(w, "Nie znaleziono biblioteki partnerskiej", http.StatusNotFound)
+		return
+	}
+
+	err = readPartnerForm(r, partner)
+	if err == nil {
+		err = h.fbClient.Traced(r.Context()).UpdatePartnerLibrary(partner)
+	}
+	if err != nil {
+		h.partnerFormError(w, r, partner, err)
+		return
+	}
+
+	basepath.Redirect(w, r, "/staff/ill/partners", http.StatusSeeOther)
+}
+
+// DeletePartner usuwa bibliotekę partnerską z katalogu (POST /staff/ill/partners/{id}/delete)
+func (h *ILLHandler) DeletePartner(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := h.fbClient.Traced(r.Context()).DeletePartnerLibrary(chi.URLParam(r, "id")); err != nil {
+		log.Printf("Błąd usuwania biblioteki partnerskiej: %v", err)
+		http.Error(w, "Błąd usuwania biblioteki partnerskiej", http.StatusInternalServerError)
+		return
+	}
+
+	basepath.Redirect(w, r, "/staff/ill/partners", http.StatusSeeOther)
+}
+
+// partnerFormError wyświetla katalog ponownie z wpisanymi danymi i komunikatem błędu
+func (h *ILLHandler) partnerFormError(w http.ResponseWriter, r *http.Request, partner *models.PartnerLibrary, err error) {
+	data := NewPageData(r)
+	partners, listErr := h.fbClient.Traced(r.Context()).ListPartnerLibraries()
+	if listErr != nil {
+		log.Printf("Błąd pobierania bibliotek partnerskich: %v", listErr)
+	}
+	data["Partners"] = partners
+	data["Editing"] = partner
+	data["Error"] = "Nie udało się zapisać biblioteki partnerskiej: " + err.Error()
+	w.WriteHeader(http.StatusBadRequest)
+	h.renderPartners(w, data)
+}
+
+func (h *ILLHandler) renderPartners(w http.ResponseWriter, data TemplateData) {
+	if h.partnersTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+	if err := h.partnersTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania bibliotek partnerskich: %v", err)
+	}
+}
+
+// readPartnerForm przepisuje pola formularza do biblioteki partnerskiej
+func readPartnerForm(r *http.Request, partner *models.PartnerLibrary) error {
+	partner.Name = strings.TrimSpace(r.FormValue("name"))
+	partner.Email = strings.TrimSpace(r.FormValue("email"))
+	partner.Phone = strings.TrimSpace(r.FormValue("phone"))
+	partner.Address = strings.TrimSpace(r.FormValue("address"))
+	partner.ContactPerson = strings.TrimSpace(r.FormValue("contact_person"))
+	partner.Terms = strings.TrimSpace(r.FormValue("terms"))
+
+	partner.LoanDays = 0
+	if days := strings.TrimSpace(r.FormValue("loan_days")); days != "" {
+		value, err := strconv.Atoi(days)
+		if err != nil {
+			return fmt.Errorf("nieprawidłowy okres wypożyczenia %s", days)
+		}
+		partner.LoanDays = value
+	}
+
+	partner.Fee = 0
+	if fee := strings.TrimSpace(r.FormValue("fee")); fee != "" {
+		value, err := models.ParseMoney(fee)
+		if err != nil {
+			return err
+		}
+		partner.Fee = value
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"time"
+
+	"library-management-system/internal/ilsexport"
+)
+
+// ExportILS eksportuje czytelników, katalog i aktywne wypożyczenia do migracji do innego
+// systemu bibliotecznego (GET /staff/users/ils-export.zip). Archiwum jest składane w pamięci,
+// żeby błąd bazy danych w połowie nie zostawił personelowi uciętego pliku.
+func (h *StaffHandler) ExportILS(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	users, err := h.fbClient.Traced(r.Context()).ListUsers()
+	if err != nil {
+		log.Printf("Błąd pobierania użytkowników do eksportu ILS: %v", err)
+		http.Error(w, "Błąd pobierania użytkowników z bazy danych", http.StatusInternalServerError)
+		return
+	}
+	books, err := h.fbClient.Traced(r.Context()).ListBooks()
+	if err != nil {
+		log.Printf("Błąd pobierania książek do eksportu ILS: %v", err)
+		http.Error(w, "Błąd pobierania książek z bazy danych", http.StatusInternalServerError)
+		return
+	}
+	loans, err := h.fbClient.Traced(r.Context()).GetActiveLoans()
+	if err != nil {
+		log.Printf("Błąd pobierania wypożyczeń do eksportu ILS: %v", err)
+		http.Error(w, "Błąd pobierania wypożyczeń z bazy danych", http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	var buf bytes.Buffer
+	if err := ilsexport.Write(&buf, &ilsexport.Data{Users: users, Books: books, Loans: loans}, now); err != nil {
+		log.Printf("Błąd tworzenia eksportu ILS: %v", err)
+		http.Error(w, "Błąd tworzenia eksportu", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="eksport-ils-`+now.Format("2006-01-02")+`.zip"`)
+	w.Write(buf.Bytes())
+}
@@ -5,23 +5,33 @@ import (
 	"log"
 	"net/http"
 
-	"library-management-system/internal/middleware"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+	"library-management-system/internal/repository"
+)
+
+const (
+	homeNewArrivals   = 6 // Liczba książek w bloku nowości
+	homePopularTitles = 5 // Liczba tytułów w bloku popularnych
 )
 
 // IndexHandler obsługuje stronę główną
 type IndexHandler struct {
 	homeTemplate    *template.Template
 	catalogTemplate *template.Template
+	store           repository.Store
+	fbClient        *firebase.Client
 }
 
-// NewIndexHandler tworzy nowy handler strony głównej
-func NewIndexHandler() *IndexHandler {
-	homeTmpl, err := template.ParseFiles("internal/templates/home.html")
+// NewIndexHandler tworzy nowy handler strony głównej. Nowości pochodzą z repozytorium
+// katalogu, pozostałe bloki (ogłoszenia, listy lektur, statystyki) z Firestore.
+func NewIndexHandler(store repository.Store, fbClient *firebase.Client) *IndexHandler {
+	homeTmpl, err := template.New("home.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/home.html")
 	if err != nil {
 		log.Printf("Błąd ładowania szablonu home.html: %v", err)
 	}
 
-	catalogTmpl, err := template.ParseFiles("internal/templates/catalog.html")
+	catalogTmpl, err := template.New("catalog.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/catalog.html")
 	if err != nil {
 		log.Printf("Błąd ładowania szablonu catalog.html: %v", err)
 	}
@@ -29,6 +39,8 @@ func NewIndexHandler() *IndexHandler {
 	return &IndexHandler{
 		homeTemplate:    homeTmpl,
 		catalogTemplate: catalogTmpl,
+		store:           store,
+		fbClient:        fbClient,
 	}
 }
 
@@ -39,8 +51,20 @@ func (h *IndexHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	session := middleware.GetSessionFromContext(r.Context())
-	data := NewTemplateData(session)
+	data := NewPageData(r)
+
+	// Bloki strony głównej w kolejności z ustawień; dane pobierane są tylko dla włączonych
+	blocks := models.DefaultHomeBlocks
+	if h.fbClient != nil {
+		if settings, err := h.fbClient.Traced(r.Context()).GetSettings(); err == nil {
+			blocks = settings.HomeBlocks
+		}
+	} else {
+		// Bez Firebase dane są w pamięci - bez ogłoszeń i zrzutu statystyk
+		blocks = []models.HomeBlock{models.HomeBlockSearch, models.HomeBlockReadingLists, models.HomeBlockNewArrivals}
+	}
+	h.loadBlocks(r, data, blocks)
+	data["HomeBlocks"] = blocks
 
 	if err := h.homeTemplate.Execute(w, data); err != nil {
 		log.Printf("Błąd renderowania strony głównej: %v", err)
@@ -48,3 +72,49 @@ func (h *IndexHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// loadBlocks pobiera dane bloków strony głównej
+func (h *IndexHandler) loadBlocks(r *http.Request, data TemplateData, blocks []models.HomeBlock) {
+	statsLoaded := false
+	for _, block := range blocks {
+		switch block {
+		case models.HomeBlockAnnouncements:
+			announcements, err := h.fbClient.GetPublishedAnnouncements(3)
+			if err != nil {
+				log.Printf("Błąd pobierania ogłoszeń: %v", err)
+			}
+			data["Announcements"] = announcements
+
+		case models.HomeBlockReadingLists:
+			readingLists, err := homeReadingLists(h.store.ReadingLists(r.Context()), h.store.Books(r.Context()))
+			if err != nil {
+				log.Printf("Błąd pobierania list lektur: %v", err)
+			}
+			data["ReadingLists"] = readingLists
+
+		case models.HomeBlockNewArrivals:
+			books, err := h.store.Books(r.Context()).GetNewestBooks(homeNewArrivals)
+			if err != nil {
+				log.Printf("Błąd pobierania nowości: %v", err)
+			}
+			data["NewArrivals"] = books
+
+		case models.HomeBlockPopular, models.HomeBlockStats:
+			// Oba bloki korzystają z tego samego nocnego zrzutu statystyk
+			if statsLoaded {
+				continue
+			}
+			statsLoaded = true
+			stats, err := h.fbClient.GetLatestPublicStats()
+			if err != nil {
+				log.Printf("Błąd pobierania statystyk publicznych: %v", err)
+			}
+			if stats != nil {
+				if len(stats.PopularBooks) > homePopularTitles {
+					stats.PopularBooks = stats.PopularBooks[:homePopularTitles]
+				}
+				data["PublicStats"] = stats
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"cmp"
+
+	"library-management-system/internal/barcode"
+	"library-management-system/internal/models"
+	"library-management-system/internal/pdf"
+)
+
+// Arkusze etykiet w PDF - układ etykiet odpowiada podglądowi z label_sheet.html
+
+const (
+	labelPaddingXMM  = 3.0
+	labelPaddingYMM  = 2.0
+	labelTextSize    = 7.0  // Tytuł, kod i sygnatura etykiety z kodem kreskowym (pt)
+	spineCodeSize    = 11.0 // Sygnatura na etykiecie grzbietowej, zmniejszana do spineMinCodeSize
+	spineMinCodeSize = 7.0
+	spineTextSize    = 6.5 // Autor i kod na etykiecie grzbietowej
+)
+
+// labelSheetPDF rysuje strony arkusza etykiet; puste pola (nil) zostają niezadrukowane
+func labelSheetPDF(pages [][]*models.Book, layout LabelLayout) *pdf.Document {
+	doc := pdf.New(pdf.A4WidthMM, pdf.A4HeightMM)
+	for _, cells := range pages {
+		page := doc.AddPage()
+		for i, book := range cells {
+			if book == nil {
+				continue
+			}
+			x := layout.MarginLeftMM() + float64(i%layout.Columns)*(layout.WidthMM+layout.ColumnGapMM)
+			y := layout.MarginTopMM() + float64(i/layout.Columns)*(layout.HeightMM+layout.RowGapMM)
+			if layout.Spine {
+				drawSpineLabel(page, book, x, y, layout)
+			} else {
+				drawBarcodeLabel(page, book, x, y, layout)
+			}
+		}
+	}
+	return doc
+}
+
+// drawBarcodeLabel rysuje etykietę z tytułem u góry, kodem kreskowym krótkiego kodu
+// pośrodku oraz krótkim kodem i sygnaturą (albo miejscem na półce) u dołu
+func drawBarcodeLabel(page *pdf.Page, book *models.Book, x, y float64, layout LabelLayout) {
+	left, top := x+labelPaddingXMM, y+labelPaddingYMM
+	width, height := layout.WidthMM-2*labelPaddingXMM, layout.HeightMM-2*labelPaddingYMM
+	line := pdf.PointsToMM(labelTextSize)
+
+	title := pdf.Fit(book.Title, pdf.HelveticaBold, labelTextSize, width)
+	page.Text(left, top+0.8*line, pdf.HelveticaBold, labelTextSize, title)
+
+	bottom := top + height - 0.2*line
+	page.Text(left, bottom, pdf.Helvetica, labelTextSize, book.ShortCode)
+	if shelf := cmp.Or(book.CallNumber, book.ShelfLocation); shelf != "" {
+		free := width - pdf.TextWidth(book.ShortCode, pdf.Helvetica, labelTextSize) - 2
+		shelf = pdf.Fit(shelf, pdf.HelveticaBold, labelTextSize, free)
+		page.Text(left+width-pdf.TextWidth(shelf, pdf.HelveticaBold, labelTextSize), bottom, pdf.HelveticaBold, labelTextSize, shelf)
+	}
+
+	drawBarcode(page, book.ShortCode, left, top+line+0.5, width, height-2*line-1)
+}
+
+// drawSpineLabel rysuje etykietę grzbietową: wyśrodkowaną sygnaturę (pomniejszaną,
+// gdy jest długa), autora i krótki kod
+func drawSpineLabel(page *pdf.Page, book *models.Book, x, y float64, layout LabelLayout) {
+	width := layout.WidthMM - 2*labelPaddingXMM
+	center := x + layout.WidthMM/2
+
+	code := cmp.Or(book.CallNumber, book.ShelfLocation, book.Category)
+	size := spineCodeSize
+	for size > spineMinCodeSize && pdf.TextWidth(code, pdf.HelveticaBold, size) > width {
+		size--
+	}
+
+	codeLine, textLine := 1.1*pdf.PointsToMM(size), 1.2*pdf.PointsToMM(spineTextSize)
+	top := y + (layout.HeightMM-codeLine-2*textLine)/2
+	centerText(page, center, top+0.8*codeLine, pdf.HelveticaBold, size, pdf.Fit(code, pdf.HelveticaBold, size, width))
+	centerText(page, center, top+codeLine+0.8*textLine, pdf.Helvetica, spineTextSize, pdf.Fit(book.Author, pdf.Helvetica, spineTextSize, width))
+	centerText(page, center, top+codeLine+1.8*textLine, pdf.Helvetica, spineTextSize, book.ShortCode)
+}
+
+// centerText pisze tekst wyśrodkowany względem center
+func centerText(page *pdf.Page, center, baseline float64, font pdf.Font, size float64, text string) {
+	page.Text(center-pdf.TextWidth(text, font, size)/2, baseline, font, size, text)
+}
+
+// drawBarcode rysuje kod Code 128 rozciągnięty na podany prostokąt razem z marginesami
+func drawBarcode(page *pdf.Page, text string, x, y, width, height float64) {
+	widths, err := barcode.Code128(text)
+	if err != nil || height <= 0 {
+		return
+	}
+
+	modules := 2 * barcode.QuietZone
+	for _, w := range widths {
+		modules += w
+	}
+	module := width / float64(modules)
+
+	pos := x + barcode.QuietZone*module
+	for i, w := range widths {
+		if i%2 == 0 {
+			page.Rect(pos, y, float64(w)*module, height)
+		}
+		pos += float64(w) * module
+	}
+}
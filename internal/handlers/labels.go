@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"library-management-system/internal/models"
+	"library-management-system/internal/pdf"
+	"library-management-system/internal/repository"
+)
+
+// maxSheetLabels ogranicza liczbę etykiet w jednym wydruku
+const maxSheetLabels = 1000
+
+// Ograniczenia własnego formatu arkusza
+const (
+	customLayoutID   = "custom"
+	maxLabelColumns  = 10
+	maxLabelRows     = 40
+	minLabelWidthMM  = 15
+	minLabelHeightMM = 10
+)
+
+// LabelLayout to format arkusza samoprzylepnych etykiet A4
+type LabelLayout struct {
+	ID          string
+	Name        string
+	Columns     int
+	Rows        int
+	WidthMM     float64
+	HeightMM    float64
+	ColumnGapMM float64 // Odstęp między kolumnami etykiet
+	RowGapMM    float64 // Odstęp między wierszami etykiet
+	Spine       bool    // Etykieta grzbietowa: sygnatura zamiast kodu kreskowego
+}
+
+// labelLayouts to obsługiwane formaty arkuszy (pierwszy jest domyślny)
+var labelLayouts = []LabelLayout{
+	{ID: "a4-3x8", Name: "Kod kreskowy z opisem - A4, 3×8 (70×37 mm)", Columns: 3, Rows: 8, WidthMM: 70, HeightMM: 37},
+	{ID: "a4-4x10", Name: "Kod kreskowy - A4, 4×10 (48,5×25,4 mm)", Columns: 4, Rows: 10, WidthMM: 48.5, HeightMM: 25.4},
+	{ID: "a4-5x13", Name: "Grzbiet - A4, 5×13 (38×21,2 mm)", Columns: 5, Rows: 13, WidthMM: 38, HeightMM: 21.2, Spine: true},
+}
+
+// gridWidthMM zwraca szerokość wszystkich kolumn etykiet razem z odstępami
+func (l LabelLayout) gridWidthMM() float64 {
+	return float64(l.Columns)*l.WidthMM + float64(l.Columns-1)*l.ColumnGapMM
+}
+
+// gridHeightMM zwraca wysokość wszystkich wierszy etykiet razem z odstępami
+func (l LabelLayout) gridHeightMM() float64 {
+	return float64(l.Rows)*l.HeightMM + float64(l.Rows-1)*l.RowGapMM
+}
+
+// MarginLeftMM zwraca lewy margines arkusza (etykiety są wyśrodkowane na stronie A4)
+func (l LabelLayout) MarginLeftMM() float64 {
+	return math.Round((pdf.A4WidthMM-l.gridWidthMM())*5) / 10
+}
+
+// MarginTopMM zwraca górny margines arkusza
+func (l LabelLayout) MarginTopMM() float64 {
+	return math.Round((pdf.A4HeightMM-l.gridHeightMM())*5) / 10
+}
+
+// validate sprawdza, czy własny format da się wydrukować na arkuszu A4
+func (l LabelLayout) validate() error {
+	switch {
+	case l.Columns < 1 || l.Columns > maxLabelColumns:
+		return fmt.Errorf("liczba kolumn musi wynosić od 1 do %d", maxLabelColumns)
+	case l.Rows < 1 || l.Rows > maxLabelRows:
+		return fmt.Errorf("liczba wierszy musi wynosić od 1 do %d", maxLabelRows)
+	case !(l.WidthMM >= minLabelWidthMM) || !(l.HeightMM >= minLabelHeightMM):
+		return fmt.Errorf("etykieta musi mieć co najmniej %d×%d mm", minLabelWidthMM, minLabelHeightMM)
+	case !(l.ColumnGapMM >= 0) || !(l.RowGapMM >= 0):
+		return fmt.Errorf("odstępy między etykietami nie mogą być ujemne")
+	case l.gridWidthMM() > pdf.A4WidthMM || l.gridHeightMM() > pdf.A4HeightMM:
+		return fmt.Errorf("etykiety zajmują %s×%s mm i nie mieszczą się na arkuszu A4", formatMM(l.gridWidthMM()), formatMM(l.gridHeightMM()))
+	}
+	return nil
+}
+
+// findLabelLayout zwraca format arkusza o podanym ID lub domyślny
+func findLabelLayout(id string) LabelLayout {
+	for _, layout := range labelLayouts {
+		if layout.ID == id {
+			return layout
+		}
+	}
+	return labelLayouts[0]
+}
+
+// labelLayoutFromQuery zwraca wybrany format arkusza albo format własny
+// (layout=custom, columns=, rows=, width=, height=, column_gap=, row_gap= w mm, spine=1).
+// Błędny format własny jest zwracany razem z błędem, żeby formularz zachował wpisane wartości.
+func labelLayoutFromQuery(query url.Values) (LabelLayout, error) {
+	if query.Get("layout") != customLayoutID {
+		return findLabelLayout(query.Get("layout")), nil
+	}
+
+	layout := LabelLayout{
+		ID:          customLayoutID,
+		WidthMM:     parseMM(query.Get("width")),
+		HeightMM:    parseMM(query.Get("height")),
+		ColumnGapMM: parseMM(query.Get("column_gap")),
+		RowGapMM:    parseMM(query.Get("row_gap")),
+		Spine:       query.Get("spine") == "1",
+	}
+	layout.Columns, _ = strconv.Atoi(query.Get("columns"))
+	layout.Rows, _ = strconv.Atoi(query.Get("rows"))
+	layout.Name = fmt.Sprintf("Własny - A4, %d×%d (%s×%s mm)", layout.Columns, layout.Rows,
+		formatMM(layout.WidthMM), formatMM(layout.HeightMM))
+	return layout, layout.validate()
+}
+
+// parseMM odczytuje wymiar w milimetrach, także z przecinkiem dziesiętnym (puste pole to 0)
+func parseMM(value string) float64 {
+	mm, err := strconv.ParseFloat(strings.Replace(strings.TrimSpace(value), ",", ".", 1), 64)
+	if err != nil {
+		return 0
+	}
+	return mm
+}
+
+// formatMM zapisuje wymiar z dokładnością do 0,1 mm tak jak w nazwach formatów (48,5)
+func formatMM(mm float64) string {
+	return strings.Replace(strconv.FormatFloat(math.Round(mm*10)/10, 'f', -1, 64), ".", ",", 1)
+}
+
+// labelSheetOptions to parametry arkusza etykiet wspólne dla podglądu i pliku PDF
+type labelSheetOptions struct {
+	IDs     []string
+	Since   string
+	Layout  LabelLayout
+	PerCopy bool
+	Skip    int
+}
+
+// parseLabelSheetOptions odczytuje parametry arkusza z zapytania. Książki wybiera się
+// zaznaczeniem w katalogu albo datą dodania (domyślnie dzisiejsze nowości).
+func parseLabelSheetOptions(query url.Values) (labelSheetOptions, error) {
+	opts := labelSheetOptions{
+		IDs:     query["id"],
+		Since:   query.Get("since"),
+		PerCopy: query.Get("per_copy") == "1",
+	}
+	if len(opts.IDs) == 0 && opts.Since == "" {
+		opts.Since = time.Now().Format("2006-01-02")
+	}
+
+	layout, err := labelLayoutFromQuery(query)
+	opts.Layout = layout
+	opts.Skip, _ = strconv.Atoi(query.Get("skip"))
+	if opts.Skip < 0 || opts.Skip >= layout.Columns*layout.Rows {
+		opts.Skip = 0
+	}
+	return opts, err
+}
+
+// ShowLabelSheet wyświetla podgląd arkuszy etykiet z formularzem wyboru formatu
+// (GET /staff/catalog/labels?id=...&id=...|since=2006-01-02, layout=, per_copy=1, skip=).
+// Plik do wydruku generuje ShowLabelSheetPDF z tymi samymi parametrami.
+func (h *PermalinkHandler) ShowLabelSheet(w http.ResponseWriter, r *http.Request) {
+	if h.sheetTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+	if h.store == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return
+	}
+
+	opts, layoutErr := parseLabelSheetOptions(r.URL.Query())
+
+	data := NewPageData(r)
+	data["Layouts"] = labelLayouts
+	data["Layout"] = opts.Layout
+	data["IDs"] = opts.IDs
+	data["Since"] = opts.Since
+	data["PerCopy"] = opts.PerCopy
+	data["Skip"] = opts.Skip
+
+	if layoutErr != nil {
+		data["Error"] = "Nieprawidłowy format etykiet: " + layoutErr.Error()
+	} else {
+		labels, err := h.sheetLabels(r, opts)
+		if err != nil {
+			log.Printf("Błąd pobierania książek do etykiet: %v", err)
+			data["Error"] = "Błąd pobierania książek do etykiet"
+		}
+		if len(labels) == maxSheetLabels {
+			data["Truncated"] = maxSheetLabels
+		}
+		data["Count"] = len(labels)
+		data["Pages"] = labelPages(labels, opts.Layout, opts.Skip)
+	}
+
+	if err := h.sheetTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania arkusza etykiet: %v", err)
+		http.Error(w, "Błąd renderowania strony", http.StatusInternalServerError)
+	}
+}
+
+// ShowLabelSheetPDF pobiera arkusze etykiet jako plik PDF gotowy do wydruku w skali 100%
+// (GET /staff/catalog/labels.pdf, parametry jak w ShowLabelSheet)
+func (h *PermalinkHandler) ShowLabelSheetPDF(w http.ResponseWriter, r *http.Request) {
+	if h.store == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return
+	}
+
+	opts, err := parseLabelSheetOptions(r.URL.Query())
+	if err != nil {
+		http.Error(w, "Nieprawidłowy format etykiet: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	labels, err := h.sheetLabels(r, opts)
+	if err != nil {
+		log.Printf("Błąd pobierania książek do etykiet: %v", err)
+		http.Error(w, "Błąd pobierania książek do etykiet", http.StatusInternalServerError)
+		return
+	}
+	if len(labels) == 0 {
+		http.Error(w, "Brak książek do wydruku", http.StatusNotFound)
+		return
+	}
+
+	doc := labelSheetPDF(labelPages(labels, opts.Layout, opts.Skip), opts.Layout)
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `attachment; filename="etykiety-`+time.Now().Format("2006-01-02")+`.pdf"`)
+	if _, err := doc.WriteTo(w); err != nil {
+		log.Printf("Błąd zapisu arkusza etykiet PDF: %v", err)
+	}
+}
+
+// sheetLabels zwraca etykiety arkusza: jedną na książkę albo po jednej na egzemplarz,
+// najwyżej maxSheetLabels. Etykiety zawierają krótki kod - książki sprzed permalinków
+// dostają go teraz.
+func (h *PermalinkHandler) sheetLabels(r *http.Request, opts labelSheetOptions) ([]*models.Book, error) {
+	catalog := h.store.Books(r.Context())
+	books, err := labelBooks(catalog, opts.IDs, opts.Since)
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []*models.Book
+	for _, book := range books {
+		if err := catalog.EnsureBookShortCode(book); err != nil {
+			log.Printf("Błąd nadawania kodu książce %s: %v", book.ID, err)
+			continue
+		}
+		copies := 1
+		if opts.PerCopy && book.TotalCopies > 1 {
+			copies = book.TotalCopies
+		}
+		for i := 0; i < copies && len(labels) < maxSheetLabels; i++ {
+			labels = append(labels, book)
+		}
+	}
+	return labels, nil
+}
+
+// labelBooks zwraca zaznaczone książki w kolejności zaznaczenia albo nowości dodane od since
+func labelBooks(catalog repository.BookRepository, ids []string, since string) ([]*models.Book, error) {
+	if len(ids) == 0 {
+		from, err := time.ParseInLocation("2006-01-02", since, time.Local)
+		if err != nil {
+			return nil, err
+		}
+		return catalog.GetBooksAddedSince(from)
+	}
+
+	found, err := catalog.GetBooksByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	var books []*models.Book
+	for _, id := range ids {
+		if book, ok := found[id]; ok {
+			books = append(books, book)
+		}
+	}
+	return books, nil
+}
+
+// labelPages dzieli etykiety na strony arkusza. Pierwsze skip pól zostaje pustych
+// (nil), żeby dokończyć częściowo wykorzystany arkusz.
+func labelPages(labels []*models.Book, layout LabelLayout, skip int) [][]*models.Book {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	perPage := layout.Columns * layout.Rows
+	cells := append(make([]*models.Book, skip), labels...)
+
+	var pages [][]*models.Book
+	for len(cells) > 0 {
+		n := perPage
+		if len(cells) < n {
+			n = len(cells)
+		}
+		pages = append(pages, cells[:n])
+		cells = cells[n:]
+	}
+	return pages
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"library-management-system/internal/models"
+	"library-management-system/internal/repository/memory"
+)
+
+func TestLabelSheetPDF(t *testing.T) {
+	store := memory.NewStore([]models.Book{
+		{ID: "b1", Title: "Pan Tadeusz", Author: "Adam Mickiewicz", CallNumber: "821.162.1", TotalCopies: 3, AvailableCopies: 3},
+		{ID: "b2", Title: "Lalka", Author: "Bolesław Prus", TotalCopies: 1, AvailableCopies: 1},
+	})
+	h := &PermalinkHandler{store: store}
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+	}{
+		{"format z listy", "id=b1&id=b2&layout=a4-4x10&per_copy=1", http.StatusOK},
+		{"format własny", "id=b1&layout=custom&columns=2&rows=7&width=99,1&height=38.1&column_gap=2.5&spine=1", http.StatusOK},
+		{"etykiety szersze od arkusza", "id=b1&layout=custom&columns=3&rows=8&width=70&height=37&column_gap=5", http.StatusBadRequest},
+		{"za mała etykieta", "id=b1&layout=custom&columns=2&rows=2&width=10&height=37", http.StatusBadRequest},
+		{"brak książek", "id=nieznana", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ShowLabelSheetPDF(w, httptest.NewRequest(http.MethodGet, "/staff/catalog/labels.pdf?"+tt.query, nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, oczekiwano %d: %s", w.Code, tt.status, w.Body.String())
+			}
+			if tt.status == http.StatusOK && !bytes.HasPrefix(w.Body.Bytes(), []byte("%PDF-")) {
+				t.Errorf("odpowiedź nie jest plikiem PDF: %q", w.Body.String()[:20])
+			}
+		})
+	}
+}
+
+func TestLabelSheetPDFPages(t *testing.T) {
+	layout := findLabelLayout("a4-3x8")
+	labels := make([]*models.Book, 30)
+	for i := range labels {
+		labels[i] = &models.Book{Title: "Łąka", ShortCode: "abc234"}
+	}
+
+	// 5 pominiętych pól i 30 etykiet to 35 pól, czyli dwie strony po 24
+	if got := labelSheetPDF(labelPages(labels, layout, 5), layout).PageCount(); got != 2 {
+		t.Errorf("PageCount() = %d, oczekiwano 2", got)
+	}
+}
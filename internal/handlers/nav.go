@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"html/template"
+	"strings"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+	"library-management-system/internal/nav"
+)
+
+const (
+	navLinkClass       = "block px-4 py-3 text-gray-700 hover:bg-gray-100 rounded-lg"
+	navActiveLinkClass = "block px-4 py-3 bg-gray-200 text-gray-800 rounded-lg font-medium"
+)
+
+// staffNav zwraca menu paska bocznego panelu personelu z podświetloną bieżącą pozycją.
+// Pozycje tylko dla konsoli sieci bibliotek są pomijane, gdy networkConsole jest false,
+// a pozycje tylko dla administratorów - w menu bibliotekarza.
+func staffNav(page *nav.Page, networkConsole bool) template.HTML {
+	var b strings.Builder
+	b.WriteString(`<nav class="space-y-2">`)
+	for _, section := range nav.StaffMenu {
+		var links strings.Builder
+		for _, item := range section.Items {
+			if item.NetworkOnly && !networkConsole || item.AdminOnly && page != nil && page.Librarian {
+				continue
+			}
+			writeNavLink(&links, page, item)
+		}
+		if links.Len() == 0 {
+			continue
+		}
+		if section.Title != "" {
+			b.WriteString(`<p class="px-4 pt-4 pb-1 text-xs font-semibold text-gray-400 uppercase tracking-wide">` +
+				template.HTMLEscapeString(section.Title) + `</p>`)
+		}
+		b.WriteString(links.String())
+	}
+	b.WriteString(`</nav>`)
+	return template.HTML(b.String())
+}
+
+// readerNav zwraca menu paska bocznego konta czytelnika z podświetloną bieżącą pozycją
+func readerNav(page *nav.Page) template.HTML {
+	var b strings.Builder
+	b.WriteString(`<nav class="space-y-2">`)
+	for _, item := range nav.ReaderMenu {
+		writeNavLink(&b, page, item)
+	}
+	b.WriteString(`</nav>`)
+	return template.HTML(b.String())
+}
+
+func writeNavLink(b *strings.Builder, page *nav.Page, item nav.Item) {
+	class := navLinkClass
+	current := ""
+	if page.IsActive(item.Path) {
+		class = navActiveLinkClass
+		current = ` aria-current="page"`
+	}
+	b.WriteString(`<a href="` + template.HTMLEscapeString(basepath.URL(item.Path)) + `" class="` + class + `"` + current + `>` +
+		template.HTMLEscapeString(item.Label) + `</a>`)
+}
+
+// breadcrumbs zwraca okruszki bieżącej strony nad treścią. Strony najwyższego poziomu
+// (jeden element okruszków) i strony spoza nawigacji nie mają okruszków.
+func breadcrumbs(page *nav.Page) template.HTML {
+	if page == nil || len(page.Breadcrumbs) < 2 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(`<nav aria-label="Okruszki" class="text-sm text-gray-500 mb-6 print:hidden"><ol class="flex flex-wrap items-center">`)
+	for i, crumb := range page.Breadcrumbs {
+		b.WriteString(`<li>`)
+		if i > 0 {
+			b.WriteString(`<span class="mx-2 text-gray-400">/</span>`)
+		}
+		label := template.HTMLEscapeString(crumb.Label)
+		if crumb.Path == "" {
+			b.WriteString(`<span class="text-gray-800" aria-current="page">` + label + `</span>`)
+		} else {
+			b.WriteString(`<a href="` + template.HTMLEscapeString(basepath.URL(crumb.Path)) + `" class="hover:text-gray-900">` + label + `</a>`)
+		}
+		b.WriteString(`</li>`)
+	}
+	b.WriteString(`</ol></nav>`)
+	return template.HTML(b.String())
+}
+
+// menuLinks zwraca odnośniki dodane w ustawieniach do paska nawigacji stron czytelnika
+func menuLinks(fbClient *firebase.Client) template.HTML {
+	var b strings.Builder
+	for _, link := range configuredLinks(fbClient, false) {
+		writeMenuLink(&b, link, "hover:text-gray-300 transition")
+	}
+	return template.HTML(b.String())
+}
+
+// footerLinks zwraca stopkę z odnośnikami dodanymi w ustawieniach (pustą, gdy ich nie ma)
+func footerLinks(fbClient *firebase.Client) template.HTML {
+	links := configuredLinks(fbClient, true)
+	if len(links) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(`<footer class="mt-auto bg-gray-800 text-gray-300 text-sm print:hidden">` +
+		`<div class="container mx-auto px-4 py-4 flex flex-wrap gap-x-6 gap-y-2">`)
+	for _, link := range links {
+		writeMenuLink(&b, link, "hover:text-white transition")
+	}
+	b.WriteString(`</div></footer>`)
+	return template.HTML(b.String())
+}
+
+// configuredLinks zwraca odnośniki paska nawigacji lub stopki z ustawień biblioteki
+func configuredLinks(fbClient *firebase.Client, footer bool) []models.MenuLink {
+	if fbClient == nil {
+		return nil
+	}
+	settings, err := fbClient.GetSettings()
+	if err != nil {
+		return nil
+	}
+	if footer {
+		return settings.FooterLinks
+	}
+	return settings.NavbarLinks
+}
+
+// writeMenuLink dopisuje odnośnik; strony zewnętrzne otwierają się w nowej karcie
+func writeMenuLink(b *strings.Builder, link models.MenuLink, class string) {
+	href := link.URL
+	target := ""
+	if link.IsInternal() {
+		href = basepath.URL(link.URL)
+	} else if strings.HasPrefix(link.URL, "http") {
+		target = ` target="_blank" rel="noopener"`
+	}
+	b.WriteString(`<a href="` + template.HTMLEscapeString(href) + `" class="` + class + `"` + target + `>` +
+		template.HTMLEscapeString(link.Label) + `</a>`)
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/middleware"
+	"library-management-system/internal/models"
+	"library-management-system/internal/notifications"
+)
+
+// newsletterIssuesLimit to liczba ostatnich wydań na stronie newslettera w panelu personelu
+const newsletterIssuesLimit = 12
+
+// NewsletterHandler obsługuje podgląd comiesięcznego newslettera w panelu personelu
+// i rezygnację z newslettera przez link z wiadomości
+type NewsletterHandler struct {
+	previewTemplate     *template.Template
+	unsubscribeTemplate *template.Template
+	fbClient            *firebase.Client
+	dispatcher          *notifications.Dispatcher
+}
+
+// NewNewsletterHandler tworzy handler newslettera. dispatcher składa treść wiadomości
+// do podglądu (nil bez bazy danych).
+func NewNewsletterHandler(fbClient *firebase.Client, dispatcher *notifications.Dispatcher) *NewsletterHandler {
+	previewTmpl, err := template.New("newsletter.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/newsletter.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/newsletter.html: %v", err)
+	}
+
+	unsubscribeTmpl, err := template.New("unsubscribe.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/newsletter/unsubscribe.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu newsletter/unsubscribe.html: %v", err)
+	}
+
+	return &NewsletterHandler{
+		previewTemplate:     previewTmpl,
+		unsubscribeTemplate: unsubscribeTmpl,
+		fbClient:            fbClient,
+		dispatcher:          dispatcher,
+	}
+}
+
+// ShowNewsletter wyświetla podgląd wydania za wybrany miesiąc (domyślnie bieżący, który
+// zostanie wysłany pierwszego dnia następnego), liczbę odbiorców i wysłane wydania
+// (GET /staff/newsletter?month=RRRR-MM)
+func (h *NewsletterHandler) ShowNewsletter(w http.ResponseWriter, r *http.Request) {
+	if h.previewTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+	if h.dispatcher == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	session := middleware.GetSessionFromContext(r.Context())
+	data := NewPageData(r)
+
+	month := time.Now()
+	if parsed, err := time.ParseInLocation("2006-01", r.URL.Query().Get("month"), time.Local); err == nil {
+		month = parsed
+	}
+	data["Month"] = month
+	data["MonthName"] = models.MonthName(month)
+	data["PrevMonth"] = models.NewsletterIssueID(month.AddDate(0, -1, 0))
+	data["NextMonth"] = models.NewsletterIssueID(month.AddDate(0, 1, 0))
+
+	newsletter, err := notifications.ComposeNewsletter(h.fbClient, month)
+	if err != nil {
+		log.Printf("Błąd składania newslettera: %v", err)
+		data["Error"] = "Błąd pobierania nowości i ogłoszeń z bazy danych"
+	} else {
+		data["Newsletter"] = newsletter
+		subject, body := h.dispatcher.NewsletterEmail(session.User, newsletter, models.GetEmailTemplateKind(models.EmailNewsletter).Examples()["wypisz"])
+		data["PreviewSubject"] = subject
+		data["PreviewBody"] = body
+	}
+
+	if subscribers, err := h.fbClient.Traced(r.Context()).GetNewsletterSubscribers(); err == nil {
+		data["Subscribers"] = len(subscribers)
+	} else {
+		log.Printf("Błąd pobierania odbiorców newslettera: %v", err)
+	}
+
+	issues, err := h.fbClient.Traced(r.Context()).ListNewsletterIssues(newsletterIssuesLimit)
+	if err != nil {
+		log.Printf("Błąd pobierania wydań newslettera: %v", err)
+	}
+	data["Issues"] = issues
+
+	if err := h.previewTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania newslettera: %v", err)
+	}
+}
+
+// ShowUnsubscribe wyświetla potwierdzenie rezygnacji z newslettera (GET /newsletter/unsubscribe/{token}).
+// Rezygnacja wymaga kliknięcia przycisku, żeby skanery linków w skrzynkach pocztowych
+// nie wypisywały czytelników.
+func (h *NewsletterHandler) ShowUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	h.renderUnsubscribe(w, r, false)
+}
+
+// Unsubscribe wypisuje czytelnika z newslettera (POST /newsletter/unsubscribe/{token}).
+// Rezygnacja wycofuje zgodę na informacje marketingowe - ponownie można ją wyrazić w ustawieniach konta.
+func (h *NewsletterHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	h.renderUnsubscribe(w, r, true)
+}
+
+func (h *NewsletterHandler) renderUnsubscribe(w http.ResponseWriter, r *http.Request, confirm bool) {
+	if h.unsubscribeTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	token := chi.URLParam(r, "token")
+	data := NewPageData(r)
+	data["Token"] = token
+
+	user, err := h.fbClient.Traced(r.Context()).GetUserByNewsletterToken(token)
+	if err != nil {
+		log.Printf("Błąd wyszukiwania czytelnika po tokenie newslettera: %v", err)
+		http.Error(w, "Błąd pobierania danych z bazy", http.StatusInternalServerError)
+		return
+	}
+	if user == nil {
+		w.WriteHeader(http.StatusNotFound)
+	} else {
+		data["Subscribed"] = user.WantsNotification(models.NotifyNewsletter, models.DeliveryEmail)
+	}
+
+	if user != nil && confirm {
+		user.RecordConsent(models.ConsentMarketingEmail, false, models.ConsentSourceUnsubscribe, time.Now())
+		if err := h.fbClient.Traced(r.Context()).SaveNotificationSettings(user); err != nil {
+			log.Printf("Błąd rezygnacji z newslettera %s: %v", user.ID, err)
+			http.Error(w, "Nie udało się zrezygnować z newslettera", http.StatusInternalServerError)
+			return
+		}
+		data["Subscribed"] = false
+		data["Done"] = true
+	}
+	data["Found"] = user != nil
+
+	if err := h.unsubscribeTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania rezygnacji z newslettera: %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+	"library-management-system/internal/notifications"
+)
+
+// notificationLogLimit to liczba wpisów dziennika wysyłek na stronie
+const notificationLogLimit = 200
+
+// NotificationLogHandler obsługuje dziennik wysyłek powiadomień w panelu personelu
+type NotificationLogHandler struct {
+	logTemplate *template.Template
+	fbClient    *firebase.Client
+	dispatcher  *notifications.Dispatcher
+}
+
+// NewNotificationLogHandler tworzy handler dziennika wysyłek. dispatcher ponawia
+// nieudane wysyłki (nil bez bazy danych).
+func NewNotificationLogHandler(fbClient *firebase.Client, dispatcher *notifications.Dispatcher) *NotificationLogHandler {
+	logTmpl, err := template.New("notification_log.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/notification_log.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/notification_log.html: %v", err)
+	}
+
+	return &NotificationLogHandler{
+		logTemplate: logTmpl,
+		fbClient:    fbClient,
+		dispatcher:  dispatcher,
+	}
+}
+
+// ShowLog wyświetla ostatnie wysyłki z filtrami kanału, wyniku i czytelnika
+// (GET /staff/notifications?channel=&status=&user=)
+func (h *NotificationLogHandler) ShowLog(w http.ResponseWriter, r *http.Request) {
+	data := NewPageData(r)
+	if r.URL.Query().Get("done") == "resent" {
+		data["Notice"] = "Wysyłka została ponowiona"
+	}
+	h.render(w, data, readDeliveryFilter(r.URL.Query()))
+}
+
+// Resend ponawia nieudaną wysyłkę (POST /staff/notifications/{id}/resend)
+func (h *NotificationLogHandler) Resend(w http.ResponseWriter, r *http.Request) {
+	if h.dispatcher == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	// Po ponowieniu wracamy do dziennika z tymi samymi filtrami
+	back, _ := url.ParseQuery(r.FormValue("filters"))
+	filter := readDeliveryFilter(back)
+
+	if err := h.dispatcher.Resend(chi.URLParam(r, "id")); err != nil {
+		log.Printf("Błąd ponawiania wysyłki %s: %v", chi.URLParam(r, "id"), err)
+		data := NewPageData(r)
+		data["Error"] = "Nie udało się ponowić wysyłki: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		h.render(w, data, filter)
+		return
+	}
+
+	query := deliveryFilterQuery(filter)
+	query.Set("done", "resent")
+	basepath.Redirect(w, r, "/staff/notifications?"+query.Encode(), http.StatusSeeOther)
+}
+
+func (h *NotificationLogHandler) render(w http.ResponseWriter, data TemplateData, filter models.DeliveryFilter) {
+	if h.logTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	data["Filter"] = filter
+	data["Filters"] = deliveryFilterQuery(filter).Encode()
+	data["Channels"] = models.DeliveryChannels
+
+	if h.fbClient != nil {
+		deliveries, err := h.fbClient.ListNotificationDeliveries(filter, notificationLogLimit)
+		if err != nil {
+			log.Printf("Błąd pobierania dziennika wysyłek: %v", err)
+			data["Error"] = "Błąd pobierania dziennika wysyłek z bazy danych"
+		}
+		data["Deliveries"] = deliveries
+
+		if filter.UserID != "" {
+			if user, err := h.fbClient.GetUser(filter.UserID); err == nil {
+				data["FilterUser"] = user
+			}
+		}
+	}
+
+	if err := h.logTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania dziennika wysyłek: %v", err)
+	}
+}
+
+// readDeliveryFilter odczytuje filtr dziennika z parametrów adresu
+func readDeliveryFilter(query url.Values) models.DeliveryFilter {
+	return models.DeliveryFilter{
+		Channel: models.DeliveryChannel(query.Get("channel")),
+		Status:  models.DeliveryStatus(query.Get("status")),
+		UserID:  query.Get("user"),
+	}
+}
+
+// deliveryFilterQuery zamienia filtr dziennika na parametry adresu (bez pustych pól)
+func deliveryFilterQuery(filter models.DeliveryFilter) url.Values {
+	query := url.Values{}
+	if filter.Channel != "" {
+		query.Set("channel", string(filter.Channel))
+	}
+	if filter.Status != "" {
+		query.Set("status", string(filter.Status))
+	}
+	if filter.UserID != "" {
+		query.Set("user", filter.UserID)
+	}
+	return query
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"time"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/bots/telegram"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/middleware"
+	"library-management-system/internal/models"
+	"library-management-system/internal/webpush"
+)
+
+// NotificationPrefsHandler obsługuje wybór kanałów powiadomień dla każdej kategorii,
+// zgody czytelnika na informacje marketingowe i zapis dat na stronach czytelnika
+type NotificationPrefsHandler struct {
+	prefsTemplate *template.Template
+	fbClient      *firebase.Client
+	channels      []models.DeliveryChannel // Kanały włączone w bibliotece
+}
+
+// NewNotificationPrefsHandler tworzy handler ustawień powiadomień. Push i Telegram są
+// do wyboru tylko, gdy biblioteka je włączyła (pushCfg i bot różne od nil).
+func NewNotificationPrefsHandler(fbClient *firebase.Client, pushCfg *webpush.Config, bot *telegram.Bot) *NotificationPrefsHandler {
+	prefsTmpl, err := template.New("notification_prefs.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/user/notification_prefs.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu user/notification_prefs.html: %v", err)
+	}
+
+	channels := []models.DeliveryChannel{models.DeliveryEmail}
+	if pushCfg != nil {
+		channels = append(channels, models.DeliveryPush)
+	}
+	if bot != nil {
+		channels = append(channels, models.DeliveryTelegram)
+	}
+
+	return &NotificationPrefsHandler{
+		prefsTemplate: prefsTmpl,
+		fbClient:      fbClient,
+		channels:      channels,
+	}
+}
+
+// notificationPrefRow to kategoria powiadomień z zaznaczonymi kanałami
+type notificationPrefRow struct {
+	*models.NotificationCategoryInfo
+	Checked map[models.DeliveryChannel]bool
+}
+
+// notificationConsentRow to zgoda z ostatnią decyzją czytelnika
+type notificationConsentRow struct {
+	*models.ConsentInfo
+	Consent models.Consent
+}
+
+// ShowPrefs wyświetla tabelę kategorii i kanałów oraz zgody (GET /user/notifications/settings)
+func (h *NotificationPrefsHandler) ShowPrefs(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if h.prefsTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	data := NewPageData(r)
+	data["Saved"] = r.URL.Query().Get("saved")
+	data["Channels"] = h.channels
+
+	// Sesja przechowuje kopię profilu z chwili logowania, więc ustawienia pobieramy z bazy
+	user := session.User
+	if h.fbClient != nil {
+		fresh, err := h.fbClient.Traced(r.Context()).GetUser(session.UserID)
+		if err != nil {
+			log.Printf("Błąd pobierania użytkownika: %v", err)
+			data["Error"] = "Błąd pobierania ustawień powiadomień"
+		} else {
+			user = fresh
+		}
+	}
+
+	rows := make([]notificationPrefRow, 0, len(models.NotificationCategories))
+	for i := range models.NotificationCategories {
+		info := &models.NotificationCategories[i]
+		// Kategorię wymagającą zgody włącza się zgodą, nie w tabeli kanałów
+		if models.ConsentFor(info.Category) != nil {
+			continue
+		}
+		checked := make(map[models.DeliveryChannel]bool)
+		for _, channel := range user.NotificationChannels(info.Category) {
+			checked[channel] = true
+		}
+		rows = append(rows, notificationPrefRow{NotificationCategoryInfo: info, Checked: checked})
+	}
+	data["Rows"] = rows
+
+	consents := make([]notificationConsentRow, 0, len(models.ConsentTypes))
+	for i := range models.ConsentTypes {
+		info := &models.ConsentTypes[i]
+		consents = append(consents, notificationConsentRow{ConsentInfo: info, Consent: user.Consent(info.Type)})
+	}
+	data["Consents"] = consents
+	data["Locales"] = models.Locales
+	data["UserLocale"] = user.Locale
+	data["Example"] = time.Now()
+
+	if err := h.prefsTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania ustawień powiadomień: %v", err)
+	}
+}
+
+// UpdatePrefs zapisuje kanały zaznaczone dla każdej kategorii i zmienione zgody
+// (POST /user/notifications/settings). Kanały, których biblioteka nie udostępnia,
+// zostają bez zmian - wrócą, gdy zostaną włączone.
+func (h *NotificationPrefsHandler) UpdatePrefs(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Nie jesteś zalogowany", http.StatusUnauthorized)
+		return
+	}
+
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	user, err := h.fbClient.Traced(r.Context()).GetUser(session.UserID)
+	if err != nil {
+		log.Printf("Błąd pobierania użytkownika: %v", err)
+		http.Error(w, "Błąd pobierania ustawień powiadomień", http.StatusInternalServerError)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Nieprawidłowy formularz", http.StatusBadRequest)
+		return
+	}
+
+	prefs := make(map[models.NotificationCategory][]models.DeliveryChannel, len(models.NotificationCategories))
+	for i := range models.NotificationCategories {
+		info := &models.NotificationCategories[i]
+		if models.ConsentFor(info.Category) != nil {
+			if channels, ok := user.NotificationPrefs[info.Category]; ok {
+				prefs[info.Category] = channels
+			}
+			continue
+		}
+
+		selected := make(map[models.DeliveryChannel]bool)
+		for _, value := range r.Form["pref_"+string(info.Category)] {
+			selected[models.DeliveryChannel(value)] = true
+		}
+
+		// Pusta lista (nie nil) oznacza "tylko w aplikacji", a nie ustawienia domyślne
+		channels := []models.DeliveryChannel{}
+		for _, channel := range models.DeliveryChannels {
+			if !info.Offers(channel) {
+				continue
+			}
+			if h.offers(channel) {
+				if selected[channel] {
+					channels = append(channels, channel)
+				}
+			} else if user.WantsNotification(info.Category, channel) {
+				channels = append(channels, channel)
+			}
+		}
+		prefs[info.Category] = channels
+	}
+
+	user.NotificationPrefs = prefs
+
+	// Data zgody w rejestrze zmienia się tylko wtedy, gdy czytelnik zmienił decyzję
+	now := time.Now()
+	for _, info := range models.ConsentTypes {
+		granted := r.FormValue("consent_"+string(info.Type)) == "1"
+		if granted != user.Consent(info.Type).Granted {
+			user.RecordConsent(info.Type, granted, models.ConsentSourceSettings, now)
+		}
+	}
+
+	// Pusty zapis dat oznacza "jak w bibliotece"
+	user.Locale = ""
+	if locale := r.FormValue("locale"); models.ValidLocale(locale) {
+		user.Locale = locale
+	}
+
+	if err := h.fbClient.Traced(r.Context()).SaveNotificationSettings(user); err != nil {
+		log.Printf("Błąd zapisywania ustawień powiadomień: %v", err)
+		http.Error(w, "Błąd zapisywania ustawień", http.StatusInternalServerError)
+		return
+	}
+	// Szablony biorą zapis dat z sesji, więc zmiana działa od następnej strony
+	session.User.Locale = user.Locale
+
+	basepath.Redirect(w, r, "/user/notifications/settings?saved=1", http.StatusSeeOther)
+}
+
+// offers sprawdza, czy kanał jest do wyboru w tej bibliotece
+func (h *NotificationPrefsHandler) offers(channel models.DeliveryChannel) bool {
+	for _, c := range h.channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"library-management-system/internal/assets"
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/middleware"
+	"library-management-system/internal/models"
+)
+
+// maxOfflineBooks ogranicza liczbę ostatnio oglądanych książek w zrzucie offline
+const maxOfflineBooks = 20
+
+// OfflineHandler obsługuje aplikację instalowaną (PWA): manifest, stronę offline
+// i zrzut danych, z którego ta strona korzysta bez połączenia
+type OfflineHandler struct {
+	offlineTemplate *template.Template
+	fbClient        *firebase.Client
+}
+
+// offlineSnapshot to dane wyświetlane bez połączenia: ostatnio oglądane książki
+// i - dla zalogowanego czytelnika - wypożyczenia z kodami odbioru
+type offlineSnapshot struct {
+	GeneratedAt time.Time     `json:"generated_at"`
+	Books       []offlineBook `json:"books"`
+	Loans       []offlineLoan `json:"loans"`
+}
+
+type offlineBook struct {
+	ID              string `json:"id"`
+	Title           string `json:"title"`
+	Author          string `json:"author"`
+	CallNumber      string `json:"call_number"`
+	ShelfLocation   string `json:"shelf_location"`
+	AvailableCopies int    `json:"available_copies"`
+}
+
+type offlineLoan struct {
+	Title          string    `json:"title"`
+	Author         string    `json:"author"`
+	PickupCode     string    `json:"pickup_code,omitempty"` // Tylko dla książek czekających na odbiór
+	PickupLocation string    `json:"pickup_location,omitempty"`
+	DueDate        time.Time `json:"due_date"`
+	Overdue        bool      `json:"overdue"`
+}
+
+// NewOfflineHandler tworzy handler aplikacji instalowanej
+func NewOfflineHandler(fbClient *firebase.Client) *OfflineHandler {
+	offlineTmpl, err := template.New("offline.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/offline.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu offline.html: %v", err)
+	}
+
+	return &OfflineHandler{
+		offlineTemplate: offlineTmpl,
+		fbClient:        fbClient,
+	}
+}
+
+// Manifest zwraca manifest aplikacji instalowanej (GET /manifest.webmanifest)
+func (h *OfflineHandler) Manifest(w http.ResponseWriter, r *http.Request) {
+	name := libraryName(h.fbClient)
+	manifest := map[string]interface{}{
+		"name":             name,
+		"short_name":       name,
+		"description":      "Katalog biblioteki, wypożyczenia i kody odbioru",
+		"lang":             "pl",
+		"start_url":        basepath.URL("/"),
+		"scope":            basepath.URL("/"),
+		"display":          "standalone",
+		"background_color": "#f9fafb",
+		"theme_color":      "#1f2937",
+		"icons": []map[string]string{
+			{"src": assets.Path("img/icon.svg"), "sizes": "any", "type": "image/svg+xml"},
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/manifest+json")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	json.NewEncoder(w).Encode(manifest)
+}
+
+// ShowOfflinePage wyświetla stronę pokazywaną bez połączenia (GET /offline).
+// Service worker zapisuje ją przy instalacji, więc nie zawiera danych sesji -
+// treść wypełnia skrypt ze zrzutu offline.
+func (h *OfflineHandler) ShowOfflinePage(w http.ResponseWriter, r *http.Request) {
+	if h.offlineTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.offlineTemplate.Execute(w, NewTemplateData(nil)); err != nil {
+		log.Printf("Błąd renderowania strony offline: %v", err)
+		http.Error(w, "Błąd renderowania strony", http.StatusInternalServerError)
+	}
+}
+
+// Snapshot zwraca zrzut danych offline (GET /offline/snapshot.json?ids=id1,id2).
+// Parametr ids to ostatnio oglądane książki zapamiętane w przeglądarce; wypożyczenia
+// są dołączane tylko dla zalogowanego czytelnika. Odpowiedź nie może trafić do
+// współdzielonych cache - zapisuje ją tylko skrypt pwa.js w pamięci przeglądarki.
+func (h *OfflineHandler) Snapshot(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	snapshot := offlineSnapshot{GeneratedAt: time.Now(), Books: []offlineBook{}, Loans: []offlineLoan{}}
+
+	var ids []string
+	for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+		if id = strings.TrimSpace(id); id != "" && len(ids) < maxOfflineBooks {
+			ids = append(ids, id)
+		}
+	}
+
+	var loans []*models.Loan
+	session := middleware.GetSessionFromContext(r.Context())
+	if session != nil {
+		var err error
+		loans, err = h.fbClient.Traced(r.Context()).GetUserActiveLoans(session.UserID)
+		if err != nil {
+			log.Printf("Błąd pobierania wypożyczeń do zrzutu offline: %v", err)
+			http.Error(w, "Błąd pobierania wypożyczeń", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	bookIDs := append([]string(nil), ids...)
+	for _, loan := range loans {
+		bookIDs = append(bookIDs, loan.BookID)
+	}
+	books, err := h.fbClient.Traced(r.Context()).GetBooksByIDs(bookIDs)
+	if err != nil {
+		log.Printf("Błąd pobierania książek do zrzutu offline: %v", err)
+		http.Error(w, "Błąd pobierania książek", http.StatusInternalServerError)
+		return
+	}
+
+	// Kolejność ostatnio oglądanych jak w przeglądarce (najnowsze pierwsze)
+	for _, id := range ids {
+		if book, ok := books[id]; ok {
+			snapshot.Books = append(snapshot.Books, offlineBook{
+				ID:              book.ID,
+				Title:           book.Title,
+				Author:          book.Author,
+				CallNumber:      book.CallNumber,
+				ShelfLocation:   book.ShelfLocation,
+				AvailableCopies: book.AvailableCopies,
+			})
+		}
+	}
+
+	for _, loan := range loans {
+		entry := offlineLoan{Title: loan.BookTitle, DueDate: loan.DueDate, Overdue: loan.IsOverdue()}
+		if book, ok := books[loan.BookID]; ok {
+			entry.Title = book.Title
+			entry.Author = book.Author
+		}
+		if loan.Status == models.LoanStatusPendingPickup {
+			entry.PickupCode = loan.PickupCode
+			entry.PickupLocation = loan.PickupLocation
+		}
+		snapshot.Loans = append(snapshot.Loans, entry)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "private, no-store")
+	json.NewEncoder(w).Encode(snapshot)
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/session"
+)
+
+// ShowPendingUsers wyświetla kolejkę kont czekających na zatwierdzenie (GET /staff/pending-users)
+func (h *StaffHandler) ShowPendingUsers(w http.ResponseWriter, r *http.Request) {
+	if h.pendingUsersTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	data := NewPageData(r)
+	data["Message"] = r.URL.Query().Get("msg")
+
+	if h.fbClient == nil {
+		data["Error"] = "Baza danych niedostępna"
+	} else {
+		users, err := h.fbClient.Traced(r.Context()).GetPendingUsers()
+		if err != nil {
+			log.Printf("Błąd pobierania kont do zatwierdzenia: %v", err)
+			data["Error"] = "Błąd pobierania kont z bazy danych"
+		}
+		data["Users"] = users
+
+		if settings, err := h.fbClient.Traced(r.Context()).GetSettings(); err == nil {
+			data["RequireApproval"] = settings.RequireApproval
+		}
+	}
+
+	if err := h.pendingUsersTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania kont do zatwierdzenia: %v", err)
+	}
+}
+
+// ApproveUser zatwierdza konto po weryfikacji tożsamości (POST /staff/pending-users/{id}/approve)
+func (h *StaffHandler) ApproveUser(w http.ResponseWriter, r *http.Request) {
+	h.decidePendingUser(w, r, true)
+}
+
+// RejectUser odrzuca konto i je dezaktywuje (POST /staff/pending-users/{id}/reject)
+func (h *StaffHandler) RejectUser(w http.ResponseWriter, r *http.Request) {
+	h.decidePendingUser(w, r, false)
+}
+
+func (h *StaffHandler) decidePendingUser(w http.ResponseWriter, r *http.Request, approved bool) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	user, err := h.fbClient.Traced(r.Context()).GetUser(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Nie znaleziono użytkownika", http.StatusNotFound)
+		return
+	}
+	if !user.PendingApproval {
+		redirectToPendingUsers(w, r, "Konto "+user.Email+" nie czeka już na zatwierdzenie")
+		return
+	}
+
+	if err := h.fbClient.Traced(r.Context()).SetUserApproval(user.ID, approved); err != nil {
+		log.Printf("Błąd zapisywania decyzji o koncie %s: %v", user.ID, err)
+		http.Error(w, "Błąd zapisywania zmian", http.StatusInternalServerError)
+		return
+	}
+
+	if !approved {
+		// Odrzucony czytelnik nie powinien dalej korzystać z otwartej sesji
+		session.GetManager().DeleteUserSessions(user.ID)
+		log.Printf("Odrzucono konto %s (%s)", user.Email, user.ID)
+		redirectToPendingUsers(w, r, "Odrzucono i dezaktywowano konto "+user.Email)
+		return
+	}
+
+	log.Printf("Zatwierdzono konto %s (%s)", user.Email, user.ID)
+	redirectToPendingUsers(w, r, "Zatwierdzono konto "+user.Email)
+}
+
+func redirectToPendingUsers(w http.ResponseWriter, r *http.Request, message string) {
+	basepath.Redirect(w, r, "/staff/pending-users?msg="+url.QueryEscape(message), http.StatusSeeOther)
+}
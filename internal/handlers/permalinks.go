@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	qrcode "github.com/skip2/go-qrcode"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/repository"
+)
+
+// qrCodeSize to rozmiar generowanego kodu QR w pikselach - wystarczający do druku etykiety
+const qrCodeSize = 512
+
+// PermalinkHandler obsługuje krótkie, stałe adresy książek i etykiety z kodami QR
+// oraz arkusze etykiet z kodami kreskowymi
+type PermalinkHandler struct {
+	labelTemplate *template.Template
+	sheetTemplate *template.Template
+	store         repository.Store
+	baseURL       string
+}
+
+// NewPermalinkHandler tworzy nowy handler permalinków.
+// baseURL jest potrzebny, bo kod QR musi zawierać pełny adres serwisu.
+func NewPermalinkHandler(store repository.Store, fbClient *firebase.Client, baseURL string) *PermalinkHandler {
+	labelTmpl, err := template.New("label.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/label.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/label.html: %v", err)
+	}
+
+	sheetTmpl, err := template.New("label_sheet.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/label_sheet.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/label_sheet.html: %v", err)
+	}
+
+	return &PermalinkHandler{
+		labelTemplate: labelTmpl,
+		sheetTemplate: sheetTmpl,
+		store:         store,
+		baseURL:       strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// Redirect przekierowuje krótki adres do strony książki (GET /b/{code})
+func (h *PermalinkHandler) Redirect(w http.ResponseWriter, r *http.Request) {
+	if h.store == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return
+	}
+
+	book, err := h.store.Books(r.Context()).GetBookByShortCode(strings.ToLower(chi.URLParam(r, "code")))
+	if err != nil {
+		log.Printf("Błąd pobierania książki po kodzie: %v", err)
+		http.Error(w, "Błąd pobierania książki", http.StatusInternalServerError)
+		return
+	}
+	if book == nil {
+		http.Error(w, "Książka nie została znaleziona", http.StatusNotFound)
+		return
+	}
+
+	basepath.Redirect(w, r, "/books/"+book.ID, http.StatusMovedPermanently)
+}
+
+// QRCode zwraca kod QR z pełnym krótkim adresem książki (GET /b/{code}/qr.png)
+func (h *PermalinkHandler) QRCode(w http.ResponseWriter, r *http.Request) {
+	code := strings.ToLower(chi.URLParam(r, "code"))
+
+	png, err := qrcode.Encode(h.baseURL+"/b/"+code, qrcode.Medium, qrCodeSize)
+	if err != nil {
+		log.Printf("Błąd generowania kodu QR: %v", err)
+		http.Error(w, "Błąd generowania kodu QR", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.Write(png)
+}
+
+// ShowLabel wyświetla etykietę do wydruku z kodem QR książki (GET /staff/catalog/{id}/label)
+func (h *PermalinkHandler) ShowLabel(w http.ResponseWriter, r *http.Request) {
+	if h.labelTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+	if h.store == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return
+	}
+
+	book, err := h.store.Books(r.Context()).GetBook(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("Błąd pobierania książki: %v", err)
+		http.Error(w, "Książka nie została znaleziona", http.StatusNotFound)
+		return
+	}
+
+	// Książki dodane przed wprowadzeniem permalinków dostają kod przy pierwszym wydruku
+	if err := h.store.Books(r.Context()).EnsureBookShortCode(book); err != nil {
+		log.Printf("Błąd nadawania kodu książce %s: %v", book.ID, err)
+		http.Error(w, "Błąd nadawania kodu książce", http.StatusInternalServerError)
+		return
+	}
+
+	data := NewPageData(r)
+	data["Book"] = book
+	data["ShortURL"] = h.baseURL + book.Permalink()
+
+	if err := h.labelTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania etykiety: %v", err)
+		http.Error(w, "Błąd renderowania strony", http.StatusInternalServerError)
+	}
+}
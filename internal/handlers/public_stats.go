@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"time"
+
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+)
+
+// PublicStatsHandler obsługuje publiczną stronę statystyk biblioteki
+type PublicStatsHandler struct {
+	statsTemplate *template.Template
+	fbClient      *firebase.Client
+}
+
+// NewPublicStatsHandler tworzy handler publicznych statystyk
+func NewPublicStatsHandler(fbClient *firebase.Client) *PublicStatsHandler {
+	statsTmpl, err := template.New("stats.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/stats.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu stats.html: %v", err)
+	}
+
+	return &PublicStatsHandler{
+		statsTemplate: statsTmpl,
+		fbClient:      fbClient,
+	}
+}
+
+// ShowStats wyświetla najnowszy dzienny zrzut statystyk (GET /stats)
+func (h *PublicStatsHandler) ShowStats(w http.ResponseWriter, r *http.Request) {
+	if h.statsTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	data := NewPageData(r)
+
+	if h.fbClient != nil {
+		stats, err := h.fbClient.Traced(r.Context()).GetLatestPublicStats()
+		if err != nil {
+			log.Printf("Błąd pobierania statystyk publicznych: %v", err)
+			data["Error"] = "Błąd pobierania statystyk z bazy danych"
+		}
+		if stats != nil {
+			data["Stats"] = stats
+			if day, err := time.Parse("2006-01-02", stats.Day); err == nil {
+				data["Day"] = day.Format("02.01.2006")
+			}
+			if month, err := time.Parse("2006-01", stats.Month); err == nil {
+				data["MonthName"] = models.MonthName(month)
+			}
+		}
+	}
+
+	if err := h.statsTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania statystyk publicznych: %v", err)
+		http.Error(w, "Błąd renderowania strony", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/middleware"
+	"library-management-system/internal/models"
+	"library-management-system/internal/webpush"
+)
+
+// PushHandler obsługuje urządzenia czytelnika, na które trafiają powiadomienia Web Push
+type PushHandler struct {
+	pushTemplate *template.Template
+	fbClient     *firebase.Client
+	publicKey    string // Klucz VAPID dla przeglądarek; pusty = powiadomienia push wyłączone
+}
+
+// NewPushHandler tworzy handler powiadomień push. cfg może być nil (brak kluczy VAPID).
+func NewPushHandler(fbClient *firebase.Client, cfg *webpush.Config) *PushHandler {
+	pushTmpl, err := template.New("push.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/user/push.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu user/push.html: %v", err)
+	}
+
+	h := &PushHandler{
+		pushTemplate: pushTmpl,
+		fbClient:     fbClient,
+	}
+	if cfg != nil {
+		h.publicKey = cfg.PublicKey
+	}
+	return h
+}
+
+// ShowDevices wyświetla urządzenia z włączonymi powiadomieniami push (GET /user/push)
+func (h *PushHandler) ShowDevices(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if h.pushTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	data := NewPageData(r)
+	data["Saved"] = r.URL.Query().Get("saved")
+	data["PushKey"] = h.publicKey
+	data["DueSoonDays"] = models.DueSoonDays
+
+	if h.fbClient != nil && h.publicKey != "" {
+		devices, err := h.fbClient.Traced(r.Context()).GetUserPushSubscriptions(session.UserID)
+		if err != nil {
+			log.Printf("Błąd pobierania urządzeń: %v", err)
+			data["Error"] = "Błąd pobierania urządzeń"
+		}
+		data["Devices"] = devices
+
+		// Sesja przechowuje kopię profilu z chwili logowania, więc ustawienie pobieramy z bazy
+		if user, err := h.fbClient.Traced(r.Context()).GetUser(session.UserID); err == nil {
+			data["PushOnly"] = user.PushOnly
+		} else {
+			log.Printf("Błąd pobierania użytkownika: %v", err)
+		}
+	}
+
+	if err := h.pushTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania strony powiadomień push: %v", err)
+	}
+}
+
+// Subscribe zapisuje subskrypcję przeglądarki przesłaną przez push.js (POST /user/push)
+func (h *PushHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Nie jesteś zalogowany", http.StatusUnauthorized)
+		return
+	}
+
+	if h.fbClient == nil || h.publicKey == "" {
+		http.Error(w, "Powiadomienia push są wyłączone", http.StatusServiceUnavailable)
+		return
+	}
+
+	sub := &models.PushSubscription{
+		UserID:   session.UserID,
+		Endpoint: strings.TrimSpace(r.FormValue("endpoint")),
+		P256dh:   strings.TrimSpace(r.FormValue("p256dh")),
+		Auth:     strings.TrimSpace(r.FormValue("auth")),
+		Device:   deviceName(r.UserAgent()),
+	}
+	if err := webpush.ValidateSubscription(sub); err != nil {
+		http.Error(w, "Nieprawidłowa subskrypcja: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.fbClient.Traced(r.Context()).SavePushSubscription(sub); err != nil {
+		log.Printf("Błąd zapisywania subskrypcji push: %v", err)
+		http.Error(w, "Błąd zapisywania subskrypcji", http.StatusInternalServerError)
+		return
+	}
+
+	basepath.Redirect(w, r, "/user/push?saved=added", http.StatusSeeOther)
+}
+
+// DeleteDevice wyłącza powiadomienia push na urządzeniu (POST /user/push/{id}/delete)
+func (h *PushHandler) DeleteDevice(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Nie jesteś zalogowany", http.StatusUnauthorized)
+		return
+	}
+
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := h.fbClient.Traced(r.Context()).DeletePushSubscription(session.UserID, chi.URLParam(r, "id")); err != nil {
+		log.Printf("Błąd usuwania subskrypcji push: %v", err)
+		http.Error(w, "Nie znaleziono urządzenia", http.StatusNotFound)
+		return
+	}
+
+	basepath.Redirect(w, r, "/user/push?saved=deleted", http.StatusSeeOther)
+}
+
+// UpdateSettings zapisuje, czy powiadomienia push zastępują email (POST /user/push/settings)
+func (h *PushHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Nie jesteś zalogowany", http.StatusUnauthorized)
+		return
+	}
+
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := h.fbClient.Traced(r.Context()).SetPushOnly(session.UserID, r.FormValue("push_only") == "1"); err != nil {
+		log.Printf("Błąd zapisywania ustawień powiadomień: %v", err)
+		http.Error(w, "Błąd zapisywania ustawień", http.StatusInternalServerError)
+		return
+	}
+
+	basepath.Redirect(w, r, "/user/push?saved=settings", http.StatusSeeOther)
+}
+
+// deviceName opisuje urządzenie na liście na podstawie nagłówka User-Agent,
+// np. "Chrome, Android". Kolejność sprawdzania ma znaczenie - Edge i Opera
+// podają się także jako Chrome, a Chrome jako Safari.
+func deviceName(userAgent string) string {
+	browser := "Przeglądarka"
+	for _, b := range []struct{ token, name string }{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+	} {
+		if strings.Contains(userAgent, b.token) {
+			browser = b.name
+			break
+		}
+	}
+
+	for _, platform := range []struct{ token, name string }{
+		{"Android", "Android"},
+		{"iPhone", "iPhone"},
+		{"iPad", "iPad"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"Linux", "Linux"},
+	} {
+		if strings.Contains(userAgent, platform.token) {
+			return browser + ", " + platform.name
+		}
+	}
+	return browser
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/middleware"
+	"library-management-system/internal/models"
+)
+
+// readerImportMaxSize to limit rozmiaru pliku importu - 500 czytelników zajmuje kilkadziesiąt KB
+const readerImportMaxSize = 2 << 20
+
+// ShowReaderImport wyświetla formularz importu czytelników z pliku CSV (GET /staff/users/import)
+func (h *StaffHandler) ShowReaderImport(w http.ResponseWriter, r *http.Request) {
+	h.renderReaderImport(w, NewPageData(r))
+}
+
+// ImportReaders wczytuje czytelników ze starego systemu z pliku CSV (POST /staff/users/import).
+// Każdy poprawny wiersz bez duplikatu dostaje konto Firebase Auth z losowym hasłem, profil
+// z numerem karty i saldem oraz email z linkiem do ustawienia własnego hasła. Przy zaznaczonym
+// sprawdzeniu pliku konta nie są zakładane - personel widzi tylko wynik walidacji.
+func (h *StaffHandler) ImportReaders(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	data := NewPageData(r)
+	if h.fbClient == nil {
+		data["Error"] = "Baza danych niedostępna"
+		w.WriteHeader(http.StatusServiceUnavailable)
+		h.renderReaderImport(w, data)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, readerImportMaxSize)
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		data["Error"] = "Wybierz plik CSV (najwyżej 2 MB)"
+		w.WriteHeader(http.StatusBadRequest)
+		h.renderReaderImport(w, data)
+		return
+	}
+	defer file.Close()
+
+	rows, err := models.ParseReaderImport(file)
+	if err != nil {
+		data["Error"] = "Nie udało się wczytać pliku: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		h.renderReaderImport(w, data)
+		return
+	}
+
+	if err := h.fbClient.Traced(r.Context()).CheckReaderImportDuplicates(rows); err != nil {
+		log.Printf("Błąd sprawdzania duplikatów importu: %v", err)
+		data["Error"] = "Błąd sprawdzania duplikatów w bazie danych"
+		w.WriteHeader(http.StatusInternalServerError)
+		h.renderReaderImport(w, data)
+		return
+	}
+
+	dryRun := r.FormValue("dry_run") == "1"
+	imported, skipped := 0, 0
+	for _, row := range rows {
+		if !row.Valid() {
+			skipped++
+			continue
+		}
+		if dryRun {
+			continue
+		}
+
+		user, err := h.fbClient.Traced(r.Context()).ImportReader(row)
+		if err != nil {
+			log.Printf("Błąd importu czytelnika %s (wiersz %d): %v", row.Email, row.Line, err)
+			row.AddProblem("nie udało się założyć konta: %v", err)
+			skipped++
+			continue
+		}
+		row.UserID = user.ID
+		imported++
+
+		if err := h.fbClient.Traced(r.Context()).SendPasswordResetEmail(row.Email); err != nil {
+			log.Printf("Błąd wysyłania emaila z ustawieniem hasła do %s: %v", row.Email, err)
+			row.Warning = "nie wysłano emaila z ustawieniem hasła - wyślij go ponownie z karty czytelnika"
+		}
+	}
+
+	if !dryRun {
+		log.Printf("Import czytelników przez %s: utworzono %d kont, pominięto %d wierszy", session.User.Email, imported, skipped)
+	}
+
+	data["Rows"] = rows
+	data["DryRun"] = dryRun
+	data["Imported"] = imported
+	data["Skipped"] = skipped
+	data["Ready"] = len(rows) - skipped
+	h.renderReaderImport(w, data)
+}
+
+// SendPasswordReset wysyła czytelnikowi email z linkiem do ustawienia hasła
+// (POST /staff/users/{id}/password-reset), np. gdy email z importu nie dotarł
+func (h *StaffHandler) SendPasswordReset(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if h.userEditTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return
+	}
+
+	user, err := h.fbClient.Traced(r.Context()).GetUser(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("Błąd pobierania użytkownika: %v", err)
+		http.Error(w, "Nie znaleziono użytkownika", http.StatusNotFound)
+		return
+	}
+
+	data := h.userEditData(r, user)
+	if err := h.fbClient.Traced(r.Context()).SendPasswordResetEmail(user.Email); err != nil {
+		log.Printf("Błąd wysyłania emaila z ustawieniem hasła do %s: %v", user.Email, err)
+		data["PasswordResetError"] = "Nie udało się wysłać emaila: " + err.Error()
+		w.WriteHeader(http.StatusBadGateway)
+	} else {
+		log.Printf("Wysłano email z ustawieniem hasła do %s na prośbę %s", user.Email, session.User.Email)
+		data["PasswordResetSent"] = true
+	}
+
+	if err := h.userEditTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania edycji użytkownika: %v", err)
+	}
+}
+
+func (h *StaffHandler) renderReaderImport(w http.ResponseWriter, data TemplateData) {
+	if h.readerImportTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+	data["MaxRows"] = models.ReaderImportMaxRows
+	if err := h.readerImportTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania importu czytelników: %v", err)
+	}
+}
@@ -0,0 +1,434 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/middleware"
+	"library-management-system/internal/models"
+	"library-management-system/internal/repository"
+	"library-management-system/internal/search"
+)
+
+const (
+	// maxHomeReadingLists to liczba list lektur wyróżnionych na stronie głównej
+	maxHomeReadingLists = 3
+
+	// homeReadingListBooks to liczba pozycji listy pokazywanych na stronie głównej
+	homeReadingListBooks = 6
+)
+
+// ReadingListsHandler obsługuje tematyczne listy lektur przygotowane przez personel
+// oraz własne listy czytelników
+type ReadingListsHandler struct {
+	listTemplate     *template.Template
+	staffTemplate    *template.Template
+	editTemplate     *template.Template
+	userTemplate     *template.Template
+	userEditTemplate *template.Template
+	store            repository.Store
+	searchIndex      *search.Index
+	baseURL          string
+}
+
+// NewReadingListsHandler tworzy nowy handler list lektur. Książki do dodania
+// wyszukuje się we współdzielonym indeksie wyszukiwania, a baseURL jest
+// potrzebny do pełnego linku, którym czytelnik udostępnia swoją listę.
+func NewReadingListsHandler(store repository.Store, fbClient *firebase.Client, searchIndex *search.Index, baseURL string) *ReadingListsHandler {
+	listTmpl, err := template.New("list.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/lists/list.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu lists/list.html: %v", err)
+	}
+
+	staffTmpl, err := template.New("reading_lists.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/reading_lists.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/reading_lists.html: %v", err)
+	}
+
+	editTmpl, err := template.New("reading_list_edit.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/reading_list_edit.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/reading_list_edit.html: %v", err)
+	}
+
+	userTmpl, err := template.New("lists.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/user/lists.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu user/lists.html: %v", err)
+	}
+
+	userEditTmpl, err := template.New("list_edit.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/user/list_edit.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu user/list_edit.html: %v", err)
+	}
+
+	return &ReadingListsHandler{
+		listTemplate:     listTmpl,
+		staffTemplate:    staffTmpl,
+		editTemplate:     editTmpl,
+		userTemplate:     userTmpl,
+		userEditTemplate: userEditTmpl,
+		store:            store,
+		searchIndex:      searchIndex,
+		baseURL:          strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// readingListView to lista lektur z książkami w kolejności ustalonej przez personel
+type readingListView struct {
+	*models.ReadingList
+	Books []*models.Book
+}
+
+// ListPublished wyświetla opublikowane listy lektur (GET /lists)
+func (h *ReadingListsHandler) ListPublished(w http.ResponseWriter, r *http.Request) {
+	h.renderList(w, r, "")
+}
+
+// ShowList wyświetla listę lektur (GET /lists/{slug}). Personel widzi też
+// listy nieopublikowane, żeby sprawdzić je przed publikacją.
+func (h *ReadingListsHandler) ShowList(w http.ResponseWriter, r *http.Request) {
+	h.renderList(w, r, chi.URLParam(r, "slug"))
+}
+
+func (h *ReadingListsHandler) renderList(w http.ResponseWriter, r *http.Request, slug string) {
+	if h.listTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+	if h.store == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	sess := middleware.GetSessionFromContext(r.Context())
+	data := NewPageData(r)
+
+	lists, err := h.store.ReadingLists(r.Context()).GetPublishedReadingLists()
+	if err != nil {
+		log.Printf("Błąd pobierania list lektur: %v", err)
+		data["Error"] = "Błąd pobierania list lektur z bazy danych"
+	}
+	data["Lists"] = lists
+
+	if slug != "" {
+		list, err := h.store.ReadingLists(r.Context()).GetReadingListBySlug(slug)
+		if err != nil {
+			log.Printf("Błąd pobierania listy lektur %s: %v", slug, err)
+			http.Error(w, "Błąd pobierania listy lektur", http.StatusInternalServerError)
+			return
+		}
+		if list == nil || (!list.Published && !isStaff(sess)) {
+			http.Error(w, "Lista lektur nie została znaleziona", http.StatusNotFound)
+			return
+		}
+
+		books, err := readingListBooks(h.store.Books(r.Context()), list.BookIDs)
+		if err != nil {
+			log.Printf("Błąd pobierania książek listy %s: %v", list.ID, err)
+			data["Error"] = "Błąd pobierania książek z bazy danych"
+		}
+		data["Current"] = &readingListView{ReadingList: list, Books: books}
+	}
+
+	if err := h.listTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania listy lektur: %v", err)
+		http.Error(w, "Błąd renderowania strony", http.StatusInternalServerError)
+	}
+}
+
+// ListReadingLists wyświetla listy lektur i formularz nowej listy (GET /staff/lists)
+func (h *ReadingListsHandler) ListReadingLists(w http.ResponseWriter, r *http.Request) {
+	h.renderStaffLists(w, r, NewPageData(r))
+}
+
+// CreateReadingList zakłada listę lektur i przechodzi do dodawania pozycji (POST /staff/lists)
+func (h *ReadingListsHandler) CreateReadingList(w http.ResponseWriter, r *http.Request) {
+	if h.store == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	list := readReadingListForm(r, &models.ReadingList{})
+	if err := h.store.ReadingLists(r.Context()).CreateReadingList(list); err != nil {
+		data := NewPageData(r)
+		data["Form"] = list
+		data["Error"] = "Nie udało się zapisać listy lektur: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		h.renderStaffLists(w, r, data)
+		return
+	}
+
+	basepath.Redirect(w, r, "/staff/lists/"+list.ID, http.StatusSeeOther)
+}
+
+// EditReadingList wyświetla pozycje listy do ułożenia oraz wyszukiwarkę książek
+// do dodania (GET /staff/lists/{id}?q=)
+func (h *ReadingListsHandler) EditReadingList(w http.ResponseWriter, r *http.Request) {
+	list, ok := h.loadReadingList(w, r)
+	if !ok {
+		return
+	}
+
+	data := NewPageData(r)
+	if r.URL.Query().Get("done") == "saved" {
+		data["Notice"] = "Zmiany zostały zapisane"
+	}
+	h.renderEdit(w, r, list, data)
+}
+
+// UpdateReadingList zapisuje tytuł, adres, opis i widoczność listy (POST /staff/lists/{id})
+func (h *ReadingListsHandler) UpdateReadingList(w http.ResponseWriter, r *http.Request) {
+	list, ok := h.loadReadingList(w, r)
+	if !ok {
+		return
+	}
+
+	h.saveReadingList(w, r, readReadingListForm(r, list))
+}
+
+// AddBook dopisuje książkę na koniec listy (POST /staff/lists/{id}/books)
+func (h *ReadingListsHandler) AddBook(w http.ResponseWriter, r *http.Request) {
+	list, ok := h.loadReadingList(w, r)
+	if !ok {
+		return
+	}
+
+	bookID := r.FormValue("book_id")
+	if !list.HasBook(bookID) {
+		list.BookIDs = append(list.BookIDs, bookID)
+	}
+	h.saveReadingList(w, r, list)
+}
+
+// RemoveBook usuwa książkę z listy (POST /staff/lists/{id}/books/{bookID}/delete)
+func (h *ReadingListsHandler) RemoveBook(w http.ResponseWriter, r *http.Request) {
+	list, ok := h.loadReadingList(w, r)
+	if !ok {
+		return
+	}
+
+	removeListBook(list, chi.URLParam(r, "bookID"))
+	h.saveReadingList(w, r, list)
+}
+
+// ReorderBooks zapisuje kolejność pozycji ułożoną przeciąganiem (POST /staff/lists/{id}/order).
+// Formularz wysyła pola book_id w nowej kolejności.
+func (h *ReadingListsHandler) ReorderBooks(w http.ResponseWriter, r *http.Request) {
+	list, ok := h.loadReadingList(w, r)
+	if !ok {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Nieprawidłowe dane formularza", http.StatusBadRequest)
+		return
+	}
+
+	orderListBooks(list, r.Form["book_id"])
+	h.saveReadingList(w, r, list)
+}
+
+// DeleteReadingList usuwa listę lektur (POST /staff/lists/{id}/delete)
+func (h *ReadingListsHandler) DeleteReadingList(w http.ResponseWriter, r *http.Request) {
+	if h.store == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := h.store.ReadingLists(r.Context()).DeleteReadingList(chi.URLParam(r, "id")); err != nil {
+		log.Printf("Błąd usuwania listy lektur: %v", err)
+		http.Error(w, "Błąd usuwania listy lektur", http.StatusInternalServerError)
+		return
+	}
+
+	basepath.Redirect(w, r, "/staff/lists", http.StatusSeeOther)
+}
+
+// loadReadingList pobiera listę z parametru {id}; przy błędzie wysyła odpowiedź
+func (h *ReadingListsHandler) loadReadingList(w http.ResponseWriter, r *http.Request) (*models.ReadingList, bool) {
+	if h.store == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return nil, false
+	}
+
+	list, err := h.store.ReadingLists(r.Context()).GetReadingList(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("Błąd pobierania listy lektur: %v", err)
+		http.Error(w, "Nie znaleziono listy lektur", http.StatusNotFound)
+		return nil, false
+	}
+	return list, true
+}
+
+// saveReadingList zapisuje listę i wraca do jej edycji albo pokazuje błąd
+func (h *ReadingListsHandler) saveReadingList(w http.ResponseWriter, r *http.Request, list *models.ReadingList) {
+	if err := h.store.ReadingLists(r.Context()).UpdateReadingList(list); err != nil {
+		data := NewPageData(r)
+		data["Error"] = "Nie udało się zapisać listy lektur: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		h.renderEdit(w, r, list, data)
+		return
+	}
+
+	basepath.Redirect(w, r, "/staff/lists/"+list.ID+"?done=saved", http.StatusSeeOther)
+}
+
+// renderStaffLists uzupełnia zestawienie list i renderuje stronę personelu
+func (h *ReadingListsHandler) renderStaffLists(w http.ResponseWriter, r *http.Request, data TemplateData) {
+	if h.staffTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	if h.store != nil {
+		lists, err := h.store.ReadingLists(r.Context()).ListReadingLists()
+		if err != nil {
+			log.Printf("Błąd pobierania list lektur: %v", err)
+			data["Error"] = "Błąd pobierania list lektur z bazy danych"
+		}
+		data["Lists"] = lists
+	}
+
+	if err := h.staffTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania list lektur: %v", err)
+	}
+}
+
+// renderEdit renderuje edycję listy z jej książkami i wynikami wyszukiwania (?q=)
+func (h *ReadingListsHandler) renderEdit(w http.ResponseWriter, r *http.Request, list *models.ReadingList, data TemplateData) {
+	if h.editTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	books, err := readingListBooks(h.store.Books(r.Context()), list.BookIDs)
+	if err != nil {
+		log.Printf("Błąd pobierania książek listy %s: %v", list.ID, err)
+		data["Error"] = "Błąd pobierania książek z bazy danych"
+	}
+	data["List"] = list
+	data["Books"] = books
+	data["Full"] = len(list.BookIDs) >= models.MaxReadingListBooks
+
+	if query := strings.TrimSpace(r.URL.Query().Get("q")); query != "" {
+		data["Query"] = query
+		if err := h.searchIndex.Refresh(); err != nil {
+			log.Printf("Błąd budowania indeksu wyszukiwania: %v", err)
+			data["Error"] = "Wyszukiwarka jest chwilowo niedostępna"
+		} else {
+			var results []search.Result
+			for _, result := range h.searchIndex.Search(query).Books {
+				if !list.HasBook(result.ID) {
+					results = append(results, result)
+				}
+			}
+			data["Results"] = results
+		}
+	}
+
+	if err := h.editTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania edycji listy lektur: %v", err)
+	}
+}
+
+// homeReadingLists zwraca opublikowane listy wyróżnione na stronie głównej
+// z pierwszymi pozycjami. Książki wszystkich list pobiera się jednym zapytaniem;
+// pozycja usunięta z katalogu nie jest zastępowana kolejną.
+func homeReadingLists(readingLists repository.ReadingListRepository, catalog repository.BookRepository) ([]*readingListView, error) {
+	lists, err := readingLists.GetPublishedReadingLists()
+	if err != nil {
+		return nil, err
+	}
+
+	var views []*readingListView
+	var ids []string
+	for _, list := range lists {
+		if !list.ShowOnHome || len(views) == maxHomeReadingLists {
+			continue
+		}
+		views = append(views, &readingListView{ReadingList: list})
+		if len(list.BookIDs) > homeReadingListBooks {
+			ids = append(ids, list.BookIDs[:homeReadingListBooks]...)
+		} else {
+			ids = append(ids, list.BookIDs...)
+		}
+	}
+	if len(views) == 0 {
+		return nil, nil
+	}
+
+	found, err := catalog.GetBooksByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, view := range views {
+		for _, id := range view.BookIDs {
+			if book, ok := found[id]; ok && len(view.Books) < homeReadingListBooks {
+				view.Books = append(view.Books, book)
+			}
+		}
+	}
+	return views, nil
+}
+
+// readingListBooks pobiera książki listy w jej kolejności, pomijając usunięte z katalogu
+func readingListBooks(catalog repository.BookRepository, ids []string) ([]*models.Book, error) {
+	found, err := catalog.GetBooksByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var books []*models.Book
+	for _, id := range ids {
+		if book, ok := found[id]; ok {
+			books = append(books, book)
+		}
+	}
+	return books, nil
+}
+
+// removeListBook usuwa książkę z pozycji listy
+func removeListBook(list *models.ReadingList, bookID string) {
+	ids := list.BookIDs[:0]
+	for _, id := range list.BookIDs {
+		if id != bookID {
+			ids = append(ids, id)
+		}
+	}
+	list.BookIDs = ids
+}
+
+// orderListBooks układa pozycje listy w kolejności z formularza. Pozycje dodane
+// w międzyczasie w innej karcie trafiają na koniec, a usunięte nie wracają.
+func orderListBooks(list *models.ReadingList, order []string) {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, id := range append(order, list.BookIDs...) {
+		if list.HasBook(id) && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	list.BookIDs = ids
+}
+
+// readReadingListForm przepisuje pola formularza do listy. Adres bez podanej
+// wartości powstaje z tytułu.
+func readReadingListForm(r *http.Request, list *models.ReadingList) *models.ReadingList {
+	list.Title = strings.TrimSpace(r.FormValue("title"))
+	list.Description = strings.TrimSpace(r.FormValue("description"))
+	list.Published = r.FormValue("published") == "on"
+	list.ShowOnHome = r.FormValue("show_on_home") == "on"
+
+	slug := strings.TrimSpace(r.FormValue("slug"))
+	if slug == "" {
+		slug = list.Title
+	}
+	list.Slug = search.Slugify(slug)
+	return list
+}
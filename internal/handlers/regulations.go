@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/middleware"
+)
+
+// RegulationsHandler obsługuje regulamin biblioteki: publiczną stronę regulaminu,
+// akceptację nowej wersji przez czytelników i edytor w panelu personelu
+type RegulationsHandler struct {
+	showTemplate   *template.Template
+	acceptTemplate *template.Template
+	editorTemplate *template.Template
+	fbClient       *firebase.Client
+}
+
+// NewRegulationsHandler tworzy handler regulaminu
+func NewRegulationsHandler(fbClient *firebase.Client) *RegulationsHandler {
+	showTmpl, err := template.New("show.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/regulations/show.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu regulations/show.html: %v", err)
+	}
+
+	acceptTmpl, err := template.New("accept.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/regulations/accept.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu regulations/accept.html: %v", err)
+	}
+
+	editorTmpl, err := template.New("regulations.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/regulations.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/regulations.html: %v", err)
+	}
+
+	return &RegulationsHandler{
+		showTemplate:   showTmpl,
+		acceptTemplate: acceptTmpl,
+		editorTemplate: editorTmpl,
+		fbClient:       fbClient,
+	}
+}
+
+// RequireAccepted przekierowuje czytelnika, który nie zaakceptował obowiązującej
+// wersji regulaminu, na stronę akceptacji. Wersja w sesji jest aktualizowana przy
+// akceptacji, a obowiązujący regulamin klient bazy trzyma w pamięci, więc sprawdzenie
+// nie odpytuje bazy.
+func (h *RegulationsHandler) RequireAccepted(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session := middleware.GetSessionFromContext(r.Context())
+		if session == nil || h.fbClient == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		current, err := h.fbClient.Traced(r.Context()).GetCurrentRegulations()
+		if err != nil {
+			// Błąd bazy nie powinien blokować konta - strony pokażą własne błędy
+			log.Printf("Błąd sprawdzania regulaminu: %v", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+		if session.User.NeedsRegulations(current) {
+			basepath.Redirect(w, r, "/regulations/accept", http.StatusSeeOther)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ShowRegulations wyświetla obowiązujący regulamin albo jego wcześniejszą wersję
+// (GET /regulations?version=N)
+func (h *RegulationsHandler) ShowRegulations(w http.ResponseWriter, r *http.Request) {
+	if h.showTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	data := NewPageData(r)
+
+	current, err := h.fbClient.Traced(r.Context()).GetCurrentRegulations()
+	if err != nil {
+		log.Printf("Błąd pobierania regulaminu: %v", err)
+		http.Error(w, "Błąd pobierania regulaminu", http.StatusInternalServerError)
+		return
+	}
+	data["Current"] = current
+
+	regulations := current
+	if version, err := strconv.Atoi(r.URL.Query().Get("version")); err == nil && current != nil && version != current.Version {
+		regulations, err = h.fbClient.Traced(r.Context()).GetRegulations(version)
+		if err != nil {
+			log.Printf("Błąd pobierania regulaminu w wersji %d: %v", version, err)
+			http.Error(w, "Błąd pobierania regulaminu", http.StatusInternalServerError)
+			return
+		}
+		if regulations == nil {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+	data["Regulations"] = regulations
+
+	if err := h.showTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania regulaminu: %v", err)
+	}
+}
+
+// ShowAccept wyświetla obowiązujący regulamin z prośbą o akceptację (GET /regulations/accept)
+func (h *RegulationsHandler) ShowAccept(w http.ResponseWriter, r *http.Request) {
+	h.renderAccept(w, r, "")
+}
+
+// Accept zapisuje akceptację obowiązującej wersji regulaminu (POST /regulations/accept).
+// Formularz przesyła numer wersji, żeby czytelnik nie zaakceptował wersji, której nie
+// widział, gdy w międzyczasie opublikowano nową.
+func (h *RegulationsHandler) Accept(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	current, err := h.fbClient.Traced(r.Context()).GetCurrentRegulations()
+	if err != nil {
+		log.Printf("Błąd pobierania regulaminu: %v", err)
+		http.Error(w, "Błąd pobierania regulaminu", http.StatusInternalServerError)
+		return
+	}
+	if !session.User.NeedsRegulations(current) {
+		basepath.Redirect(w, r, "/user", http.StatusSeeOther)
+		return
+	}
+
+	if r.FormValue("version") != strconv.Itoa(current.Version) {
+		w.WriteHeader(http.StatusConflict)
+		h.renderAccept(w, r, "W międzyczasie opublikowano nową wersję regulaminu - zapoznaj się z nią przed akceptacją")
+		return
+	}
+	if r.FormValue("accept") != "1" {
+		w.WriteHeader(http.StatusBadRequest)
+		h.renderAccept(w, r, "Potwierdź, że akceptujesz regulamin, zaznaczając pole pod jego treścią")
+		return
+	}
+
+	user, err := h.fbClient.Traced(r.Context()).GetUser(session.UserID)
+	if err != nil {
+		log.Printf("Błąd pobierania użytkownika: %v", err)
+		http.Error(w, "Błąd zapisywania akceptacji regulaminu", http.StatusInternalServerError)
+		return
+	}
+	now := time.Now()
+	if err := h.fbClient.Traced(r.Context()).AcceptRegulations(user, current.Version, now); err != nil {
+		log.Printf("Błąd akceptacji regulaminu przez %s: %v", session.UserID, err)
+		http.Error(w, "Błąd zapisywania akceptacji regulaminu", http.StatusInternalServerError)
+		return
+	}
+
+	// Sesja przechowuje kopię profilu z chwili logowania - bez aktualizacji czytelnik
+	// wracałby na stronę akceptacji do ponownego zalogowania
+	session.User.RegulationsVersion = current.Version
+	session.User.RegulationsAcceptedAt = &now
+
+	basepath.Redirect(w, r, "/user", http.StatusSeeOther)
+}
+
+func (h *RegulationsHandler) renderAccept(w http.ResponseWriter, r *http.Request, errMsg string) {
+	if h.acceptTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	session := middleware.GetSessionFromContext(r.Context())
+	current, err := h.fbClient.Traced(r.Context()).GetCurrentRegulations()
+	if err != nil {
+		log.Printf("Błąd pobierania regulaminu: %v", err)
+		http.Error(w, "Błąd pobierania regulaminu", http.StatusInternalServerError)
+		return
+	}
+	if !session.User.NeedsRegulations(current) {
+		basepath.Redirect(w, r, "/user", http.StatusSeeOther)
+		return
+	}
+
+	data := NewPageData(r)
+	data["Regulations"] = current
+	// Czytelnik, który zaakceptował wcześniejszą wersję, widzi opis zmian
+	data["Update"] = session.User.RegulationsVersion > 0
+	if errMsg != "" {
+		data["Error"] = errMsg
+	}
+
+	if err := h.acceptTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania akceptacji regulaminu: %v", err)
+	}
+}
+
+// ShowEditor wyświetla obowiązujący regulamin w edytorze i historię wersji (GET /staff/regulations)
+func (h *RegulationsHandler) ShowEditor(w http.ResponseWriter, r *http.Request) {
+	data := NewPageData(r)
+	if r.URL.Query().Get("done") == "published" {
+		data["Notice"] = "Opublikowano nową wersję regulaminu - czytelnicy zaakceptują ją przy następnej wizycie"
+	}
+	h.renderEditor(w, data, nil)
+}
+
+// Publish publikuje treść z edytora jako nową wersję regulaminu (POST /staff/regulations)
+func (h *RegulationsHandler) Publish(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	session := middleware.GetSessionFromContext(r.Context())
+	body := strings.TrimSpace(r.FormValue("body"))
+	changes := strings.TrimSpace(r.FormValue("changes"))
+
+	if _, err := h.fbClient.Traced(r.Context()).PublishRegulations(body, changes, session.User); err != nil {
+		log.Printf("Błąd publikowania regulaminu: %v", err)
+		data := NewPageData(r)
+		data["Error"] = "Nie udało się opublikować regulaminu: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		h.renderEditor(w, data, map[string]string{"Body": body, "Changes": changes})
+		return
+	}
+
+	basepath.Redirect(w, r, "/staff/regulations?done=published", http.StatusSeeOther)
+}
+
+// renderEditor wyświetla edytor; form to treść odrzuconego formularza (nil = obowiązująca wersja)
+func (h *RegulationsHandler) renderEditor(w http.ResponseWriter, data TemplateData, form map[string]string) {
+	if h.editorTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	if h.fbClient != nil {
+		versions, err := h.fbClient.ListRegulations()
+		if err != nil {
+			log.Printf("Błąd pobierania wersji regulaminu: %v", err)
+			data["Error"] = "Błąd pobierania wersji regulaminu z bazy danych"
+		}
+		data["Versions"] = versions
+		if form == nil {
+			form = map[string]string{}
+			if len(versions) > 0 {
+				form["Body"] = versions[0].Body
+			}
+		}
+	}
+	data["Form"] = form
+
+	if err := h.editorTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania edytora regulaminu: %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+	"library-management-system/internal/repository"
+)
+
+// ReturnsHandler obsługuje seryjne przyjmowanie zwrotów (np. z wrzutni):
+// każdy zeskanowany kod od razu kończy wypożyczenie i trafia do dziennika sesji
+type ReturnsHandler struct {
+	returnsTemplate *template.Template
+	store           repository.Store
+	fbClient        *firebase.Client // Dziennik zmian i statystyki czynności personelu
+}
+
+// ReturnEntry to wpis dziennika zwrotów wyświetlany po każdym skanie
+type ReturnEntry struct {
+	Time        time.Time
+	Code        string
+	Book        *models.Book
+	Loan        *models.Loan
+	Fine        models.Money
+	Reservation *models.Reservation // Rezerwacja, dla której książkę trzeba odłożyć
+	Candidates  []*models.Loan      // Kilka wypożyczonych egzemplarzy - personel wybiera czytelnika
+	Error       string
+}
+
+// NewReturnsHandler tworzy handler przyjmowania zwrotów
+func NewReturnsHandler(store repository.Store, fbClient *firebase.Client) *ReturnsHandler {
+	returnsTmpl, err := template.New("returns.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/returns.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/returns.html: %v", err)
+	}
+
+	return &ReturnsHandler{
+		returnsTemplate: returnsTmpl,
+		store:           store,
+		fbClient:        fbClient,
+	}
+}
+
+// ShowReturns wyświetla ekran przyjmowania zwrotów (GET /staff/returns)
+func (h *ReturnsHandler) ShowReturns(w http.ResponseWriter, r *http.Request) {
+	if h.returnsTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	data := NewPageData(r)
+	if err := h.returnsTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania ekranu zwrotów: %v", err)
+	}
+}
+
+// ScanReturn przyjmuje zwrot zeskanowanej książki (POST /staff/returns).
+// Przyjmuje kod z etykiety (także cały adres z kodu QR) albo ISBN.
+func (h *ReturnsHandler) ScanReturn(w http.ResponseWriter, r *http.Request) {
+	code := path.Base(strings.TrimSpace(r.FormValue("code")))
+	entry := &ReturnEntry{Time: time.Now(), Code: code}
+
+	if h.store == nil {
+		entry.Error = "Baza danych niedostępna"
+		h.renderEntry(w, entry)
+		return
+	}
+
+	book, err := h.store.Books(r.Context()).FindBookByCode(code)
+	if err != nil {
+		log.Printf("Błąd wyszukiwania książki %s: %v", code, err)
+		entry.Error = "Błąd wyszukiwania książki"
+		h.renderEntry(w, entry)
+		return
+	}
+	if book == nil {
+		entry.Error = "Nie znaleziono książki o tym kodzie"
+		h.renderEntry(w, entry)
+		return
+	}
+	entry.Book = book
+
+	loans, err := h.store.Loans(r.Context()).GetBookActiveLoans(book.ID)
+	if err != nil {
+		log.Printf("Błąd pobierania wypożyczeń książki %s: %v", book.ID, err)
+		entry.Error = "Błąd pobierania wypożyczeń"
+		h.renderEntry(w, entry)
+		return
+	}
+
+	switch len(loans) {
+	case 0:
+		entry.Error = "Książka nie jest wypożyczona"
+	case 1:
+		h.returnLoan(r, entry, loans[0].ID)
+	default:
+		entry.Candidates = loans
+	}
+	h.renderEntry(w, entry)
+}
+
+// ReturnLoan przyjmuje zwrot wybranego egzemplarza, gdy wypożyczonych jest kilka
+// (POST /staff/returns/loans/{id})
+func (h *ReturnsHandler) ReturnLoan(w http.ResponseWriter, r *http.Request) {
+	entry := &ReturnEntry{Time: time.Now()}
+	if h.store == nil {
+		entry.Error = "Baza danych niedostępna"
+	} else {
+		h.returnLoan(r, entry, chi.URLParam(r, "id"))
+	}
+	h.renderEntry(w, entry)
+}
+
+// returnLoan kończy wypożyczenie i zapisuje skutki zwrotu we wpisie dziennika
+func (h *ReturnsHandler) returnLoan(r *http.Request, entry *ReturnEntry, loanID string) {
+	audit, err := h.fbClient.Traced(r.Context()).BeginReturnAudit(loanID)
+	if err != nil {
+		log.Printf("Błąd dziennika zmian przed zwrotem %s: %v", loanID, err)
+	}
+
+	result, err := h.store.Loans(r.Context()).ReturnLoan(loanID)
+	if err != nil {
+		log.Printf("Błąd zwrotu wypożyczenia %s: %v", loanID, err)
+		entry.Error = "Błąd zwrotu: " + err.Error()
+		return
+	}
+	recordStaffActivity(h.fbClient, r, models.StaffActionReturn)
+	recordAudit(r, audit, models.AuditReturn, loanID, returnAuditLabel(result.Loan))
+
+	entry.Loan = result.Loan
+	entry.Fine = result.Fine
+	entry.Reservation = result.NextReservation
+	if entry.Book == nil {
+		entry.Book = &models.Book{ID: result.Loan.BookID, Title: result.Loan.BookTitle}
+	}
+}
+
+func (h *ReturnsHandler) renderEntry(w http.ResponseWriter, entry *ReturnEntry) {
+	if h.returnsTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+	if err := h.returnsTemplate.ExecuteTemplate(w, "entry", entry); err != nil {
+		log.Printf("Błąd renderowania wpisu zwrotu: %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"library-management-system/internal/models"
+	"library-management-system/internal/repository/memory"
+)
+
+func TestScanReturnWithMemoryStore(t *testing.T) {
+	store := memory.NewStore([]models.Book{{ID: "b1", ISBN: "9788324012343", Title: "Łódź", Author: "Julian Tuwim", TotalCopies: 1, AvailableCopies: 1}})
+	for _, user := range []*models.User{
+		{ID: "u1", FirstName: "Anna", LastName: "Nowak", Role: models.RoleReader, IsActive: true, CurrentLoans: 1},
+		{ID: "u2", FirstName: "Jan", LastName: "Kowalski", Role: models.RoleReader, IsActive: true},
+	} {
+		if err := store.UpdateUser(user.ID, user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Jedyny egzemplarz wydany pierwszej czytelniczce, druga czeka w kolejce
+	loan := &models.Loan{BookID: "b1", UserID: "u1"}
+	if err := store.CreateLoan(loan); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpdateBookAvailability("b1", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.ConfirmPickup(loan.PickupCode); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.CreateReservation(&models.Reservation{BookID: "b1", UserID: "u2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	h := &ReturnsHandler{
+		returnsTemplate: template.Must(template.New("returns").Parse(
+			`{{define "entry"}}{{with .Error}}błąd: {{.}}{{else}}zwrot {{.Loan.ID}}{{with .Reservation}} dla {{.UserID}}{{end}}{{end}}{{end}}`)),
+		store: store,
+	}
+	scan := func() string {
+		r := httptest.NewRequest(http.MethodPost, "/staff/returns", strings.NewReader(url.Values{"code": {"9788324012343"}}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		h.ScanReturn(w, r)
+		return w.Body.String()
+	}
+
+	if got, want := scan(), "zwrot "+loan.ID+" dla u2"; got != want {
+		t.Fatalf("pierwszy skan = %q, oczekiwano %q", got, want)
+	}
+	if got := scan(); got != "błąd: Książka nie jest wypożyczona" {
+		t.Errorf("ponowny skan = %q, oczekiwano błędu", got)
+	}
+
+	book, _ := store.GetBook("b1")
+	if book.AvailableCopies != 0 {
+		t.Errorf("dostępne egzemplarze = %d, oczekiwano 0 - egzemplarz czeka na rezerwację", book.AvailableCopies)
+	}
+	ready, err := store.GetReadyReservations()
+	if err != nil || len(ready) != 1 || ready[0].UserID != "u2" {
+		t.Errorf("GetReadyReservations = %+v, %v; oczekiwano rezerwacji u2", ready, err)
+	}
+	if reader, _ := store.GetUser("u1"); reader.CurrentLoans != 0 {
+		t.Errorf("licznik wypożyczeń czytelniczki = %d, oczekiwano 0", reader.CurrentLoans)
+	}
+}
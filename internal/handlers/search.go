@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"library-management-system/internal/analytics"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/middleware"
+	"library-management-system/internal/models"
+	"library-management-system/internal/repository"
+	"library-management-system/internal/search"
+)
+
+// SearchHandler obsługuje globalne wyszukiwanie w serwisie
+type SearchHandler struct {
+	resultsTemplate *template.Template
+	index           *search.Index
+	fbClient        *firebase.Client
+	analytics       *analytics.Recorder
+}
+
+// NewSearchHandler tworzy nowy handler wyszukiwania globalnego.
+// Indeks jest współdzielony z handlerami, które go unieważniają po zmianach.
+func NewSearchHandler(fbClient *firebase.Client, index *search.Index, recorder *analytics.Recorder) *SearchHandler {
+	resultsTmpl, err := template.New("search.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/search.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu search.html: %v", err)
+	}
+
+	return &SearchHandler{
+		resultsTemplate: resultsTmpl,
+		index:           index,
+		fbClient:        fbClient,
+		analytics:       recorder,
+	}
+}
+
+// ShowResults wyświetla wyniki wyszukiwania globalnego (GET /search)
+func (h *SearchHandler) ShowResults(w http.ResponseWriter, r *http.Request) {
+	if h.resultsTemplate == nil {
+		http.Error(w, "Szablon wyszukiwania nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+
+	session := middleware.GetSessionFromContext(r.Context())
+	data := NewPageData(r)
+	data["Query"] = query
+
+	if query != "" {
+		if err := h.index.Refresh(); err != nil {
+			log.Printf("Błąd budowania indeksu wyszukiwania: %v", err)
+			data["Error"] = "Wyszukiwanie jest chwilowo niedostępne"
+		} else {
+			results := h.index.Search(query)
+			data["Results"] = results
+
+			// Wyszukiwanie na żywo (htmx) wysyła zapytanie przy każdym naciśnięciu klawisza,
+			// więc liczymy tylko pełne wyszukiwania
+			if r.Header.Get("HX-Request") != "true" && !isStaff(session) {
+				h.analytics.RecordSearch(query, results.Total())
+			}
+		}
+	}
+
+	// Dla htmx zwróć tylko fragment z wynikami
+	name := "search.html"
+	if r.Header.Get("HX-Request") == "true" {
+		name = "results"
+	}
+
+	if err := h.resultsTemplate.ExecuteTemplate(w, name, data); err != nil {
+		log.Printf("Błąd renderowania wyników wyszukiwania: %v", err)
+		http.Error(w, "Błąd renderowania strony", http.StatusInternalServerError)
+	}
+}
+
+// SearchIndexLoader zwraca funkcję pobierającą książki i ogłoszenia do indeksu wyszukiwania.
+// Ogłoszenia są tylko w Firestore - bez Firebase indeks obejmuje sam katalog.
+func SearchIndexLoader(store repository.Store, fbClient *firebase.Client) search.Loader {
+	return func() ([]*models.Book, []*models.Announcement, error) {
+		if store == nil {
+			return nil, nil, fmt.Errorf("baza danych niedostępna")
+		}
+
+		books, err := store.Books(context.Background()).ListBooks()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if fbClient == nil {
+			return books, nil, nil
+		}
+		announcements, err := fbClient.ListAnnouncements()
+		if err != nil {
+			// Ogłoszenia nie są krytyczne - indeksuj same książki
+			log.Printf("Błąd pobierania ogłoszeń do indeksu: %v", err)
+		}
+
+		return books, announcements, nil
+	}
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/firebase"
+)
+
+// SelfCheckHandler zarządza stanowiskami samoobsługowymi korzystającymi z API /api/v1/selfcheck
+type SelfCheckHandler struct {
+	stationsTemplate *template.Template
+	fbClient         *firebase.Client
+}
+
+// NewSelfCheckHandler tworzy handler stanowisk samoobsługowych
+func NewSelfCheckHandler(fbClient *firebase.Client) *SelfCheckHandler {
+	stationsTmpl, err := template.New("selfcheck_stations.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/selfcheck_stations.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/selfcheck_stations.html: %v", err)
+	}
+
+	return &SelfCheckHandler{
+		stationsTemplate: stationsTmpl,
+		fbClient:         fbClient,
+	}
+}
+
+// ListStations wyświetla stanowiska samoobsługowe z formularzem dodawania (GET /staff/selfcheck)
+func (h *SelfCheckHandler) ListStations(w http.ResponseWriter, r *http.Request) {
+	h.render(w, r, NewPageData(r))
+}
+
+// CreateStation dodaje stanowisko i jednorazowo pokazuje jego token (POST /staff/selfcheck)
+func (h *SelfCheckHandler) CreateStation(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	data := NewPageData(r)
+	station, token, err := h.fbClient.Traced(r.Context()).CreateSelfCheckStation(r.FormValue("name"))
+	if err != nil {
+		log.Printf("Błąd dodawania stanowiska samoobsługowego: %v", err)
+		data["Error"] = "Nie udało się dodać stanowiska: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+	} else {
+		// Token nie jest nigdzie zapisany jawnie - personel musi go teraz przepisać do stanowiska
+		data["NewStation"] = station
+		data["NewToken"] = token
+	}
+	h.render(w, r, data)
+}
+
+// DeleteStation usuwa stanowisko, a jego token przestaje działać (POST /staff/selfcheck/{id}/delete)
+func (h *SelfCheckHandler) DeleteStation(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := h.fbClient.Traced(r.Context()).DeleteSelfCheckStation(chi.URLParam(r, "id")); err != nil {
+		log.Printf("Błąd usuwania stanowiska samoobsługowego: %v", err)
+		http.Error(w, "Błąd usuwania stanowiska", http.StatusInternalServerError)
+		return
+	}
+
+	basepath.Redirect(w, r, "/staff/selfcheck", http.StatusSeeOther)
+}
+
+func (h *SelfCheckHandler) render(w http.ResponseWriter, r *http.Request, data TemplateData) {
+	if h.stationsTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	if h.fbClient != nil {
+		stations, err := h.fbClient.Traced(r.Context()).ListSelfCheckStations()
+		if err != nil {
+			log.Printf("Błąd pobierania stanowisk samoobsługowych: %v", err)
+			data["Error"] = "Błąd pobierania stanowisk z bazy danych"
+		}
+		data["Stations"] = stations
+	}
+
+	if err := h.stationsTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania stanowisk samoobsługowych: %v", err)
+	}
+}
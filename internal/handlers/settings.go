@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/demo"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+)
+
+// SettingsHandler obsługuje edycję ustawień biblioteki w panelu personelu
+type SettingsHandler struct {
+	settingsTemplate *template.Template
+	fbClient         *firebase.Client
+}
+
+// NewSettingsHandler tworzy handler ustawień biblioteki
+func NewSettingsHandler(fbClient *firebase.Client) *SettingsHandler {
+	settingsTmpl, err := template.New("settings.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/settings.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/settings.html: %v", err)
+	}
+
+	return &SettingsHandler{
+		settingsTemplate: settingsTmpl,
+		fbClient:         fbClient,
+	}
+}
+
+// ShowSettings wyświetla formularz ustawień (GET /staff/settings)
+func (h *SettingsHandler) ShowSettings(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	settings, err := h.fbClient.Traced(r.Context()).GetSettings()
+	if err != nil {
+		log.Printf("Błąd pobierania ustawień: %v", err)
+		http.Error(w, "Błąd pobierania ustawień", http.StatusInternalServerError)
+		return
+	}
+
+	data := NewPageData(r)
+	data["Settings"] = settings
+	data["Currencies"] = models.Currencies
+	data["Locales"] = models.Locales
+	data["Weekdays"] = models.Weekdays
+	data["HomeBlocks"] = homeBlockChoices(settings.HomeBlocks)
+	data["Saved"] = r.URL.Query().Get("saved") == "1"
+	data["DemoMode"] = demo.Enabled()
+	h.render(w, data)
+}
+
+// UpdateSettings zapisuje ustawienia biblioteki (POST /staff/settings)
+func (h *SettingsHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	settings := &models.Settings{
+		LibraryName: strings.TrimSpace(r.FormValue("library_name")),
+		LoanDays:    formInt(r, "loan_days"),
+		MaxLoans:    formInt(r, "max_loans"),
+		PickupDays:  formInt(r, "pickup_days"),
+
+		MaxReadyHolds: formInt(r, "max_ready_holds"),
+
+		PickupLocations: formLines(r, "pickup_locations"),
+		Currency:        r.FormValue("currency"),
+		Locale:          r.FormValue("locale"),
+
+		PickupCodeAlphabet:         models.PickupCodeAlphabet(r.FormValue("pickup_code_alphabet")),
+		PickupCodeLength:           formInt(r, "pickup_code_length"),
+		PickupCodeExcludeAmbiguous: r.FormValue("pickup_code_exclude_ambiguous") == "on",
+
+		OpenDays: formInts(r, "open_days"),
+
+		ReceiptPrinter: strings.TrimSpace(r.FormValue("receipt_printer")),
+
+		RequireApproval:    r.FormValue("require_approval") == "on",
+		LoanRetentionYears: formInt(r, "loan_retention_years"),
+
+		CommentPremoderation: r.FormValue("comment_premoderation") == "on",
+		BlockedWords:         formLines(r, "blocked_words"),
+
+		HomeBlocks:      formHomeBlocks(r),
+		CatalogPageSize: formInt(r, "catalog_page_size"),
+
+		NavbarLinks: formMenuLinks(r, "navbar_links"),
+		FooterLinks: formMenuLinks(r, "footer_links"),
+	}
+
+	if err := h.fbClient.Traced(r.Context()).SaveSettings(settings); err != nil {
+		log.Printf("Błąd zapisywania ustawień: %v", err)
+		data := NewPageData(r)
+		data["Settings"] = settings
+		data["Currencies"] = models.Currencies
+		data["Locales"] = models.Locales
+		data["Weekdays"] = models.Weekdays
+		data["HomeBlocks"] = homeBlockChoices(settings.HomeBlocks)
+		data["Error"] = "Nie udało się zapisać ustawień: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		h.render(w, data)
+		return
+	}
+
+	basepath.Redirect(w, r, "/staff/settings?saved=1", http.StatusSeeOther)
+}
+
+func (h *SettingsHandler) render(w http.ResponseWriter, data TemplateData) {
+	if h.settingsTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+	data["MinLoanRetentionYears"] = models.MinLoanRetentionYears
+	data["CatalogPageSizes"] = models.CatalogPageSizes
+	data["MaxMenuLinks"] = models.MaxMenuLinks
+	if err := h.settingsTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania ustawień: %v", err)
+	}
+}
+
+// homeBlockChoice to blok strony głównej w formularzu ustawień
+type homeBlockChoice struct {
+	models.HomeBlockOption
+	Enabled bool
+}
+
+// homeBlockChoices zwraca bloki do formularza: najpierw włączone w kolejności
+// wyświetlania, potem pozostałe
+func homeBlockChoices(blocks []models.HomeBlock) []homeBlockChoice {
+	enabled := make(map[models.HomeBlock]bool, len(blocks))
+	var choices []homeBlockChoice
+	for _, block := range blocks {
+		for _, option := range models.HomeBlockOptions {
+			if option.Block == block && !enabled[block] {
+				enabled[block] = true
+				choices = append(choices, homeBlockChoice{HomeBlockOption: option, Enabled: true})
+			}
+		}
+	}
+	for _, option := range models.HomeBlockOptions {
+		if !enabled[option.Block] {
+			choices = append(choices, homeBlockChoice{HomeBlockOption: option})
+		}
+	}
+	return choices
+}
+
+// formHomeBlocks zwraca zaznaczone bloki strony głównej w kolejności wierszy formularza
+func formHomeBlocks(r *http.Request) []models.HomeBlock {
+	if err := r.ParseForm(); err != nil {
+		return nil
+	}
+
+	checked := make(map[string]bool)
+	for _, v := range r.Form["home_blocks"] {
+		checked[v] = true
+	}
+
+	var blocks []models.HomeBlock
+	for _, v := range r.Form["home_block_order"] {
+		if checked[v] {
+			blocks = append(blocks, models.HomeBlock(v))
+			delete(checked, v) // Każdy blok tylko raz
+		}
+	}
+	return blocks
+}
+
+// formLines zwraca niepuste linie pola tekstowego formularza
+func formLines(r *http.Request, name string) []string {
+	var lines []string
+	for _, line := range strings.Split(r.FormValue(name), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// formMenuLinks zwraca odnośniki z pola tekstowego formularza (jeden "Etykieta | adres" w linii)
+func formMenuLinks(r *http.Request, name string) []models.MenuLink {
+	var links []models.MenuLink
+	for _, line := range formLines(r, name) {
+		links = append(links, models.ParseMenuLink(line))
+	}
+	return links
+}
+
+// formInts zwraca liczby z wielokrotnego pola formularza (np. pól wyboru), pomijając niepoprawne
+func formInts(r *http.Request, name string) []int {
+	if err := r.ParseForm(); err != nil {
+		return nil
+	}
+
+	var values []int
+	for _, v := range r.Form[name] {
+		if n, err := strconv.Atoi(v); err == nil {
+			values = append(values, n)
+		}
+	}
+	return values
+}
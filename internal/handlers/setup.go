@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+	"library-management-system/internal/session"
+
+	"firebase.google.com/go/v4/auth"
+)
+
+// SetupHandler obsługuje kreator pierwszego uruchomienia: dopóki nie istnieje żadne
+// konto administratora, wszystkie strony przekierowują na /setup
+type SetupHandler struct {
+	template *template.Template
+	fbClient *firebase.Client
+
+	mu        sync.Mutex   // Zapobiega utworzeniu dwóch administratorów przez równoległe formularze
+	completed atomic.Bool  // Po utworzeniu administratora baza nie jest już odpytywana
+	checkedAt atomic.Int64 // Czas (UnixNano) ostatniego sprawdzenia, które nie znalazło administratora
+}
+
+// setupCheckTTL to czas, przez który brak administratora nie jest sprawdzany ponownie,
+// żeby każde żądanie przed konfiguracją nie odpytywało bazy
+const setupCheckTTL = 5 * time.Second
+
+// NewSetupHandler tworzy nowy handler kreatora konfiguracji
+func NewSetupHandler(fbClient *firebase.Client) *SetupHandler {
+	tmpl, err := template.New("setup.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/setup.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu setup.html: %v", err)
+	}
+
+	return &SetupHandler{
+		template: tmpl,
+		fbClient: fbClient,
+	}
+}
+
+// Middleware przekierowuje na /setup, dopóki konfiguracja nie zostanie zakończona
+func (h *SetupHandler) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == basepath.URL("/setup") || isAssetPath(r.URL.Path) || !h.setupRequired() {
+			next.ServeHTTP(w, r)
+			return
+		}
+		basepath.Redirect(w, r, "/setup", http.StatusSeeOther)
+	})
+}
+
+// isAssetPath sprawdza, czy ścieżka to plik statyczny lub plik aplikacji instalowanej,
+// dla których kreator nie przekierowuje ani nie sprawdza konfiguracji
+func isAssetPath(path string) bool {
+	return strings.HasPrefix(path, basepath.URL("/static/")) ||
+		path == basepath.URL("/sw.js") || path == basepath.URL("/manifest.webmanifest")
+}
+
+// setupRequired sprawdza, czy nie istnieje jeszcze żadne konto administratora.
+// Brak administratora jest zapamiętywany na setupCheckTTL.
+func (h *SetupHandler) setupRequired() bool {
+	if h.completed.Load() {
+		return false
+	}
+	if time.Since(time.Unix(0, h.checkedAt.Load())) < setupCheckTTL {
+		return true
+	}
+	return h.checkSetupRequired()
+}
+
+// checkSetupRequired odpytuje bazę o konto administratora z pominięciem zapamiętanego wyniku
+func (h *SetupHandler) checkSetupRequired() bool {
+	hasAdmin, err := h.fbClient.HasAdmin()
+	if err != nil {
+		// Błąd bazy nie powinien blokować aplikacji - strony pokażą własne błędy
+		log.Printf("Błąd sprawdzania konfiguracji: %v", err)
+		return false
+	}
+	if hasAdmin {
+		h.completed.Store(true)
+	} else {
+		h.checkedAt.Store(time.Now().UnixNano())
+	}
+	return !hasAdmin
+}
+
+// ShowSetup wyświetla formularz konfiguracji (GET /setup)
+func (h *SetupHandler) ShowSetup(w http.ResponseWriter, r *http.Request) {
+	if !h.setupRequired() {
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	settings, err := h.fbClient.Traced(r.Context()).GetSettings()
+	if err != nil {
+		log.Printf("Błąd pobierania ustawień: %v", err)
+		settings = models.DefaultSettings()
+	}
+
+	h.render(w, map[string]interface{}{
+		"Settings": settings,
+	})
+}
+
+// CompleteSetup zapisuje ustawienia i tworzy pierwszego administratora (POST /setup)
+func (h *SetupHandler) CompleteSetup(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// Przed utworzeniem administratora zawsze sprawdzamy bazę - konto mogło powstać
+	// w innej instancji serwera
+	if h.completed.Load() || !h.checkSetupRequired() {
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	settings := &models.Settings{
+		LibraryName: strings.TrimSpace(r.FormValue("library_name")),
+		LoanDays:    formInt(r, "loan_days"),
+		MaxLoans:    formInt(r, "max_loans"),
+		PickupDays:  formInt(r, "pickup_days"),
+	}
+	firstName := strings.TrimSpace(r.FormValue("first_name"))
+	lastName := strings.TrimSpace(r.FormValue("last_name"))
+	email := strings.TrimSpace(r.FormValue("email"))
+	password := r.FormValue("password")
+
+	data := map[string]interface{}{
+		"Settings":  settings,
+		"FirstName": firstName,
+		"LastName":  lastName,
+		"Email":     email,
+	}
+	fail := func(message string) {
+		data["Error"] = message
+		w.WriteHeader(http.StatusBadRequest)
+		h.render(w, data)
+	}
+
+	// Walidacja
+	if settings.LibraryName == "" || settings.LoanDays < 1 || settings.MaxLoans < 1 || settings.PickupDays < 1 {
+		fail("Podaj nazwę biblioteki oraz dodatnie wartości zasad wypożyczeń")
+		return
+	}
+	if firstName == "" || lastName == "" || email == "" {
+		fail("Imię, nazwisko i email administratora są wymagane")
+		return
+	}
+	if len(password) < 8 {
+		fail("Hasło administratora musi mieć minimum 8 znaków")
+		return
+	}
+	if password != r.FormValue("password_confirm") {
+		fail("Hasła nie są identyczne")
+		return
+	}
+
+	if err := h.fbClient.Traced(r.Context()).SaveSettings(settings); err != nil {
+		log.Printf("Błąd zapisywania ustawień: %v", err)
+		fail("Nie udało się zapisać ustawień biblioteki")
+		return
+	}
+
+	// Utwórz konto administratora w Firebase Auth
+	params := (&auth.UserToCreate{}).
+		Email(email).
+		Password(password).
+		DisplayName(firstName + " " + lastName)
+
+	firebaseUser, err := h.fbClient.Auth.CreateUser(r.Context(), params)
+	if err != nil {
+		log.Printf("Błąd tworzenia administratora w Firebase Auth: %v", err)
+		fail("Nie udało się utworzyć konta - sprawdź adres email")
+		return
+	}
+
+	user := &models.User{
+		FirebaseUID: firebaseUser.UID,
+		Email:       email,
+		FirstName:   firstName,
+		LastName:    lastName,
+		Role:        models.RoleAdmin,
+		IsActive:    true,
+	}
+
+	if err := h.fbClient.Traced(r.Context()).CreateUser(user); err != nil {
+		log.Printf("Błąd tworzenia administratora w Firestore: %v", err)
+		h.fbClient.Auth.DeleteUser(r.Context(), firebaseUser.UID)
+		fail("Błąd tworzenia konta administratora")
+		return
+	}
+
+	h.completed.Store(true)
+	log.Printf("Konfiguracja zakończona - utworzono administratora %s", email)
+
+	// Zaloguj administratora i przejdź do panelu personelu
+	sess, err := session.GetManager().CreateSession(user)
+	if err != nil {
+		log.Printf("Błąd tworzenia sesji: %v", err)
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	session.SetSessionCookie(w, sess.ID)
+	basepath.Redirect(w, r, "/staff", http.StatusSeeOther)
+}
+
+func (h *SetupHandler) render(w http.ResponseWriter, data map[string]interface{}) {
+	if h.template == nil {
+		http.Error(w, "Szablon konfiguracji nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+	if err := h.template.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania strony konfiguracji: %v", err)
+	}
+}
+
+// formInt odczytuje liczbę całkowitą z formularza (0 gdy pole jest puste lub niepoprawne)
+func formInt(r *http.Request, name string) int {
+	value, err := strconv.Atoi(strings.TrimSpace(r.FormValue(name)))
+	if err != nil {
+		return 0
+	}
+	return value
+}
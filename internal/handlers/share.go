@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/middleware"
+	"library-management-system/internal/models"
+)
+
+// shareRef to wartość parametru ref w udostępnianych linkach - wejścia z nimi
+// są liczone w statystykach jako wejścia z udostępnień
+const shareRef = "share"
+
+// shareDescriptionLength ogranicza opis książki w podglądzie linku
+const shareDescriptionLength = 200
+
+// shareChannel to serwis, do którego czytelnik może udostępnić stronę książki
+type shareChannel struct {
+	Name  string // Identyfikator w adresie /books/{id}/share/{name}
+	Label string
+	// link zwraca adres udostępnienia w serwisie dla linku do książki i tekstu wiadomości
+	link func(link, text string) string
+}
+
+// shareChannels to serwisy w kolejności przycisków na stronie książki
+var shareChannels = []shareChannel{
+	{"facebook", "Facebook", func(link, _ string) string {
+		return "https://www.facebook.com/sharer/sharer.php?u=" + url.QueryEscape(link)
+	}},
+	{"messenger", "Messenger", func(link, _ string) string {
+		return "fb-messenger://share/?link=" + url.QueryEscape(link)
+	}},
+	{"whatsapp", "WhatsApp", func(link, text string) string {
+		return "https://wa.me/?text=" + url.QueryEscape(text+" "+link)
+	}},
+	{"telegram", "Telegram", func(link, text string) string {
+		return "https://t.me/share/url?url=" + url.QueryEscape(link) + "&text=" + url.QueryEscape(text)
+	}},
+	{"x", "X", func(link, text string) string {
+		return "https://x.com/intent/post?url=" + url.QueryEscape(link) + "&text=" + url.QueryEscape(text)
+	}},
+	{"email", "E-mail", func(link, text string) string {
+		// W mailto spacje muszą być zapisane jako %20, nie jako +
+		return "mailto:?subject=" + url.PathEscape(text) + "&body=" + url.PathEscape(text+"\n\n"+link)
+	}},
+}
+
+// copyLinkChannel to kanał w statystykach dla skopiowanego linku
+const copyLinkChannel = "link"
+
+func findShareChannel(name string) *shareChannel {
+	for i := range shareChannels {
+		if shareChannels[i].Name == name {
+			return &shareChannels[i]
+		}
+	}
+	return nil
+}
+
+// bookShareURL zwraca pełny adres strony książki do udostępnienia
+func (h *BooksHandler) bookShareURL(book *models.Book) string {
+	return h.baseURL + "/books/" + book.ID + "?ref=" + shareRef
+}
+
+// shareText zwraca treść wiadomości z udostępnianym linkiem
+func shareText(book *models.Book) string {
+	if book.Author == "" {
+		return book.Title
+	}
+	return book.Title + " - " + book.Author
+}
+
+// shareDescription zwraca opis książki do podglądu linku (autor, rok i początek opisu)
+func shareDescription(book *models.Book) string {
+	var parts []string
+	if book.Author != "" {
+		parts = append(parts, book.Author)
+	}
+	if book.PublicationYear > 0 {
+		parts = append(parts, strconv.Itoa(book.PublicationYear))
+	}
+	description := strings.Join(parts, ", ")
+
+	if text := strings.Join(strings.Fields(book.Description), " "); text != "" {
+		if runes := []rune(text); len(runes) > shareDescriptionLength {
+			text = strings.TrimRight(string(runes[:shareDescriptionLength]), " ,.;:") + "…"
+		}
+		if description != "" {
+			description += ". "
+		}
+		description += text
+	}
+	return description
+}
+
+// ShareBook zlicza udostępnienie i przekierowuje do serwisu (GET /books/{id}/share/{channel})
+func (h *BooksHandler) ShareBook(w http.ResponseWriter, r *http.Request) {
+	channel := findShareChannel(chi.URLParam(r, "channel"))
+	if channel == nil {
+		http.Error(w, "Nieznany serwis", http.StatusNotFound)
+		return
+	}
+	if h.store == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	book, err := h.store.Books(r.Context()).GetBook(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("Błąd pobierania książki do udostępnienia: %v", err)
+		http.Error(w, "Książka nie została znaleziona", http.StatusNotFound)
+		return
+	}
+
+	if !isStaff(middleware.GetSessionFromContext(r.Context())) {
+		h.analytics.RecordShare(book, channel.Name, channel.Label)
+	}
+
+	// Każde kliknięcie musi dotrzeć do serwera, żeby zostało policzone
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, channel.link(h.bookShareURL(book), shareText(book)), http.StatusFound)
+}
+
+// CopyBookLink zlicza skopiowanie linku do książki (POST /books/{id}/share/link,
+// wysyłane przez htmx po kliknięciu „Kopiuj link”)
+func (h *BooksHandler) CopyBookLink(w http.ResponseWriter, r *http.Request) {
+	if h.store == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	book, err := h.store.Books(r.Context()).GetBook(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Książka nie została znaleziona", http.StatusNotFound)
+		return
+	}
+
+	if !isStaff(middleware.GetSessionFromContext(r.Context())) {
+		h.analytics.RecordShare(book, copyLinkChannel, "Skopiowany link")
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
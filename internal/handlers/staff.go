@@ -4,15 +4,21 @@ import (
 	"html/template"
 	"log"
 	"net/http"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 
+	"library-management-system/internal/basepath"
 	"library-management-system/internal/firebase"
 	"library-management-system/internal/middleware"
 	"library-management-system/internal/models"
+	"library-management-system/internal/repository"
+	"library-management-system/internal/returnrisk"
+	"library-management-system/internal/session"
 )
 
 type StaffHandler struct {
@@ -22,7 +28,12 @@ type StaffHandler struct {
 	userEditTemplate       *template.Template
 	reportsTemplate        *template.Template
 	pendingPickupsTemplate *template.Template
-	fbClient               *firebase.Client
+	userSyncTemplate       *template.Template
+	pendingUsersTemplate   *template.Template
+	readerImportTemplate   *template.Template
+	trashTemplate          *template.Template
+	store                  repository.Store
+	fbClient               *firebase.Client // Funkcje spoza repozytoriów: raporty, PIN, wpłaty, regulamin, dziennik zmian
 }
 
 type LoanDisplay struct {
@@ -35,42 +46,85 @@ type LoanDisplay struct {
 	DueDate     time.Time
 	ReturnDate  *time.Time
 	Status      string
-	FineAmount  float64
+	FineAmount  models.Money
 	IsOverdue   bool
-	DaysOverdue int
+	Notes       string
+	ReadingRoom bool // Udostępnienie na miejscu
+	// Ryzyko spóźnionego zwrotu trwającego wypożyczenia według historii czytelnika
+	Risk returnrisk.Assessment
 }
 
-func NewStaffHandler(fbClient *firebase.Client) *StaffHandler {
-	dashboardTmpl, err := template.ParseFiles("internal/templates/staff/dashboard.html")
+// PendingPickupDisplay to wiersz listy oczekujących odbiorów z danymi potrzebnymi
+// do zdjęcia książki z półki i kontaktu z czytelnikiem
+type PendingPickupDisplay struct {
+	*models.Loan
+	ShelfLocation  string
+	ReaderEmail    string
+	ReaderPhone    string
+	PickupDeadline time.Time // Data zamówienia + czas na odbiór z ustawień biblioteki
+}
+
+// Expired sprawdza czy minął czas na odbiór
+func (p PendingPickupDisplay) Expired() bool {
+	return time.Now().After(p.PickupDeadline)
+}
+
+// ExpiresSoon sprawdza czy czas na odbiór mija w ciągu doby - warto zadzwonić do czytelnika
+func (p PendingPickupDisplay) ExpiresSoon() bool {
+	return !p.Expired() && time.Until(p.PickupDeadline) < 24*time.Hour
+}
+
+func NewStaffHandler(store repository.Store, fbClient *firebase.Client) *StaffHandler {
+	dashboardTmpl, err := template.New("dashboard.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/dashboard.html")
 	if err != nil {
 		log.Printf("Błąd ładowania szablonu staff/dashboard.html: %v", err)
 	}
 
-	loansTmpl, err := template.ParseFiles("internal/templates/staff/loans.html")
+	loansTmpl, err := template.New("loans.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/loans.html")
 	if err != nil {
 		log.Printf("Błąd ładowania szablonu staff/loans.html: %v", err)
 	}
 
-	usersTmpl, err := template.ParseFiles("internal/templates/staff/users.html")
+	usersTmpl, err := template.New("users.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/users.html")
 	if err != nil {
 		log.Printf("Błąd ładowania szablonu staff/users.html: %v", err)
 	}
 
-	userEditTmpl, err := template.ParseFiles("internal/templates/staff/user_edit.html")
+	userEditTmpl, err := template.New("user_edit.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/user_edit.html")
 	if err != nil {
 		log.Printf("Błąd ładowania szablonu staff/user_edit.html: %v", err)
 	}
 
-	reportsTmpl, err := template.ParseFiles("internal/templates/staff/reports.html")
+	reportsTmpl, err := template.New("reports.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/reports.html")
 	if err != nil {
 		log.Printf("Błąd ładowania szablonu staff/reports.html: %v", err)
 	}
 
-	pendingPickupsTmpl, err := template.ParseFiles("internal/templates/staff/pending_pickups.html")
+	pendingPickupsTmpl, err := template.New("pending_pickups.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/pending_pickups.html")
 	if err != nil {
 		log.Printf("Błąd ładowania szablonu staff/pending_pickups.html: %v", err)
 	}
 
+	userSyncTmpl, err := template.New("user_sync.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/user_sync.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/user_sync.html: %v", err)
+	}
+
+	pendingUsersTmpl, err := template.New("pending_users.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/pending_users.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/pending_users.html: %v", err)
+	}
+
+	readerImportTmpl, err := template.New("reader_import.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/reader_import.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/reader_import.html: %v", err)
+	}
+
+	trashTmpl, err := template.New("trash.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/trash.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/trash.html: %v", err)
+	}
+
 	return &StaffHandler{
 		dashboardTemplate:      dashboardTmpl,
 		loansTemplate:          loansTmpl,
@@ -78,6 +132,11 @@ func NewStaffHandler(fbClient *firebase.Client) *StaffHandler {
 		userEditTemplate:       userEditTmpl,
 		reportsTemplate:        reportsTmpl,
 		pendingPickupsTemplate: pendingPickupsTmpl,
+		userSyncTemplate:       userSyncTmpl,
+		pendingUsersTemplate:   pendingUsersTmpl,
+		readerImportTemplate:   readerImportTmpl,
+		trashTemplate:          trashTmpl,
+		store:                  store,
 		fbClient:               fbClient,
 	}
 }
@@ -85,7 +144,7 @@ func NewStaffHandler(fbClient *firebase.Client) *StaffHandler {
 func (h *StaffHandler) ShowDashboard(w http.ResponseWriter, r *http.Request) {
 	session := middleware.GetSessionFromContext(r.Context())
 	if session == nil {
-		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
 		return
 	}
 
@@ -94,7 +153,7 @@ func (h *StaffHandler) ShowDashboard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Pobierz rzeczywiste statystyki z Firebase
+	// Pobierz rzeczywiste statystyki z bazy danych
 	stats := map[string]interface{}{
 		"totalBooks":     0,
 		"activeLoans":    0,
@@ -103,50 +162,44 @@ func (h *StaffHandler) ShowDashboard(w http.ResponseWriter, r *http.Request) {
 		"totalUsers":     0,
 	}
 
-	if h.fbClient != nil {
+	if h.store != nil {
 		// Liczba książek
-		if totalBooks, err := h.fbClient.CountTotalBooks(); err == nil {
+		if totalBooks, err := h.store.Books(r.Context()).CountTotalBooks(); err == nil {
 			stats["totalBooks"] = totalBooks
 		} else {
 			log.Printf("Błąd pobierania liczby książek: %v", err)
 		}
 
 		// Liczba aktywnych wypożyczeń
-		if activeLoans, err := h.fbClient.CountActiveLoans(); err == nil {
+		if activeLoans, err := h.store.Loans(r.Context()).CountActiveLoans(); err == nil {
 			stats["activeLoans"] = activeLoans
 		} else {
 			log.Printf("Błąd pobierania liczby aktywnych wypożyczeń: %v", err)
 		}
 
 		// Liczba wypożyczeń oczekujących na odbiór
-		if allLoans, err := h.fbClient.ListLoans(); err == nil {
-			pendingCount := 0
-			for _, loan := range allLoans {
-				if loan.Status == models.LoanStatusPendingPickup {
-					pendingCount++
-				}
-			}
-			stats["pendingPickups"] = pendingCount
+		if pending, err := h.store.Loans(r.Context()).GetPendingPickupLoans(); err == nil {
+			stats["pendingPickups"] = len(pending)
 		} else {
 			log.Printf("Błąd pobierania liczby oczekujących odbiorów: %v", err)
 		}
 
 		// Liczba przeterminowanych wypożyczeń
-		if overdueLoans, err := h.fbClient.CountOverdueLoans(); err == nil {
+		if overdueLoans, err := h.store.Loans(r.Context()).CountOverdueLoans(); err == nil {
 			stats["overdueLoans"] = overdueLoans
 		} else {
 			log.Printf("Błąd pobierania liczby przeterminowanych wypożyczeń: %v", err)
 		}
 
 		// Liczba użytkowników
-		if totalUsers, err := h.fbClient.CountTotalUsers(); err == nil {
+		if totalUsers, err := h.store.Users(r.Context()).CountTotalUsers(); err == nil {
 			stats["totalUsers"] = totalUsers
 		} else {
 			log.Printf("Błąd pobierania liczby użytkowników: %v", err)
 		}
 	}
 
-	data := NewTemplateData(session)
+	data := NewPageData(r)
 	data["Stats"] = stats
 
 	if err := h.dashboardTemplate.Execute(w, data); err != nil {
@@ -158,7 +211,7 @@ func (h *StaffHandler) ShowDashboard(w http.ResponseWriter, r *http.Request) {
 func (h *StaffHandler) ShowLoans(w http.ResponseWriter, r *http.Request) {
 	session := middleware.GetSessionFromContext(r.Context())
 	if session == nil {
-		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
 		return
 	}
 
@@ -176,15 +229,17 @@ func (h *StaffHandler) ShowLoans(w http.ResponseWriter, r *http.Request) {
 	var loans []*models.Loan
 	var err error
 
-	if h.fbClient != nil {
+	if h.store != nil {
 		switch filter {
 		case "active":
-			loans, err = h.fbClient.GetActiveLoans()
+			loans, err = h.store.Loans(r.Context()).GetActiveLoans()
 		case "overdue":
-			loans, err = h.fbClient.GetOverdueLoans()
+			loans, err = h.store.Loans(r.Context()).GetOverdueLoans()
+		case "at-risk":
+			loans, err = h.store.Loans(r.Context()).GetActiveLoans()
 		case "returned":
 			// Pobierz zwrócone wypożyczenia
-			allLoans, e := h.fbClient.ListLoans()
+			allLoans, e := h.store.Loans(r.Context()).ListLoans()
 			if e == nil {
 				for _, loan := range allLoans {
 					if loan.Status == models.LoanStatusReturned {
@@ -195,7 +250,7 @@ func (h *StaffHandler) ShowLoans(w http.ResponseWriter, r *http.Request) {
 				err = e
 			}
 		default:
-			loans, err = h.fbClient.ListLoans()
+			loans, err = h.store.Loans(r.Context()).ListLoans()
 		}
 
 		if err != nil {
@@ -203,47 +258,59 @@ func (h *StaffHandler) ShowLoans(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	risks := h.loanRisks(r, loans)
+	if filter == "at-risk" {
+		var atRisk []*models.Loan
+		for _, loan := range loans {
+			if _, ok := risks[loan.ID]; ok {
+				atRisk = append(atRisk, loan)
+			}
+		}
+		loans = atRisk
+	}
+
 	// Przygotuj dane do wyświetlenia
 	var loansDisplay []LoanDisplay
-	if h.fbClient != nil {
+	if h.store != nil {
 		for _, loan := range loans {
 			// Pobierz dane książki
-			book, err := h.fbClient.GetBook(loan.BookID)
+			book, err := h.store.Books(r.Context()).GetBook(loan.BookID)
 			if err != nil {
 				log.Printf("Błąd pobierania książki %s: %v", loan.BookID, err)
 				continue
 			}
 
-			// Pobierz dane użytkownika
-			user, err := h.fbClient.GetUser(loan.UserID)
-			if err != nil {
-				log.Printf("Błąd pobierania użytkownika %s: %v", loan.UserID, err)
-				continue
-			}
-
-			daysOverdue := 0
-			if loan.IsOverdue() {
-				daysOverdue = int(time.Since(loan.DueDate).Hours() / 24)
+			// Pobierz dane użytkownika (zanonimizowane wypożyczenie nie ma już czytelnika, a konto
+			// usunięte do kosza - profilu; wtedy zostaje imię i nazwisko zapisane w wypożyczeniu)
+			userName, userEmail := loan.ReaderName(), ""
+			if !loan.Anonymized {
+				if user, err := h.store.Users(r.Context()).GetUser(loan.UserID); err != nil {
+					log.Printf("Błąd pobierania użytkownika %s: %v", loan.UserID, err)
+				} else {
+					userName, userEmail = user.FullName(), user.Email
+				}
 			}
 
 			loansDisplay = append(loansDisplay, LoanDisplay{
 				ID:          loan.ID,
 				BookTitle:   book.Title,
 				BookAuthor:  book.Author,
-				UserName:    user.FullName(),
-				UserEmail:   user.Email,
+				UserName:    userName,
+				UserEmail:   userEmail,
 				LoanDate:    loan.LoanDate,
 				DueDate:     loan.DueDate,
 				ReturnDate:  loan.ReturnDate,
 				Status:      string(loan.Status),
 				FineAmount:  loan.FineAmount,
 				IsOverdue:   loan.IsOverdue(),
-				DaysOverdue: daysOverdue,
+				Notes:       loan.Notes,
+				ReadingRoom: loan.IsReadingRoom(),
+				Risk:        risks[loan.ID],
 			})
 		}
 	}
 
-	data := NewTemplateData(session)
+	data := NewPageData(r)
 	data["Loans"] = loansDisplay
 	data["Filter"] = filter
 
@@ -256,7 +323,7 @@ func (h *StaffHandler) ShowLoans(w http.ResponseWriter, r *http.Request) {
 func (h *StaffHandler) ShowUsers(w http.ResponseWriter, r *http.Request) {
 	session := middleware.GetSessionFromContext(r.Context())
 	if session == nil {
-		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
 		return
 	}
 
@@ -266,19 +333,19 @@ func (h *StaffHandler) ShowUsers(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var users []*models.User
-	if h.fbClient != nil {
+	if h.store != nil {
 		var err error
-		users, err = h.fbClient.ListUsers()
+		users, err = h.store.Users(r.Context()).ListUsers()
 		if err != nil {
 			log.Printf("Błąd pobierania użytkowników: %v", err)
-			data := NewTemplateData(session)
+			data := NewPageData(r)
 			data["Error"] = "Błąd pobierania użytkowników z bazy danych"
 			h.usersTemplate.Execute(w, data)
 			return
 		}
 	}
 
-	data := NewTemplateData(session)
+	data := NewPageData(r)
 	data["Users"] = users
 
 	if err := h.usersTemplate.Execute(w, data); err != nil {
@@ -292,8 +359,8 @@ func (h *StaffHandler) SearchUsers(w http.ResponseWriter, r *http.Request) {
 	searchTerm := strings.ToLower(r.URL.Query().Get("search"))
 
 	var users []*models.User
-	if h.fbClient != nil {
-		allUsers, err := h.fbClient.ListUsers()
+	if h.store != nil {
+		allUsers, err := h.store.Users(r.Context()).ListUsers()
 		if err != nil {
 			log.Printf("Błąd pobierania użytkowników: %v", err)
 			w.Write([]byte("<p class='p-6 text-center text-red-600'>Błąd wyszukiwania</p>"))
@@ -322,7 +389,7 @@ func (h *StaffHandler) SearchUsers(w http.ResponseWriter, r *http.Request) {
 func (h *StaffHandler) ShowEditUser(w http.ResponseWriter, r *http.Request) {
 	session := middleware.GetSessionFromContext(r.Context())
 	if session == nil {
-		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
 		return
 	}
 
@@ -338,9 +405,9 @@ func (h *StaffHandler) ShowEditUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var user *models.User
-	if h.fbClient != nil {
+	if h.store != nil {
 		var err error
-		user, err = h.fbClient.GetUser(userID)
+		user, err = h.store.Users(r.Context()).GetUser(userID)
 		if err != nil {
 			log.Printf("Błąd pobierania użytkownika: %v", err)
 			http.Error(w, "Nie znaleziono użytkownika", http.StatusNotFound)
@@ -348,8 +415,139 @@ func (h *StaffHandler) ShowEditUser(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	data := NewTemplateData(session)
+	data := h.userEditData(r, user)
+	data["ReadingRoomIssued"] = r.URL.Query().Get("reading_room") == "1"
+
+	if err := h.userEditTemplate.Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// userEditData zwraca dane strony edycji czytelnika wraz z książkami udostępnionymi mu na miejscu
+func (h *StaffHandler) userEditData(r *http.Request, user *models.User) TemplateData {
+	data := NewPageData(r)
 	data["EditUser"] = user
+	data["Roles"] = models.Roles
+	data["ConsentTypes"] = models.ConsentTypes
+	data["TrashRetentionDays"] = models.TrashRetentionDays
+
+	if h.store != nil && user != nil {
+		loans, err := h.store.Loans(r.Context()).GetUserActiveLoans(user.ID)
+		if err != nil {
+			log.Printf("Błąd pobierania wypożyczeń czytelnika %s: %v", user.ID, err)
+		}
+		var readingRoom []*models.Loan
+		for _, loan := range loans {
+			if loan.IsReadingRoom() {
+				readingRoom = append(readingRoom, loan)
+			}
+		}
+		data["ReadingRoomLoans"] = readingRoom
+
+		if user.Role == models.RoleReader && h.fbClient != nil {
+			acceptances, err := h.fbClient.GetUserRegulationsAcceptances(user.ID)
+			if err != nil {
+				log.Printf("Błąd pobierania akceptacji regulaminu przez %s: %v", user.ID, err)
+			}
+			data["RegulationsAcceptances"] = acceptances
+			if current, err := h.fbClient.GetCurrentRegulations(); err == nil {
+				data["CurrentRegulations"] = current
+			}
+		}
+	}
+	return data
+}
+
+// IssueReadingRoomLoan udostępnia czytelnikowi książkę na miejscu (POST /staff/users/{id}/reading-room).
+// Książka jest wskazywana kodem z etykiety lub ISBN i wraca automatycznie na koniec dnia.
+func (h *StaffHandler) IssueReadingRoomLoan(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if h.userEditTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+	if h.store == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return
+	}
+
+	userID := chi.URLParam(r, "id")
+	user, err := h.store.Users(r.Context()).GetUser(userID)
+	if err != nil {
+		log.Printf("Błąd pobierania użytkownika: %v", err)
+		http.Error(w, "Nie znaleziono użytkownika", http.StatusNotFound)
+		return
+	}
+
+	var issueErr string
+	book, err := h.store.Books(r.Context()).FindBookByCode(r.FormValue("code"))
+	switch {
+	case err != nil:
+		log.Printf("Błąd wyszukiwania książki %s: %v", r.FormValue("code"), err)
+		issueErr = "błąd wyszukiwania książki"
+	case book == nil:
+		issueErr = "nie znaleziono książki o tym kodzie"
+	default:
+		if _, err := h.store.Loans(r.Context()).CreateReadingRoomLoan(book, user); err != nil {
+			issueErr = err.Error()
+		}
+	}
+	if issueErr != "" {
+		data := h.userEditData(r, user)
+		data["ReadingRoomError"] = "Nie udało się udostępnić książki: " + issueErr
+		w.WriteHeader(http.StatusBadRequest)
+		if err := h.userEditTemplate.Execute(w, data); err != nil {
+			log.Printf("Błąd renderowania edycji użytkownika: %v", err)
+		}
+		return
+	}
+
+	log.Printf("Udostępnienie na miejscu: książka %s dla czytelnika %s, wydał %s", book.ID, userID, session.User.Email)
+	basepath.Redirect(w, r, "/staff/users/"+userID+"/edit?reading_room=1", http.StatusSeeOther)
+}
+
+// VerifyUserPIN sprawdza PIN podany przez czytelnika przez telefon (POST /staff/users/{id}/verify-pin)
+func (h *StaffHandler) VerifyUserPIN(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if h.userEditTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return
+	}
+
+	userID := chi.URLParam(r, "id")
+	user, err := h.fbClient.Traced(r.Context()).GetUser(userID)
+	if err != nil {
+		log.Printf("Błąd pobierania użytkownika: %v", err)
+		http.Error(w, "Nie znaleziono użytkownika", http.StatusNotFound)
+		return
+	}
+
+	verified, err := h.fbClient.Traced(r.Context()).VerifyUserPIN(userID, r.FormValue("pin"))
+	if err != nil {
+		log.Printf("Błąd weryfikacji PIN-u: %v", err)
+		http.Error(w, "Błąd weryfikacji PIN-u", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Weryfikacja PIN-u czytelnika %s przez %s: %t", userID, session.User.Email, verified)
+
+	data := h.userEditData(r, user)
+	data["PINChecked"] = true
+	data["PINVerified"] = verified
 
 	if err := h.userEditTemplate.Execute(w, data); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -357,6 +555,63 @@ func (h *StaffHandler) ShowEditUser(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// RecordFinePayment przyjmuje wpłatę kary przy ladzie (POST /staff/users/{id}/fine-payments)
+// i przekierowuje do pokwitowania do wydruku
+func (h *StaffHandler) RecordFinePayment(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if h.userEditTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return
+	}
+
+	userID := chi.URLParam(r, "id")
+	payment := &models.FinePayment{
+		UserID:        userID,
+		Method:        models.PaymentMethod(r.FormValue("method")),
+		ReceiptNumber: r.FormValue("receipt_number"),
+		RecordedBy:    session.User.Email,
+	}
+
+	var paymentErr string
+	amount, err := models.ParseMoney(r.FormValue("amount"))
+	if err != nil {
+		paymentErr = "nieprawidłowa kwota"
+	} else {
+		payment.Amount = amount
+		if err := h.fbClient.Traced(r.Context()).RecordFinePayment(payment); err != nil {
+			paymentErr = err.Error()
+		}
+	}
+	if paymentErr != "" {
+		user, getErr := h.fbClient.Traced(r.Context()).GetUser(userID)
+		if getErr != nil {
+			log.Printf("Błąd pobierania użytkownika: %v", getErr)
+			http.Error(w, "Nie znaleziono użytkownika", http.StatusNotFound)
+			return
+		}
+
+		data := h.userEditData(r, user)
+		data["PaymentError"] = "Nie udało się przyjąć wpłaty: " + paymentErr
+		w.WriteHeader(http.StatusBadRequest)
+		if err := h.userEditTemplate.Execute(w, data); err != nil {
+			log.Printf("Błąd renderowania edycji użytkownika: %v", err)
+		}
+		return
+	}
+
+	log.Printf("Wpłata kary %s: kwota %s od czytelnika %s (%s), przyjął %s", payment.ID, payment.Amount, userID, payment.Method, session.User.Email)
+	basepath.Redirect(w, r, "/staff/fine-payments/"+payment.ID+"/receipt", http.StatusSeeOther)
+}
+
 // UpdateUser aktualizuje dane użytkownika
 func (h *StaffHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	userID := chi.URLParam(r, "id")
@@ -379,30 +634,59 @@ func (h *StaffHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 
 	isActive := r.FormValue("is_active") == "true"
 
-	if h.fbClient != nil {
+	// Pole roli jest zablokowane przy edycji własnego konta (administrator nie odbierze sobie dostępu)
+	role := models.UserRole(r.FormValue("role"))
+	if role != "" && !slices.Contains(models.Roles, role) {
+		http.Error(w, "Nieprawidłowa rola", http.StatusBadRequest)
+		return
+	}
+
+	if h.store != nil {
 		// Pobierz aktualnego użytkownika
-		user, err := h.fbClient.GetUser(userID)
+		user, err := h.store.Users(r.Context()).GetUser(userID)
 		if err != nil {
 			log.Printf("Błąd pobierania użytkownika: %v", err)
 			http.Error(w, "Nie znaleziono użytkownika", http.StatusNotFound)
 			return
 		}
 
+		roleChanged := role != "" && role != user.Role
+		if roleChanged {
+			if sess := middleware.GetSessionFromContext(r.Context()); sess != nil && sess.UserID == userID {
+				http.Error(w, "Nie można zmienić roli własnego konta", http.StatusBadRequest)
+				return
+			}
+		}
+
 		// Zaktualizuj tylko edytowalne pola
 		user.MaxLoans = maxLoans
 		user.IsActive = isActive
+		if roleChanged {
+			user.Role = role
+		}
 		user.UpdatedAt = time.Now()
 
+		audit, err := h.fbClient.Traced(r.Context()).BeginAudit(firebase.AuditDoc{Collection: firebase.UsersCollection, ID: userID})
+		if err != nil {
+			log.Printf("Błąd dziennika zmian przed edycją użytkownika %s: %v", userID, err)
+		}
+
 		// Zapisz zmiany
-		if err := h.fbClient.UpdateUser(userID, user); err != nil {
+		if err := h.store.Users(r.Context()).UpdateUser(userID, user); err != nil {
 			log.Printf("Błąd aktualizacji użytkownika: %v", err)
 			http.Error(w, "Błąd zapisywania zmian", http.StatusInternalServerError)
 			return
 		}
+		recordAudit(r, audit, models.AuditUserEdit, userID, user.FullName()+" ("+user.Email+")")
+
+		// Sesja trzyma rolę z chwili logowania - nowa rola obowiązuje od ponownego zalogowania
+		if roleChanged {
+			session.GetManager().DeleteUserSessions(userID)
+		}
 	}
 
 	// Przekieruj z powrotem do listy użytkowników
-	http.Redirect(w, r, "/staff/users", http.StatusSeeOther)
+	basepath.Redirect(w, r, "/staff/users", http.StatusSeeOther)
 }
 
 // ReturnLoan obsługuje zwrot książki
@@ -413,18 +697,69 @@ func (h *StaffHandler) ReturnLoan(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if h.fbClient != nil {
-		if err := h.fbClient.ReturnLoan(loanID); err != nil {
+	if h.store != nil {
+		audit, err := h.fbClient.Traced(r.Context()).BeginReturnAudit(loanID)
+		if err != nil {
+			log.Printf("Błąd dziennika zmian przed zwrotem %s: %v", loanID, err)
+		}
+
+		result, err := h.store.Loans(r.Context()).ReturnLoan(loanID)
+		if err != nil {
 			log.Printf("Błąd zwrotu książki: %v", err)
 			http.Error(w, "Błąd zwrotu książki", http.StatusInternalServerError)
 			return
 		}
+		recordStaffActivity(h.fbClient, r, models.StaffActionReturn)
+		recordAudit(r, audit, models.AuditReturn, loanID, returnAuditLabel(result.Loan))
 	}
 
 	// Zwróć pustą odpowiedź (wiersz zostanie usunięty przez htmx)
 	w.WriteHeader(http.StatusOK)
 }
 
+// loanRisks ocenia ryzyko spóźnionego zwrotu trwających wypożyczeń z listy. Historię
+// czytelników czyta jednym zapytaniem o wszystkie wypożyczenia, tylko gdy lista ma
+// wypożyczenia do oceny.
+func (h *StaffHandler) loanRisks(r *http.Request, loans []*models.Loan) map[string]returnrisk.Assessment {
+	if h.store == nil {
+		return nil
+	}
+	pending := false
+	for _, loan := range loans {
+		if loan.IsBorrowed() && !loan.IsOverdue() {
+			pending = true
+			break
+		}
+	}
+	if !pending {
+		return nil
+	}
+
+	history, err := h.store.Loans(r.Context()).ListLoans()
+	if err != nil {
+		log.Printf("Błąd pobierania historii wypożyczeń do oceny ryzyka: %v", err)
+		return nil
+	}
+	return returnrisk.Default.AssessLoans(loans, history, time.Now())
+}
+
+// RemindLoan wysyła czytelnikowi przypomnienie o terminie zwrotu przed nocnym
+// przypomnieniem (POST /staff/loans/{id}/remind, z listy wypożyczeń zagrożonych spóźnieniem)
+func (h *StaffHandler) RemindLoan(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	if _, err := h.fbClient.Traced(r.Context()).RemindLoan(chi.URLParam(r, "id")); err != nil {
+		log.Printf("Błąd przypomnienia o zwrocie: %v", err)
+		http.Error(w, "Nie udało się wysłać przypomnienia", http.StatusBadRequest)
+		return
+	}
+
+	w.Write([]byte(`<span class="text-xs text-green-700">Przypomnienie wysłane</span>`))
+}
+
 // renderUsersTable renderuje tylko tabelę użytkowników (dla htmx)
 func (h *StaffHandler) renderUsersTable(w http.ResponseWriter, users []*models.User) {
 	if len(users) == 0 {
@@ -449,11 +784,13 @@ func (h *StaffHandler) renderUsersTable(w http.ResponseWriter, users []*models.U
 
 	for _, user := range users {
 		roleClass := "bg-blue-100 text-blue-800"
-		roleText := "Czytelnik"
-		if user.Role == models.RoleAdmin {
+		switch user.Role {
+		case models.RoleAdmin:
 			roleClass = "bg-purple-100 text-purple-800"
-			roleText = "Administrator"
+		case models.RoleLibrarian:
+			roleClass = "bg-green-100 text-green-800"
 		}
+		roleText := user.Role.Label()
 
 		statusClass := "bg-green-100 text-green-800"
 		statusText := "Aktywny"
@@ -494,7 +831,7 @@ func (h *StaffHandler) renderUsersTable(w http.ResponseWriter, users []*models.U
 				</span>
 			</td>
 			<td class="px-6 py-4 whitespace-nowrap text-sm font-medium">
-				<a href="/staff/users/` + user.ID + `/edit" class="text-blue-600 hover:text-blue-900 mr-4">Edytuj</a>
+				<a href="` + basepath.URL("/staff/users/"+user.ID+"/edit") + `" class="text-blue-600 hover:text-blue-900 mr-4">Edytuj</a>
 			</td>
 		</tr>`
 	}
@@ -506,7 +843,7 @@ func (h *StaffHandler) renderUsersTable(w http.ResponseWriter, users []*models.U
 func (h *StaffHandler) ShowReports(w http.ResponseWriter, r *http.Request) {
 	session := middleware.GetSessionFromContext(r.Context())
 	if session == nil {
-		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
 		return
 	}
 
@@ -515,7 +852,61 @@ func (h *StaffHandler) ShowReports(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	data := NewTemplateData(session)
+	// Okres statystyk w dniach
+	days := 30
+	switch r.URL.Query().Get("days") {
+	case "7":
+		days = 7
+	case "90":
+		days = 90
+	}
+
+	data := NewPageData(r)
+	data["Days"] = days
+
+	if h.fbClient != nil {
+		since := time.Now().AddDate(0, 0, -days+1).Format("2006-01-02")
+
+		topSearches, err := h.fbClient.Traced(r.Context()).GetTopAnalytics(models.AnalyticsSearch, since, 10)
+		if err != nil {
+			log.Printf("Błąd pobierania statystyk wyszukiwań: %v", err)
+			data["Error"] = "Błąd pobierania statystyk"
+		}
+		zeroResults, err := h.fbClient.Traced(r.Context()).GetTopAnalytics(models.AnalyticsZeroResultSearch, since, 10)
+		if err != nil {
+			log.Printf("Błąd pobierania statystyk wyszukiwań bez wyników: %v", err)
+			data["Error"] = "Błąd pobierania statystyk"
+		}
+		topViewed, err := h.fbClient.Traced(r.Context()).GetTopAnalytics(models.AnalyticsBookView, since, 10)
+		if err != nil {
+			log.Printf("Błąd pobierania statystyk wyświetleń: %v", err)
+			data["Error"] = "Błąd pobierania statystyk"
+		}
+
+		topShared, err := h.fbClient.Traced(r.Context()).GetTopAnalytics(models.AnalyticsBookShare, since, 10)
+		if err != nil {
+			log.Printf("Błąd pobierania statystyk udostępnień: %v", err)
+			data["Error"] = "Błąd pobierania statystyk"
+		}
+		shareVisits, err := h.fbClient.Traced(r.Context()).GetTopAnalytics(models.AnalyticsShareVisit, since, 10)
+		if err != nil {
+			log.Printf("Błąd pobierania statystyk wejść z udostępnień: %v", err)
+			data["Error"] = "Błąd pobierania statystyk"
+		}
+		shareChannels, err := h.fbClient.Traced(r.Context()).GetTopAnalytics(models.AnalyticsShareChannel, since, 10)
+		if err != nil {
+			log.Printf("Błąd pobierania statystyk serwisów udostępniania: %v", err)
+			data["Error"] = "Błąd pobierania statystyk"
+		}
+
+		data["TopSearches"] = topSearches
+		data["ZeroResultSearches"] = zeroResults
+		data["TopViewed"] = topViewed
+		data["TopShared"] = topShared
+		data["TopShareVisits"] = shareVisits
+		data["ShareChannels"] = shareChannels
+	}
+
 	if err := h.reportsTemplate.Execute(w, data); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -526,7 +917,7 @@ func (h *StaffHandler) ShowReports(w http.ResponseWriter, r *http.Request) {
 func (h *StaffHandler) ShowPendingPickups(w http.ResponseWriter, r *http.Request) {
 	session := middleware.GetSessionFromContext(r.Context())
 	if session == nil {
-		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
 		return
 	}
 
@@ -535,26 +926,39 @@ func (h *StaffHandler) ShowPendingPickups(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Pobierz wszystkie wypożyczenia oczekujące na odbiór
-	allLoans, err := h.fbClient.ListLoans()
+	settings := librarySettings(h.fbClient)
+
+	loans, err := h.store.Loans(r.Context()).GetPendingPickupLoans()
 	if err != nil {
 		log.Printf("Błąd pobierania wypożyczeń: %v", err)
 		http.Error(w, "Błąd pobierania danych", http.StatusInternalServerError)
 		return
 	}
+	pendingPickups := h.pendingPickupsDisplay(r, loans, settings)
 
-	var pendingPickups []*models.Loan
-	for _, loan := range allLoans {
-		if loan.Status == models.LoanStatusPendingPickup {
-			pendingPickups = append(pendingPickups, loan)
-		}
+	// Po potwierdzeniu odbioru htmx odświeża tylko listę oczekujących odbiorów
+	if r.Header.Get("HX-Request") == "true" {
+		h.renderPickupFragment(w, "pending-list", map[string]interface{}{"PendingPickups": pendingPickups})
+		return
 	}
 
+	// Gotowe rezerwacje posortowane po miejscu odbioru - lista książek do odłożenia na półki
+	readyReservations, err := h.store.Reservations(r.Context()).GetReadyReservations()
+	if err != nil {
+		log.Printf("Błąd pobierania gotowych rezerwacji: %v", err)
+	}
+	sort.SliceStable(readyReservations, func(i, j int) bool {
+		return readyReservations[i].PickupLocation < readyReservations[j].PickupLocation
+	})
+
 	data := map[string]interface{}{
-		"User":           session.User,
-		"PendingPickups": pendingPickups,
-		"Success":        r.URL.Query().Get("success"),
-		"Error":          r.URL.Query().Get("error"),
+		"User":              session.User,
+		"PickupCode":        settings.PickupCode(),
+		"PendingPickups":    pendingPickups,
+		"ReadyReservations": readyReservations,
+		"Code":              r.URL.Query().Get("code"), // Kod wybrany w szybkim wyszukiwaniu
+		"Success":           r.URL.Query().Get("success"),
+		"Error":             r.URL.Query().Get("error"),
 	}
 
 	if err := h.pendingPickupsTemplate.Execute(w, data); err != nil {
@@ -564,47 +968,114 @@ func (h *StaffHandler) ShowPendingPickups(w http.ResponseWriter, r *http.Request
 	}
 }
 
+// pendingPickupsDisplay uzupełnia wypożyczenia o półkę książki i kontakt do czytelnika.
+// Książki i czytelnicy są pobierani zbiorczo; gdy się nie uda, lista pokazuje same wypożyczenia.
+func (h *StaffHandler) pendingPickupsDisplay(r *http.Request, loans []*models.Loan, settings *models.Settings) []PendingPickupDisplay {
+	bookIDs := make([]string, 0, len(loans))
+	userIDs := make([]string, 0, len(loans))
+	for _, loan := range loans {
+		bookIDs = append(bookIDs, loan.BookID)
+		userIDs = append(userIDs, loan.UserID)
+	}
+
+	books, err := h.store.Books(r.Context()).GetBooksByIDs(bookIDs)
+	if err != nil {
+		log.Printf("Błąd pobierania książek do odbioru: %v", err)
+	}
+	users, err := h.store.Users(r.Context()).GetUsersByIDs(userIDs)
+	if err != nil {
+		log.Printf("Błąd pobierania czytelników do odbioru: %v", err)
+	}
+
+	display := make([]PendingPickupDisplay, 0, len(loans))
+	for _, loan := range loans {
+		item := PendingPickupDisplay{
+			Loan:           loan,
+			PickupDeadline: loan.LoanDate.AddDate(0, 0, settings.PickupDays),
+		}
+		if book, ok := books[loan.BookID]; ok {
+			item.ShelfLocation = book.ShelfLocation
+		}
+		if user, ok := users[loan.UserID]; ok {
+			item.ReaderEmail = user.Email
+			item.ReaderPhone = user.Phone
+		}
+		display = append(display, item)
+	}
+	return display
+}
+
+// LookupPickup pokazuje wypożyczenie pasujące do wpisanego lub zeskanowanego kodu,
+// żeby pracownik sprawdził czytelnika i książkę przed wydaniem (GET /staff/pending-pickups/lookup)
+func (h *StaffHandler) LookupPickup(w http.ResponseWriter, r *http.Request) {
+	pickupCode, ok := models.NormalizePickupCode(r.FormValue("pickup_code"))
+	data := map[string]interface{}{"Code": pickupCode}
+
+	if !ok {
+		data["Error"] = "Niepoprawny kod odbioru"
+		h.renderPickupFragment(w, "pickup-match", data)
+		return
+	}
+
+	loan, err := h.store.Loans(r.Context()).GetLoanByPickupCode(pickupCode)
+	switch {
+	case err != nil:
+		log.Printf("Błąd wyszukiwania kodu odbioru %s: %v", pickupCode, err)
+		data["Error"] = "Błąd wyszukiwania wypożyczenia"
+	case loan == nil:
+		data["Error"] = "Nie znaleziono wypożyczenia z kodem " + pickupCode
+	default:
+		data["Loan"] = loan
+		if user, err := h.store.Users(r.Context()).GetUser(loan.UserID); err == nil {
+			data["Reader"] = user
+		} else {
+			log.Printf("Błąd pobierania czytelnika %s: %v", loan.UserID, err)
+		}
+		if book, err := h.store.Books(r.Context()).GetBook(loan.BookID); err == nil {
+			data["ShelfLocation"] = book.ShelfLocation
+		} else {
+			log.Printf("Błąd pobierania książki %s: %v", loan.BookID, err)
+		}
+	}
+
+	h.renderPickupFragment(w, "pickup-match", data)
+}
+
 // ConfirmPickup potwierdza odbiór książki
 func (h *StaffHandler) ConfirmPickup(w http.ResponseWriter, r *http.Request) {
 	session := middleware.GetSessionFromContext(r.Context())
 	if session == nil {
-		w.Header().Set("Content-Type", "text/html")
-		w.Write([]byte(`<div class="bg-red-100 border border-red-400 text-red-700 px-4 py-3 rounded mb-4">Musisz być zalogowany</div>`))
-		return
-	}
-
-	if err := r.ParseForm(); err != nil {
-		w.Header().Set("Content-Type", "text/html")
-		w.Write([]byte(`<div class="bg-red-100 border border-red-400 text-red-700 px-4 py-3 rounded mb-4">Błąd przetwarzania formularza</div>`))
+		h.renderPickupFragment(w, "pickup-result", map[string]interface{}{"Error": "Musisz być zalogowany"})
 		return
 	}
 
-	pickupCode := strings.ToUpper(strings.TrimSpace(r.FormValue("pickup_code")))
-	if pickupCode == "" {
-		w.Header().Set("Content-Type", "text/html")
-		w.Write([]byte(`<div class="bg-red-100 border border-red-400 text-red-700 px-4 py-3 rounded mb-4">Kod odbioru nie może być pusty</div>`))
+	pickupCode, ok := models.NormalizePickupCode(r.FormValue("pickup_code"))
+	if !ok {
+		h.renderPickupFragment(w, "pickup-result", map[string]interface{}{"Error": "Niepoprawny kod odbioru"})
 		return
 	}
 
 	// Potwierdź odbiór
-	if err := h.fbClient.ConfirmPickup(pickupCode); err != nil {
+	if err := h.store.Loans(r.Context()).ConfirmPickup(pickupCode); err != nil {
 		log.Printf("Błąd potwierdzania odbioru: %v", err)
-		w.Header().Set("Content-Type", "text/html")
-		w.Write([]byte(`<div class="bg-red-100 border border-red-400 text-red-700 px-4 py-3 rounded mb-4">` + err.Error() + `</div>`))
+		h.renderPickupFragment(w, "pickup-result", map[string]interface{}{"Error": err.Error()})
 		return
 	}
 
 	log.Printf("Pracownik %s potwierdził odbiór z kodem %s", session.User.Email, pickupCode)
+	recordStaffActivity(h.fbClient, r, models.StaffActionPickup)
 
-	// Zwróć komunikat sukcesu i odśwież listę
-	w.Header().Set("Content-Type", "text/html")
-	w.Header().Set("HX-Trigger", "reload-pickups")
-	w.Write([]byte(`<div class="bg-green-100 border border-green-400 text-green-700 px-4 py-3 rounded mb-4">
-		✓ Odbiór potwierdzony pomyślnie! Kod: ` + pickupCode + `
-		<script>
-			document.getElementById('pickup_code').value = '';
-			document.getElementById('pickup_code').focus();
-			setTimeout(() => window.location.reload(), 1500);
-		</script>
-	</div>`))
+	// Zdarzenie odświeża listę oczekujących odbiorów i przywraca fokus na pole kodu
+	w.Header().Set("HX-Trigger", "pickup-confirmed")
+	h.renderPickupFragment(w, "pickup-result", map[string]interface{}{"Code": pickupCode})
+}
+
+func (h *StaffHandler) renderPickupFragment(w http.ResponseWriter, name string, data map[string]interface{}) {
+	if h.pendingPickupsTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+	if err := h.pendingPickupsTemplate.ExecuteTemplate(w, name, data); err != nil {
+		log.Printf("Błąd renderowania fragmentu %s: %v", name, err)
+	}
 }
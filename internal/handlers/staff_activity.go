@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+)
+
+// StaffActivityHandler obsługuje raport czynności personelu przy ladzie
+type StaffActivityHandler struct {
+	activityTemplate *template.Template
+	fbClient         *firebase.Client
+}
+
+// StaffActivityRow to czynności jednego pracownika w jednym dniu (lub w całym okresie)
+type StaffActivityRow struct {
+	Day         string
+	StaffName   string
+	Returns     int
+	Pickups     int
+	CatalogEdit int
+}
+
+// Total zwraca łączną liczbę czynności
+func (r *StaffActivityRow) Total() int {
+	return r.Returns + r.Pickups + r.CatalogEdit
+}
+
+func (r *StaffActivityRow) add(action models.StaffAction, count int) {
+	switch action {
+	case models.StaffActionReturn:
+		r.Returns += count
+	case models.StaffActionPickup:
+		r.Pickups += count
+	case models.StaffActionCatalogEdit:
+		r.CatalogEdit += count
+	}
+}
+
+// NewStaffActivityHandler tworzy handler raportu czynności personelu
+func NewStaffActivityHandler(fbClient *firebase.Client) *StaffActivityHandler {
+	activityTmpl, err := template.New("staff_activity.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/staff_activity.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/staff_activity.html: %v", err)
+	}
+
+	return &StaffActivityHandler{
+		activityTemplate: activityTmpl,
+		fbClient:         fbClient,
+	}
+}
+
+// ShowReport wyświetla czynności personelu dzień po dniu (GET /staff/reports/staff-activity?days=7)
+func (h *StaffActivityHandler) ShowReport(w http.ResponseWriter, r *http.Request) {
+	if h.activityTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return
+	}
+
+	days := 7
+	if r.URL.Query().Get("days") == "30" {
+		days = 30
+	}
+
+	now := time.Now()
+	counters, err := h.fbClient.Traced(r.Context()).GetStaffActivity(now.AddDate(0, 0, -days+1).Format("2006-01-02"), now.Format("2006-01-02"))
+	if err != nil {
+		log.Printf("Błąd pobierania czynności personelu: %v", err)
+		http.Error(w, "Błąd pobierania czynności personelu", http.StatusInternalServerError)
+		return
+	}
+
+	daily, totals := staffActivityRows(counters)
+
+	data := NewPageData(r)
+	data["Days"] = days
+	data["Daily"] = daily
+	data["Totals"] = totals
+
+	if err := h.activityTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania raportu czynności personelu: %v", err)
+	}
+}
+
+// staffActivityRows zestawia liczniki w wiersze dzienne (najnowsze dni pierwsze)
+// oraz sumy pracowników za cały okres (najbardziej aktywni pierwsi)
+func staffActivityRows(counters []*models.StaffActivityCounter) ([]*StaffActivityRow, []*StaffActivityRow) {
+	daily := make(map[string]*StaffActivityRow)
+	totals := make(map[string]*StaffActivityRow)
+	for _, c := range counters {
+		key := c.Day + "|" + c.StaffID
+		if daily[key] == nil {
+			daily[key] = &StaffActivityRow{Day: c.Day, StaffName: c.StaffName}
+		}
+		daily[key].add(c.Action, c.Count)
+
+		if totals[c.StaffID] == nil {
+			totals[c.StaffID] = &StaffActivityRow{StaffName: c.StaffName}
+		}
+		totals[c.StaffID].add(c.Action, c.Count)
+	}
+
+	dailyRows := make([]*StaffActivityRow, 0, len(daily))
+	for _, row := range daily {
+		dailyRows = append(dailyRows, row)
+	}
+	sort.Slice(dailyRows, func(i, j int) bool {
+		if dailyRows[i].Day != dailyRows[j].Day {
+			return dailyRows[i].Day > dailyRows[j].Day
+		}
+		return dailyRows[i].StaffName < dailyRows[j].StaffName
+	})
+
+	totalRows := make([]*StaffActivityRow, 0, len(totals))
+	for _, row := range totals {
+		totalRows = append(totalRows, row)
+	}
+	sort.Slice(totalRows, func(i, j int) bool {
+		return totalRows[i].Total() > totalRows[j].Total()
+	})
+
+	return dailyRows, totalRows
+}
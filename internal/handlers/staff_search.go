@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+	"library-management-system/internal/search"
+)
+
+// staffSearchLimit to maksymalna liczba wyników w każdej grupie
+const staffSearchLimit = 5
+
+var (
+	cardNumberPattern = regexp.MustCompile(`^[0-9]{12}$`)
+	shortCodePattern  = regexp.MustCompile(`^[2-9a-z]{6}$`)
+	isbnPattern       = regexp.MustCompile(`^[0-9][0-9-]{8,15}[0-9Xx]$`)
+)
+
+// StaffSearchHandler obsługuje szybkie wyszukiwanie personelu (Ctrl+K): jedno pole
+// znajduje czytelników, książki, wypożyczenia i kody odbioru
+type StaffSearchHandler struct {
+	searchTemplate *template.Template
+	fbClient       *firebase.Client
+	index          *search.Index
+}
+
+// StaffSearchResult to pojedynczy wynik prowadzący do strony szczegółów
+type StaffSearchResult struct {
+	Title    string
+	Subtitle string
+	URL      string
+}
+
+// StaffSearchResults zawiera wyniki pogrupowane według typu
+type StaffSearchResults struct {
+	Query   string
+	Pickups []StaffSearchResult
+	Readers []StaffSearchResult
+	Books   []StaffSearchResult
+	Loans   []StaffSearchResult
+}
+
+// Total zwraca łączną liczbę wyników
+func (r *StaffSearchResults) Total() int {
+	return len(r.Pickups) + len(r.Readers) + len(r.Books) + len(r.Loans)
+}
+
+// NewStaffSearchHandler tworzy handler wyszukiwania personelu.
+// Indeks książek jest współdzielony z wyszukiwaniem globalnym.
+func NewStaffSearchHandler(fbClient *firebase.Client, index *search.Index) *StaffSearchHandler {
+	searchTmpl, err := template.New("search.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/search.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/search.html: %v", err)
+	}
+
+	return &StaffSearchHandler{
+		searchTemplate: searchTmpl,
+		fbClient:       fbClient,
+		index:          index,
+	}
+}
+
+// Search wyszukuje we wszystkich źródłach naraz (GET /staff/search?q=).
+// Paleta (htmx/fetch) dostaje tylko fragment z wynikami, zwykłe wejście - całą stronę.
+func (h *StaffSearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	if h.searchTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+
+	data := NewPageData(r)
+	data["Query"] = query
+	if query != "" && h.fbClient != nil {
+		data["Results"] = h.search(query)
+	}
+
+	name := "search.html"
+	if r.Header.Get("HX-Request") == "true" {
+		name = "results"
+	}
+	if err := h.searchTemplate.ExecuteTemplate(w, name, data); err != nil {
+		log.Printf("Błąd renderowania wyszukiwania personelu: %v", err)
+	}
+}
+
+// search zbiera wyniki ze wszystkich źródeł. Błąd jednego źródła jest logowany
+// i nie ukrywa wyników pozostałych.
+func (h *StaffSearchHandler) search(query string) *StaffSearchResults {
+	results := &StaffSearchResults{Query: query}
+
+	// Kod odbioru - przejście do potwierdzenia odbioru z wpisanym kodem
+	if code, ok := models.NormalizePickupCode(query); ok && !strings.ContainsAny(query, " ") {
+		loan, err := h.fbClient.GetLoanByPickupCode(code)
+		if err != nil {
+			log.Printf("Błąd wyszukiwania kodu odbioru %s: %v", code, err)
+		} else if loan != nil {
+			results.Pickups = append(results.Pickups, StaffSearchResult{
+				Title:    "Kod odbioru " + code,
+				Subtitle: loan.BookTitle + " - " + loan.UserName,
+				URL:      "/staff/pending-pickups?code=" + code,
+			})
+		}
+	}
+
+	// Numer karty - czytelnik i jego bieżące wypożyczenia
+	if cardNumberPattern.MatchString(query) {
+		user, err := h.fbClient.GetUserByCardNumber(query)
+		if err != nil {
+			log.Printf("Błąd wyszukiwania karty %s: %v", query, err)
+		} else if user != nil {
+			results.Readers = append(results.Readers, readerResult(user))
+			h.addReaderLoans(results, user)
+		}
+	}
+
+	// Kod z etykiety lub ISBN - książka i osoby, które ją wypożyczyły
+	if book := h.findBook(query); book != nil {
+		results.Books = append(results.Books, StaffSearchResult{
+			Title:    book.Title,
+			Subtitle: book.Author,
+			URL:      "/books/" + book.ID,
+		})
+		h.addBookLoans(results, book)
+	}
+
+	if len(search.Terms(query)) > 0 {
+		h.addCatalogMatches(results, query)
+	}
+	if len(results.Readers) == 0 && len([]rune(query)) >= 2 {
+		h.addReaderMatches(results, query)
+	}
+
+	return results
+}
+
+// findBook szuka książki po kodzie z etykiety, a następnie po ISBN
+func (h *StaffSearchHandler) findBook(query string) *models.Book {
+	var book *models.Book
+	var err error
+	if code := strings.ToLower(query); shortCodePattern.MatchString(code) {
+		book, err = h.fbClient.GetBookByShortCode(code)
+	}
+	if err == nil && book == nil && isbnPattern.MatchString(query) {
+		book, err = h.fbClient.GetBookByISBN(query)
+	}
+	if err != nil {
+		log.Printf("Błąd wyszukiwania książki po kodzie %s: %v", query, err)
+		return nil
+	}
+	return book
+}
+
+// addCatalogMatches dopisuje książki z indeksu wyszukiwania
+func (h *StaffSearchHandler) addCatalogMatches(results *StaffSearchResults, query string) {
+	if err := h.index.Refresh(); err != nil {
+		log.Printf("Błąd budowania indeksu wyszukiwania: %v", err)
+		return
+	}
+
+	for _, r := range h.index.Search(query).Books {
+		if len(results.Books) >= staffSearchLimit {
+			break
+		}
+		if containsResult(results.Books, r.URL) {
+			continue
+		}
+		results.Books = append(results.Books, StaffSearchResult{Title: r.Title, Subtitle: r.Subtitle, URL: r.URL})
+	}
+}
+
+// addReaderMatches dopisuje czytelników pasujących imieniem, nazwiskiem lub emailem
+func (h *StaffSearchHandler) addReaderMatches(results *StaffSearchResults, query string) {
+	users, err := h.fbClient.ListUsers()
+	if err != nil {
+		log.Printf("Błąd pobierania użytkowników: %v", err)
+		return
+	}
+
+	for _, user := range users {
+		if search.ContainsFold(user.FullName(), query) || search.ContainsFold(user.Email, query) {
+			results.Readers = append(results.Readers, readerResult(user))
+			if len(results.Readers) >= staffSearchLimit {
+				return
+			}
+		}
+	}
+}
+
+// addBookLoans dopisuje bieżące wypożyczenia książki (prowadzą do profilu czytelnika)
+func (h *StaffSearchHandler) addBookLoans(results *StaffSearchResults, book *models.Book) {
+	loans, err := h.fbClient.GetBookActiveLoans(book.ID)
+	if err != nil {
+		log.Printf("Błąd pobierania wypożyczeń książki %s: %v", book.ID, err)
+		return
+	}
+
+	for _, loan := range loans {
+		results.Loans = append(results.Loans, StaffSearchResult{
+			Title:    loan.BookTitle + " - " + loan.UserName,
+			Subtitle: loanDueLabel(loan),
+			URL:      "/staff/users/" + loan.UserID + "/edit",
+		})
+	}
+}
+
+// addReaderLoans dopisuje bieżące wypożyczenia czytelnika (prowadzą do strony książki)
+func (h *StaffSearchHandler) addReaderLoans(results *StaffSearchResults, user *models.User) {
+	loans, err := h.fbClient.GetUserActiveLoans(user.ID)
+	if err != nil {
+		log.Printf("Błąd pobierania wypożyczeń czytelnika %s: %v", user.ID, err)
+		return
+	}
+
+	for _, loan := range loans {
+		results.Loans = append(results.Loans, StaffSearchResult{
+			Title:    loan.BookTitle + " - " + user.FullName(),
+			Subtitle: loanDueLabel(loan),
+			URL:      "/books/" + loan.BookID,
+		})
+	}
+}
+
+func readerResult(user *models.User) StaffSearchResult {
+	subtitle := user.Email
+	if user.CardNumber != "" {
+		subtitle += ", karta " + user.CardNumber
+	}
+	return StaffSearchResult{
+		Title:    user.FullName(),
+		Subtitle: subtitle,
+		URL:      "/staff/users/" + user.ID + "/edit",
+	}
+}
+
+func loanDueLabel(loan *models.Loan) string {
+	if loan.Status == models.LoanStatusPendingPickup {
+		return "Oczekuje na odbiór"
+	}
+	label := "Termin zwrotu " + loan.DueDate.Format("2006-01-02")
+	if loan.IsOverdue() {
+		label += " (po terminie)"
+	}
+	return label
+}
+
+func containsResult(list []StaffSearchResult, url string) bool {
+	for _, r := range list {
+		if r.URL == url {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/middleware"
+	"library-management-system/internal/models"
+)
+
+// SuggestionsHandler obsługuje propozycje zakupu książek zgłaszane przez czytelników
+type SuggestionsHandler struct {
+	formTemplate  *template.Template
+	staffTemplate *template.Template
+	fbClient      *firebase.Client
+}
+
+// NewSuggestionsHandler tworzy nowy handler propozycji zakupu
+func NewSuggestionsHandler(fbClient *firebase.Client) *SuggestionsHandler {
+	formTmpl, err := template.New("form.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/suggestions/form.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu suggestions/form.html: %v", err)
+	}
+
+	staffTmpl, err := template.New("suggestions.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/suggestions.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/suggestions.html: %v", err)
+	}
+
+	return &SuggestionsHandler{
+		formTemplate:  formTmpl,
+		staffTemplate: staffTmpl,
+		fbClient:      fbClient,
+	}
+}
+
+// ShowForm wyświetla formularz propozycji zakupu (GET /suggestions/new?title=...)
+func (h *SuggestionsHandler) ShowForm(w http.ResponseWriter, r *http.Request) {
+	if h.formTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	data := NewPageData(r)
+	data["Title"] = r.URL.Query().Get("title")
+	data["Sent"] = r.URL.Query().Get("sent") == "1"
+
+	if err := h.formTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania formularza propozycji: %v", err)
+		http.Error(w, "Błąd renderowania strony", http.StatusInternalServerError)
+	}
+}
+
+// CreateSuggestion zapisuje propozycję zakupu (POST /suggestions)
+func (h *SuggestionsHandler) CreateSuggestion(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Błąd parsowania formularza", http.StatusBadRequest)
+		return
+	}
+
+	title := strings.TrimSpace(r.FormValue("title"))
+	if title == "" {
+		http.Error(w, "Tytuł jest wymagany", http.StatusBadRequest)
+		return
+	}
+
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return
+	}
+
+	suggestion := &models.PurchaseSuggestion{
+		UserID:   session.UserID,
+		UserName: session.User.FullName(),
+		Title:    title,
+		Author:   strings.TrimSpace(r.FormValue("author")),
+		Note:     strings.TrimSpace(r.FormValue("note")),
+	}
+
+	if err := h.fbClient.Traced(r.Context()).CreatePurchaseSuggestion(suggestion); err != nil {
+		log.Printf("Błąd zapisywania propozycji zakupu: %v", err)
+		http.Error(w, "Błąd zapisywania propozycji", http.StatusInternalServerError)
+		return
+	}
+
+	basepath.Redirect(w, r, "/suggestions/new?sent=1", http.StatusSeeOther)
+}
+
+// SuggestCopies zgłasza propozycję dokupienia egzemplarzy tytułu z katalogu, np. z raportu
+// rezerwacji (POST /staff/suggestions/books/{id})
+func (h *SuggestionsHandler) SuggestCopies(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return
+	}
+
+	book, err := h.fbClient.Traced(r.Context()).GetBook(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("Błąd pobierania książki: %v", err)
+		http.Error(w, "Książka nie została znaleziona", http.StatusNotFound)
+		return
+	}
+
+	copies, err := strconv.Atoi(r.FormValue("copies"))
+	if err != nil || copies < 1 {
+		http.Error(w, "Nieprawidłowa liczba egzemplarzy", http.StatusBadRequest)
+		return
+	}
+
+	note := "Dokupienie egzemplarzy: " + strconv.Itoa(copies)
+	if reason := strings.TrimSpace(r.FormValue("note")); reason != "" {
+		note += " (" + reason + ")"
+	}
+
+	suggestion := &models.PurchaseSuggestion{
+		UserID:   session.UserID,
+		UserName: session.User.FullName(),
+		Title:    book.Title,
+		Author:   book.Author,
+		Note:     note,
+		BookID:   book.ID,
+	}
+
+	if err := h.fbClient.Traced(r.Context()).CreatePurchaseSuggestion(suggestion); err != nil {
+		log.Printf("Błąd zapisywania propozycji zakupu: %v", err)
+		http.Error(w, "Błąd zapisywania propozycji", http.StatusInternalServerError)
+		return
+	}
+
+	basepath.Redirect(w, r, "/staff/reports/holds", http.StatusSeeOther)
+}
+
+// ListSuggestions wyświetla propozycje zakupu w panelu personelu (GET /staff/suggestions)
+func (h *SuggestionsHandler) ListSuggestions(w http.ResponseWriter, r *http.Request) {
+	if h.staffTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	data := NewPageData(r)
+
+	if h.fbClient != nil {
+		suggestions, err := h.fbClient.Traced(r.Context()).ListPurchaseSuggestions()
+		if err != nil {
+			log.Printf("Błąd pobierania propozycji zakupu: %v", err)
+			data["Error"] = "Błąd pobierania propozycji z bazy danych"
+		}
+		data["Suggestions"] = suggestions
+	}
+
+	if err := h.staffTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania propozycji zakupu: %v", err)
+		http.Error(w, "Błąd renderowania strony", http.StatusInternalServerError)
+	}
+}
+
+// UpdateStatus zmienia status propozycji zakupu (POST /staff/suggestions/{id}/status)
+func (h *SuggestionsHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	suggestionID := chi.URLParam(r, "id")
+	if suggestionID == "" {
+		http.Error(w, "Brak ID propozycji", http.StatusBadRequest)
+		return
+	}
+
+	status := models.PurchaseSuggestionStatus(r.FormValue("status"))
+	switch status {
+	case models.PurchaseSuggestionNew, models.PurchaseSuggestionOrdered, models.PurchaseSuggestionRejected:
+	default:
+		http.Error(w, "Nieprawidłowy status", http.StatusBadRequest)
+		return
+	}
+
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.fbClient.Traced(r.Context()).UpdatePurchaseSuggestionStatus(suggestionID, status); err != nil {
+		log.Printf("Błąd aktualizacji propozycji zakupu: %v", err)
+		http.Error(w, "Błąd zapisywania propozycji", http.StatusInternalServerError)
+		return
+	}
+
+	basepath.Redirect(w, r, "/staff/suggestions", http.StatusSeeOther)
+}
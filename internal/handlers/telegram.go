@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/bots/telegram"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/middleware"
+)
+
+// TelegramHandler obsługuje łączenie konta czytelnika z botem Telegrama
+type TelegramHandler struct {
+	telegramTemplate *template.Template
+	fbClient         *firebase.Client
+	bot              *telegram.Bot // nil, gdy bot jest wyłączony
+}
+
+// NewTelegramHandler tworzy handler połączenia z Telegramem
+func NewTelegramHandler(fbClient *firebase.Client, bot *telegram.Bot) *TelegramHandler {
+	telegramTmpl, err := template.New("telegram.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/user/telegram.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu user/telegram.html: %v", err)
+	}
+
+	return &TelegramHandler{
+		telegramTemplate: telegramTmpl,
+		fbClient:         fbClient,
+		bot:              bot,
+	}
+}
+
+// ShowTelegram wyświetla stan połączenia z botem (GET /user/telegram)
+func (h *TelegramHandler) ShowTelegram(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if h.telegramTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	data := NewPageData(r)
+	data["Saved"] = r.URL.Query().Get("saved")
+
+	if h.bot != nil && h.fbClient != nil {
+		data["BotUsername"] = h.bot.Username()
+		// Sesja przechowuje kopię profilu z chwili logowania, więc stan połączenia pobieramy z bazy
+		if user, err := h.fbClient.Traced(r.Context()).GetUser(session.UserID); err == nil {
+			data["Linked"] = user.TelegramChatID != 0
+		} else {
+			log.Printf("Błąd pobierania użytkownika: %v", err)
+			data["Error"] = "Błąd pobierania stanu połączenia"
+		}
+	}
+
+	if err := h.telegramTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania strony Telegrama: %v", err)
+	}
+}
+
+// Link tworzy jednorazowy kod i przekierowuje do bota, który po /start łączy czat
+// z kontem (POST /user/telegram/link)
+func (h *TelegramHandler) Link(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Nie jesteś zalogowany", http.StatusUnauthorized)
+		return
+	}
+
+	if h.bot == nil || h.fbClient == nil {
+		http.Error(w, "Bot Telegrama jest wyłączony", http.StatusServiceUnavailable)
+		return
+	}
+
+	code, err := h.fbClient.Traced(r.Context()).CreateTelegramLinkCode(session.UserID)
+	if err != nil {
+		log.Printf("Błąd tworzenia kodu Telegrama: %v", err)
+		http.Error(w, "Błąd tworzenia kodu", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "https://t.me/"+h.bot.Username()+"?start="+code, http.StatusSeeOther)
+}
+
+// Unlink odłącza czat Telegrama od konta (POST /user/telegram/unlink)
+func (h *TelegramHandler) Unlink(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Nie jesteś zalogowany", http.StatusUnauthorized)
+		return
+	}
+
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := h.fbClient.Traced(r.Context()).UnlinkTelegram(session.UserID); err != nil {
+		log.Printf("Błąd odłączania Telegrama: %v", err)
+		http.Error(w, "Błąd odłączania Telegrama", http.StatusInternalServerError)
+		return
+	}
+
+	basepath.Redirect(w, r, "/user/telegram?saved=unlinked", http.StatusSeeOther)
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+	"library-management-system/internal/tenant"
+)
+
+// TenantsHandler obsługuje konsolę sieci bibliotek. Jest dostępny tylko
+// w bibliotece głównej - jej administratorzy zarządzają całą siecią.
+type TenantsHandler struct {
+	tenantsTemplate *template.Template
+	fbClient        *firebase.Client
+	router          *tenant.Router
+}
+
+// NewTenantsHandler tworzy handler konsoli sieci bibliotek
+func NewTenantsHandler(fbClient *firebase.Client, router *tenant.Router) *TenantsHandler {
+	tenantsTmpl, err := template.New("tenants.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/tenants.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/tenants.html: %v", err)
+	}
+
+	return &TenantsHandler{
+		tenantsTemplate: tenantsTmpl,
+		fbClient:        fbClient,
+		router:          router,
+	}
+}
+
+// ListTenants wyświetla biblioteki sieci (GET /staff/tenants)
+func (h *TenantsHandler) ListTenants(w http.ResponseWriter, r *http.Request) {
+	tenants, err := h.fbClient.Traced(r.Context()).ListTenants()
+	if err != nil {
+		log.Printf("Błąd pobierania bibliotek sieci: %v", err)
+		http.Error(w, "Błąd pobierania bibliotek sieci", http.StatusInternalServerError)
+		return
+	}
+
+	data := NewPageData(r)
+	data["Tenants"] = tenants
+	data["Saved"] = r.URL.Query().Get("saved") == "1"
+	h.render(w, data)
+}
+
+// SaveTenant tworzy lub aktualizuje bibliotekę sieci (POST /staff/tenants)
+func (h *TenantsHandler) SaveTenant(w http.ResponseWriter, r *http.Request) {
+	tenants, err := h.fbClient.Traced(r.Context()).ListTenants()
+	if err != nil {
+		log.Printf("Błąd pobierania bibliotek sieci: %v", err)
+		http.Error(w, "Błąd pobierania bibliotek sieci", http.StatusInternalServerError)
+		return
+	}
+
+	t := &models.Tenant{
+		ID:        strings.ToLower(strings.TrimSpace(r.FormValue("id"))),
+		Name:      strings.TrimSpace(r.FormValue("name")),
+		Hostnames: parseHostnames(r.FormValue("hostnames")),
+		Active:    r.FormValue("active") == "on",
+	}
+
+	err = checkHostnames(t, tenants)
+	if err == nil {
+		for _, existing := range tenants {
+			if existing.ID == t.ID {
+				t.CreatedAt = existing.CreatedAt
+			}
+		}
+		err = h.fbClient.Traced(r.Context()).SaveTenant(t)
+	}
+	if err != nil {
+		data := NewPageData(r)
+		data["Tenants"] = tenants
+		data["Error"] = "Nie udało się zapisać biblioteki: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		h.render(w, data)
+		return
+	}
+
+	// Pozostałe instancje serwera zobaczą zmianę przy okresowym odświeżeniu
+	if err := h.router.Reload(); err != nil {
+		log.Printf("Błąd odświeżania bibliotek sieci: %v", err)
+	}
+
+	basepath.Redirect(w, r, "/staff/tenants?saved=1", http.StatusSeeOther)
+}
+
+func (h *TenantsHandler) render(w http.ResponseWriter, data TemplateData) {
+	if h.tenantsTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+	if err := h.tenantsTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania konsoli sieci: %v", err)
+	}
+}
+
+// parseHostnames dzieli listę domen rozdzieloną przecinkami lub spacjami
+func parseHostnames(value string) []string {
+	var hostnames []string
+	for _, host := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\n' || r == '\r' || r == '\t'
+	}) {
+		hostnames = append(hostnames, strings.ToLower(host))
+	}
+	return hostnames
+}
+
+// checkHostnames sprawdza, czy domeny nie są już przypisane do innej biblioteki
+func checkHostnames(t *models.Tenant, tenants []*models.Tenant) error {
+	for _, other := range tenants {
+		if other.ID == t.ID {
+			continue
+		}
+		for _, host := range other.Hostnames {
+			for _, wanted := range t.Hostnames {
+				if host == wanted {
+					return fmt.Errorf("domena %s jest już przypisana do biblioteki %s", host, other.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
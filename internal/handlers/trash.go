@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/middleware"
+	"library-management-system/internal/models"
+	"library-management-system/internal/session"
+)
+
+// ShowTrash wyświetla usunięte konta i wypożyczenia, które można jeszcze przywrócić (GET /staff/trash)
+func (h *StaffHandler) ShowTrash(w http.ResponseWriter, r *http.Request) {
+	if h.trashTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	data := NewPageData(r)
+	data["Message"] = r.URL.Query().Get("msg")
+	data["Error"] = r.URL.Query().Get("error")
+	data["RetentionDays"] = models.TrashRetentionDays
+
+	if h.fbClient == nil {
+		data["Error"] = "Baza danych niedostępna"
+	} else {
+		items, err := h.fbClient.Traced(r.Context()).ListTrash()
+		if err != nil {
+			log.Printf("Błąd pobierania kosza: %v", err)
+			data["Error"] = "Błąd pobierania kosza z bazy danych"
+		}
+		data["Items"] = items
+	}
+
+	if err := h.trashTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania kosza: %v", err)
+	}
+}
+
+// TrashUser przenosi konto czytelnika do kosza (POST /staff/users/{id}/delete)
+func (h *StaffHandler) TrashUser(w http.ResponseWriter, r *http.Request) {
+	sess := middleware.GetSessionFromContext(r.Context())
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	user, err := h.fbClient.Traced(r.Context()).GetUser(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Nie znaleziono użytkownika", http.StatusNotFound)
+		return
+	}
+
+	if err := h.fbClient.Traced(r.Context()).TrashUser(user.ID, sess.User.Email); err != nil {
+		log.Printf("Błąd usuwania konta %s: %v", user.ID, err)
+		if h.userEditTemplate == nil {
+			http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+			return
+		}
+		data := h.userEditData(r, user)
+		data["DeleteError"] = "Nie udało się usunąć konta: " + err.Error()
+		w.WriteHeader(http.StatusConflict)
+		if err := h.userEditTemplate.Execute(w, data); err != nil {
+			log.Printf("Błąd renderowania edycji użytkownika: %v", err)
+		}
+		return
+	}
+
+	// Usunięty czytelnik nie powinien dalej korzystać z otwartej sesji
+	session.GetManager().DeleteUserSessions(user.ID)
+	log.Printf("Konto %s (%s) przeniesione do kosza przez %s", user.Email, user.ID, sess.User.Email)
+	redirectToTrash(w, r, "msg", "Przeniesiono do kosza konto "+user.Email)
+}
+
+// TrashLoan przenosi zwrócone wypożyczenie do kosza (POST /staff/loans/{id}/delete)
+func (h *StaffHandler) TrashLoan(w http.ResponseWriter, r *http.Request) {
+	sess := middleware.GetSessionFromContext(r.Context())
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	loanID := chi.URLParam(r, "id")
+	if err := h.fbClient.Traced(r.Context()).TrashLoan(loanID, sess.User.Email); err != nil {
+		log.Printf("Błąd usuwania wypożyczenia %s: %v", loanID, err)
+		redirectToTrash(w, r, "error", "Nie udało się usunąć wypożyczenia: "+err.Error())
+		return
+	}
+
+	log.Printf("Wypożyczenie %s przeniesione do kosza przez %s", loanID, sess.User.Email)
+	redirectToTrash(w, r, "msg", "Przeniesiono wypożyczenie do kosza")
+}
+
+// RestoreTrashItem przywraca obiekt z kosza (POST /staff/trash/{id}/restore)
+func (h *StaffHandler) RestoreTrashItem(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	sess := middleware.GetSessionFromContext(r.Context())
+	item, err := h.fbClient.Traced(r.Context()).RestoreTrashItem(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("Błąd przywracania z kosza: %v", err)
+		redirectToTrash(w, r, "error", err.Error())
+		return
+	}
+
+	log.Printf("Przywrócono z kosza %s %s przez %s", item.Kind, item.EntityID, sess.User.Email)
+	redirectToTrash(w, r, "msg", "Przywrócono: "+item.Label)
+}
+
+// PurgeTrashItem usuwa obiekt z kosza na stałe (POST /staff/trash/{id}/purge)
+func (h *StaffHandler) PurgeTrashItem(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	sess := middleware.GetSessionFromContext(r.Context())
+	item, err := h.fbClient.Traced(r.Context()).PurgeTrashItem(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("Błąd usuwania z kosza: %v", err)
+		redirectToTrash(w, r, "error", err.Error())
+		return
+	}
+
+	log.Printf("Usunięto na stałe %s %s przez %s", item.Kind, item.EntityID, sess.User.Email)
+	redirectToTrash(w, r, "msg", "Usunięto na stałe: "+item.Label)
+}
+
+func redirectToTrash(w http.ResponseWriter, r *http.Request, key, message string) {
+	basepath.Redirect(w, r, "/staff/trash?"+key+"="+url.QueryEscape(message), http.StatusSeeOther)
+}
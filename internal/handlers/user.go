@@ -1,22 +1,35 @@
 package handlers
 
 import (
+	"fmt"
 	"html/template"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
+	"library-management-system/internal/basepath"
 	"library-management-system/internal/firebase"
 	"library-management-system/internal/middleware"
 	"library-management-system/internal/models"
+	"library-management-system/internal/repository"
+	"library-management-system/internal/search"
+	"library-management-system/internal/session"
 )
 
+// maxSavedSearches to limit zapisanych wyszukiwań na czytelnika
+const maxSavedSearches = 20
+
 type UserHandler struct {
-	dashboardTemplate    *template.Template
-	feesTemplate         *template.Template
-	historyTemplate      *template.Template
-	reservationsTemplate *template.Template
-	fbClient             *firebase.Client
+	dashboardTemplate     *template.Template
+	feesTemplate          *template.Template
+	historyTemplate       *template.Template
+	reservationsTemplate  *template.Template
+	savedSearchesTemplate *template.Template
+	notificationsTemplate *template.Template
+	pinTemplate           *template.Template
+	store                 repository.Store
+	fbClient              *firebase.Client // Funkcje spoza repozytoriów: zapisane wyszukiwania, powiadomienia, PIN, urlop
 }
 
 type LoanView struct {
@@ -27,6 +40,7 @@ type LoanView struct {
 	Status     string
 	PickupCode string
 	IsOverdue  bool
+	FineAmount models.Money // Kara naliczona do dziś za przetrzymanie
 }
 
 type FeeView struct {
@@ -53,36 +67,60 @@ type ReservationView struct {
 	ExpiryDate      time.Time
 	Status          string
 	QueuePosition   int
+	PickupLocation  string
+	EditionOnly     bool
+	LockerID        string
+	LockerCode      string
+	SuspendedAt     *time.Time // Egzemplarz ominął czytelnika z pełnym limitem wypożyczeń
 }
 
-func NewUserHandler(fbClient *firebase.Client) *UserHandler {
-	dashboardTmpl, err := template.ParseFiles("internal/templates/user/dashboard.html")
+func NewUserHandler(store repository.Store, fbClient *firebase.Client) *UserHandler {
+	dashboardTmpl, err := template.New("dashboard.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/user/dashboard.html")
 	if err != nil {
 		log.Printf("Błąd ładowania szablonu user/dashboard.html: %v", err)
 	}
 
-	historyTmpl, err := template.ParseFiles("internal/templates/user/history.html")
+	historyTmpl, err := template.New("history.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/user/history.html")
 	if err != nil {
 		log.Printf("Błąd ładowania szablonu user/history.html: %v", err)
 	}
 
-	reservationsTmpl, err := template.ParseFiles("internal/templates/user/reservations.html")
+	reservationsTmpl, err := template.New("reservations.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/user/reservations.html")
 	if err != nil {
 		log.Printf("Błąd ładowania szablonu user/reservations.html: %v", err)
 	}
 
+	savedSearchesTmpl, err := template.New("saved_searches.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/user/saved_searches.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu user/saved_searches.html: %v", err)
+	}
+
+	notificationsTmpl, err := template.New("notifications.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/user/notifications.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu user/notifications.html: %v", err)
+	}
+
+	pinTmpl, err := template.New("pin.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/user/pin.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu user/pin.html: %v", err)
+	}
+
 	return &UserHandler{
-		dashboardTemplate:    dashboardTmpl,
-		historyTemplate:      historyTmpl,
-		reservationsTemplate: reservationsTmpl,
-		fbClient:             fbClient,
+		dashboardTemplate:     dashboardTmpl,
+		historyTemplate:       historyTmpl,
+		reservationsTemplate:  reservationsTmpl,
+		savedSearchesTemplate: savedSearchesTmpl,
+		notificationsTemplate: notificationsTmpl,
+		pinTemplate:           pinTmpl,
+		store:                 store,
+		fbClient:              fbClient,
 	}
 }
 
 func (h *UserHandler) ShowDashboard(w http.ResponseWriter, r *http.Request) {
 	session := middleware.GetSessionFromContext(r.Context())
 	if session == nil {
-		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
 		return
 	}
 
@@ -93,14 +131,14 @@ func (h *UserHandler) ShowDashboard(w http.ResponseWriter, r *http.Request) {
 
 	// Pobierz aktywne wypożyczenia użytkownika
 	var activeLoans []LoanView
-	if h.fbClient != nil {
-		loans, err := h.fbClient.GetUserActiveLoans(session.UserID)
+	if h.store != nil {
+		loans, err := h.store.Loans(r.Context()).GetUserActiveLoans(session.UserID)
 		if err != nil {
 			log.Printf("Błąd pobierania wypożyczeń: %v", err)
 		} else {
 			for _, loan := range loans {
 				// Pobierz informacje o książce
-				book, err := h.fbClient.GetBook(loan.BookID)
+				book, err := h.store.Books(r.Context()).GetBook(loan.BookID)
 				if err != nil {
 					log.Printf("Błąd pobierania książki %s: %v", loan.BookID, err)
 					continue
@@ -114,32 +152,39 @@ func (h *UserHandler) ShowDashboard(w http.ResponseWriter, r *http.Request) {
 					Status:     string(loan.Status),
 					PickupCode: loan.PickupCode,
 					IsOverdue:  loan.IsOverdue(),
+					FineAmount: loan.FineAmount,
 				})
 			}
 		}
 	}
 
-	// Pobierz aktywne rezerwacje użytkownika
-	activeReservationsCount := 0
-	if h.fbClient != nil {
-		reservations, err := h.fbClient.GetUserActiveReservations(session.UserID)
-		if err != nil {
-			log.Printf("Błąd pobierania rezerwacji: %v", err)
-		} else {
-			activeReservationsCount = len(reservations)
-		}
-	}
-
+	// Rezerwacje, kary, powiadomienia i profil (odznaki, zatwierdzenie konta) są aktualne
+	// z bazy - sesja nie widzi zmian po zalogowaniu, np. odznak przyznanych w nocy
+	viewer := viewerFromContext(r.Context())
 	stats := map[string]interface{}{
 		"currentLoans":       len(activeLoans),
-		"maxLoans":           5,
-		"totalFines":         0.0,
-		"activeReservations": activeReservationsCount,
+		"maxLoans":           viewer.User().MaxLoans,
+		"totalFines":         viewer.TotalFines(),
+		"activeReservations": viewer.ActiveReservations(),
 	}
 
-	data := NewTemplateData(session)
+	data := NewPageData(r)
 	data["ActiveLoans"] = activeLoans
 	data["Stats"] = stats
+	data["UnreadNotifications"] = viewer.UnreadNotifications()
+
+	// Odznaki za czytanie i zatwierdzenie konta
+	user := viewer.User()
+	data["PendingApproval"] = user.PendingApproval
+	if user.BadgesOptOut {
+		data["BadgesOptOut"] = true
+	} else if h.fbClient != nil {
+		if badges, err := userBadges(h.fbClient, user); err != nil {
+			log.Printf("Błąd pobierania odznak: %v", err)
+		} else {
+			data["Badges"] = badges
+		}
+	}
 
 	if err := h.dashboardTemplate.Execute(w, data); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -150,7 +195,7 @@ func (h *UserHandler) ShowDashboard(w http.ResponseWriter, r *http.Request) {
 func (h *UserHandler) ShowFees(w http.ResponseWriter, r *http.Request) {
 	session := middleware.GetSessionFromContext(r.Context())
 	if session == nil {
-		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
 		return
 	}
 
@@ -175,7 +220,7 @@ func (h *UserHandler) ShowFees(w http.ResponseWriter, r *http.Request) {
 		totalFees += fee.Amount
 	}
 
-	data := NewTemplateData(session)
+	data := NewPageData(r)
 	data["Fees"] = fees
 	data["TotalFees"] = totalFees
 
@@ -188,7 +233,7 @@ func (h *UserHandler) ShowFees(w http.ResponseWriter, r *http.Request) {
 func (h *UserHandler) ShowHistory(w http.ResponseWriter, r *http.Request) {
 	session := middleware.GetSessionFromContext(r.Context())
 	if session == nil {
-		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
 		return
 	}
 
@@ -199,14 +244,14 @@ func (h *UserHandler) ShowHistory(w http.ResponseWriter, r *http.Request) {
 
 	// Pobierz historię wypożyczeń użytkownika
 	var history []HistoryView
-	if h.fbClient != nil {
-		loans, err := h.fbClient.GetUserLoanHistory(session.UserID)
+	if h.store != nil {
+		loans, err := h.store.Loans(r.Context()).GetUserLoanHistory(session.UserID)
 		if err != nil {
 			log.Printf("Błąd pobierania historii: %v", err)
 		} else {
 			for _, loan := range loans {
 				// Pobierz informacje o książce
-				book, err := h.fbClient.GetBook(loan.BookID)
+				book, err := h.store.Books(r.Context()).GetBook(loan.BookID)
 				if err != nil {
 					log.Printf("Błąd pobierania książki %s: %v", loan.BookID, err)
 					continue
@@ -223,7 +268,7 @@ func (h *UserHandler) ShowHistory(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	data := NewTemplateData(session)
+	data := NewPageData(r)
 	data["History"] = history
 
 	if err := h.historyTemplate.Execute(w, data); err != nil {
@@ -235,25 +280,92 @@ func (h *UserHandler) ShowHistory(w http.ResponseWriter, r *http.Request) {
 func (h *UserHandler) ShowReservations(w http.ResponseWriter, r *http.Request) {
 	session := middleware.GetSessionFromContext(r.Context())
 	if session == nil {
-		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	data := NewPageData(r)
+	data["PauseSaved"] = r.URL.Query().Get("paused") == "1"
+	h.renderReservations(w, r, session, data)
+}
+
+// UpdateHoldPause ustawia lub usuwa urlop czytelnika (POST /user/reservations/pause).
+// W czasie urlopu rezerwacje nie przepadają, ale zwracane książki trafiają do następnych osób w kolejce.
+func (h *UserHandler) UpdateHoldPause(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Nie jesteś zalogowany", http.StatusUnauthorized)
+		return
+	}
+
+	if h.fbClient == nil {
+		http.Error(w, "Błąd serwera", http.StatusInternalServerError)
 		return
 	}
 
+	var from, until time.Time
+	var err error
+	if r.FormValue("action") != "delete" {
+		from, err = parseFormDate(r.FormValue("from"))
+		if err == nil {
+			until, err = parseFormDate(r.FormValue("until"))
+		}
+	}
+	if err == nil {
+		err = h.fbClient.Traced(r.Context()).SetUserHoldPause(session.UserID, from, until)
+	}
+	if err != nil {
+		log.Printf("Błąd zapisywania urlopu czytelnika %s: %v", session.UserID, err)
+		data := NewPageData(r)
+		data["PauseError"] = "Nie udało się zapisać urlopu: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		h.renderReservations(w, r, session, data)
+		return
+	}
+
+	basepath.Redirect(w, r, "/user/reservations?paused=1", http.StatusSeeOther)
+}
+
+// parseFormDate odczytuje datę z pola formularza type="date" (pusta wartość daje zerową datę)
+func parseFormDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("nieprawidłowa data")
+	}
+	return day, nil
+}
+
+func (h *UserHandler) renderReservations(w http.ResponseWriter, r *http.Request, sess *session.Session, data TemplateData) {
 	if h.reservationsTemplate == nil {
 		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
 		return
 	}
 
+	// Urlop czytelnika (sesja przechowuje kopię profilu z chwili logowania)
+	if h.store != nil {
+		if user, err := h.store.Users(r.Context()).GetUser(sess.UserID); err != nil {
+			log.Printf("Błąd pobierania użytkownika %s: %v", sess.UserID, err)
+		} else {
+			data["HoldPausedFrom"] = user.HoldPausedFrom
+			data["HoldPausedUntil"] = user.HoldPausedUntil
+			data["HoldsPaused"] = user.HoldsPausedAt(time.Now())
+			data["AtLoanLimit"] = user.AtLoanLimit()
+		}
+	}
+
 	// Pobierz rezerwacje użytkownika
 	var reservations []ReservationView
-	if h.fbClient != nil {
-		res, err := h.fbClient.GetUserActiveReservations(session.UserID)
+	if h.store != nil {
+		res, err := h.store.Reservations(r.Context()).GetUserActiveReservations(sess.UserID)
 		if err != nil {
 			log.Printf("Błąd pobierania rezerwacji: %v", err)
 		} else {
 			for _, reservation := range res {
 				// Pobierz informacje o książce
-				book, err := h.fbClient.GetBook(reservation.BookID)
+				book, err := h.store.Books(r.Context()).GetBook(reservation.BookID)
 				if err != nil {
 					log.Printf("Błąd pobierania książki %s: %v", reservation.BookID, err)
 					continue
@@ -262,7 +374,7 @@ func (h *UserHandler) ShowReservations(w http.ResponseWriter, r *http.Request) {
 				// Oblicz pozycję w kolejce (tylko dla pending)
 				queuePos := 0
 				if reservation.Status == models.ReservationStatusPending {
-					allReservations, _ := h.fbClient.GetBookReservations(reservation.BookID)
+					allReservations, _ := h.store.Reservations(r.Context()).GetBookReservations(reservation.BookID)
 					for i, r := range allReservations {
 						if r.Status == models.ReservationStatusPending && r.ID == reservation.ID {
 							queuePos = i + 1
@@ -279,12 +391,16 @@ func (h *UserHandler) ShowReservations(w http.ResponseWriter, r *http.Request) {
 					ExpiryDate:      reservation.ExpiryDate,
 					Status:          string(reservation.Status),
 					QueuePosition:   queuePos,
+					PickupLocation:  reservation.PickupLocation,
+					EditionOnly:     reservation.EditionOnly,
+					LockerID:        reservation.LockerID,
+					LockerCode:      reservation.LockerCode,
+					SuspendedAt:     reservation.SuspendedAt,
 				})
 			}
 		}
 	}
 
-	data := NewTemplateData(session)
 	data["Reservations"] = reservations
 
 	if err := h.reservationsTemplate.Execute(w, data); err != nil {
@@ -314,7 +430,7 @@ func (h *UserHandler) BorrowFromReservation(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Pobierz rezerwację
-	reservation, err := h.fbClient.GetReservation(reservationID)
+	reservation, err := h.store.Reservations(r.Context()).GetReservation(reservationID)
 	if err != nil {
 		log.Printf("Błąd pobierania rezerwacji: %v", err)
 		http.Error(w, "Nie znaleziono rezerwacji", http.StatusNotFound)
@@ -337,7 +453,7 @@ func (h *UserHandler) BorrowFromReservation(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Pobierz użytkownika
-	user, err := h.fbClient.GetUser(session.UserID)
+	user, err := h.store.Users(r.Context()).GetUser(session.UserID)
 	if err != nil {
 		log.Printf("Błąd pobierania użytkownika: %v", err)
 		http.Error(w, "Błąd pobierania danych użytkownika", http.StatusInternalServerError)
@@ -359,9 +475,11 @@ func (h *UserHandler) BorrowFromReservation(w http.ResponseWriter, r *http.Reque
 		UserID:    session.UserID,
 		BookTitle: reservation.BookTitle,                // Denormalizacja
 		UserName:  user.FirstName + " " + user.LastName, // Denormalizacja
+
+		PickupLocation: reservation.PickupLocation,
 	}
 
-	if err := h.fbClient.CreateLoan(loan); err != nil {
+	if err := h.store.Loans(r.Context()).CreateLoan(loan); err != nil {
 		log.Printf("Błąd tworzenia wypożyczenia: %v", err)
 		http.Error(w, "Nie udało się wypożyczyć książki", http.StatusInternalServerError)
 		return
@@ -370,12 +488,12 @@ func (h *UserHandler) BorrowFromReservation(w http.ResponseWriter, r *http.Reque
 	// Zwiększ licznik wypożyczeń użytkownika
 	user.CurrentLoans++
 	user.UpdatedAt = time.Now()
-	if err := h.fbClient.UpdateUser(session.UserID, user); err != nil {
+	if err := h.store.Users(r.Context()).UpdateUser(session.UserID, user); err != nil {
 		log.Printf("Błąd aktualizacji użytkownika: %v", err)
 	}
 
 	// Oznacz rezerwację jako zrealizowaną
-	if err := h.fbClient.CompleteReservation(reservationID); err != nil {
+	if err := h.fbClient.Traced(r.Context()).CompleteReservation(reservationID); err != nil {
 		log.Printf("Błąd oznaczania rezerwacji jako zrealizowanej: %v", err)
 	}
 
@@ -385,7 +503,7 @@ func (h *UserHandler) BorrowFromReservation(w http.ResponseWriter, r *http.Reque
 		✓ Rezerwacja przekształcona w zamówienie!<br>
 		<span class="font-bold">Kod odbioru: ` + loan.PickupCode + `</span><br>
 		Podaj ten kod w bibliotece, aby odebrać książkę.
-		<a href="/user" class="underline ml-2">Zobacz moje wypożyczenia</a>
+		<a href="` + basepath.URL("/user") + `" class="underline ml-2">Zobacz moje wypożyczenia</a>
 	</div>`))
 }
 
@@ -410,7 +528,7 @@ func (h *UserHandler) CancelReservation(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Pobierz rezerwację
-	reservation, err := h.fbClient.GetReservation(reservationID)
+	reservation, err := h.store.Reservations(r.Context()).GetReservation(reservationID)
 	if err != nil {
 		log.Printf("Błąd pobierania rezerwacji: %v", err)
 		http.Error(w, "Nie znaleziono rezerwacji", http.StatusNotFound)
@@ -426,35 +544,38 @@ func (h *UserHandler) CancelReservation(w http.ResponseWriter, r *http.Request)
 	bookID := reservation.BookID
 
 	// Anuluj rezerwację
-	if err := h.fbClient.CancelReservation(reservationID); err != nil {
+	if err := h.store.Reservations(r.Context()).CancelReservation(reservationID); err != nil {
 		log.Printf("Błąd anulowania rezerwacji: %v", err)
 		http.Error(w, "Nie udało się anulować rezerwacji", http.StatusInternalServerError)
 		return
 	}
 
 	// Sprawdź czy są kolejne rezerwacje w kolejce
-	nextReservation, err := h.fbClient.GetNextReservation(bookID)
+	nextReservation, err := h.store.Reservations(r.Context()).GetNextReservation(bookID)
 	if err != nil {
 		log.Printf("Błąd sprawdzania kolejki rezerwacji: %v", err)
 	}
 
 	if nextReservation != nil {
 		// Jest kolejna rezerwacja - aktywuj ją
-		if err := h.fbClient.MarkReservationReady(nextReservation.ID); err != nil {
+		if err := h.fbClient.Traced(r.Context()).MarkReservationReady(nextReservation.ID, bookID); err != nil {
 			log.Printf("Błąd aktywacji kolejnej rezerwacji: %v", err)
 		}
 	} else {
 		// Brak kolejnych rezerwacji - zwróć książkę do katalogu (zwiększ dostępność)
-		book, err := h.fbClient.GetBook(bookID)
+		book, err := h.store.Books(r.Context()).GetBook(bookID)
 		if err != nil {
 			log.Printf("Błąd pobierania książki: %v", err)
 		} else {
 			book.AvailableCopies++
 			book.UpdatedAt = time.Now()
-			if err := h.fbClient.UpdateBook(bookID, book); err != nil {
+			if err := h.store.Books(r.Context()).UpdateBook(bookID, book); err != nil {
 				log.Printf("Błąd aktualizacji dostępności książki: %v", err)
 			}
 		}
+		if err := h.fbClient.Traced(r.Context()).SuspendLimitedReservations(bookID, time.Time{}); err != nil {
+			log.Printf("Błąd powiadamiania o pominiętych rezerwacjach: %v", err)
+		}
 	}
 
 	// Zwróć komunikat sukcesu (htmx usunie element)
@@ -463,3 +584,308 @@ func (h *UserHandler) CancelReservation(w http.ResponseWriter, r *http.Request)
 		Rezerwacja została anulowana.
 	</div>`))
 }
+
+// ShowSavedSearches wyświetla zapisane wyszukiwania czytelnika (GET /user/saved-searches)
+func (h *UserHandler) ShowSavedSearches(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if h.savedSearchesTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	data := NewPageData(r)
+
+	if h.fbClient != nil {
+		savedSearches, err := h.fbClient.Traced(r.Context()).GetUserSavedSearches(session.UserID)
+		if err != nil {
+			log.Printf("Błąd pobierania zapisanych wyszukiwań: %v", err)
+			data["Error"] = "Błąd pobierania zapisanych wyszukiwań"
+		}
+		data["SavedSearches"] = savedSearches
+
+		subscriptions, err := h.fbClient.Traced(r.Context()).GetUserSubscriptions(session.UserID)
+		if err != nil {
+			log.Printf("Błąd pobierania subskrypcji: %v", err)
+			data["Error"] = "Błąd pobierania subskrypcji"
+		}
+		data["Subscriptions"] = subscriptions
+	}
+
+	if err := h.savedSearchesTemplate.Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// CreateSavedSearch zapisuje wyszukiwanie z alertem (POST /user/saved-searches)
+func (h *UserHandler) CreateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Nie jesteś zalogowany", http.StatusUnauthorized)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Błąd parsowania formularza", http.StatusBadRequest)
+		return
+	}
+
+	rawQuery := strings.TrimSpace(r.FormValue("query"))
+	query := search.ParseQuery(rawQuery)
+	if query.IsEmpty() {
+		http.Error(w, "Zapytanie nie może być puste", http.StatusBadRequest)
+		return
+	}
+
+	if h.fbClient == nil {
+		http.Error(w, "Błąd serwera", http.StatusInternalServerError)
+		return
+	}
+
+	existing, err := h.fbClient.Traced(r.Context()).GetUserSavedSearches(session.UserID)
+	if err != nil {
+		log.Printf("Błąd pobierania zapisanych wyszukiwań: %v", err)
+		http.Error(w, "Błąd zapisywania wyszukiwania", http.StatusInternalServerError)
+		return
+	}
+	if len(existing) >= maxSavedSearches {
+		http.Error(w, "Osiągnięto limit zapisanych wyszukiwań", http.StatusConflict)
+		return
+	}
+	for _, saved := range existing {
+		if saved.Query == rawQuery {
+			// Takie wyszukiwanie jest już zapisane
+			basepath.Redirect(w, r, "/user/saved-searches", http.StatusSeeOther)
+			return
+		}
+	}
+
+	saved := &models.SavedSearch{
+		UserID:   session.UserID,
+		Query:    rawQuery,
+		MatchKey: query.MatchKey(),
+	}
+	if err := h.fbClient.Traced(r.Context()).CreateSavedSearch(saved); err != nil {
+		log.Printf("Błąd zapisywania wyszukiwania: %v", err)
+		http.Error(w, "Błąd zapisywania wyszukiwania", http.StatusInternalServerError)
+		return
+	}
+
+	basepath.Redirect(w, r, "/user/saved-searches", http.StatusSeeOther)
+}
+
+// DeleteSavedSearch usuwa zapisane wyszukiwanie (POST /user/saved-searches/{id}/delete)
+func (h *UserHandler) DeleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Nie jesteś zalogowany", http.StatusUnauthorized)
+		return
+	}
+
+	savedID := r.PathValue("id")
+	if savedID == "" {
+		http.Error(w, "Brak ID wyszukiwania", http.StatusBadRequest)
+		return
+	}
+
+	if h.fbClient == nil {
+		http.Error(w, "Błąd serwera", http.StatusInternalServerError)
+		return
+	}
+
+	saved, err := h.fbClient.Traced(r.Context()).GetSavedSearch(savedID)
+	if err != nil {
+		log.Printf("Błąd pobierania wyszukiwania: %v", err)
+		http.Error(w, "Nie znaleziono wyszukiwania", http.StatusNotFound)
+		return
+	}
+
+	// Sprawdź czy wyszukiwanie należy do użytkownika
+	if saved.UserID != session.UserID {
+		http.Error(w, "To nie Twoje wyszukiwanie", http.StatusForbidden)
+		return
+	}
+
+	if err := h.fbClient.Traced(r.Context()).DeleteSavedSearch(savedID); err != nil {
+		log.Printf("Błąd usuwania wyszukiwania: %v", err)
+		http.Error(w, "Nie udało się usunąć wyszukiwania", http.StatusInternalServerError)
+		return
+	}
+
+	basepath.Redirect(w, r, "/user/saved-searches", http.StatusSeeOther)
+}
+
+// ShowNotifications wyświetla powiadomienia i oznacza je jako przeczytane (GET /user/notifications)
+func (h *UserHandler) ShowNotifications(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if h.notificationsTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	data := NewPageData(r)
+
+	if h.fbClient != nil {
+		notifications, err := h.fbClient.Traced(r.Context()).GetUserNotifications(session.UserID)
+		if err != nil {
+			log.Printf("Błąd pobierania powiadomień: %v", err)
+			data["Error"] = "Błąd pobierania powiadomień"
+		}
+		data["Notifications"] = notifications
+
+		// Nieprzeczytane są wyróżnione na liście, więc oznaczamy je dopiero po pobraniu
+		if err == nil {
+			if err := h.fbClient.Traced(r.Context()).MarkNotificationsRead(session.UserID); err != nil {
+				log.Printf("Błąd oznaczania powiadomień jako przeczytane: %v", err)
+			}
+		}
+	}
+
+	if err := h.notificationsTemplate.Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// ToggleSubscription włącza lub wyłącza subskrypcję nowych tytułów autora/kategorii (POST /user/subscriptions)
+func (h *UserHandler) ToggleSubscription(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Nie jesteś zalogowany", http.StatusUnauthorized)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Błąd parsowania formularza", http.StatusBadRequest)
+		return
+	}
+
+	subType := models.SubscriptionType(r.FormValue("type"))
+	value := strings.TrimSpace(r.FormValue("value"))
+	if value == "" || (subType != models.SubscriptionAuthor && subType != models.SubscriptionCategory) {
+		http.Error(w, "Nieprawidłowa subskrypcja", http.StatusBadRequest)
+		return
+	}
+
+	if h.fbClient == nil {
+		http.Error(w, "Błąd serwera", http.StatusInternalServerError)
+		return
+	}
+
+	existing, err := h.fbClient.Traced(r.Context()).FindUserSubscription(session.UserID, models.SubscriptionKey(subType, value))
+	if err != nil {
+		log.Printf("Błąd pobierania subskrypcji: %v", err)
+		http.Error(w, "Błąd zapisywania subskrypcji", http.StatusInternalServerError)
+		return
+	}
+
+	if existing != nil {
+		err = h.fbClient.Traced(r.Context()).DeleteSubscription(existing.ID)
+	} else {
+		err = h.fbClient.Traced(r.Context()).CreateSubscription(&models.Subscription{
+			UserID: session.UserID,
+			Type:   subType,
+			Value:  value,
+		})
+	}
+	if err != nil {
+		log.Printf("Błąd zmiany subskrypcji: %v", err)
+		http.Error(w, "Błąd zapisywania subskrypcji", http.StatusInternalServerError)
+		return
+	}
+
+	// Wróć na stronę, z której przełączono subskrypcję (tylko ścieżki lokalne)
+	redirect := r.FormValue("redirect")
+	if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") {
+		redirect = "/user/saved-searches"
+	}
+	basepath.Redirect(w, r, redirect, http.StatusSeeOther)
+}
+
+// ShowPIN wyświetla formularz PIN-u do weryfikacji przez telefon (GET /user/pin)
+func (h *UserHandler) ShowPIN(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		basepath.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	data := NewPageData(r)
+	data["Saved"] = r.URL.Query().Get("saved")
+	h.renderPIN(w, r, data)
+}
+
+// UpdatePIN ustawia lub usuwa PIN czytelnika (POST /user/pin)
+func (h *UserHandler) UpdatePIN(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if session == nil {
+		http.Error(w, "Nie jesteś zalogowany", http.StatusUnauthorized)
+		return
+	}
+
+	if h.fbClient == nil {
+		http.Error(w, "Błąd serwera", http.StatusInternalServerError)
+		return
+	}
+
+	if r.FormValue("action") == "delete" {
+		if err := h.fbClient.Traced(r.Context()).SetUserPIN(session.UserID, ""); err != nil {
+			log.Printf("Błąd usuwania PIN-u: %v", err)
+			http.Error(w, "Błąd usuwania PIN-u", http.StatusInternalServerError)
+			return
+		}
+		basepath.Redirect(w, r, "/user/pin?saved=deleted", http.StatusSeeOther)
+		return
+	}
+
+	pin := r.FormValue("pin")
+	if pin != r.FormValue("pin_confirm") {
+		data := NewPageData(r)
+		data["Error"] = "Podane PIN-y nie są identyczne"
+		w.WriteHeader(http.StatusBadRequest)
+		h.renderPIN(w, r, data)
+		return
+	}
+
+	if err := h.fbClient.Traced(r.Context()).SetUserPIN(session.UserID, pin); err != nil {
+		log.Printf("Błąd zapisywania PIN-u: %v", err)
+		data := NewPageData(r)
+		data["Error"] = "Nie udało się zapisać PIN-u: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		h.renderPIN(w, r, data)
+		return
+	}
+
+	basepath.Redirect(w, r, "/user/pin?saved=set", http.StatusSeeOther)
+}
+
+func (h *UserHandler) renderPIN(w http.ResponseWriter, r *http.Request, data TemplateData) {
+	if h.pinTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	// Sesja przechowuje kopię profilu z chwili logowania, więc stan PIN-u pobieramy z bazy
+	if h.fbClient != nil {
+		session := middleware.GetSessionFromContext(r.Context())
+		if user, err := h.fbClient.Traced(r.Context()).GetUser(session.UserID); err == nil {
+			data["HasPIN"] = user.HasPIN()
+		} else {
+			log.Printf("Błąd pobierania użytkownika: %v", err)
+		}
+	}
+
+	if err := h.pinTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania strony PIN-u: %v", err)
+	}
+}
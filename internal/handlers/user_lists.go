@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/middleware"
+	"library-management-system/internal/models"
+)
+
+// ShowUserLists wyświetla listy czytelnika i formularz nowej listy (GET /user/lists)
+func (h *ReadingListsHandler) ShowUserLists(w http.ResponseWriter, r *http.Request) {
+	h.renderUserLists(w, r, NewPageData(r))
+}
+
+// CreateUserList zakłada listę czytelnika (POST /user/lists). Formularz ze strony
+// książki wysyła też book_id - książka trafia wtedy od razu na nową listę.
+func (h *ReadingListsHandler) CreateUserList(w http.ResponseWriter, r *http.Request) {
+	session := middleware.GetSessionFromContext(r.Context())
+	if h.store == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	list := readUserListForm(r, &models.ReadingList{OwnerID: session.UserID})
+	bookID := r.FormValue("book_id")
+	if bookID != "" {
+		list.BookIDs = []string{bookID}
+	}
+
+	if err := h.store.ReadingLists(r.Context()).CreateUserReadingList(list); err != nil {
+		data := NewPageData(r)
+		data["Form"] = list
+		data["Error"] = "Nie udało się utworzyć listy: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		h.renderUserLists(w, r, data)
+		return
+	}
+
+	if bookID != "" {
+		basepath.Redirect(w, r, "/books/"+bookID+"?listed="+list.ID, http.StatusSeeOther)
+		return
+	}
+	basepath.Redirect(w, r, "/user/lists/"+list.ID, http.StatusSeeOther)
+}
+
+// AddToUserList dopisuje książkę na koniec wybranej listy i wraca na stronę
+// książki (POST /user/lists/books)
+func (h *ReadingListsHandler) AddToUserList(w http.ResponseWriter, r *http.Request) {
+	list, ok := h.loadUserList(w, r, r.FormValue("list_id"))
+	if !ok {
+		return
+	}
+
+	bookID := r.FormValue("book_id")
+	if bookID == "" {
+		http.Error(w, "Brak ID książki", http.StatusBadRequest)
+		return
+	}
+	if !list.HasBook(bookID) {
+		list.BookIDs = append(list.BookIDs, bookID)
+		if err := h.store.ReadingLists(r.Context()).UpdateUserReadingList(list); err != nil {
+			log.Printf("Błąd dodawania książki do listy %s: %v", list.ID, err)
+			http.Error(w, "Nie udało się dodać książki: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	basepath.Redirect(w, r, "/books/"+bookID+"?listed="+list.ID, http.StatusSeeOther)
+}
+
+// EditUserList wyświetla pozycje listy, jej ustawienia i link do udostępniania
+// (GET /user/lists/{id})
+func (h *ReadingListsHandler) EditUserList(w http.ResponseWriter, r *http.Request) {
+	list, ok := h.loadUserList(w, r, chi.URLParam(r, "id"))
+	if !ok {
+		return
+	}
+
+	data := NewPageData(r)
+	if r.URL.Query().Get("done") == "saved" {
+		data["Notice"] = "Zmiany zostały zapisane"
+	}
+	h.renderUserEdit(w, r, list, data)
+}
+
+// UpdateUserList zapisuje nazwę, opis i udostępnianie listy (POST /user/lists/{id})
+func (h *ReadingListsHandler) UpdateUserList(w http.ResponseWriter, r *http.Request) {
+	list, ok := h.loadUserList(w, r, chi.URLParam(r, "id"))
+	if !ok {
+		return
+	}
+
+	h.saveUserList(w, r, readUserListForm(r, list))
+}
+
+// RemoveUserListBook usuwa książkę z listy (POST /user/lists/{id}/books/{bookID}/delete)
+func (h *ReadingListsHandler) RemoveUserListBook(w http.ResponseWriter, r *http.Request) {
+	list, ok := h.loadUserList(w, r, chi.URLParam(r, "id"))
+	if !ok {
+		return
+	}
+
+	removeListBook(list, chi.URLParam(r, "bookID"))
+	h.saveUserList(w, r, list)
+}
+
+// ReorderUserListBooks zapisuje kolejność pozycji (POST /user/lists/{id}/order)
+func (h *ReadingListsHandler) ReorderUserListBooks(w http.ResponseWriter, r *http.Request) {
+	list, ok := h.loadUserList(w, r, chi.URLParam(r, "id"))
+	if !ok {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Nieprawidłowe dane formularza", http.StatusBadRequest)
+		return
+	}
+
+	orderListBooks(list, r.Form["book_id"])
+	h.saveUserList(w, r, list)
+}
+
+// RenewShareLink unieważnia dotychczasowy link do listy i nadaje nowy
+// (POST /user/lists/{id}/share)
+func (h *ReadingListsHandler) RenewShareLink(w http.ResponseWriter, r *http.Request) {
+	list, ok := h.loadUserList(w, r, chi.URLParam(r, "id"))
+	if !ok {
+		return
+	}
+
+	if err := h.store.ReadingLists(r.Context()).RenewShareToken(list); err != nil {
+		log.Printf("Błąd zmiany linku do listy %s: %v", list.ID, err)
+		http.Error(w, "Nie udało się zmienić linku", http.StatusInternalServerError)
+		return
+	}
+
+	basepath.Redirect(w, r, "/user/lists/"+list.ID+"?done=saved", http.StatusSeeOther)
+}
+
+// DeleteUserList usuwa listę czytelnika (POST /user/lists/{id}/delete)
+func (h *ReadingListsHandler) DeleteUserList(w http.ResponseWriter, r *http.Request) {
+	list, ok := h.loadUserList(w, r, chi.URLParam(r, "id"))
+	if !ok {
+		return
+	}
+
+	if err := h.store.ReadingLists(r.Context()).DeleteUserReadingList(list.ID); err != nil {
+		log.Printf("Błąd usuwania listy: %v", err)
+		http.Error(w, "Nie udało się usunąć listy", http.StatusInternalServerError)
+		return
+	}
+
+	basepath.Redirect(w, r, "/user/lists", http.StatusSeeOther)
+}
+
+// ShowSharedList wyświetla listę czytelnika otwartą z linku (GET /lists/shared/{token}).
+// Właściciel widzi listę także wtedy, gdy udostępnianie jest wyłączone.
+func (h *ReadingListsHandler) ShowSharedList(w http.ResponseWriter, r *http.Request) {
+	if h.listTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+	if h.store == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return
+	}
+
+	session := middleware.GetSessionFromContext(r.Context())
+	list, err := h.store.ReadingLists(r.Context()).GetReadingListByShareToken(chi.URLParam(r, "token"))
+	if err != nil {
+		log.Printf("Błąd pobierania udostępnionej listy: %v", err)
+		http.Error(w, "Błąd pobierania listy", http.StatusInternalServerError)
+		return
+	}
+	if list == nil || (!list.Shared && (session == nil || session.UserID != list.OwnerID)) {
+		http.Error(w, "Lista nie istnieje albo nie jest udostępniona", http.StatusNotFound)
+		return
+	}
+
+	data := NewPageData(r)
+	books, err := readingListBooks(h.store.Books(r.Context()), list.BookIDs)
+	if err != nil {
+		log.Printf("Błąd pobierania książek listy %s: %v", list.ID, err)
+		data["Error"] = "Błąd pobierania książek z bazy danych"
+	}
+	data["Current"] = &readingListView{ReadingList: list, Books: books}
+
+	lists, err := h.store.ReadingLists(r.Context()).GetPublishedReadingLists()
+	if err != nil {
+		log.Printf("Błąd pobierania list lektur: %v", err)
+	}
+	data["Lists"] = lists
+
+	if err := h.listTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania udostępnionej listy: %v", err)
+		http.Error(w, "Błąd renderowania strony", http.StatusInternalServerError)
+	}
+}
+
+// loadUserList pobiera listę zalogowanego czytelnika; przy błędzie lub cudzej
+// liście wysyła odpowiedź
+func (h *ReadingListsHandler) loadUserList(w http.ResponseWriter, r *http.Request, id string) (*models.ReadingList, bool) {
+	if h.store == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusServiceUnavailable)
+		return nil, false
+	}
+
+	list, err := h.store.ReadingLists(r.Context()).GetUserReadingList(id)
+	if err != nil {
+		log.Printf("Błąd pobierania listy: %v", err)
+		http.Error(w, "Nie znaleziono listy", http.StatusNotFound)
+		return nil, false
+	}
+
+	session := middleware.GetSessionFromContext(r.Context())
+	if list.OwnerID != session.UserID {
+		http.Error(w, "To nie Twoja lista", http.StatusForbidden)
+		return nil, false
+	}
+	return list, true
+}
+
+// saveUserList zapisuje listę i wraca do jej edycji albo pokazuje błąd
+func (h *ReadingListsHandler) saveUserList(w http.ResponseWriter, r *http.Request, list *models.ReadingList) {
+	if err := h.store.ReadingLists(r.Context()).UpdateUserReadingList(list); err != nil {
+		data := NewPageData(r)
+		data["Error"] = "Nie udało się zapisać listy: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		h.renderUserEdit(w, r, list, data)
+		return
+	}
+
+	basepath.Redirect(w, r, "/user/lists/"+list.ID+"?done=saved", http.StatusSeeOther)
+}
+
+// renderUserLists uzupełnia listy czytelnika i renderuje stronę
+func (h *ReadingListsHandler) renderUserLists(w http.ResponseWriter, r *http.Request, data TemplateData) {
+	if h.userTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	if h.store != nil {
+		session := middleware.GetSessionFromContext(r.Context())
+		lists, err := h.store.ReadingLists(r.Context()).GetUserReadingLists(session.UserID)
+		if err != nil {
+			log.Printf("Błąd pobierania list czytelnika: %v", err)
+			data["Error"] = "Błąd pobierania list z bazy danych"
+		}
+		data["Lists"] = lists
+		data["CanCreate"] = len(lists) < models.MaxUserReadingLists
+	}
+
+	if err := h.userTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania list czytelnika: %v", err)
+	}
+}
+
+// renderUserEdit renderuje edycję listy czytelnika z jej książkami
+func (h *ReadingListsHandler) renderUserEdit(w http.ResponseWriter, r *http.Request, list *models.ReadingList, data TemplateData) {
+	if h.userEditTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	books, err := readingListBooks(h.store.Books(r.Context()), list.BookIDs)
+	if err != nil {
+		log.Printf("Błąd pobierania książek listy %s: %v", list.ID, err)
+		data["Error"] = "Błąd pobierania książek z bazy danych"
+	}
+	data["List"] = list
+	data["Books"] = books
+	data["ShareURL"] = h.baseURL + "/lists/shared/" + list.ShareToken
+
+	if err := h.userEditTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania edycji listy: %v", err)
+	}
+}
+
+// readUserListForm przepisuje pola formularza do listy czytelnika
+func readUserListForm(r *http.Request, list *models.ReadingList) *models.ReadingList {
+	list.Title = strings.TrimSpace(r.FormValue("title"))
+	list.Description = strings.TrimSpace(r.FormValue("description"))
+	list.Shared = r.FormValue("shared") == "on"
+	return list
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/models"
+	"library-management-system/internal/session"
+)
+
+// ShowUserSync wyświetla rozbieżności między Firebase Auth a profilami użytkowników (GET /staff/users/sync)
+func (h *StaffHandler) ShowUserSync(w http.ResponseWriter, r *http.Request) {
+	if h.userSyncTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+
+	data := NewPageData(r)
+	data["Message"] = r.URL.Query().Get("msg")
+
+	if h.fbClient == nil {
+		data["Error"] = "Baza danych niedostępna"
+	} else if report, err := h.fbClient.Traced(r.Context()).FindOrphanedUsers(); err != nil {
+		log.Printf("Błąd synchronizacji kont: %v", err)
+		data["Error"] = "Błąd porównywania kont: " + err.Error()
+	} else {
+		data["Report"] = report
+	}
+
+	if err := h.userSyncTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania synchronizacji kont: %v", err)
+		http.Error(w, "Błąd renderowania strony", http.StatusInternalServerError)
+	}
+}
+
+// ReconcileProfiles dezaktywuje wszystkie profile bez konta Firebase Auth (POST /staff/users/sync/profiles)
+func (h *StaffHandler) ReconcileProfiles(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return
+	}
+
+	report, err := h.fbClient.Traced(r.Context()).FindOrphanedUsers()
+	if err != nil {
+		log.Printf("Błąd synchronizacji kont: %v", err)
+		http.Error(w, "Błąd porównywania kont", http.StatusInternalServerError)
+		return
+	}
+
+	deactivated := 0
+	for _, profile := range report.OrphanedProfiles {
+		if err := h.deactivateOrphanedProfile(profile); err != nil {
+			log.Printf("Błąd dezaktywacji profilu %s: %v", profile.ID, err)
+			continue
+		}
+		deactivated++
+	}
+
+	redirectToUserSync(w, r, "Dezaktywowano profile: "+strconv.Itoa(deactivated))
+}
+
+// DeactivateProfile dezaktywuje pojedynczy profil bez konta Firebase Auth (POST /staff/users/sync/profiles/{id})
+func (h *StaffHandler) DeactivateProfile(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return
+	}
+
+	profile, err := h.fbClient.Traced(r.Context()).GetUser(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Nie znaleziono użytkownika", http.StatusNotFound)
+		return
+	}
+
+	// Raport mógł być nieaktualny - nie dezaktywuj profilu z istniejącym kontem
+	exists, err := h.fbClient.Traced(r.Context()).AuthAccountExists(profile.FirebaseUID)
+	if err != nil {
+		log.Printf("Błąd sprawdzania konta %s: %v", profile.FirebaseUID, err)
+		http.Error(w, "Błąd sprawdzania konta", http.StatusInternalServerError)
+		return
+	}
+	if exists {
+		redirectToUserSync(w, r, "Konto "+profile.Email+" istnieje w Firebase Auth - pominięto")
+		return
+	}
+
+	if err := h.deactivateOrphanedProfile(profile); err != nil {
+		log.Printf("Błąd dezaktywacji profilu %s: %v", profile.ID, err)
+		http.Error(w, "Błąd zapisywania zmian", http.StatusInternalServerError)
+		return
+	}
+
+	redirectToUserSync(w, r, "Dezaktywowano profil "+profile.Email)
+}
+
+// DeleteAuthAccount usuwa konto Firebase Auth bez profilu (POST /staff/users/sync/accounts/{uid}/delete)
+func (h *StaffHandler) DeleteAuthAccount(w http.ResponseWriter, r *http.Request) {
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return
+	}
+
+	uid := chi.URLParam(r, "uid")
+
+	// Nie usuwaj konta, do którego w międzyczasie dodano profil (także w innej bibliotece sieci)
+	hasProfile, err := h.fbClient.Traced(r.Context()).AuthAccountHasProfile(uid)
+	if err != nil {
+		log.Printf("Błąd sprawdzania profilu konta %s: %v", uid, err)
+		http.Error(w, "Błąd sprawdzania konta", http.StatusInternalServerError)
+		return
+	}
+	if hasProfile {
+		redirectToUserSync(w, r, "Konto ma już profil w bazie - pominięto")
+		return
+	}
+
+	if err := h.fbClient.Traced(r.Context()).DeleteAuthAccount(uid); err != nil {
+		log.Printf("Błąd usuwania konta %s: %v", uid, err)
+		http.Error(w, "Błąd usuwania konta", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Usunięto konto Firebase Auth bez profilu: %s", uid)
+	redirectToUserSync(w, r, "Usunięto konto Firebase Auth")
+}
+
+// deactivateOrphanedProfile blokuje profil i kończy jego sesje, zachowując historię wypożyczeń
+func (h *StaffHandler) deactivateOrphanedProfile(profile *models.User) error {
+	session.GetManager().DeleteUserSessions(profile.ID)
+
+	if !profile.IsActive {
+		return nil
+	}
+	profile.IsActive = false
+	if err := h.fbClient.UpdateUser(profile.ID, profile); err != nil {
+		return err
+	}
+
+	log.Printf("Dezaktywowano profil bez konta Firebase Auth: %s (%s)", profile.Email, profile.ID)
+	return nil
+}
+
+func redirectToUserSync(w http.ResponseWriter, r *http.Request, message string) {
+	basepath.Redirect(w, r, "/staff/users/sync?msg="+url.QueryEscape(message), http.StatusSeeOther)
+}
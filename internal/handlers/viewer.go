@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"library-management-system/internal/basepath"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/middleware"
+	"library-management-system/internal/models"
+	"library-management-system/internal/nav"
+	"library-management-system/internal/repository"
+	"library-management-system/internal/session"
+)
+
+type viewerContextKey struct{}
+
+// Viewer to aktualne dane zalogowanego użytkownika dla szablonów. Sesja trzyma kopię profilu
+// z chwili logowania, więc kary, liczniki wypożyczeń i rezerwacji oraz nieprzeczytane
+// powiadomienia są pobierane z bazy - każda grupa przy pierwszym użyciu i najwyżej raz
+// na żądanie, niezależnie od tego, ile fragmentów strony z nich korzysta.
+type Viewer struct {
+	ctx      context.Context
+	sess     *session.Session
+	store    repository.Store
+	fbClient *firebase.Client
+
+	userOnce sync.Once
+	user     *models.User
+
+	loansOnce    sync.Once
+	activeLoans  int
+	overdueLoans int
+
+	reservationsOnce   sync.Once
+	activeReservations int
+	readyReservations  int
+
+	notificationsOnce   sync.Once
+	unreadNotifications int
+}
+
+// User zwraca profil z bazy (albo z sesji, gdy nie udało się go pobrać)
+func (v *Viewer) User() *models.User {
+	v.userOnce.Do(func() {
+		v.user = v.sess.User
+		if v.store == nil {
+			return
+		}
+		user, err := v.store.Users(v.ctx).GetUser(v.sess.UserID)
+		if err != nil {
+			log.Printf("Błąd pobierania użytkownika %s do szablonu: %v", v.sess.UserID, err)
+			return
+		}
+		v.user = user
+	})
+	return v.user
+}
+
+// TotalFines zwraca sumę kar do zapłaty
+func (v *Viewer) TotalFines() models.Money {
+	return v.User().TotalFines
+}
+
+// CanBorrow sprawdza czy użytkownik może teraz wypożyczyć książkę
+func (v *Viewer) CanBorrow() bool {
+	return v.User().CanBorrow()
+}
+
+// ActiveLoans zwraca liczbę trwających wypożyczeń (z zamówionymi do odbioru)
+func (v *Viewer) ActiveLoans() int {
+	v.loadLoans()
+	return v.activeLoans
+}
+
+// OverdueLoans zwraca liczbę wypożyczeń po terminie zwrotu
+func (v *Viewer) OverdueLoans() int {
+	v.loadLoans()
+	return v.overdueLoans
+}
+
+// ActiveReservations zwraca liczbę oczekujących i gotowych do odbioru rezerwacji
+func (v *Viewer) ActiveReservations() int {
+	v.loadReservations()
+	return v.activeReservations
+}
+
+// ReadyReservations zwraca liczbę rezerwacji czekających na półce do odbioru
+func (v *Viewer) ReadyReservations() int {
+	v.loadReservations()
+	return v.readyReservations
+}
+
+// UnreadNotifications zwraca liczbę nieprzeczytanych powiadomień
+func (v *Viewer) UnreadNotifications() int {
+	v.notificationsOnce.Do(func() {
+		if v.fbClient == nil {
+			return
+		}
+		count, err := v.fbClient.Traced(v.ctx).CountUnreadNotifications(v.sess.UserID)
+		if err != nil {
+			log.Printf("Błąd pobierania liczby powiadomień do szablonu: %v", err)
+			return
+		}
+		v.unreadNotifications = count
+	})
+	return v.unreadNotifications
+}
+
+func (v *Viewer) loadLoans() {
+	v.loansOnce.Do(func() {
+		if v.store == nil {
+			return
+		}
+		loans, err := v.store.Loans(v.ctx).GetUserActiveLoans(v.sess.UserID)
+		if err != nil {
+			log.Printf("Błąd pobierania wypożyczeń do szablonu: %v", err)
+			return
+		}
+		v.activeLoans = len(loans)
+		for _, loan := range loans {
+			if loan.IsOverdue() {
+				v.overdueLoans++
+			}
+		}
+	})
+}
+
+func (v *Viewer) loadReservations() {
+	v.reservationsOnce.Do(func() {
+		if v.store == nil {
+			return
+		}
+		reservations, err := v.store.Reservations(v.ctx).GetUserActiveReservations(v.sess.UserID)
+		if err != nil {
+			log.Printf("Błąd pobierania rezerwacji do szablonu: %v", err)
+			return
+		}
+		v.activeReservations = len(reservations)
+		for _, reservation := range reservations {
+			if reservation.Status == models.ReservationStatusReady {
+				v.readyReservations++
+			}
+		}
+	})
+}
+
+// ViewerLoader dołącza do żądań zalogowanych użytkowników Viewer (patrz NewPageData)
+type ViewerLoader struct {
+	store    repository.Store
+	fbClient *firebase.Client
+}
+
+// NewViewerLoader tworzy ładowanie danych użytkownika dla szablonów
+func NewViewerLoader(store repository.Store, fbClient *firebase.Client) *ViewerLoader {
+	return &ViewerLoader{store: store, fbClient: fbClient}
+}
+
+// Middleware dodaje Viewer do kontekstu żądania z sesją (musi działać po SessionMiddleware).
+// Sam Viewer niczego nie pobiera - zapytania wykonują dopiero szablony, które go używają.
+func (l *ViewerLoader) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sess := middleware.GetSessionFromContext(r.Context())
+		if sess == nil || sess.User == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		viewer := &Viewer{sess: sess, store: l.store, fbClient: l.fbClient}
+		ctx := context.WithValue(r.Context(), viewerContextKey{}, viewer)
+		viewer.ctx = ctx
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// viewerFromContext zwraca Viewer ustawiony przez ViewerLoader (nil bez sesji)
+func viewerFromContext(ctx context.Context) *Viewer {
+	viewer, _ := ctx.Value(viewerContextKey{}).(*Viewer)
+	return viewer
+}
+
+// NewPageData tworzy dane szablonu dla żądania: jak NewTemplateData, a dodatkowo
+// "Viewer" z aktualnymi danymi zalogowanego użytkownika (nil dla gości) oraz "Nav"
+// z okruszkami i bieżącą pozycją menu (funkcje staffNav, readerNav i breadcrumbs)
+func NewPageData(r *http.Request) TemplateData {
+	sess := middleware.GetSessionFromContext(r.Context())
+	data := NewTemplateData(sess)
+	page := nav.For(strings.TrimPrefix(r.URL.Path, basepath.Prefix()))
+	page.Librarian = sess != nil && sess.User != nil && sess.User.Role == models.RoleLibrarian
+	data["Nav"] = page
+	if viewer := viewerFromContext(r.Context()); viewer != nil {
+		data["Viewer"] = viewer
+	} else {
+		data["Viewer"] = nil
+	}
+	return data
+}
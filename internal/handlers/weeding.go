@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"html/template"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+)
+
+// weedingDefaultMonths to domyślny okres bez wypożyczeń w raporcie selekcji
+const weedingDefaultMonths = 24
+
+// weedingMonthOptions to okresy do wyboru w raporcie (inne można podać w adresie)
+var weedingMonthOptions = []int{6, 12, 24, 36, 60}
+
+// WeedingHandler obsługuje raport selekcji księgozbioru: tytuły, których nikt
+// nie wypożyczył od N miesięcy
+type WeedingHandler struct {
+	weedingTemplate *template.Template
+	fbClient        *firebase.Client
+}
+
+// NewWeedingHandler tworzy handler raportu selekcji
+func NewWeedingHandler(fbClient *firebase.Client) *WeedingHandler {
+	weedingTmpl, err := template.New("weeding.html").Funcs(templateFuncs(fbClient)).ParseFiles("internal/templates/staff/weeding.html")
+	if err != nil {
+		log.Printf("Błąd ładowania szablonu staff/weeding.html: %v", err)
+	}
+
+	return &WeedingHandler{
+		weedingTemplate: weedingTmpl,
+		fbClient:        fbClient,
+	}
+}
+
+// ShowReport wyświetla raport selekcji (GET /staff/reports/weeding?months=24).
+// Z parametrem format=csv zwraca ten sam raport jako plik CSV.
+func (h *WeedingHandler) ShowReport(w http.ResponseWriter, r *http.Request) {
+	if h.weedingTemplate == nil {
+		http.Error(w, "Szablon nie został załadowany", http.StatusInternalServerError)
+		return
+	}
+	if h.fbClient == nil {
+		http.Error(w, "Baza danych niedostępna", http.StatusInternalServerError)
+		return
+	}
+
+	months := weedingDefaultMonths
+	if value := r.URL.Query().Get("months"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 240 {
+			http.Error(w, "Nieprawidłowa liczba miesięcy", http.StatusBadRequest)
+			return
+		}
+		months = parsed
+	}
+
+	since := time.Now().AddDate(0, -months, 0)
+	books, err := h.candidates(since)
+	if err != nil {
+		log.Printf("Błąd przygotowania raportu selekcji: %v", err)
+		http.Error(w, "Błąd przygotowania raportu", http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "csv" {
+		h.writeCSV(w, books)
+		return
+	}
+
+	copies := 0
+	for _, book := range books {
+		copies += book.TotalCopies
+	}
+
+	data := NewPageData(r)
+	data["Months"] = months
+	data["MonthOptions"] = weedingMonthOptions
+	data["Since"] = since
+	data["Books"] = books
+	data["Copies"] = copies
+
+	if err := h.weedingTemplate.Execute(w, data); err != nil {
+		log.Printf("Błąd renderowania raportu selekcji: %v", err)
+	}
+}
+
+// candidates zwraca książki bez wypożyczeń od podanej daty, najdawniej dodane pierwsze.
+// Pomija książki dodane później - nie miały jeszcze szansy na wypożyczenie.
+func (h *WeedingHandler) candidates(since time.Time) ([]*models.Book, error) {
+	borrowed, err := h.fbClient.GetBorrowedBookIDsSince(since)
+	if err != nil {
+		return nil, err
+	}
+	books, err := h.fbClient.ListBooks()
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []*models.Book
+	for _, book := range books {
+		if !borrowed[book.ID] && book.CreatedAt.Before(since) {
+			candidates = append(candidates, book)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].CreatedAt.Before(candidates[j].CreatedAt)
+	})
+	return candidates, nil
+}
+
+// writeCSV zapisuje raport w formacie otwieranym bezpośrednio przez polskiego Excela
+// (średnik jako separator, BOM UTF-8)
+func (h *WeedingHandler) writeCSV(w http.ResponseWriter, books []*models.Book) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="selekcja-`+time.Now().Format("2006-01-02")+`.csv"`)
+	w.Write([]byte("\xef\xbb\xbf"))
+
+	writer := csv.NewWriter(w)
+	writer.Comma = ';'
+	writer.Write([]string{"Tytuł", "Autor", "ISBN", "Kategoria", "Lokalizacja", "Data dodania", "Egzemplarze", "Dostępne"})
+	for _, book := range books {
+		writer.Write([]string{
+			book.Title,
+			book.Author,
+			book.ISBN,
+			book.Category,
+			book.ShelfLocation,
+			book.CreatedAt.Format("2006-01-02"),
+			strconv.Itoa(book.TotalCopies),
+			strconv.Itoa(book.AvailableCopies),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Printf("Błąd zapisu raportu selekcji CSV: %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+// Package ilsexport przygotowuje eksport czytelników, katalogu i wypożyczeń w formatach
+// narzędzi migracji popularnych zintegrowanych systemów bibliotecznych (ILS), np. Koha:
+// plik importu czytelników, plik egzemplarzy, wypożyczenia w formacie obiegu offline (KOC)
+// oraz zwykłe pliki CSV z wypożyczeniami i należnościami dla pozostałych systemów.
+package ilsexport
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"library-management-system/internal/models"
+)
+
+// Pliki w archiwum eksportu
+const (
+	PatronsFile = "borrowers.csv" // Czytelnicy - kolumny narzędzia importu czytelników Koha
+	ItemsFile   = "items.csv"     // Katalog - jeden wiersz na tytuł z liczbą egzemplarzy
+	IssuesFile  = "issues.koc"    // Aktywne wypożyczenia w formacie obiegu offline Koha
+	LoansFile   = "loans.csv"     // Aktywne wypożyczenia z terminem zwrotu
+	FinesFile   = "fines.csv"     // Nieopłacone należności czytelników
+)
+
+// kocTimeLayout to zapis chwili w pliku obiegu offline (z milisekundami)
+const kocTimeLayout = "2006-01-02 15:04:05 000"
+
+// Data to dane biblioteki do eksportu
+type Data struct {
+	Users []*models.User
+	Books []*models.Book
+	Loans []*models.Loan // Aktywne wypożyczenia
+}
+
+// Write zapisuje archiwum ZIP z plikami eksportu. Czytelnik bez numeru karty jest
+// identyfikowany swoim ID, a książka - kodem z etykiety, ISBN albo ID (w tej kolejności),
+// tak samo we wszystkich plikach.
+func Write(w io.Writer, data *Data, now time.Time) error {
+	archive := zip.NewWriter(w)
+
+	patrons := make(map[string]string)
+	for _, user := range data.Users {
+		if user.Role == models.RoleReader {
+			patrons[user.ID] = PatronKey(user)
+		}
+	}
+	barcodes := make(map[string]string)
+	for _, book := range data.Books {
+		barcodes[book.ID] = Barcode(book)
+	}
+
+	files := []struct {
+		name  string
+		write func(io.Writer) error
+	}{
+		{PatronsFile, func(f io.Writer) error { return writePatrons(f, data.Users) }},
+		{ItemsFile, func(f io.Writer) error { return writeItems(f, data.Books) }},
+		{IssuesFile, func(f io.Writer) error { return writeIssues(f, data.Loans, patrons, barcodes) }},
+		{LoansFile, func(f io.Writer) error { return writeLoans(f, data.Loans, patrons, barcodes) }},
+		{FinesFile, func(f io.Writer) error { return writeFines(f, data.Users) }},
+	}
+	for _, file := range files {
+		f, err := archive.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return fmt.Errorf("błąd tworzenia pliku %s: %w", file.name, err)
+		}
+		if err := file.write(f); err != nil {
+			return fmt.Errorf("błąd zapisu pliku %s: %w", file.name, err)
+		}
+	}
+
+	return archive.Close()
+}
+
+// PatronKey zwraca identyfikator czytelnika w eksporcie: numer karty, a bez niej ID profilu
+func PatronKey(user *models.User) string {
+	if user.CardNumber != "" {
+		return user.CardNumber
+	}
+	return user.ID
+}
+
+// Barcode zwraca kod egzemplarza w eksporcie: kod z etykiety, ISBN albo ID książki.
+// Kod z etykiety i ISBN rozpoznaje także skaner przy ladzie tej aplikacji.
+func Barcode(book *models.Book) string {
+	switch {
+	case book.ShortCode != "":
+		return book.ShortCode
+	case book.ISBN != "":
+		return book.ISBN
+	}
+	return book.ID
+}
+
+// writePatrons zapisuje czytelników w kolumnach importu Koha. Oddział i kategorię
+// czytelnika (branchcode, categorycode) uzupełnia się wartościami domyślnymi przy imporcie.
+func writePatrons(w io.Writer, users []*models.User) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"cardnumber", "surname", "firstname", "email", "phone", "branchcode", "categorycode", "dateenrolled", "borrowernotes"})
+	for _, user := range users {
+		if user.Role != models.RoleReader {
+			continue
+		}
+		var notes string
+		switch {
+		case !user.IsActive:
+			notes = "Konto nieaktywne"
+		case user.PendingApproval:
+			notes = "Konto czeka na zatwierdzenie"
+		}
+		writer.Write([]string{PatronKey(user), user.LastName, user.FirstName, user.Email, user.Phone, "", "", user.CreatedAt.Format("2006-01-02"), notes})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeItems zapisuje katalog z kolumnami rekordu bibliograficznego i egzemplarza Koha
+func writeItems(w io.Writer, books []*models.Book) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"barcode", "isbn", "title", "author", "publishercode", "publicationyear", "itemcallnumber", "location", "copies"})
+	for _, book := range books {
+		year := ""
+		if book.PublicationYear > 0 {
+			year = strconv.Itoa(book.PublicationYear)
+		}
+		writer.Write([]string{Barcode(book), book.ISBN, book.Title, book.Author, book.Publisher, year, book.CallNumber, book.ShelfLocation, strconv.Itoa(book.TotalCopies)})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeIssues zapisuje wypożyczenia w formacie obiegu offline Koha (plik .koc): nagłówek
+// z wersją formatu i po jednym wierszu "chwila, issue, karta, kod egzemplarza" rozdzielonym
+// tabulatorami. Termin zwrotu wyznacza Koha według swoich zasad wypożyczeń.
+func writeIssues(w io.Writer, loans []*models.Loan, patrons, barcodes map[string]string) error {
+	if _, err := io.WriteString(w, "Version=1.0\tGenerator=library-management-system\tGeneratorVersion=1.0\n"); err != nil {
+		return err
+	}
+	for _, loan := range exportedLoans(loans, patrons, barcodes) {
+		line := loan.LoanDate.Format(kocTimeLayout) + "\tissue\t" + patrons[loan.UserID] + "\t" + barcodes[loan.BookID] + "\n"
+		if _, err := io.WriteString(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeLoans zapisuje wypożyczenia z terminem zwrotu (nazwy kolumn jak w tabeli wypożyczeń Koha)
+func writeLoans(w io.Writer, loans []*models.Loan, patrons, barcodes map[string]string) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"cardnumber", "barcode", "title", "issuedate", "date_due", "renewals"})
+	for _, loan := range exportedLoans(loans, patrons, barcodes) {
+		writer.Write([]string{patrons[loan.UserID], barcodes[loan.BookID], loan.BookTitle, loan.LoanDate.Format("2006-01-02"), loan.DueDate.Format("2006-01-02"), strconv.Itoa(loan.Renewals)})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeFines zapisuje nieopłacone należności czytelników (kwota z kropką dziesiętną)
+func writeFines(w io.Writer, users []*models.User) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"cardnumber", "amount", "description"})
+	for _, user := range users {
+		if user.Role != models.RoleReader || user.TotalFines <= 0 {
+			continue
+		}
+		writer.Write([]string{PatronKey(user), user.TotalFines.String(), "Należności przeniesione z poprzedniego systemu"})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// exportedLoans pomija udostępnienia na miejscu (wracają na półkę tego samego dnia)
+// i wypożyczenia, których czytelnika lub książki nie ma w eksporcie
+func exportedLoans(loans []*models.Loan, patrons, barcodes map[string]string) []*models.Loan {
+	var exported []*models.Loan
+	for _, loan := range loans {
+		if loan.IsReadingRoom() {
+			continue
+		}
+		if _, ok := patrons[loan.UserID]; !ok {
+			continue
+		}
+		if _, ok := barcodes[loan.BookID]; !ok {
+			continue
+		}
+		exported = append(exported, loan)
+	}
+	return exported
+}
@@ -0,0 +1,44 @@
+package jobs
+
+import (
+	"log"
+
+	"library-management-system/internal/models"
+	"library-management-system/internal/repository"
+)
+
+// AccrueOverdueFines nalicza kary za wypożyczenia po terminie zwrotu (zadanie nocne):
+// oznacza je statusem overdue, zapisuje w FineAmount karę należną do dziś i dolicza
+// jej przyrost do salda czytelnika, więc dług widać na koncie jeszcze przed zwrotem.
+// Kara wypożyczenia i saldo zmieniają się razem (LoanRepository.AccrueLoanFine), więc
+// ponowne uruchomienie zadania nie nalicza kary drugi raz. Przy zwrocie czytelnik
+// dostaje już tylko różnicę. Udostępnienia na miejscu i kary umorzone w amnestii nie
+// rosną. Zwraca liczbę wypożyczeń, których kara wzrosła.
+func AccrueOverdueFines(loans repository.LoanRepository) (int, error) {
+	overdue, err := loans.GetOverdueLoans()
+	if err != nil {
+		return 0, err
+	}
+
+	accrued := 0
+	for _, loan := range overdue {
+		if loan.IsReadingRoom() || loan.FineWaived {
+			continue
+		}
+
+		fine := loan.CalculateFine()
+		if fine <= loan.FineAmount && loan.Status == models.LoanStatusOverdue {
+			continue
+		}
+
+		increase, err := loans.AccrueLoanFine(loan.ID, fine)
+		if err != nil {
+			log.Printf("Błąd naliczania kary za wypożyczenie %s: %v", loan.ID, err)
+			continue
+		}
+		if increase > 0 {
+			accrued++
+		}
+	}
+	return accrued, nil
+}
@@ -0,0 +1,126 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"library-management-system/internal/models"
+	"library-management-system/internal/repository/memory"
+)
+
+func TestAccrueOverdueFinesChargesOnce(t *testing.T) {
+	store := memory.NewStore([]models.Book{{ID: "b1", Title: "Lalka", Author: "Bolesław Prus", TotalCopies: 1}})
+	user := &models.User{ID: "u1", FirstName: "Anna", LastName: "Nowak", Role: models.RoleReader, IsActive: true}
+	if err := store.UpdateUser(user.ID, user); err != nil {
+		t.Fatal(err)
+	}
+
+	loan := &models.Loan{BookID: "b1", UserID: user.ID}
+	if err := store.CreateLoan(loan); err != nil {
+		t.Fatal(err)
+	}
+	// Odebrana książka z terminem zwrotu sprzed trzech dni
+	loan.Status = models.LoanStatusActive
+	loan.DueDate = time.Now().Add(-3*24*time.Hour - time.Hour)
+	if err := store.UpdateLoan(loan.ID, loan); err != nil {
+		t.Fatal(err)
+	}
+
+	want := 3 * models.FinePerDay
+	for run := 1; run <= 2; run++ {
+		n, err := AccrueOverdueFines(store)
+		if err != nil {
+			t.Fatalf("uruchomienie %d: %v", run, err)
+		}
+		// Drugie uruchomienie tego samego dnia nie ma nic do naliczenia
+		wantAccrued := 0
+		if run == 1 {
+			wantAccrued = 1
+		}
+		if n != wantAccrued {
+			t.Errorf("uruchomienie %d: kara wzrosła dla %d wypożyczeń, oczekiwano %d", run, n, wantAccrued)
+		}
+
+		stored, err := store.GetLoan(loan.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored.Status != models.LoanStatusOverdue || stored.FineAmount != want {
+			t.Errorf("uruchomienie %d: wypożyczenie %s z karą %d, oczekiwano %s z karą %d",
+				run, stored.Status, stored.FineAmount, models.LoanStatusOverdue, want)
+		}
+
+		reader, err := store.GetUser(user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reader.TotalFines != want {
+			t.Errorf("uruchomienie %d: saldo kar %d, oczekiwano %d", run, reader.TotalFines, want)
+		}
+	}
+}
+
+func TestAccrueOverdueFinesSkipsReadingRoom(t *testing.T) {
+	store := memory.NewStore([]models.Book{{ID: "b1", Title: "Lalka", Author: "Bolesław Prus", TotalCopies: 1}})
+	user := &models.User{ID: "u1", Role: models.RoleReader, IsActive: true}
+	if err := store.UpdateUser(user.ID, user); err != nil {
+		t.Fatal(err)
+	}
+
+	loan := &models.Loan{BookID: "b1", UserID: user.ID}
+	if err := store.CreateLoan(loan); err != nil {
+		t.Fatal(err)
+	}
+	loan.Status = models.LoanStatusActive
+	loan.Type = models.LoanTypeReadingRoom
+	loan.DueDate = time.Now().Add(-48 * time.Hour)
+	if err := store.UpdateLoan(loan.ID, loan); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := AccrueOverdueFines(store); err != nil || n != 0 {
+		t.Errorf("AccrueOverdueFines = %d, %v; udostępnienie na miejscu nie ma kar", n, err)
+	}
+	if reader, _ := store.GetUser(user.ID); reader.TotalFines != 0 {
+		t.Errorf("saldo kar %d, oczekiwano 0", reader.TotalFines)
+	}
+}
+
+func TestAccrueOverdueFinesSkipsWaivedFines(t *testing.T) {
+	store := memory.NewStore([]models.Book{{ID: "b1", Title: "Lalka", Author: "Bolesław Prus", TotalCopies: 1}})
+	user := &models.User{ID: "u1", Role: models.RoleReader, IsActive: true}
+	if err := store.UpdateUser(user.ID, user); err != nil {
+		t.Fatal(err)
+	}
+
+	loan := &models.Loan{BookID: "b1", UserID: user.ID}
+	if err := store.CreateLoan(loan); err != nil {
+		t.Fatal(err)
+	}
+	// Kara za dwa dni umorzona w amnestii, a książka wciąż nie wróciła
+	loan.Status = models.LoanStatusOverdue
+	loan.DueDate = time.Now().Add(-5*24*time.Hour - time.Hour)
+	loan.FineAmount = 2 * models.FinePerDay
+	loan.FineWaived = true
+	if err := store.UpdateLoan(loan.ID, loan); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := AccrueOverdueFines(store); err != nil || n != 0 {
+		t.Errorf("AccrueOverdueFines = %d, %v; umorzona kara nie powinna rosnąć", n, err)
+	}
+	if increase, err := store.AccrueLoanFine(loan.ID, 5*models.FinePerDay); err != nil || increase != 0 {
+		t.Errorf("AccrueLoanFine = %d, %v; umorzona kara nie powinna rosnąć", increase, err)
+	}
+
+	stored, err := store.GetLoan(loan.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.FineAmount != 2*models.FinePerDay {
+		t.Errorf("kara wypożyczenia %d, oczekiwano %d", stored.FineAmount, 2*models.FinePerDay)
+	}
+	if reader, _ := store.GetUser(user.ID); reader.TotalFines != 0 {
+		t.Errorf("saldo kar %d, oczekiwano 0", reader.TotalFines)
+	}
+}
@@ -0,0 +1,61 @@
+// Package jobs uruchamia zadania okresowe w tle (np. nocny reset wersji demonstracyjnej)
+// oraz zadania działające na repozytoriach danych (np. naliczanie kar za przetrzymanie)
+package jobs
+
+import (
+	"log"
+	"time"
+)
+
+// job to zadanie uruchamiane codziennie o stałej godzinie (czas lokalny serwera)
+type job struct {
+	name   string
+	hour   int
+	minute int
+	run    func() error
+}
+
+// Scheduler przechowuje zarejestrowane zadania
+type Scheduler struct {
+	jobs []job
+}
+
+// NewScheduler tworzy pusty harmonogram zadań
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// Daily rejestruje zadanie uruchamiane codziennie o podanej godzinie
+func (s *Scheduler) Daily(name string, hour, minute int, run func() error) {
+	s.jobs = append(s.jobs, job{name: name, hour: hour, minute: minute, run: run})
+}
+
+// Start uruchamia wszystkie zarejestrowane zadania w osobnych goroutine
+func (s *Scheduler) Start() {
+	for _, j := range s.jobs {
+		go j.loop()
+	}
+}
+
+func (j job) loop() {
+	for {
+		next := nextRun(time.Now(), j.hour, j.minute)
+		time.Sleep(time.Until(next))
+
+		start := time.Now()
+		if err := j.run(); err != nil {
+			log.Printf("Błąd zadania %s: %v", j.name, err)
+			continue
+		}
+		log.Printf("Zadanie %s zakończone w %s", j.name, time.Since(start).Round(time.Millisecond))
+	}
+}
+
+// nextRun zwraca najbliższy moment o podanej godzinie (dziś lub jutro)
+func nextRun(now time.Time, hour, minute int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
@@ -0,0 +1,225 @@
+// Package lockers integruje bibliotekę z systemem paczkomatów (skrytek na książki).
+// Gdy rezerwacja z miejscem odbioru w paczkomacie jest gotowa, biblioteka prosi system
+// o przydzielenie skrytki i wysyła czytelnikowi kod otwarcia. System paczkomatów zgłasza
+// odbiór webhookiem, a biblioteka zamienia rezerwację w aktywne wypożyczenie.
+package lockers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"library-management-system/internal/events"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+	"library-management-system/internal/notifications"
+)
+
+// SignatureHeader to nagłówek z podpisem HMAC-SHA256 treści webhooka ("sha256=<hex>")
+const SignatureHeader = "X-Locker-Signature"
+
+// maxWebhookBody ogranicza rozmiar treści webhooka
+const maxWebhookBody = 64 << 10
+
+// Config to ustawienia integracji z systemem paczkomatów
+type Config struct {
+	APIURL        string // Adres API systemu paczkomatów
+	APIToken      string // Token wysyłany w nagłówku Authorization
+	WebhookSecret string // Klucz podpisu webhooków
+	Location      string // Miejsce odbioru (z ustawień biblioteki) obsługiwane przez paczkomat
+}
+
+// ConfigFromEnv wczytuje konfigurację z LOCKER_API_URL, LOCKER_API_TOKEN,
+// LOCKER_WEBHOOK_SECRET i LOCKER_LOCATION. Bez LOCKER_API_URL integracja jest wyłączona (nil).
+func ConfigFromEnv() (*Config, error) {
+	cfg := &Config{
+		APIURL:        strings.TrimRight(os.Getenv("LOCKER_API_URL"), "/"),
+		APIToken:      os.Getenv("LOCKER_API_TOKEN"),
+		WebhookSecret: os.Getenv("LOCKER_WEBHOOK_SECRET"),
+		Location:      os.Getenv("LOCKER_LOCATION"),
+	}
+	if cfg.APIURL == "" {
+		return nil, nil
+	}
+	if cfg.WebhookSecret == "" || cfg.Location == "" {
+		return nil, fmt.Errorf("LOCKER_API_URL wymaga ustawienia LOCKER_WEBHOOK_SECRET i LOCKER_LOCATION")
+	}
+	return cfg, nil
+}
+
+// Assignment to skrytka przydzielona rezerwacji przez system paczkomatów
+type Assignment struct {
+	LockerID string `json:"locker_id"`
+	OpenCode string `json:"open_code"`
+}
+
+// Service łączy rezerwacje jednej biblioteki z systemem paczkomatów
+type Service struct {
+	cfg        *Config
+	fbClient   *firebase.Client
+	dispatcher *notifications.Dispatcher
+	http       *http.Client
+}
+
+// NewService tworzy integrację z paczkomatami dla biblioteki
+func NewService(cfg *Config, fbClient *firebase.Client, dispatcher *notifications.Dispatcher) *Service {
+	return &Service{
+		cfg:        cfg,
+		fbClient:   fbClient,
+		dispatcher: dispatcher,
+		http:       &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Register subskrybuje gotowe rezerwacje biblioteki z miejscem odbioru w paczkomacie
+func (s *Service) Register() {
+	events.Subscribe(events.ReservationReady, func(e events.Event) {
+		reservation, ok := e.Payload.(*models.Reservation)
+		if !ok || e.Tenant != s.fbClient.Tenant() || reservation.PickupLocation != s.cfg.Location {
+			return
+		}
+		if err := s.assignLocker(reservation); err != nil {
+			log.Printf("Błąd przydzielania skrytki rezerwacji %s: %v", reservation.ID, err)
+		}
+	})
+}
+
+// assignLocker prosi system paczkomatów o skrytkę i wysyła czytelnikowi kod otwarcia
+func (s *Service) assignLocker(reservation *models.Reservation) error {
+	assignment, err := s.requestAssignment(reservation)
+	if err != nil {
+		return err
+	}
+	if err := s.fbClient.SetReservationLocker(reservation.ID, assignment.LockerID, assignment.OpenCode); err != nil {
+		return err
+	}
+
+	user, err := s.fbClient.GetUser(reservation.UserID)
+	if err != nil {
+		return err
+	}
+	s.dispatcher.Notify(user, notifications.Message{
+		Title: "Kod do skrytki: " + reservation.BookTitle,
+		Body: "Książka " + reservation.BookTitle + " czeka w skrytce " + assignment.LockerID + " (" + s.cfg.Location + "). " +
+			"Kod otwarcia: " + assignment.OpenCode + ". Odbierz ją do " + reservation.ExpiryDate.Format("02.01.2006") + ".",
+		Link:     "/user/reservations",
+		Category: models.NotifyReservations,
+		Email:    models.EmailLockerCode,
+		Vars: map[string]string{
+			"tytul":   reservation.BookTitle,
+			"skrytka": assignment.LockerID,
+			"miejsce": s.cfg.Location,
+			"kod":     assignment.OpenCode,
+			"termin":  reservation.ExpiryDate.Format("02.01.2006"),
+		},
+	})
+
+	log.Printf("Rezerwacja %s czeka w skrytce %s", reservation.ID, assignment.LockerID)
+	return nil
+}
+
+// requestAssignment wysyła żądanie przydziału skrytki (POST {LOCKER_API_URL}/assignments)
+func (s *Service) requestAssignment(reservation *models.Reservation) (*Assignment, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"reservation_id": reservation.ID,
+		"library":        s.fbClient.Tenant(),
+		"book_title":     reservation.BookTitle,
+		"reader_name":    reservation.UserName,
+		"expires_at":     reservation.ExpiryDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.APIURL+"/assignments", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.cfg.APIToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.cfg.APIToken)
+	}
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("błąd połączenia z systemem paczkomatów: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("system paczkomatów odpowiedział statusem %d", resp.StatusCode)
+	}
+
+	var assignment Assignment
+	if err := json.NewDecoder(resp.Body).Decode(&assignment); err != nil {
+		return nil, fmt.Errorf("błąd parsowania odpowiedzi systemu paczkomatów: %w", err)
+	}
+	if assignment.LockerID == "" || assignment.OpenCode == "" {
+		return nil, fmt.Errorf("system paczkomatów nie przydzielił skrytki")
+	}
+	return &assignment, nil
+}
+
+// webhookEvent to zgłoszenie z systemu paczkomatów
+type webhookEvent struct {
+	Event         string `json:"event"` // "picked_up" - czytelnik odebrał książkę
+	ReservationID string `json:"reservation_id"`
+	LockerID      string `json:"locker_id"`
+}
+
+// Webhook przyjmuje zgłoszenia z systemu paczkomatów (POST /webhooks/lockers)
+func (s *Service) Webhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBody))
+	if err != nil {
+		http.Error(w, "Błąd odczytu żądania", http.StatusBadRequest)
+		return
+	}
+	if !s.validSignature(body, r.Header.Get(SignatureHeader)) {
+		http.Error(w, "Nieprawidłowy podpis", http.StatusUnauthorized)
+		return
+	}
+
+	var event webhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		http.Error(w, "Nieprawidłowy format zgłoszenia", http.StatusBadRequest)
+		return
+	}
+
+	switch event.Event {
+	case "picked_up":
+		if err := s.fbClient.CompleteLockerPickup(event.ReservationID); err != nil {
+			log.Printf("Błąd obsługi odbioru ze skrytki %s: %v", event.LockerID, err)
+			http.Error(w, "Błąd obsługi odbioru", http.StatusUnprocessableEntity)
+			return
+		}
+		log.Printf("Odebrano rezerwację %s ze skrytki %s", event.ReservationID, event.LockerID)
+	default:
+		// Inne zdarzenia (np. włożenie książki) nie zmieniają stanu biblioteki
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validSignature sprawdza podpis HMAC-SHA256 treści webhooka
+func (s *Service) validSignature(body []byte, header string) bool {
+	signature, err := hex.DecodeString(strings.TrimPrefix(header, "sha256="))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(s.cfg.WebhookSecret))
+	mac.Write(body)
+	return hmac.Equal(signature, mac.Sum(nil))
+}
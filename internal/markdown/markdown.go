@@ -0,0 +1,152 @@
+package markdown
+
+import (
+	"html"
+	"html/template"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Obsługiwany podzbiór Markdown:
+//   - nagłówki (#, ##, ###)
+//   - listy punktowane (-, *) i numerowane (1.)
+//   - cytaty (>)
+//   - **pogrubienie**, *kursywa*, `kod`
+//   - linki [tekst](https://...) - tylko http, https, mailto i ścieżki względne
+//
+// Surowy HTML nigdy nie jest przepuszczany - cała treść jest najpierw escapowana,
+// a dopiero potem zamieniana na dozwolone znaczniki.
+
+var (
+	boldPattern   = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	italicPattern = regexp.MustCompile(`\*([^*]+)\*`)
+	codePattern   = regexp.MustCompile("`([^`]+)`")
+	// Adres może zawierać pary nawiasów, np. https://pl.wikipedia.org/wiki/Wiedźmin_(saga)
+	linkPattern        = regexp.MustCompile(`\[([^\]]+)\]\(((?:[^()\s]|\([^()\s]*\))+)\)`)
+	orderedItem        = regexp.MustCompile(`^\d+\.\s+`)
+	placeholderPattern = regexp.MustCompile("\x00(\\d+)\x00")
+)
+
+// Render zamienia tekst Markdown na bezpieczny HTML
+func Render(source string) template.HTML {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	lines := strings.Split(source, "\n")
+
+	var out strings.Builder
+	var paragraph []string
+	listTag := ""
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			out.WriteString("<p>" + strings.Join(paragraph, "<br>") + "</p>\n")
+			paragraph = nil
+		}
+	}
+	closeList := func() {
+		if listTag != "" {
+			out.WriteString("</" + listTag + ">\n")
+			listTag = ""
+		}
+	}
+	openList := func(tag string) {
+		if listTag != tag {
+			closeList()
+			out.WriteString("<" + tag + ">\n")
+			listTag = tag
+		}
+	}
+
+	for _, raw := range lines {
+		line := strings.TrimSpace(raw)
+
+		switch {
+		case line == "":
+			flushParagraph()
+			closeList()
+		case strings.HasPrefix(line, "### "):
+			flushParagraph()
+			closeList()
+			out.WriteString("<h3>" + inline(line[4:]) + "</h3>\n")
+		case strings.HasPrefix(line, "## "):
+			flushParagraph()
+			closeList()
+			out.WriteString("<h2>" + inline(line[3:]) + "</h2>\n")
+		case strings.HasPrefix(line, "# "):
+			flushParagraph()
+			closeList()
+			out.WriteString("<h1>" + inline(line[2:]) + "</h1>\n")
+		case strings.HasPrefix(line, "> "):
+			flushParagraph()
+			closeList()
+			out.WriteString("<blockquote>" + inline(line[2:]) + "</blockquote>\n")
+		case strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* "):
+			flushParagraph()
+			openList("ul")
+			out.WriteString("<li>" + inline(line[2:]) + "</li>\n")
+		case orderedItem.MatchString(line):
+			flushParagraph()
+			openList("ol")
+			out.WriteString("<li>" + inline(orderedItem.ReplaceAllString(line, "")) + "</li>\n")
+		default:
+			closeList()
+			paragraph = append(paragraph, inline(line))
+		}
+	}
+
+	flushParagraph()
+	closeList()
+
+	return template.HTML(out.String())
+}
+
+// inline escapuje tekst i stosuje formatowanie w obrębie linii.
+// Gotowe fragmenty (kod, linki) są zastępowane znacznikami zastępczymi,
+// żeby pogrubienie i kursywa nie trafiały do ich wnętrza ani do atrybutu href.
+func inline(text string) string {
+	text = html.EscapeString(strings.ReplaceAll(text, "\x00", ""))
+
+	var protected []string
+	protect := func(fragment string) string {
+		protected = append(protected, fragment)
+		return "\x00" + strconv.Itoa(len(protected)-1) + "\x00"
+	}
+
+	text = codePattern.ReplaceAllStringFunc(text, func(match string) string {
+		return protect("<code>" + codePattern.FindStringSubmatch(match)[1] + "</code>")
+	})
+	text = linkPattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := linkPattern.FindStringSubmatch(match)
+		label, href := emphasis(parts[1]), html.UnescapeString(parts[2])
+		if !isSafeURL(href) {
+			return label
+		}
+		return protect(`<a href="` + html.EscapeString(href) + `" rel="nofollow noopener" target="_blank">` + label + `</a>`)
+	})
+	text = emphasis(text)
+
+	// Link może zawierać kod w etykiecie, więc podmieniamy aż do skutku
+	for placeholderPattern.MatchString(text) {
+		text = placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+			i, _ := strconv.Atoi(placeholderPattern.FindStringSubmatch(match)[1])
+			return protected[i]
+		})
+	}
+
+	return text
+}
+
+// emphasis stosuje pogrubienie i kursywę
+func emphasis(text string) string {
+	text = boldPattern.ReplaceAllString(text, "<strong>$1</strong>")
+	return italicPattern.ReplaceAllString(text, "<em>$1</em>")
+}
+
+// isSafeURL dopuszcza tylko linki http(s), mailto oraz ścieżki względne
+func isSafeURL(href string) bool {
+	lower := strings.ToLower(href)
+	return strings.HasPrefix(lower, "http://") ||
+		strings.HasPrefix(lower, "https://") ||
+		strings.HasPrefix(lower, "mailto:") ||
+		(strings.HasPrefix(lower, "/") && !strings.HasPrefix(lower, "//"))
+}
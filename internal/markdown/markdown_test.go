@@ -0,0 +1,171 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderEscapesRawHTML(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		forbidden []string
+	}{
+		{"script", "<script>alert(1)</script>", []string{"<script"}},
+		{"img onerror", `<img src=x onerror="alert(1)">`, []string{"<img"}},
+		{"html w nagłówku", "# <b>tytuł</b>", []string{"<b>"}},
+		{"html w liście", "- <iframe src=x></iframe>", []string{"<iframe"}},
+		{"html w etykiecie linku", "[<svg onload=x>](https://example.com)", []string{"<svg"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(Render(tt.input))
+			for _, f := range tt.forbidden {
+				if strings.Contains(got, f) {
+					t.Errorf("Render(%q) = %q, nie powinno zawierać %q", tt.input, got, f)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderRejectsUnsafeLinks(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"javascript", "[x](javascript:alert(1))"},
+		{"mieszana wielkość liter", "[x](JaVaScRiPt:alert(1))"},
+		{"data", "[x](data:text/html;base64,PHNjcmlwdD4=)"},
+		{"protocol-relative", "[x](//evil.example.com)"},
+		{"encje dziesiętne", "[x](&#106;avascript:alert(1))"},
+		{"encje szesnastkowe", "[x](&#x6A;avascript:alert(1))"},
+		{"encja dwukropka", "[x](javascript&colon;alert(1))"},
+		{"vbscript", "[x](vbscript:msgbox(1))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(Render(tt.input))
+			if strings.Contains(got, "<a ") {
+				t.Errorf("Render(%q) = %q, link nie powinien zostać wyrenderowany", tt.input, got)
+			}
+		})
+	}
+}
+
+func TestRenderLinks(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			"https",
+			"[Biblioteka](https://example.com)",
+			`<a href="https://example.com" rel="nofollow noopener" target="_blank">Biblioteka</a>`,
+		},
+		{
+			"ścieżka względna",
+			"[Katalog](/books)",
+			`<a href="/books" rel="nofollow noopener" target="_blank">Katalog</a>`,
+		},
+		{
+			"mailto",
+			"[Napisz](mailto:biblioteka@example.com)",
+			`<a href="mailto:biblioteka@example.com" rel="nofollow noopener" target="_blank">Napisz</a>`,
+		},
+		{
+			"cudzysłów w adresie",
+			`[x](https://example.com/"onmouseover="alert(1))`,
+			`<a href="https://example.com/&#34;onmouseover=&#34;alert(1)" rel="nofollow noopener" target="_blank">x</a>`,
+		},
+		{
+			"nawiasy w adresie",
+			"[saga](https://pl.wikipedia.org/wiki/Wiedźmin_(saga))",
+			`<a href="https://pl.wikipedia.org/wiki/Wiedźmin_(saga)" rel="nofollow noopener" target="_blank">saga</a>`,
+		},
+		{
+			"ampersand w adresie",
+			"[x](https://example.com/?a=1&b=2)",
+			`<a href="https://example.com/?a=1&amp;b=2" rel="nofollow noopener" target="_blank">x</a>`,
+		},
+		{
+			"gwiazdki w adresie",
+			"[x](https://example.com/*a*)",
+			`<a href="https://example.com/*a*" rel="nofollow noopener" target="_blank">x</a>`,
+		},
+		{
+			"pogrubienie w etykiecie",
+			"[**ważne**](https://example.com)",
+			`<a href="https://example.com" rel="nofollow noopener" target="_blank"><strong>ważne</strong></a>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(Render(tt.input))
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("Render(%q) = %q, oczekiwano fragmentu %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderInlineFormatting(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"pogrubienie", "**tekst**", "<p><strong>tekst</strong></p>\n"},
+		{"kursywa", "*tekst*", "<p><em>tekst</em></p>\n"},
+		{"kod", "`kod`", "<p><code>kod</code></p>\n"},
+		{"gwiazdki w kodzie", "`a*b*c`", "<p><code>a*b*c</code></p>\n"},
+		{"pogrubienie w kodzie", "`**x**`", "<p><code>**x**</code></p>\n"},
+		{
+			"gwiazdki w dwóch linkach",
+			"[a](https://a.example/*) i [b](https://b.example/*)",
+			`<p><a href="https://a.example/*" rel="nofollow noopener" target="_blank">a</a> i ` +
+				`<a href="https://b.example/*" rel="nofollow noopener" target="_blank">b</a></p>` + "\n",
+		},
+		{"bajt zerowy", "a\x000\x00b", "<p>a0b</p>\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(Render(tt.input)); got != tt.want {
+				t.Errorf("Render(%q) = %q, oczekiwano %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderBlocks(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"nagłówek h1", "# Tytuł", "<h1>Tytuł</h1>\n"},
+		{"nagłówek h2", "## Tytuł", "<h2>Tytuł</h2>\n"},
+		{"nagłówek h3", "### Tytuł", "<h3>Tytuł</h3>\n"},
+		{"cytat", "> cytat", "<blockquote>cytat</blockquote>\n"},
+		{"lista punktowana", "- a\n* b", "<ul>\n<li>a</li>\n<li>b</li>\n</ul>\n"},
+		{"lista numerowana", "1. a\n2. b", "<ol>\n<li>a</li>\n<li>b</li>\n</ol>\n"},
+		{"zmiana typu listy", "- a\n1. b", "<ul>\n<li>a</li>\n</ul>\n<ol>\n<li>b</li>\n</ol>\n"},
+		{"akapity", "pierwszy\ndrugi\n\ntrzeci", "<p>pierwszy<br>drugi</p>\n<p>trzeci</p>\n"},
+		{"końce linii CRLF", "a\r\n\r\nb", "<p>a</p>\n<p>b</p>\n"},
+		{"akapit po liście", "- a\n\ntekst", "<ul>\n<li>a</li>\n</ul>\n<p>tekst</p>\n"},
+		{"pusty tekst", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(Render(tt.input)); got != tt.want {
+				t.Errorf("Render(%q) = %q, oczekiwano %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
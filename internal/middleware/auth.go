@@ -6,8 +6,10 @@ import (
 	"net/http"
 	"strings"
 
-	"library-management-system/internal/firebase"
+	"firebase.google.com/go/v4/auth"
+
 	"library-management-system/internal/models"
+	"library-management-system/internal/repository"
 )
 
 // Klucze do przechowywania wartości w context
@@ -19,93 +21,103 @@ const (
 	UserKey     contextKey = "user"
 )
 
-// AuthMiddleware weryfikuje token Firebase i dodaje dane użytkownika do kontekstu
-func AuthMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Pobierz token z nagłówka Authorization
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			http.Error(w, "Brak nagłówka Authorization", http.StatusUnauthorized)
-			return
-		}
-
-		// Sprawdź format: "Bearer <token>"
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			http.Error(w, "Nieprawidłowy format Authorization", http.StatusUnauthorized)
-			return
-		}
-
-		token := parts[1]
-
-		// Weryfikuj token przez Firebase Admin SDK
-		if firebase.GlobalClient == nil {
-			http.Error(w, "Firebase nie został zainicjalizowany", http.StatusInternalServerError)
-			return
-		}
-
-		decodedToken, err := firebase.GlobalClient.Auth.VerifyIDToken(r.Context(), token)
-		if err != nil {
-			http.Error(w, fmt.Sprintf("Nieprawidłowy token: %v", err), http.StatusUnauthorized)
-			return
-		}
-
-		// Pobierz dane użytkownika z bazy na podstawie Firebase UID
-		user, err := firebase.GlobalClient.GetUserByFirebaseUID(decodedToken.UID)
-		if err != nil {
-			http.Error(w, "Użytkownik nie został znaleziony", http.StatusUnauthorized)
-			return
-		}
-
-		// Sprawdź czy użytkownik jest aktywny
-		if !user.IsActive {
-			http.Error(w, "Konto użytkownika jest nieaktywne", http.StatusForbidden)
-			return
-		}
-
-		// Dodaj dane użytkownika do kontekstu
-		ctx := context.WithValue(r.Context(), UserUIDKey, decodedToken.UID)
-		ctx = context.WithValue(ctx, UserRoleKey, user.Role)
-		ctx = context.WithValue(ctx, UserKey, user)
-
-		// Przekaż żądanie dalej z nowym kontekstem
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+// IDTokenVerifier weryfikuje tokeny ID Firebase Auth (spełnia go auth.Client z Firebase Admin SDK)
+type IDTokenVerifier interface {
+	VerifyIDToken(ctx context.Context, idToken string) (*auth.Token, error)
 }
 
-// OptionalAuthMiddleware próbuje uwierzytelnić użytkownika, ale nie wymaga tego
-func OptionalAuthMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			next.ServeHTTP(w, r)
-			return
-		}
+// AuthMiddleware zwraca middleware, które weryfikuje token Firebase i dodaje dane użytkownika
+// do kontekstu. Konto użytkownika jest odczytywane z repozytoriów store.
+func AuthMiddleware(verifier IDTokenVerifier, store repository.Store) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Pobierz token z nagłówka Authorization
+			authHeader := r.Header.Get("Authorization")
+			if authHeader == "" {
+				http.Error(w, "Brak nagłówka Authorization", http.StatusUnauthorized)
+				return
+			}
 
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			next.ServeHTTP(w, r)
-			return
-		}
-
-		token := parts[1]
-
-		if firebase.GlobalClient != nil {
-			decodedToken, err := firebase.GlobalClient.Auth.VerifyIDToken(r.Context(), token)
-			if err == nil {
-				user, err := firebase.GlobalClient.GetUserByFirebaseUID(decodedToken.UID)
-				if err == nil && user.IsActive {
-					ctx := context.WithValue(r.Context(), UserUIDKey, decodedToken.UID)
-					ctx = context.WithValue(ctx, UserRoleKey, user.Role)
-					ctx = context.WithValue(ctx, UserKey, user)
-					next.ServeHTTP(w, r.WithContext(ctx))
-					return
+			// Sprawdź format: "Bearer <token>"
+			parts := strings.Split(authHeader, " ")
+			if len(parts) != 2 || parts[0] != "Bearer" {
+				http.Error(w, "Nieprawidłowy format Authorization", http.StatusUnauthorized)
+				return
+			}
+
+			token := parts[1]
+
+			// Weryfikuj token przez Firebase Admin SDK
+			if verifier == nil || store == nil {
+				http.Error(w, "Firebase nie został zainicjalizowany", http.StatusInternalServerError)
+				return
+			}
+
+			decodedToken, err := verifier.VerifyIDToken(r.Context(), token)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Nieprawidłowy token: %v", err), http.StatusUnauthorized)
+				return
+			}
+
+			// Pobierz dane użytkownika z bazy na podstawie Firebase UID
+			user, err := store.Users(r.Context()).GetUserByFirebaseUID(decodedToken.UID)
+			if err != nil {
+				http.Error(w, "Użytkownik nie został znaleziony", http.StatusUnauthorized)
+				return
+			}
+
+			// Sprawdź czy użytkownik jest aktywny
+			if !user.IsActive {
+				http.Error(w, "Konto użytkownika jest nieaktywne", http.StatusForbidden)
+				return
+			}
+
+			// Dodaj dane użytkownika do kontekstu
+			ctx := context.WithValue(r.Context(), UserUIDKey, decodedToken.UID)
+			ctx = context.WithValue(ctx, UserRoleKey, user.Role)
+			ctx = context.WithValue(ctx, UserKey, user)
+
+			// Przekaż żądanie dalej z nowym kontekstem
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// OptionalAuthMiddleware działa jak AuthMiddleware, ale nie wymaga uwierzytelnienia
+func OptionalAuthMiddleware(verifier IDTokenVerifier, store repository.Store) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authHeader := r.Header.Get("Authorization")
+			if authHeader == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			parts := strings.Split(authHeader, " ")
+			if len(parts) != 2 || parts[0] != "Bearer" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token := parts[1]
+
+			if verifier != nil && store != nil {
+				decodedToken, err := verifier.VerifyIDToken(r.Context(), token)
+				if err == nil {
+					user, err := store.Users(r.Context()).GetUserByFirebaseUID(decodedToken.UID)
+					if err == nil && user.IsActive {
+						ctx := context.WithValue(r.Context(), UserUIDKey, decodedToken.UID)
+						ctx = context.WithValue(ctx, UserRoleKey, user.Role)
+						ctx = context.WithValue(ctx, UserKey, user)
+						next.ServeHTTP(w, r.WithContext(ctx))
+						return
+					}
 				}
 			}
-		}
 
-		next.ServeHTTP(w, r)
-	})
+			next.ServeHTTP(w, r)
+		})
+	}
 }
 
 // RequireRole zwraca middleware, który wymaga określonej roli
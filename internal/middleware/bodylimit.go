@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"library-management-system/internal/basepath"
+)
+
+// BodyLimits ogranicza rozmiar treści żądań. Zwykłe formularze dostają mały limit domyślny,
+// a trasy przyjmujące pliki (import CSV, okładki) - większy, ustawiany przez Set.
+// Limit musi być sprawdzany przed DuplicateGuard, który wczytuje całą treść do pamięci.
+type BodyLimits struct {
+	def    int64
+	routes map[string]int64 // Ścieżka (bez prefiksu BASE_PATH) -> limit w bajtach
+}
+
+// NewBodyLimits tworzy limity treści żądań z limitem domyślnym w bajtach
+func NewBodyLimits(def int64) *BodyLimits {
+	return &BodyLimits{def: def, routes: make(map[string]int64)}
+}
+
+// Set ustawia limit dla jednej ścieżki, np. "/staff/users/import"
+func (l *BodyLimits) Set(path string, limit int64) *BodyLimits {
+	l.routes[path] = limit
+	return l
+}
+
+func (l *BodyLimits) limit(r *http.Request) int64 {
+	path := strings.TrimPrefix(r.URL.Path, basepath.Prefix())
+	if limit, ok := l.routes[path]; ok {
+		return limit
+	}
+	return l.def
+}
+
+// Middleware odrzuca żądania z zadeklarowaną treścią większą niż limit trasy, zanim
+// handler zacznie ją czytać. Treść bez Content-Length (chunked) jest ucinana przez
+// http.MaxBytesReader - handler dostaje wtedy *http.MaxBytesError przy odczycie formularza.
+func (l *BodyLimits) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil || r.Body == http.NoBody {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		limit := l.limit(r)
+		if r.ContentLength > limit {
+			bodyTooLarge(w, limit)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// exceededLimit zwraca limit, gdy błąd odczytu treści wynika z jego przekroczenia
+func exceededLimit(err error) (int64, bool) {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return maxErr.Limit, true
+	}
+	return 0, false
+}
+
+// bodyTooLarge odpowiada 413 z komunikatem zrozumiałym dla czytelnika
+func bodyTooLarge(w http.ResponseWriter, limit int64) {
+	msg := fmt.Sprintf("Przesłane dane są za duże (najwyżej %s). Skróć tekst lub wybierz mniejszy plik.", formatSize(limit))
+	http.Error(w, msg, http.StatusRequestEntityTooLarge)
+}
+
+// formatSize zapisuje rozmiar w KB lub MB
+func formatSize(n int64) string {
+	if n >= 1<<20 && n%(1<<20) == 0 {
+		return fmt.Sprintf("%d MB", n>>20)
+	}
+	if n >= 1<<20 {
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	}
+	return fmt.Sprintf("%d KB", (n+1<<10-1)>>10)
+}
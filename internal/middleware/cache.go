@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// CatalogCache nadaje publicznym stronom katalogu ETag zależny od wersji katalogu.
+// Wersja rośnie przy każdej zmianie książek, więc przeglądarka dostaje 304 Not Modified
+// dopóki katalog się nie zmieni.
+type CatalogCache struct {
+	started int64
+	version atomic.Int64
+}
+
+// NewCatalogCache tworzy pamięć wersji katalogu
+func NewCatalogCache() *CatalogCache {
+	// Czas startu odróżnia ETagi po restarcie serwera, gdy licznik wersji zaczyna od zera
+	return &CatalogCache{started: time.Now().Unix()}
+}
+
+// Invalidate oznacza wszystkie zapamiętane strony katalogu jako nieaktualne
+func (c *CatalogCache) Invalidate() {
+	c.version.Add(1)
+}
+
+func (c *CatalogCache) etag() string {
+	return fmt.Sprintf(`W/"%d-%d"`, c.started, c.version.Load())
+}
+
+// Middleware obsługuje warunkowe żądania GET do stron katalogu.
+// Strony zalogowanych użytkowników zawierają dane konta, więc nie dostają ETagu.
+// Odpowiedź 304 pomija handler - ponowne wyświetlenie tej samej wersji strony
+// przez tę samą przeglądarkę nie jest liczone w statystykach.
+func (c *CatalogCache) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Cookie")
+
+		if GetSessionFromContext(r.Context()) != nil {
+			w.Header().Set("Cache-Control", "private, no-cache")
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		etag := c.etag()
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.Header().Set("ETag", etag)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		next.ServeHTTP(&etagWriter{ResponseWriter: w, etag: etag}, r)
+	})
+}
+
+// etagWriter dodaje ETag tylko do udanych odpowiedzi
+type etagWriter struct {
+	http.ResponseWriter
+	etag        string
+	wroteHeader bool
+}
+
+func (w *etagWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if status == http.StatusOK {
+			w.Header().Set("ETag", w.etag)
+			w.Header().Set("Cache-Control", "public, no-cache")
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *etagWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// etagMatches sprawdza nagłówek If-None-Match (może zawierać listę ETagów lub "*")
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
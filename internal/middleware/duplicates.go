@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// DuplicateGuard odrzuca powtórne wysłanie tego samego formularza: to samo żądanie
+// (użytkownik, metoda, ścieżka i treść) w ciągu window od poprzedniego. Chroni czynności,
+// które nie są jeszcze idempotentne, przed podwójnym kliknięciem i ponownym wysłaniem
+// formularza przez przeglądarkę. Dotyczy tylko zalogowanych użytkowników.
+type DuplicateGuard struct {
+	window time.Duration
+
+	mu   sync.Mutex
+	seen map[string]time.Time // Klucz żądania -> chwila pierwszego wysłania
+}
+
+// NewDuplicateGuard tworzy ochronę przed powtórzonymi formularzami
+func NewDuplicateGuard(window time.Duration) *DuplicateGuard {
+	return &DuplicateGuard{window: window, seen: make(map[string]time.Time)}
+}
+
+// Middleware przepuszcza pierwsze wysłanie formularza, a powtórzenia w oknie czasu
+// kończy bez wywołania handlera. Żądanie htmx dostaje 204 (strona zostaje bez zmian),
+// zwykły formularz wraca na stronę, z której go wysłano.
+func (g *DuplicateGuard) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sess := GetSessionFromContext(r.Context())
+		if sess == nil || r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if limit, ok := exceededLimit(err); ok {
+			bodyTooLarge(w, limit)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Błąd odczytu żądania", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		key := duplicateKey(sess.UserID, r, body)
+		if !g.claim(key, time.Now()) {
+			log.Printf("Pominięto powtórzone żądanie %s %s użytkownika %s", r.Method, r.URL.Path, sess.UserID)
+			rejectDuplicate(w, r)
+			return
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		// Po błędzie serwera ponowienie tego samego formularza ma sens
+		if recorder.status >= http.StatusInternalServerError {
+			g.release(key)
+		}
+	})
+}
+
+// claim zapisuje żądanie i zwraca false, gdy to samo żądanie przyszło w ciągu okna czasu
+func (g *DuplicateGuard) claim(key string, now time.Time) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if sent, ok := g.seen[key]; ok && now.Sub(sent) < g.window {
+		return false
+	}
+
+	// W oknie kilku sekund żądań jest niewiele, więc porządki przy każdym zapisie są tanie
+	for k, sent := range g.seen {
+		if now.Sub(sent) >= g.window {
+			delete(g.seen, k)
+		}
+	}
+	g.seen[key] = now
+	return true
+}
+
+func (g *DuplicateGuard) release(key string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.seen, key)
+}
+
+// duplicateKey wylicza klucz żądania z użytkownika, metody, ścieżki i skrótu treści.
+// Przeglądarka losuje separator części multipart przy każdym wysłaniu, więc przed
+// liczeniem skrótu jest on usuwany z treści.
+func duplicateKey(userID string, r *http.Request, body []byte) string {
+	if mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err == nil && mediaType == "multipart/form-data" && params["boundary"] != "" {
+		body = bytes.ReplaceAll(body, []byte(params["boundary"]), nil)
+	}
+
+	hash := sha256.New()
+	for _, part := range []string{userID, r.Method, r.URL.Path, r.URL.RawQuery} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// rejectDuplicate kończy powtórzone żądanie bez wykonywania czynności drugi raz
+func rejectDuplicate(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("HX-Request") == "true" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// Przeglądarka pokazuje odpowiedź na ostatnie wysłanie, więc wracamy na stronę formularza
+	if referer, err := url.Parse(r.Referer()); err == nil && referer.Host == r.Host && referer.Path != "" {
+		http.Redirect(w, r, referer.RequestURI(), http.StatusSeeOther)
+		return
+	}
+	http.Error(w, "Ten formularz został już wysłany - odśwież stronę, aby zobaczyć wynik", http.StatusConflict)
+}
+
+// statusRecorder zapamiętuje kod odpowiedzi handlera
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"library-management-system/internal/session"
+)
+
+// postForm wysyła formularz użytkownika userID przez ochronę przed powtórzeniami
+// i zwraca kod odpowiedzi
+func postForm(handler http.Handler, userID, body string) int {
+	r := httptest.NewRequest(http.MethodPost, "/staff/loans/return", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), sessionContextKey, &session.Session{UserID: userID}))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w.Code
+}
+
+func TestDuplicateGuardBlocksRepeatWithinWindow(t *testing.T) {
+	calls := 0
+	handler := NewDuplicateGuard(3 * time.Second).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+
+	if code := postForm(handler, "u1", "loan_id=l1"); code != http.StatusOK {
+		t.Fatalf("pierwsze wysłanie: kod %d, oczekiwano %d", code, http.StatusOK)
+	}
+	if code := postForm(handler, "u1", "loan_id=l1"); code != http.StatusConflict {
+		t.Errorf("powtórzone wysłanie: kod %d, oczekiwano %d", code, http.StatusConflict)
+	}
+	if calls != 1 {
+		t.Errorf("handler wywołany %d razy, oczekiwano 1", calls)
+	}
+}
+
+func TestDuplicateGuardAllowsDifferentRequests(t *testing.T) {
+	calls := 0
+	handler := NewDuplicateGuard(3 * time.Second).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+
+	postForm(handler, "u1", "loan_id=l1")
+	if code := postForm(handler, "u1", "loan_id=l2"); code != http.StatusOK {
+		t.Errorf("inna treść: kod %d, oczekiwano %d", code, http.StatusOK)
+	}
+	if code := postForm(handler, "u2", "loan_id=l1"); code != http.StatusOK {
+		t.Errorf("inny użytkownik: kod %d, oczekiwano %d", code, http.StatusOK)
+	}
+	if calls != 3 {
+		t.Errorf("handler wywołany %d razy, oczekiwano 3", calls)
+	}
+}
+
+func TestDuplicateGuardWindowExpires(t *testing.T) {
+	guard := NewDuplicateGuard(3 * time.Second)
+	now := time.Now()
+
+	if !guard.claim("k", now) {
+		t.Fatal("pierwsze żądanie zostało odrzucone")
+	}
+	if guard.claim("k", now.Add(2*time.Second)) {
+		t.Error("powtórzenie po 2 s powinno zostać odrzucone")
+	}
+	if !guard.claim("k", now.Add(3*time.Second)) {
+		t.Error("powtórzenie po upływie okna powinno przejść")
+	}
+}
+
+func TestDuplicateKeyIgnoresMultipartBoundary(t *testing.T) {
+	form := func(boundary string) (*http.Request, []byte) {
+		body := "--" + boundary + "\r\nContent-Disposition: form-data; name=\"loan_id\"\r\n\r\nl1\r\n--" + boundary + "--\r\n"
+		r := httptest.NewRequest(http.MethodPost, "/staff/loans/return", strings.NewReader(body))
+		r.Header.Set("Content-Type", "multipart/form-data; boundary="+boundary)
+		return r, []byte(body)
+	}
+
+	first, firstBody := form("AaB03x")
+	second, secondBody := form("Zz9876")
+	if duplicateKey("u1", first, firstBody) != duplicateKey("u1", second, secondBody) {
+		t.Error("ten sam formularz z innym separatorem multipart powinien mieć ten sam klucz")
+	}
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"library-management-system/internal/firebase"
+)
+
+// expensiveRequestReads to liczba odczytów dokumentów, od której pojedyncze żądanie jest logowane
+const expensiveRequestReads = 1000
+
+// endpointCost to suma operacji Firestore jednej trasy
+type endpointCost struct {
+	Route    string
+	Requests int64
+	Reads    int64
+	Writes   int64
+}
+
+// firestoreCosts to koszty tras od ostatniego raportu (wspólne dla wszystkich bibliotek sieci)
+var firestoreCosts = struct {
+	mu     sync.Mutex
+	routes map[string]*endpointCost
+}{routes: make(map[string]*endpointCost)}
+
+// FirestoreCost zlicza odczyty i zapisy dokumentów Firestore wykonane przez żądanie
+// (operacje klienta Traced z kontekstem żądania). Koszt trafia do atrybutów spanu żądania
+// i do sumy trasy raportowanej przez LogFirestoreCosts; żądania powyżej
+// expensiveRequestReads odczytów są logowane od razu.
+func FirestoreCost(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, usage := firebase.WithUsage(r.Context())
+		r = r.WithContext(ctx)
+		next.ServeHTTP(w, r)
+
+		reads, writes := usage.Reads(), usage.Writes()
+		trace.SpanFromContext(ctx).SetAttributes(
+			attribute.Int64("firestore.reads", reads),
+			attribute.Int64("firestore.writes", writes),
+		)
+
+		route := r.URL.Path
+		if rctx := chi.RouteContext(ctx); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		route = r.Method + " " + route
+
+		if reads >= expensiveRequestReads {
+			log.Printf("Kosztowne żądanie %s (%s): %d odczytów, %d zapisów Firestore", route, r.URL.Path, reads, writes)
+		}
+
+		firestoreCosts.mu.Lock()
+		defer firestoreCosts.mu.Unlock()
+		cost, ok := firestoreCosts.routes[route]
+		if !ok {
+			cost = &endpointCost{Route: route}
+			firestoreCosts.routes[route] = cost
+		}
+		cost.Requests++
+		cost.Reads += reads
+		cost.Writes += writes
+	})
+}
+
+// LogFirestoreCosts loguje trasy z największą liczbą odczytów od ostatniego raportu
+// oraz dzienne zużycie Firestore, a potem zeruje sumy tras
+func LogFirestoreCosts(top int) {
+	firestoreCosts.mu.Lock()
+	costs := make([]*endpointCost, 0, len(firestoreCosts.routes))
+	for _, cost := range firestoreCosts.routes {
+		costs = append(costs, cost)
+	}
+	firestoreCosts.routes = make(map[string]*endpointCost)
+	firestoreCosts.mu.Unlock()
+
+	sort.Slice(costs, func(i, j int) bool {
+		return costs[i].Reads > costs[j].Reads
+	})
+	if len(costs) > top {
+		costs = costs[:top]
+	}
+
+	reads, writes, budget := firebase.DailyUsage()
+	if budget > 0 {
+		log.Printf("Firestore dziś: %d odczytów (%d%% budżetu %d), %d zapisów", reads, reads*100/budget, budget, writes)
+	} else {
+		log.Printf("Firestore dziś: %d odczytów, %d zapisów", reads, writes)
+	}
+	for i, cost := range costs {
+		if cost.Reads == 0 {
+			break
+		}
+		log.Printf("  %d. %s: %d odczytów w %d żądaniach (średnio %d), %d zapisów",
+			i+1, cost.Route, cost.Reads, cost.Requests, cost.Reads/cost.Requests, cost.Writes)
+	}
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// TrustedProxies to serwery pośredniczące (reverse proxy, load balancer), którym wolno
+// podać adres klienta w nagłówkach X-Forwarded-For / X-Real-IP. Nagłówki od pozostałych
+// połączeń są ignorowane - klient mógłby w nich wpisać dowolny adres i np. obejść limity API.
+type TrustedProxies struct {
+	nets []*net.IPNet
+}
+
+// TrustedProxiesFromEnv odczytuje zmienną TRUSTED_PROXIES: adresy IP lub sieci CIDR
+// oddzielone przecinkami. Nieprawidłowe wpisy są pomijane z ostrzeżeniem.
+func TrustedProxiesFromEnv() *TrustedProxies {
+	return NewTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+}
+
+// NewTrustedProxies tworzy listę zaufanych serwerów z adresów IP i sieci CIDR oddzielonych przecinkami
+func NewTrustedProxies(value string) *TrustedProxies {
+	p := &TrustedProxies{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			log.Printf("Nieprawidłowy adres w TRUSTED_PROXIES: %q", entry)
+			continue
+		}
+		p.nets = append(p.nets, network)
+	}
+	return p
+}
+
+// trusted sprawdza czy adres należy do zaufanego serwera pośredniczącego
+func (p *TrustedProxies) trusted(ip net.IP) bool {
+	for _, network := range p.nets {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// RealIP ustawia r.RemoteAddr na adres klienta. Nagłówki X-Forwarded-For i X-Real-IP są
+// brane pod uwagę tylko, gdy połączenie przyszło od zaufanego serwera pośredniczącego;
+// w X-Forwarded-For adresem klienta jest ostatni wpis spoza zaufanych serwerów (wcześniejsze
+// wpisy dopisał sam klient).
+func (p *TrustedProxies) RealIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ip := p.clientIP(r); ip != "" {
+			r.RemoteAddr = ip
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientIP zwraca adres klienta z nagłówków zaufanego serwera (pusty, gdy nie ma go skąd wziąć)
+func (p *TrustedProxies) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	peer := net.ParseIP(host)
+	if peer == nil || !p.trusted(peer) {
+		return ""
+	}
+
+	forwarded := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(forwarded[i]))
+		if ip == nil {
+			break
+		}
+		if !p.trusted(ip) {
+			return ip.String()
+		}
+	}
+
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTrustedProxiesRealIP(t *testing.T) {
+	proxies := NewTrustedProxies("127.0.0.1, 10.0.0.0/8, nieprawidłowy")
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		realIP     string
+		want       string
+	}{
+		{"bez proxy", "203.0.113.7:5123", "", "", "203.0.113.7:5123"},
+		{"nagłówek od klienta jest ignorowany", "203.0.113.7:5123", "198.51.100.1", "198.51.100.2", "203.0.113.7:5123"},
+		{"adres od zaufanego proxy", "127.0.0.1:40000", "198.51.100.1", "", "198.51.100.1"},
+		{"wpis dopisany przez klienta jest pomijany", "127.0.0.1:40000", "1.2.3.4, 198.51.100.1", "", "198.51.100.1"},
+		{"łańcuch zaufanych proxy", "10.0.0.5:40000", "198.51.100.1, 10.1.2.3", "", "198.51.100.1"},
+		{"X-Real-IP od zaufanego proxy", "127.0.0.1:40000", "", "198.51.100.2", "198.51.100.2"},
+		{"zaufane proxy bez nagłówków", "127.0.0.1:40000", "", "", "127.0.0.1:40000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/books", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			var got string
+			proxies.RealIP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			})).ServeHTTP(httptest.NewRecorder(), r)
+			if got != tt.want {
+				t.Errorf("RemoteAddr = %q, oczekiwano %q", got, tt.want)
+			}
+		})
+	}
+}
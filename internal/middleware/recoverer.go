@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	chimw "github.com/go-chi/chi/v5/middleware"
+
+	"library-management-system/internal/errorreport"
+	"library-management-system/internal/session"
+	"library-management-system/internal/tenant"
+)
+
+// Recoverer zastępuje middleware.Recoverer z chi: po panic w handlerze loguje stos
+// wywołań, zgłasza błąd do reportera (Sentry / Error Reporting, jeśli skonfigurowany)
+// i odpowiada 500. Reporter może być nil - wtedy błąd jest tylko logowany.
+func Recoverer(reporter *errorreport.Reporter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				rvr := recover()
+				if rvr == nil {
+					return
+				}
+				// Przerwane połączenie - net/http obsługuje je sam i nie jest to błąd aplikacji
+				if rvr == http.ErrAbortHandler {
+					panic(rvr)
+				}
+
+				stack := debug.Stack()
+				log.Printf("panic: %v\n%s", rvr, stack)
+				reporter.Report(panicEvent(r, rvr, stack))
+
+				if r.Header.Get("Connection") != "Upgrade" {
+					http.Error(w, "Wystąpił błąd serwera", http.StatusInternalServerError)
+				}
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// panicEvent zbiera kontekst żądania. Recoverer działa przed SessionMiddleware,
+// więc użytkownik jest odczytywany z cookie sesji.
+func panicEvent(r *http.Request, rvr any, stack []byte) *errorreport.Event {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	e := &errorreport.Event{
+		Message:    fmt.Sprint(rvr),
+		Stack:      string(stack),
+		Time:       time.Now(),
+		Method:     r.Method,
+		URL:        scheme + "://" + r.Host + r.URL.RequestURI(),
+		UserAgent:  r.UserAgent(),
+		Referer:    r.Referer(),
+		RemoteAddr: r.RemoteAddr,
+		RequestID:  chimw.GetReqID(r.Context()),
+		Tenant:     tenant.FromContext(r.Context()),
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		e.RemoteAddr = host
+	}
+	if sess, ok := session.GetSessionFromRequest(r); ok {
+		e.UserID = sess.UserID
+	}
+	return e
+}
@@ -3,18 +3,22 @@ package middleware
 import (
 	"context"
 	"net/http"
+	"slices"
 
+	"library-management-system/internal/basepath"
 	"library-management-system/internal/models"
 	"library-management-system/internal/session"
+	"library-management-system/internal/tenant"
 )
 
 const sessionContextKey contextKey = "session"
 
-// SessionMiddleware dodaje sesję do kontekstu jeśli istnieje
+// SessionMiddleware dodaje sesję do kontekstu jeśli istnieje.
+// Sesja jest ważna tylko w bibliotece sieci, w której użytkownik się zalogował.
 func SessionMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		sess, exists := session.GetSessionFromRequest(r)
-		if exists {
+		if exists && sess.User.Tenant == tenant.FromContext(r.Context()) {
 			ctx := context.WithValue(r.Context(), sessionContextKey, sess)
 			r = r.WithContext(ctx)
 		}
@@ -27,24 +31,24 @@ func RequireAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		sess := GetSessionFromContext(r.Context())
 		if sess == nil {
-			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			basepath.Redirect(w, r, "/login", http.StatusSeeOther)
 			return
 		}
 		next.ServeHTTP(w, r)
 	})
 }
 
-// RequireAuthRole wymaga zalogowania i określonej roli
-func RequireAuthRole(role models.UserRole) func(http.Handler) http.Handler {
+// RequireAuthRole wymaga zalogowania i jednej z podanych ról
+func RequireAuthRole(roles ...models.UserRole) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			sess := GetSessionFromContext(r.Context())
 			if sess == nil {
-				http.Redirect(w, r, "/login", http.StatusSeeOther)
+				basepath.Redirect(w, r, "/login", http.StatusSeeOther)
 				return
 			}
 
-			if sess.User.Role != role {
+			if !slices.Contains(roles, sess.User.Role) {
 				http.Error(w, "Brak uprawnień", http.StatusForbidden)
 				return
 			}
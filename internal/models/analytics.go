@@ -0,0 +1,30 @@
+package models
+
+// AnalyticsKind określa rodzaj zliczanego zdarzenia w statystykach przeglądania
+type AnalyticsKind string
+
+const (
+	AnalyticsSearch           AnalyticsKind = "search"             // Wyszukiwana fraza
+	AnalyticsZeroResultSearch AnalyticsKind = "zero_result_search" // Fraza bez wyników
+	AnalyticsBookView         AnalyticsKind = "book_view"          // Wyświetlenie strony książki
+	AnalyticsBookShare        AnalyticsKind = "book_share"         // Udostępnienie książki (przycisk lub skopiowany link)
+	AnalyticsShareChannel     AnalyticsKind = "share_channel"      // Serwis, przez który udostępniono książkę
+	AnalyticsShareVisit       AnalyticsKind = "share_visit"        // Wejście na stronę książki z udostępnionego linku
+)
+
+// AnalyticsCounter to dzienny licznik zdarzeń jednego rodzaju dla jednego klucza.
+// Nie zawiera danych identyfikujących użytkownika - tylko frazę lub ID książki.
+type AnalyticsCounter struct {
+	Day   string        `json:"day" firestore:"day"` // RRRR-MM-DD
+	Kind  AnalyticsKind `json:"kind" firestore:"kind"`
+	Key   string        `json:"key" firestore:"key"`     // Znormalizowana fraza lub ID książki
+	Label string        `json:"label" firestore:"label"` // Tekst do wyświetlenia (np. tytuł książki)
+	Count int           `json:"count" firestore:"count"`
+}
+
+// AnalyticsStat to zsumowany licznik z wybranego okresu
+type AnalyticsStat struct {
+	Key   string
+	Label string
+	Count int
+}
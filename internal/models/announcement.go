@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Announcement reprezentuje ogłoszenie biblioteki wyświetlane na stronie głównej
+type Announcement struct {
+	ID         string    `json:"id" firestore:"id"`
+	Title      string    `json:"title" firestore:"title"`
+	Body       string    `json:"body" firestore:"body"` // Treść w formacie Markdown
+	Published  bool      `json:"published" firestore:"published"`
+	AuthorID   string    `json:"author_id" firestore:"author_id"`
+	AuthorName string    `json:"author_name" firestore:"author_name"` // Denormalizacja
+	CreatedAt  time.Time `json:"created_at" firestore:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" firestore:"updated_at"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// AuditAction określa rodzaj czynności personelu zapisanej w dzienniku zmian
+type AuditAction string
+
+const (
+	AuditBookEdit AuditAction = "book_edit" // Edycja opisu książki w katalogu
+	AuditUserEdit AuditAction = "user_edit" // Zmiana limitu wypożyczeń lub blokada konta czytelnika
+	AuditReturn   AuditAction = "return"    // Przyjęcie zwrotu
+)
+
+// AuditUndoWindow to czas, w którym czynność z dziennika zmian można cofnąć.
+// Po nim stan mógł się już zmienić w sposób, którego cofnięcie by nie uwzględniło.
+const AuditUndoWindow = 30 * time.Minute
+
+// AuditChange to zmiana jednego dokumentu przez czynność personelu. Zapisane są tylko pola,
+// które czynność zmieniła - brak pola w Before oznacza, że przed czynnością go nie było.
+type AuditChange struct {
+	Collection string                 `json:"collection" firestore:"collection"`
+	DocID      string                 `json:"doc_id" firestore:"doc_id"`
+	Before     map[string]interface{} `json:"-" firestore:"before"`
+	After      map[string]interface{} `json:"-" firestore:"after"`
+}
+
+// AuditEntry to wpis dziennika zmian z treścią dokumentów sprzed czynności,
+// dzięki której czynność można cofnąć
+type AuditEntry struct {
+	ID         string         `json:"id" firestore:"-"`
+	Action     AuditAction    `json:"action" firestore:"action"`
+	EntityID   string         `json:"entity_id" firestore:"entity_id"` // ID książki, czytelnika lub wypożyczenia
+	Label      string         `json:"label" firestore:"label"`         // Opis na liście (np. tytuł książki)
+	Changes    []*AuditChange `json:"-" firestore:"changes"`
+	StaffID    string         `json:"staff_id" firestore:"staff_id"`
+	StaffEmail string         `json:"staff_email" firestore:"staff_email"`
+	CreatedAt  time.Time      `json:"created_at" firestore:"created_at"`
+	UndoneAt   *time.Time     `json:"undone_at,omitempty" firestore:"undone_at,omitempty"`
+	UndoneBy   string         `json:"undone_by,omitempty" firestore:"undone_by,omitempty"` // Email pracownika
+}
+
+// UndoDeadline zwraca chwilę, po której czynności nie można już cofnąć
+func (e *AuditEntry) UndoDeadline() time.Time {
+	return e.CreatedAt.Add(AuditUndoWindow)
+}
+
+// CanUndo sprawdza czy czynność można jeszcze cofnąć
+func (e *AuditEntry) CanUndo(now time.Time) bool {
+	return e.UndoneAt == nil && now.Before(e.UndoDeadline())
+}
+
+// ActionLabel zwraca polską nazwę czynności
+func (e *AuditEntry) ActionLabel() string {
+	switch e.Action {
+	case AuditBookEdit:
+		return "Edycja książki"
+	case AuditUserEdit:
+		return "Edycja czytelnika"
+	case AuditReturn:
+		return "Zwrot"
+	}
+	return string(e.Action)
+}
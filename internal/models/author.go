@@ -0,0 +1,46 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Author to hasło wzorcowe autora: nazwa preferowana, pod którą autor występuje
+// w katalogu, oraz warianty zapisu, które są na nią zamieniane
+// (np. "J.R.R. Tolkien" i "Tolkien, John Ronald Reuel")
+type Author struct {
+	ID       string   `json:"id" firestore:"id"`
+	Name     string   `json:"name" firestore:"name"`
+	Variants []string `json:"variants" firestore:"variants"`
+	// NameKeys to klucze nazwy preferowanej i wariantów (AuthorNameKey) używane w zapytaniach
+	NameKeys  []string  `json:"-" firestore:"name_keys"`
+	Note      string    `json:"note" firestore:"note"`
+	CreatedAt time.Time `json:"created_at" firestore:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" firestore:"updated_at"`
+}
+
+// AuthorNameKey normalizuje zapis nazwiska do porównań: bez wielkości liter
+// i interpunkcji, z odwróconą postacią "Nazwisko, Imię" ("imię nazwisko")
+func AuthorNameKey(name string) string {
+	if surname, given, found := strings.Cut(name, ","); found {
+		name = given + " " + surname
+	}
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(".,;", r) {
+			return ' '
+		}
+		return r
+	}, name)
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// HasName sprawdza czy nazwa jest nazwą preferowaną albo wariantem hasła
+func (a *Author) HasName(name string) bool {
+	key := AuthorNameKey(name)
+	for _, k := range a.NameKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package models
+
+import "time"
+
+// BadgeRule określa, za co czytelnik dostaje odznakę
+type BadgeRule string
+
+const (
+	BadgeRuleLoans      BadgeRule = "loans"        // Liczba wypożyczeń (bez udostępnień na miejscu)
+	BadgeRuleCategories BadgeRule = "categories"   // Liczba różnych kategorii wypożyczonych książek
+	BadgeRuleOnTimeDays BadgeRule = "on_time_days" // Tyle dni korzystania z biblioteki bez zwrotu po terminie
+)
+
+// BadgeRuleOption to reguła odznaki do wyboru w katalogu odznak
+type BadgeRuleOption struct {
+	Rule           BadgeRule
+	Label          string
+	ThresholdLabel string // Znaczenie progu reguły
+}
+
+// BadgeRules to reguły dostępne w katalogu odznak
+var BadgeRules = []BadgeRuleOption{
+	{BadgeRuleLoans, "Wypożyczenia", "liczba wypożyczeń"},
+	{BadgeRuleCategories, "Różne kategorie", "liczba kategorii"},
+	{BadgeRuleOnTimeDays, "Zwroty w terminie", "liczba dni bez spóźnienia"},
+}
+
+// Label zwraca nazwę reguły wyświetlaną w katalogu odznak
+func (r BadgeRule) Label() string {
+	for _, option := range BadgeRules {
+		if option.Rule == r {
+			return option.Label
+		}
+	}
+	return string(r)
+}
+
+// ValidBadgeRule sprawdza czy reguła pochodzi z listy BadgeRules
+func ValidBadgeRule(rule BadgeRule) bool {
+	for _, option := range BadgeRules {
+		if option.Rule == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// Badge to odznaka z katalogu prowadzonego przez personel. Odznaki przyznaje
+// nocne zadanie na podstawie historii wypożyczeń czytelnika.
+type Badge struct {
+	ID          string    `json:"id" firestore:"id"`
+	Name        string    `json:"name" firestore:"name"`
+	Description string    `json:"description" firestore:"description"`
+	Icon        string    `json:"icon" firestore:"icon"` // Emoji wyświetlane na dashboardzie czytelnika
+	Rule        BadgeRule `json:"rule" firestore:"rule"`
+	Threshold   int       `json:"threshold" firestore:"threshold"`
+	Active      bool      `json:"active" firestore:"active"` // Nieaktywna odznaka nie jest przyznawana ani wyświetlana
+	CreatedAt   time.Time `json:"created_at" firestore:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" firestore:"updated_at"`
+}
+
+// DefaultBadges zwraca podstawowy katalog odznak proponowany bibliotece
+func DefaultBadges() []*Badge {
+	return []*Badge{
+		{Name: "Pierwsze wypożyczenie", Description: "Wypożyczyłeś pierwszą książkę", Icon: "📖", Rule: BadgeRuleLoans, Threshold: 1, Active: true},
+		{Name: "Dziesięć książek", Description: "Wypożyczyłeś 10 książek", Icon: "📚", Rule: BadgeRuleLoans, Threshold: 10, Active: true},
+		{Name: "Odkrywca", Description: "Sięgnąłeś po książki z 5 różnych kategorii", Icon: "🧭", Rule: BadgeRuleCategories, Threshold: 5, Active: true},
+		{Name: "Rok bez spóźnień", Description: "Przez rok oddawałeś wszystkie książki w terminie", Icon: "⏰", Rule: BadgeRuleOnTimeDays, Threshold: 365, Active: true},
+	}
+}
+
+// ReaderStats to podsumowanie historii wypożyczeń czytelnika, na podstawie
+// którego przyznawane są odznaki
+type ReaderStats struct {
+	Loans       int
+	Categories  int
+	FirstLoan   time.Time // Zerowy, gdy czytelnik niczego nie wypożyczył
+	LastOverdue time.Time // Ostatnie spóźnienie (zwrot po terminie lub trwające przeterminowanie)
+}
+
+// EarnedBy sprawdza czy czytelnik spełnia warunek odznaki w chwili now
+func (b *Badge) EarnedBy(stats *ReaderStats, now time.Time) bool {
+	switch b.Rule {
+	case BadgeRuleLoans:
+		return stats.Loans >= b.Threshold
+	case BadgeRuleCategories:
+		return stats.Categories >= b.Threshold
+	case BadgeRuleOnTimeDays:
+		since := now.AddDate(0, 0, -b.Threshold)
+		return !stats.FirstLoan.IsZero() && !stats.FirstLoan.After(since) && stats.LastOverdue.Before(since)
+	}
+	return false
+}
+
+// EarnedBadge to odznaka przyznana czytelnikowi (zapisywana w profilu użytkownika)
+type EarnedBadge struct {
+	BadgeID   string    `json:"badge_id" firestore:"badge_id"`
+	AwardedAt time.Time `json:"awarded_at" firestore:"awarded_at"`
+}
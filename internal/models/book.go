@@ -1,6 +1,18 @@
 package models
 
-import "time"
+import (
+	"crypto/rand"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// shortCodeAlphabet pomija znaki łatwe do pomylenia na wydruku (0/o, 1/l/i)
+	shortCodeAlphabet = "23456789abcdefghjkmnpqrstuvwxyz"
+	shortCodeLength   = 6
+)
 
 // Book reprezentuje książkę w systemie bibliotecznym
 type Book struct {
@@ -15,11 +27,65 @@ type Book struct {
 	TotalCopies     int       `json:"total_copies" firestore:"total_copies"`
 	AvailableCopies int       `json:"available_copies" firestore:"available_copies"`
 	ShelfLocation   string    `json:"shelf_location" firestore:"shelf_location"`
+	CallNumber      string    `json:"call_number" firestore:"call_number"`
+	CallNumberKey   string    `json:"-" firestore:"call_number_key"` // Klucz kolejności półkowej, wyliczany przy zapisie
+	Series          string    `json:"series,omitempty" firestore:"series"`
+	SeriesVolume    int       `json:"series_volume,omitempty" firestore:"series_volume"` // Numer tomu w serii (0 - nieznany)
 	CoverImageURL   string    `json:"cover_image_url" firestore:"cover_image_url"`
+	ShortCode       string    `json:"short_code,omitempty" firestore:"short_code,omitempty"`
 	CreatedAt       time.Time `json:"created_at" firestore:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at" firestore:"updated_at"`
 }
 
+// BookActivity to liczba bieżących wypożyczeń i rezerwacji czekających w kolejce
+type BookActivity struct {
+	Loans        int // Wypożyczone i oczekujące na odbiór
+	Reservations int // Rezerwacje w kolejce
+}
+
+// Permalink zwraca stały, krótki adres książki (pusty, jeśli kod nie został jeszcze nadany)
+func (b *Book) Permalink() string {
+	if b.ShortCode == "" {
+		return ""
+	}
+	return "/b/" + b.ShortCode
+}
+
+// NewShortCode losuje krótki kod permalinku książki. Unikalność sprawdza repozytorium,
+// które nadaje kod.
+func NewShortCode() (string, error) {
+	buf := make([]byte, shortCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("błąd generowania kodu: %w", err)
+	}
+
+	code := make([]byte, shortCodeLength)
+	for i, b := range buf {
+		code[i] = shortCodeAlphabet[int(b)%len(shortCodeAlphabet)]
+	}
+	return string(code), nil
+}
+
+// ClassSymbol zwraca symbol klasyfikacji z sygnatury w zapisie z kropkami, np. "821.162.1"
+func (b *Book) ClassSymbol() string {
+	return FormatClassification(ClassificationDigits(b.CallNumber))
+}
+
+// EditionLabel opisuje wydanie (wydawca i rok), np. do wyboru wydania przy rezerwacji
+func (b *Book) EditionLabel() string {
+	var parts []string
+	if b.Publisher != "" {
+		parts = append(parts, b.Publisher)
+	}
+	if b.PublicationYear > 0 {
+		parts = append(parts, strconv.Itoa(b.PublicationYear))
+	}
+	if len(parts) == 0 {
+		return "ISBN " + b.ISBN
+	}
+	return strings.Join(parts, ", ")
+}
+
 // IsAvailable sprawdza czy książka jest dostępna do wypożyczenia
 func (b *Book) IsAvailable() bool {
 	return b.AvailableCopies > 0
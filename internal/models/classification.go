@@ -0,0 +1,155 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Sygnatury działowe według UKD (lub DDC): symbol klasyfikacji, ewentualnie
+// poddziały wspólne i znak autorski, np. 821.162.1-3 Mic. Symbol główny
+// porządkuje książki na półkach i w przeglądaniu katalogu według dziedzin.
+
+// maxCallNumberLength ogranicza długość sygnatury (mieści się na etykiecie grzbietowej)
+const maxCallNumberLength = 64
+
+// UKDClass to klasa główna UKD
+type UKDClass struct {
+	Symbol string
+	Name   string
+}
+
+// UKDClasses to klasy główne UKD (klasa 4 jest nieużywana)
+var UKDClasses = []UKDClass{
+	{Symbol: "0", Name: "Zagadnienia ogólne. Informatyka. Bibliotekoznawstwo"},
+	{Symbol: "1", Name: "Filozofia. Psychologia"},
+	{Symbol: "2", Name: "Religia. Teologia"},
+	{Symbol: "3", Name: "Nauki społeczne. Prawo. Edukacja"},
+	{Symbol: "5", Name: "Matematyka. Nauki przyrodnicze"},
+	{Symbol: "6", Name: "Nauki stosowane. Medycyna. Technika"},
+	{Symbol: "7", Name: "Sztuka. Rozrywka. Sport"},
+	{Symbol: "8", Name: "Język. Językoznawstwo. Literatura"},
+	{Symbol: "9", Name: "Geografia. Biografie. Historia"},
+}
+
+// UKDClassName zwraca nazwę klasy głównej dla symbolu klasyfikacji (pustą dla nieznanej)
+func UKDClassName(symbol string) string {
+	for _, class := range UKDClasses {
+		if strings.HasPrefix(symbol, class.Symbol) {
+			return class.Name
+		}
+	}
+	return ""
+}
+
+// NormalizeCallNumber usuwa nadmiarowe spacje i sprawdza poprawność sygnatury.
+// Pusta sygnatura jest dozwolona - książka nie jest wtedy sklasyfikowana.
+func NormalizeCallNumber(callNumber string) (string, error) {
+	callNumber = strings.Join(strings.Fields(callNumber), " ")
+	if callNumber == "" {
+		return "", nil
+	}
+
+	if len(callNumber) > maxCallNumberLength {
+		return "", fmt.Errorf("sygnatura może mieć najwyżej %d znaków", maxCallNumberLength)
+	}
+	if callNumber[0] < '0' || callNumber[0] > '9' {
+		return "", fmt.Errorf("sygnatura %q musi zaczynać się od symbolu klasyfikacji (cyfry)", callNumber)
+	}
+	if strings.Contains(callNumber, "..") {
+		return "", fmt.Errorf("sygnatura %q zawiera podwójną kropkę", callNumber)
+	}
+
+	depth := 0
+	quotes := 0
+	for _, r := range callNumber {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+			if depth < 0 {
+				return "", fmt.Errorf("sygnatura %q ma niedomknięty nawias", callNumber)
+			}
+		case r == '"':
+			quotes++
+		case unicode.IsLetter(r), unicode.IsDigit(r), strings.ContainsRune(" .-:+/='", r):
+		default:
+			return "", fmt.Errorf("sygnatura %q zawiera niedozwolony znak %q", callNumber, r)
+		}
+	}
+	if depth != 0 {
+		return "", fmt.Errorf("sygnatura %q ma niedomknięty nawias", callNumber)
+	}
+	if quotes%2 != 0 {
+		return "", fmt.Errorf("sygnatura %q ma niedomknięty cudzysłów", callNumber)
+	}
+
+	return callNumber, nil
+}
+
+// ClassificationDigits zwraca cyfry symbolu głównego sygnatury bez kropek,
+// np. "8211621" dla "821.162.1-3 Mic"
+func ClassificationDigits(callNumber string) string {
+	var digits strings.Builder
+	for i := 0; i < len(callNumber); i++ {
+		c := callNumber[i]
+		switch {
+		case c >= '0' && c <= '9':
+			digits.WriteByte(c)
+		case c == '.' && i+1 < len(callNumber) && callNumber[i+1] >= '0' && callNumber[i+1] <= '9':
+			// Kropka rozdziela grupy cyfr symbolu głównego
+		default:
+			return digits.String()
+		}
+	}
+	return digits.String()
+}
+
+// FormatClassification zapisuje cyfry symbolu w grupach po trzy, np. "821.162" dla "821162"
+func FormatClassification(digits string) string {
+	var groups []string
+	for len(digits) > 3 {
+		groups = append(groups, digits[:3])
+		digits = digits[3:]
+	}
+	return strings.Join(append(groups, digits), ".")
+}
+
+// auxiliaryOrder zastępuje znaki poddziałów tak, żeby porównanie tekstów dawało
+// kolejność szeregowania UKD: + / : = ( " - ' .
+var auxiliaryOrder = strings.NewReplacer(
+	"+", "!",
+	"/", "#",
+	":", "$",
+	"=", "%",
+	"(", "&",
+	`"`, "'",
+	"-", "*",
+	"'", "+",
+	".", ",",
+)
+
+// CallNumberKey zwraca klucz sortowania sygnatury w kolejności półkowej. Cyfry
+// symbolu głównego porównywane jako tekst dają porządek ułamków dziesiętnych
+// (82 < 821 < 821.1 < 9), a symbol ogólniejszy poprzedza szczegółowy.
+// Dla pustej sygnatury zwraca pusty klucz.
+func CallNumberKey(callNumber string) string {
+	digits := ClassificationDigits(callNumber)
+	if digits == "" {
+		return ""
+	}
+
+	// Reszta zaczyna się po ostatnim znaku symbolu głównego
+	consumed := 0
+	for found := 0; found < len(digits); consumed++ {
+		if callNumber[consumed] != '.' {
+			found++
+		}
+	}
+	rest := strings.TrimSpace(callNumber[consumed:])
+	if rest == "" {
+		return digits
+	}
+	return digits + " " + auxiliaryOrder.Replace(strings.ToLower(rest))
+}
@@ -0,0 +1,184 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// MaxCommentLength ogranicza długość komentarza (w znakach)
+	MaxCommentLength = 2000
+
+	// CommentReportThreshold to liczba zgłoszeń, po której opublikowany komentarz
+	// znika ze strony do decyzji moderatora
+	CommentReportThreshold = 3
+)
+
+// CommentReportReasons to powody zgłoszenia komentarza do wyboru przez czytelnika
+var CommentReportReasons = []string{
+	"Obraźliwy lub wulgarny",
+	"Spam lub reklama",
+	"Zdradza zakończenie książki",
+	"Inny powód",
+}
+
+// ValidCommentReportReason sprawdza czy powód zgłoszenia pochodzi z listy
+func ValidCommentReportReason(reason string) bool {
+	for _, r := range CommentReportReasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// CommentStatus określa widoczność komentarza
+type CommentStatus string
+
+const (
+	CommentPending   CommentStatus = "pending"   // Czeka na moderację - widzi go tylko autor i personel
+	CommentPublished CommentStatus = "published" // Widoczny na stronie książki
+	CommentRejected  CommentStatus = "rejected"  // Odrzucony lub ukryty przez personel
+)
+
+// Comment to wpis w dyskusji o książce. Odpowiedź wskazuje komentarz nadrzędny
+// przez ParentID, więc dyskusja tworzy drzewo wątków.
+type Comment struct {
+	ID       string `json:"id" firestore:"id"`
+	BookID   string `json:"book_id" firestore:"book_id"`
+	ParentID string `json:"parent_id,omitempty" firestore:"parent_id,omitempty"`
+	UserID   string `json:"user_id" firestore:"user_id"`
+	// Podpis widoczny publicznie ("Jan K.") i uchwyt do wzmianek (@JanK)
+	AuthorName   string        `json:"author_name" firestore:"author_name"`
+	AuthorHandle string        `json:"author_handle" firestore:"author_handle"`
+	Body         string        `json:"body" firestore:"body"`
+	Status       CommentStatus `json:"status" firestore:"status"`
+	HoldReason   string        `json:"hold_reason,omitempty" firestore:"hold_reason,omitempty"` // Dlaczego komentarz czeka na moderację
+	ModeratedBy  string        `json:"moderated_by,omitempty" firestore:"moderated_by,omitempty"`
+	ModeratedAt  *time.Time    `json:"moderated_at,omitempty" firestore:"moderated_at,omitempty"`
+	// Zgłoszenia czytelników; Flagged oznacza zgłoszenia czekające na decyzję moderatora
+	Reports []CommentReport `json:"-" firestore:"reports"`
+	Flagged bool            `json:"flagged" firestore:"flagged"`
+	// Oceny przydatności od czytelników - jeden głos na osobę
+	Votes     []CommentVote `json:"-" firestore:"votes"`
+	CreatedAt time.Time     `json:"created_at" firestore:"created_at"`
+}
+
+// CommentVote to ocena przydatności komentarza przez czytelnika
+type CommentVote struct {
+	UserID    string    `json:"user_id" firestore:"user_id"`
+	Helpful   bool      `json:"helpful" firestore:"helpful"`
+	CreatedAt time.Time `json:"created_at" firestore:"created_at"`
+}
+
+// CommentReport to zgłoszenie komentarza jako niestosownego
+type CommentReport struct {
+	UserID    string    `json:"user_id" firestore:"user_id"`
+	Reason    string    `json:"reason" firestore:"reason"`
+	CreatedAt time.Time `json:"created_at" firestore:"created_at"`
+}
+
+// OpenReports zwraca zgłoszenia nowsze niż ostatnia decyzja moderatora - po
+// zatwierdzeniu komentarza wcześniejsze zgłoszenia nie liczą się do progu ukrycia
+func (c *Comment) OpenReports() []CommentReport {
+	if c.ModeratedAt == nil {
+		return c.Reports
+	}
+	var open []CommentReport
+	for _, report := range c.Reports {
+		if report.CreatedAt.After(*c.ModeratedAt) {
+			open = append(open, report)
+		}
+	}
+	return open
+}
+
+// ReportedBy sprawdza czy czytelnik już zgłosił komentarz
+func (c *Comment) ReportedBy(userID string) bool {
+	for _, report := range c.Reports {
+		if report.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// VoteOf zwraca głos czytelnika na komentarz (nil, jeśli jeszcze nie głosował)
+func (c *Comment) VoteOf(userID string) *CommentVote {
+	for i := range c.Votes {
+		if c.Votes[i].UserID == userID {
+			return &c.Votes[i]
+		}
+	}
+	return nil
+}
+
+// HelpfulVotes zwraca liczbę głosów "przydatny" i "nieprzydatny"
+func (c *Comment) HelpfulVotes() (helpful, unhelpful int) {
+	for _, vote := range c.Votes {
+		if vote.Helpful {
+			helpful++
+		} else {
+			unhelpful++
+		}
+	}
+	return helpful, unhelpful
+}
+
+// HelpfulScore to różnica głosów "przydatny" i "nieprzydatny" - według niej
+// wątki dyskusji są ułożone na stronie książki
+func (c *Comment) HelpfulScore() int {
+	helpful, unhelpful := c.HelpfulVotes()
+	return helpful - unhelpful
+}
+
+// CommentAuthorName zwraca podpis komentarza: imię i inicjał nazwiska.
+// Pełne nazwisko czytelnika nie trafia na publiczną stronę książki.
+func CommentAuthorName(user *User) string {
+	first := strings.TrimSpace(user.FirstName)
+	last := strings.TrimSpace(user.LastName)
+	if first == "" {
+		return "Czytelnik"
+	}
+	if last == "" {
+		return first
+	}
+	initial, _ := utf8.DecodeRuneInString(last)
+	return first + " " + string(unicode.ToUpper(initial)) + "."
+}
+
+// CommentHandle zwraca uchwyt czytelnika do wzmianek - podpis bez spacji i kropek
+// ("Jan K." -> "JanK")
+func CommentHandle(user *User) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, CommentAuthorName(user))
+}
+
+var mentionPattern = regexp.MustCompile(`@([\p{L}\p{N}]+)`)
+
+// Mentions zwraca uchwyty wspomniane w treści (@JanK), bez powtórzeń
+// i w małych literach
+func (c *Comment) Mentions() []string {
+	var handles []string
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(c.Body, -1) {
+		handle := strings.ToLower(match[1])
+		if !seen[handle] {
+			seen[handle] = true
+			handles = append(handles, handle)
+		}
+	}
+	return handles
+}
+
+// IsPublished sprawdza czy komentarz jest widoczny na stronie książki
+func (c *Comment) IsPublished() bool {
+	return c.Status == CommentPublished
+}
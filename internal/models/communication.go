@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// CommunicationAudience to grupa czytelników, do której personel wysyła wiadomość
+type CommunicationAudience string
+
+const (
+	AudienceActiveReaders  CommunicationAudience = "active_readers"  // Wszyscy aktywni czytelnicy
+	AudienceOverdueReaders CommunicationAudience = "overdue_readers" // Czytelnicy z książkami po terminie zwrotu
+)
+
+// CommunicationAudienceInfo opisuje grupę odbiorców w formularzu wiadomości
+type CommunicationAudienceInfo struct {
+	Audience    CommunicationAudience
+	Label       string
+	Description string
+}
+
+// CommunicationAudiences to grupy odbiorców w kolejności wyświetlania
+var CommunicationAudiences = []CommunicationAudienceInfo{
+	{
+		Audience:    AudienceActiveReaders,
+		Label:       "Wszyscy aktywni czytelnicy",
+		Description: "Konta aktywne i zatwierdzone przez personel",
+	},
+	{
+		Audience:    AudienceOverdueReaders,
+		Label:       "Czytelnicy z książkami po terminie",
+		Description: "Aktywni czytelnicy, którzy mają co najmniej jedno wypożyczenie po terminie zwrotu",
+	},
+}
+
+// GetCommunicationAudience zwraca opis grupy odbiorców (nil, jeśli nie istnieje)
+func GetCommunicationAudience(audience CommunicationAudience) *CommunicationAudienceInfo {
+	for i := range CommunicationAudiences {
+		if CommunicationAudiences[i].Audience == audience {
+			return &CommunicationAudiences[i]
+		}
+	}
+	return nil
+}
+
+// Communication to wiadomość personelu do grupy czytelników. Wysyłka trwa w tle
+// partiami, a Sent pokazuje jej postęp.
+type Communication struct {
+	ID         string                `json:"id" firestore:"-"`
+	Subject    string                `json:"subject" firestore:"subject"`
+	Body       string                `json:"body" firestore:"body"`
+	Audience   CommunicationAudience `json:"audience" firestore:"audience"`
+	SentBy     string                `json:"sent_by" firestore:"sent_by"`       // Imię i nazwisko osoby z personelu
+	SentByID   string                `json:"sent_by_id" firestore:"sent_by_id"` // ID jej profilu
+	Recipients int                   `json:"recipients" firestore:"recipients"` // Liczba odbiorców ustalona na początku wysyłki
+	Sent       int                   `json:"sent" firestore:"sent"`             // Liczba czytelników, do których wiadomość już wysłano
+	CreatedAt  time.Time             `json:"created_at" firestore:"created_at"`
+	FinishedAt time.Time             `json:"finished_at,omitempty" firestore:"finished_at,omitempty"` // Zerowa - wysyłka trwa albo została przerwana
+}
+
+// AudienceLabel zwraca nazwę grupy odbiorców
+func (c *Communication) AudienceLabel() string {
+	if info := GetCommunicationAudience(c.Audience); info != nil {
+		return info.Label
+	}
+	return string(c.Audience)
+}
@@ -0,0 +1,111 @@
+package models
+
+import "time"
+
+// ConsentType to rodzaj zgody czytelnika odnotowywanej w rejestrze czynności przetwarzania
+type ConsentType string
+
+const (
+	ConsentMarketingEmail ConsentType = "marketing_email" // Informacje marketingowe emailem (newsletter)
+	ConsentSMS            ConsentType = "sms"             // Wiadomości SMS na numer z profilu
+)
+
+// ConsentSource to miejsce, w którym czytelnik wyraził lub wycofał zgodę
+type ConsentSource string
+
+const (
+	ConsentSourceRegistration ConsentSource = "registration"
+	ConsentSourceSettings     ConsentSource = "settings"
+	ConsentSourceUnsubscribe  ConsentSource = "unsubscribe" // Link rezygnacji w newsletterze
+)
+
+// Label zwraca opis źródła zgody w eksporcie i panelu personelu
+func (s ConsentSource) Label() string {
+	switch s {
+	case ConsentSourceRegistration:
+		return "rejestracja"
+	case ConsentSourceSettings:
+		return "ustawienia konta"
+	case ConsentSourceUnsubscribe:
+		return "link rezygnacji"
+	default:
+		return string(s)
+	}
+}
+
+// Consent to ostatnia decyzja czytelnika dotycząca zgody
+type Consent struct {
+	Granted   bool          `json:"granted" firestore:"granted"`
+	UpdatedAt time.Time     `json:"updated_at" firestore:"updated_at"`
+	Source    ConsentSource `json:"source" firestore:"source"`
+}
+
+// ConsentInfo opisuje zgodę na stronach rejestracji i ustawień
+type ConsentInfo struct {
+	Type  ConsentType
+	Label string
+	Text  string // Treść zgody, którą zaznacza czytelnik
+	// Category to kategoria powiadomień włączana zgodą (pusta - zgoda nie steruje wysyłką).
+	// Powiadomienia z tej kategorii wychodzą tylko emailem i nie ma ich w tabeli kanałów.
+	Category NotificationCategory
+}
+
+// ConsentTypes to zgody w kolejności wyświetlania
+var ConsentTypes = []ConsentInfo{
+	{
+		Type:     ConsentMarketingEmail,
+		Label:    "Newsletter",
+		Text:     "Zgadzam się na otrzymywanie emailem informacji marketingowych biblioteki - comiesięcznego newslettera z nowościami i ogłoszeniami.",
+		Category: NotifyNewsletter,
+	},
+	{
+		Type:  ConsentSMS,
+		Label: "SMS",
+		Text:  "Zgadzam się na otrzymywanie od biblioteki wiadomości SMS na podany numer telefonu.",
+	},
+}
+
+// GetConsentInfo zwraca opis zgody (nil, jeśli nie istnieje)
+func GetConsentInfo(consentType ConsentType) *ConsentInfo {
+	for i := range ConsentTypes {
+		if ConsentTypes[i].Type == consentType {
+			return &ConsentTypes[i]
+		}
+	}
+	return nil
+}
+
+// ConsentFor zwraca zgodę kategorii powiadomień (nil, jeśli kategoria nie wymaga zgody)
+func ConsentFor(category NotificationCategory) *ConsentInfo {
+	for i := range ConsentTypes {
+		if ConsentTypes[i].Category == category {
+			return &ConsentTypes[i]
+		}
+	}
+	return nil
+}
+
+// Consent zwraca zgodę czytelnika; zerowy UpdatedAt oznacza, że nie podjął decyzji
+func (u *User) Consent(consentType ConsentType) Consent {
+	return u.Consents[consentType]
+}
+
+// RecordConsent odnotowuje decyzję czytelnika. Zgoda sterująca kategorią powiadomień
+// od razu włącza lub wyłącza wysyłkę tej kategorii emailem.
+func (u *User) RecordConsent(consentType ConsentType, granted bool, source ConsentSource, at time.Time) {
+	if u.Consents == nil {
+		u.Consents = make(map[ConsentType]Consent)
+	}
+	u.Consents[consentType] = Consent{Granted: granted, UpdatedAt: at, Source: source}
+
+	if info := GetConsentInfo(consentType); info != nil && info.Category != "" {
+		if u.NotificationPrefs == nil {
+			u.NotificationPrefs = make(map[NotificationCategory][]DeliveryChannel)
+		}
+		channels := []DeliveryChannel{}
+		if granted {
+			channels = append(channels, DeliveryEmail)
+		}
+		u.NotificationPrefs[info.Category] = channels
+	}
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// WithdrawalReason określa powód wycofania egzemplarzy z księgozbioru
+type WithdrawalReason string
+
+const (
+	WithdrawalDamaged WithdrawalReason = "damaged" // Zniszczone
+	WithdrawalLost    WithdrawalReason = "lost"    // Zagubione
+	WithdrawalWeeded  WithdrawalReason = "weeded"  // Selekcja księgozbioru (nieaktualne, mało czytane)
+)
+
+// CopyWithdrawal reprezentuje wycofanie egzemplarzy książki z księgozbioru.
+// Liczbę egzemplarzy zmniejsza się tylko w ten sposób, żeby zostawał ślad w historii.
+type CopyWithdrawal struct {
+	ID         string           `json:"id" firestore:"id"`
+	BookID     string           `json:"book_id" firestore:"book_id"`
+	BookTitle  string           `json:"book_title" firestore:"book_title"` // Denormalizacja
+	Count      int              `json:"count" firestore:"count"`
+	Reason     WithdrawalReason `json:"reason" firestore:"reason"`
+	Notes      string           `json:"notes" firestore:"notes"`
+	RecordedBy string           `json:"recorded_by" firestore:"recorded_by"` // Email pracownika, który wycofał egzemplarze
+	CreatedAt  time.Time        `json:"created_at" firestore:"created_at"`
+}
+
+// ReasonLabel zwraca polską nazwę powodu wycofania
+func (w *CopyWithdrawal) ReasonLabel() string {
+	switch w.Reason {
+	case WithdrawalDamaged:
+		return "Zniszczone"
+	case WithdrawalLost:
+		return "Zagubione"
+	default:
+		return "Selekcja księgozbioru"
+	}
+}
+
+// ValidWithdrawalReason sprawdza czy powód wycofania jest obsługiwany
+func ValidWithdrawalReason(reason WithdrawalReason) bool {
+	return reason == WithdrawalDamaged || reason == WithdrawalLost || reason == WithdrawalWeeded
+}
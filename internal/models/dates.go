@@ -0,0 +1,64 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// FormatDate zwraca datę w zapisie języka locale ("pl": 16.10.2026, "en": Oct 16, 2026)
+func FormatDate(t time.Time, locale string) string {
+	if locale == "en" {
+		return t.Format("Jan 2, 2006")
+	}
+	return t.Format("02.01.2006")
+}
+
+// FormatDateTime zwraca datę z godziną w zapisie języka locale
+func FormatDateTime(t time.Time, locale string) string {
+	if locale == "en" {
+		return t.Format("Jan 2, 2006 15:04")
+	}
+	return t.Format("02.01.2006 15:04")
+}
+
+// RelativeTime zwraca odległość t od now w dniach kalendarzowych w języku locale,
+// np. "dzisiaj", "jutro", "za 3 dni", "2 dni temu" ("today", "in 3 days", "2 days ago")
+func RelativeTime(t, now time.Time, locale string) string {
+	days := calendarDays(now, t)
+	if locale == "en" {
+		switch {
+		case days == 0:
+			return "today"
+		case days == 1:
+			return "tomorrow"
+		case days == -1:
+			return "yesterday"
+		case days > 0:
+			return "in " + strconv.Itoa(days) + " days"
+		default:
+			return strconv.Itoa(-days) + " days ago"
+		}
+	}
+
+	switch {
+	case days == 0:
+		return "dzisiaj"
+	case days == 1:
+		return "jutro"
+	case days == -1:
+		return "wczoraj"
+	case days > 0:
+		return "za " + strconv.Itoa(days) + " dni"
+	default:
+		return strconv.Itoa(-days) + " dni temu"
+	}
+}
+
+// calendarDays zwraca liczbę dni kalendarzowych od from do to (w strefie czasowej from).
+// Liczenie na datach bez godzin nie myli się przy zmianie czasu na letni i zimowy.
+func calendarDays(from, to time.Time) int {
+	to = to.In(from.Location())
+	fromDay := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDay := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(toDay.Sub(fromDay).Hours() / 24)
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRelativeTime(t *testing.T) {
+	warsaw, err := time.LoadLocation("Europe/Warsaw")
+	if err != nil {
+		t.Skipf("brak strefy czasowej Europe/Warsaw: %v", err)
+	}
+	now := time.Date(2026, 10, 16, 23, 30, 0, 0, warsaw)
+
+	tests := []struct {
+		days   int
+		locale string
+		want   string
+	}{
+		{0, "pl", "dzisiaj"},
+		{1, "pl", "jutro"},
+		{-1, "pl", "wczoraj"},
+		{2, "pl", "za 2 dni"},
+		{4, "pl", "za 4 dni"},
+		{5, "pl", "za 5 dni"},
+		{22, "pl", "za 22 dni"},
+		{-2, "pl", "2 dni temu"},
+		{-4, "pl", "4 dni temu"},
+		{-5, "pl", "5 dni temu"},
+		{0, "en", "today"},
+		{1, "en", "tomorrow"},
+		{-1, "en", "yesterday"},
+		{3, "en", "in 3 days"},
+		{-5, "en", "5 days ago"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			date := now.AddDate(0, 0, tt.days)
+			if got := RelativeTime(date, now, tt.locale); got != tt.want {
+				t.Errorf("RelativeTime(%+d dni, %s) = %q, oczekiwano %q", tt.days, tt.locale, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRelativeTimeCountsCalendarDays(t *testing.T) {
+	warsaw, err := time.LoadLocation("Europe/Warsaw")
+	if err != nil {
+		t.Skipf("brak strefy czasowej Europe/Warsaw: %v", err)
+	}
+
+	// Zmiana czasu na zimowy 25.10.2026: doba ma 25 godzin, ale to wciąż jeden dzień
+	now := time.Date(2026, 10, 24, 22, 0, 0, 0, warsaw)
+	due := time.Date(2026, 10, 26, 0, 30, 0, 0, warsaw)
+	if got := RelativeTime(due, now, "pl"); got != "za 2 dni" {
+		t.Errorf("RelativeTime przez zmianę czasu = %q, oczekiwano %q", got, "za 2 dni")
+	}
+
+	// Termin zapisany w UTC liczy się według dnia w strefie biblioteki
+	due = time.Date(2026, 10, 25, 23, 30, 0, 0, time.UTC) // 26.10 00:30 w Warszawie
+	if got := RelativeTime(due, now, "pl"); got != "za 2 dni" {
+		t.Errorf("RelativeTime dla daty w UTC = %q, oczekiwano %q", got, "za 2 dni")
+	}
+}
+
+func TestSettingsDateLocale(t *testing.T) {
+	settings := &Settings{Locale: "pl"}
+	due := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		preferred string
+		want      string
+	}{
+		{"", "16.10.2026"},
+		{"en", "Oct 16, 2026"},
+		{"pl", "16.10.2026"},
+		{"de", "16.10.2026"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.preferred, func(t *testing.T) {
+			if got := settings.FormatDate(due, tt.preferred); got != tt.want {
+				t.Errorf("FormatDate(%q) = %q, oczekiwano %q", tt.preferred, got, tt.want)
+			}
+		})
+	}
+}
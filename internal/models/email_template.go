@@ -0,0 +1,247 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// EmailTemplateKey identyfikuje rodzaj wiadomości email wysyłanej czytelnikom
+type EmailTemplateKey string
+
+const (
+	EmailReservationReady     EmailTemplateKey = "reservation_ready"
+	EmailReservationSuspended EmailTemplateKey = "reservation_suspended"
+	EmailLockerCode           EmailTemplateKey = "locker_code"
+	EmailDueSoon              EmailTemplateKey = "due_soon"
+	EmailCommentMention       EmailTemplateKey = "comment_mention"
+	EmailNewInCatalog         EmailTemplateKey = "new_in_catalog"
+	EmailSavedSearchNew       EmailTemplateKey = "saved_search_new"
+	EmailSavedSearchAvailable EmailTemplateKey = "saved_search_available"
+	EmailNewsletter           EmailTemplateKey = "newsletter"
+)
+
+// EmailPlaceholder to zmienna, którą można wstawić w szablon jako {nazwa}
+type EmailPlaceholder struct {
+	Name        string
+	Description string
+	Example     string // Wartość w podglądzie i wiadomości testowej
+}
+
+// EmailTemplate to treść wiadomości email danego rodzaju. Szablony zmienione przez
+// personel są zapisane w bazie, pozostałe mają treść domyślną z EmailTemplateKinds.
+type EmailTemplate struct {
+	Key       EmailTemplateKey `json:"key" firestore:"-"`
+	Subject   string           `json:"subject" firestore:"subject"`
+	Body      string           `json:"body" firestore:"body"`
+	UpdatedAt time.Time        `json:"updated_at" firestore:"updated_at"`
+	UpdatedBy string           `json:"updated_by" firestore:"updated_by"` // Imię i nazwisko osoby z personelu
+	Custom    bool             `json:"custom" firestore:"-"`              // Zapisany w bazie (nie domyślny)
+}
+
+// EmailTemplateKind opisuje rodzaj wiadomości: kiedy jest wysyłana, jakie zmienne
+// są dostępne i jaka jest jej domyślna treść
+type EmailTemplateKind struct {
+	Key          EmailTemplateKey
+	Name         string
+	Description  string
+	Placeholders []EmailPlaceholder
+	Subject      string
+	Body         string
+}
+
+// commonPlaceholders są dostępne we wszystkich szablonach
+var commonPlaceholders = []EmailPlaceholder{
+	{"imie", "imię czytelnika", "Anna"},
+	{"biblioteka", "nazwa biblioteki", "Biblioteka Miejska"},
+	{"link", "adres strony w bibliotece", "https://biblioteka.example.com/user"},
+}
+
+// EmailTemplateKinds to rodzaje wiadomości, których treść może zmienić personel
+var EmailTemplateKinds = []EmailTemplateKind{
+	{
+		Key:         EmailReservationReady,
+		Name:        "Rezerwacja gotowa do odbioru",
+		Description: "Wysyłana, gdy zarezerwowana książka czeka na czytelnika w miejscu odbioru.",
+		Placeholders: []EmailPlaceholder{
+			{"tytul", "tytuł książki", "Lalka"},
+			{"miejsce", "miejsce odbioru", "Wypożyczalnia główna"},
+			{"termin", "ostatni dzień odbioru", "14.03.2026"},
+		},
+		Subject: "Rezerwacja gotowa do odbioru: {tytul}",
+		Body: "Dzień dobry {imie},\n\nksiążka {tytul} czeka na Ciebie. Miejsce odbioru: {miejsce}.\n" +
+			"Odbierz ją do {termin}.\n\nTwoje rezerwacje: {link}\n\n{biblioteka}",
+	},
+	{
+		Key:         EmailReservationSuspended,
+		Name:        "Rezerwacja pominięta przez limit wypożyczeń",
+		Description: "Wysyłana, gdy zwrócony egzemplarz trafił do następnej osoby w kolejce, bo czytelnik ma wypożyczone tyle książek, ile pozwala limit.",
+		Placeholders: []EmailPlaceholder{
+			{"tytul", "tytuł książki", "Lalka"},
+			{"limit", "limit wypożyczeń czytelnika", "5"},
+		},
+		Subject: "Rezerwacja czeka: {tytul}",
+		Body: "Dzień dobry {imie},\n\nzwrócony egzemplarz książki {tytul} przekazaliśmy następnej osobie w kolejce, " +
+			"bo masz wypożyczone {limit} książek - tyle, ile pozwala Twój limit.\n" +
+			"Rezerwacja zachowuje miejsce w kolejce. Oddaj którąś z wypożyczonych książek, " +
+			"a kolejny zwrócony egzemplarz trafi do Ciebie.\n\nTwoje rezerwacje: {link}\n\n{biblioteka}",
+	},
+	{
+		Key:         EmailLockerCode,
+		Name:        "Kod do skrytki",
+		Description: "Wysyłana, gdy rezerwacja trafiła do skrytki w paczkomacie.",
+		Placeholders: []EmailPlaceholder{
+			{"tytul", "tytuł książki", "Lalka"},
+			{"skrytka", "numer skrytki", "A12"},
+			{"miejsce", "lokalizacja paczkomatu", "Paczkomat przy wejściu"},
+			{"kod", "kod otwarcia skrytki", "482913"},
+			{"termin", "ostatni dzień odbioru", "14.03.2026"},
+		},
+		Subject: "Kod do skrytki: {tytul}",
+		Body: "Dzień dobry {imie},\n\nksiążka {tytul} czeka w skrytce {skrytka} ({miejsce}).\n" +
+			"Kod otwarcia: {kod}. Odbierz ją do {termin}.\n\nTwoje rezerwacje: {link}\n\n{biblioteka}",
+	},
+	{
+		Key:         EmailDueSoon,
+		Name:        "Zbliża się termin zwrotu",
+		Description: fmt.Sprintf("Wysyłana %d dni przed terminem zwrotu wypożyczenia.", DueSoonDays),
+		Placeholders: []EmailPlaceholder{
+			{"tytul", "tytuł książki", "Lalka"},
+			{"termin", "termin zwrotu", "14.03.2026"},
+		},
+		Subject: "Zbliża się termin zwrotu: {tytul}",
+		Body: "Dzień dobry {imie},\n\ntermin zwrotu książki {tytul} mija {termin}.\n" +
+			"Oddaj ją na czas, aby uniknąć kary.\n\nTwoje wypożyczenia: {link}\n\n{biblioteka}",
+	},
+	{
+		Key:         EmailCommentMention,
+		Name:        "Wzmianka w dyskusji",
+		Description: "Wysyłana, gdy ktoś wspomni czytelnika przez @uchwyt w komentarzu do książki.",
+		Placeholders: []EmailPlaceholder{
+			{"autor", "autor komentarza", "Jan K."},
+			{"tytul", "tytuł książki", "Lalka"},
+			{"tresc", "treść komentarza", "@Anna polecam zakończenie!"},
+		},
+		Subject: "{autor} wspomina Cię w dyskusji: {tytul}",
+		Body:    "Dzień dobry {imie},\n\n{autor} napisał(a) w dyskusji o książce {tytul}:\n\n{tresc}\n\n{link}\n\n{biblioteka}",
+	},
+	{
+		Key:         EmailNewInCatalog,
+		Name:        "Nowość obserwowanego autora lub kategorii",
+		Description: "Wysyłana, gdy w katalogu pojawi się książka obserwowanego autora lub z obserwowanej kategorii.",
+		Placeholders: []EmailPlaceholder{
+			{"tytul", "tytuł książki", "Lalka"},
+			{"autor", "autor książki", "Bolesław Prus"},
+			{"powod", "co obserwuje czytelnik", "autor: Bolesław Prus"},
+		},
+		Subject: "Nowość w katalogu: {tytul}",
+		Body:    "Dzień dobry {imie},\n\nw katalogu pojawiła się książka {tytul} - {autor} ({powod}).\n\n{link}\n\n{biblioteka}",
+	},
+	{
+		Key:         EmailSavedSearchNew,
+		Name:        "Nowa książka z zapisanego wyszukiwania",
+		Description: "Wysyłana, gdy nowa książka w katalogu pasuje do zapisanego wyszukiwania czytelnika.",
+		Placeholders: []EmailPlaceholder{
+			{"tytul", "tytuł książki", "Lalka"},
+			{"autor", "autor książki", "Bolesław Prus"},
+			{"wyszukiwanie", "zapisane wyszukiwanie", "prus"},
+		},
+		Subject: "Nowa książka pasująca do Twojego wyszukiwania",
+		Body:    "Dzień dobry {imie},\n\ndo Twojego wyszukiwania \"{wyszukiwanie}\" pasuje nowa książka: {tytul} - {autor}.\n\n{link}\n\n{biblioteka}",
+	},
+	{
+		Key:         EmailSavedSearchAvailable,
+		Name:        "Książka z zapisanego wyszukiwania jest dostępna",
+		Description: "Wysyłana, gdy książka pasująca do zapisanego wyszukiwania znów jest dostępna.",
+		Placeholders: []EmailPlaceholder{
+			{"tytul", "tytuł książki", "Lalka"},
+			{"autor", "autor książki", "Bolesław Prus"},
+			{"wyszukiwanie", "zapisane wyszukiwanie", "prus"},
+		},
+		Subject: "Książka z Twojego wyszukiwania jest dostępna",
+		Body:    "Dzień dobry {imie},\n\nksiążka {tytul} - {autor} z Twojego wyszukiwania \"{wyszukiwanie}\" jest znów dostępna.\n\n{link}\n\n{biblioteka}",
+	},
+	{
+		Key:         EmailNewsletter,
+		Name:        "Newsletter",
+		Description: "Wysyłany pierwszego dnia miesiąca czytelnikom, którzy zamówili newsletter. Nowości i ogłoszenia są składane automatycznie.",
+		Placeholders: []EmailPlaceholder{
+			{"miesiac", "miesiąc, którego dotyczy wydanie", "marzec 2026"},
+			{"nowosci", "książki dodane w miesiącu według kategorii", "Powieść\n- Lalka - Bolesław Prus\n  https://biblioteka.example.com/books/123"},
+			{"ogloszenia", "ogłoszenia opublikowane w miesiącu", "- Zmiana godzin otwarcia\n  https://biblioteka.example.com/announcements/456"},
+			{"wypisz", "link rezygnacji z newslettera", "https://biblioteka.example.com/newsletter/unsubscribe/abc123"},
+		},
+		Subject: "{biblioteka} - newsletter, {miesiac}",
+		Body: "Dzień dobry {imie},\n\noto nowości i ogłoszenia z minionego miesiąca ({miesiac}).\n\n" +
+			"NOWOŚCI W KATALOGU\n\n{nowosci}\n\nOGŁOSZENIA\n\n{ogloszenia}\n\n" +
+			"Katalog: {link}\n\n{biblioteka}\n\nNie chcesz dostawać newslettera? Zrezygnuj: {wypisz}",
+	},
+}
+
+// GetEmailTemplateKind zwraca rodzaj wiadomości o danym kluczu (nil, jeśli nie istnieje)
+func GetEmailTemplateKind(key EmailTemplateKey) *EmailTemplateKind {
+	for i := range EmailTemplateKinds {
+		if EmailTemplateKinds[i].Key == key {
+			return &EmailTemplateKinds[i]
+		}
+	}
+	return nil
+}
+
+// AllPlaceholders zwraca zmienne rodzaju razem ze wspólnymi dla wszystkich szablonów
+func (k *EmailTemplateKind) AllPlaceholders() []EmailPlaceholder {
+	return append(append([]EmailPlaceholder(nil), k.Placeholders...), commonPlaceholders...)
+}
+
+// Default zwraca szablon z domyślną treścią
+func (k *EmailTemplateKind) Default() *EmailTemplate {
+	return &EmailTemplate{Key: k.Key, Subject: k.Subject, Body: k.Body}
+}
+
+// Examples zwraca przykładowe wartości zmiennych do podglądu
+func (k *EmailTemplateKind) Examples() map[string]string {
+	vars := make(map[string]string)
+	for _, p := range k.AllPlaceholders() {
+		vars[p.Name] = p.Example
+	}
+	return vars
+}
+
+var placeholderPattern = regexp.MustCompile(`\{([^{}\s]*)\}`)
+
+// Validate sprawdza, czy szablon ma temat i treść oraz używa tylko zmiennych swojego rodzaju
+func (k *EmailTemplateKind) Validate(t *EmailTemplate) error {
+	if strings.TrimSpace(t.Subject) == "" {
+		return fmt.Errorf("temat wiadomości nie może być pusty")
+	}
+	if strings.ContainsAny(t.Subject, "\r\n") {
+		return fmt.Errorf("temat wiadomości musi mieścić się w jednej linii")
+	}
+	if strings.TrimSpace(t.Body) == "" {
+		return fmt.Errorf("treść wiadomości nie może być pusta")
+	}
+
+	known := make(map[string]bool)
+	for _, p := range k.AllPlaceholders() {
+		known[p.Name] = true
+	}
+	for _, match := range placeholderPattern.FindAllStringSubmatch(t.Subject+t.Body, -1) {
+		if !known[match[1]] {
+			return fmt.Errorf("nieznana zmienna {%s}", match[1])
+		}
+	}
+	return nil
+}
+
+// Render wstawia wartości zmiennych w temat i treść. Zmienne bez wartości zostają puste.
+func (t *EmailTemplate) Render(vars map[string]string) (subject, body string) {
+	replace := func(s string) string {
+		return placeholderPattern.ReplaceAllStringFunc(s, func(match string) string {
+			return vars[match[1:len(match)-1]]
+		})
+	}
+	// Wartości (np. tytuł książki) nie mogą przełamać nagłówka Subject
+	subject = strings.Join(strings.Fields(replace(t.Subject)), " ")
+	return subject, replace(t.Body)
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// PaymentMethod określa sposób wpłaty kary w bibliotece
+type PaymentMethod string
+
+const (
+	PaymentCash     PaymentMethod = "cash"     // Gotówka w kasie
+	PaymentTerminal PaymentMethod = "terminal" // Karta w terminalu płatniczym
+)
+
+// FinePayment reprezentuje wpłatę kary przyjętą przy ladzie
+type FinePayment struct {
+	ID            string        `json:"id" firestore:"id"`
+	UserID        string        `json:"user_id" firestore:"user_id"`
+	UserName      string        `json:"user_name" firestore:"user_name"` // Denormalizacja dla pokwitowania i raportu kasowego
+	Amount        Money         `json:"amount" firestore:"amount_gr"`
+	Method        PaymentMethod `json:"method" firestore:"method"`
+	ReceiptNumber string        `json:"receipt_number" firestore:"receipt_number"` // Numer paragonu z kasy fiskalnej lub potwierdzenia z terminala
+	RecordedBy    string        `json:"recorded_by" firestore:"recorded_by"`       // Email pracownika, który przyjął wpłatę
+	CreatedAt     time.Time     `json:"created_at" firestore:"created_at"`
+}
+
+// MethodLabel zwraca polską nazwę sposobu płatności
+func (p *FinePayment) MethodLabel() string {
+	if p.Method == PaymentTerminal {
+		return "Karta (terminal)"
+	}
+	return "Gotówka"
+}
+
+// ValidPaymentMethod sprawdza czy sposób płatności jest obsługiwany
+func ValidPaymentMethod(method PaymentMethod) bool {
+	return method == PaymentCash || method == PaymentTerminal
+}
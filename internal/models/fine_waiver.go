@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// FineWaiverCriteria określa, które kary obejmuje umorzenie zbiorcze.
+// Puste pola nie ograniczają wyboru.
+type FineWaiverCriteria struct {
+	MaxAmount    Money     `json:"max_amount" firestore:"max_amount_gr"`    // Tylko kary nie wyższe niż kwota (0 = dowolna kwota)
+	ReturnedFrom time.Time `json:"returned_from" firestore:"returned_from"` // Zwrot nie wcześniej niż tego dnia
+	ReturnedTo   time.Time `json:"returned_to" firestore:"returned_to"`     // Zwrot nie później niż tego dnia (włącznie)
+}
+
+// IsEmpty sprawdza czy nie podano żadnego kryterium
+func (c FineWaiverCriteria) IsEmpty() bool {
+	return c.MaxAmount <= 0 && c.ReturnedFrom.IsZero() && c.ReturnedTo.IsZero()
+}
+
+// Matches sprawdza czy nieumorzona kara za wypożyczenie spełnia kryteria
+func (c FineWaiverCriteria) Matches(loan *Loan) bool {
+	if loan.FineAmount <= 0 || loan.FineWaived || loan.ReturnDate == nil {
+		return false
+	}
+	if c.MaxAmount > 0 && loan.FineAmount > c.MaxAmount {
+		return false
+	}
+	if !c.ReturnedFrom.IsZero() && loan.ReturnDate.Before(c.ReturnedFrom) {
+		return false
+	}
+	if !c.ReturnedTo.IsZero() && !loan.ReturnDate.Before(c.ReturnedTo.AddDate(0, 0, 1)) {
+		return false
+	}
+	return true
+}
+
+// FineWaiver to wpis dziennika umorzeń zbiorczych (amnestii)
+type FineWaiver struct {
+	ID          string             `json:"id" firestore:"id"`
+	Criteria    FineWaiverCriteria `json:"criteria" firestore:"criteria"`
+	Reason      string             `json:"reason" firestore:"reason"`             // Np. "Tydzień amnestii 2026"
+	LoanCount   int                `json:"loan_count" firestore:"loan_count"`     // Liczba umorzonych kar
+	ReaderCount int                `json:"reader_count" firestore:"reader_count"` // Liczba czytelników objętych umorzeniem
+	Amount      Money              `json:"amount" firestore:"amount_gr"`          // Łączna kwota zdjęta z sald czytelników
+	PerformedBy string             `json:"performed_by" firestore:"performed_by"` // Email pracownika
+	CreatedAt   time.Time          `json:"created_at" firestore:"created_at"`
+}
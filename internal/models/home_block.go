@@ -0,0 +1,43 @@
+package models
+
+// HomeBlock to blok strony głównej, który administrator może włączyć i ustawić w kolejności
+type HomeBlock string
+
+const (
+	HomeBlockAnnouncements HomeBlock = "announcements" // Ostatnie ogłoszenia
+	HomeBlockReadingLists  HomeBlock = "reading_lists" // Listy lektur wyróżnione na stronie głównej
+	HomeBlockSearch        HomeBlock = "search"        // Formularz wyszukiwania w katalogu
+	HomeBlockNewArrivals   HomeBlock = "new_arrivals"  // Ostatnio dodane książki
+	HomeBlockPopular       HomeBlock = "popular"       // Najczęściej wypożyczane w miesiącu (ze zrzutu statystyk)
+	HomeBlockStats         HomeBlock = "stats"         // Biblioteka w liczbach (ze zrzutu statystyk)
+)
+
+// HomeBlockOption to blok strony głównej do wyboru w ustawieniach
+type HomeBlockOption struct {
+	Block       HomeBlock
+	Label       string
+	Description string
+}
+
+// HomeBlockOptions to bloki dostępne na stronie głównej
+var HomeBlockOptions = []HomeBlockOption{
+	{HomeBlockAnnouncements, "Ogłoszenia", "trzy ostatnie opublikowane ogłoszenia"},
+	{HomeBlockReadingLists, "Listy lektur", "listy oznaczone do wyświetlania na stronie głównej"},
+	{HomeBlockSearch, "Wyszukiwarka", "formularz wyszukiwania w katalogu"},
+	{HomeBlockNewArrivals, "Nowości", "ostatnio dodane do katalogu książki"},
+	{HomeBlockPopular, "Popularne", "najczęściej wypożyczane w tym miesiącu"},
+	{HomeBlockStats, "Biblioteka w liczbach", "wielkość księgozbioru i wypożyczenia w miesiącu"},
+}
+
+// DefaultHomeBlocks to układ strony głównej, dopóki administrator go nie zmieni
+var DefaultHomeBlocks = []HomeBlock{HomeBlockAnnouncements, HomeBlockReadingLists, HomeBlockSearch}
+
+// ValidHomeBlock sprawdza czy blok pochodzi z listy HomeBlockOptions
+func ValidHomeBlock(block HomeBlock) bool {
+	for _, option := range HomeBlockOptions {
+		if option.Block == block {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package models
+
+import "time"
+
+// ILLStatus określa etap zamówienia międzybibliotecznego
+type ILLStatus string
+
+const (
+	ILLStatusRequested ILLStatus = "requested" // Zgłoszone przez czytelnika
+	ILLStatusSent      ILLStatus = "sent"      // Zamówienie wysłane do biblioteki partnerskiej
+	ILLStatusReceived  ILLStatus = "received"  // Książka otrzymana od partnera
+	ILLStatusReady     ILLStatus = "ready"     // Czeka na odbiór przez czytelnika
+	ILLStatusReturned  ILLStatus = "returned"  // Odesłana do biblioteki partnerskiej
+	ILLStatusCancelled ILLStatus = "cancelled" // Anulowane (np. partner nie ma tytułu)
+)
+
+// ILLStatuses to etapy zamówienia w kolejności realizacji
+var ILLStatuses = []ILLStatus{
+	ILLStatusRequested,
+	ILLStatusSent,
+	ILLStatusReceived,
+	ILLStatusReady,
+	ILLStatusReturned,
+	ILLStatusCancelled,
+}
+
+// ValidILLStatus sprawdza czy status zamówienia jest obsługiwany
+func ValidILLStatus(status ILLStatus) bool {
+	for _, s := range ILLStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Label zwraca polską nazwę statusu
+func (s ILLStatus) Label() string {
+	switch s {
+	case ILLStatusSent:
+		return "Wysłane do partnera"
+	case ILLStatusReceived:
+		return "Otrzymane od partnera"
+	case ILLStatusReady:
+		return "Gotowe do odbioru"
+	case ILLStatusReturned:
+		return "Odesłane do partnera"
+	case ILLStatusCancelled:
+		return "Anulowane"
+	default:
+		return "Zgłoszone"
+	}
+}
+
+// ILLRequest to zamówienie międzybiblioteczne tytułu, którego biblioteka nie ma w zbiorach.
+// Terminy i opłaty biblioteki partnerskiej są prowadzone osobno od zwykłych wypożyczeń.
+type ILLRequest struct {
+	ID       string    `json:"id" firestore:"id"`
+	UserID   string    `json:"user_id" firestore:"user_id"`
+	UserName string    `json:"user_name" firestore:"user_name"` // Denormalizacja
+	Title    string    `json:"title" firestore:"title"`
+	Author   string    `json:"author" firestore:"author"`
+	ISBN     string    `json:"isbn" firestore:"isbn"`
+	Note     string    `json:"note" firestore:"note"` // Uwagi czytelnika
+	Status   ILLStatus `json:"status" firestore:"status"`
+
+	PartnerLibraryID string     `json:"partner_library_id" firestore:"partner_library_id"`
+	PartnerLibrary   string     `json:"partner_library" firestore:"partner_library"`       // Denormalizacja nazwy partnera
+	DueDate          *time.Time `json:"due_date,omitempty" firestore:"due_date,omitempty"` // Termin zwrotu do biblioteki partnerskiej
+	Fee              Money      `json:"fee" firestore:"fee_gr"`                            // Opłata za sprowadzenie pobierana od czytelnika
+	FeePaid          bool       `json:"fee_paid" firestore:"fee_paid"`
+	StaffNotes       string     `json:"staff_notes" firestore:"staff_notes"`
+
+	Messages []ILLMessage `json:"messages" firestore:"messages"` // Korespondencja z partnerem (najstarsza pierwsza)
+
+	CreatedAt time.Time `json:"created_at" firestore:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" firestore:"updated_at"`
+}
+
+// ILLChannel określa rodzaj korespondencji z biblioteką partnerską
+type ILLChannel string
+
+const (
+	ILLChannelEmail    ILLChannel = "email"    // Email wysłany z systemu
+	ILLChannelLetter   ILLChannel = "letter"   // Wydrukowany list
+	ILLChannelIncoming ILLChannel = "incoming" // Odpowiedź partnera zapisana przez personel
+)
+
+// Label zwraca polską nazwę rodzaju korespondencji
+func (c ILLChannel) Label() string {
+	switch c {
+	case ILLChannelEmail:
+		return "Email"
+	case ILLChannelLetter:
+		return "List"
+	default:
+		return "Odpowiedź partnera"
+	}
+}
+
+// ILLMessage to wpis korespondencji w zamówieniu międzybibliotecznym
+type ILLMessage struct {
+	Channel   ILLChannel `json:"channel" firestore:"channel"`
+	Recipient string     `json:"recipient" firestore:"recipient"` // Adres email lub nazwa biblioteki
+	Subject   string     `json:"subject" firestore:"subject"`
+	Body      string     `json:"body" firestore:"body"`
+	Author    string     `json:"author" firestore:"author"` // Email pracownika
+	CreatedAt time.Time  `json:"created_at" firestore:"created_at"`
+}
+
+// StatusLabel zwraca polską nazwę statusu
+func (r *ILLRequest) StatusLabel() string {
+	return r.Status.Label()
+}
+
+// IsOpen sprawdza czy zamówienie jest w toku (nie zostało odesłane ani anulowane)
+func (r *ILLRequest) IsOpen() bool {
+	return r.Status != ILLStatusReturned && r.Status != ILLStatusCancelled
+}
+
+// IsOverdue sprawdza czy minął termin zwrotu książki do biblioteki partnerskiej
+func (r *ILLRequest) IsOverdue() bool {
+	return r.IsOpen() && r.DueDate != nil && time.Now().After(*r.DueDate)
+}
@@ -9,35 +9,76 @@ const (
 	LoanStatusPendingPickup LoanStatus = "pending_pickup" // Oczekuje na odbiór
 	LoanStatusActive        LoanStatus = "active"         // Aktywne wypożyczenie
 	LoanStatusReturned      LoanStatus = "returned"       // Zwrócone
-	LoanStatusOverdue       LoanStatus = "overdue"        // Przeterminowane
+	LoanStatusOverdue       LoanStatus = "overdue"        // Po terminie zwrotu (ustawiane przez nocne naliczanie kar)
+)
+
+// BorrowedStatuses to statusy wypożyczeń, w których książka jest u czytelnika
+var BorrowedStatuses = []LoanStatus{LoanStatusActive, LoanStatusOverdue}
+
+// DueSoonDays to liczba dni przed terminem zwrotu, w której czytelnik dostaje przypomnienie
+const DueSoonDays = 2
+
+// MaxRenewals to liczba przedłużeń, o które czytelnik może poprosić sam (np. przez bota)
+const MaxRenewals = 1
+
+// LoanType określa rodzaj wypożyczenia
+type LoanType string
+
+const (
+	LoanTypeHome        LoanType = ""             // Wypożyczenie na zewnątrz (także wypożyczenia sprzed dodania rodzajów)
+	LoanTypeReadingRoom LoanType = "reading_room" // Udostępnienie na miejscu - zwrot tego samego dnia
 )
 
 // Loan reprezentuje wypożyczenie książki
 type Loan struct {
-	ID         string     `json:"id" firestore:"id"`
-	BookID     string     `json:"book_id" firestore:"book_id"`
-	UserID     string     `json:"user_id" firestore:"user_id"`
-	BookTitle  string     `json:"book_title" firestore:"book_title"`   // Denormalizacja dla łatwiejszego wyświetlania
-	UserName   string     `json:"user_name" firestore:"user_name"`     // Denormalizacja dla łatwiejszego wyświetlania
-	PickupCode string     `json:"pickup_code" firestore:"pickup_code"` // Kod odbioru
-	Status     LoanStatus `json:"status" firestore:"status"`
-	LoanDate   time.Time  `json:"loan_date" firestore:"loan_date"`
-	DueDate    time.Time  `json:"due_date" firestore:"due_date"`
-	ReturnDate *time.Time `json:"return_date,omitempty" firestore:"return_date,omitempty"`
-	FineAmount float64    `json:"fine_amount" firestore:"fine_amount"` // Kara za opóźnienie
-	Notes      string     `json:"notes" firestore:"notes"`
-	CreatedAt  time.Time  `json:"created_at" firestore:"created_at"`
-	UpdatedAt  time.Time  `json:"updated_at" firestore:"updated_at"`
+	ID             string     `json:"id" firestore:"id"`
+	BookID         string     `json:"book_id" firestore:"book_id"`
+	UserID         string     `json:"user_id" firestore:"user_id"`
+	BookTitle      string     `json:"book_title" firestore:"book_title"`           // Denormalizacja dla łatwiejszego wyświetlania
+	UserName       string     `json:"user_name" firestore:"user_name"`             // Denormalizacja dla łatwiejszego wyświetlania
+	PickupCode     string     `json:"pickup_code" firestore:"pickup_code"`         // Kod odbioru
+	PickupLocation string     `json:"pickup_location" firestore:"pickup_location"` // Miejsce odbioru z rezerwacji (puste = wypożyczalnia)
+	Status         LoanStatus `json:"status" firestore:"status"`
+	Type           LoanType   `json:"type,omitempty" firestore:"type,omitempty"`
+	LoanDate       time.Time  `json:"loan_date" firestore:"loan_date"`
+	DueDate        time.Time  `json:"due_date" firestore:"due_date"`
+	ReturnDate     *time.Time `json:"return_date,omitempty" firestore:"return_date,omitempty"`
+	FineAmount     Money      `json:"fine_amount" firestore:"fine_amount_gr"` // Kara za opóźnienie
+	FineWaived     bool       `json:"fine_waived" firestore:"fine_waived"`    // Kara umorzona (np. w ramach amnestii)
+	Renewals       int        `json:"renewals" firestore:"renewals"`          // Liczba przedłużeń terminu zwrotu
+	Anonymized     bool       `json:"anonymized" firestore:"anonymized"`      // Dane czytelnika usunięte po okresie przechowywania
+	Notes          string     `json:"notes" firestore:"notes"`
+	CreatedAt      time.Time  `json:"created_at" firestore:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" firestore:"updated_at"`
+}
+
+// IsReadingRoom sprawdza czy książka została udostępniona na miejscu (w czytelni)
+func (l *Loan) IsReadingRoom() bool {
+	return l.Type == LoanTypeReadingRoom
+}
+
+// ReaderName zwraca imię i nazwisko czytelnika zapisane w wypożyczeniu albo informację
+// o anonimizacji
+func (l *Loan) ReaderName() string {
+	if l.Anonymized {
+		return "Czytelnik (dane usunięte)"
+	}
+	return l.UserName
+}
+
+// IsBorrowed sprawdza czy książka jest u czytelnika (wypożyczenie aktywne lub po terminie)
+func (l *Loan) IsBorrowed() bool {
+	return l.Status == LoanStatusActive || l.Status == LoanStatusOverdue
 }
 
 // IsOverdue sprawdza czy wypożyczenie jest przeterminowane
 func (l *Loan) IsOverdue() bool {
-	return l.Status == LoanStatusActive && time.Now().After(l.DueDate)
+	return l.IsBorrowed() && time.Now().After(l.DueDate)
 }
 
-// CalculateFine oblicza karę za opóźnienie (1 zł za każdy dzień)
-func (l *Loan) CalculateFine() float64 {
-	if !l.IsOverdue() {
+// CalculateFine oblicza karę za opóźnienie (FinePerDay za każdy dzień)
+func (l *Loan) CalculateFine() Money {
+	if !l.IsOverdue() || l.IsReadingRoom() {
 		return 0
 	}
 
@@ -46,16 +87,22 @@ func (l *Loan) CalculateFine() float64 {
 		return 0
 	}
 
-	// 1 zł za każdy dzień opóźnienia
-	return float64(daysOverdue) * 1.0
+	return Money(daysOverdue) * FinePerDay
 }
 
 // DaysUntilDue zwraca liczbę dni do terminu zwrotu
 func (l *Loan) DaysUntilDue() int {
-	if l.Status != LoanStatusActive {
+	if !l.IsBorrowed() {
 		return 0
 	}
 
 	days := int(time.Until(l.DueDate).Hours() / 24)
 	return days
 }
+
+// ReturnResult opisuje skutki zwrotu książki
+type ReturnResult struct {
+	Loan            *Loan
+	Fine            Money        // Kara naliczona przy zwrocie (0 gdy zwrot w terminie)
+	NextReservation *Reservation // Rezerwacja, która stała się gotowa do odbioru (nil gdy książka wraca na półkę)
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// MaxMenuLinks to najwięcej odnośników w pasku nawigacji i w stopce (osobno)
+const MaxMenuLinks = 8
+
+// MenuLink to odnośnik dodany przez administratora w ustawieniach do paska nawigacji
+// lub stopki stron czytelnika (np. strona gminy, pomoc katalogu)
+type MenuLink struct {
+	Label string `json:"label" firestore:"label"`
+	// Adres http(s), mailto: lub tel:, albo ścieżka w aplikacji zaczynająca się od "/"
+	URL string `json:"url" firestore:"url"`
+}
+
+// ParseMenuLink odczytuje odnośnik z linii formularza w zapisie "Etykieta | adres"
+func ParseMenuLink(line string) MenuLink {
+	label, address, found := strings.Cut(line, "|")
+	if !found {
+		return MenuLink{URL: strings.TrimSpace(line)}
+	}
+	return MenuLink{Label: strings.TrimSpace(label), URL: strings.TrimSpace(address)}
+}
+
+// String zwraca odnośnik w zapisie linii formularza
+func (l MenuLink) String() string {
+	return l.Label + " | " + l.URL
+}
+
+// IsInternal sprawdza czy odnośnik prowadzi do strony aplikacji (adres względem BASE_PATH)
+func (l MenuLink) IsInternal() bool {
+	return strings.HasPrefix(l.URL, "/") && !strings.HasPrefix(l.URL, "//")
+}
+
+// Validate sprawdza etykietę i adres odnośnika
+func (l MenuLink) Validate() error {
+	if l.Label == "" || l.URL == "" {
+		return fmt.Errorf("odnośnik %q wymaga etykiety i adresu w zapisie \"Etykieta | adres\"", l.String())
+	}
+	if l.IsInternal() {
+		return nil
+	}
+	u, err := url.Parse(l.URL)
+	if err != nil {
+		return fmt.Errorf("nieprawidłowy adres odnośnika %q", l.Label)
+	}
+	switch u.Scheme {
+	case "http", "https":
+		if u.Host == "" {
+			return fmt.Errorf("nieprawidłowy adres odnośnika %q", l.Label)
+		}
+	case "mailto", "tel":
+	default:
+		return fmt.Errorf("odnośnik %q musi prowadzić do adresu http(s), mailto:, tel: lub ścieżki zaczynającej się od /", l.Label)
+	}
+	return nil
+}
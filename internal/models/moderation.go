@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// ModerationAction to decyzja moderatora w sprawie komentarza lub jego autora
+type ModerationAction string
+
+const (
+	ModerationApprove ModerationAction = "approve" // Komentarz zostaje na stronie, zgłoszenia są odrzucone
+	ModerationHide    ModerationAction = "hide"    // Komentarz znika ze strony książki
+	ModerationBan     ModerationAction = "ban"     // Ukrycie komentarza i blokada komentowania autora
+	ModerationUnban   ModerationAction = "unban"   // Zdjęcie blokady komentowania
+)
+
+// Label zwraca nazwę decyzji wyświetlaną w dzienniku moderacji
+func (a ModerationAction) Label() string {
+	switch a {
+	case ModerationApprove:
+		return "Zatwierdzenie"
+	case ModerationHide:
+		return "Ukrycie"
+	case ModerationBan:
+		return "Blokada autora"
+	case ModerationUnban:
+		return "Zdjęcie blokady"
+	}
+	return string(a)
+}
+
+// ModerationDecision to wpis dziennika moderacji - kto, kiedy i jak rozstrzygnął
+// sprawę komentarza. Treść komentarza jest kopiowana, bo dziennik ma pozostać
+// czytelny także po zmianach w komentarzu.
+type ModerationDecision struct {
+	ID             string           `json:"id" firestore:"id"`
+	Action         ModerationAction `json:"action" firestore:"action"`
+	CommentID      string           `json:"comment_id,omitempty" firestore:"comment_id,omitempty"` // Pusty przy zdjęciu blokady
+	BookID         string           `json:"book_id,omitempty" firestore:"book_id,omitempty"`
+	AuthorID       string           `json:"author_id" firestore:"author_id"`
+	AuthorName     string           `json:"author_name" firestore:"author_name"`
+	CommentBody    string           `json:"comment_body,omitempty" firestore:"comment_body,omitempty"`
+	ReportCount    int              `json:"report_count" firestore:"report_count"` // Liczba zgłoszeń w chwili decyzji
+	ModeratorEmail string           `json:"moderator_email" firestore:"moderator_email"`
+	CreatedAt      time.Time        `json:"created_at" firestore:"created_at"`
+}
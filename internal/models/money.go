@@ -0,0 +1,208 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money to kwota w groszach (setnych częściach waluty). Kary i wpłaty liczone
+// na liczbach całkowitych nie gromadzą błędów zaokrągleń jak float64.
+type Money int64
+
+// FinePerDay to kara za każdy dzień opóźnienia zwrotu
+const FinePerDay Money = 100
+
+// Currency to waluta, w której biblioteka pobiera kary
+type Currency struct {
+	Code   string // Kod ISO 4217
+	Symbol string
+	Prefix bool // Symbol przed kwotą w zapisie angielskim (np. €12.50)
+}
+
+// Currencies to waluty do wyboru w ustawieniach biblioteki
+var Currencies = []Currency{
+	{Code: "PLN", Symbol: "zł"},
+	{Code: "EUR", Symbol: "€", Prefix: true},
+	{Code: "CZK", Symbol: "Kč"},
+	{Code: "USD", Symbol: "$", Prefix: true},
+	{Code: "GBP", Symbol: "£", Prefix: true},
+}
+
+// Locale to język zapisu kwot (separatory i położenie symbolu waluty) oraz dat
+type Locale struct {
+	Code     string
+	Name     string
+	Language string // Nazwa języka na liście zapisu dat wybieranego przez czytelnika
+}
+
+// Locales to języki formatowania kwot i dat do wyboru w ustawieniach biblioteki
+var Locales = []Locale{
+	{Code: "pl", Name: "polski (1 234,50 zł, 16.10.2026)", Language: "polski"},
+	{Code: "en", Name: "angielski (1,234.50 PLN, Oct 16, 2026)", Language: "angielski"},
+}
+
+// ValidLocale sprawdza czy język formatowania kwot i dat jest obsługiwany
+func ValidLocale(code string) bool {
+	for _, l := range Locales {
+		if l.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// FindCurrency zwraca walutę o podanym kodzie
+func FindCurrency(code string) (Currency, bool) {
+	for _, c := range Currencies {
+		if c.Code == code {
+			return c, true
+		}
+	}
+	return Currency{}, false
+}
+
+// ParseMoney odczytuje kwotę wpisaną w formularzu ("12", "12,5", "1 234.50", "1.234,50", "1,234.50").
+// Separatorem dziesiętnym jest ostatni przecinek lub kropka, gdy po kwocie występują oba znaki
+// albo jeden z nich tylko raz; pozostałe przecinki, kropki i spacje rozdzielają tysiące.
+// Nie przyjmuje kwot ujemnych ani z więcej niż dwoma miejscami po przecinku.
+func ParseMoney(value string) (Money, error) {
+	value = strings.NewReplacer(" ", "", "\u00a0", "").Replace(strings.TrimSpace(value))
+	units, fraction, ok := splitAmount(value)
+	if !ok || units == "" && fraction == "" || len(fraction) > 2 || strings.HasPrefix(units, "-") || strings.HasPrefix(units, "+") {
+		return 0, fmt.Errorf("nieprawidłowa kwota")
+	}
+
+	var whole int64
+	if units != "" {
+		n, err := strconv.ParseInt(units, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("nieprawidłowa kwota")
+		}
+		whole = n
+	}
+
+	var cents int64
+	if fraction != "" {
+		n, err := strconv.ParseUint(fraction, 10, 8)
+		if err != nil {
+			return 0, fmt.Errorf("nieprawidłowa kwota")
+		}
+		cents = int64(n)
+		if len(fraction) == 1 {
+			cents *= 10
+		}
+	}
+
+	return Money(whole*100 + cents), nil
+}
+
+// splitAmount dzieli kwotę na część całkowitą bez separatorów tysięcy i część ułamkową.
+// Grupy tysięcy po pierwszej muszą mieć po trzy cyfry.
+func splitAmount(value string) (units, fraction string, ok bool) {
+	lastComma, lastDot := strings.LastIndex(value, ","), strings.LastIndex(value, ".")
+	decimal := ""
+	switch {
+	case lastComma >= 0 && lastDot >= 0:
+		decimal = "."
+		if lastComma > lastDot {
+			decimal = ","
+		}
+	case strings.Count(value, ",") == 1:
+		decimal = ","
+	case strings.Count(value, ".") == 1:
+		decimal = "."
+	}
+
+	units = value
+	if decimal != "" {
+		if strings.Count(value, decimal) > 1 {
+			return "", "", false
+		}
+		units, fraction, _ = strings.Cut(value, decimal)
+	}
+
+	groupSep := ","
+	if strings.Contains(units, ".") {
+		groupSep = "."
+	}
+	groups := strings.Split(units, groupSep)
+	for i, group := range groups {
+		if i == 0 && len(groups) > 1 && (group == "" || len(group) > 3) || i > 0 && len(group) != 3 {
+			return "", "", false
+		}
+	}
+	return strings.Join(groups, ""), fraction, true
+}
+
+// Float zwraca kwotę w jednostkach waluty
+func (m Money) Float() float64 {
+	return float64(m) / 100
+}
+
+// String zwraca kwotę w postaci do pól formularzy i logów ("1234.50")
+func (m Money) String() string {
+	sign := ""
+	if m < 0 {
+		sign, m = "-", -m
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, int64(m)/100, int64(m)%100)
+}
+
+// MarshalJSON zapisuje kwotę jako liczbę w jednostkach waluty,
+// tak jak przed wprowadzeniem typu Money (zgodność JSON API)
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON odczytuje kwotę zapisaną przez MarshalJSON (liczba w jednostkach waluty)
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var amount float64
+	if err := json.Unmarshal(data, &amount); err != nil {
+		return fmt.Errorf("nieprawidłowa kwota: %w", err)
+	}
+	*m = Money(math.Round(amount * 100))
+	return nil
+}
+
+// Format zwraca kwotę z walutą w zapisie języka locale ("pl" lub "en")
+func (m Money) Format(locale, currencyCode string) string {
+	currency, ok := FindCurrency(currencyCode)
+	if !ok {
+		currency = Currency{Code: currencyCode, Symbol: currencyCode}
+	}
+
+	groupSep, decimalSep := "\u00a0", ","
+	if locale == "en" {
+		groupSep, decimalSep = ",", "."
+	}
+
+	sign := ""
+	if m < 0 {
+		sign, m = "-", -m
+	}
+
+	units := strconv.FormatInt(int64(m)/100, 10)
+	var grouped strings.Builder
+	for i, digit := range units {
+		if i > 0 && (len(units)-i)%3 == 0 {
+			grouped.WriteString(groupSep)
+		}
+		grouped.WriteRune(digit)
+	}
+	amount := fmt.Sprintf("%s%s%02d", grouped.String(), decimalSep, int64(m)%100)
+
+	switch {
+	case locale != "en":
+		return sign + amount + "\u00a0" + currency.Symbol
+	case currency.Prefix:
+		return sign + currency.Symbol + amount
+	default:
+		return sign + amount + "\u00a0" + currency.Code
+	}
+}
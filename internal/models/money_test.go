@@ -0,0 +1,110 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Money
+	}{
+		{"12", 1200},
+		{"12,5", 1250},
+		{"12.05", 1205},
+		{",5", 50},
+		{"1 234.50", 123450},
+		{"1\u00a0234,50", 123450},
+		{"1.234,50", 123450},
+		{"1,234.50", 123450},
+		{"1,234,567.89", 123456789},
+		{"1.234.567", 123456700},
+		{"  7  ", 700},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseMoney(tt.input)
+			if err != nil {
+				t.Fatalf("ParseMoney(%q) zwrócił błąd: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMoney(%q) = %d, oczekiwano %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMoneyRejectsInvalid(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"abc",
+		"-5",
+		"+5",
+		"1,234",
+		"12.345",
+		"1,2,3",
+		"12,34,5.00",
+		"1.234.5,6,7",
+		",234.50",
+		"1,234.5.0",
+	} {
+		t.Run(input, func(t *testing.T) {
+			if got, err := ParseMoney(input); err == nil {
+				t.Errorf("ParseMoney(%q) = %d, oczekiwano błędu", input, got)
+			}
+		})
+	}
+}
+
+func TestMoneyFormat(t *testing.T) {
+	tests := []struct {
+		amount   Money
+		locale   string
+		currency string
+		want     string
+	}{
+		{123450, "pl", "PLN", "1\u00a0234,50\u00a0zł"},
+		{5, "pl", "PLN", "0,05\u00a0zł"},
+		{-1250, "pl", "EUR", "-12,50\u00a0€"},
+		{123450, "en", "PLN", "1,234.50\u00a0PLN"},
+		{123456789, "en", "EUR", "€1,234,567.89"},
+		{-1250, "en", "USD", "-$12.50"},
+		{1000, "pl", "XYZ", "10,00\u00a0XYZ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.amount.Format(tt.locale, tt.currency); got != tt.want {
+				t.Errorf("Money(%d).Format(%q, %q) = %q, oczekiwano %q", tt.amount, tt.locale, tt.currency, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyJSONRoundTrip(t *testing.T) {
+	for _, amount := range []Money{0, 5, 1250, 123456789, -1999} {
+		data, err := json.Marshal(amount)
+		if err != nil {
+			t.Fatalf("json.Marshal(%d): %v", amount, err)
+		}
+		var got Money
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("json.Unmarshal(%s): %v", data, err)
+		}
+		if got != amount {
+			t.Errorf("kwota %d po zapisie %s odczytana jako %d", amount, data, got)
+		}
+	}
+
+	var fine struct {
+		Fine Money `json:"fine"`
+	}
+	if err := json.Unmarshal([]byte(`{"fine": 12.5}`), &fine); err != nil || fine.Fine != 1250 {
+		t.Errorf("odczyt {\"fine\": 12.5} = %d, %v; oczekiwano 1250", fine.Fine, err)
+	}
+	if err := json.Unmarshal([]byte(`{"fine": "12"}`), &fine); err == nil {
+		t.Error("odczyt kwoty zapisanej jako tekst powinien zwrócić błąd")
+	}
+}
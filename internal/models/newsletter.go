@@ -0,0 +1,59 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// NewsletterIssue to wysłane wydanie newslettera. ID to miesiąc (np. 2026-03), dzięki
+// czemu każdy miesiąc wysyłany jest tylko raz.
+type NewsletterIssue struct {
+	ID            string    `json:"id" firestore:"-"`
+	Month         time.Time `json:"month" firestore:"month"` // Pierwszy dzień miesiąca
+	Books         int       `json:"books" firestore:"books"` // Liczba nowości w wydaniu
+	Announcements int       `json:"announcements" firestore:"announcements"`
+	Recipients    int       `json:"recipients" firestore:"recipients"`
+	StartedAt     time.Time `json:"started_at" firestore:"started_at"`
+	FinishedAt    time.Time `json:"finished_at,omitempty" firestore:"finished_at,omitempty"` // Zerowa - wysyłka trwa albo została przerwana
+}
+
+// Newsletter to treść wydania złożona z katalogu i ogłoszeń
+type Newsletter struct {
+	Month         time.Time
+	Categories    []NewsletterCategory
+	Announcements []*Announcement
+}
+
+// NewsletterCategory to nowości z jednej kategorii
+type NewsletterCategory struct {
+	Name  string
+	Books []*Book
+}
+
+// IsEmpty sprawdza, czy w miesiącu nie było nowości ani ogłoszeń
+func (n *Newsletter) IsEmpty() bool {
+	return len(n.Categories) == 0 && len(n.Announcements) == 0
+}
+
+// BookCount zwraca liczbę nowości w wydaniu
+func (n *Newsletter) BookCount() int {
+	count := 0
+	for _, category := range n.Categories {
+		count += len(category.Books)
+	}
+	return count
+}
+
+// NewsletterIssueID zwraca ID wydania za miesiąc, do którego należy chwila t
+func NewsletterIssueID(t time.Time) string {
+	return t.Format("2006-01")
+}
+
+// MonthNames to nazwy miesięcy w mianowniku
+var MonthNames = []string{"styczeń", "luty", "marzec", "kwiecień", "maj", "czerwiec",
+	"lipiec", "sierpień", "wrzesień", "październik", "listopad", "grudzień"}
+
+// MonthName zwraca nazwę miesiąca z rokiem, np. "marzec 2026"
+func MonthName(t time.Time) string {
+	return fmt.Sprintf("%s %d", MonthNames[t.Month()-1], t.Year())
+}
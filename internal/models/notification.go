@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Notification reprezentuje powiadomienie w aplikacji wyświetlane użytkownikowi
+type Notification struct {
+	ID        string    `json:"id" firestore:"id"`
+	UserID    string    `json:"user_id" firestore:"user_id"`
+	Title     string    `json:"title" firestore:"title"`
+	Body      string    `json:"body" firestore:"body"`
+	Link      string    `json:"link" firestore:"link"` // Opcjonalny link do szczegółów
+	Read      bool      `json:"read" firestore:"read"`
+	CreatedAt time.Time `json:"created_at" firestore:"created_at"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// DeliveryChannel to kanał, którym powiadomienie wyszło poza aplikację
+type DeliveryChannel string
+
+const (
+	DeliveryEmail    DeliveryChannel = "email"
+	DeliveryPush     DeliveryChannel = "push"
+	DeliveryTelegram DeliveryChannel = "telegram"
+)
+
+// DeliveryChannels to kanały w kolejności wyświetlania w filtrach dziennika
+var DeliveryChannels = []DeliveryChannel{DeliveryEmail, DeliveryPush, DeliveryTelegram}
+
+// Label zwraca nazwę kanału wyświetlaną w dzienniku
+func (c DeliveryChannel) Label() string {
+	switch c {
+	case DeliveryEmail:
+		return "Email"
+	case DeliveryPush:
+		return "Push"
+	case DeliveryTelegram:
+		return "Telegram"
+	default:
+		return string(c)
+	}
+}
+
+// DeliveryStatus to wynik wysyłki
+type DeliveryStatus string
+
+const (
+	DeliverySent   DeliveryStatus = "sent"
+	DeliveryFailed DeliveryStatus = "failed"
+)
+
+// NotificationDelivery to wpis dziennika wysyłek: jedna próba dostarczenia powiadomienia
+// jednym kanałem. Treść jest zapisana, żeby nieudaną wysyłkę dało się ponowić.
+type NotificationDelivery struct {
+	ID        string          `json:"id" firestore:"id"`
+	UserID    string          `json:"user_id" firestore:"user_id"`
+	UserName  string          `json:"user_name" firestore:"user_name"`
+	Channel   DeliveryChannel `json:"channel" firestore:"channel"`
+	Recipient string          `json:"recipient" firestore:"recipient"`               // Adres email, urządzenie albo ID czatu
+	Target    string          `json:"target,omitempty" firestore:"target,omitempty"` // ID subskrypcji push
+	Subject   string          `json:"subject" firestore:"subject"`
+	Body      string          `json:"body" firestore:"body"`
+	Link      string          `json:"link,omitempty" firestore:"link,omitempty"` // Pełny adres otwierany z powiadomienia push
+	Status    DeliveryStatus  `json:"status" firestore:"status"`
+	Response  string          `json:"response,omitempty" firestore:"response,omitempty"`   // Błąd zwrócony przez serwer SMTP, usługę push lub Telegram
+	ResendOf  string          `json:"resend_of,omitempty" firestore:"resend_of,omitempty"` // ID ponawianego wpisu
+	Resent    bool            `json:"resent" firestore:"resent"`                           // Wysyłkę już ponowiono
+	CreatedAt time.Time       `json:"created_at" firestore:"created_at"`
+}
+
+// CanResend sprawdza, czy personel może ponowić wysyłkę
+func (d *NotificationDelivery) CanResend() bool {
+	return d.Status == DeliveryFailed && !d.Resent
+}
+
+// DeliveryFilter zawęża dziennik wysyłek. Puste pola nie filtrują.
+type DeliveryFilter struct {
+	Channel DeliveryChannel
+	Status  DeliveryStatus
+	UserID  string
+}
@@ -0,0 +1,109 @@
+package models
+
+// NotificationCategory to rodzaj powiadomień, dla którego czytelnik wybiera kanały
+type NotificationCategory string
+
+const (
+	NotifyReservations NotificationCategory = "reservations" // Rezerwacja gotowa do odbioru, kod do skrytki
+	NotifyDueDates     NotificationCategory = "due_dates"    // Zbliżający się termin zwrotu
+	NotifyDiscussions  NotificationCategory = "discussions"  // Wzmianki w komentarzach
+	NotifyCatalog      NotificationCategory = "catalog"      // Nowości obserwowanych autorów i zapisane wyszukiwania
+	NotifyNewsletter   NotificationCategory = "newsletter"   // Comiesięczny newsletter
+	NotifyLibrary      NotificationCategory = "library"      // Wiadomości personelu do grup czytelników
+)
+
+// NotificationCategoryInfo opisuje kategorię powiadomień na stronie preferencji
+type NotificationCategoryInfo struct {
+	Category    NotificationCategory
+	Label       string
+	Description string
+	Defaults    []DeliveryChannel // Kanały, dopóki czytelnik nie wybierze własnych
+	Channels    []DeliveryChannel // Kanały do wyboru (nil - wszystkie)
+}
+
+// NotificationCategories to kategorie powiadomień w kolejności wyświetlania. Domyślnie
+// push dostają tylko pilne powiadomienia, a email i Telegram - wszystkie. Newsletter
+// trafia tylko do czytelników, którzy zgodzili się na informacje marketingowe (ConsentTypes).
+var NotificationCategories = []NotificationCategoryInfo{
+	{
+		Category:    NotifyReservations,
+		Label:       "Rezerwacje",
+		Description: "Książka czeka na odbiór, kod do skrytki",
+		Defaults:    []DeliveryChannel{DeliveryEmail, DeliveryPush, DeliveryTelegram},
+	},
+	{
+		Category:    NotifyDueDates,
+		Label:       "Terminy zwrotu",
+		Description: "Przypomnienie przed terminem zwrotu",
+		Defaults:    []DeliveryChannel{DeliveryEmail, DeliveryPush, DeliveryTelegram},
+	},
+	{
+		Category:    NotifyDiscussions,
+		Label:       "Dyskusje",
+		Description: "Wzmianki w komentarzach do książek",
+		Defaults:    []DeliveryChannel{DeliveryEmail, DeliveryTelegram},
+	},
+	{
+		Category:    NotifyCatalog,
+		Label:       "Nowości w katalogu",
+		Description: "Obserwowani autorzy i kategorie, zapisane wyszukiwania",
+		Defaults:    []DeliveryChannel{DeliveryEmail, DeliveryTelegram},
+	},
+	{
+		Category:    NotifyNewsletter,
+		Label:       "Newsletter",
+		Description: "Raz w miesiącu: nowości w katalogu według kategorii i ogłoszenia biblioteki",
+		Channels:    []DeliveryChannel{DeliveryEmail},
+	},
+	{
+		Category:    NotifyLibrary,
+		Label:       "Wiadomości z biblioteki",
+		Description: "Komunikaty personelu, np. o zmianie godzin otwarcia lub książkach po terminie",
+		Defaults:    []DeliveryChannel{DeliveryEmail, DeliveryTelegram},
+	},
+}
+
+// GetNotificationCategory zwraca opis kategorii (nil, jeśli nie istnieje)
+func GetNotificationCategory(category NotificationCategory) *NotificationCategoryInfo {
+	for i := range NotificationCategories {
+		if NotificationCategories[i].Category == category {
+			return &NotificationCategories[i]
+		}
+	}
+	return nil
+}
+
+// Offers sprawdza, czy powiadomienia z kategorii można dostawać danym kanałem
+func (info *NotificationCategoryInfo) Offers(channel DeliveryChannel) bool {
+	if info.Channels == nil {
+		return true
+	}
+	for _, c := range info.Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationChannels zwraca kanały, którymi czytelnik chce dostawać powiadomienia z kategorii.
+// Pusta lista oznacza, że z kategorii zostają tylko powiadomienia w aplikacji.
+func (u *User) NotificationChannels(category NotificationCategory) []DeliveryChannel {
+	if channels, ok := u.NotificationPrefs[category]; ok {
+		return channels
+	}
+	if info := GetNotificationCategory(category); info != nil {
+		return info.Defaults
+	}
+	return []DeliveryChannel{DeliveryEmail, DeliveryTelegram}
+}
+
+// WantsNotification sprawdza, czy czytelnik chce dostawać powiadomienia z kategorii danym kanałem
+func (u *User) WantsNotification(category NotificationCategory, channel DeliveryChannel) bool {
+	for _, c := range u.NotificationChannels(category) {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
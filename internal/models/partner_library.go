@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// PartnerLibrary to biblioteka partnerska, z której sprowadzamy książki
+// w ramach wypożyczeń międzybibliotecznych
+type PartnerLibrary struct {
+	ID            string `json:"id" firestore:"id"`
+	Name          string `json:"name" firestore:"name"`
+	Email         string `json:"email" firestore:"email"` // Adres działu wypożyczeń międzybibliotecznych
+	Phone         string `json:"phone" firestore:"phone"`
+	Address       string `json:"address" firestore:"address"` // Adres korespondencyjny (wiele linii)
+	ContactPerson string `json:"contact_person" firestore:"contact_person"`
+
+	// Warunki wypożyczania
+	LoanDays int    `json:"loan_days" firestore:"loan_days"` // Okres wypożyczenia u partnera (0 = nieokreślony)
+	Fee      Money  `json:"fee" firestore:"fee_gr"`          // Opłata partnera za wypożyczenie
+	Terms    string `json:"terms" firestore:"terms"`         // Pozostałe warunki (np. tylko na miejscu, zwrot pocztą)
+
+	CreatedAt time.Time `json:"created_at" firestore:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" firestore:"updated_at"`
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// PickupCodeAlphabet określa, z jakich znaków składa się kod odbioru
+type PickupCodeAlphabet string
+
+const (
+	PickupCodeAlphanumeric PickupCodeAlphabet = "alphanumeric" // Wielkie litery i cyfry
+	PickupCodeNumeric      PickupCodeAlphabet = "numeric"      // Same cyfry (wygodniejsze dla skanerów i klawiatur telefonów)
+)
+
+const (
+	MinPickupCodeLength = 4
+	MaxPickupCodeLength = 10
+
+	pickupCodeDigits    = "0123456789"
+	pickupCodeLetters   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	pickupCodeAmbiguous = "0O1I" // Znaki łatwe do pomylenia przy przepisywaniu kodu
+
+	// PickupCodeAttempts to liczba losowań kodu odbioru, zanim utworzenie wypożyczenia zgłosi błąd.
+	// Przy krótkich kodach (np. 4 cyfry) los może trafić w kod innej czekającej książki.
+	PickupCodeAttempts = 20
+)
+
+// PickupCodeFormat opisuje format kodów odbioru nadawanych nowym wypożyczeniom
+type PickupCodeFormat struct {
+	Alphabet         PickupCodeAlphabet
+	Length           int
+	ExcludeAmbiguous bool // Pomija 0/O i 1/I w kodach z literami
+}
+
+// ValidPickupCodeAlphabet sprawdza czy alfabet kodów jest obsługiwany
+func ValidPickupCodeAlphabet(alphabet PickupCodeAlphabet) bool {
+	return alphabet == PickupCodeAlphanumeric || alphabet == PickupCodeNumeric
+}
+
+// IsNumeric sprawdza czy kody składają się z samych cyfr
+func (f PickupCodeFormat) IsNumeric() bool {
+	return f.Alphabet == PickupCodeNumeric
+}
+
+// Charset zwraca znaki, z których losowany jest kod
+func (f PickupCodeFormat) Charset() string {
+	if f.IsNumeric() {
+		return pickupCodeDigits // Bez liter cyfry 0 i 1 nie są niejednoznaczne
+	}
+
+	charset := pickupCodeLetters + pickupCodeDigits
+	if !f.ExcludeAmbiguous {
+		return charset
+	}
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(pickupCodeAmbiguous, r) {
+			return -1
+		}
+		return r
+	}, charset)
+}
+
+// Generate losuje kod odbioru w tym formacie
+func (f PickupCodeFormat) Generate() (string, error) {
+	charset := f.Charset()
+	max := big.NewInt(int64(len(charset)))
+
+	code := make([]byte, f.Length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("błąd generowania kodu odbioru: %w", err)
+		}
+		code[i] = charset[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// Example zwraca przykładowy kod do podpowiedzi w polu formularza
+func (f PickupCodeFormat) Example() string {
+	example := "ABC123456789"
+	if f.IsNumeric() {
+		example = "482751963048"
+	}
+	return example[:f.Length]
+}
+
+// Description zwraca opis formatu do etykiety pola, np. "6 cyfr"
+func (f PickupCodeFormat) Description() string {
+	few := f.Length%10 >= 2 && f.Length%10 <= 4 && (f.Length%100 < 10 || f.Length%100 >= 20)
+	switch {
+	case f.IsNumeric() && few:
+		return strconv.Itoa(f.Length) + " cyfry"
+	case f.IsNumeric():
+		return strconv.Itoa(f.Length) + " cyfr"
+	case few:
+		return strconv.Itoa(f.Length) + " znaki"
+	default:
+		return strconv.Itoa(f.Length) + " znaków"
+	}
+}
+
+// NormalizePickupCode ujednolica wpisany lub zeskanowany kod (wielkie litery, bez spacji
+// i myślników) i sprawdza, czy może być kodem odbioru. Sprawdzany jest tylko ogólny kształt
+// kodu, a nie bieżący format - kody wydane przed zmianą ustawień pozostają ważne.
+func NormalizePickupCode(code string) (string, bool) {
+	code = strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(code)))
+	if len(code) < MinPickupCodeLength || len(code) > MaxPickupCodeLength {
+		return code, false
+	}
+	for _, r := range code {
+		if !strings.ContainsRune(pickupCodeLetters+pickupCodeDigits, r) {
+			return code, false
+		}
+	}
+	return code, true
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// PublicStatsTopTitles to liczba najpopularniejszych tytułów na stronie statystyk
+const PublicStatsTopTitles = 10
+
+// PublicStats to dzienny zrzut publicznych statystyk biblioteki wyświetlany na /stats.
+// Zawiera wyłącznie liczby zbiorcze i tytuły książek - nic, co identyfikuje czytelnika.
+type PublicStats struct {
+	Day          string         `json:"day" firestore:"day"`     // RRRR-MM-DD - dzień, do którego końca liczono statystyki
+	Month        string         `json:"month" firestore:"month"` // RRRR-MM - miesiąc, z którego liczone są wypożyczenia
+	Titles       int            `json:"titles" firestore:"titles"`
+	Volumes      int            `json:"volumes" firestore:"volumes"`
+	Categories   int            `json:"categories" firestore:"categories"`
+	MonthLoans   int            `json:"month_loans" firestore:"month_loans"` // Wypożyczenia od początku miesiąca (bez udostępnień na miejscu)
+	PopularBooks []PopularTitle `json:"popular_books" firestore:"popular_books"`
+	CreatedAt    time.Time      `json:"created_at" firestore:"created_at"`
+}
+
+// PopularTitle to tytuł z liczbą wypożyczeń w miesiącu zrzutu
+type PopularTitle struct {
+	BookID string `json:"book_id" firestore:"book_id"`
+	Title  string `json:"title" firestore:"title"`
+	Author string `json:"author" firestore:"author"`
+	Loans  int    `json:"loans" firestore:"loans"`
+}
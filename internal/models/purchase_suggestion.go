@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// PurchaseSuggestionStatus reprezentuje status propozycji zakupu
+type PurchaseSuggestionStatus string
+
+const (
+	PurchaseSuggestionNew      PurchaseSuggestionStatus = "new"      // Nowa, nierozpatrzona
+	PurchaseSuggestionOrdered  PurchaseSuggestionStatus = "ordered"  // Zamówiona
+	PurchaseSuggestionRejected PurchaseSuggestionStatus = "rejected" // Odrzucona
+)
+
+// PurchaseSuggestion reprezentuje propozycję zakupu książki zgłoszoną przez czytelnika
+type PurchaseSuggestion struct {
+	ID        string                   `json:"id" firestore:"id"`
+	UserID    string                   `json:"user_id" firestore:"user_id"`
+	UserName  string                   `json:"user_name" firestore:"user_name"`
+	Title     string                   `json:"title" firestore:"title"`
+	Author    string                   `json:"author" firestore:"author"`
+	Note      string                   `json:"note" firestore:"note"`
+	BookID    string                   `json:"book_id,omitempty" firestore:"book_id,omitempty"` // Tytuł z katalogu, gdy personel proponuje dokupienie egzemplarzy
+	Status    PurchaseSuggestionStatus `json:"status" firestore:"status"`
+	CreatedAt time.Time                `json:"created_at" firestore:"created_at"`
+	UpdatedAt time.Time                `json:"updated_at" firestore:"updated_at"`
+}
+
+// StatusLabel zwraca polską nazwę statusu
+func (p *PurchaseSuggestion) StatusLabel() string {
+	switch p.Status {
+	case PurchaseSuggestionOrdered:
+		return "Zamówiona"
+	case PurchaseSuggestionRejected:
+		return "Odrzucona"
+	default:
+		return "Nowa"
+	}
+}
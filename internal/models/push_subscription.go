@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// PushSubscription to zgoda przeglądarki czytelnika na powiadomienia Web Push
+// (jedno urządzenie). Endpoint, P256dh i Auth pochodzą z PushSubscription.toJSON().
+type PushSubscription struct {
+	ID        string    `json:"id" firestore:"id"`
+	UserID    string    `json:"user_id" firestore:"user_id"`
+	Endpoint  string    `json:"endpoint" firestore:"endpoint"` // Adres usługi push przeglądarki
+	P256dh    string    `json:"p256dh" firestore:"p256dh"`     // Klucz publiczny przeglądarki (base64url)
+	Auth      string    `json:"auth" firestore:"auth"`         // Sekret uwierzytelniający (base64url)
+	Device    string    `json:"device" firestore:"device"`     // Opis urządzenia z User-Agent, np. "Chrome, Android"
+	CreatedAt time.Time `json:"created_at" firestore:"created_at"`
+}
@@ -0,0 +1,203 @@
+package models
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/mail"
+	"strings"
+	"unicode"
+)
+
+// ReaderImportMaxRows to największa liczba czytelników w jednym pliku importu - każde
+// konto to kilka wywołań Firebase, więc większe bazy importuje się w częściach
+const ReaderImportMaxRows = 500
+
+// readerImportColumns mapuje nagłówki kolumn akceptowane w pliku (po zamianie na małe
+// litery) na pola wiersza. Eksporty starych systemów różnie zapisują polskie znaki;
+// nazwy angielskie obejmują też kolumny eksportu czytelników Koha (borrowers.csv).
+var readerImportColumns = map[string]string{
+	"imię": "first_name", "imie": "first_name", "first_name": "first_name", "firstname": "first_name",
+	"nazwisko": "last_name", "last_name": "last_name", "surname": "last_name",
+	"email": "email", "e-mail": "email", "adres email": "email",
+	"telefon": "phone", "phone": "phone",
+	"numer karty": "card_number", "nr karty": "card_number", "karta": "card_number", "card_number": "card_number", "cardnumber": "card_number",
+	"saldo": "balance", "kary": "balance", "należności": "balance", "naleznosci": "balance", "balance": "balance",
+}
+
+// ReaderImportRow to jeden czytelnik z pliku importu wraz z wynikiem walidacji i importu
+type ReaderImportRow struct {
+	Line       int // Numer wiersza w pliku (nagłówek to wiersz 1)
+	FirstName  string
+	LastName   string
+	Email      string
+	Phone      string
+	CardNumber string // Numer karty ze starego systemu (pusty = zostanie nadany przy otwarciu karty)
+	Balance    Money  // Nieopłacone należności przeniesione ze starego systemu
+
+	Problems []string // Błędy walidacji i duplikaty - wiersz z problemami nie jest importowany
+	UserID   string   // ID utworzonego profilu (pusty = nie zaimportowano)
+	Warning  string   // Problem po utworzeniu konta, np. niewysłany email z ustawieniem hasła
+}
+
+// Valid sprawdza czy wiersz można zaimportować
+func (r *ReaderImportRow) Valid() bool {
+	return len(r.Problems) == 0
+}
+
+// AddProblem dopisuje problem, przez który wiersz nie zostanie zaimportowany
+func (r *ReaderImportRow) AddProblem(format string, args ...interface{}) {
+	r.Problems = append(r.Problems, fmt.Sprintf(format, args...))
+}
+
+// ParseReaderImport wczytuje czytelników z pliku CSV ze starego systemu. Pierwszy wiersz
+// to nagłówek; wymagane kolumny to imię, nazwisko i email, opcjonalne - telefon, numer
+// karty i saldo. Separatorem może być średnik (eksport z Excela) albo przecinek.
+// Błędy pojedynczych wierszy trafiają do ich Problems, błąd zwracany jest tylko wtedy,
+// gdy pliku nie da się odczytać jako całości. Duplikaty w obrębie pliku są oznaczane;
+// duplikaty z bazą sprawdza klient Firebase.
+func ParseReaderImport(file io.Reader) ([]*ReaderImportRow, error) {
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("błąd odczytu pliku: %w", err)
+	}
+	content = bytes.TrimPrefix(content, []byte("\ufeff"))
+
+	firstLine, _, _ := bytes.Cut(content, []byte("\n"))
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("plik jest pusty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("błąd odczytu nagłówka: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		field, ok := readerImportColumns[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			continue
+		}
+		if _, dup := columns[field]; dup {
+			return nil, fmt.Errorf("kolumna %q występuje w nagłówku więcej niż raz", name)
+		}
+		columns[field] = i
+	}
+	for _, required := range []struct{ field, label string }{{"first_name", "imię"}, {"last_name", "nazwisko"}, {"email", "email"}} {
+		if _, ok := columns[required.field]; !ok {
+			return nil, fmt.Errorf("brak wymaganej kolumny %q w nagłówku", required.label)
+		}
+	}
+
+	var rows []*ReaderImportRow
+	emails := make(map[string]int)
+	cards := make(map[string]int)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			return nil, fmt.Errorf("błąd odczytu wiersza %d: %w", line, err)
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+		if len(rows) == ReaderImportMaxRows {
+			return nil, fmt.Errorf("plik zawiera więcej niż %d czytelników - podziel go na części", ReaderImportMaxRows)
+		}
+
+		value := func(field string) string {
+			i, ok := columns[field]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		row := &ReaderImportRow{
+			Line:       line,
+			FirstName:  value("first_name"),
+			LastName:   value("last_name"),
+			Email:      strings.ToLower(value("email")),
+			Phone:      value("phone"),
+			CardNumber: value("card_number"),
+		}
+		row.validate(value("balance"))
+
+		if row.Email != "" {
+			if first, seen := emails[row.Email]; seen {
+				row.AddProblem("ten sam email co w wierszu %d", first)
+			} else {
+				emails[row.Email] = line
+			}
+		}
+		if row.CardNumber != "" {
+			if first, seen := cards[row.CardNumber]; seen {
+				row.AddProblem("ten sam numer karty co w wierszu %d", first)
+			} else {
+				cards[row.CardNumber] = line
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("plik nie zawiera żadnych czytelników")
+	}
+	return rows, nil
+}
+
+// validate sprawdza pola wiersza i odczytuje saldo
+func (r *ReaderImportRow) validate(balance string) {
+	if r.FirstName == "" || r.LastName == "" {
+		r.AddProblem("brak imienia lub nazwiska")
+	}
+	if r.Email == "" {
+		r.AddProblem("brak adresu email")
+	} else if addr, err := mail.ParseAddress(r.Email); err != nil || addr.Address != r.Email {
+		r.AddProblem("nieprawidłowy adres email")
+	}
+	if r.CardNumber != "" && !isCardNumber(r.CardNumber) {
+		r.AddProblem("numer karty może zawierać tylko litery i cyfry (najwyżej 32 znaki)")
+	}
+	if balance != "" {
+		amount, err := ParseMoney(balance)
+		if err != nil {
+			r.AddProblem("nieprawidłowe saldo %q", balance)
+		} else {
+			r.Balance = amount
+		}
+	}
+}
+
+// isCardNumber sprawdza numer karty ze starego systemu - skaner przy ladzie odczytuje
+// kody z literami i cyframi
+func isCardNumber(number string) bool {
+	if len(number) > 32 {
+		return false
+	}
+	for _, r := range number {
+		if r > unicode.MaxASCII || !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isBlankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
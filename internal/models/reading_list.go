@@ -0,0 +1,81 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxReadingListBooks ogranicza liczbę pozycji na liście lektur
+	MaxReadingListBooks = 100
+
+	// MaxUserReadingLists ogranicza liczbę list jednego czytelnika
+	MaxUserReadingLists = 20
+)
+
+// ReadingList to lista lektur. Listy tematyczne ("Na wakacje", "Laureaci Nobla")
+// przygotowują bibliotekarze; czytelnicy tworzą własne listy, np. dla klubu
+// książki albo na zadanie szkolne. Kolejność BookIDs jest kolejnością wyświetlania.
+type ReadingList struct {
+	ID          string   `json:"id" firestore:"id"`
+	Title       string   `json:"title" firestore:"title"`
+	Slug        string   `json:"slug" firestore:"slug"` // Adres listy: /lists/{slug}
+	Description string   `json:"description" firestore:"description"`
+	BookIDs     []string `json:"book_ids" firestore:"book_ids"`
+	Published   bool     `json:"published" firestore:"published"`
+	ShowOnHome  bool     `json:"show_on_home" firestore:"show_on_home"` // Wyróżniona na stronie głównej
+	// Listy czytelników (osobna kolekcja): właściciel i udostępnianie przez link /lists/shared/{token}
+	OwnerID    string    `json:"owner_id,omitempty" firestore:"owner_id,omitempty"`
+	Shared     bool      `json:"shared" firestore:"shared"`
+	ShareToken string    `json:"-" firestore:"share_token,omitempty"`
+	CreatedAt  time.Time `json:"created_at" firestore:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" firestore:"updated_at"`
+}
+
+// HasBook sprawdza czy książka jest już na liście
+func (l *ReadingList) HasBook(bookID string) bool {
+	for _, id := range l.BookIDs {
+		if id == bookID {
+			return true
+		}
+	}
+	return false
+}
+
+// Normalize sprawdza tytuł i porządkuje pozycje listy (bez pustych i powtórzonych ID)
+func (l *ReadingList) Normalize() error {
+	if l == nil {
+		return fmt.Errorf("lista lektur nie może być nil")
+	}
+
+	l.Title = strings.TrimSpace(l.Title)
+	if l.Title == "" {
+		return fmt.Errorf("tytuł listy lektur jest wymagany")
+	}
+
+	var ids []string
+	for _, id := range l.BookIDs {
+		if id != "" && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > MaxReadingListBooks {
+		return fmt.Errorf("lista lektur może mieć najwyżej %d pozycji", MaxReadingListBooks)
+	}
+	l.BookIDs = ids
+
+	return nil
+}
+
+// NewShareToken zwraca losowy token linku do listy czytelnika (niemożliwy do odgadnięcia)
+func NewShareToken() (string, error) {
+	buf := make([]byte, 18)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("błąd generowania linku do listy: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Regulations to jedna wersja regulaminu biblioteki. Opublikowanej wersji się nie
+// edytuje - zmiana treści to nowa wersja, którą czytelnicy muszą zaakceptować.
+type Regulations struct {
+	Version         int       `json:"version" firestore:"version"`
+	Body            string    `json:"body" firestore:"body"`       // Treść w Markdown
+	Changes         string    `json:"changes" firestore:"changes"` // Krótki opis zmian pokazywany przy akceptacji
+	PublishedAt     time.Time `json:"published_at" firestore:"published_at"`
+	PublishedBy     string    `json:"published_by" firestore:"published_by"`           // ID administratora
+	PublishedByName string    `json:"published_by_name" firestore:"published_by_name"` // Imię i nazwisko w chwili publikacji
+}
+
+// RegulationsAcceptance to akceptacja jednej wersji regulaminu przez czytelnika
+type RegulationsAcceptance struct {
+	UserID     string    `json:"user_id" firestore:"user_id"`
+	Version    int       `json:"version" firestore:"version"`
+	AcceptedAt time.Time `json:"accepted_at" firestore:"accepted_at"`
+}
+
+// NeedsRegulations sprawdza, czy czytelnik musi zaakceptować obowiązującą wersję
+// regulaminu (current == nil, gdy biblioteka nie opublikowała regulaminu).
+// Personel regulaminu nie akceptuje.
+func (u *User) NeedsRegulations(current *Regulations) bool {
+	return current != nil && u.Role == RoleReader && u.RegulationsVersion < current.Version
+}
@@ -24,6 +24,14 @@ type Reservation struct {
 	ReservationDate time.Time         `json:"reservation_date" firestore:"reservation_date"`
 	ExpiryDate      time.Time         `json:"expiry_date" firestore:"expiry_date"`                         // Data wygaśnięcia rezerwacji
 	NotifiedDate    *time.Time        `json:"notified_date,omitempty" firestore:"notified_date,omitempty"` // Kiedy powiadomiono użytkownika
+	CompletedAt     *time.Time        `json:"completed_at,omitempty" firestore:"completed_at,omitempty"`   // Kiedy rezerwacja zamieniła się w wypożyczenie
+	PickupLocation  string            `json:"pickup_location" firestore:"pickup_location"`                 // Miejsce odbioru wybrane przez czytelnika (puste = wypożyczalnia)
+	WorkKey         string            `json:"-" firestore:"work_key,omitempty"`                            // Klucz utworu (search.WorkKey) - pozwala przydzielić egzemplarz innego wydania
+	ISBNKey         string            `json:"-" firestore:"isbn_key,omitempty"`                            // Znormalizowany ISBN (search.ISBNKey) - pozwala przydzielić egzemplarz zdublowanego rekordu
+	EditionOnly     bool              `json:"edition_only" firestore:"edition_only"`                       // Czytelnik czeka tylko na to wydanie (np. duży druk)
+	LockerID        string            `json:"locker_id,omitempty" firestore:"locker_id,omitempty"`         // Skrytka przydzielona przez system paczkomatów
+	LockerCode      string            `json:"-" firestore:"locker_code,omitempty"`                         // Kod otwarcia skrytki (tylko dla czytelnika)
+	SuspendedAt     *time.Time        `json:"suspended_at,omitempty" firestore:"suspended_at,omitempty"`   // Kiedy zwrócony egzemplarz ominął czytelnika z pełnym limitem wypożyczeń (jedno powiadomienie)
 	Notes           string            `json:"notes" firestore:"notes"`
 	CreatedAt       time.Time         `json:"created_at" firestore:"created_at"`
 	UpdatedAt       time.Time         `json:"updated_at" firestore:"updated_at"`
@@ -39,6 +47,22 @@ func (r *Reservation) CanBeCompleted() bool {
 	return r.Status == ReservationStatusReady && !r.IsExpired()
 }
 
+// BorrowWait zwraca czas od złożenia rezerwacji do wypożyczenia książki. Rezerwacje
+// zrealizowane przed zapisywaniem CompletedAt korzystają z daty ostatniej zmiany.
+func (r *Reservation) BorrowWait() (time.Duration, bool) {
+	if r.Status != ReservationStatusCompleted {
+		return 0, false
+	}
+	completed := r.UpdatedAt
+	if r.CompletedAt != nil {
+		completed = *r.CompletedAt
+	}
+	if completed.Before(r.ReservationDate) {
+		return 0, false
+	}
+	return completed.Sub(r.ReservationDate), true
+}
+
 // DaysUntilExpiry zwraca liczbę dni do wygaśnięcia rezerwacji
 func (r *Reservation) DaysUntilExpiry() int {
 	if r.Status != ReservationStatusReady {
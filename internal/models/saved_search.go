@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// SavedSearch reprezentuje zapisane wyszukiwanie czytelnika z alertem dostępności
+type SavedSearch struct {
+	ID     string `json:"id" firestore:"id"`
+	UserID string `json:"user_id" firestore:"user_id"`
+	Query  string `json:"query" firestore:"query"` // np. author:"Sapkowski" available:true
+	// MatchKey to słowo z zapytania, po którym wyszukiwanie jest wybierane przy zdarzeniach katalogu
+	MatchKey string `json:"match_key" firestore:"match_key"`
+	// NotifiedBooks to czas ostatniego powiadomienia o danej książce (ID książki -> czas)
+	NotifiedBooks  map[string]time.Time `json:"notified_books,omitempty" firestore:"notified_books,omitempty"`
+	LastNotifiedAt *time.Time           `json:"last_notified_at,omitempty" firestore:"last_notified_at,omitempty"`
+	CreatedAt      time.Time            `json:"created_at" firestore:"created_at"`
+}
+
+// WasNotifiedAbout sprawdza czy czytelnik dostał powiadomienie o książce po podanym czasie
+func (s *SavedSearch) WasNotifiedAbout(bookID string, since time.Time) bool {
+	notifiedAt, ok := s.NotifiedBooks[bookID]
+	return ok && notifiedAt.After(since)
+}
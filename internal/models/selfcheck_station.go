@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// SelfCheckStation to stanowisko samoobsługowe (automat z czytnikiem RFID lub kodów kreskowych),
+// przez które czytelnicy sami wypożyczają i zwracają książki. Stanowisko uwierzytelnia się
+// w API własnym tokenem - zapisywany jest tylko jego skrót.
+type SelfCheckStation struct {
+	ID         string     `json:"id" firestore:"id"`
+	Name       string     `json:"name" firestore:"name"`    // Nazwa widoczna dla personelu (np. "Automat przy wejściu")
+	TokenHash  string     `json:"-" firestore:"token_hash"` // SHA-256 tokenu stanowiska (hex)
+	CreatedAt  time.Time  `json:"created_at" firestore:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" firestore:"last_used_at,omitempty"` // Ostatnie wypożyczenie lub zwrot
+}
@@ -0,0 +1,175 @@
+package models
+
+import "time"
+
+// Settings to ustawienia biblioteki zapisywane w jednym dokumencie Firestore
+// (tworzone przez kreator pierwszego uruchomienia /setup)
+type Settings struct {
+	LibraryName string `json:"library_name" firestore:"library_name"`
+	LoanDays    int    `json:"loan_days" firestore:"loan_days"`     // Okres wypożyczenia w dniach
+	MaxLoans    int    `json:"max_loans" firestore:"max_loans"`     // Domyślny limit wypożyczeń nowego czytelnika
+	PickupDays  int    `json:"pickup_days" firestore:"pickup_days"` // Czas na odbiór gotowej rezerwacji w dniach
+	// Najwięcej rezerwacji gotowych do odbioru naraz u jednego czytelnika - kolejne czekają
+	// w kolejce, aż czytelnik odbierze książkę (0 = bez limitu)
+	MaxReadyHolds int `json:"max_ready_holds" firestore:"max_ready_holds"`
+	// Miejsca odbioru rezerwacji do wyboru przez czytelnika (np. wypożyczalnia, czytelnia, paczkomat).
+	// Pusta lista oznacza odbiór w wypożyczalni bez wyboru.
+	PickupLocations []string `json:"pickup_locations" firestore:"pickup_locations"`
+	Currency        string   `json:"currency" firestore:"currency"` // Kod waluty kar (PLN, EUR, ...)
+	Locale          string   `json:"locale" firestore:"locale"`     // Zapis kwot i dat: "pl" lub "en"
+	// Format kodów odbioru nadawanych nowym wypożyczeniom
+	PickupCodeAlphabet         PickupCodeAlphabet `json:"pickup_code_alphabet" firestore:"pickup_code_alphabet"`
+	PickupCodeLength           int                `json:"pickup_code_length" firestore:"pickup_code_length"`
+	PickupCodeExcludeAmbiguous bool               `json:"pickup_code_exclude_ambiguous" firestore:"pickup_code_exclude_ambiguous"`
+	// Dni tygodnia, w które biblioteka jest otwarta (numeracja time.Weekday: 0 = niedziela).
+	// Pusta lista oznacza otwarcie codziennie.
+	OpenDays []int `json:"open_days" firestore:"open_days"`
+	// Bloki strony głównej w kolejności wyświetlania
+	HomeBlocks []HomeBlock `json:"home_blocks" firestore:"home_blocks"`
+	// Liczba książek na stronie katalogu publicznego - kolejne strony doczytują się przy przewijaniu
+	CatalogPageSize int `json:"catalog_page_size" firestore:"catalog_page_size"`
+	// Nowe konta zarejestrowane przez czytelników czekają na zatwierdzenie przez personel
+	// (np. po okazaniu dokumentu tożsamości) - do tego czasu czytelnik tylko przegląda katalog
+	RequireApproval bool `json:"require_approval" firestore:"require_approval"`
+	// Po tylu latach od zwrotu wypożyczenia tracą dane czytelnika, a zostają w statystykach
+	// jako anonimowe (0 = dane przechowywane bezterminowo)
+	LoanRetentionYears int `json:"loan_retention_years" firestore:"loan_retention_years"`
+	// Moderacja komentarzy: zatwierdzanie każdego komentarza przed publikacją
+	// i rdzenie słów, które wstrzymują komentarz do decyzji moderatora
+	CommentPremoderation bool     `json:"comment_premoderation" firestore:"comment_premoderation"`
+	BlockedWords         []string `json:"blocked_words" firestore:"blocked_words"`
+	// Adres sieciowej drukarki paragonów ESC/POS przy ladzie (host:port), na której drukują się
+	// potwierdzenia wydania i zwrotu książki (puste = bez wydruków)
+	ReceiptPrinter string `json:"receipt_printer" firestore:"receipt_printer"`
+	// Dodatkowe odnośniki w pasku nawigacji i stopce stron czytelnika, w kolejności wyświetlania
+	NavbarLinks []MenuLink `json:"navbar_links" firestore:"navbar_links"`
+	FooterLinks []MenuLink `json:"footer_links" firestore:"footer_links"`
+	UpdatedAt   time.Time  `json:"updated_at" firestore:"updated_at"`
+}
+
+// DefaultReceiptPrinterPort to port drukarek paragonów (raw TCP), gdy adres go nie podaje
+const DefaultReceiptPrinterPort = "9100"
+
+// MinLoanRetentionYears to najkrótszy okres przechowywania danych czytelnika w wypożyczeniach -
+// roczne sprawozdanie za miniony rok liczy czytelników i odwiedziny z pełnych danych
+const MinLoanRetentionYears = 2
+
+// CatalogPageSizes to liczby książek na stronie katalogu do wyboru w ustawieniach
+var CatalogPageSizes = []int{12, 24, 48, 96}
+
+// ValidCatalogPageSize sprawdza czy liczba książek na stronie katalogu jest jedną z dostępnych
+func ValidCatalogPageSize(size int) bool {
+	for _, s := range CatalogPageSizes {
+		if s == size {
+			return true
+		}
+	}
+	return false
+}
+
+// Weekday to dzień tygodnia do wyboru w formularzu ustawień
+type Weekday struct {
+	Day  int
+	Name string
+}
+
+// Weekdays to dni tygodnia w kolejności polskiego kalendarza (od poniedziałku)
+var Weekdays = []Weekday{
+	{int(time.Monday), "Poniedziałek"},
+	{int(time.Tuesday), "Wtorek"},
+	{int(time.Wednesday), "Środa"},
+	{int(time.Thursday), "Czwartek"},
+	{int(time.Friday), "Piątek"},
+	{int(time.Saturday), "Sobota"},
+	{int(time.Sunday), "Niedziela"},
+}
+
+// DefaultSettings zwraca ustawienia używane, dopóki dokument ustawień nie zostanie zapisany
+func DefaultSettings() *Settings {
+	return &Settings{
+		LibraryName:        "Biblioteka",
+		LoanDays:           14,
+		MaxLoans:           5,
+		PickupDays:         3,
+		Currency:           "PLN",
+		Locale:             "pl",
+		PickupCodeAlphabet: PickupCodeAlphanumeric,
+		PickupCodeLength:   6,
+		HomeBlocks:         append([]HomeBlock(nil), DefaultHomeBlocks...),
+		CatalogPageSize:    24,
+	}
+}
+
+// FormatMoney zwraca kwotę w walucie i zapisie ustawionym dla biblioteki
+func (s *Settings) FormatMoney(m Money) string {
+	return m.Format(s.Locale, s.Currency)
+}
+
+// DateLocale zwraca zapis dat wybrany przez czytelnika (preferred), a gdy nie wybrał
+// żadnego lub wybrał nieobsługiwany - zapis ustawiony dla biblioteki
+func (s *Settings) DateLocale(preferred string) string {
+	if ValidLocale(preferred) {
+		return preferred
+	}
+	return s.Locale
+}
+
+// FormatDate zwraca datę w zapisie wybranym przez czytelnika lub ustawionym dla biblioteki
+func (s *Settings) FormatDate(t time.Time, preferred string) string {
+	return FormatDate(t, s.DateLocale(preferred))
+}
+
+// FormatDateTime zwraca datę z godziną w zapisie wybranym przez czytelnika lub ustawionym dla biblioteki
+func (s *Settings) FormatDateTime(t time.Time, preferred string) string {
+	return FormatDateTime(t, s.DateLocale(preferred))
+}
+
+// RelativeTime zwraca odległość daty od dzisiaj ("za 3 dni", "2 dni temu") w języku
+// wybranym przez czytelnika lub ustawionym dla biblioteki
+func (s *Settings) RelativeTime(t time.Time, preferred string) string {
+	return RelativeTime(t, time.Now(), s.DateLocale(preferred))
+}
+
+// PickupCode zwraca format kodów odbioru
+func (s *Settings) PickupCode() PickupCodeFormat {
+	return PickupCodeFormat{
+		Alphabet:         s.PickupCodeAlphabet,
+		Length:           s.PickupCodeLength,
+		ExcludeAmbiguous: s.PickupCodeExcludeAmbiguous,
+	}
+}
+
+// HasPickupLocation sprawdza czy miejsce odbioru jest na liście skonfigurowanych miejsc
+func (s *Settings) HasPickupLocation(location string) bool {
+	for _, l := range s.PickupLocations {
+		if l == location {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOpenWeekday sprawdza czy biblioteka jest otwarta w podany dzień tygodnia
+func (s *Settings) IsOpenWeekday(day int) bool {
+	if len(s.OpenDays) == 0 {
+		return true
+	}
+	for _, d := range s.OpenDays {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOpenDay zwraca początek najbliższego dnia otwarcia po dniu t
+func (s *Settings) NextOpenDay(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	for i := 0; i < 7; i++ {
+		day = day.AddDate(0, 0, 1)
+		if s.IsOpenWeekday(int(day.Weekday())) {
+			break
+		}
+	}
+	return day
+}
@@ -0,0 +1,19 @@
+package models
+
+// StaffAction określa rodzaj czynności przy ladzie zliczanej w statystykach personelu
+type StaffAction string
+
+const (
+	StaffActionReturn      StaffAction = "return"       // Przyjęcie zwrotu
+	StaffActionPickup      StaffAction = "pickup"       // Wydanie zamówionej książki
+	StaffActionCatalogEdit StaffAction = "catalog_edit" // Dodanie, zmiana, usunięcie książki lub wycofanie egzemplarzy
+)
+
+// StaffActivityCounter to dzienny licznik czynności jednego pracownika
+type StaffActivityCounter struct {
+	Day       string      `json:"day" firestore:"day"` // RRRR-MM-DD
+	StaffID   string      `json:"staff_id" firestore:"staff_id"`
+	StaffName string      `json:"staff_name" firestore:"staff_name"` // Denormalizacja
+	Action    StaffAction `json:"action" firestore:"action"`
+	Count     int         `json:"count" firestore:"count"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// SubscriptionType określa czego dotyczy subskrypcja nowych tytułów
+type SubscriptionType string
+
+const (
+	SubscriptionAuthor   SubscriptionType = "author"
+	SubscriptionCategory SubscriptionType = "category"
+)
+
+// Subscription reprezentuje subskrypcję nowych książek autora lub z kategorii
+type Subscription struct {
+	ID     string           `json:"id" firestore:"id"`
+	UserID string           `json:"user_id" firestore:"user_id"`
+	Type   SubscriptionType `json:"type" firestore:"type"`
+	Value  string           `json:"value" firestore:"value"` // Nazwa autora lub kategorii
+	// Key to znormalizowany klucz (np. author:andrzej sapkowski) używany w zapytaniach
+	Key       string    `json:"key" firestore:"key"`
+	CreatedAt time.Time `json:"created_at" firestore:"created_at"`
+}
+
+// SubscriptionKey buduje znormalizowany klucz subskrypcji
+func SubscriptionKey(subType SubscriptionType, value string) string {
+	return string(subType) + ":" + strings.Join(strings.Fields(strings.ToLower(value)), " ")
+}
+
+// Label zwraca opis subskrypcji do wyświetlenia
+func (s *Subscription) Label() string {
+	if s.Type == SubscriptionCategory {
+		return "Kategoria: " + s.Value
+	}
+	return "Autor: " + s.Value
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// TelegramLink łączy czat z botem Telegrama z kontem czytelnika jednej z bibliotek sieci
+type TelegramLink struct {
+	ChatID    int64     `json:"chat_id" firestore:"chat_id"`
+	Tenant    string    `json:"tenant" firestore:"tenant"` // Biblioteka czytelnika (pusty = biblioteka główna)
+	UserID    string    `json:"user_id" firestore:"user_id"`
+	CreatedAt time.Time `json:"created_at" firestore:"created_at"`
+}
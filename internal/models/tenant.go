@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Tenant reprezentuje bibliotekę w sieci bibliotek obsługiwanych przez jedno wdrożenie.
+// Dane biblioteki są podkolekcjami dokumentu tenants/{ID}.
+type Tenant struct {
+	ID        string    `json:"id" firestore:"id"` // Krótki identyfikator, np. "filia-polnoc"
+	Name      string    `json:"name" firestore:"name"`
+	Hostnames []string  `json:"hostnames" firestore:"hostnames"` // Domeny, pod którymi biblioteka jest dostępna
+	Active    bool      `json:"active" firestore:"active"`
+	CreatedAt time.Time `json:"created_at" firestore:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" firestore:"updated_at"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// TrashKind określa rodzaj usuniętego obiektu w koszu
+type TrashKind string
+
+const (
+	TrashKindUser TrashKind = "user" // Konto czytelnika
+	TrashKindLoan TrashKind = "loan" // Wypożyczenie
+)
+
+// TrashRetentionDays to liczba dni, przez które usunięty obiekt można przywrócić z kosza -
+// potem nocne zadanie usuwa go na stałe
+const TrashRetentionDays = 30
+
+// TrashItem to usunięty obiekt czekający w koszu. Dokument przechowuje pełną kopię
+// usuniętego dokumentu, więc przywrócenie odtwarza go pod tym samym ID.
+type TrashItem struct {
+	ID        string                 `json:"id" firestore:"-"`
+	Kind      TrashKind              `json:"kind" firestore:"kind"`
+	EntityID  string                 `json:"entity_id" firestore:"entity_id"` // ID dokumentu w kolekcji źródłowej
+	Label     string                 `json:"label" firestore:"label"`         // Opis na liście kosza (np. imię i nazwisko z emailem)
+	Data      map[string]interface{} `json:"-" firestore:"data"`              // Kopia usuniętego dokumentu
+	DeletedAt time.Time              `json:"deleted_at" firestore:"deleted_at"`
+	DeletedBy string                 `json:"deleted_by" firestore:"deleted_by"` // Email pracownika
+}
+
+// PurgeAt zwraca chwilę, po której obiekt zostanie usunięty na stałe
+func (t *TrashItem) PurgeAt() time.Time {
+	return t.DeletedAt.AddDate(0, 0, TrashRetentionDays)
+}
+
+// KindLabel zwraca polską nazwę rodzaju obiektu
+func (t *TrashItem) KindLabel() string {
+	switch t.Kind {
+	case TrashKindUser:
+		return "Konto czytelnika"
+	case TrashKindLoan:
+		return "Wypożyczenie"
+	}
+	return string(t.Kind)
+}
@@ -6,30 +6,119 @@ import "time"
 type UserRole string
 
 const (
-	RoleReader UserRole = "reader" // Czytelnik - może wypożyczać książki
-	RoleAdmin  UserRole = "admin"  // Administrator - pełny dostęp do panelu staff
+	RoleReader    UserRole = "reader"    // Czytelnik - może wypożyczać książki
+	RoleLibrarian UserRole = "librarian" // Bibliotekarz - wypożyczenia, odbiory i katalog w panelu staff
+	RoleAdmin     UserRole = "admin"     // Administrator - pełny dostęp do panelu staff
 )
 
+// Roles to role do wyboru przy edycji konta
+var Roles = []UserRole{RoleReader, RoleLibrarian, RoleAdmin}
+
+// IsStaff sprawdza czy rola daje dostęp do panelu personelu
+func (r UserRole) IsStaff() bool {
+	return r == RoleAdmin || r == RoleLibrarian
+}
+
+// Label zwraca nazwę roli wyświetlaną w panelu
+func (r UserRole) Label() string {
+	switch r {
+	case RoleAdmin:
+		return "Administrator"
+	case RoleLibrarian:
+		return "Bibliotekarz"
+	default:
+		return "Czytelnik"
+	}
+}
+
 // User reprezentuje użytkownika systemu
 type User struct {
-	ID           string    `json:"id" firestore:"id"`
-	FirebaseUID  string    `json:"firebase_uid" firestore:"firebase_uid"` // UID z Firebase Auth
-	Email        string    `json:"email" firestore:"email"`
-	FirstName    string    `json:"first_name" firestore:"first_name"`
-	LastName     string    `json:"last_name" firestore:"last_name"`
-	Role         UserRole  `json:"role" firestore:"role"`
-	Phone        string    `json:"phone" firestore:"phone"`
-	IsActive     bool      `json:"is_active" firestore:"is_active"`
-	MaxLoans     int       `json:"max_loans" firestore:"max_loans"`         // Maksymalna liczba wypożyczeń
-	CurrentLoans int       `json:"current_loans" firestore:"current_loans"` // Aktualna liczba wypożyczeń
-	TotalFines   float64   `json:"total_fines" firestore:"total_fines"`     // Suma kar
-	CreatedAt    time.Time `json:"created_at" firestore:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at" firestore:"updated_at"`
+	ID           string   `json:"id" firestore:"id"`
+	FirebaseUID  string   `json:"firebase_uid" firestore:"firebase_uid"` // UID z Firebase Auth
+	Email        string   `json:"email" firestore:"email"`
+	FirstName    string   `json:"first_name" firestore:"first_name"`
+	LastName     string   `json:"last_name" firestore:"last_name"`
+	Role         UserRole `json:"role" firestore:"role"`
+	Phone        string   `json:"phone" firestore:"phone"`
+	IsActive     bool     `json:"is_active" firestore:"is_active"`
+	MaxLoans     int      `json:"max_loans" firestore:"max_loans"`         // Maksymalna liczba wypożyczeń
+	CurrentLoans int      `json:"current_loans" firestore:"current_loans"` // Aktualna liczba wypożyczeń
+	TotalFines   Money    `json:"total_fines" firestore:"total_fines_gr"`  // Suma kar do zapłaty
+	CardNumber   string   `json:"card_number" firestore:"card_number"`     // Numer karty bibliotecznej (nadawany przy pierwszym otwarciu karty)
+	PINHash      string   `json:"-" firestore:"pin_hash"`                  // Hash bcrypt PIN-u do weryfikacji przez telefon (pusty = brak PIN-u)
+	// Urlop czytelnika: w tych dniach jego rezerwacje czekają w kolejce, a książki trafiają do następnych osób
+	HoldPausedFrom  *time.Time `json:"hold_paused_from,omitempty" firestore:"hold_paused_from,omitempty"`
+	HoldPausedUntil *time.Time `json:"hold_paused_until,omitempty" firestore:"hold_paused_until,omitempty"` // Ostatni dzień urlopu (włącznie)
+	CommentBanned   bool       `json:"comment_banned" firestore:"comment_banned"`                           // Blokada komentowania nałożona przez moderatora
+	PendingApproval bool       `json:"pending_approval" firestore:"pending_approval"`                       // Konto czeka na zatwierdzenie przez personel (gdy biblioteka tego wymaga)
+	PushOnly        bool       `json:"push_only" firestore:"push_only"`                                     // Powiadomienia dostarczone przez push nie są wysyłane emailem
+	TelegramChatID  int64      `json:"-" firestore:"telegram_chat_id,omitempty"`                            // Czat z botem Telegrama połączony z kontem (0 = brak)
+	CalendarToken   string     `json:"-" firestore:"calendar_token,omitempty"`                              // Tajny token w adresie kalendarza iCal (pusty = brak)
+	// Kanały powiadomień wybrane przez czytelnika według kategorii; kategoria bez wpisu ma kanały domyślne
+	NotificationPrefs map[NotificationCategory][]DeliveryChannel `json:"notification_prefs,omitempty" firestore:"notification_prefs,omitempty"`
+	NewsletterToken   string                                     `json:"-" firestore:"newsletter_token,omitempty"` // Tajny token w linku rezygnacji z newslettera (pusty = brak)
+	// Zgody czytelnika z datą i miejscem ostatniej decyzji (rejestr czynności przetwarzania)
+	Consents map[ConsentType]Consent `json:"consents,omitempty" firestore:"consents,omitempty"`
+	// Ostatnia zaakceptowana wersja regulaminu (0 = żadna) i chwila akceptacji
+	RegulationsVersion    int        `json:"regulations_version" firestore:"regulations_version"`
+	RegulationsAcceptedAt *time.Time `json:"regulations_accepted_at,omitempty" firestore:"regulations_accepted_at,omitempty"`
+	// Odznaki za czytanie; czytelnik, który z nich zrezygnował, nie dostaje nowych
+	Badges       []EarnedBadge `json:"badges,omitempty" firestore:"badges,omitempty"`
+	BadgesOptOut bool          `json:"badges_opt_out" firestore:"badges_opt_out"`
+	Locale       string        `json:"locale,omitempty" firestore:"locale,omitempty"` // Zapis dat wybrany przez czytelnika (pusty = ustawienie biblioteki)
+	CreatedAt    time.Time     `json:"created_at" firestore:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at" firestore:"updated_at"`
+
+	Tenant string `json:"-" firestore:"-"` // Biblioteka z sieci, z której wczytano profil (ustawia klient Firebase)
 }
 
 // CanBorrow sprawdza czy użytkownik może wypożyczyć książkę
 func (u *User) CanBorrow() bool {
-	return u.IsActive && u.CurrentLoans < u.MaxLoans
+	return u.IsActive && !u.PendingApproval && u.CurrentLoans < u.MaxLoans
+}
+
+// BorrowRefusal zwraca powód, dla którego czytelnik nie może wypożyczyć książki
+// (pusty, gdy może) - komunikat dla czytelnika w API i na stanowiskach samoobsługowych
+func (u *User) BorrowRefusal() string {
+	switch {
+	case u.CanBorrow():
+		return ""
+	case !u.IsActive:
+		return "Konto nieaktywne - skontaktuj się z biblioteką"
+	case u.PendingApproval:
+		return "Konto czeka na zatwierdzenie - zgłoś się do biblioteki z dokumentem tożsamości"
+	case u.AtLoanLimit():
+		return "Osiągnięto maksymalny limit wypożyczeń"
+	}
+	return "Nie możesz wypożyczyć książki"
+}
+
+// AtLoanLimit sprawdza czy czytelnik wypożyczył już tyle książek, ile pozwala jego limit
+func (u *User) AtLoanLimit() bool {
+	return u.CurrentLoans >= u.MaxLoans
+}
+
+// HasPIN sprawdza czy czytelnik ustawił PIN do weryfikacji przez telefon
+func (u *User) HasPIN() bool {
+	return u.PINHash != ""
+}
+
+// HoldsPausedAt sprawdza czy czytelnik jest w danej chwili na urlopie (rezerwacje wstrzymane)
+func (u *User) HoldsPausedAt(t time.Time) bool {
+	if u.HoldPausedFrom == nil || u.HoldPausedUntil == nil {
+		return false
+	}
+	return !t.Before(*u.HoldPausedFrom) && t.Before(u.HoldPausedUntil.AddDate(0, 0, 1))
+}
+
+// HasBadge sprawdza czy czytelnik ma już odznakę
+func (u *User) HasBadge(badgeID string) bool {
+	for _, earned := range u.Badges {
+		if earned.BadgeID == badgeID {
+			return true
+		}
+	}
+	return false
 }
 
 // IsAdmin sprawdza czy użytkownik jest administratorem
@@ -37,6 +126,11 @@ func (u *User) IsAdmin() bool {
 	return u.Role == RoleAdmin
 }
 
+// IsStaff sprawdza czy użytkownik jest pracownikiem biblioteki (administratorem lub bibliotekarzem)
+func (u *User) IsStaff() bool {
+	return u.Role.IsStaff()
+}
+
 // FullName zwraca pełne imię i nazwisko użytkownika
 func (u *User) FullName() string {
 	return u.FirstName + " " + u.LastName
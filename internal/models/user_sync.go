@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// AuthAccount to konto Firebase Auth bez profilu w Firestore
+type AuthAccount struct {
+	UID       string
+	Email     string
+	CreatedAt time.Time
+}
+
+// UserSyncReport zestawia rozbieżności między Firebase Auth a profilami w Firestore
+type UserSyncReport struct {
+	// OrphanedProfiles to profile, których konto usunięto w Firebase Auth
+	OrphanedProfiles []*User
+	// OrphanedAccounts to konta Auth bez profilu (np. przerwana rejestracja)
+	OrphanedAccounts []AuthAccount
+	CheckedAt        time.Time
+}
+
+// IsClean sprawdza czy nie znaleziono rozbieżności
+func (r *UserSyncReport) IsClean() bool {
+	return len(r.OrphanedProfiles) == 0 && len(r.OrphanedAccounts) == 0
+}
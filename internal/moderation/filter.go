@@ -0,0 +1,92 @@
+// Package moderation sprawdza treści publikowane przez czytelników (komentarze do
+// książek), zanim pojawią się na stronie. Filtr nie odrzuca treści - wskazuje tylko,
+// że ma trafić do kolejki moderacji, gdzie decyzję podejmuje bibliotekarz.
+package moderation
+
+import (
+	"strings"
+	"unicode"
+
+	"library-management-system/internal/search"
+)
+
+// Verdict to wynik sprawdzenia treści
+type Verdict struct {
+	Hold   bool   // Treść czeka na zatwierdzenie zamiast od razu trafić na stronę
+	Reason string // Powód wstrzymania widoczny dla moderatora
+}
+
+// Filter sprawdza treść przed publikacją. Własny filtr (np. zewnętrzną usługę
+// wykrywania wulgaryzmów) podłącza się przy tworzeniu handlera komentarzy.
+type Filter interface {
+	Check(text string) Verdict
+}
+
+// FilterFunc pozwala użyć zwykłej funkcji jako filtra
+type FilterFunc func(text string) Verdict
+
+// Check wywołuje funkcję filtra
+func (f FilterFunc) Check(text string) Verdict {
+	return f(text)
+}
+
+// Chain sprawdza treść kolejnymi filtrami i zwraca pierwszy werdykt wstrzymujący.
+// Puste (nil) filtry są pomijane.
+func Chain(filters ...Filter) Filter {
+	return FilterFunc(func(text string) Verdict {
+		for _, filter := range filters {
+			if filter == nil {
+				continue
+			}
+			if verdict := filter.Check(text); verdict.Hold {
+				return verdict
+			}
+		}
+		return Verdict{}
+	})
+}
+
+// WordList wstrzymuje treść zawierającą słowo zaczynające się od któregoś z podanych
+// rdzeni - bez względu na wielkość liter i znaki diakrytyczne, więc jeden wpis
+// obejmuje też odmiany słowa
+func WordList(words []string) Filter {
+	var stems []string
+	for _, word := range words {
+		if stem := search.Normalize(word); stem != "" {
+			stems = append(stems, stem)
+		}
+	}
+
+	return FilterFunc(func(text string) Verdict {
+		if len(stems) == 0 {
+			return Verdict{}
+		}
+		for _, word := range splitWords(text) {
+			for _, stem := range stems {
+				if strings.HasPrefix(word, stem) {
+					return Verdict{Hold: true, Reason: "Niedozwolone słowo: " + word}
+				}
+			}
+		}
+		return Verdict{}
+	})
+}
+
+// MaxLinks wstrzymuje treść z więcej niż max linkami - typowy objaw spamu
+func MaxLinks(max int) Filter {
+	return FilterFunc(func(text string) Verdict {
+		lower := strings.ToLower(text)
+		links := strings.Count(lower, "http://") + strings.Count(lower, "https://") + strings.Count(lower, "www.")
+		if links > max {
+			return Verdict{Hold: true, Reason: "Zbyt wiele linków"}
+		}
+		return Verdict{}
+	})
+}
+
+// splitWords dzieli tekst na słowa bez znaków diakrytycznych, pomijając interpunkcję
+func splitWords(text string) []string {
+	return strings.FieldsFunc(search.Fold(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
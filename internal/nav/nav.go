@@ -0,0 +1,247 @@
+// Package nav opisuje nawigację stron: menu panelu personelu i konta czytelnika
+// oraz okruszki (breadcrumbs) wyznaczane ze ścieżki żądania. Menu i tytuły stron są
+// w jednym miejscu, więc nowa strona personelu wymaga tylko dopisania jej tutaj.
+package nav
+
+import "strings"
+
+// Item to pozycja menu
+type Item struct {
+	Label string
+	Path  string
+	// Tylko w konsoli sieci bibliotek (biblioteka główna z bibliotekami sieci)
+	NetworkOnly bool
+	// Tylko dla administratorów (bibliotekarz nie ma dostępu do strony)
+	AdminOnly bool
+}
+
+// Section to grupa pozycji menu z nagłówkiem (pusty - pozycje bez nagłówka)
+type Section struct {
+	Title string
+	Items []Item
+}
+
+// Crumb to element okruszków; bieżąca strona nie ma adresu
+type Crumb struct {
+	Label string
+	Path  string
+}
+
+// route to tytuł strony o danym wzorcu ścieżki ({nazwa} pasuje do dowolnego segmentu)
+type route struct {
+	pattern string
+	title   string
+}
+
+// StaffMenu to menu panelu personelu
+var StaffMenu = []Section{
+	{Items: []Item{
+		{Label: "Dashboard", Path: "/staff"},
+	}},
+	{Title: "Wypożyczalnia", Items: []Item{
+		{Label: "Wypożyczenia", Path: "/staff/loans"},
+		{Label: "Oczekujące odbiory", Path: "/staff/pending-pickups"},
+		{Label: "Zwroty", Path: "/staff/returns"},
+		{Label: "Kasa", Path: "/staff/fine-payments", AdminOnly: true},
+		{Label: "Umorzenie kar", Path: "/staff/fine-amnesty", AdminOnly: true},
+		{Label: "Zamówienia międzybiblioteczne", Path: "/staff/ill"},
+	}},
+	{Title: "Zbiory", Items: []Item{
+		{Label: "Katalog", Path: "/staff/catalog"},
+		{Label: "Propozycje zakupów", Path: "/staff/suggestions"},
+		{Label: "Listy lektur", Path: "/staff/lists"},
+		{Label: "Komentarze", Path: "/staff/comments", AdminOnly: true},
+	}},
+	{Title: "Czytelnicy", Items: []Item{
+		{Label: "Użytkownicy", Path: "/staff/users", AdminOnly: true},
+		{Label: "Konta do zatwierdzenia", Path: "/staff/pending-users", AdminOnly: true},
+		{Label: "Wiadomości do czytelników", Path: "/staff/communications", AdminOnly: true},
+		{Label: "Newsletter", Path: "/staff/newsletter", AdminOnly: true},
+		{Label: "Ogłoszenia", Path: "/staff/announcements", AdminOnly: true},
+		{Label: "Odznaki", Path: "/staff/badges", AdminOnly: true},
+	}},
+	{Title: "Raporty", Items: []Item{
+		{Label: "Raporty", Path: "/staff/reports", AdminOnly: true},
+		{Label: "Dziennik zmian", Path: "/staff/audit-log", AdminOnly: true},
+		{Label: "Dziennik wysyłek", Path: "/staff/notifications", AdminOnly: true},
+		{Label: "API", Path: "/staff/api-usage", AdminOnly: true},
+	}},
+	{Title: "Administracja", Items: []Item{
+		{Label: "Ustawienia", Path: "/staff/settings", AdminOnly: true},
+		{Label: "Regulamin", Path: "/staff/regulations", AdminOnly: true},
+		{Label: "Szablony wiadomości", Path: "/staff/templates", AdminOnly: true},
+		{Label: "Stanowiska samoobsługowe", Path: "/staff/selfcheck", AdminOnly: true},
+		{Label: "Sieć bibliotek", Path: "/staff/tenants", NetworkOnly: true, AdminOnly: true},
+		{Label: "Kosz", Path: "/staff/trash", AdminOnly: true},
+	}},
+}
+
+// ReaderMenu to menu konta czytelnika
+var ReaderMenu = []Item{
+	{Label: "Moje wypożyczenia", Path: "/user"},
+	{Label: "Historia", Path: "/user/history"},
+	{Label: "Rezerwacje", Path: "/user/reservations"},
+	{Label: "Zapisane wyszukiwania", Path: "/user/saved-searches"},
+	{Label: "Moje listy", Path: "/user/lists"},
+	{Label: "Zamówienia międzybiblioteczne", Path: "/user/ill"},
+	{Label: "Powiadomienia", Path: "/user/notifications"},
+	{Label: "Karta biblioteczna", Path: "/user/card"},
+	{Label: "PIN telefoniczny", Path: "/user/pin"},
+}
+
+// routes to tytuły stron w okruszkach. Przy niejednoznaczności wygrywa pierwszy
+// pasujący wzorzec, więc stałe ścieżki są przed wzorcami z parametrem.
+var routes = []route{
+	{"/staff", "Panel personelu"},
+	{"/staff/search", "Wyszukiwanie"},
+	{"/staff/catalog", "Katalog"},
+	{"/staff/catalog/search", "Wyniki wyszukiwania"},
+	{"/staff/catalog/new", "Nowa książka"},
+	{"/staff/catalog/labels", "Etykiety"},
+	{"/staff/catalog/{id}/edit", "Edycja książki"},
+	{"/staff/catalog/{id}/label", "Etykieta"},
+	{"/staff/catalog/{id}/history", "Historia egzemplarza"},
+	{"/staff/authors", "Hasła autorów"},
+	{"/staff/loans", "Wypożyczenia"},
+	{"/staff/returns", "Zwroty"},
+	{"/staff/pending-pickups", "Oczekujące odbiory"},
+	{"/staff/users", "Użytkownicy"},
+	{"/staff/users/search", "Wyszukiwanie"},
+	{"/staff/users/sync", "Synchronizacja kont"},
+	{"/staff/users/import", "Import czytelników"},
+	{"/staff/users/{id}/edit", "Edycja konta"},
+	{"/staff/pending-users", "Konta do zatwierdzenia"},
+	{"/staff/trash", "Kosz"},
+	{"/staff/audit-log", "Dziennik zmian"},
+	{"/staff/fine-payments", "Kasa"},
+	{"/staff/fine-payments/{id}/receipt", "Pokwitowanie"},
+	{"/staff/fine-amnesty", "Umorzenie kar"},
+	{"/staff/reports", "Raporty"},
+	{"/staff/reports/weeding", "Selekcja zbiorów"},
+	{"/staff/reports/holds", "Kolejki rezerwacji"},
+	{"/staff/reports/annual", "Sprawozdanie roczne"},
+	{"/staff/reports/staff-activity", "Aktywność personelu"},
+	{"/staff/announcements", "Ogłoszenia"},
+	{"/staff/lists", "Listy lektur"},
+	{"/staff/lists/{id}", "Lista"},
+	{"/staff/comments", "Komentarze"},
+	{"/staff/badges", "Odznaki"},
+	{"/staff/suggestions", "Propozycje zakupów"},
+	{"/staff/ill", "Zamówienia międzybiblioteczne"},
+	{"/staff/ill/partners", "Biblioteki partnerskie"},
+	{"/staff/ill/{id}", "Zamówienie"},
+	{"/staff/templates", "Szablony wiadomości"},
+	{"/staff/templates/{key}", "Edycja szablonu"},
+	{"/staff/notifications", "Dziennik wysyłek"},
+	{"/staff/newsletter", "Newsletter"},
+	{"/staff/communications", "Wiadomości do czytelników"},
+	{"/staff/regulations", "Regulamin"},
+	{"/staff/api-usage", "API"},
+	{"/staff/selfcheck", "Stanowiska samoobsługowe"},
+	{"/staff/settings", "Ustawienia"},
+	{"/staff/tenants", "Sieć bibliotek"},
+
+	{"/user", "Moje konto"},
+	{"/user/history", "Historia"},
+	{"/user/reservations", "Rezerwacje"},
+	{"/user/saved-searches", "Zapisane wyszukiwania"},
+	{"/user/lists", "Moje listy"},
+	{"/user/lists/{id}", "Edycja listy"},
+	{"/user/ill", "Zamówienia międzybiblioteczne"},
+	{"/user/notifications", "Powiadomienia"},
+	{"/user/notifications/settings", "Ustawienia powiadomień"},
+	{"/user/push", "Powiadomienia push"},
+	{"/user/telegram", "Telegram"},
+	{"/user/calendar", "Kalendarz"},
+	{"/user/card", "Karta biblioteczna"},
+	{"/user/pin", "PIN telefoniczny"},
+}
+
+// Page to nawigacja bieżącej strony
+type Page struct {
+	Path        string // Ścieżka żądania (bez prefiksu BASE_PATH)
+	Active      string // Ścieżka pozycji menu, do której należy strona (pusta poza menu)
+	Breadcrumbs []Crumb
+	Librarian   bool // Menu bibliotekarza - bez pozycji tylko dla administratorów
+}
+
+// For wyznacza nawigację strony o podanej ścieżce (bez prefiksu BASE_PATH)
+func For(path string) *Page {
+	if path != "/" {
+		path = strings.TrimRight(path, "/")
+	}
+	page := &Page{Path: path}
+
+	var items []Item
+	for _, section := range StaffMenu {
+		items = append(items, section.Items...)
+	}
+	items = append(items, ReaderMenu...)
+	for _, item := range items {
+		if within(path, item.Path) && len(item.Path) > len(page.Active) {
+			page.Active = item.Path
+		}
+	}
+
+	// Okruszki to kolejne prefiksy ścieżki, które mają tytuł (np. /staff/users/{id} nie ma)
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := range segments {
+		prefix := "/" + strings.Join(segments[:i+1], "/")
+		if title := Title(prefix); title != "" {
+			page.Breadcrumbs = append(page.Breadcrumbs, Crumb{Label: title, Path: prefix})
+		}
+	}
+	if n := len(page.Breadcrumbs); n > 0 && page.Breadcrumbs[n-1].Path == path {
+		page.Breadcrumbs[n-1].Path = ""
+	}
+
+	return page
+}
+
+// Title zwraca tytuł strony o podanej ścieżce (pusty dla stron spoza nawigacji)
+func Title(path string) string {
+	for _, r := range routes {
+		if match(r.pattern, path) {
+			return r.title
+		}
+	}
+	return ""
+}
+
+// IsActive sprawdza czy pozycja menu o podanej ścieżce jest podświetlona
+func (p *Page) IsActive(path string) bool {
+	return p != nil && p.Active == path
+}
+
+// within sprawdza czy ścieżka jest stroną menu albo jej podstroną. Pulpit (/staff, /user)
+// obejmuje tylko siebie - podstrony bez własnej pozycji nie podświetlają pulpitu.
+func within(path, menuPath string) bool {
+	if path == menuPath {
+		return true
+	}
+	if menuPath == "/staff" || menuPath == "/user" {
+		return false
+	}
+	return strings.HasPrefix(path, menuPath+"/")
+}
+
+// match porównuje ścieżkę ze wzorcem segment po segmencie
+func match(pattern, path string) bool {
+	ps := strings.Split(pattern, "/")
+	segments := strings.Split(path, "/")
+	if len(ps) != len(segments) {
+		return false
+	}
+	for i, p := range ps {
+		if strings.HasPrefix(p, "{") && strings.HasSuffix(p, "}") {
+			if segments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if p != segments[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package notifications
+
+import (
+	"log"
+	"strings"
+
+	"library-management-system/internal/events"
+	"library-management-system/internal/models"
+)
+
+// RegisterCommentMentions subskrybuje publikację komentarzy i powiadamia czytelników
+// wspomnianych przez @uchwyt. Uchwyty są rozpoznawane wśród uczestników dyskusji
+// o tej samej książce - wzmianka nie dotrze do osoby, która nie brała w niej udziału.
+func (d *Dispatcher) RegisterCommentMentions() {
+	d.subscribe(events.CommentPublished, func(e events.Event) {
+		if comment, ok := e.Payload.(*models.Comment); ok {
+			d.notifyMentioned(comment)
+		}
+	})
+}
+
+func (d *Dispatcher) notifyMentioned(comment *models.Comment) {
+	mentions := comment.Mentions()
+	if d.fbClient == nil || len(mentions) == 0 {
+		return
+	}
+
+	comments, err := d.fbClient.GetBookComments(comment.BookID)
+	if err != nil {
+		log.Printf("Błąd pobierania dyskusji książki %s: %v", comment.BookID, err)
+		return
+	}
+
+	// Kilku uczestników może mieć ten sam uchwyt (Jan K.) - powiadamiamy wszystkich
+	participants := make(map[string][]string)
+	for _, c := range comments {
+		handle := strings.ToLower(c.AuthorHandle)
+		if c.IsPublished() && !containsUser(participants[handle], c.UserID) {
+			participants[handle] = append(participants[handle], c.UserID)
+		}
+	}
+
+	book, err := d.fbClient.GetBook(comment.BookID)
+	if err != nil {
+		log.Printf("Błąd pobierania książki %s: %v", comment.BookID, err)
+		return
+	}
+
+	notified := map[string]bool{comment.UserID: true}
+	for _, handle := range mentions {
+		for _, userID := range participants[handle] {
+			if notified[userID] {
+				continue
+			}
+			notified[userID] = true
+
+			user, err := d.fbClient.GetUser(userID)
+			if err != nil {
+				log.Printf("Błąd pobierania użytkownika %s: %v", userID, err)
+				continue
+			}
+			if !user.IsActive {
+				continue
+			}
+
+			d.Notify(user, Message{
+				Title:    comment.AuthorName + " wspomina Cię w dyskusji: " + book.Title,
+				Body:     comment.Body,
+				Link:     "/books/" + book.ID + "#comment-" + comment.ID,
+				Category: models.NotifyDiscussions,
+				Email:    models.EmailCommentMention,
+				Vars: map[string]string{
+					"autor": comment.AuthorName,
+					"tytul": book.Title,
+					"tresc": comment.Body,
+				},
+			})
+		}
+	}
+}
+
+func containsUser(ids []string, id string) bool {
+	for _, existing := range ids {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
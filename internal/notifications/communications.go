@@ -0,0 +1,73 @@
+package notifications
+
+import (
+	"log"
+	"time"
+
+	"library-management-system/internal/events"
+	"library-management-system/internal/models"
+)
+
+const (
+	// communicationBatchSize to liczba czytelników w jednej partii wysyłki wiadomości
+	// personelu - serwery SMTP i Telegram ograniczają liczbę wiadomości na minutę
+	communicationBatchSize = 50
+	// communicationBatchPause to przerwa między partiami wysyłki
+	communicationBatchPause = 30 * time.Second
+)
+
+// RegisterCommunications subskrybuje wysyłkę wiadomości personelu do grup czytelników
+// (events.CommunicationQueued)
+func (d *Dispatcher) RegisterCommunications() {
+	d.subscribe(events.CommunicationQueued, func(e events.Event) {
+		comm, ok := e.Payload.(*models.Communication)
+		if !ok {
+			return
+		}
+		d.SendCommunication(comm, communicationBatchPause)
+	})
+}
+
+// SendCommunication wysyła wiadomość personelu każdemu czytelnikowi z grupy odbiorców
+// kanałami, które wybrał dla wiadomości z biblioteki. Wysyłka idzie partiami z przerwą pause
+// między nimi, a po każdej partii zapisywany jest jej postęp.
+func (d *Dispatcher) SendCommunication(comm *models.Communication, pause time.Duration) {
+	if d.fbClient == nil {
+		return
+	}
+
+	recipients, err := d.fbClient.GetCommunicationAudience(comm.Audience)
+	if err != nil {
+		log.Printf("Błąd pobierania odbiorców wiadomości %s: %v", comm.ID, err)
+		return
+	}
+
+	msg := Message{
+		Title:    comm.Subject,
+		Body:     comm.Body,
+		Category: models.NotifyLibrary,
+	}
+	sent := 0
+	for start := 0; start < len(recipients); start += communicationBatchSize {
+		if start > 0 {
+			time.Sleep(pause)
+		}
+		end := min(start+communicationBatchSize, len(recipients))
+		for _, user := range recipients[start:end] {
+			d.Notify(user, msg)
+			sent++
+		}
+
+		finished := end == len(recipients)
+		if err := d.fbClient.UpdateCommunicationProgress(comm.ID, len(recipients), sent, finished); err != nil {
+			log.Printf("Błąd zapisywania postępu wysyłki wiadomości %s: %v", comm.ID, err)
+		}
+	}
+	if len(recipients) == 0 {
+		if err := d.fbClient.UpdateCommunicationProgress(comm.ID, 0, 0, true); err != nil {
+			log.Printf("Błąd zapisywania postępu wysyłki wiadomości %s: %v", comm.ID, err)
+		}
+	}
+
+	log.Printf("Wysłano wiadomość %q do %d czytelników (biblioteka %q)", comm.Subject, sent, d.fbClient.Tenant())
+}
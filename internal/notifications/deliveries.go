@@ -0,0 +1,156 @@
+package notifications
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"library-management-system/internal/models"
+	"library-management-system/internal/webpush"
+)
+
+// deliverEmail wysyła email i zapisuje wynik w dzienniku wysyłek. Bez skonfigurowanego
+// SMTP wiadomość jest tylko logowana, więc nie trafia do dziennika.
+func (d *Dispatcher) deliverEmail(user *models.User, subject, body, resendOf string) error {
+	err := d.mailer.Send(user.Email, subject, body)
+	if err != nil {
+		log.Printf("Błąd wysyłania emaila do %s: %v", user.Email, err)
+	}
+	if d.mailer.IsConfigured() {
+		d.record(user, &models.NotificationDelivery{
+			Channel:   models.DeliveryEmail,
+			Recipient: user.Email,
+			Subject:   subject,
+			Body:      body,
+			ResendOf:  resendOf,
+		}, err)
+	}
+	return err
+}
+
+// deliverTelegram wysyła wiadomość na czat połączony z kontem i zapisuje wynik w dzienniku
+func (d *Dispatcher) deliverTelegram(user *models.User, text, resendOf string) error {
+	err := d.bot.Send(user.TelegramChatID, text)
+	if err != nil {
+		log.Printf("Błąd wysyłania powiadomienia na Telegram do %s: %v", user.ID, err)
+	}
+	d.record(user, &models.NotificationDelivery{
+		Channel:   models.DeliveryTelegram,
+		Recipient: strconv.FormatInt(user.TelegramChatID, 10),
+		Body:      text,
+		ResendOf:  resendOf,
+	}, err)
+	return err
+}
+
+// deliverPush wysyła powiadomienie na jedno urządzenie i zapisuje wynik w dzienniku.
+// Subskrypcję, która wygasła, usuwa.
+func (d *Dispatcher) deliverPush(user *models.User, sub *models.PushSubscription, title, body, link, resendOf string) error {
+	payload, _ := json.Marshal(map[string]string{
+		"title": title,
+		"body":  body,
+		"url":   link,
+	})
+
+	err := d.pusher.Send(sub, payload)
+	switch {
+	case err == nil:
+	case errors.Is(err, webpush.ErrGone):
+		if err := d.fbClient.DeletePushSubscription(user.ID, sub.ID); err != nil {
+			log.Printf("Błąd usuwania wygasłej subskrypcji push %s: %v", sub.ID, err)
+		}
+	default:
+		log.Printf("Błąd wysyłania powiadomienia push do %s (%s): %v", user.ID, sub.Device, err)
+	}
+
+	d.record(user, &models.NotificationDelivery{
+		Channel:   models.DeliveryPush,
+		Recipient: sub.Device,
+		Target:    sub.ID,
+		Subject:   title,
+		Body:      body,
+		Link:      link,
+		ResendOf:  resendOf,
+	}, err)
+	return err
+}
+
+// record zapisuje wynik wysyłki w dzienniku
+func (d *Dispatcher) record(user *models.User, delivery *models.NotificationDelivery, sendErr error) {
+	if d.fbClient == nil {
+		return
+	}
+
+	delivery.UserID = user.ID
+	delivery.UserName = user.FirstName + " " + user.LastName
+	delivery.Status = models.DeliverySent
+	if sendErr != nil {
+		delivery.Status = models.DeliveryFailed
+		delivery.Response = sendErr.Error()
+	}
+
+	if err := d.fbClient.CreateNotificationDelivery(delivery); err != nil {
+		log.Printf("Błąd zapisywania wysyłki w dzienniku: %v", err)
+	}
+}
+
+// Resend ponawia nieudaną wysyłkę z dziennika tym samym kanałem, na obecny adres
+// czytelnika (np. poprawiony email). Nowa próba trafia do dziennika jako osobny wpis.
+func (d *Dispatcher) Resend(deliveryID string) error {
+	delivery, err := d.fbClient.GetNotificationDelivery(deliveryID)
+	if err != nil {
+		return err
+	}
+	if !delivery.CanResend() {
+		return fmt.Errorf("tej wysyłki nie można ponowić")
+	}
+
+	user, err := d.fbClient.GetUser(delivery.UserID)
+	if err != nil {
+		return err
+	}
+
+	switch delivery.Channel {
+	case models.DeliveryEmail:
+		if !d.mailer.IsConfigured() {
+			return fmt.Errorf("wysyłka email nie jest skonfigurowana (SMTP_HOST)")
+		}
+		err = d.deliverEmail(user, delivery.Subject, delivery.Body, delivery.ID)
+
+	case models.DeliveryTelegram:
+		if d.bot == nil || user.TelegramChatID == 0 {
+			return fmt.Errorf("czytelnik nie ma połączonego Telegrama")
+		}
+		err = d.deliverTelegram(user, delivery.Body, delivery.ID)
+
+	case models.DeliveryPush:
+		if d.pusher == nil {
+			return fmt.Errorf("powiadomienia push są wyłączone")
+		}
+		var subs []*models.PushSubscription
+		if subs, err = d.fbClient.GetUserPushSubscriptions(user.ID); err != nil {
+			return err
+		}
+		var target *models.PushSubscription
+		for _, sub := range subs {
+			if sub.ID == delivery.Target {
+				target = sub
+			}
+		}
+		if target == nil {
+			return fmt.Errorf("urządzenie nie ma już włączonych powiadomień push")
+		}
+		err = d.deliverPush(user, target, delivery.Subject, delivery.Body, delivery.Link, delivery.ID)
+
+	default:
+		return fmt.Errorf("nieznany kanał wysyłki: %s", delivery.Channel)
+	}
+
+	// Ponowienie jest oznaczane także po nieudanej próbie - kolejną ponawia się z nowego wpisu
+	if markErr := d.fbClient.MarkNotificationDeliveryResent(delivery.ID); markErr != nil {
+		log.Printf("Błąd oznaczania ponowionej wysyłki %s: %v", delivery.ID, markErr)
+	}
+	return err
+}
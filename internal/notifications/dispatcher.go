@@ -0,0 +1,161 @@
+package notifications
+
+import (
+	"log"
+	"strings"
+
+	"library-management-system/internal/bots/telegram"
+	"library-management-system/internal/events"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+	"library-management-system/internal/webpush"
+)
+
+// Dispatcher dostarcza powiadomienia do użytkowników (w aplikacji, emailem, przez Web Push
+// i na czat Telegrama)
+type Dispatcher struct {
+	fbClient *firebase.Client
+	mailer   *Mailer
+	pusher   *webpush.Sender // nil, gdy nie skonfigurowano kluczy VAPID
+	bot      *telegram.Bot   // nil, gdy bot Telegrama jest wyłączony
+	baseURL  string
+}
+
+// NewDispatcher tworzy nowy dispatcher powiadomień.
+// baseURL (np. https://biblioteka.example.com) służy do budowania linków w emailach,
+// powiadomieniach push i na Telegramie. pusher i bot mogą być nil - wtedy te kanały są pomijane.
+func NewDispatcher(fbClient *firebase.Client, mailer *Mailer, pusher *webpush.Sender, bot *telegram.Bot, baseURL string) *Dispatcher {
+	return &Dispatcher{
+		fbClient: fbClient,
+		mailer:   mailer,
+		pusher:   pusher,
+		bot:      bot,
+		baseURL:  strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// subscribe rejestruje handler zdarzeń dotyczących tylko biblioteki dispatchera
+// (w sieci bibliotek każda biblioteka ma własny dispatcher)
+func (d *Dispatcher) subscribe(eventType events.Type, handler events.Handler) {
+	events.Subscribe(eventType, func(e events.Event) {
+		if d.fbClient != nil && e.Tenant == d.fbClient.Tenant() {
+			handler(e)
+		}
+	})
+}
+
+// Message to treść powiadomienia do wysłania
+type Message struct {
+	Title string
+	Body  string
+	Link  string // ścieżka względna w aplikacji, np. /books/123
+
+	// Category decyduje, którymi kanałami wysłać powiadomienie (preferencje czytelnika)
+	Category models.NotificationCategory
+
+	// Email to szablon wiadomości email (edytowany przez personel), a Vars - wartości jego zmiennych.
+	// Bez szablonu email zawiera tytuł i treść powiadomienia.
+	Email models.EmailTemplateKey
+	Vars  map[string]string
+}
+
+// Notify zapisuje powiadomienie w aplikacji i wysyła je kanałami, które czytelnik wybrał
+// dla kategorii wiadomości: emailem, przez Web Push i na Telegram (jeśli połączył konto z botem).
+// Jeśli czytelnik wybrał push zamiast emaila i powiadomienie dotarło, email nie jest wysyłany.
+func (d *Dispatcher) Notify(user *models.User, msg Message) {
+	if user == nil {
+		return
+	}
+
+	if d.fbClient != nil {
+		notification := &models.Notification{
+			UserID: user.ID,
+			Title:  msg.Title,
+			Body:   msg.Body,
+			Link:   msg.Link,
+		}
+		if err := d.fbClient.CreateNotification(notification); err != nil {
+			log.Printf("Błąd zapisywania powiadomienia dla %s: %v", user.ID, err)
+		}
+	}
+
+	if d.bot != nil && user.TelegramChatID != 0 && user.WantsNotification(msg.Category, models.DeliveryTelegram) {
+		text := msg.Title + "\n\n" + msg.Body
+		if msg.Link != "" {
+			text += "\n" + d.absoluteURL(msg.Link)
+		}
+		d.deliverTelegram(user, text, "")
+	}
+
+	pushed := user.WantsNotification(msg.Category, models.DeliveryPush) && d.push(user, msg)
+
+	if d.mailer != nil && user.WantsNotification(msg.Category, models.DeliveryEmail) && !(pushed && user.PushOnly) {
+		subject, body := d.email(user, msg)
+		d.deliverEmail(user, subject, body, "")
+	}
+}
+
+// email buduje temat i treść emaila z szablonu wiadomości. Jeśli szablonu nie da się
+// pobrać, email zawiera tytuł i treść powiadomienia - czytelnik nie może go nie dostać.
+func (d *Dispatcher) email(user *models.User, msg Message) (subject, body string) {
+	if msg.Email != "" && d.fbClient != nil {
+		template, err := d.fbClient.GetEmailTemplate(msg.Email)
+		if err == nil {
+			return template.Render(d.emailVars(user, d.absoluteURL(msg.Link), msg.Vars))
+		}
+		log.Printf("Błąd pobierania szablonu wiadomości %s: %v", msg.Email, err)
+	}
+
+	body = msg.Body
+	if msg.Link != "" {
+		body += "\n\n" + d.absoluteURL(msg.Link)
+	}
+	return msg.Title, body
+}
+
+// emailVars uzupełnia zmienne wiadomości o wspólne dla wszystkich szablonów:
+// imię czytelnika, nazwę biblioteki i pełny adres linku
+func (d *Dispatcher) emailVars(user *models.User, link string, vars map[string]string) map[string]string {
+	all := map[string]string{
+		"imie":       user.FirstName,
+		"biblioteka": models.DefaultSettings().LibraryName,
+		"link":       link,
+	}
+	if settings, err := d.fbClient.GetSettings(); err == nil {
+		all["biblioteka"] = settings.LibraryName
+	}
+	for name, value := range vars {
+		all[name] = value
+	}
+	return all
+}
+
+// push wysyła powiadomienie na urządzenia użytkownika. Zwraca true, jeśli dotarło
+// na co najmniej jedno urządzenie.
+func (d *Dispatcher) push(user *models.User, msg Message) bool {
+	if d.pusher == nil || d.fbClient == nil {
+		return false
+	}
+
+	subs, err := d.fbClient.GetUserPushSubscriptions(user.ID)
+	if err != nil {
+		log.Printf("Błąd pobierania urządzeń %s: %v", user.ID, err)
+		return false
+	}
+
+	delivered := false
+	for _, sub := range subs {
+		if d.deliverPush(user, sub, msg.Title, msg.Body, d.absoluteURL(msg.Link), "") == nil {
+			delivered = true
+		}
+	}
+	return delivered
+}
+
+// absoluteURL zamienia ścieżkę względną na pełny adres, który da się kliknąć w emailu
+func (d *Dispatcher) absoluteURL(link string) string {
+	if !strings.HasPrefix(link, "/") {
+		return link
+	}
+	return d.baseURL + link
+}
@@ -0,0 +1,39 @@
+package notifications
+
+import (
+	"log"
+
+	"library-management-system/internal/events"
+	"library-management-system/internal/models"
+)
+
+// RegisterDueSoonAlerts subskrybuje przypomnienia nocnego zadania o zbliżającym się
+// terminie zwrotu (events.LoanDueSoon)
+func (d *Dispatcher) RegisterDueSoonAlerts() {
+	d.subscribe(events.LoanDueSoon, func(e events.Event) {
+		if loan, ok := e.Payload.(*models.Loan); ok {
+			d.notifyDueSoon(loan)
+		}
+	})
+}
+
+func (d *Dispatcher) notifyDueSoon(loan *models.Loan) {
+	user, err := d.fbClient.GetUser(loan.UserID)
+	if err != nil {
+		log.Printf("Błąd pobierania użytkownika %s: %v", loan.UserID, err)
+		return
+	}
+
+	d.Notify(user, Message{
+		Title: "Zbliża się termin zwrotu: " + loan.BookTitle,
+		Body: "Termin zwrotu książki " + loan.BookTitle + " mija " + loan.DueDate.Format("02.01.2006") +
+			". Oddaj ją na czas, aby uniknąć kary.",
+		Link:     "/user",
+		Category: models.NotifyDueDates,
+		Email:    models.EmailDueSoon,
+		Vars: map[string]string{
+			"tytul":  loan.BookTitle,
+			"termin": loan.DueDate.Format("02.01.2006"),
+		},
+	})
+}
@@ -0,0 +1,73 @@
+package notifications
+
+import (
+	"fmt"
+	"log"
+	"net/smtp"
+	"os"
+	"strings"
+)
+
+// Mailer wysyła wiadomości email przez SMTP
+type Mailer struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+// NewMailerFromEnv tworzy mailera na podstawie zmiennych środowiskowych
+// (SMTP_HOST, SMTP_PORT, SMTP_USER, SMTP_PASSWORD, SMTP_FROM).
+// Jeśli SMTP_HOST nie jest ustawiony, wiadomości są tylko logowane.
+func NewMailerFromEnv() *Mailer {
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+
+	return &Mailer{
+		host:     os.Getenv("SMTP_HOST"),
+		port:     port,
+		username: os.Getenv("SMTP_USER"),
+		password: os.Getenv("SMTP_PASSWORD"),
+		from:     os.Getenv("SMTP_FROM"),
+	}
+}
+
+// IsConfigured sprawdza czy skonfigurowano serwer SMTP
+func (m *Mailer) IsConfigured() bool {
+	return m.host != ""
+}
+
+// Send wysyła wiadomość email w formacie tekstowym
+func (m *Mailer) Send(to, subject, body string) error {
+	if to == "" {
+		return fmt.Errorf("brak adresu odbiorcy")
+	}
+
+	if !m.IsConfigured() {
+		log.Printf("[email] SMTP nie skonfigurowany - wiadomość do %s: %s", to, subject)
+		return nil
+	}
+
+	var msg strings.Builder
+	msg.WriteString("From: " + m.from + "\r\n")
+	msg.WriteString("To: " + to + "\r\n")
+	msg.WriteString("Subject: " + subject + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(body)
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	if err := smtp.SendMail(m.host+":"+m.port, auth, m.from, []string{to}, []byte(msg.String())); err != nil {
+		return fmt.Errorf("błąd wysyłania emaila: %w", err)
+	}
+
+	return nil
+}
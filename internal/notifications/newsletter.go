@@ -0,0 +1,193 @@
+package notifications
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"library-management-system/internal/events"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+	"library-management-system/internal/search"
+)
+
+// newsletterBooksPerCategory to liczba nowości z jednej kategorii wymienionych w newsletterze.
+// Pozostałe czytelnik znajdzie pod linkiem do kategorii.
+const newsletterBooksPerCategory = 10
+
+// newsletterUncategorized to nazwa grupy nowości bez kategorii
+const newsletterUncategorized = "Pozostałe"
+
+// RegisterNewsletter subskrybuje comiesięczne zadanie wysyłki newslettera (events.NewsletterDue)
+func (d *Dispatcher) RegisterNewsletter() {
+	d.subscribe(events.NewsletterDue, func(e events.Event) {
+		month, ok := e.Payload.(time.Time)
+		if !ok {
+			return
+		}
+		sent, err := d.SendNewsletter(month)
+		if err != nil {
+			log.Printf("Błąd wysyłki newslettera (biblioteka %q): %v", d.fbClient.Tenant(), err)
+			return
+		}
+		if sent > 0 {
+			log.Printf("Wysłano newsletter za %s do %d czytelników (biblioteka %q)", models.MonthName(month), sent, d.fbClient.Tenant())
+		}
+	})
+}
+
+// ComposeNewsletter składa wydanie za miesiąc, do którego należy chwila month: książki
+// dodane w tym miesiącu według kategorii i opublikowane w nim ogłoszenia
+func ComposeNewsletter(fbClient *firebase.Client, month time.Time) (*models.Newsletter, error) {
+	from := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+	to := from.AddDate(0, 1, 0)
+
+	books, err := fbClient.GetBooksAddedBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+	announcements, err := fbClient.GetAnnouncementsPublishedBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	byCategory := make(map[string][]*models.Book)
+	for _, book := range books {
+		category := strings.TrimSpace(book.Category)
+		if category == "" {
+			category = newsletterUncategorized
+		}
+		byCategory[category] = append(byCategory[category], book)
+	}
+
+	newsletter := &models.Newsletter{Month: from, Announcements: announcements}
+	for name, books := range byCategory {
+		newsletter.Categories = append(newsletter.Categories, models.NewsletterCategory{Name: name, Books: books})
+	}
+	// Kategorie alfabetycznie, książki bez kategorii na końcu
+	sort.Slice(newsletter.Categories, func(i, j int) bool {
+		a, b := newsletter.Categories[i].Name, newsletter.Categories[j].Name
+		if (a == newsletterUncategorized) != (b == newsletterUncategorized) {
+			return b == newsletterUncategorized
+		}
+		return search.Fold(a) < search.Fold(b)
+	})
+
+	return newsletter, nil
+}
+
+// SendNewsletter składa i wysyła wydanie za miesiąc month czytelnikom, którzy zamówili
+// newsletter. Każdy miesiąc jest wysyłany najwyżej raz; miesiąc bez nowości i ogłoszeń
+// jest pomijany. Zwraca liczbę odbiorców.
+func (d *Dispatcher) SendNewsletter(month time.Time) (int, error) {
+	if d.fbClient == nil {
+		return 0, nil
+	}
+
+	newsletter, err := ComposeNewsletter(d.fbClient, month)
+	if err != nil {
+		return 0, err
+	}
+	if newsletter.IsEmpty() {
+		return 0, nil
+	}
+
+	issue := &models.NewsletterIssue{
+		ID:            models.NewsletterIssueID(newsletter.Month),
+		Month:         newsletter.Month,
+		Books:         newsletter.BookCount(),
+		Announcements: len(newsletter.Announcements),
+	}
+	started, err := d.fbClient.StartNewsletterIssue(issue)
+	if err != nil || !started {
+		return 0, err
+	}
+
+	subscribers, err := d.fbClient.GetNewsletterSubscribers()
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, user := range subscribers {
+		if !user.IsActive {
+			continue
+		}
+		token, err := d.fbClient.EnsureNewsletterToken(user)
+		if err != nil {
+			// Bez linku rezygnacji newslettera nie wysyłamy
+			log.Printf("Błąd przygotowania newslettera dla %s: %v", user.ID, err)
+			continue
+		}
+
+		subject, body := d.NewsletterEmail(user, newsletter, d.absoluteURL("/newsletter/unsubscribe/"+token))
+		d.deliverEmail(user, subject, body, "")
+		sent++
+	}
+
+	if err := d.fbClient.FinishNewsletterIssue(issue.ID, sent); err != nil {
+		log.Printf("Błąd zapisywania wydania newslettera %s: %v", issue.ID, err)
+	}
+	return sent, nil
+}
+
+// NewsletterEmail buduje temat i treść newslettera z szablonu wiadomości edytowanego przez personel
+func (d *Dispatcher) NewsletterEmail(user *models.User, newsletter *models.Newsletter, unsubscribeURL string) (subject, body string) {
+	template, err := d.fbClient.GetEmailTemplate(models.EmailNewsletter)
+	if err != nil {
+		log.Printf("Błąd pobierania szablonu wiadomości %s: %v", models.EmailNewsletter, err)
+		template = models.GetEmailTemplateKind(models.EmailNewsletter).Default()
+	}
+
+	return template.Render(d.emailVars(user, d.absoluteURL("/books"), map[string]string{
+		"miesiac":    models.MonthName(newsletter.Month),
+		"nowosci":    d.newsletterBooks(newsletter),
+		"ogloszenia": d.newsletterAnnouncements(newsletter),
+		"wypisz":     unsubscribeURL,
+	}))
+}
+
+// newsletterBooks wypisuje nowości według kategorii z linkami do stron książek
+func (d *Dispatcher) newsletterBooks(newsletter *models.Newsletter) string {
+	if len(newsletter.Categories) == 0 {
+		return "W tym miesiącu nie dodano nowych książek."
+	}
+
+	var sections []string
+	for _, category := range newsletter.Categories {
+		var b strings.Builder
+		b.WriteString(category.Name + "\n")
+		for i, book := range category.Books {
+			if i == newsletterBooksPerCategory {
+				more := fmt.Sprintf("...i %d więcej", len(category.Books)-i)
+				if category.Name != newsletterUncategorized {
+					more += ": " + d.absoluteURL("/books/categories/"+search.Slugify(category.Name))
+				}
+				b.WriteString(more + "\n")
+				break
+			}
+			b.WriteString("- " + book.Title)
+			if book.Author != "" {
+				b.WriteString(" - " + book.Author)
+			}
+			b.WriteString("\n  " + d.absoluteURL("/books/"+book.ID) + "\n")
+		}
+		sections = append(sections, strings.TrimRight(b.String(), "\n"))
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// newsletterAnnouncements wypisuje ogłoszenia z linkami
+func (d *Dispatcher) newsletterAnnouncements(newsletter *models.Newsletter) string {
+	if len(newsletter.Announcements) == 0 {
+		return "W tym miesiącu nie było nowych ogłoszeń."
+	}
+
+	lines := make([]string, 0, len(newsletter.Announcements))
+	for _, announcement := range newsletter.Announcements {
+		lines = append(lines, "- "+announcement.Title+"\n  "+d.absoluteURL("/announcements/"+announcement.ID))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,75 @@
+package notifications
+
+import (
+	"log"
+	"strconv"
+
+	"library-management-system/internal/events"
+	"library-management-system/internal/models"
+)
+
+// RegisterReservationAlerts subskrybuje gotowe rezerwacje i informuje czytelnika,
+// gdzie i do kiedy może odebrać książkę, a także rezerwacje pominięte przez limit
+// wypożyczeń czytelnika
+func (d *Dispatcher) RegisterReservationAlerts() {
+	d.subscribe(events.ReservationReady, func(e events.Event) {
+		if reservation, ok := e.Payload.(*models.Reservation); ok {
+			d.notifyReservationReady(reservation)
+		}
+	})
+	d.subscribe(events.ReservationSuspended, func(e events.Event) {
+		if reservation, ok := e.Payload.(*models.Reservation); ok {
+			d.notifyReservationSuspended(reservation)
+		}
+	})
+}
+
+func (d *Dispatcher) notifyReservationReady(reservation *models.Reservation) {
+	user, err := d.fbClient.GetUser(reservation.UserID)
+	if err != nil {
+		log.Printf("Błąd pobierania użytkownika %s: %v", reservation.UserID, err)
+		return
+	}
+
+	location := reservation.PickupLocation
+	if location == "" {
+		location = "wypożyczalnia"
+	}
+
+	d.Notify(user, Message{
+		Title: "Rezerwacja gotowa do odbioru: " + reservation.BookTitle,
+		Body: "Książka " + reservation.BookTitle + " czeka na Ciebie. Miejsce odbioru: " + location +
+			". Odbierz ją do " + reservation.ExpiryDate.Format("02.01.2006") + ".",
+		Link:     "/user/reservations",
+		Category: models.NotifyReservations,
+		Email:    models.EmailReservationReady,
+		Vars: map[string]string{
+			"tytul":   reservation.BookTitle,
+			"miejsce": location,
+			"termin":  reservation.ExpiryDate.Format("02.01.2006"),
+		},
+	})
+}
+
+func (d *Dispatcher) notifyReservationSuspended(reservation *models.Reservation) {
+	user, err := d.fbClient.GetUser(reservation.UserID)
+	if err != nil {
+		log.Printf("Błąd pobierania użytkownika %s: %v", reservation.UserID, err)
+		return
+	}
+
+	limit := strconv.Itoa(user.MaxLoans)
+	d.Notify(user, Message{
+		Title: "Rezerwacja czeka: " + reservation.BookTitle,
+		Body: "Zwrócony egzemplarz książki " + reservation.BookTitle + " przekazaliśmy następnej osobie w kolejce, " +
+			"bo masz wypożyczone " + limit + " książek - tyle, ile pozwala Twój limit. " +
+			"Oddaj którąś z wypożyczonych książek, a kolejny egzemplarz trafi do Ciebie.",
+		Link:     "/user/reservations",
+		Category: models.NotifyReservations,
+		Email:    models.EmailReservationSuspended,
+		Vars: map[string]string{
+			"tytul": reservation.BookTitle,
+			"limit": limit,
+		},
+	})
+}
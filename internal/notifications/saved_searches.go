@@ -0,0 +1,84 @@
+package notifications
+
+import (
+	"log"
+	"time"
+
+	"library-management-system/internal/events"
+	"library-management-system/internal/models"
+	"library-management-system/internal/search"
+)
+
+// RegisterSavedSearchAlerts subskrybuje zdarzenia katalogu i powiadamia czytelników,
+// których zapisane wyszukiwania pasują do nowej lub ponownie dostępnej książki
+func (d *Dispatcher) RegisterSavedSearchAlerts() {
+	d.subscribe(events.BookCreated, func(e events.Event) {
+		if book, ok := e.Payload.(*models.Book); ok {
+			d.notifySavedSearches(book, "Nowa książka pasująca do Twojego wyszukiwania", models.EmailSavedSearchNew)
+		}
+	})
+
+	d.subscribe(events.BookAvailable, func(e events.Event) {
+		if book, ok := e.Payload.(*models.Book); ok {
+			d.notifySavedSearches(book, "Książka z Twojego wyszukiwania jest dostępna", models.EmailSavedSearchAvailable)
+		}
+	})
+}
+
+// savedSearchCooldown to okres, w którym czytelnik nie dostanie ponownie
+// powiadomienia o tej samej książce z tego samego wyszukiwania
+const savedSearchCooldown = 7 * 24 * time.Hour
+
+func (d *Dispatcher) notifySavedSearches(book *models.Book, title string, email models.EmailTemplateKey) {
+	if d.fbClient == nil {
+		return
+	}
+
+	// Pobierz tylko wyszukiwania, których klucz występuje w książce
+	savedSearches, err := d.fbClient.GetSavedSearchesByMatchKeys(search.BookKeys(book))
+	if err != nil {
+		log.Printf("Błąd pobierania zapisanych wyszukiwań: %v", err)
+		return
+	}
+
+	// Jeden użytkownik dostaje jedno powiadomienie o danej książce,
+	// nawet jeśli pasuje do kilku jego wyszukiwań
+	notified := make(map[string]bool)
+	since := time.Now().Add(-savedSearchCooldown)
+
+	for _, saved := range savedSearches {
+		if notified[saved.UserID] || saved.WasNotifiedAbout(book.ID, since) {
+			continue
+		}
+		if !search.ParseQuery(saved.Query).Matches(book) {
+			continue
+		}
+
+		user, err := d.fbClient.GetUser(saved.UserID)
+		if err != nil {
+			log.Printf("Błąd pobierania użytkownika %s: %v", saved.UserID, err)
+			continue
+		}
+		if !user.IsActive {
+			continue
+		}
+
+		d.Notify(user, Message{
+			Title:    title,
+			Body:     book.Title + " - " + book.Author + " (wyszukiwanie: " + saved.Query + ")",
+			Link:     "/books/" + book.ID,
+			Category: models.NotifyCatalog,
+			Email:    email,
+			Vars: map[string]string{
+				"tytul":        book.Title,
+				"autor":        book.Author,
+				"wyszukiwanie": saved.Query,
+			},
+		})
+		notified[saved.UserID] = true
+
+		if err := d.fbClient.MarkSavedSearchNotified(saved, book.ID, since); err != nil {
+			log.Printf("Błąd aktualizacji wyszukiwania %s: %v", saved.ID, err)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package notifications
+
+import (
+	"log"
+
+	"library-management-system/internal/events"
+	"library-management-system/internal/models"
+)
+
+// RegisterSubscriptionAlerts subskrybuje dodawanie książek i powiadamia czytelników,
+// którzy obserwują autora lub kategorię nowego tytułu
+func (d *Dispatcher) RegisterSubscriptionAlerts() {
+	d.subscribe(events.BookCreated, func(e events.Event) {
+		if book, ok := e.Payload.(*models.Book); ok {
+			d.notifySubscribers(book)
+		}
+	})
+}
+
+func (d *Dispatcher) notifySubscribers(book *models.Book) {
+	if d.fbClient == nil {
+		return
+	}
+
+	var keys []string
+	if book.Author != "" {
+		keys = append(keys, models.SubscriptionKey(models.SubscriptionAuthor, book.Author))
+	}
+	if book.Category != "" {
+		keys = append(keys, models.SubscriptionKey(models.SubscriptionCategory, book.Category))
+	}
+
+	subs, err := d.fbClient.GetSubscriptionsByKeys(keys)
+	if err != nil {
+		log.Printf("Błąd pobierania subskrypcji: %v", err)
+		return
+	}
+
+	// Czytelnik obserwujący i autora, i kategorię dostaje jedno powiadomienie
+	notified := make(map[string]bool)
+
+	for _, sub := range subs {
+		if notified[sub.UserID] {
+			continue
+		}
+		notified[sub.UserID] = true
+
+		user, err := d.fbClient.GetUser(sub.UserID)
+		if err != nil {
+			log.Printf("Błąd pobierania użytkownika %s: %v", sub.UserID, err)
+			continue
+		}
+		if !user.IsActive {
+			continue
+		}
+
+		d.Notify(user, Message{
+			Title:    "Nowość w katalogu: " + book.Title,
+			Body:     book.Title + " - " + book.Author + " (" + sub.Label() + ")",
+			Link:     "/books/" + book.ID,
+			Category: models.NotifyCatalog,
+			Email:    models.EmailNewInCatalog,
+			Vars: map[string]string{
+				"tytul": book.Title,
+				"autor": book.Author,
+				"powod": sub.Label(),
+			},
+		})
+	}
+}
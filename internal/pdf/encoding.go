@@ -0,0 +1,136 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kodowanie czcionek dokumentu: kody 32-126 to znaki ASCII, a od kodu 128 kolejne
+// znaki z extraGlyphs (polskie litery, popularne litery z akcentami i typograficzne
+// znaki interpunkcyjne). Szerokości znaków pochodzą z metryk czcionek Helvetica (AFM),
+// w tysięcznych częściach rozmiaru czcionki.
+
+// firstExtraCode to kod pierwszego znaku spoza ASCII
+const firstExtraCode = 128
+
+// helveticaWidths to szerokości znaków ASCII 32-126 czcionki Helvetica
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// helveticaBoldWidths to szerokości znaków ASCII 32-126 czcionki Helvetica-Bold
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
+
+// extraGlyph to znak spoza ASCII. Litery z akcentami mają szerokość litery bazowej
+// (like), pozostałe znaki - własne szerokości w obu czcionkach.
+type extraGlyph struct {
+	r           rune
+	name        string
+	like        rune
+	width, bold int
+}
+
+var extraGlyphs = []extraGlyph{
+	{r: 'Ą', name: "Aogonek", like: 'A'}, {r: 'ą', name: "aogonek", like: 'a'},
+	{r: 'Ć', name: "Cacute", like: 'C'}, {r: 'ć', name: "cacute", like: 'c'},
+	{r: 'Ę', name: "Eogonek", like: 'E'}, {r: 'ę', name: "eogonek", like: 'e'},
+	{r: 'Ł', name: "Lslash", like: 'L'}, {r: 'ł', name: "lslash", like: 'l'},
+	{r: 'Ń', name: "Nacute", like: 'N'}, {r: 'ń', name: "nacute", like: 'n'},
+	{r: 'Ó', name: "Oacute", like: 'O'}, {r: 'ó', name: "oacute", like: 'o'},
+	{r: 'Ś', name: "Sacute", like: 'S'}, {r: 'ś', name: "sacute", like: 's'},
+	{r: 'Ź', name: "Zacute", like: 'Z'}, {r: 'ź', name: "zacute", like: 'z'},
+	{r: 'Ż', name: "Zdotaccent", like: 'Z'}, {r: 'ż', name: "zdotaccent", like: 'z'},
+	{r: 'Á', name: "Aacute", like: 'A'}, {r: 'á', name: "aacute", like: 'a'},
+	{r: 'Ä', name: "Adieresis", like: 'A'}, {r: 'ä', name: "adieresis", like: 'a'},
+	{r: 'Č', name: "Ccaron", like: 'C'}, {r: 'č', name: "ccaron", like: 'c'},
+	{r: 'É', name: "Eacute", like: 'E'}, {r: 'é', name: "eacute", like: 'e'},
+	{r: 'Ě', name: "Ecaron", like: 'E'}, {r: 'ě', name: "ecaron", like: 'e'},
+	{r: 'Í', name: "Iacute", like: 'I'}, {r: 'í', name: "iacute", like: 'i'},
+	{r: 'Ö', name: "Odieresis", like: 'O'}, {r: 'ö', name: "odieresis", like: 'o'},
+	{r: 'Ř', name: "Rcaron", like: 'R'}, {r: 'ř', name: "rcaron", like: 'r'},
+	{r: 'Š', name: "Scaron", like: 'S'}, {r: 'š', name: "scaron", like: 's'},
+	{r: 'Ú', name: "Uacute", like: 'U'}, {r: 'ú', name: "uacute", like: 'u'},
+	{r: 'Ü', name: "Udieresis", like: 'U'}, {r: 'ü', name: "udieresis", like: 'u'},
+	{r: 'Ž', name: "Zcaron", like: 'Z'}, {r: 'ž', name: "zcaron", like: 'z'},
+	{r: 'ß', name: "germandbls", like: 'T'},
+	{r: '–', name: "endash", width: 556, bold: 556},
+	{r: '—', name: "emdash", width: 1000, bold: 1000},
+	{r: '„', name: "quotedblbase", width: 333, bold: 500},
+	{r: '”', name: "quotedblright", width: 333, bold: 500},
+	{r: '“', name: "quotedblleft", width: 333, bold: 500},
+	{r: '’', name: "quoteright", width: 222, bold: 278},
+	{r: '…', name: "ellipsis", width: 1000, bold: 1000},
+	{r: '×', name: "multiply", width: 584, bold: 584},
+	{r: '°', name: "degree", width: 400, bold: 400},
+}
+
+// extraCodes przypisuje znakom spoza ASCII ich kody w kodowaniu dokumentu
+var extraCodes = func() map[rune]byte {
+	codes := make(map[rune]byte, len(extraGlyphs))
+	for i, glyph := range extraGlyphs {
+		codes[glyph.r] = byte(firstExtraCode + i)
+	}
+	return codes
+}()
+
+// encode zamienia tekst na kody znaków czcionki; nieobsługiwane znaki stają się "?"
+func encode(text string) []byte {
+	encoded := make([]byte, 0, len(text))
+	for _, r := range text {
+		code, ok := extraCodes[r]
+		switch {
+		case r >= 32 && r <= 126:
+			encoded = append(encoded, byte(r))
+		case ok:
+			encoded = append(encoded, code)
+		case r == '\u00a0': // Twarda spacja
+			encoded = append(encoded, ' ')
+		default:
+			encoded = append(encoded, '?')
+		}
+	}
+	return encoded
+}
+
+// glyphWidth zwraca szerokość znaku o podanym kodzie
+func glyphWidth(code byte, font Font) int {
+	widths := &helveticaWidths
+	if font == HelveticaBold {
+		widths = &helveticaBoldWidths
+	}
+	if code < firstExtraCode {
+		return widths[code-32]
+	}
+
+	glyph := extraGlyphs[code-firstExtraCode]
+	switch {
+	case glyph.like != 0:
+		return widths[glyph.like-32]
+	case font == HelveticaBold:
+		return glyph.bold
+	default:
+		return glyph.width
+	}
+}
+
+// encodingObject zwraca słownik kodowania wspólny dla obu czcionek
+func encodingObject() string {
+	names := make([]string, len(extraGlyphs))
+	for i, glyph := range extraGlyphs {
+		names[i] = "/" + glyph.name
+	}
+	return fmt.Sprintf("<< /Type /Encoding /BaseEncoding /WinAnsiEncoding /Differences [%d %s] >>",
+		firstExtraCode, strings.Join(names, " "))
+}
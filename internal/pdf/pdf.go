@@ -0,0 +1,178 @@
+// Package pdf składa proste dokumenty PDF z prostokątów i tekstu - wystarczające do
+// arkuszy etykiet z kodami kreskowymi. Używa standardowych czcionek Helvetica, których
+// nie trzeba osadzać w pliku; polskie litery są dostępne przez własne kodowanie czcionki.
+// Wymiary i położenie podaje się w milimetrach od lewego górnego rogu strony.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Wymiary strony A4
+const (
+	A4WidthMM  = 210.0
+	A4HeightMM = 297.0
+)
+
+// ptPerMM to liczba punktów typograficznych (jednostek PDF) w milimetrze
+const ptPerMM = 72 / 25.4
+
+// Font to jedna ze standardowych czcionek dokumentu
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+)
+
+// resource zwraca nazwę czcionki w zasobach strony
+func (f Font) resource() string {
+	if f == HelveticaBold {
+		return "F2"
+	}
+	return "F1"
+}
+
+// Document to dokument PDF o stronach jednakowego rozmiaru
+type Document struct {
+	widthMM  float64
+	heightMM float64
+	pages    []*Page
+}
+
+// Page to jedna strona dokumentu
+type Page struct {
+	doc     *Document
+	content bytes.Buffer
+}
+
+// New tworzy pusty dokument o stronach podanego rozmiaru
+func New(widthMM, heightMM float64) *Document {
+	return &Document{widthMM: widthMM, heightMM: heightMM}
+}
+
+// AddPage dodaje na końcu dokumentu nową stronę
+func (d *Document) AddPage() *Page {
+	page := &Page{doc: d}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// PageCount zwraca liczbę stron dokumentu
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+// Rect rysuje wypełniony czarny prostokąt o lewym górnym rogu w (x, y)
+func (p *Page) Rect(x, y, width, height float64) {
+	fmt.Fprintf(&p.content, "%s %s %s %s re f\n",
+		number(x*ptPerMM), number((p.doc.heightMM-y-height)*ptPerMM),
+		number(width*ptPerMM), number(height*ptPerMM))
+}
+
+// Text pisze tekst od punktu (x, y) leżącego na linii bazowej. Rozmiar czcionki
+// podaje się w punktach; znaki spoza obsługiwanych są zastępowane przez "?".
+func (p *Page) Text(x, y float64, font Font, size float64, text string) {
+	fmt.Fprintf(&p.content, "BT /%s %s Tf %s %s Td (%s) Tj ET\n",
+		font.resource(), number(size), number(x*ptPerMM), number((p.doc.heightMM-y)*ptPerMM),
+		escape(encode(text)))
+}
+
+// PointsToMM przelicza rozmiar czcionki w punktach na milimetry
+func PointsToMM(points float64) float64 {
+	return points / ptPerMM
+}
+
+// TextWidth zwraca szerokość tekstu w milimetrach
+func TextWidth(text string, font Font, size float64) float64 {
+	units := 0
+	for _, c := range encode(text) {
+		units += glyphWidth(c, font)
+	}
+	return PointsToMM(float64(units) / 1000 * size)
+}
+
+// Fit skraca tekst do podanej szerokości, kończąc go wielokropkiem
+func Fit(text string, font Font, size, widthMM float64) string {
+	if TextWidth(text, font, size) <= widthMM {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		fitted := strings.TrimRight(string(runes), " ") + "…"
+		if TextWidth(fitted, font, size) <= widthMM {
+			return fitted
+		}
+	}
+	return ""
+}
+
+// WriteTo zapisuje dokument w formacie PDF
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var out bytes.Buffer
+	var offsets []int
+
+	// Obiekty 1-5 są stałe, a każda strona ma dwa kolejne: stronę i jej treść
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object(encodingObject())
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding 3 0 R >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding 3 0 R >>")
+
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents %d 0 R >>",
+			number(d.widthMM*ptPerMM), number(d.heightMM*ptPerMM), 7+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.content.Len(), page.content.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%EOF\n", len(offsets)+1, xref)
+
+	return out.WriteTo(w)
+}
+
+// number formatuje liczbę z dokładnością do setnych części punktu
+func number(v float64) string {
+	s := strings.TrimRight(fmt.Sprintf("%.2f", v), "0")
+	s = strings.TrimSuffix(s, ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// escape zapisuje zakodowany tekst jako napis PDF
+func escape(text []byte) string {
+	var b strings.Builder
+	for _, c := range text {
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 32 || c > 126:
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
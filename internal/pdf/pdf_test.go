@@ -0,0 +1,62 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWriteTo(t *testing.T) {
+	doc := New(A4WidthMM, A4HeightMM)
+	doc.AddPage().Text(10, 20, HelveticaBold, 12, "Zażółć gęślą jaźń (1)")
+	doc.AddPage().Rect(10, 20, 1, 15)
+
+	var out bytes.Buffer
+	if _, err := doc.WriteTo(&out); err != nil {
+		t.Fatal(err)
+	}
+	file := out.String()
+
+	if !strings.Contains(file, "/Count 2") {
+		t.Error("brak dwóch stron w drzewie stron")
+	}
+	if !strings.Contains(file, `(Za\221\213\207\203 g\205\215l\201 ja\217\211 \(1\)) Tj`) {
+		t.Errorf("tekst zakodowany niepoprawnie:\n%s", file)
+	}
+
+	// Tabela xref musi wskazywać początki kolejnych obiektów
+	xref := strings.LastIndex(file, "\nxref\n") + 1
+	if !strings.HasSuffix(file, fmt.Sprintf("startxref\n%d\n%%EOF\n", xref)) {
+		t.Errorf("startxref nie wskazuje tabeli xref (%d)", xref)
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(file[xref:], -1)
+	if len(entries) != 9 {
+		t.Fatalf("obiektów w xref: %d, oczekiwano 9", len(entries))
+	}
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(entry[1])
+		if want := fmt.Sprintf("%d 0 obj", i+1); !strings.HasPrefix(file[offset:], want) {
+			t.Errorf("xref obiektu %d wskazuje %q", i+1, file[offset:offset+10])
+		}
+	}
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		text  string
+		width float64
+		want  string
+	}{
+		{"Lalka", 20, "Lalka"},
+		{"Przedwiośnie", 17, "Przedwi…"},
+		{"Przedwiośnie", 1, ""},
+	}
+	for _, tt := range tests {
+		if got := Fit(tt.text, Helvetica, 10, tt.width); got != tt.want {
+			t.Errorf("Fit(%q, %v) = %q, oczekiwano %q", tt.text, tt.width, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package printing
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// LineWidth to liczba znaków w wierszu na papierze 80 mm (czcionka A)
+const LineWidth = 48
+
+// Polecenia ESC/POS
+var (
+	cmdInit        = []byte{0x1B, 0x40}                         // ESC @ - reset drukarki
+	cmdCodePage    = []byte{0x1B, 0x74, 18}                     // ESC t 18 - strona kodowa PC852 (Latin-2)
+	cmdAlignLeft   = []byte{0x1B, 0x61, 0}                      // ESC a 0
+	cmdAlignCenter = []byte{0x1B, 0x61, 1}                      // ESC a 1
+	cmdBoldOn      = []byte{0x1B, 0x45, 1}                      // ESC E 1
+	cmdBoldOff     = []byte{0x1B, 0x45, 0}                      // ESC E 0
+	cmdDoubleOn    = []byte{0x1D, 0x21, 0x11}                   // GS ! - podwójna szerokość i wysokość
+	cmdDoubleOff   = []byte{0x1D, 0x21, 0}                      // GS ! 0
+	cmdFeedCut     = []byte{0x1B, 0x64, 4, 0x1D, 0x56, 0x41, 0} // ESC d 4, GS V A 0 - wysuw i częściowe cięcie
+)
+
+// Receipt składa wydruk ESC/POS wiersz po wierszu. Tekst jest kodowany w stronie
+// kodowej PC852, w której są polskie litery - znaki spoza niej drukują się jako "?".
+type Receipt struct {
+	buf bytes.Buffer
+}
+
+// NewReceipt rozpoczyna nowy wydruk
+func NewReceipt() *Receipt {
+	r := &Receipt{}
+	r.buf.Write(cmdInit)
+	r.buf.Write(cmdCodePage)
+	return r
+}
+
+// Title dodaje wyśrodkowany nagłówek podwójnej wielkości
+func (r *Receipt) Title(text string) *Receipt {
+	r.buf.Write(cmdAlignCenter)
+	r.buf.Write(cmdDoubleOn)
+	r.text(text)
+	r.buf.Write(cmdDoubleOff)
+	r.buf.Write(cmdAlignLeft)
+	return r
+}
+
+// Center dodaje wyśrodkowany wiersz
+func (r *Receipt) Center(text string) *Receipt {
+	r.buf.Write(cmdAlignCenter)
+	r.text(text)
+	r.buf.Write(cmdAlignLeft)
+	return r
+}
+
+// Text dodaje tekst zawijany do szerokości papieru
+func (r *Receipt) Text(text string) *Receipt {
+	for _, line := range wrap(text, LineWidth) {
+		r.text(line)
+	}
+	return r
+}
+
+// Field dodaje wiersz "etykieta ... wartość" z wartością wyrównaną do prawej.
+// Wartość pogrubiona wyróżnia najważniejszą informację (np. termin zwrotu).
+func (r *Receipt) Field(label, value string, bold bool) *Receipt {
+	gap := LineWidth - utf8.RuneCountInString(label) - utf8.RuneCountInString(value)
+	if gap < 1 {
+		r.text(label)
+		label, gap = "", LineWidth-utf8.RuneCountInString(value)
+	}
+	r.write(label + strings.Repeat(" ", max(gap, 0)))
+	if bold {
+		r.buf.Write(cmdBoldOn)
+	}
+	r.text(value)
+	if bold {
+		r.buf.Write(cmdBoldOff)
+	}
+	return r
+}
+
+// Rule dodaje linię oddzielającą
+func (r *Receipt) Rule() *Receipt {
+	r.text(strings.Repeat("-", LineWidth))
+	return r
+}
+
+// Bytes kończy wydruk wysuwem i cięciem papieru i zwraca dane dla drukarki
+func (r *Receipt) Bytes() []byte {
+	r.buf.Write(cmdFeedCut)
+	return r.buf.Bytes()
+}
+
+func (r *Receipt) text(s string) {
+	r.write(s)
+	r.buf.WriteByte('\n')
+}
+
+func (r *Receipt) write(s string) {
+	for _, c := range s {
+		b, ok := charmap.CodePage852.EncodeRune(c)
+		if !ok {
+			b = '?'
+		}
+		r.buf.WriteByte(b)
+	}
+}
+
+// wrap dzieli tekst na wiersze najwyżej width znaków, łamiąc między słowami
+// (słowa dłuższe od wiersza są cięte)
+func wrap(text string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		for utf8.RuneCountInString(word) > width {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			runes := []rune(word)
+			lines = append(lines, string(runes[:width]))
+			word = string(runes[width:])
+		}
+		switch {
+		case line == "":
+			line = word
+		case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
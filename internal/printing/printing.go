@@ -0,0 +1,111 @@
+// Package printing drukuje potwierdzenia wypożyczeń i zwrotów na drukarce paragonowej
+// przy ladzie. Drukarka obsługująca ESC/POS jest podłączona do sieci (zwykle port 9100),
+// a jej adres ustawia się osobno dla każdej biblioteki. Bez adresu nic nie jest drukowane.
+package printing
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"library-management-system/internal/events"
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+)
+
+// dialTimeout ogranicza czekanie na wyłączoną lub odłączoną drukarkę
+const dialTimeout = 5 * time.Second
+
+// Service drukuje potwierdzenia jednej biblioteki
+type Service struct {
+	fbClient *firebase.Client
+}
+
+// NewService tworzy usługę wydruków dla biblioteki
+func NewService(fbClient *firebase.Client) *Service {
+	return &Service{fbClient: fbClient}
+}
+
+// Register subskrybuje zdarzenia wydania i zwrotu książki przy ladzie
+func (s *Service) Register() {
+	events.Subscribe(events.LoanPickedUp, s.handle(LoanSlip))
+	events.Subscribe(events.LoanReturned, s.handle(ReturnSlip))
+}
+
+// handle drukuje potwierdzenie zbudowane przez slip, gdy biblioteka ma ustawioną drukarkę
+func (s *Service) handle(slip func(*models.Settings, *models.Loan, time.Time) []byte) events.Handler {
+	return func(e events.Event) {
+		if e.Tenant != s.fbClient.Tenant() {
+			return
+		}
+		loan, ok := e.Payload.(*models.Loan)
+		if !ok || loan.IsReadingRoom() {
+			return
+		}
+		settings, err := s.fbClient.GetSettings()
+		if err != nil {
+			log.Printf("Błąd pobierania ustawień drukarki paragonów: %v", err)
+			return
+		}
+		if settings.ReceiptPrinter == "" {
+			return
+		}
+		if err := Print(settings.ReceiptPrinter, slip(settings, loan, time.Now())); err != nil {
+			log.Printf("Błąd drukowania potwierdzenia wypożyczenia %s: %v", loan.ID, err)
+		}
+	}
+}
+
+// Print wysyła gotowy wydruk na drukarkę sieciową pod adresem addr (host:port)
+func Print(addr string, data []byte) error {
+	conn, err := net.DialTimeout("tcp", addr, dialTimeout)
+	if err != nil {
+		return fmt.Errorf("brak połączenia z drukarką %s: %w", addr, err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetWriteDeadline(time.Now().Add(dialTimeout)); err != nil {
+		return err
+	}
+	if _, err := conn.Write(data); err != nil {
+		return fmt.Errorf("błąd wysyłania wydruku do %s: %w", addr, err)
+	}
+	return nil
+}
+
+// LoanSlip buduje potwierdzenie wydania książki z terminem zwrotu
+func LoanSlip(settings *models.Settings, loan *models.Loan, now time.Time) []byte {
+	r := NewReceipt().
+		Title(settings.LibraryName).
+		Center("Potwierdzenie wypożyczenia").
+		Center(now.Format("02.01.2006 15:04")).
+		Rule().
+		Text(loan.BookTitle).
+		Field("Czytelnik", loan.UserName, false).
+		Field("Termin zwrotu", loan.DueDate.Format("02.01.2006"), true).
+		Rule().
+		Center("Prosimy o zwrot w terminie")
+	return r.Bytes()
+}
+
+// ReturnSlip buduje potwierdzenie zwrotu książki z naliczoną karą
+func ReturnSlip(settings *models.Settings, loan *models.Loan, now time.Time) []byte {
+	r := NewReceipt().
+		Title(settings.LibraryName).
+		Center("Potwierdzenie zwrotu").
+		Center(now.Format("02.01.2006 15:04")).
+		Rule().
+		Text(loan.BookTitle).
+		Field("Czytelnik", loan.UserName, false).
+		Field("Wypożyczono", loan.LoanDate.Format("02.01.2006"), false).
+		Field("Termin zwrotu", loan.DueDate.Format("02.01.2006"), false)
+	if loan.ReturnDate != nil {
+		r.Field("Zwrócono", loan.ReturnDate.Format("02.01.2006"), false)
+	}
+	if loan.FineAmount > 0 {
+		r.Field("Kara za opóźnienie", settings.FormatMoney(loan.FineAmount), true)
+	}
+	r.Rule().Center("Dziękujemy")
+	return r.Bytes()
+}
@@ -0,0 +1,92 @@
+// Package publicstats przygotowuje dzienny zrzut publicznych statystyk biblioteki
+// (wielkość księgozbioru, wypożyczenia w miesiącu, najpopularniejsze tytuły).
+// Strona /stats czyta tylko gotowy zrzut, więc jej wyświetlenie nie przegląda
+// katalogu ani historii wypożyczeń.
+package publicstats
+
+import (
+	"sort"
+	"time"
+
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+)
+
+// Snapshot liczy statystyki do końca poprzedniego dnia i zapisuje je jako zrzut.
+// Wypożyczenia są liczone od początku miesiąca, do którego należy ten dzień -
+// zrzut z pierwszego dnia miesiąca podsumowuje cały poprzedni miesiąc.
+func Snapshot(c *firebase.Client, now time.Time) (*models.PublicStats, error) {
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	day := end.AddDate(0, 0, -1)
+	month := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	books, err := c.ListBooks()
+	if err != nil {
+		return nil, err
+	}
+	loans, err := c.GetLoansBetween(month, end)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := summarize(books, loans)
+	stats.Day = day.Format("2006-01-02")
+	stats.Month = month.Format("2006-01")
+	stats.CreatedAt = now
+
+	if err := c.SavePublicStats(stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// summarize liczy statystyki z katalogu i wypożyczeń miesiąca. Udostępnienia
+// na miejscu i zamówienia nieodebrane nie są wypożyczeniami.
+func summarize(books []*models.Book, loans []*models.Loan) *models.PublicStats {
+	stats := &models.PublicStats{Titles: len(books)}
+
+	byID := make(map[string]*models.Book, len(books))
+	categories := make(map[string]bool)
+	for _, book := range books {
+		byID[book.ID] = book
+		stats.Volumes += book.TotalCopies
+		if book.Category != "" {
+			categories[book.Category] = true
+		}
+	}
+	stats.Categories = len(categories)
+
+	counts := make(map[string]int)
+	for _, loan := range loans {
+		if loan.IsReadingRoom() || loan.Status == models.LoanStatusPendingPickup {
+			continue
+		}
+		stats.MonthLoans++
+		counts[loan.BookID]++
+	}
+
+	for bookID, n := range counts {
+		book, ok := byID[bookID]
+		if !ok {
+			continue // Książka usunięta z katalogu
+		}
+		stats.PopularBooks = append(stats.PopularBooks, models.PopularTitle{
+			BookID: book.ID,
+			Title:  book.Title,
+			Author: book.Author,
+			Loans:  n,
+		})
+	}
+	sort.Slice(stats.PopularBooks, func(i, j int) bool {
+		a, b := stats.PopularBooks[i], stats.PopularBooks[j]
+		if a.Loans != b.Loans {
+			return a.Loans > b.Loans
+		}
+		return a.Title < b.Title
+	})
+	if len(stats.PopularBooks) > models.PublicStatsTopTitles {
+		stats.PopularBooks = stats.PopularBooks[:models.PublicStatsTopTitles]
+	}
+
+	return stats
+}
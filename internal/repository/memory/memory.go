@@ -0,0 +1,1378 @@
+// Package memory to repozytoria trzymane w pamięci procesu - backend trybu bez bazy danych,
+// gdy Firebase nie jest skonfigurowany. Dane znikają po restarcie serwera, a zasady
+// wypożyczeń pochodzą z domyślnych ustawień biblioteki (models.DefaultSettings).
+package memory
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"library-management-system/internal/models"
+	"library-management-system/internal/repository"
+	"library-management-system/internal/search"
+)
+
+// shortCodeAttempts to liczba losowań krótkiego kodu, zanim dodanie książki zgłosi błąd
+const shortCodeAttempts = 5
+
+// Store implementuje wszystkie repozytoria na mapach chronionych jednym muteksem.
+// Zwracane obiekty są kopiami - zmiany zapisuje się jak w Firestore, przez Update*.
+type Store struct {
+	mu           sync.RWMutex
+	books        map[string]*models.Book
+	loans        map[string]*models.Loan
+	users        map[string]*models.User
+	reservations map[string]*models.Reservation
+	withdrawals  []*models.CopyWithdrawal
+	settings     *models.Settings
+
+	readingLists     map[string]*models.ReadingList // Listy personelu
+	userReadingLists map[string]*models.ReadingList // Własne listy czytelników
+	passwords        map[string][]byte              // Hash bcrypt hasła konta (klucz: ID użytkownika)
+}
+
+var (
+	_ repository.Store                 = (*Store)(nil)
+	_ repository.BookRepository        = (*Store)(nil)
+	_ repository.LoanRepository        = (*Store)(nil)
+	_ repository.UserRepository        = (*Store)(nil)
+	_ repository.ReservationRepository = (*Store)(nil)
+	_ repository.ReadingListRepository = (*Store)(nil)
+	_ repository.Accounts              = (*Store)(nil)
+)
+
+// NewStore tworzy pusty magazyn w pamięci z katalogiem books (np. demo.SampleBooks)
+func NewStore(books []models.Book) *Store {
+	s := &Store{
+		books:        make(map[string]*models.Book),
+		loans:        make(map[string]*models.Loan),
+		users:        make(map[string]*models.User),
+		reservations: make(map[string]*models.Reservation),
+		settings:     models.DefaultSettings(),
+
+		readingLists:     make(map[string]*models.ReadingList),
+		userReadingLists: make(map[string]*models.ReadingList),
+		passwords:        make(map[string][]byte),
+	}
+	for i := range books {
+		book := books[i]
+		if err := s.CreateBook(&book); err != nil {
+			// Przykładowy katalog jest stały - błąd oznacza pomyłkę w danych
+			panic(fmt.Sprintf("memory: błąd dodawania książki %q: %v", book.Title, err))
+		}
+	}
+	return s
+}
+
+// Books zwraca katalog (kontekst nie jest potrzebny - zapytania nie wychodzą poza proces)
+func (s *Store) Books(ctx context.Context) repository.BookRepository {
+	return s
+}
+
+// Loans zwraca wypożyczenia
+func (s *Store) Loans(ctx context.Context) repository.LoanRepository {
+	return s
+}
+
+// Users zwraca konta
+func (s *Store) Users(ctx context.Context) repository.UserRepository {
+	return s
+}
+
+// Reservations zwraca rezerwacje
+func (s *Store) Reservations(ctx context.Context) repository.ReservationRepository {
+	return s
+}
+
+// ReadingLists zwraca listy lektur
+func (s *Store) ReadingLists(ctx context.Context) repository.ReadingListRepository {
+	return s
+}
+
+// newID zwraca losowy identyfikator dokumentu (20 znaków jak w Firestore)
+func newID() string {
+	b := make([]byte, 10)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Książki
+
+// GetBook pobiera książkę po ID
+func (s *Store) GetBook(id string) (*models.Book, error) {
+	if id == "" {
+		return nil, fmt.Errorf("ID książki nie może być puste")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	book, ok := s.books[id]
+	if !ok {
+		return nil, fmt.Errorf("książka %s nie została znaleziona", id)
+	}
+	copied := *book
+	return &copied, nil
+}
+
+// GetBooksByIDs pobiera kilka książek naraz (książek spoza katalogu brakuje w mapie)
+func (s *Store) GetBooksByIDs(ids []string) (map[string]*models.Book, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	books := make(map[string]*models.Book, len(ids))
+	for _, id := range ids {
+		if book, ok := s.books[id]; ok {
+			copied := *book
+			books[id] = &copied
+		}
+	}
+	return books, nil
+}
+
+// GetBookByShortCode pobiera książkę po krótkim kodzie permalinku (nil, gdy nie ma takiej)
+func (s *Store) GetBookByShortCode(code string) (*models.Book, error) {
+	if code == "" {
+		return nil, fmt.Errorf("kod nie może być pusty")
+	}
+
+	books := s.filterBooks(func(book *models.Book) bool { return book.ShortCode == code })
+	if len(books) == 0 {
+		return nil, nil
+	}
+	return books[0], nil
+}
+
+// FindBookByCode szuka książki po kodzie z etykiety (także całym adresie z kodu QR),
+// a następnie po ISBN. Zwraca nil, gdy żadna książka nie pasuje.
+func (s *Store) FindBookByCode(code string) (*models.Book, error) {
+	code = path.Base(strings.TrimSpace(code))
+	if code == "" || code == "." {
+		return nil, nil
+	}
+
+	book, err := s.GetBookByShortCode(strings.ToLower(code))
+	if err != nil || book != nil {
+		return book, err
+	}
+	return s.GetBookByISBN(code)
+}
+
+// GetBookByISBN pobiera książkę po ISBN (nil, gdy nie ma jej w katalogu)
+func (s *Store) GetBookByISBN(isbn string) (*models.Book, error) {
+	if isbn == "" {
+		return nil, fmt.Errorf("ISBN nie może być pusty")
+	}
+
+	books := s.filterBooks(func(book *models.Book) bool { return book.ISBN == isbn })
+	if len(books) == 0 {
+		return nil, nil
+	}
+	return books[0], nil
+}
+
+// ListBooks pobiera wszystkie książki w kolejności tytułów
+func (s *Store) ListBooks() ([]*models.Book, error) {
+	return s.filterBooks(nil), nil
+}
+
+// ListBooksPage pobiera stronę katalogu w kolejności tytułów, opcjonalnie z jednej kategorii.
+// Kursor after to pozycja pierwszej książki strony.
+func (s *Store) ListBooksPage(category string, limit int, after string) ([]*models.Book, string, error) {
+	books := s.filterBooks(func(book *models.Book) bool {
+		return category == "" || book.Category == category
+	})
+
+	start, _ := strconv.Atoi(after)
+	if start < 0 || start > len(books) {
+		start = len(books)
+	}
+	end := start + limit
+	if limit <= 0 || end >= len(books) {
+		return books[start:], "", nil
+	}
+	return books[start:end], strconv.Itoa(end), nil
+}
+
+// ListBooksWithPagination pobiera stronę katalogu posortowaną po polu sortBy (domyślnie
+// tytuł; sygnatury w kolejności półkowej) i liczbę wszystkich książek
+func (s *Store) ListBooksWithPagination(limit int, offset int, sortBy string, sortOrder string) ([]*models.Book, int, error) {
+	books := s.filterBooks(nil)
+
+	var key func(*models.Book) string
+	switch sortBy {
+	case "author":
+		key = func(book *models.Book) string { return search.Fold(book.Author) }
+	case "category":
+		key = func(book *models.Book) string { return search.Fold(book.Category) }
+	case "call_number":
+		key = func(book *models.Book) string { return book.CallNumberKey }
+	}
+	if key != nil {
+		sort.SliceStable(books, func(i, j int) bool { return key(books[i]) < key(books[j]) })
+	}
+	if sortOrder == "desc" {
+		slices.Reverse(books)
+	}
+
+	total := len(books)
+	if offset < 0 || offset > total {
+		offset = total
+	}
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+	return books[offset:end], total, nil
+}
+
+// SearchBooks wyszukuje książki po tytule, autorze lub ISBN (jak klient Firestore -
+// bez znaków diakrytycznych i z tolerancją literówek, najlepsze dopasowania pierwsze)
+func (s *Store) SearchBooks(searchTerm string) ([]*models.Book, error) {
+	books := s.filterBooks(nil)
+	if searchTerm == "" {
+		return books, nil
+	}
+
+	terms := search.Terms(searchTerm)
+	scores := make(map[string]int)
+	var results []*models.Book
+	for _, book := range books {
+		text := search.Normalize(book.Title + " " + book.Author + " " + book.ISBN)
+		if score := search.Score(text, terms); score > 0 {
+			scores[book.ID] = score
+			results = append(results, book)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return scores[results[i].ID] > scores[results[j].ID]
+	})
+	return results, nil
+}
+
+// SearchBooksAdvanced wyszukuje książki spełniające wszystkie podane kryteria
+func (s *Store) SearchBooksAdvanced(title, author, isbn string) ([]*models.Book, error) {
+	return s.filterBooks(func(book *models.Book) bool {
+		return (title == "" || search.ContainsFold(book.Title, title)) &&
+			(author == "" || search.ContainsFold(book.Author, author)) &&
+			(isbn == "" || search.ContainsFold(book.ISBN, isbn))
+	}), nil
+}
+
+// GetAvailableBooks pobiera książki z wolnymi egzemplarzami
+func (s *Store) GetAvailableBooks() ([]*models.Book, error) {
+	return s.filterBooks(func(book *models.Book) bool { return book.AvailableCopies > 0 }), nil
+}
+
+// GetNewestBooks pobiera limit ostatnio dodanych książek (najnowsze pierwsze)
+func (s *Store) GetNewestBooks(limit int) ([]*models.Book, error) {
+	books := s.filterBooks(nil)
+	sort.SliceStable(books, func(i, j int) bool {
+		return books[i].CreatedAt.After(books[j].CreatedAt)
+	})
+	if limit > 0 && len(books) > limit {
+		books = books[:limit]
+	}
+	return books, nil
+}
+
+// GetBooksAddedSince pobiera książki dodane do katalogu od podanej chwili (najstarsze pierwsze)
+func (s *Store) GetBooksAddedSince(from time.Time) ([]*models.Book, error) {
+	books := s.filterBooks(func(book *models.Book) bool { return !book.CreatedAt.Before(from) })
+	sort.SliceStable(books, func(i, j int) bool {
+		return books[i].CreatedAt.Before(books[j].CreatedAt)
+	})
+	return books, nil
+}
+
+// GetBooksByCategory pobiera książki z danej kategorii (pusta - cały katalog)
+func (s *Store) GetBooksByCategory(category string) ([]*models.Book, error) {
+	return s.filterBooks(func(book *models.Book) bool {
+		return category == "" || book.Category == category
+	}), nil
+}
+
+// GetBooksByClassification pobiera książki, których symbol klasyfikacji zaczyna się
+// od podanych cyfr, w kolejności półkowej
+func (s *Store) GetBooksByClassification(digits string) ([]*models.Book, error) {
+	books := s.filterBooks(func(book *models.Book) bool {
+		return book.CallNumberKey != "" && strings.HasPrefix(book.CallNumberKey, digits)
+	})
+	sort.SliceStable(books, func(i, j int) bool {
+		return books[i].CallNumberKey < books[j].CallNumberKey
+	})
+	return books, nil
+}
+
+// GetBooksBySeries pobiera książki z serii według numeru tomu i roku wydania
+func (s *Store) GetBooksBySeries(series string) ([]*models.Book, error) {
+	books := s.filterBooks(func(book *models.Book) bool { return book.Series == series })
+	sort.SliceStable(books, func(i, j int) bool {
+		if books[i].SeriesVolume != books[j].SeriesVolume {
+			return books[i].SeriesVolume < books[j].SeriesVolume
+		}
+		return books[i].PublicationYear < books[j].PublicationYear
+	})
+	return books, nil
+}
+
+// GetBookEditions pobiera inne wydania tej samej książki (ten sam tytuł i autor)
+func (s *Store) GetBookEditions(book *models.Book) ([]*models.Book, error) {
+	return s.filterBooks(func(edition *models.Book) bool {
+		return edition.ID != book.ID && search.SameWork(edition, book)
+	}), nil
+}
+
+// CreateBook dodaje książkę do katalogu
+func (s *Store) CreateBook(book *models.Book) error {
+	if book == nil {
+		return fmt.Errorf("książka nie może być nil")
+	}
+	if book.Title == "" {
+		return fmt.Errorf("tytuł książki jest wymagany")
+	}
+	if book.Author == "" {
+		return fmt.Errorf("autor książki jest wymagany")
+	}
+	if err := setCallNumber(book); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	book.CreatedAt = now
+	book.UpdatedAt = now
+	if book.ID == "" {
+		book.ID = newID()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if book.ShortCode == "" {
+		code, err := s.unusedShortCode()
+		if err != nil {
+			return err
+		}
+		book.ShortCode = code
+	}
+
+	stored := *book
+	s.books[book.ID] = &stored
+	return nil
+}
+
+// EnsureBookShortCode nadaje krótki kod książce bez kodu (w pamięci kod dostaje każda
+// nowa książka, więc zwykle nie ma nic do zrobienia)
+func (s *Store) EnsureBookShortCode(book *models.Book) error {
+	if book.ShortCode != "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.books[book.ID]
+	if !ok {
+		return fmt.Errorf("książka %s nie została znaleziona", book.ID)
+	}
+	if stored.ShortCode == "" {
+		code, err := s.unusedShortCode()
+		if err != nil {
+			return err
+		}
+		stored.ShortCode = code
+	}
+	book.ShortCode = stored.ShortCode
+	return nil
+}
+
+// unusedShortCode losuje krótki kod permalinku, którego nie ma żadna książka (wywoływane pod blokadą)
+func (s *Store) unusedShortCode() (string, error) {
+	for i := 0; i < shortCodeAttempts; i++ {
+		code, err := models.NewShortCode()
+		if err != nil {
+			return "", err
+		}
+		taken := false
+		for _, book := range s.books {
+			if book.ShortCode == code {
+				taken = true
+				break
+			}
+		}
+		if !taken {
+			return code, nil
+		}
+	}
+	return "", fmt.Errorf("nie udało się wygenerować unikalnego kodu książki")
+}
+
+// UpdateBook zapisuje zmiany istniejącej książki
+func (s *Store) UpdateBook(id string, book *models.Book) error {
+	if id == "" {
+		return fmt.Errorf("ID książki nie może być puste")
+	}
+	if book == nil {
+		return fmt.Errorf("książka nie może być nil")
+	}
+	if err := setCallNumber(book); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.books[id]
+	if !ok {
+		return fmt.Errorf("książka nie istnieje: %s", id)
+	}
+
+	book.ID = id
+	book.UpdatedAt = time.Now()
+	book.ShortCode = existing.ShortCode
+	stored := *book
+	s.books[id] = &stored
+	return nil
+}
+
+// DeleteBook usuwa książkę z katalogu
+func (s *Store) DeleteBook(id string) error {
+	if id == "" {
+		return fmt.Errorf("ID książki nie może być puste")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.books[id]; !ok {
+		return fmt.Errorf("książka nie istnieje: %s", id)
+	}
+	delete(s.books, id)
+	return nil
+}
+
+// UpdateBookAvailability zwiększa lub zmniejsza liczbę wolnych egzemplarzy
+func (s *Store) UpdateBookAvailability(bookID string, increment bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	book, ok := s.books[bookID]
+	if !ok {
+		return fmt.Errorf("książka nie istnieje: %s", bookID)
+	}
+
+	if increment {
+		book.IncrementAvailableCopies()
+	} else {
+		if !book.IsAvailable() {
+			return fmt.Errorf("książka nie jest dostępna")
+		}
+		book.DecrementAvailableCopies()
+	}
+	book.UpdatedAt = time.Now()
+	return nil
+}
+
+// HasActiveLoans sprawdza czy książka ma aktywne wypożyczenia
+func (s *Store) HasActiveLoans(bookID string) (bool, error) {
+	if bookID == "" {
+		return false, fmt.Errorf("ID książki nie może być puste")
+	}
+
+	loans := s.filterLoans(func(loan *models.Loan) bool {
+		return loan.BookID == bookID && loan.IsBorrowed()
+	})
+	return len(loans) > 0, nil
+}
+
+// CountTotalBooks zwraca liczbę książek w katalogu
+func (s *Store) CountTotalBooks() (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.books), nil
+}
+
+// GetBooksActivity zlicza bieżące wypożyczenia i rezerwacje w kolejce dla listy książek
+func (s *Store) GetBooksActivity(bookIDs []string) (map[string]*models.BookActivity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	activity := make(map[string]*models.BookActivity, len(bookIDs))
+	for _, id := range bookIDs {
+		activity[id] = &models.BookActivity{}
+	}
+	for _, loan := range s.loans {
+		if item, ok := activity[loan.BookID]; ok && (loan.IsBorrowed() || loan.Status == models.LoanStatusPendingPickup) {
+			item.Loans++
+		}
+	}
+	for _, reservation := range s.reservations {
+		if item, ok := activity[reservation.BookID]; ok && reservation.Status == models.ReservationStatusPending {
+			item.Reservations++
+		}
+	}
+	return activity, nil
+}
+
+// WithdrawCopies wycofuje egzemplarze stojące na półce (zasady jak w kliencie Firestore)
+func (s *Store) WithdrawCopies(withdrawal *models.CopyWithdrawal) error {
+	if withdrawal == nil {
+		return fmt.Errorf("wycofanie nie może być nil")
+	}
+	if withdrawal.BookID == "" {
+		return fmt.Errorf("ID książki nie może być puste")
+	}
+	if !models.ValidWithdrawalReason(withdrawal.Reason) {
+		return fmt.Errorf("nieznany powód wycofania")
+	}
+	if withdrawal.Count < 1 {
+		return fmt.Errorf("liczba wycofywanych egzemplarzy musi być większa od zera")
+	}
+	withdrawal.Notes = strings.TrimSpace(withdrawal.Notes)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	book, ok := s.books[withdrawal.BookID]
+	if !ok {
+		return fmt.Errorf("błąd wycofywania egzemplarzy: książka %s nie istnieje", withdrawal.BookID)
+	}
+	if withdrawal.Count > book.AvailableCopies {
+		return fmt.Errorf("dostępnych na półce egzemplarzy: %d - wypożyczone i odłożone dla czytelników trzeba najpierw odebrać", book.AvailableCopies)
+	}
+	if withdrawal.Count >= book.TotalCopies {
+		return fmt.Errorf("nie można wycofać wszystkich egzemplarzy - aby usunąć tytuł, usuń książkę z katalogu")
+	}
+
+	now := time.Now()
+	book.TotalCopies -= withdrawal.Count
+	book.AvailableCopies -= withdrawal.Count
+	book.UpdatedAt = now
+
+	withdrawal.ID = newID()
+	withdrawal.BookTitle = book.Title
+	withdrawal.CreatedAt = now
+	stored := *withdrawal
+	s.withdrawals = append(s.withdrawals, &stored)
+	return nil
+}
+
+// GetBookWithdrawals pobiera historię wycofań egzemplarzy książki (najnowsze pierwsze)
+func (s *Store) GetBookWithdrawals(bookID string) ([]*models.CopyWithdrawal, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var withdrawals []*models.CopyWithdrawal
+	for i := len(s.withdrawals) - 1; i >= 0; i-- {
+		if s.withdrawals[i].BookID == bookID {
+			copied := *s.withdrawals[i]
+			withdrawals = append(withdrawals, &copied)
+		}
+	}
+	return withdrawals, nil
+}
+
+// filterBooks zwraca kopie książek spełniających warunek (nil - wszystkie) w kolejności tytułów
+func (s *Store) filterBooks(keep func(*models.Book) bool) []*models.Book {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var books []*models.Book
+	for _, book := range s.books {
+		if keep == nil || keep(book) {
+			copied := *book
+			books = append(books, &copied)
+		}
+	}
+	sort.Slice(books, func(i, j int) bool {
+		if books[i].Title != books[j].Title {
+			return search.Fold(books[i].Title) < search.Fold(books[j].Title)
+		}
+		return books[i].ID < books[j].ID
+	})
+	return books
+}
+
+// setCallNumber sprawdza sygnaturę książki i wylicza jej klucz kolejności półkowej
+func setCallNumber(book *models.Book) error {
+	callNumber, err := models.NormalizeCallNumber(book.CallNumber)
+	if err != nil {
+		return err
+	}
+	book.CallNumber = callNumber
+	book.CallNumberKey = models.CallNumberKey(callNumber)
+	return nil
+}
+
+// Wypożyczenia
+
+// GetLoan pobiera wypożyczenie po ID
+func (s *Store) GetLoan(id string) (*models.Loan, error) {
+	if id == "" {
+		return nil, fmt.Errorf("ID wypożyczenia nie może być puste")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	loan, ok := s.loans[id]
+	if !ok {
+		return nil, fmt.Errorf("wypożyczenie %s nie zostało znalezione", id)
+	}
+	copied := *loan
+	return &copied, nil
+}
+
+// CreateLoan tworzy zamówienie książki oczekujące na odbiór (termin zwrotu ustala wydanie)
+func (s *Store) CreateLoan(loan *models.Loan) error {
+	if loan == nil {
+		return fmt.Errorf("wypożyczenie nie może być nil")
+	}
+	if loan.BookID == "" || loan.UserID == "" {
+		return fmt.Errorf("ID książki i użytkownika są wymagane")
+	}
+
+	now := time.Now()
+	loan.CreatedAt = now
+	loan.UpdatedAt = now
+	loan.LoanDate = now
+	loan.Status = models.LoanStatusPendingPickup
+	loan.DueDate = time.Time{}
+	if loan.ID == "" {
+		loan.ID = newID()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	code, err := s.unusedPickupCode()
+	if err != nil {
+		return err
+	}
+	loan.PickupCode = code
+
+	stored := *loan
+	s.loans[loan.ID] = &stored
+	return nil
+}
+
+// unusedPickupCode losuje kod odbioru, którego nie ma żadne wypożyczenie czekające
+// na odbiór (wywoływane pod blokadą)
+func (s *Store) unusedPickupCode() (string, error) {
+	for i := 0; i < models.PickupCodeAttempts; i++ {
+		code, err := s.settings.PickupCode().Generate()
+		if err != nil {
+			return "", err
+		}
+		taken := false
+		for _, loan := range s.loans {
+			if loan.Status == models.LoanStatusPendingPickup && loan.PickupCode == code {
+				taken = true
+				break
+			}
+		}
+		if !taken {
+			return code, nil
+		}
+	}
+	return "", fmt.Errorf("nie udało się wygenerować unikalnego kodu odbioru")
+}
+
+// GetLoanByPickupCode pobiera zamówienie czekające na odbiór po kodzie (nil, gdy brak)
+func (s *Store) GetLoanByPickupCode(pickupCode string) (*models.Loan, error) {
+	loans := s.filterLoans(func(loan *models.Loan) bool {
+		return loan.Status == models.LoanStatusPendingPickup && loan.PickupCode == pickupCode
+	})
+	if len(loans) == 0 {
+		return nil, nil
+	}
+	return loans[0], nil
+}
+
+// ConfirmPickup wydaje zamówioną książkę: wypożyczenie z kodem odbioru staje się aktywne
+// z terminem zwrotu według okresu wypożyczenia z ustawień
+func (s *Store) ConfirmPickup(pickupCode string) error {
+	if pickupCode == "" {
+		return fmt.Errorf("kod odbioru nie może być pusty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, loan := range s.loans {
+		if loan.Status == models.LoanStatusPendingPickup && loan.PickupCode == pickupCode {
+			now := time.Now()
+			loan.Status = models.LoanStatusActive
+			loan.DueDate = now.AddDate(0, 0, s.settings.LoanDays)
+			loan.UpdatedAt = now
+			return nil
+		}
+	}
+	return fmt.Errorf("nie znaleziono wypożyczenia z kodem %s", pickupCode)
+}
+
+// UpdateLoan zapisuje zmiany wypożyczenia
+func (s *Store) UpdateLoan(id string, loan *models.Loan) error {
+	if id == "" {
+		return fmt.Errorf("ID wypożyczenia nie może być puste")
+	}
+	if loan == nil {
+		return fmt.Errorf("wypożyczenie nie może być nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.loans[id]; !ok {
+		return fmt.Errorf("wypożyczenie nie istnieje: %s", id)
+	}
+
+	loan.ID = id
+	loan.UpdatedAt = time.Now()
+	stored := *loan
+	s.loans[id] = &stored
+	return nil
+}
+
+// RenewLoan przedłuża termin zwrotu o okres wypożyczenia (zasady jak w kliencie Firestore)
+func (s *Store) RenewLoan(loanID, userID string) (*models.Loan, error) {
+	loan, err := s.GetLoan(loanID)
+	if err != nil {
+		return nil, err
+	}
+	if loan.UserID != userID {
+		return nil, fmt.Errorf("wypożyczenie należy do innego czytelnika")
+	}
+	if !loan.IsBorrowed() || loan.IsReadingRoom() {
+		return nil, fmt.Errorf("tego wypożyczenia nie można przedłużyć")
+	}
+	if loan.IsOverdue() {
+		return nil, fmt.Errorf("termin zwrotu już minął - przedłużenie jest możliwe tylko w bibliotece")
+	}
+	if loan.Renewals >= models.MaxRenewals {
+		return nil, fmt.Errorf("osiągnięto limit przedłużeń (%d)", models.MaxRenewals)
+	}
+
+	next, err := s.GetNextReservation(loan.BookID)
+	if err != nil {
+		return nil, err
+	}
+	if next != nil {
+		return nil, fmt.Errorf("na tę książkę czekają inni czytelnicy")
+	}
+
+	loan.DueDate = loan.DueDate.AddDate(0, 0, s.settings.LoanDays)
+	loan.Renewals++
+	if err := s.UpdateLoan(loan.ID, loan); err != nil {
+		return nil, err
+	}
+	return loan, nil
+}
+
+// CreateReadingRoomLoan udostępnia książkę na miejscu: wypożyczenie jest od razu aktywne,
+// nie wlicza się do limitu czytelnika i trwa do końca dnia
+func (s *Store) CreateReadingRoomLoan(book *models.Book, user *models.User) (*models.Loan, error) {
+	if !user.IsActive {
+		return nil, fmt.Errorf("konto czytelnika jest nieaktywne")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.books[book.ID]
+	if !ok {
+		return nil, fmt.Errorf("książka nie istnieje: %s", book.ID)
+	}
+	if !stored.IsAvailable() {
+		return nil, fmt.Errorf("książka nie ma dostępnych egzemplarzy")
+	}
+
+	now := time.Now()
+	loan := &models.Loan{
+		ID:        newID(),
+		BookID:    book.ID,
+		UserID:    user.ID,
+		BookTitle: stored.Title,
+		UserName:  user.FullName(),
+		Status:    models.LoanStatusActive,
+		Type:      models.LoanTypeReadingRoom,
+		LoanDate:  now,
+		DueDate:   time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 0, now.Location()),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	stored.DecrementAvailableCopies()
+	stored.UpdatedAt = now
+
+	copied := *loan
+	s.loans[loan.ID] = &copied
+	return loan, nil
+}
+
+// ReturnLoan kończy wypożyczenie pod jedną blokadą (zasady jak w kliencie Firestore):
+// nalicza karę, zmniejsza licznik wypożyczeń czytelnika i oddaje egzemplarz pierwszej
+// rezerwacji w kolejce albo odkłada go na półkę
+func (s *Store) ReturnLoan(loanID string) (*models.ReturnResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	loan, ok := s.loans[loanID]
+	if !ok {
+		return nil, fmt.Errorf("wypożyczenie %s nie zostało znalezione", loanID)
+	}
+	if !loan.IsBorrowed() {
+		return nil, fmt.Errorf("wypożyczenie nie jest aktywne")
+	}
+
+	// Część kary naliczona przez nocne zadanie jest już w saldzie, a umorzona nie rośnie
+	fine := loan.CalculateFine()
+	if loan.FineWaived {
+		fine = loan.FineAmount
+	}
+	fineIncrease := max(fine-loan.FineAmount, 0)
+
+	now := time.Now()
+	loan.ReturnDate = &now
+	loan.Status = models.LoanStatusReturned
+	loan.FineAmount = fine
+	loan.UpdatedAt = now
+
+	if user, ok := s.users[loan.UserID]; ok && !loan.IsReadingRoom() {
+		if user.CurrentLoans > 0 {
+			user.CurrentLoans--
+		}
+		user.TotalFines += fineIncrease
+		user.UpdatedAt = now
+	}
+
+	returned := *loan
+	result := &models.ReturnResult{Loan: &returned, Fine: fine}
+
+	book, ok := s.books[loan.BookID]
+	if !ok {
+		return result, nil // Książkę usunięto z katalogu w trakcie wypożyczenia
+	}
+	if next := s.nextReservation(book); next != nil {
+		// Egzemplarz czeka na czytelnika z kolejki - nie wraca na półkę
+		next.Status = models.ReservationStatusReady
+		next.BookID = book.ID
+		next.BookTitle = book.Title
+		next.NotifiedDate = &now
+		next.SuspendedAt = nil
+		next.ExpiryDate = now.AddDate(0, 0, s.settings.PickupDays)
+		next.UpdatedAt = now
+		ready := *next
+		result.NextReservation = &ready
+	} else {
+		book.IncrementAvailableCopies()
+		book.UpdatedAt = now
+	}
+	return result, nil
+}
+
+// GetUserLoans pobiera wszystkie wypożyczenia użytkownika
+func (s *Store) GetUserLoans(userID string) ([]*models.Loan, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("ID użytkownika nie może być puste")
+	}
+	return s.filterLoans(func(loan *models.Loan) bool { return loan.UserID == userID }), nil
+}
+
+// GetUserActiveLoans pobiera wypożyczenia użytkownika aktywne i oczekujące na odbiór
+func (s *Store) GetUserActiveLoans(userID string) ([]*models.Loan, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("ID użytkownika nie może być puste")
+	}
+	return s.filterLoans(func(loan *models.Loan) bool {
+		return loan.UserID == userID &&
+			(loan.IsBorrowed() || loan.Status == models.LoanStatusPendingPickup)
+	}), nil
+}
+
+// GetUserLoanHistory pobiera zwrócone wypożyczenia użytkownika
+func (s *Store) GetUserLoanHistory(userID string) ([]*models.Loan, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("ID użytkownika nie może być puste")
+	}
+	return s.filterLoans(func(loan *models.Loan) bool {
+		return loan.UserID == userID && loan.Status == models.LoanStatusReturned
+	}), nil
+}
+
+// GetBookLoans pobiera wszystkie wypożyczenia książki, od najnowszych
+func (s *Store) GetBookLoans(bookID string) ([]*models.Loan, error) {
+	if bookID == "" {
+		return nil, fmt.Errorf("ID książki nie może być puste")
+	}
+	return s.filterLoans(func(loan *models.Loan) bool { return loan.BookID == bookID }), nil
+}
+
+// GetBookActiveLoans pobiera aktywne wypożyczenia książki
+func (s *Store) GetBookActiveLoans(bookID string) ([]*models.Loan, error) {
+	if bookID == "" {
+		return nil, fmt.Errorf("ID książki nie może być puste")
+	}
+	return s.filterLoans(func(loan *models.Loan) bool {
+		return loan.BookID == bookID && loan.IsBorrowed()
+	}), nil
+}
+
+// ListLoans pobiera wszystkie wypożyczenia, od najnowszych
+func (s *Store) ListLoans() ([]*models.Loan, error) {
+	return s.filterLoans(func(*models.Loan) bool { return true }), nil
+}
+
+// GetPendingPickupLoans pobiera zamówienia czekające na odbiór, od najnowszych
+func (s *Store) GetPendingPickupLoans() ([]*models.Loan, error) {
+	return s.filterLoans(func(loan *models.Loan) bool {
+		return loan.Status == models.LoanStatusPendingPickup
+	}), nil
+}
+
+// GetActiveLoans pobiera wszystkie aktywne wypożyczenia
+func (s *Store) GetActiveLoans() ([]*models.Loan, error) {
+	return s.filterLoans(func(loan *models.Loan) bool { return loan.IsBorrowed() }), nil
+}
+
+// GetOverdueLoans pobiera aktywne wypożyczenia po terminie zwrotu
+func (s *Store) GetOverdueLoans() ([]*models.Loan, error) {
+	now := time.Now()
+	return s.filterLoans(func(loan *models.Loan) bool {
+		return loan.IsBorrowed() && loan.DueDate.Before(now)
+	}), nil
+}
+
+// CountActiveLoans zwraca liczbę aktywnych wypożyczeń
+func (s *Store) CountActiveLoans() (int, error) {
+	loans, err := s.GetActiveLoans()
+	return len(loans), err
+}
+
+// CountOverdueLoans zwraca liczbę wypożyczeń po terminie zwrotu
+func (s *Store) CountOverdueLoans() (int, error) {
+	loans, err := s.GetOverdueLoans()
+	return len(loans), err
+}
+
+// AccrueLoanFine podnosi karę wypożyczenia po terminie i saldo czytelnika pod jedną
+// blokadą (zasady jak w kliencie Firestore)
+func (s *Store) AccrueLoanFine(loanID string, fine models.Money) (models.Money, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	loan, ok := s.loans[loanID]
+	if !ok {
+		return 0, fmt.Errorf("wypożyczenie nie istnieje: %s", loanID)
+	}
+	if !loan.IsBorrowed() || loan.FineWaived {
+		return 0, nil
+	}
+
+	increase := fine - loan.FineAmount
+	if increase <= 0 {
+		increase = 0
+		if loan.Status == models.LoanStatusOverdue {
+			return 0, nil
+		}
+	}
+
+	var user *models.User
+	if increase > 0 {
+		if user, ok = s.users[loan.UserID]; !ok {
+			return 0, fmt.Errorf("błąd naliczania kary: użytkownik %s nie istnieje", loan.UserID)
+		}
+	}
+
+	now := time.Now()
+	loan.Status = models.LoanStatusOverdue
+	loan.FineAmount += increase
+	loan.UpdatedAt = now
+	if user != nil {
+		user.TotalFines += increase
+		user.UpdatedAt = now
+	}
+	return increase, nil
+}
+
+// filterLoans zwraca kopie wypożyczeń spełniających warunek, od najnowszych
+func (s *Store) filterLoans(keep func(*models.Loan) bool) []*models.Loan {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var loans []*models.Loan
+	for _, loan := range s.loans {
+		if keep(loan) {
+			copied := *loan
+			loans = append(loans, &copied)
+		}
+	}
+	sort.Slice(loans, func(i, j int) bool {
+		return loans[i].LoanDate.After(loans[j].LoanDate)
+	})
+	return loans
+}
+
+// Konta
+
+// GetUser pobiera użytkownika po ID
+func (s *Store) GetUser(id string) (*models.User, error) {
+	if id == "" {
+		return nil, fmt.Errorf("ID użytkownika nie może być puste")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return nil, fmt.Errorf("użytkownik nie został znaleziony")
+	}
+	copied := *user
+	return &copied, nil
+}
+
+// GetUsersByIDs pobiera kilku użytkowników naraz (nieistniejących kont brakuje w mapie)
+func (s *Store) GetUsersByIDs(ids []string) (map[string]*models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := make(map[string]*models.User, len(ids))
+	for _, id := range ids {
+		if user, ok := s.users[id]; ok {
+			copied := *user
+			users[id] = &copied
+		}
+	}
+	return users, nil
+}
+
+// GetUserByFirebaseUID pobiera użytkownika po UID konta logowania
+func (s *Store) GetUserByFirebaseUID(uid string) (*models.User, error) {
+	if uid == "" {
+		return nil, fmt.Errorf("Firebase UID nie może być pusty")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, user := range s.users {
+		if user.FirebaseUID == uid {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, fmt.Errorf("użytkownik nie został znaleziony")
+}
+
+// ListUsers pobiera wszystkich użytkowników w kolejności nazwisk
+func (s *Store) ListUsers() ([]*models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := make([]*models.User, 0, len(s.users))
+	for _, user := range s.users {
+		copied := *user
+		users = append(users, &copied)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].LastName != users[j].LastName {
+			return users[i].LastName < users[j].LastName
+		}
+		return users[i].ID < users[j].ID
+	})
+	return users, nil
+}
+
+// CountTotalUsers zwraca liczbę kont
+func (s *Store) CountTotalUsers() (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.users), nil
+}
+
+// Register zakłada konto z hasłem (rejestracja i konta demonstracyjne trybu bez bazy danych).
+// Hasło jest przechowywane jako hash bcrypt, jak PIN w Firestore.
+func (s *Store) Register(user *models.User, password string) error {
+	if user == nil {
+		return fmt.Errorf("użytkownik nie może być nil")
+	}
+	if user.Email == "" || password == "" {
+		return fmt.Errorf("email i hasło są wymagane")
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("błąd zapisywania hasła: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.userByEmail(user.Email) != nil {
+		return fmt.Errorf("użytkownik z adresem %s już istnieje", user.Email)
+	}
+
+	if user.MaxLoans == 0 {
+		user.MaxLoans = s.settings.MaxLoans // Domyślny limit z ustawień biblioteki
+	}
+	now := time.Now()
+	user.ID = newID()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	stored := *user
+	s.users[user.ID] = &stored
+	s.passwords[user.ID] = hashed
+	return nil
+}
+
+// Authenticate zwraca konto o tym adresie email, gdy hasło się zgadza (nil, gdy nie)
+func (s *Store) Authenticate(email, password string) (*models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user := s.userByEmail(email)
+	if user == nil {
+		return nil, nil
+	}
+	if bcrypt.CompareHashAndPassword(s.passwords[user.ID], []byte(password)) != nil {
+		return nil, nil
+	}
+	copied := *user
+	return &copied, nil
+}
+
+// userByEmail zwraca konto o tym adresie email bez względu na wielkość liter (wywoływane pod blokadą)
+func (s *Store) userByEmail(email string) *models.User {
+	email = strings.TrimSpace(email)
+	for _, user := range s.users {
+		if strings.EqualFold(user.Email, email) {
+			return user
+		}
+	}
+	return nil
+}
+
+// UpdateUser zapisuje dane użytkownika. Nieznane konto jest dodawane bez hasła - tak
+// powstają konta lokalne w skryptach i testach (logowanie wymaga konta z Register).
+func (s *Store) UpdateUser(id string, user *models.User) error {
+	if id == "" {
+		return fmt.Errorf("ID użytkownika nie może być puste")
+	}
+	if user == nil {
+		return fmt.Errorf("użytkownik nie może być nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if _, ok := s.users[id]; !ok && user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	user.ID = id
+	user.UpdatedAt = now
+	stored := *user
+	s.users[id] = &stored
+	return nil
+}
+
+// UpdateUserLoansCount zwiększa lub zmniejsza licznik wypożyczeń użytkownika
+func (s *Store) UpdateUserLoansCount(userID string, increment bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return fmt.Errorf("błąd aktualizacji liczby wypożyczeń: użytkownik %s nie istnieje", userID)
+	}
+	if increment {
+		user.CurrentLoans++
+	} else {
+		user.CurrentLoans--
+	}
+	user.UpdatedAt = time.Now()
+	return nil
+}
+
+// UpdateUserFines dolicza kwotę do sumy kar użytkownika (ujemna kwota ją zmniejsza)
+func (s *Store) UpdateUserFines(userID string, amount models.Money) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return fmt.Errorf("błąd aktualizacji kar: użytkownik %s nie istnieje", userID)
+	}
+	user.TotalFines += amount
+	user.UpdatedAt = time.Now()
+	return nil
+}
+
+// Rezerwacje
+
+// GetReservation pobiera rezerwację po ID
+func (s *Store) GetReservation(id string) (*models.Reservation, error) {
+	if id == "" {
+		return nil, fmt.Errorf("ID rezerwacji nie może być puste")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	reservation, ok := s.reservations[id]
+	if !ok {
+		return nil, fmt.Errorf("rezerwacja %s nie została znaleziona", id)
+	}
+	copied := *reservation
+	return &copied, nil
+}
+
+// CreateReservation dodaje rezerwację na koniec kolejki do książki
+func (s *Store) CreateReservation(reservation *models.Reservation) error {
+	if reservation == nil {
+		return fmt.Errorf("rezerwacja nie może być nil")
+	}
+	if reservation.BookID == "" || reservation.UserID == "" {
+		return fmt.Errorf("ID książki i użytkownika są wymagane")
+	}
+
+	now := time.Now()
+	reservation.CreatedAt = now
+	reservation.UpdatedAt = now
+	reservation.ReservationDate = now
+	reservation.Status = models.ReservationStatusPending
+	if reservation.ExpiryDate.IsZero() {
+		reservation.ExpiryDate = now.AddDate(0, 0, s.settings.PickupDays)
+	}
+	if reservation.ID == "" {
+		reservation.ID = newID()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := *reservation
+	s.reservations[reservation.ID] = &stored
+	return nil
+}
+
+// CancelReservation anuluje rezerwację
+func (s *Store) CancelReservation(reservationID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reservation, ok := s.reservations[reservationID]
+	if !ok {
+		return fmt.Errorf("rezerwacja %s nie została znaleziona", reservationID)
+	}
+	if reservation.Status == models.ReservationStatusCompleted {
+		return fmt.Errorf("nie można anulować zrealizowanej rezerwacji")
+	}
+	reservation.Status = models.ReservationStatusCancelled
+	reservation.UpdatedAt = time.Now()
+	return nil
+}
+
+// GetUserReservations pobiera rezerwacje użytkownika, od najnowszych
+func (s *Store) GetUserReservations(userID string) ([]*models.Reservation, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("ID użytkownika nie może być puste")
+	}
+	reservations := s.filterReservations(func(r *models.Reservation) bool { return r.UserID == userID })
+	for i, j := 0, len(reservations)-1; i < j; i, j = i+1, j-1 {
+		reservations[i], reservations[j] = reservations[j], reservations[i]
+	}
+	return reservations, nil
+}
+
+// GetUserActiveReservations pobiera oczekujące i gotowe do odbioru rezerwacje użytkownika
+func (s *Store) GetUserActiveReservations(userID string) ([]*models.Reservation, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("ID użytkownika nie może być puste")
+	}
+	return s.filterReservations(func(r *models.Reservation) bool {
+		return r.UserID == userID &&
+			(r.Status == models.ReservationStatusPending || r.Status == models.ReservationStatusReady)
+	}), nil
+}
+
+// GetBookReservations pobiera rezerwacje książki w kolejności złożenia
+func (s *Store) GetBookReservations(bookID string) ([]*models.Reservation, error) {
+	if bookID == "" {
+		return nil, fmt.Errorf("ID książki nie może być puste")
+	}
+	return s.filterReservations(func(r *models.Reservation) bool { return r.BookID == bookID }), nil
+}
+
+// GetReadyReservations pobiera rezerwacje gotowe do odbioru, od najbliższego terminu odbioru
+func (s *Store) GetReadyReservations() ([]*models.Reservation, error) {
+	reservations := s.filterReservations(func(r *models.Reservation) bool {
+		return r.Status == models.ReservationStatusReady
+	})
+	sort.SliceStable(reservations, func(i, j int) bool {
+		return reservations[i].ExpiryDate.Before(reservations[j].ExpiryDate)
+	})
+	return reservations, nil
+}
+
+// GetNextReservation zwraca najdłużej oczekującą rezerwację, którą może zaspokoić egzemplarz
+// książki (także rezerwację innego wydania lub rekordu z tym samym ISBN; nil, gdy kolejka jest pusta).
+// W przeciwieństwie do klienta Firestore nie pomija czytelników na urlopie ani z pełnym limitem.
+func (s *Store) GetNextReservation(bookID string) (*models.Reservation, error) {
+	book, err := s.GetBook(bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	next := s.nextReservation(book)
+	if next == nil {
+		return nil, nil
+	}
+	copied := *next
+	return &copied, nil
+}
+
+// nextReservation zwraca najstarszą oczekującą rezerwację pasującą do książki (wywoływane pod blokadą)
+func (s *Store) nextReservation(book *models.Book) *models.Reservation {
+	var next *models.Reservation
+	for _, reservation := range s.reservations {
+		if reservation.Status == models.ReservationStatusPending && search.MatchesHold(reservation, book) &&
+			(next == nil || reservation.ReservationDate.Before(next.ReservationDate)) {
+			next = reservation
+		}
+	}
+	return next
+}
+
+// filterReservations zwraca kopie rezerwacji spełniających warunek, od najstarszych
+func (s *Store) filterReservations(keep func(*models.Reservation) bool) []*models.Reservation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var reservations []*models.Reservation
+	for _, reservation := range s.reservations {
+		if keep(reservation) {
+			copied := *reservation
+			reservations = append(reservations, &copied)
+		}
+	}
+	sort.Slice(reservations, func(i, j int) bool {
+		return reservations[i].ReservationDate.Before(reservations[j].ReservationDate)
+	})
+	return reservations
+}
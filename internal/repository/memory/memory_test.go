@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"testing"
+
+	"library-management-system/internal/models"
+)
+
+func TestCreateLoanPickupCodesAreUnique(t *testing.T) {
+	s := NewStore([]models.Book{{ID: "b1", Title: "Lalka", Author: "Bolesław Prus", TotalCopies: 1}})
+	// 4 cyfry: przy 2000 czekających książkach bez sprawdzania kody powtarzałyby się wielokrotnie
+	s.settings.PickupCodeAlphabet = models.PickupCodeNumeric
+	s.settings.PickupCodeLength = models.MinPickupCodeLength
+
+	seen := make(map[string]bool)
+	for i := 0; i < 2000; i++ {
+		loan := &models.Loan{BookID: "b1", UserID: "u1"}
+		if err := s.CreateLoan(loan); err != nil {
+			t.Fatalf("wypożyczenie %d: %v", i, err)
+		}
+		if len(loan.PickupCode) != 4 {
+			t.Fatalf("kod odbioru %q, oczekiwano 4 cyfr", loan.PickupCode)
+		}
+		if seen[loan.PickupCode] {
+			t.Fatalf("kod odbioru %s nadany dwóm czekającym wypożyczeniom", loan.PickupCode)
+		}
+		seen[loan.PickupCode] = true
+	}
+}
+
+func TestRegisterAndAuthenticate(t *testing.T) {
+	s := NewStore(nil)
+	user := &models.User{Email: "anna@example.com", FirstName: "Anna", LastName: "Nowak", Role: models.RoleReader, IsActive: true}
+	if err := s.Register(user, "sekret1"); err != nil {
+		t.Fatal(err)
+	}
+	if user.ID == "" || user.MaxLoans != s.settings.MaxLoans {
+		t.Errorf("konto po rejestracji: ID %q, limit %d; oczekiwano ID i domyślnego limitu %d", user.ID, user.MaxLoans, s.settings.MaxLoans)
+	}
+
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		ok       bool
+	}{
+		{"poprawne hasło", "anna@example.com", "sekret1", true},
+		{"email wielkimi literami", "Anna@Example.com", "sekret1", true},
+		{"złe hasło", "anna@example.com", "sekret2", false},
+		{"nieznany email", "jan@example.com", "sekret1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := s.Authenticate(tt.email, tt.password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (found != nil) != tt.ok {
+				t.Errorf("Authenticate(%q) = %+v, oczekiwano zalogowania: %v", tt.email, found, tt.ok)
+			}
+		})
+	}
+
+	if err := s.Register(&models.User{Email: "ANNA@example.com"}, "inne-haslo"); err == nil {
+		t.Error("Register z zajętym adresem email powinien zwrócić błąd")
+	}
+}
+
+func TestCreateBookAssignsShortCode(t *testing.T) {
+	s := NewStore([]models.Book{{ID: "b1", Title: "Lalka", Author: "Bolesław Prus", TotalCopies: 1}})
+
+	book, err := s.GetBook("b1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if book.ShortCode == "" {
+		t.Fatal("książka nie dostała krótkiego kodu")
+	}
+	if found, err := s.GetBookByShortCode(book.ShortCode); err != nil || found == nil || found.ID != "b1" {
+		t.Errorf("GetBookByShortCode(%q) = %+v, %v; oczekiwano książki b1", book.ShortCode, found, err)
+	}
+}
@@ -0,0 +1,267 @@
+package memory
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"library-management-system/internal/models"
+)
+
+// Listy lektur personelu
+
+// CreateReadingList zapisuje nową listę lektur
+func (s *Store) CreateReadingList(list *models.ReadingList) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.validateReadingList(list); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	list.CreatedAt = now
+	list.UpdatedAt = now
+	list.ID = newID()
+	s.readingLists[list.ID] = copyReadingList(list)
+	return nil
+}
+
+// GetReadingList pobiera listę lektur po ID
+func (s *Store) GetReadingList(id string) (*models.ReadingList, error) {
+	if id == "" {
+		return nil, fmt.Errorf("ID listy lektur nie może być puste")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list, ok := s.readingLists[id]
+	if !ok {
+		return nil, fmt.Errorf("lista lektur %s nie została znaleziona", id)
+	}
+	return copyReadingList(list), nil
+}
+
+// GetReadingListBySlug pobiera listę lektur po adresie albo nil, jeśli jej nie ma
+func (s *Store) GetReadingListBySlug(slug string) (*models.ReadingList, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if list := s.readingListBySlug(slug); list != nil {
+		return copyReadingList(list), nil
+	}
+	return nil, nil
+}
+
+// UpdateReadingList zapisuje zmiany listy lektur (opis, pozycje i ich kolejność)
+func (s *Store) UpdateReadingList(list *models.ReadingList) error {
+	if list == nil || list.ID == "" {
+		return fmt.Errorf("ID listy lektur nie może być puste")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.validateReadingList(list); err != nil {
+		return err
+	}
+
+	list.UpdatedAt = time.Now()
+	s.readingLists[list.ID] = copyReadingList(list)
+	return nil
+}
+
+// DeleteReadingList usuwa listę lektur
+func (s *Store) DeleteReadingList(id string) error {
+	if id == "" {
+		return fmt.Errorf("ID listy lektur nie może być puste")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.readingLists, id)
+	return nil
+}
+
+// ListReadingLists pobiera wszystkie listy lektur posortowane według tytułu
+func (s *Store) ListReadingLists() ([]*models.ReadingList, error) {
+	return s.filterReadingLists(s.readingLists, func(*models.ReadingList) bool { return true }), nil
+}
+
+// GetPublishedReadingLists pobiera opublikowane listy lektur posortowane według tytułu
+func (s *Store) GetPublishedReadingLists() ([]*models.ReadingList, error) {
+	return s.filterReadingLists(s.readingLists, func(list *models.ReadingList) bool { return list.Published }), nil
+}
+
+// validateReadingList sprawdza listę personelu: poprawność pozycji i to, czy adres
+// nie jest zajęty przez inną listę (wywoływane pod blokadą)
+func (s *Store) validateReadingList(list *models.ReadingList) error {
+	if err := list.Normalize(); err != nil {
+		return err
+	}
+	if list.Slug == "" {
+		return fmt.Errorf("adres listy lektur musi zawierać litery lub cyfry")
+	}
+	if other := s.readingListBySlug(list.Slug); other != nil && other.ID != list.ID {
+		return fmt.Errorf("adres /lists/%s ma już lista %q", list.Slug, other.Title)
+	}
+	return nil
+}
+
+// readingListBySlug zwraca listę personelu o tym adresie (wywoływane pod blokadą)
+func (s *Store) readingListBySlug(slug string) *models.ReadingList {
+	if slug == "" {
+		return nil
+	}
+	for _, list := range s.readingLists {
+		if list.Slug == slug {
+			return list
+		}
+	}
+	return nil
+}
+
+// Listy czytelników
+
+// CreateUserReadingList zapisuje nową listę czytelnika z losowym tokenem linku do udostępniania
+func (s *Store) CreateUserReadingList(list *models.ReadingList) error {
+	if err := list.Normalize(); err != nil {
+		return err
+	}
+	if list.OwnerID == "" {
+		return fmt.Errorf("lista czytelnika musi mieć właściciela")
+	}
+
+	token, err := models.NewShareToken()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	owned := 0
+	for _, existing := range s.userReadingLists {
+		if existing.OwnerID == list.OwnerID {
+			owned++
+		}
+	}
+	if owned >= models.MaxUserReadingLists {
+		return fmt.Errorf("można mieć najwyżej %d list", models.MaxUserReadingLists)
+	}
+
+	now := time.Now()
+	list.ShareToken = token
+	list.CreatedAt = now
+	list.UpdatedAt = now
+	list.ID = newID()
+	s.userReadingLists[list.ID] = copyReadingList(list)
+	return nil
+}
+
+// GetUserReadingList pobiera listę czytelnika po ID
+func (s *Store) GetUserReadingList(id string) (*models.ReadingList, error) {
+	if id == "" {
+		return nil, fmt.Errorf("ID listy nie może być puste")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list, ok := s.userReadingLists[id]
+	if !ok {
+		return nil, fmt.Errorf("lista %s nie została znaleziona", id)
+	}
+	return copyReadingList(list), nil
+}
+
+// UpdateUserReadingList zapisuje zmiany listy czytelnika
+func (s *Store) UpdateUserReadingList(list *models.ReadingList) error {
+	if list == nil || list.ID == "" {
+		return fmt.Errorf("ID listy nie może być puste")
+	}
+	if err := list.Normalize(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list.UpdatedAt = time.Now()
+	s.userReadingLists[list.ID] = copyReadingList(list)
+	return nil
+}
+
+// RenewShareToken nadaje liście nowy token - dotychczasowy link przestaje działać
+func (s *Store) RenewShareToken(list *models.ReadingList) error {
+	token, err := models.NewShareToken()
+	if err != nil {
+		return err
+	}
+
+	list.ShareToken = token
+	return s.UpdateUserReadingList(list)
+}
+
+// DeleteUserReadingList usuwa listę czytelnika
+func (s *Store) DeleteUserReadingList(id string) error {
+	if id == "" {
+		return fmt.Errorf("ID listy nie może być puste")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.userReadingLists, id)
+	return nil
+}
+
+// GetUserReadingLists pobiera listy czytelnika posortowane według tytułu
+func (s *Store) GetUserReadingLists(userID string) ([]*models.ReadingList, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("ID użytkownika nie może być puste")
+	}
+	return s.filterReadingLists(s.userReadingLists, func(list *models.ReadingList) bool { return list.OwnerID == userID }), nil
+}
+
+// GetReadingListByShareToken pobiera listę czytelnika po tokenie z linku albo nil,
+// jeśli takiej nie ma. O tym, czy link działa, decyduje flaga Shared.
+func (s *Store) GetReadingListByShareToken(token string) (*models.ReadingList, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	lists := s.filterReadingLists(s.userReadingLists, func(list *models.ReadingList) bool { return list.ShareToken == token })
+	if len(lists) == 0 {
+		return nil, nil
+	}
+	return lists[0], nil
+}
+
+// filterReadingLists zwraca kopie list spełniających warunek, posortowane według tytułu
+func (s *Store) filterReadingLists(lists map[string]*models.ReadingList, keep func(*models.ReadingList) bool) []*models.ReadingList {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var found []*models.ReadingList
+	for _, list := range lists {
+		if keep(list) {
+			found = append(found, copyReadingList(list))
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return strings.ToLower(found[i].Title) < strings.ToLower(found[j].Title)
+	})
+	return found
+}
+
+// copyReadingList kopiuje listę razem z pozycjami, żeby zmiany kopii nie trafiały do magazynu
+func copyReadingList(list *models.ReadingList) *models.ReadingList {
+	copied := *list
+	copied.BookIDs = slices.Clone(list.BookIDs)
+	return &copied
+}
@@ -0,0 +1,152 @@
+// Package repository definiuje interfejsy dostępu do danych biblioteki (książki, wypożyczenia,
+// czytelnicy, rezerwacje). Handlery zależą od tych interfejsów zamiast od klienta Firestore,
+// dzięki czemu backend można wymienić, a w testach podstawić implementację w pamięci.
+package repository
+
+import (
+	"context"
+	"time"
+
+	"library-management-system/internal/models"
+)
+
+// BookRepository to katalog książek
+type BookRepository interface {
+	GetBook(id string) (*models.Book, error)
+	// GetBooksByIDs zwraca mapę ID -> książka (usuniętych z katalogu brakuje w mapie)
+	GetBooksByIDs(ids []string) (map[string]*models.Book, error)
+	GetBookByISBN(isbn string) (*models.Book, error)
+	GetBookByShortCode(code string) (*models.Book, error)
+	// EnsureBookShortCode nadaje krótki kod permalinku książce dodanej przed ich wprowadzeniem
+	EnsureBookShortCode(book *models.Book) error
+	// FindBookByCode szuka książki po kodzie z etykiety (także adresie z kodu QR), a potem po ISBN
+	FindBookByCode(code string) (*models.Book, error)
+	ListBooks() ([]*models.Book, error)
+	// ListBooksWithPagination zwraca stronę katalogu personelu i liczbę wszystkich książek
+	ListBooksWithPagination(limit int, offset int, sortBy string, sortOrder string) ([]*models.Book, int, error)
+	// ListBooksPage zwraca stronę katalogu (opcjonalnie jednej kategorii) po kursorze after
+	// i kursor następnej strony (pusty na ostatniej stronie)
+	ListBooksPage(category string, limit int, after string) ([]*models.Book, string, error)
+	SearchBooks(searchTerm string) ([]*models.Book, error)
+	SearchBooksAdvanced(title, author, isbn string) ([]*models.Book, error)
+	GetAvailableBooks() ([]*models.Book, error)
+	GetNewestBooks(limit int) ([]*models.Book, error)
+	// GetBooksAddedSince zwraca książki dodane od podanej chwili (najstarsze pierwsze)
+	GetBooksAddedSince(from time.Time) ([]*models.Book, error)
+	GetBooksByCategory(category string) ([]*models.Book, error)
+	GetBooksByClassification(digits string) ([]*models.Book, error)
+	GetBooksBySeries(series string) ([]*models.Book, error)
+	// GetBookEditions zwraca inne wydania tego samego tytułu (ten sam tytuł i autor)
+	GetBookEditions(book *models.Book) ([]*models.Book, error)
+	CreateBook(book *models.Book) error
+	UpdateBook(id string, book *models.Book) error
+	DeleteBook(id string) error
+	UpdateBookAvailability(bookID string, increment bool) error
+	HasActiveLoans(bookID string) (bool, error)
+	CountTotalBooks() (int, error)
+	// GetBooksActivity zlicza bieżące wypożyczenia i rezerwacje w kolejce dla listy książek
+	GetBooksActivity(bookIDs []string) (map[string]*models.BookActivity, error)
+	// WithdrawCopies wycofuje egzemplarze stojące na półce i zapisuje wpis historii
+	WithdrawCopies(withdrawal *models.CopyWithdrawal) error
+	GetBookWithdrawals(bookID string) ([]*models.CopyWithdrawal, error)
+}
+
+// LoanRepository to wypożyczenia
+type LoanRepository interface {
+	GetLoan(id string) (*models.Loan, error)
+	CreateLoan(loan *models.Loan) error
+	UpdateLoan(id string, loan *models.Loan) error
+	RenewLoan(loanID, userID string) (*models.Loan, error)
+	// GetLoanByPickupCode zwraca zamówienie czekające na odbiór z tym kodem (nil, gdy brak)
+	GetLoanByPickupCode(pickupCode string) (*models.Loan, error)
+	// ConfirmPickup wydaje zamówioną książkę - wypożyczenie staje się aktywne z terminem zwrotu
+	ConfirmPickup(pickupCode string) error
+	// CreateReadingRoomLoan udostępnia książkę na miejscu do końca dnia (poza limitem wypożyczeń)
+	CreateReadingRoomLoan(book *models.Book, user *models.User) (*models.Loan, error)
+	// ReturnLoan kończy wypożyczenie przy ladzie: nalicza karę i przekazuje książkę
+	// następnej osobie w kolejce rezerwacji albo odkłada ją na półkę
+	ReturnLoan(loanID string) (*models.ReturnResult, error)
+	ListLoans() ([]*models.Loan, error)
+	GetPendingPickupLoans() ([]*models.Loan, error)
+	GetUserLoans(userID string) ([]*models.Loan, error)
+	GetUserActiveLoans(userID string) ([]*models.Loan, error)
+	GetUserLoanHistory(userID string) ([]*models.Loan, error)
+	// GetBookLoans zwraca wszystkie wypożyczenia książki (także zwrócone)
+	GetBookLoans(bookID string) ([]*models.Loan, error)
+	GetBookActiveLoans(bookID string) ([]*models.Loan, error)
+	GetActiveLoans() ([]*models.Loan, error)
+	GetOverdueLoans() ([]*models.Loan, error)
+	CountActiveLoans() (int, error)
+	CountOverdueLoans() (int, error)
+	// AccrueLoanFine podnosi karę wypożyczenia po terminie do fine i dolicza przyrost
+	// do salda czytelnika jednym zapisem; zwraca przyrost kary
+	AccrueLoanFine(loanID string, fine models.Money) (models.Money, error)
+}
+
+// UserRepository to konta czytelników i personelu
+type UserRepository interface {
+	GetUser(id string) (*models.User, error)
+	// GetUsersByIDs zwraca mapę ID -> użytkownik (usuniętych kont brakuje w mapie)
+	GetUsersByIDs(ids []string) (map[string]*models.User, error)
+	GetUserByFirebaseUID(uid string) (*models.User, error)
+	ListUsers() ([]*models.User, error)
+	CountTotalUsers() (int, error)
+	UpdateUser(id string, user *models.User) error
+	UpdateUserLoansCount(userID string, increment bool) error
+	UpdateUserFines(userID string, amount models.Money) error
+}
+
+// ReservationRepository to rezerwacje
+type ReservationRepository interface {
+	GetReservation(id string) (*models.Reservation, error)
+	CreateReservation(reservation *models.Reservation) error
+	CancelReservation(reservationID string) error
+	GetUserReservations(userID string) ([]*models.Reservation, error)
+	GetUserActiveReservations(userID string) ([]*models.Reservation, error)
+	GetBookReservations(bookID string) ([]*models.Reservation, error)
+	// GetReadyReservations zwraca rezerwacje czekające na odbiór, od najbliższego terminu
+	GetReadyReservations() ([]*models.Reservation, error)
+	// GetNextReservation zwraca pierwszą oczekującą rezerwację w kolejce do książki (nil, gdy brak)
+	GetNextReservation(bookID string) (*models.Reservation, error)
+}
+
+// ReadingListRepository to listy lektur personelu i własne listy czytelników
+type ReadingListRepository interface {
+	CreateReadingList(list *models.ReadingList) error
+	GetReadingList(id string) (*models.ReadingList, error)
+	// GetReadingListBySlug zwraca listę personelu o tym adresie (nil, gdy brak)
+	GetReadingListBySlug(slug string) (*models.ReadingList, error)
+	UpdateReadingList(list *models.ReadingList) error
+	DeleteReadingList(id string) error
+	ListReadingLists() ([]*models.ReadingList, error)
+	GetPublishedReadingLists() ([]*models.ReadingList, error)
+	// CreateUserReadingList zapisuje listę czytelnika z losowym tokenem linku do udostępniania
+	CreateUserReadingList(list *models.ReadingList) error
+	GetUserReadingList(id string) (*models.ReadingList, error)
+	UpdateUserReadingList(list *models.ReadingList) error
+	// RenewShareToken nadaje liście nowy token - dotychczasowy link przestaje działać
+	RenewShareToken(list *models.ReadingList) error
+	DeleteUserReadingList(id string) error
+	GetUserReadingLists(userID string) ([]*models.ReadingList, error)
+	// GetReadingListByShareToken zwraca listę czytelnika po tokenie z linku (nil, gdy brak)
+	GetReadingListByShareToken(token string) (*models.ReadingList, error)
+}
+
+// Accounts to konta, których hasła sprawdza sam backend - w trybie bez bazy danych
+// nie ma Firebase Authentication
+type Accounts interface {
+	// Authenticate zwraca konto o tym adresie email, gdy hasło się zgadza (nil, gdy nie)
+	Authenticate(email, password string) (*models.User, error)
+	// Register zakłada konto z hasłem (błąd, gdy adres email jest zajęty)
+	Register(user *models.User, password string) error
+}
+
+// Store daje repozytoria dla żądania. Kontekst wiąże zapytania z żądaniem
+// (śledzenie, liczniki użycia bazy), nie ogranicza czasu ich wykonania.
+type Store interface {
+	Books(ctx context.Context) BookRepository
+	Loans(ctx context.Context) LoanRepository
+	Users(ctx context.Context) UserRepository
+	Reservations(ctx context.Context) ReservationRepository
+	ReadingLists(ctx context.Context) ReadingListRepository
+}
@@ -0,0 +1,160 @@
+// Package returnrisk ocenia, czy wypożyczenie może zostać zwrócone po terminie.
+// Ocena opiera się na prostych regułach z historii czytelnika (odsetek spóźnionych
+// zwrotów i trwające przeterminowane wypożyczenia), żeby personel mógł przypomnieć
+// o terminie wcześniej niż nocne przypomnienie.
+package returnrisk
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"library-management-system/internal/models"
+)
+
+// Level to poziom ryzyka spóźnionego zwrotu
+type Level int
+
+const (
+	None     Level = iota // Brak oznak ryzyka albo za krótka historia
+	Elevated              // Czytelnik spóźnia się co jakiś czas
+	High                  // Czytelnik spóźnia się często albo ma przeterminowane wypożyczenie
+)
+
+// Label zwraca nazwę poziomu ryzyka wyświetlaną personelowi
+func (l Level) Label() string {
+	switch l {
+	case Elevated:
+		return "Możliwe spóźnienie"
+	case High:
+		return "Duże ryzyko spóźnienia"
+	}
+	return ""
+}
+
+// History to podsumowanie zwrotów czytelnika
+type History struct {
+	Returned int // Zwroty brane pod uwagę (najwyżej Scorer.RecentReturns ostatnich)
+	Late     int // Zwroty po terminie spośród Returned
+	Overdue  int // Trwające wypożyczenia po terminie
+}
+
+// Assessment to ocena ryzyka wypożyczenia z uzasadnieniem dla personelu
+type Assessment struct {
+	Level  Level
+	Reason string
+}
+
+// AtRisk sprawdza czy wypożyczenie warto oznaczyć na liście
+func (a Assessment) AtRisk() bool {
+	return a.Level > None
+}
+
+// IsHigh sprawdza czy ryzyko jest duże (wyróżnienie na liście)
+func (a Assessment) IsHigh() bool {
+	return a.Level == High
+}
+
+// Scorer to progi oceny ryzyka
+type Scorer struct {
+	// MinReturns to najmniejsza liczba zwrotów, od której odsetek spóźnień coś znaczy
+	MinReturns int
+	// RecentReturns ogranicza historię do ostatnich zwrotów - liczy się obecne zachowanie
+	RecentReturns int
+	// ElevatedRate i HighRate to odsetki spóźnionych zwrotów dla poziomów Elevated i High
+	ElevatedRate float64
+	HighRate     float64
+	// Grace to spóźnienie, które nie liczy się jako zwrot po terminie (np. zwrot rano
+	// następnego dnia, gdy biblioteka była zamknięta)
+	Grace time.Duration
+}
+
+// Default to progi używane na liście wypożyczeń personelu
+var Default = Scorer{
+	MinReturns:    3,
+	RecentReturns: 10,
+	ElevatedRate:  0.25,
+	HighRate:      0.5,
+	Grace:         24 * time.Hour,
+}
+
+// Histories podsumowuje historię zwrotów każdego czytelnika z listy wypożyczeń.
+// Udostępnienia na miejscu i zamówienia nieodebrane nie są brane pod uwagę.
+func (s Scorer) Histories(loans []*models.Loan, now time.Time) map[string]*History {
+	returned := make(map[string][]*models.Loan)
+	histories := make(map[string]*History)
+	for _, loan := range loans {
+		if loan.UserID == "" || loan.IsReadingRoom() || loan.Status == models.LoanStatusPendingPickup {
+			continue
+		}
+		h, ok := histories[loan.UserID]
+		if !ok {
+			h = &History{}
+			histories[loan.UserID] = h
+		}
+		switch {
+		case loan.ReturnDate != nil:
+			returned[loan.UserID] = append(returned[loan.UserID], loan)
+		case now.After(loan.DueDate.Add(s.Grace)):
+			h.Overdue++
+		}
+	}
+
+	for userID, list := range returned {
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].ReturnDate.After(*list[j].ReturnDate)
+		})
+		if s.RecentReturns > 0 && len(list) > s.RecentReturns {
+			list = list[:s.RecentReturns]
+		}
+		h := histories[userID]
+		for _, loan := range list {
+			h.Returned++
+			if loan.ReturnDate.After(loan.DueDate.Add(s.Grace)) {
+				h.Late++
+			}
+		}
+	}
+	return histories
+}
+
+// Assess ocenia ryzyko spóźnienia kolejnego zwrotu czytelnika z historią h (może być nil)
+func (s Scorer) Assess(h *History) Assessment {
+	if h == nil {
+		return Assessment{}
+	}
+	if h.Overdue > 0 {
+		return Assessment{Level: High, Reason: fmt.Sprintf("Przeterminowane wypożyczenia czytelnika: %d", h.Overdue)}
+	}
+	if h.Returned < s.MinReturns || h.Late == 0 {
+		return Assessment{}
+	}
+
+	reason := fmt.Sprintf("Zwroty po terminie: %d z %d ostatnich", h.Late, h.Returned)
+	rate := float64(h.Late) / float64(h.Returned)
+	switch {
+	case rate >= s.HighRate:
+		return Assessment{Level: High, Reason: reason}
+	case rate >= s.ElevatedRate:
+		return Assessment{Level: Elevated, Reason: reason}
+	}
+	return Assessment{}
+}
+
+// AssessLoans ocenia trwające wypożyczenia z loans na podstawie historii history
+// (wszystkich wypożyczeń biblioteki). Wypożyczenia już przeterminowane nie są oceniane.
+// Zwraca oceny wypożyczeń zagrożonych spóźnieniem według ID.
+func (s Scorer) AssessLoans(loans, history []*models.Loan, now time.Time) map[string]Assessment {
+	histories := s.Histories(history, now)
+
+	assessments := make(map[string]Assessment)
+	for _, loan := range loans {
+		if !loan.IsBorrowed() || loan.IsReadingRoom() || now.After(loan.DueDate) {
+			continue
+		}
+		if a := s.Assess(histories[loan.UserID]); a.AtRisk() {
+			assessments[loan.ID] = a
+		}
+	}
+	return assessments
+}
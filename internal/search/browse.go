@@ -0,0 +1,187 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"library-management-system/internal/models"
+)
+
+// OtherLetter grupuje autorów, których nazwisko nie zaczyna się od litery
+const OtherLetter = "0-9"
+
+// AuthorCount to autor z liczbą tytułów w katalogu
+type AuthorCount struct {
+	Name  string
+	Count int
+}
+
+// LetterCount to litera alfabetu z liczbą autorów
+type LetterCount struct {
+	Letter string
+	Count  int
+}
+
+// CategoryCount to kategoria z liczbą tytułów w katalogu
+type CategoryCount struct {
+	Name  string
+	Slug  string
+	Count int
+}
+
+// SeriesCount to seria wydawnicza z liczbą tytułów w katalogu
+type SeriesCount struct {
+	Name  string
+	Slug  string
+	Count int
+}
+
+// ClassCount to klasa główna UKD z liczbą tytułów o sygnaturze z tej klasy
+type ClassCount struct {
+	Symbol string
+	Name   string
+	Count  int
+}
+
+// Browse zawiera zestawienia autorów, kategorii, serii i klas UKD wyliczane przy budowie
+// indeksu, dzięki czemu strony przeglądania katalogu nie odpytują bazy o całą kolekcję
+type Browse struct {
+	Letters    []LetterCount
+	Categories []CategoryCount
+	Series     []SeriesCount
+	Classes    []ClassCount
+	authors    map[string][]AuthorCount
+}
+
+// AuthorsByLetter zwraca autorów, których nazwisko zaczyna się od podanej litery
+func (b *Browse) AuthorsByLetter(letter string) []AuthorCount {
+	return b.authors[letter]
+}
+
+// Authors zwraca wszystkich autorów z katalogu w kolejności liter spisu
+func (b *Browse) Authors() []AuthorCount {
+	var authors []AuthorCount
+	for _, letter := range b.Letters {
+		authors = append(authors, b.authors[letter.Letter]...)
+	}
+	return authors
+}
+
+// HasLetter sprawdza czy litera należy do alfabetu przeglądania
+func (b *Browse) HasLetter(letter string) bool {
+	for _, l := range b.Letters {
+		if l.Letter == letter {
+			return true
+		}
+	}
+	return false
+}
+
+// CategoryBySlug wyszukuje kategorię po jej adresie
+func (b *Browse) CategoryBySlug(slug string) (CategoryCount, bool) {
+	for _, c := range b.Categories {
+		if c.Slug == slug {
+			return c, true
+		}
+	}
+	return CategoryCount{}, false
+}
+
+// SeriesBySlug wyszukuje serię po jej adresie
+func (b *Browse) SeriesBySlug(slug string) (SeriesCount, bool) {
+	for _, s := range b.Series {
+		if s.Slug == slug {
+			return s, true
+		}
+	}
+	return SeriesCount{}, false
+}
+
+// AuthorLetter zwraca literę, pod którą autor występuje w spisie - pierwszą literę
+// nazwiska (ostatniego słowa), bez znaków diakrytycznych
+func AuthorLetter(author string) string {
+	fields := strings.Fields(Fold(author))
+	if len(fields) == 0 {
+		return OtherLetter
+	}
+	for _, r := range fields[len(fields)-1] {
+		if r >= 'a' && r <= 'z' {
+			return string(unicode.ToUpper(r))
+		}
+		break
+	}
+	return OtherLetter
+}
+
+// surnameKey zwraca klucz sortowania autora: nazwisko, potem pełne imię i nazwisko
+func surnameKey(author string) string {
+	fields := strings.Fields(Fold(author))
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[len(fields)-1] + " " + strings.Join(fields, " ")
+}
+
+// buildBrowse wylicza zestawienia autorów, kategorii, serii i klas na podstawie książek
+func buildBrowse(books []*models.Book) *Browse {
+	authorCounts := make(map[string]int)
+	categoryCounts := make(map[string]int)
+	classCounts := make(map[string]int)
+	seriesCounts := make(map[string]int)
+
+	for _, book := range books {
+		if book.Author != "" {
+			authorCounts[book.Author]++
+		}
+		if book.Category != "" {
+			categoryCounts[book.Category]++
+		}
+		if book.Series != "" {
+			seriesCounts[book.Series]++
+		}
+		if digits := models.ClassificationDigits(book.CallNumber); digits != "" {
+			classCounts[digits[:1]]++
+		}
+	}
+
+	b := &Browse{authors: make(map[string][]AuthorCount)}
+
+	for author, count := range authorCounts {
+		letter := AuthorLetter(author)
+		b.authors[letter] = append(b.authors[letter], AuthorCount{Name: author, Count: count})
+	}
+	for _, list := range b.authors {
+		sort.Slice(list, func(i, j int) bool {
+			return surnameKey(list[i].Name) < surnameKey(list[j].Name)
+		})
+	}
+
+	for r := 'A'; r <= 'Z'; r++ {
+		letter := string(r)
+		b.Letters = append(b.Letters, LetterCount{Letter: letter, Count: len(b.authors[letter])})
+	}
+	if others := len(b.authors[OtherLetter]); others > 0 {
+		b.Letters = append(b.Letters, LetterCount{Letter: OtherLetter, Count: others})
+	}
+
+	for category, count := range categoryCounts {
+		b.Categories = append(b.Categories, CategoryCount{Name: category, Slug: Slugify(category), Count: count})
+	}
+	sort.Slice(b.Categories, func(i, j int) bool {
+		return Fold(b.Categories[i].Name) < Fold(b.Categories[j].Name)
+	})
+
+	for series, count := range seriesCounts {
+		b.Series = append(b.Series, SeriesCount{Name: series, Slug: Slugify(series), Count: count})
+	}
+	sort.Slice(b.Series, func(i, j int) bool {
+		return Fold(b.Series[i].Name) < Fold(b.Series[j].Name)
+	})
+
+	for _, class := range models.UKDClasses {
+		b.Classes = append(b.Classes, ClassCount{Symbol: class.Symbol, Name: class.Name, Count: classCounts[class.Symbol]})
+	}
+
+	return b
+}
@@ -0,0 +1,127 @@
+package search
+
+import (
+	"strings"
+)
+
+// maxSuggestDistance to maksymalna względna odległość edycyjna podpowiedzi
+// (0.34 = mniej więcej jedna literówka na trzy znaki)
+const maxSuggestDistance = 0.34
+
+// Levenshtein zwraca odległość edycyjną między dwoma tekstami (liczoną w znakach, nie bajtach)
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 {
+		return len(rb)
+	}
+	if len(rb) == 0 {
+		return len(ra)
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// fuzzyDistance zwraca względną odległość zapytania od kandydata (0 = identyczne).
+// Dłuższy kandydat jest porównywany fragmentami o tej samej liczbie słów co zapytanie,
+// więc "sapkowsky" jest blisko "andrzej sapkowski".
+func fuzzyDistance(query, candidate string) float64 {
+	queryWords := strings.Fields(query)
+	candidateWords := strings.Fields(candidate)
+	if len(queryWords) == 0 || len(candidateWords) == 0 {
+		return 1
+	}
+
+	best := 1.0
+	window := min(len(queryWords), len(candidateWords))
+	for start := 0; start+window <= len(candidateWords); start++ {
+		part := strings.Join(candidateWords[start:start+window], " ")
+		longest := max(len([]rune(query)), len([]rune(part)))
+		if d := float64(Levenshtein(query, part)) / float64(longest); d < best {
+			best = d
+		}
+	}
+
+	return best
+}
+
+// maxEdits zwraca dopuszczalną liczbę literówek w słowie zapytania.
+// Krótkich słów nie poprawiamy - "kot" i "lot" to różne słowa.
+func maxEdits(term string) int {
+	switch n := len([]rune(term)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// fuzzyWordMatch sprawdza czy któreś słowo tekstu różni się od słowa zapytania
+// o najwyżej maxEdits znaków
+func fuzzyWordMatch(textWords []string, term string) bool {
+	limit := maxEdits(term)
+	if limit == 0 {
+		return false
+	}
+
+	termLen := len([]rune(term))
+	for _, word := range textWords {
+		wordLen := len([]rune(word))
+		if wordLen-termLen > limit || termLen-wordLen > limit {
+			continue
+		}
+		if Levenshtein(term, word) <= limit {
+			return true
+		}
+	}
+	return false
+}
+
+// Score ocenia dopasowanie znormalizowanego tekstu do słów zapytania (0 = brak dopasowania).
+// Każde słowo musi wystąpić w tekście dokładnie (jako fragment) albo z drobną literówką;
+// dopasowania dokładne i na początku słowa są oceniane wyżej.
+func Score(text string, terms []string) int {
+	var textWords []string
+	score := 0
+
+	for _, term := range terms {
+		pos := strings.Index(text, term)
+		if pos < 0 {
+			if textWords == nil {
+				textWords = strings.Fields(text)
+			}
+			if !fuzzyWordMatch(textWords, term) {
+				return 0
+			}
+			score += 4
+			continue
+		}
+
+		score += 10
+		// Premia za dopasowanie na początku słowa
+		if pos == 0 || text[pos-1] == ' ' {
+			score += 5
+		}
+	}
+
+	return score
+}
@@ -0,0 +1,327 @@
+package search
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"library-management-system/internal/models"
+)
+
+// ResultType określa typ wyniku wyszukiwania
+type ResultType string
+
+const (
+	TypeBook         ResultType = "book"
+	TypeAuthor       ResultType = "author"
+	TypeCategory     ResultType = "category"
+	TypeAnnouncement ResultType = "announcement"
+)
+
+// Result reprezentuje pojedynczy wynik wyszukiwania
+type Result struct {
+	Type     ResultType
+	ID       string // ID książki lub ogłoszenia
+	Title    string
+	Subtitle string
+	URL      string
+	Score    int
+}
+
+// Results zawiera wyniki pogrupowane według typu
+type Results struct {
+	Query         string
+	Books         []Result
+	Authors       []Result
+	Categories    []Result
+	Announcements []Result
+}
+
+// Total zwraca łączną liczbę wyników
+func (r *Results) Total() int {
+	return len(r.Books) + len(r.Authors) + len(r.Categories) + len(r.Announcements)
+}
+
+// entry to pojedynczy wpis w indeksie z przygotowanym tekstem do dopasowania
+type entry struct {
+	result Result
+	text   string
+}
+
+// Loader pobiera dane potrzebne do zbudowania indeksu
+type Loader func() ([]*models.Book, []*models.Announcement, error)
+
+// Index to prosty indeks wyszukiwania w pamięci, przebudowywany okresowo
+type Index struct {
+	mu         sync.RWMutex
+	entries    []entry
+	browse     *Browse
+	builtAt    time.Time
+	generation int // zwiększany przy każdym Invalidate
+	maxAge     time.Duration
+	maxPerType int
+	load       Loader
+
+	// refreshMu zapewnia, że naraz trwa tylko jedna przebudowa
+	refreshMu sync.Mutex
+}
+
+// NewIndex tworzy pusty indeks, który uznaje się za nieaktualny po maxAge.
+// load pobiera dane przy każdej przebudowie.
+func NewIndex(maxAge time.Duration, load Loader) *Index {
+	return &Index{
+		browse:     buildBrowse(nil),
+		maxAge:     maxAge,
+		maxPerType: 10,
+		load:       load,
+	}
+}
+
+// IsStale sprawdza czy indeks wymaga przebudowy
+func (idx *Index) IsStale() bool {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.builtAt.IsZero() || time.Since(idx.builtAt) > idx.maxAge
+}
+
+// Invalidate wymusza przebudowę indeksu przy następnym wyszukiwaniu
+func (idx *Index) Invalidate() {
+	idx.mu.Lock()
+	idx.builtAt = time.Time{}
+	idx.generation++
+	idx.mu.Unlock()
+}
+
+// Refresh przebudowuje indeks, jeśli jest nieaktualny. Równoczesne wywołania
+// czekają na jedną przebudowę zamiast każde osobno pobierać dane z bazy.
+func (idx *Index) Refresh() error {
+	if !idx.IsStale() {
+		return nil
+	}
+
+	idx.refreshMu.Lock()
+	defer idx.refreshMu.Unlock()
+
+	// Indeks mógł zostać przebudowany, gdy czekaliśmy na blokadę
+	if !idx.IsStale() {
+		return nil
+	}
+
+	idx.mu.RLock()
+	generation := idx.generation
+	idx.mu.RUnlock()
+
+	books, announcements, err := idx.load()
+	if err != nil {
+		return err
+	}
+
+	entries := buildEntries(books, announcements)
+	browse := buildBrowse(books)
+
+	idx.mu.Lock()
+	idx.entries = entries
+	idx.browse = browse
+	// Jeśli w trakcie pobierania danych indeks unieważniono, zostaje nieaktualny
+	if idx.generation == generation {
+		idx.builtAt = time.Now()
+	}
+	idx.mu.Unlock()
+
+	return nil
+}
+
+// Build przebudowuje indeks na podstawie książek i ogłoszeń
+func (idx *Index) Build(books []*models.Book, announcements []*models.Announcement) {
+	entries := buildEntries(books, announcements)
+	browse := buildBrowse(books)
+
+	idx.mu.Lock()
+	idx.entries = entries
+	idx.browse = browse
+	idx.builtAt = time.Now()
+	idx.mu.Unlock()
+}
+
+// Browse zwraca zestawienia autorów, kategorii, serii i klas z ostatniej przebudowy indeksu
+func (idx *Index) Browse() *Browse {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.browse
+}
+
+// buildEntries przygotowuje wpisy indeksu dla książek, autorów, kategorii i ogłoszeń
+func buildEntries(books []*models.Book, announcements []*models.Announcement) []entry {
+	var entries []entry
+	authors := make(map[string]int)
+	categories := make(map[string]int)
+
+	for _, book := range books {
+		entries = append(entries, entry{
+			result: Result{
+				Type:     TypeBook,
+				ID:       book.ID,
+				Title:    book.Title,
+				Subtitle: book.Author,
+				URL:      "/books/" + book.ID,
+			},
+			text: Normalize(book.Title + " " + book.Author + " " + book.ISBN + " " + book.CallNumber + " " + book.Series),
+		})
+		if book.Author != "" {
+			authors[book.Author]++
+		}
+		if book.Category != "" {
+			categories[book.Category]++
+		}
+	}
+
+	for author, count := range authors {
+		entries = append(entries, entry{
+			result: Result{
+				Type:     TypeAuthor,
+				Title:    author,
+				Subtitle: pluralBooks(count),
+				URL:      "/books?author=" + url.QueryEscape(author),
+			},
+			text: Normalize(author),
+		})
+	}
+
+	for category, count := range categories {
+		entries = append(entries, entry{
+			result: Result{
+				Type:     TypeCategory,
+				Title:    category,
+				Subtitle: pluralBooks(count),
+				URL:      "/books/categories/" + Slugify(category),
+			},
+			text: Normalize(category),
+		})
+	}
+
+	for _, a := range announcements {
+		if !a.Published {
+			continue
+		}
+		entries = append(entries, entry{
+			result: Result{
+				Type:     TypeAnnouncement,
+				ID:       a.ID,
+				Title:    a.Title,
+				Subtitle: a.CreatedAt.Format("02.01.2006"),
+				URL:      "/announcements/" + a.ID,
+			},
+			text: Normalize(a.Title + " " + a.Body),
+		})
+	}
+
+	return entries
+}
+
+// Search wyszukuje frazę w indeksie i zwraca wyniki pogrupowane według typu
+func (idx *Index) Search(query string) *Results {
+	results := &Results{Query: query}
+
+	terms := Terms(query)
+	if len(terms) == 0 {
+		return results
+	}
+
+	idx.mu.RLock()
+	var matched []Result
+	for _, e := range idx.entries {
+		if score := Score(e.text, terms); score > 0 {
+			r := e.result
+			r.Score = score
+			matched = append(matched, r)
+		}
+	}
+	idx.mu.RUnlock()
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		if matched[i].Score != matched[j].Score {
+			return matched[i].Score > matched[j].Score
+		}
+		return matched[i].Title < matched[j].Title
+	})
+
+	for _, r := range matched {
+		switch r.Type {
+		case TypeBook:
+			results.Books = appendLimited(results.Books, r, idx.maxPerType)
+		case TypeAuthor:
+			results.Authors = appendLimited(results.Authors, r, idx.maxPerType)
+		case TypeCategory:
+			results.Categories = appendLimited(results.Categories, r, idx.maxPerType)
+		case TypeAnnouncement:
+			results.Announcements = appendLimited(results.Announcements, r, idx.maxPerType)
+		}
+	}
+
+	return results
+}
+
+// Suggest zwraca tytuły i autorów podobnych do zapytania ("czy chodziło Ci o...")
+func (idx *Index) Suggest(query string, limit int) []Result {
+	q := Normalize(query)
+	if q == "" {
+		return nil
+	}
+
+	type scored struct {
+		result   Result
+		distance float64
+	}
+
+	var candidates []scored
+	seen := make(map[string]bool)
+
+	idx.mu.RLock()
+	for _, e := range idx.entries {
+		if e.result.Type != TypeBook && e.result.Type != TypeAuthor {
+			continue
+		}
+		title := Normalize(e.result.Title)
+		if seen[title] {
+			continue
+		}
+		if d := fuzzyDistance(q, title); d <= maxSuggestDistance {
+			seen[title] = true
+			candidates = append(candidates, scored{result: e.result, distance: d})
+		}
+	}
+	idx.mu.RUnlock()
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].result.Title < candidates[j].result.Title
+	})
+
+	var suggestions []Result
+	for _, c := range candidates {
+		suggestions = appendLimited(suggestions, c.result, limit)
+	}
+	return suggestions
+}
+
+func appendLimited(list []Result, r Result, limit int) []Result {
+	if len(list) >= limit {
+		return list
+	}
+	return append(list, r)
+}
+
+func pluralBooks(n int) string {
+	switch {
+	case n == 1:
+		return "1 książka"
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 10 || n%100 >= 20):
+		return strconv.Itoa(n) + " książki"
+	default:
+		return strconv.Itoa(n) + " książek"
+	}
+}
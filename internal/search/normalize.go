@@ -0,0 +1,64 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// foldTable zamienia litery ze znakami diakrytycznymi na ich podstawowe odpowiedniki.
+// Obejmuje polskie litery (także ł, która nie rozkłada się w Unicode) i najczęstsze
+// litery z innych języków spotykane w nazwiskach autorów.
+var foldTable = map[rune]rune{
+	'ą': 'a', 'ć': 'c', 'ę': 'e', 'ł': 'l', 'ń': 'n', 'ó': 'o', 'ś': 's', 'ź': 'z', 'ż': 'z',
+	'á': 'a', 'à': 'a', 'â': 'a', 'ä': 'a', 'ã': 'a', 'å': 'a',
+	'é': 'e', 'è': 'e', 'ê': 'e', 'ë': 'e', 'ě': 'e',
+	'í': 'i', 'ì': 'i', 'î': 'i', 'ï': 'i',
+	'ò': 'o', 'ô': 'o', 'ö': 'o', 'õ': 'o', 'ø': 'o', 'ő': 'o',
+	'ú': 'u', 'ù': 'u', 'û': 'u', 'ü': 'u', 'ů': 'u', 'ű': 'u',
+	'ý': 'y', 'ÿ': 'y',
+	'č': 'c', 'ç': 'c', 'ď': 'd', 'ň': 'n', 'ř': 'r', 'š': 's', 'ť': 't', 'ž': 'z',
+}
+
+// Fold sprowadza tekst do małych liter bez znaków diakrytycznych ("Żółć" -> "zolc")
+func Fold(s string) string {
+	return strings.Map(func(r rune) rune {
+		if folded, ok := foldTable[r]; ok {
+			return folded
+		}
+		return r
+	}, strings.ToLower(s))
+}
+
+// Normalize sprowadza tekst do postaci porównywalnej: małe litery bez znaków
+// diakrytycznych i pojedyncze spacje
+func Normalize(s string) string {
+	return strings.Join(strings.Fields(Fold(s)), " ")
+}
+
+// Terms dzieli zapytanie na znormalizowane słowa
+func Terms(query string) []string {
+	return strings.Fields(Normalize(query))
+}
+
+// ContainsFold sprawdza czy s zawiera substr bez względu na wielkość liter i znaki diakrytyczne
+func ContainsFold(s, substr string) bool {
+	return strings.Contains(Normalize(s), Normalize(substr))
+}
+
+// Slugify zamienia tekst na fragment adresu URL ("Literatura piękna" -> "literatura-piekna")
+func Slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range Fold(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,165 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"library-management-system/internal/models"
+)
+
+// Query reprezentuje sparsowane zapytanie z opcjonalnymi filtrami pól,
+// np. `wiedźmin author:"Sapkowski" available:true`
+type Query struct {
+	Terms         []string
+	Title         string
+	Author        string
+	ISBN          string
+	Category      string
+	AvailableOnly bool
+}
+
+// ParseQuery parsuje zapytanie tekstowe z filtrami w postaci pole:wartość
+func ParseQuery(raw string) Query {
+	var q Query
+
+	for _, token := range tokenize(raw) {
+		key, value, found := strings.Cut(token, ":")
+		if !found || value == "" {
+			q.Terms = append(q.Terms, Normalize(token))
+			continue
+		}
+
+		value = strings.Trim(value, `"`)
+		switch strings.ToLower(key) {
+		case "title", "tytul", "tytuł":
+			q.Title = value
+		case "author", "autor":
+			q.Author = value
+		case "isbn":
+			q.ISBN = value
+		case "category", "kategoria":
+			q.Category = value
+		case "available", "dostepna", "dostępna":
+			q.AvailableOnly = value == "true" || value == "tak"
+		default:
+			q.Terms = append(q.Terms, Normalize(token))
+		}
+	}
+
+	return q
+}
+
+// HasFilters sprawdza czy zapytanie zawiera filtry pól
+func (q Query) HasFilters() bool {
+	return q.Title != "" || q.Author != "" || q.ISBN != "" || q.Category != "" || q.AvailableOnly
+}
+
+// IsEmpty sprawdza czy zapytanie nie zawiera żadnych kryteriów
+func (q Query) IsEmpty() bool {
+	return len(q.Terms) == 0 && !q.HasFilters()
+}
+
+// Matches sprawdza czy książka spełnia wszystkie kryteria zapytania
+func (q Query) Matches(book *models.Book) bool {
+	if q.AvailableOnly && !book.IsAvailable() {
+		return false
+	}
+	if q.Title != "" && !ContainsFold(book.Title, q.Title) {
+		return false
+	}
+	if q.Author != "" && !ContainsFold(book.Author, q.Author) {
+		return false
+	}
+	if q.ISBN != "" && !ContainsFold(book.ISBN, q.ISBN) {
+		return false
+	}
+	if q.Category != "" && !ContainsFold(book.Category, q.Category) {
+		return false
+	}
+
+	if len(q.Terms) > 0 {
+		text := Normalize(book.Title + " " + book.Author + " " + book.ISBN + " " + book.Category)
+		return Score(text, q.Terms) > 0
+	}
+
+	return true
+}
+
+// Filter zwraca książki spełniające kryteria zapytania
+func (q Query) Filter(books []*models.Book) []*models.Book {
+	var results []*models.Book
+	for _, book := range books {
+		if q.Matches(book) {
+			results = append(results, book)
+		}
+	}
+	return results
+}
+
+// MatchAll to klucz zapytań bez słów (np. samo available:true), pasujących do każdej książki
+const MatchAll = "*"
+
+// MatchKey zwraca słowo, po którym zapisane wyszukiwanie jest wybierane przy zdarzeniach
+// katalogu. Zapytanie wymaga wszystkich kryteriów, więc wystarczy jedno słowo - wybierane
+// jest najdłuższe jako najbardziej selektywne. Dopasowywane są całe słowa.
+func (q Query) MatchKey() string {
+	key := ""
+	for _, value := range append([]string{q.Title, q.Author, q.ISBN, q.Category}, q.Terms...) {
+		for _, word := range words(value) {
+			if len([]rune(word)) > len([]rune(key)) {
+				key = word
+			}
+		}
+	}
+	if key == "" {
+		return MatchAll
+	}
+	return key
+}
+
+// BookKeys zwraca klucze, po których wyszukiwane są zapisane wyszukiwania pasujące do książki
+func BookKeys(book *models.Book) []string {
+	keys := []string{MatchAll}
+	seen := map[string]bool{MatchAll: true}
+	for _, word := range words(book.Title + " " + book.Author + " " + book.ISBN + " " + book.Category) {
+		if !seen[word] {
+			seen[word] = true
+			keys = append(keys, word)
+		}
+	}
+	return keys
+}
+
+// words dzieli tekst na słowa złożone z liter i cyfr (małymi literami, bez znaków diakrytycznych)
+func words(s string) []string {
+	return strings.FieldsFunc(Fold(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// tokenize dzieli zapytanie na słowa, zachowując frazy w cudzysłowie
+func tokenize(raw string) []string {
+	var tokens []string
+	var current strings.Builder
+	inQuotes := false
+
+	for _, r := range raw {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			current.WriteRune(r)
+		case r == ' ' && !inQuotes:
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+
+	return tokens
+}
@@ -0,0 +1,70 @@
+package search
+
+import (
+	"strings"
+
+	"library-management-system/internal/models"
+)
+
+// WorkKey zwraca klucz utworu łączący różne wydania i zdublowane rekordy tej samej książki:
+// znormalizowany autor i tytuł, więc "Łódź" i "Lodz" dają ten sam klucz
+func WorkKey(book *models.Book) string {
+	return Normalize(book.Author) + "|" + Normalize(book.Title)
+}
+
+// ISBNKey zwraca ISBN w postaci 13 cyfr bez myślników i spacji, także dla ISBN-10,
+// żeby rekordy z tym samym numerem zapisanym różnie trafiały do wspólnej kolejki rezerwacji.
+// Zwraca pusty klucz, gdy numer nie ma 10 ani 13 znaków.
+func ISBNKey(isbn string) string {
+	var digits strings.Builder
+	for _, r := range strings.ToUpper(isbn) {
+		if r >= '0' && r <= '9' || r == 'X' {
+			digits.WriteRune(r)
+		}
+	}
+
+	key := digits.String()
+	switch {
+	case len(key) == 13 && !strings.Contains(key, "X"):
+		return key
+	case len(key) == 10 && !strings.Contains(key[:9], "X"):
+		return isbn13("978" + key[:9])
+	default:
+		return ""
+	}
+}
+
+// isbn13 dopisuje cyfrę kontrolną ISBN-13 do 12 cyfr
+func isbn13(digits string) string {
+	sum := 0
+	for i, r := range digits {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(r-'0') * weight
+	}
+	return digits + string(rune('0'+(10-sum%10)%10))
+}
+
+// SameWork sprawdza czy dwa rekordy opisują ten sam utwór: mają ten sam ISBN
+// albo tego samego autora i tytuł po normalizacji
+func SameWork(a, b *models.Book) bool {
+	if key := ISBNKey(a.ISBN); key != "" && key == ISBNKey(b.ISBN) {
+		return true
+	}
+	return WorkKey(a) == WorkKey(b)
+}
+
+// MatchesHold sprawdza czy egzemplarz książki book może zaspokoić rezerwację r.
+// Rekord z tym samym ISBN to to samo wydanie, więc pasuje także do rezerwacji
+// ograniczonej do wydania; inne wydania utworu pasują tylko do rezerwacji tytułu.
+func MatchesHold(r *models.Reservation, book *models.Book) bool {
+	if r.BookID == book.ID {
+		return true
+	}
+	if key := ISBNKey(book.ISBN); key != "" && r.ISBNKey == key {
+		return true
+	}
+	return !r.EditionOnly && r.WorkKey != "" && r.WorkKey == WorkKey(book)
+}
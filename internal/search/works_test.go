@@ -0,0 +1,105 @@
+package search
+
+import (
+	"testing"
+
+	"library-management-system/internal/models"
+)
+
+func TestISBNKey(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"978-83-240-1234-3", "9788324012343"},
+		{"978 83 240 1234 3", "9788324012343"},
+		{"83-240-1234-X", "9788324012343"},
+		{"0-306-40615-2", "9780306406157"},
+		{"030640615x", "9780306406157"},
+		{"", ""},
+		{"12345", ""},
+		{"X123456789", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := ISBNKey(tt.input); got != tt.want {
+				t.Errorf("ISBNKey(%q) = %q, oczekiwano %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSameWork(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b models.Book
+		want bool
+	}{
+		{
+			"znaki diakrytyczne",
+			models.Book{Author: "Julian Tuwim", Title: "Łódź"},
+			models.Book{Author: "julian  TUWIM", Title: "Lodz"},
+			true,
+		},
+		{
+			"ISBN-10 i ISBN-13",
+			models.Book{ISBN: "0-306-40615-2", Author: "Orwell", Title: "Rok 1984"},
+			models.Book{ISBN: "9780306406157", Author: "George Orwell", Title: "1984"},
+			true,
+		},
+		{
+			"inny utwór",
+			models.Book{ISBN: "9788324012343", Author: "George Orwell", Title: "Rok 1984"},
+			models.Book{ISBN: "9780306406157", Author: "George Orwell", Title: "Folwark zwierzęcy"},
+			false,
+		},
+		{
+			"brak ISBN",
+			models.Book{Author: "George Orwell", Title: "Rok 1984"},
+			models.Book{Author: "Aldous Huxley", Title: "Nowy wspaniały świat"},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SameWork(&tt.a, &tt.b); got != tt.want {
+				t.Errorf("SameWork(%+v, %+v) = %v, oczekiwano %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesHold(t *testing.T) {
+	reserved := &models.Book{ID: "b1", ISBN: "83-240-1234-X", Author: "Julian Tuwim", Title: "Łódź"}
+	duplicate := &models.Book{ID: "b2", ISBN: "978-83-240-1234-3", Author: "J. Tuwim", Title: "Lodz (duży druk)"}
+	otherEdition := &models.Book{ID: "b3", ISBN: "9780306406157", Author: "Julian Tuwim", Title: "Lodz"}
+	otherWork := &models.Book{ID: "b4", ISBN: "9788373271234", Author: "Julian Tuwim", Title: "Lokomotywa"}
+
+	hold := &models.Reservation{BookID: reserved.ID, WorkKey: WorkKey(reserved), ISBNKey: ISBNKey(reserved.ISBN)}
+	editionOnly := *hold
+	editionOnly.EditionOnly = true
+
+	tests := []struct {
+		name string
+		hold *models.Reservation
+		book *models.Book
+		want bool
+	}{
+		{"zarezerwowana książka", hold, reserved, true},
+		{"rekord z tym samym ISBN", hold, duplicate, true},
+		{"inne wydanie", hold, otherEdition, true},
+		{"inny utwór", hold, otherWork, false},
+		{"wydanie: rekord z tym samym ISBN", &editionOnly, duplicate, true},
+		{"wydanie: inne wydanie", &editionOnly, otherEdition, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchesHold(tt.hold, tt.book); got != tt.want {
+				t.Errorf("MatchesHold(%s) = %v, oczekiwano %v", tt.book.ID, got, tt.want)
+			}
+		})
+	}
+}
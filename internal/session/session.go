@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"library-management-system/internal/basepath"
 	"library-management-system/internal/models"
 )
 
@@ -32,6 +33,14 @@ type Manager struct {
 
 var globalManager *Manager
 
+// secureCookies określa, czy cookie sesji mają flagę Secure (wysyłane tylko po HTTPS)
+var secureCookies bool
+
+// SetSecureCookies włącza flagę Secure dla cookie sesji (gdy serwer działa po HTTPS)
+func SetSecureCookies(secure bool) {
+	secureCookies = secure
+}
+
 // Init inicjalizuje globalny manager sesji
 func Init() {
 	globalManager = &Manager{
@@ -97,15 +106,33 @@ func (m *Manager) DeleteSession(sessionID string) {
 	m.mu.Unlock()
 }
 
+// DeleteUserSessions usuwa wszystkie sesje użytkownika (np. po usunięciu jego konta)
+func (m *Manager) DeleteUserSessions(userID string) {
+	m.mu.Lock()
+	for id, session := range m.sessions {
+		if session.UserID == userID {
+			delete(m.sessions, id)
+		}
+	}
+	m.mu.Unlock()
+}
+
+// DeleteAllSessions usuwa wszystkie sesje (np. po resecie danych wersji demonstracyjnej)
+func (m *Manager) DeleteAllSessions() {
+	m.mu.Lock()
+	m.sessions = make(map[string]*Session)
+	m.mu.Unlock()
+}
+
 // SetSessionCookie ustawia cookie z ID sesji
 func SetSessionCookie(w http.ResponseWriter, sessionID string) {
 	http.SetCookie(w, &http.Cookie{
 		Name:     sessionCookieName,
 		Value:    sessionID,
-		Path:     "/",
+		Path:     basepath.CookiePath(),
 		MaxAge:   int(sessionDuration.Seconds()),
 		HttpOnly: true,
-		Secure:   false, // w produkcji ustaw na true (HTTPS)
+		Secure:   secureCookies,
 		SameSite: http.SameSiteLaxMode,
 	})
 }
@@ -115,9 +142,10 @@ func ClearSessionCookie(w http.ResponseWriter) {
 	http.SetCookie(w, &http.Cookie{
 		Name:     sessionCookieName,
 		Value:    "",
-		Path:     "/",
+		Path:     basepath.CookiePath(),
 		MaxAge:   -1,
 		HttpOnly: true,
+		Secure:   secureCookies,
 	})
 }
 
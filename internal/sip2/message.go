@@ -0,0 +1,177 @@
+package sip2
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Kody komunikatów SIP2 obsługiwanych przez serwer (żądania stanowiska i odpowiedzi biblioteki)
+const (
+	codePatronStatus     = "23"
+	codePatronStatusResp = "24"
+	codeCheckout         = "11"
+	codeCheckoutResp     = "12"
+	codeCheckin          = "09"
+	codeCheckinResp      = "10"
+	codeRenew            = "29"
+	codeRenewResp        = "30"
+	codePatronInfo       = "63"
+	codePatronInfoResp   = "64"
+	codeEndSession       = "35"
+	codeEndSessionResp   = "36"
+	codeLogin            = "93"
+	codeLoginResp        = "94"
+	codeSCStatus         = "99"
+	codeACSStatus        = "98"
+	codeResend           = "97" // Stanowisko prosi o powtórzenie ostatniej odpowiedzi
+	codeSCResend         = "96" // Biblioteka prosi o powtórzenie żądania (błędna suma kontrolna)
+)
+
+// fixedLengths to długości części o stałej długości (po kodzie) w żądaniach stanowiska
+var fixedLengths = map[string]int{
+	codePatronStatus: 21, // język(3) data(18)
+	codeCheckout:     38, // polityka przedłużeń(1) bez blokady(1) data(18) termin w trybie offline(18)
+	codeCheckin:      37, // bez blokady(1) data(18) data zwrotu(18)
+	codeRenew:        38, // strona trzecia(1) bez blokady(1) data(18) termin w trybie offline(18)
+	codePatronInfo:   31, // język(3) data(18) podsumowanie(10)
+	codeEndSession:   18, // data(18)
+	codeLogin:        2,  // algorytmy identyfikatora i hasła
+	codeSCStatus:     8,  // stan(1) szerokość wydruku(3) wersja protokołu(4)
+	codeResend:       0,
+}
+
+// trailerPattern to numer sekwencyjny i suma kontrolna na końcu komunikatu (wykrywanie błędów)
+var trailerPattern = regexp.MustCompile(`(?:AY(\d))?AZ([0-9A-Fa-f]{4})$`)
+
+// timeLayout to zapis daty SIP2: RRRRMMDDZZZZGGMMSS (strefa czasu jako spacje = czas lokalny)
+const timeLayout = "20060102    150405"
+
+// message to żądanie stanowiska: kod, część o stałej długości i pola z identyfikatorami
+type message struct {
+	Code     string
+	Fixed    string
+	Fields   map[string]string
+	Sequence string // Numer sekwencyjny AY (pusty, gdy stanowisko nie wykrywa błędów)
+	Checksum bool   // Żądanie miało sumę kontrolną - odpowiedź też ją dostaje
+}
+
+// parseMessage odczytuje żądanie stanowiska (bez końcowego CR). Błędna suma kontrolna
+// zwraca błąd - stanowisko powinno wtedy powtórzyć żądanie.
+func parseMessage(line string) (*message, error) {
+	if len(line) < 2 {
+		return nil, fmt.Errorf("za krótki komunikat")
+	}
+
+	msg := &message{Code: line[:2], Fields: make(map[string]string)}
+	body := line
+	if m := trailerPattern.FindStringSubmatchIndex(line); m != nil {
+		msg.Checksum = true
+		if m[2] >= 0 {
+			msg.Sequence = line[m[2]:m[3]]
+		}
+		want := line[m[4]:m[5]]
+		if !strings.EqualFold(checksum(line[:m[4]]), want) {
+			return nil, fmt.Errorf("błędna suma kontrolna %s", want)
+		}
+		body = line[:m[0]]
+	}
+
+	fixed, ok := fixedLengths[msg.Code]
+	if !ok {
+		return msg, nil
+	}
+	if len(body) < 2+fixed {
+		return nil, fmt.Errorf("za krótki komunikat %s", msg.Code)
+	}
+	msg.Fixed = body[2 : 2+fixed]
+	for _, field := range strings.Split(body[2+fixed:], "|") {
+		if len(field) >= 2 {
+			msg.Fields[field[:2]] = field[2:]
+		}
+	}
+	return msg, nil
+}
+
+// response składa odpowiedź biblioteki
+type response struct {
+	sb strings.Builder
+}
+
+func newResponse(code string, fixed ...string) *response {
+	r := &response{}
+	r.sb.WriteString(code)
+	for _, f := range fixed {
+		r.sb.WriteString(f)
+	}
+	return r
+}
+
+// Field dodaje pole z identyfikatorem (znak "|" w wartości zamieniany jest na spację)
+func (r *response) Field(id, value string) *response {
+	r.sb.WriteString(id)
+	r.sb.WriteString(strings.ReplaceAll(value, "|", " "))
+	r.sb.WriteString("|")
+	return r
+}
+
+// String zwraca odpowiedź zakończoną CR, z numerem sekwencyjnym i sumą kontrolną,
+// jeśli żądanie je miało
+func (r *response) String(req *message) string {
+	s := r.sb.String()
+	if req != nil && req.Checksum {
+		if req.Sequence != "" {
+			s += "AY" + req.Sequence
+		}
+		s += "AZ"
+		s += checksum(s)
+	}
+	return s + "\r"
+}
+
+// checksum liczy sumę kontrolną SIP2: uzupełnienie do dwóch sumy bajtów, 4 cyfry szesnastkowe
+func checksum(s string) string {
+	var sum uint16
+	for i := 0; i < len(s); i++ {
+		sum += uint16(s[i])
+	}
+	return fmt.Sprintf("%04X", -sum)
+}
+
+// flag zwraca znak Y/N
+func flag(v bool) string {
+	if v {
+		return "Y"
+	}
+	return "N"
+}
+
+// okStatus zwraca znak 1/0 pola "ok" odpowiedzi
+func okStatus(v bool) string {
+	if v {
+		return "1"
+	}
+	return "0"
+}
+
+// sipTime zapisuje chwilę w formacie daty SIP2
+func sipTime(t time.Time) string {
+	return t.Format(timeLayout)
+}
+
+// count zapisuje liczbę w polu o stałej długości 4 znaków
+func count(n int) string {
+	if n > 9999 {
+		n = 9999
+	}
+	return fmt.Sprintf("%04d", n)
+}
+
+// number zapisuje liczbę w polu o stałej długości 3 znaków
+func number(n int) string {
+	if n > 999 {
+		n = 999
+	}
+	return fmt.Sprintf("%03d", n)
+}
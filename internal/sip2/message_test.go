@@ -0,0 +1,174 @@
+package sip2
+
+import (
+	"strings"
+	"testing"
+)
+
+// withTrailer dopisuje do żądania numer sekwencyjny i sumę kontrolną, jak robi to stanowisko
+func withTrailer(s, sequence string) string {
+	s += "AY" + sequence + "AZ"
+	return s + checksum(s)
+}
+
+func TestChecksum(t *testing.T) {
+	// Przykład ze specyfikacji SIP2 (zgłoszenie stanu stanowiska)
+	if got := checksum("9900302.00AY1AZ"); got != "FCA5" {
+		t.Errorf("checksum = %s, oczekiwano FCA5", got)
+	}
+}
+
+func TestParseMessage(t *testing.T) {
+	patronStatus := "23000" + "20261016    120000" + "AOBiblioteka|AA0001234|ADtajne|"
+
+	tests := []struct {
+		name     string
+		line     string
+		code     string
+		fixed    string
+		fields   map[string]string
+		sequence string
+		checksum bool
+	}{
+		{
+			name:     "suma kontrolna ze specyfikacji",
+			line:     "9900302.00AY1AZFCA5",
+			code:     codeSCStatus,
+			fixed:    "00302.00",
+			sequence: "1",
+			checksum: true,
+		},
+		{
+			name:     "suma kontrolna małymi literami",
+			line:     "9900302.00AY1AZfca5",
+			code:     codeSCStatus,
+			fixed:    "00302.00",
+			sequence: "1",
+			checksum: true,
+		},
+		{
+			name:   "bez sumy kontrolnej",
+			line:   patronStatus,
+			code:   codePatronStatus,
+			fixed:  "000" + "20261016    120000",
+			fields: map[string]string{"AO": "Biblioteka", "AA": "0001234", "AD": "tajne"},
+		},
+		{
+			name:     "numer sekwencyjny",
+			line:     withTrailer(patronStatus, "7"),
+			code:     codePatronStatus,
+			fixed:    "000" + "20261016    120000",
+			fields:   map[string]string{"AO": "Biblioteka", "AA": "0001234", "AD": "tajne"},
+			sequence: "7",
+			checksum: true,
+		},
+		{
+			name:     "suma kontrolna bez numeru sekwencyjnego",
+			line:     "97AZFEF5",
+			code:     codeResend,
+			checksum: true,
+		},
+		{
+			name:   "logowanie",
+			line:   "9300CNstanowisko-1|COtoken|CPFilia|",
+			code:   codeLogin,
+			fixed:  "00",
+			fields: map[string]string{"CN": "stanowisko-1", "CO": "token", "CP": "Filia"},
+		},
+		{
+			name: "nieznany kod",
+			line: "01Nabc",
+			code: "01",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := parseMessage(tt.line)
+			if err != nil {
+				t.Fatalf("parseMessage(%q) zwrócił błąd: %v", tt.line, err)
+			}
+			if msg.Code != tt.code || msg.Sequence != tt.sequence || msg.Checksum != tt.checksum {
+				t.Errorf("kod %q, sekwencja %q, suma %v; oczekiwano %q, %q, %v",
+					msg.Code, msg.Sequence, msg.Checksum, tt.code, tt.sequence, tt.checksum)
+			}
+			if msg.Fixed != tt.fixed {
+				t.Errorf("część stała %q, oczekiwano %q", msg.Fixed, tt.fixed)
+			}
+			for id, want := range tt.fields {
+				if got := msg.Fields[id]; got != want {
+					t.Errorf("pole %s = %q, oczekiwano %q", id, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseMessageRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"pusty", ""},
+		{"jeden znak", "9"},
+		{"błędna suma kontrolna", "9900302.00AY1AZFCA6"},
+		{"zmieniona treść", "9900302.01AY1AZFCA5"},
+		{"za krótka część stała", "23000"},
+		{"za krótka część stała z sumą", withTrailer("1100", "2")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if msg, err := parseMessage(tt.line); err == nil {
+				t.Errorf("parseMessage(%q) = %+v, oczekiwano błędu", tt.line, msg)
+			}
+		})
+	}
+}
+
+func TestResponseSequence(t *testing.T) {
+	req, err := parseMessage(withTrailer("9900302.00", "4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reply := newResponse(codeEndSessionResp, "Y").Field("AO", "Biblioteka|Filia").String(req)
+	if !strings.HasSuffix(reply, "\r") {
+		t.Fatalf("odpowiedź %q nie kończy się CR", reply)
+	}
+	reply = strings.TrimSuffix(reply, "\r")
+	if !strings.Contains(reply, "AOBiblioteka Filia|AY4AZ") {
+		t.Errorf("odpowiedź %q powinna mieć pole bez znaku | i numer sekwencyjny żądania", reply)
+	}
+	if _, err := parseMessage(reply); err != nil {
+		t.Errorf("suma kontrolna odpowiedzi %q jest błędna: %v", reply, err)
+	}
+
+	if plain := newResponse(codeEndSessionResp, "Y").String(&message{}); plain != codeEndSessionResp+"Y\r" {
+		t.Errorf("odpowiedź na żądanie bez sumy kontrolnej = %q, oczekiwano bez AY/AZ", plain)
+	}
+}
+
+func TestHandleRejectsBeforeLogin(t *testing.T) {
+	s := NewServer(&Config{}, nil)
+	sess := &session{}
+
+	if _, err := s.handle(sess, "23000"+"20261016    120000"+"AOBiblioteka|AA0001234|"); err == nil {
+		t.Error("status czytelnika przed zalogowaniem powinien zakończyć połączenie")
+	}
+
+	// Logowanie bez danych nie loguje stanowiska, ale dostaje odpowiedź
+	reply, err := s.handle(sess, "9300CN|CO|")
+	if err != nil || reply != codeLoginResp+"0\r" {
+		t.Errorf("logowanie bez danych = %q, %v; oczekiwano %q", reply, err, codeLoginResp+"0\r")
+	}
+	if _, err := s.handle(sess, withTrailer("9900302.00", "1")); err == nil {
+		t.Error("zgłoszenie stanu po nieudanym logowaniu powinno zakończyć połączenie")
+	}
+
+	// Błędna suma kontrolna przed zalogowaniem: prośba o powtórzenie zamiast rozłączenia
+	reply, err = s.handle(sess, "9900302.00AY1AZFCA6")
+	if err != nil || reply != codeSCResend+"\r" {
+		t.Errorf("błędna suma kontrolna = %q, %v; oczekiwano %q", reply, err, codeSCResend+"\r")
+	}
+}
@@ -0,0 +1,531 @@
+// Package sip2 udostępnia serwer protokołu SIP2 dla automatów samoobsługowych, które nie
+// korzystają z JSON API. Automat loguje się jako stanowisko samoobsługowe biblioteki
+// (identyfikator stanowiska i jego token z panelu personelu), a następnie sprawdza konta
+// czytelników, wypożycza, przyjmuje zwroty i przedłuża wypożyczenia.
+package sip2
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+)
+
+const (
+	// idleTimeout rozłącza stanowisko, które długo nic nie wysyła
+	idleTimeout = 10 * time.Minute
+	// writeTimeout ogranicza czekanie na odbiór odpowiedzi przez stanowisko
+	writeTimeout = 10 * time.Second
+	// maxMessage ogranicza długość jednego komunikatu
+	maxMessage = 4 << 10
+	// supportedMessages to obsługiwane komunikaty w kolejności pola BX: status czytelnika,
+	// wypożyczenie, zwrot, (blokada), status, powtórzenie, logowanie, informacje o czytelniku,
+	// koniec sesji, (opłata, informacje o egzemplarzu, zmiana egzemplarza, odblokowanie,
+	// rezerwacja), przedłużenie, (przedłużenie wszystkich)
+	supportedMessages = "YYYNYYYYYNNNNNYN"
+)
+
+// Config to ustawienia serwera SIP2
+type Config struct {
+	Addr string // Adres nasłuchiwania (np. ":6001")
+}
+
+// ConfigFromEnv wczytuje konfigurację z SIP2_ADDR. Bez niej serwer jest wyłączony (nil).
+func ConfigFromEnv() *Config {
+	addr := os.Getenv("SIP2_ADDR")
+	if addr == "" {
+		return nil
+	}
+	return &Config{Addr: addr}
+}
+
+// Libraries zwraca klientów wszystkich bibliotek sieci - stanowisko należy do tej,
+// w której zostało dodane
+type Libraries func() ([]*firebase.Client, error)
+
+// Server obsługuje połączenia stanowisk SIP2
+type Server struct {
+	cfg       *Config
+	libraries Libraries
+}
+
+// NewServer tworzy serwer SIP2
+func NewServer(cfg *Config, libraries Libraries) *Server {
+	return &Server{cfg: cfg, libraries: libraries}
+}
+
+// ListenAndServe nasłuchuje połączeń stanowisk i obsługuje każde w osobnej gorutynie
+func (s *Server) ListenAndServe() error {
+	ln, err := net.Listen("tcp", s.cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("błąd uruchamiania serwera SIP2: %w", err)
+	}
+	log.Printf("Serwer SIP2 nasłuchuje na %s", s.cfg.Addr)
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			return err
+		}
+		go s.serve(conn)
+	}
+}
+
+// session to stan połączenia jednego stanowiska
+type session struct {
+	fbClient *firebase.Client
+	station  *models.SelfCheckStation
+	last     string // Ostatnia odpowiedź - do powtórzenia na żądanie stanowiska
+}
+
+// serve czyta komunikaty zakończone CR i odpowiada na nie do rozłączenia
+func (s *Server) serve(conn net.Conn) {
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 512), maxMessage)
+	scanner.Split(scanMessages)
+
+	sess := &session{}
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(idleTimeout)); err != nil {
+			return
+		}
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				log.Printf("SIP2: zakończono połączenie %s: %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		reply, err := s.handle(sess, line)
+		if err != nil {
+			log.Printf("SIP2: rozłączono %s: %v", conn.RemoteAddr(), err)
+			return
+		}
+		if reply == "" {
+			continue
+		}
+
+		if err := conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+			return
+		}
+		if _, err := conn.Write([]byte(reply)); err != nil {
+			log.Printf("SIP2: błąd wysyłania odpowiedzi do %s: %v", conn.RemoteAddr(), err)
+			return
+		}
+	}
+}
+
+// scanMessages dzieli strumień na komunikaty zakończone CR (także CR LF)
+func scanMessages(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	for i, b := range data {
+		if b == '\r' || b == '\n' {
+			return i + 1, data[:i], nil
+		}
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// handle odpowiada na jeden komunikat. Błąd kończy połączenie (komunikat przed zalogowaniem).
+func (s *Server) handle(sess *session, line string) (string, error) {
+	msg, err := parseMessage(line)
+	if err != nil {
+		log.Printf("SIP2: nieprawidłowy komunikat: %v", err)
+		return newResponse(codeSCResend).String(nil), nil
+	}
+
+	if msg.Code == codeResend {
+		return sess.last, nil
+	}
+	if msg.Code == codeLogin {
+		sess.last = s.login(sess, msg)
+		return sess.last, nil
+	}
+	if sess.station == nil {
+		return "", fmt.Errorf("komunikat %s przed zalogowaniem", msg.Code)
+	}
+
+	var reply string
+	switch msg.Code {
+	case codeSCStatus:
+		reply = sess.status(msg)
+	case codePatronStatus:
+		reply = sess.patronStatus(msg)
+	case codePatronInfo:
+		reply = sess.patronInfo(msg)
+	case codeCheckout:
+		reply = sess.checkout(msg)
+	case codeCheckin:
+		reply = sess.checkin(msg)
+	case codeRenew:
+		reply = sess.renew(msg)
+	case codeEndSession:
+		reply = newResponse(codeEndSessionResp, "Y", sipTime(time.Now())).
+			Field("AO", msg.Fields["AO"]).
+			Field("AA", msg.Fields["AA"]).
+			String(msg)
+	default:
+		log.Printf("SIP2: nieobsługiwany komunikat %s ze stanowiska %s", msg.Code, sess.station.Name)
+		return "", nil
+	}
+
+	sess.last = reply
+	return reply, nil
+}
+
+// login loguje stanowisko: CN to identyfikator stanowiska, CO - jego token
+func (s *Server) login(sess *session, msg *message) string {
+	sess.fbClient, sess.station = s.authenticate(msg.Fields["CN"], msg.Fields["CO"])
+	if sess.station != nil {
+		log.Printf("SIP2: zalogowano stanowisko %s (biblioteka %q)", sess.station.Name, sess.fbClient.Tenant())
+	}
+	return newResponse(codeLoginResp, okStatus(sess.station != nil)).String(msg)
+}
+
+// authenticate szuka stanowiska z tym tokenem we wszystkich bibliotekach sieci
+func (s *Server) authenticate(login, password string) (*firebase.Client, *models.SelfCheckStation) {
+	if login == "" || password == "" {
+		return nil, nil
+	}
+
+	clients, err := s.libraries()
+	if err != nil {
+		log.Printf("SIP2: błąd pobierania bibliotek sieci: %v", err)
+		return nil, nil
+	}
+	for _, c := range clients {
+		station, err := c.GetSelfCheckStationByToken(password)
+		if err != nil {
+			log.Printf("SIP2: błąd weryfikacji stanowiska (biblioteka %q): %v", c.Tenant(), err)
+			continue
+		}
+		if station != nil && station.ID == login {
+			return c, station
+		}
+	}
+	return nil, nil
+}
+
+// status odpowiada na zgłoszenie stanu stanowiska opisem możliwości biblioteki
+func (sess *session) status(msg *message) string {
+	name := ""
+	if settings, err := sess.fbClient.GetSettings(); err == nil {
+		name = settings.LibraryName
+	}
+
+	// Online, zwroty, wypożyczenia i przedłużenia dozwolone, bez zmian statusu i pracy offline,
+	// limit czasu 10 s (w dziesiątych częściach sekundy), 3 próby
+	return newResponse(codeACSStatus, "Y", "Y", "Y", "Y", "N", "N", number(100), number(3), sipTime(time.Now()), "2.00").
+		Field("AO", sess.fbClient.Tenant()).
+		Field("AM", name).
+		Field("BX", supportedMessages).
+		Field("AN", sess.station.Name).
+		String(msg)
+}
+
+// patron rozpoznaje czytelnika po numerze karty (AA) i sprawdza PIN (AD), jeśli czytelnik go ustawił.
+// Zwraca nil, gdy karty nie ma w bazie.
+func (sess *session) patron(msg *message) (user *models.User, pinOK bool) {
+	number := strings.TrimSpace(msg.Fields["AA"])
+	if number == "" {
+		return nil, false
+	}
+
+	user, err := sess.fbClient.GetUserByCardNumber(number)
+	if err != nil {
+		log.Printf("SIP2: błąd wyszukiwania czytelnika po karcie: %v", err)
+		return nil, false
+	}
+	if user == nil {
+		return nil, false
+	}
+	if !user.HasPIN() {
+		return user, true
+	}
+
+	verified, err := sess.fbClient.VerifyUserPIN(user.ID, msg.Fields["AD"])
+	if err != nil {
+		log.Printf("SIP2: błąd weryfikacji PIN-u: %v", err)
+	}
+	return user, verified
+}
+
+// patronBlocks zwraca 14 znaków stanu czytelnika (spacja = brak ograniczenia)
+func patronBlocks(user *models.User) string {
+	blocks := []byte(strings.Repeat(" ", 14))
+	if user == nil {
+		return string(blocks)
+	}
+	if !user.CanBorrow() {
+		blocks[0] = 'Y' // Wypożyczanie zablokowane
+	}
+	if !user.IsActive {
+		blocks[1] = 'Y' // Przedłużanie zablokowane
+		blocks[3] = 'Y' // Rezerwowanie zablokowane
+	}
+	if user.AtLoanLimit() {
+		blocks[5] = 'Y' // Za dużo wypożyczonych książek
+	}
+	return string(blocks)
+}
+
+// patronMessage zwraca komunikat dla czytelnika na ekranie stanowiska
+func patronMessage(user *models.User, pinOK bool) string {
+	switch {
+	case user == nil:
+		return "Nie znaleziono karty czytelnika"
+	case !pinOK:
+		return "Nieprawidłowy PIN"
+	}
+	return user.BorrowRefusal()
+}
+
+// patronResponse dodaje pola wspólne dla odpowiedzi o czytelniku
+func patronResponse(r *response, msg *message, user *models.User, pinOK bool) *response {
+	r.Field("AO", msg.Fields["AO"]).Field("AA", msg.Fields["AA"])
+	if user != nil {
+		r.Field("AE", user.FullName())
+	} else {
+		r.Field("AE", "")
+	}
+	r.Field("BL", flag(user != nil)).Field("CQ", flag(user != nil && pinOK))
+	if user != nil && pinOK {
+		r.Field("BV", user.TotalFines.String())
+	}
+	if message := patronMessage(user, pinOK); message != "" {
+		r.Field("AF", message)
+	}
+	return r
+}
+
+// patronStatus odpowiada na zapytanie o stan konta czytelnika
+func (sess *session) patronStatus(msg *message) string {
+	user, pinOK := sess.patron(msg)
+	r := newResponse(codePatronStatusResp, patronBlocks(user), "000", sipTime(time.Now()))
+	return patronResponse(r, msg, user, pinOK).String(msg)
+}
+
+// patronInfo odpowiada na zapytanie o czytelnika liczbą rezerwacji i wypożyczeń
+func (sess *session) patronInfo(msg *message) string {
+	user, pinOK := sess.patron(msg)
+
+	var ready, pending, overdue, charged int
+	if user != nil && pinOK {
+		charged = user.CurrentLoans
+		reservations, err := sess.fbClient.GetUserActiveReservations(user.ID)
+		if err != nil {
+			log.Printf("SIP2: błąd pobierania rezerwacji czytelnika: %v", err)
+		}
+		for _, reservation := range reservations {
+			if reservation.Status == models.ReservationStatusReady {
+				ready++
+			} else {
+				pending++
+			}
+		}
+		loans, err := sess.fbClient.GetUserActiveLoans(user.ID)
+		if err != nil {
+			log.Printf("SIP2: błąd pobierania wypożyczeń czytelnika: %v", err)
+		}
+		for _, loan := range loans {
+			if loan.IsOverdue() {
+				overdue++
+			}
+		}
+	}
+
+	// Rezerwacje gotowe, przeterminowane, wypożyczone, z karami, przywołane, rezerwacje oczekujące
+	r := newResponse(codePatronInfoResp, patronBlocks(user), "000", sipTime(time.Now()),
+		count(ready), count(overdue), count(charged), count(0), count(0), count(pending))
+	if user != nil {
+		r.Field("CB", number(user.MaxLoans))
+	}
+	return patronResponse(r, msg, user, pinOK).String(msg)
+}
+
+// item szuka książki po kodzie z etykiety lub ISBN (AB)
+func (sess *session) item(msg *message) *models.Book {
+	book, err := sess.fbClient.FindBookByCode(msg.Fields["AB"])
+	if err != nil {
+		log.Printf("SIP2: błąd wyszukiwania książki %q: %v", msg.Fields["AB"], err)
+		return nil
+	}
+	return book
+}
+
+// checkout wypożycza książkę czytelnikowi
+func (sess *session) checkout(msg *message) string {
+	user, pinOK := sess.patron(msg)
+	book := sess.item(msg)
+
+	var loan *models.Loan
+	message := patronMessage(user, pinOK)
+	switch {
+	case message != "":
+	case book == nil:
+		message = "Nie rozpoznano książki - zgłoś się do wypożyczalni"
+	default:
+		var err error
+		loan, err = sess.fbClient.SelfCheckout(sess.station, user, book)
+		if err != nil {
+			log.Printf("SIP2: błąd wypożyczenia (książka %s, czytelnik %s): %v", book.ID, user.ID, err)
+			message = "Nie można wypożyczyć tej książki - zgłoś się do wypożyczalni"
+		}
+	}
+
+	ok := loan != nil
+	// Wynik, przedłużenie u stanowiska niedozwolone, nośnik magnetyczny nieznany, odbezpieczenie
+	r := newResponse(codeCheckoutResp, okStatus(ok), "N", "U", flag(ok), sipTime(time.Now())).
+		Field("AO", msg.Fields["AO"]).
+		Field("AA", msg.Fields["AA"]).
+		Field("AB", msg.Fields["AB"])
+	if book != nil {
+		r.Field("AJ", book.Title)
+	} else {
+		r.Field("AJ", "")
+	}
+	if ok {
+		r.Field("AH", sipTime(loan.DueDate))
+	} else {
+		r.Field("AH", "")
+	}
+	if message != "" {
+		r.Field("AF", message)
+	}
+	return r.String(msg)
+}
+
+// checkin przyjmuje zwrot książki. Gdy wypożyczonych jest kilka egzemplarzy tej samej
+// książki, zwrot trzeba przyjąć w wypożyczalni - komunikat zwrotu nie podaje czytelnika.
+func (sess *session) checkin(msg *message) string {
+	book := sess.item(msg)
+
+	var result *models.ReturnResult
+	message := ""
+	if book == nil {
+		message = "Nie rozpoznano książki - zgłoś się do wypożyczalni"
+	} else {
+		loans, err := sess.fbClient.GetBookActiveLoans(book.ID)
+		switch {
+		case err != nil:
+			log.Printf("SIP2: błąd pobierania wypożyczeń książki %s: %v", book.ID, err)
+			message = "Błąd zwrotu - zgłoś się do wypożyczalni"
+		case len(loans) == 0:
+			message = "Ta książka nie jest wypożyczona"
+		case len(loans) > 1:
+			message = "Zwróć tę książkę w wypożyczalni"
+		default:
+			result, err = sess.fbClient.SelfCheckin(sess.station, loans[0].ID)
+			if err != nil {
+				log.Printf("SIP2: błąd zwrotu (wypożyczenie %s): %v", loans[0].ID, err)
+				message = "Błąd zwrotu - zgłoś się do wypożyczalni"
+			}
+		}
+	}
+
+	ok := result != nil
+	hold := ok && result.NextReservation != nil
+	if ok && result.Fine > 0 {
+		if settings, err := sess.fbClient.GetSettings(); err == nil {
+			message = "Naliczono karę za opóźnienie: " + settings.FormatMoney(result.Fine)
+		}
+	}
+
+	location := ""
+	if settings, err := sess.fbClient.GetSettings(); err == nil {
+		location = settings.LibraryName
+	}
+
+	// Wynik, ponowne zabezpieczenie, nośnik magnetyczny nieznany, alert (książka do odłożenia)
+	r := newResponse(codeCheckinResp, okStatus(ok), flag(ok), "U", flag(hold), sipTime(time.Now())).
+		Field("AO", msg.Fields["AO"]).
+		Field("AB", msg.Fields["AB"]).
+		Field("AQ", location)
+	if book != nil {
+		r.Field("AJ", book.Title)
+	}
+	if hold {
+		r.Field("CV", "01") // Rezerwacja w tej bibliotece - książka trafia na półkę odbiorów
+	}
+	if message != "" {
+		r.Field("AF", message)
+	}
+	return r.String(msg)
+}
+
+// renew przedłuża wypożyczenie czytelnika
+func (sess *session) renew(msg *message) string {
+	user, pinOK := sess.patron(msg)
+	book := sess.item(msg)
+
+	var loan *models.Loan
+	message := ""
+	switch {
+	case user == nil || !pinOK:
+		message = patronMessage(user, pinOK)
+	case !user.IsActive:
+		// Przedłużenie jest możliwe także przy pełnym limicie wypożyczeń, ale nie na zablokowanym koncie
+		message = user.BorrowRefusal()
+	case book == nil:
+		message = "Nie rozpoznano książki - zgłoś się do wypożyczalni"
+	default:
+		loans, err := sess.fbClient.GetBookActiveLoans(book.ID)
+		if err != nil {
+			log.Printf("SIP2: błąd pobierania wypożyczeń książki %s: %v", book.ID, err)
+		}
+		var own *models.Loan
+		for _, l := range loans {
+			if l.UserID == user.ID {
+				own = l
+				break
+			}
+		}
+		if own == nil {
+			message = "Nie masz wypożyczonej tej książki"
+			break
+		}
+		loan, err = sess.fbClient.RenewLoan(own.ID, user.ID)
+		if err != nil {
+			message = "Nie można przedłużyć: " + err.Error()
+		}
+	}
+
+	ok := loan != nil
+	r := newResponse(codeRenewResp, okStatus(ok), flag(ok), "U", "U", sipTime(time.Now())).
+		Field("AO", msg.Fields["AO"]).
+		Field("AA", msg.Fields["AA"]).
+		Field("AB", msg.Fields["AB"])
+	if book != nil {
+		r.Field("AJ", book.Title)
+	} else {
+		r.Field("AJ", "")
+	}
+	if ok {
+		r.Field("AH", sipTime(loan.DueDate))
+	} else {
+		r.Field("AH", "")
+	}
+	if message != "" {
+		r.Field("AF", message)
+	}
+	return r.String(msg)
+}
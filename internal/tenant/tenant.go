@@ -0,0 +1,149 @@
+// Package tenant obsługuje sieć bibliotek w jednym wdrożeniu. Biblioteka jest wybierana
+// po nazwie hosta, a każda z nich dostaje własny zestaw handlerów z klientem Firestore
+// ograniczonym do jej danych. Nieznane hosty obsługuje biblioteka główna.
+package tenant
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"library-management-system/internal/firebase"
+	"library-management-system/internal/models"
+)
+
+type contextKey struct{}
+
+// WithTenant zapisuje ID biblioteki w kontekście żądania
+func WithTenant(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext zwraca ID biblioteki obsługującej żądanie (pusty dla biblioteki głównej)
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// BuildFunc tworzy kompletny router aplikacji dla biblioteki sieci
+type BuildFunc func(fbClient *firebase.Client, tenant *models.Tenant) http.Handler
+
+// Router kieruje żądania do handlerów biblioteki przypisanej do hosta
+type Router struct {
+	root  *firebase.Client
+	build BuildFunc
+
+	mu       sync.RWMutex
+	byHost   map[string]*models.Tenant
+	handlers map[string]http.Handler // ID biblioteki -> router (tworzony przy pierwszym żądaniu)
+}
+
+// NewRouter tworzy router sieci bibliotek i wczytuje listę bibliotek
+func NewRouter(root *firebase.Client, build BuildFunc) *Router {
+	r := &Router{
+		root:     root,
+		build:    build,
+		byHost:   make(map[string]*models.Tenant),
+		handlers: make(map[string]http.Handler),
+	}
+	if err := r.Reload(); err != nil {
+		log.Printf("Błąd wczytywania bibliotek sieci: %v", err)
+	}
+	return r
+}
+
+// Reload wczytuje ponownie listę bibliotek (po zmianach w konsoli sieci)
+func (r *Router) Reload() error {
+	tenants, err := r.root.ListTenants()
+	if err != nil {
+		return err
+	}
+
+	byHost := make(map[string]*models.Tenant)
+	for _, t := range tenants {
+		for _, host := range t.Hostnames {
+			byHost[normalizeHost(host)] = t
+		}
+	}
+
+	r.mu.Lock()
+	r.byHost = byHost
+	r.mu.Unlock()
+	return nil
+}
+
+// StartRefresh okresowo wczytuje listę bibliotek (zmiany z innych instancji serwera)
+func (r *Router) StartRefresh(interval time.Duration) {
+	go func() {
+		for range time.Tick(interval) {
+			if err := r.Reload(); err != nil {
+				log.Printf("Błąd odświeżania bibliotek sieci: %v", err)
+			}
+		}
+	}()
+}
+
+// Tenants zwraca liczbę bibliotek przypisanych do hostów (do logów przy starcie)
+func (r *Router) Tenants() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	for _, t := range r.byHost {
+		seen[t.ID] = true
+	}
+	return len(seen)
+}
+
+// Handler zwraca handler kierujący żądania do bibliotek sieci,
+// a żądania z nieznanych hostów do routera biblioteki głównej
+func (r *Router) Handler(rootHandler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.mu.RLock()
+		t := r.byHost[normalizeHost(req.Host)]
+		r.mu.RUnlock()
+
+		if t == nil {
+			rootHandler.ServeHTTP(w, req)
+			return
+		}
+		if !t.Active {
+			http.Error(w, "Biblioteka jest nieaktywna", http.StatusServiceUnavailable)
+			return
+		}
+		r.handlerFor(t).ServeHTTP(w, req.WithContext(WithTenant(req.Context(), t.ID)))
+	})
+}
+
+// handlerFor zwraca router biblioteki, tworząc go przy pierwszym użyciu
+func (r *Router) handlerFor(t *models.Tenant) http.Handler {
+	r.mu.RLock()
+	h, ok := r.handlers[t.ID]
+	r.mu.RUnlock()
+	if ok {
+		return h
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if h, ok := r.handlers[t.ID]; ok {
+		return h
+	}
+
+	log.Printf("Uruchamianie biblioteki sieci: %s (%s)", t.Name, t.ID)
+	h = r.build(r.root.ForTenant(t.ID), t)
+	r.handlers[t.ID] = h
+	return h
+}
+
+// normalizeHost usuwa port i zamienia nazwę hosta na małe litery
+func normalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
@@ -0,0 +1,81 @@
+// Package tracing włącza śledzenie żądań w OpenTelemetry z eksportem OTLP.
+// Każde żądanie HTTP dostaje span nazwany według wzorca trasy chi, a każda operacja
+// klienta Firebase - span potomny (internal/firebase), więc w śladzie wolnej strony
+// widać, które zapytania do Firestore zajęły najwięcej czasu.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"library-management-system/internal/tenant"
+)
+
+// tracerName to nazwa instrumentacji w spanach aplikacji
+const tracerName = "library-management-system"
+
+// Tracer zwraca tracer aplikacji. Bez wywołania Init spany nie są nigdzie wysyłane.
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// Init włącza eksport spanów, gdy ustawiono OTEL_EXPORTER_OTLP_ENDPOINT lub
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT. Eksporter OTLP/HTTP czyta z otoczenia także
+// pozostałe zmienne OTEL_* (nagłówki, próbkowanie przez OTEL_TRACES_SAMPLER),
+// a nazwa usługi pochodzi z OTEL_SERVICE_NAME. Zwraca false, gdy śledzenie jest wyłączone.
+func Init(ctx context.Context) (bool, error) {
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return false, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return false, fmt.Errorf("błąd inicjalizacji eksportera OTLP: %w", err)
+	}
+
+	res := resource.Default()
+	if os.Getenv("OTEL_SERVICE_NAME") == "" {
+		res, err = resource.Merge(res, resource.NewSchemaless(attribute.String("service.name", tracerName)))
+		if err != nil {
+			return false, fmt.Errorf("błąd opisu usługi: %w", err)
+		}
+	}
+
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	))
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return true, nil
+}
+
+// Middleware tworzy span dla każdego żądania (z kontekstem śladu z nagłówka traceparent).
+// Nazwa spanu to metoda i wzorzec trasy chi (np. "GET /books/{id}"), znany dopiero po
+// dopasowaniu trasy, dlatego middleware musi być zarejestrowany w routerze biblioteki.
+func Middleware(next http.Handler) http.Handler {
+	named := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+
+		span := trace.SpanFromContext(r.Context())
+		span.SetAttributes(attribute.String("library.tenant", tenant.FromContext(r.Context())))
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			if pattern := rctx.RoutePattern(); pattern != "" {
+				span.SetName(r.Method + " " + pattern)
+				span.SetAttributes(attribute.String("http.route", pattern))
+			}
+		}
+	})
+	return otelhttp.NewHandler(named, "http.request")
+}
@@ -0,0 +1,268 @@
+// Package webpush wysyła powiadomienia Web Push do przeglądarek czytelników.
+// Treść jest szyfrowana zgodnie z RFC 8291 (aes128gcm), a serwer przedstawia się
+// usłudze push kluczem VAPID (RFC 8292), więc nie potrzeba kont u dostawców przeglądarek.
+package webpush
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/hkdf"
+
+	"library-management-system/internal/models"
+)
+
+// recordSize to rozmiar rekordu aes128gcm - cała wiadomość mieści się w jednym rekordzie
+const recordSize = 4096
+
+// headerSize to nagłówek treści: sól (16), rozmiar rekordu (4), długość klucza (1) i klucz serwera (65)
+const headerSize = 16 + 4 + 1 + 65
+
+// MaxPayload to największa treść, jaką usługi push muszą przyjąć (4096 bajtów razem
+// z nagłówkiem, znacznikiem GCM i bajtem końca rekordu)
+const MaxPayload = recordSize - headerSize - 16 - 1
+
+// ErrGone oznacza, że usługa push nie zna już subskrypcji (czytelnik cofnął zgodę
+// albo subskrypcja wygasła) - trzeba ją usunąć
+var ErrGone = errors.New("subskrypcja push wygasła")
+
+// Config to klucz VAPID serwera
+type Config struct {
+	PublicKey  string // Klucz publiczny (base64url, punkt P-256 bez kompresji) przekazywany przeglądarkom
+	Subject    string // Kontakt dla usług push (mailto: lub https:)
+	privateKey *ecdsa.PrivateKey
+}
+
+// ConfigFromEnv wczytuje klucz z VAPID_PRIVATE_KEY (base64url, 32 bajty - format
+// `web-push generate-vapid-keys`) i kontakt z VAPID_SUBJECT. Klucz publiczny jest
+// wyliczany z prywatnego; jeśli podano VAPID_PUBLIC_KEY, musi do niego pasować.
+// Bez VAPID_PRIVATE_KEY powiadomienia push są wyłączone (nil).
+func ConfigFromEnv() (*Config, error) {
+	encoded := os.Getenv("VAPID_PRIVATE_KEY")
+	if encoded == "" {
+		return nil, nil
+	}
+
+	subject := os.Getenv("VAPID_SUBJECT")
+	if !strings.HasPrefix(subject, "mailto:") && !strings.HasPrefix(subject, "https://") {
+		return nil, fmt.Errorf("VAPID_SUBJECT musi być adresem mailto: lub https:")
+	}
+
+	raw, err := decodeKey(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("nieprawidłowy VAPID_PRIVATE_KEY: %w", err)
+	}
+	key, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("nieprawidłowy VAPID_PRIVATE_KEY: %w", err)
+	}
+
+	point := key.PublicKey().Bytes()
+	publicKey := base64.RawURLEncoding.EncodeToString(point)
+	if expected := os.Getenv("VAPID_PUBLIC_KEY"); expected != "" && strings.TrimRight(expected, "=") != publicKey {
+		return nil, fmt.Errorf("VAPID_PUBLIC_KEY nie pasuje do VAPID_PRIVATE_KEY")
+	}
+
+	return &Config{
+		PublicKey: publicKey,
+		Subject:   subject,
+		privateKey: &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(point[1:33]),
+				Y:     new(big.Int).SetBytes(point[33:]),
+			},
+			D: new(big.Int).SetBytes(raw),
+		},
+	}, nil
+}
+
+// ValidateSubscription sprawdza subskrypcję zgłoszoną przez przeglądarkę
+// (adres usługi push i klucze do szyfrowania treści)
+func ValidateSubscription(sub *models.PushSubscription) error {
+	endpoint, err := url.Parse(sub.Endpoint)
+	if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+		return fmt.Errorf("adres usługi push musi być adresem https")
+	}
+	p256dh, err := decodeKey(sub.P256dh)
+	if err != nil {
+		return fmt.Errorf("nieprawidłowy klucz p256dh: %w", err)
+	}
+	if _, err := ecdh.P256().NewPublicKey(p256dh); err != nil {
+		return fmt.Errorf("nieprawidłowy klucz p256dh: %w", err)
+	}
+	auth, err := decodeKey(sub.Auth)
+	if err != nil || len(auth) != 16 {
+		return fmt.Errorf("nieprawidłowy klucz auth")
+	}
+	return nil
+}
+
+// Sender wysyła powiadomienia do usług push przeglądarek
+type Sender struct {
+	cfg  *Config
+	http *http.Client
+}
+
+// NewSender tworzy nadawcę powiadomień podpisującego żądania kluczem z cfg
+func NewSender(cfg *Config) *Sender {
+	return &Sender{
+		cfg:  cfg,
+		http: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Send szyfruje treść kluczami subskrypcji i przekazuje ją usłudze push.
+// Usługa przechowuje wiadomość najwyżej dobę, jeśli urządzenie jest offline.
+func (s *Sender) Send(sub *models.PushSubscription, payload []byte) error {
+	body, err := encrypt(sub, payload)
+	if err != nil {
+		return err
+	}
+	authorization, err := s.authorization(sub.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("błąd tworzenia żądania push: %w", err)
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", "86400")
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("błąd połączenia z usługą push: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrGone
+	case resp.StatusCode >= 300:
+		return fmt.Errorf("usługa push odpowiedziała statusem %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// authorization buduje nagłówek VAPID: token JWT (ES256) dla adresu usługi push
+// i klucz publiczny serwera
+func (s *Sender) authorization(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("nieprawidłowy adres usługi push: %w", err)
+	}
+
+	header, _ := json.Marshal(map[string]string{"typ": "JWT", "alg": "ES256"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": time.Now().Add(12 * time.Hour).Unix(),
+		"sub": s.cfg.Subject,
+	})
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(unsigned))
+	r, sig, err := ecdsa.Sign(rand.Reader, s.cfg.privateKey, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("błąd podpisywania tokenu VAPID: %w", err)
+	}
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	sig.FillBytes(signature[32:])
+
+	token := unsigned + "." + base64.RawURLEncoding.EncodeToString(signature)
+	return "vapid t=" + token + ", k=" + s.cfg.PublicKey, nil
+}
+
+// encrypt szyfruje treść dla subskrypcji (RFC 8291): klucz wspólny ECDH z jednorazowym
+// kluczem serwera, klucz treści i nonce z HKDF, jeden rekord AES-128-GCM
+func encrypt(sub *models.PushSubscription, payload []byte) ([]byte, error) {
+	if len(payload) > MaxPayload {
+		return nil, fmt.Errorf("treść powiadomienia push jest za długa (%d bajtów)", len(payload))
+	}
+
+	userKey, err := decodeKey(sub.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("nieprawidłowy klucz p256dh: %w", err)
+	}
+	userPublic, err := ecdh.P256().NewPublicKey(userKey)
+	if err != nil {
+		return nil, fmt.Errorf("nieprawidłowy klucz p256dh: %w", err)
+	}
+	authSecret, err := decodeKey(sub.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("nieprawidłowy klucz auth: %w", err)
+	}
+
+	serverKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("błąd generowania klucza: %w", err)
+	}
+	serverPublic := serverKey.PublicKey().Bytes()
+	shared, err := serverKey.ECDH(userPublic)
+	if err != nil {
+		return nil, fmt.Errorf("błąd uzgadniania klucza: %w", err)
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("błąd generowania soli: %w", err)
+	}
+
+	keyInfo := append([]byte("WebPush: info\x00"), userKey...)
+	keyInfo = append(keyInfo, serverPublic...)
+	ikm := derive(authSecret, shared, keyInfo, 32)
+	contentKey := derive(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce := derive(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12)
+
+	block, err := aes.NewCipher(contentKey)
+	if err != nil {
+		return nil, fmt.Errorf("błąd szyfrowania: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("błąd szyfrowania: %w", err)
+	}
+
+	body := make([]byte, 0, headerSize+len(payload)+17)
+	body = append(body, salt...)
+	body = binary.BigEndian.AppendUint32(body, recordSize)
+	body = append(body, byte(len(serverPublic)))
+	body = append(body, serverPublic...)
+	plaintext := append(append([]byte(nil), payload...), 0x02) // 0x02 = ostatni rekord
+	return gcm.Seal(body, nonce, plaintext, nil), nil
+}
+
+func derive(salt, secret, info []byte, length int) []byte {
+	out := make([]byte, length)
+	io.ReadFull(hkdf.New(sha256.New, secret, salt, info), out)
+	return out
+}
+
+// decodeKey dekoduje klucz base64url - przeglądarki pomijają dopełnienie "=", ale narzędzia
+// do generowania kluczy VAPID bywają niekonsekwentne
+func decodeKey(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
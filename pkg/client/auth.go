@@ -0,0 +1,43 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Session to wynik logowania
+type Session struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	User      User      `json:"user"`
+}
+
+// Login loguje użytkownika i zapamiętuje token w kliencie
+func (c *Client) Login(ctx context.Context, email, password string) (*Session, error) {
+	var sess Session
+	body := map[string]string{"email": email, "password": password}
+	if err := c.do(ctx, http.MethodPost, "/auth/token", nil, body, &sess); err != nil {
+		return nil, err
+	}
+	c.token = sess.Token
+	return &sess, nil
+}
+
+// Logout unieważnia token na serwerze i usuwa go z klienta
+func (c *Client) Logout(ctx context.Context) error {
+	if err := c.do(ctx, http.MethodDelete, "/auth/token", nil, nil, nil); err != nil {
+		return err
+	}
+	c.token = ""
+	return nil
+}
+
+// Me zwraca profil zalogowanego użytkownika
+func (c *Client) Me(ctx context.Context) (*User, error) {
+	var user User
+	if err := c.do(ctx, http.MethodGet, "/me", nil, nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
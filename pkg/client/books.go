@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// ListBooks zwraca stronę katalogu
+func (c *Client) ListBooks(ctx context.Context, opts BookListOptions) (*Page[Book], error) {
+	query := opts.values()
+	if opts.Query != "" {
+		query.Set("q", opts.Query)
+	}
+	if opts.Category != "" {
+		query.Set("category", opts.Category)
+	}
+
+	var page Page[Book]
+	if err := c.do(ctx, http.MethodGet, "/books", query, nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// EachBook przechodzi przez wszystkie strony katalogu i wywołuje fn dla każdej książki.
+// Zwrócenie błędu z fn przerywa iterację.
+func (c *Client) EachBook(ctx context.Context, opts BookListOptions, fn func(Book) error) error {
+	if opts.Page < 1 {
+		opts.Page = 1
+	}
+	for {
+		page, err := c.ListBooks(ctx, opts)
+		if err != nil {
+			return err
+		}
+		for _, book := range page.Items {
+			if err := fn(book); err != nil {
+				return err
+			}
+		}
+		if !page.HasNext() || len(page.Items) == 0 {
+			return nil
+		}
+		opts.Page = page.Page + 1
+	}
+}
+
+// GetBook zwraca szczegóły książki
+func (c *Client) GetBook(ctx context.Context, id string) (*Book, error) {
+	var book Book
+	if err := c.do(ctx, http.MethodGet, "/books/"+url.PathEscape(id), nil, nil, &book); err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+// CreateBook dodaje książkę do katalogu (wymaga tokenu personelu)
+func (c *Client) CreateBook(ctx context.Context, input BookInput) (*Book, error) {
+	var book Book
+	if err := c.do(ctx, http.MethodPost, "/books", nil, input, &book); err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+// UpdateBook zastępuje dane książki (wymaga tokenu personelu).
+// Liczby egzemplarzy nie można zmniejszyć - egzemplarze wycofuje się w panelu personelu.
+func (c *Client) UpdateBook(ctx context.Context, id string, input BookInput) (*Book, error) {
+	var book Book
+	if err := c.do(ctx, http.MethodPut, "/books/"+url.PathEscape(id), nil, input, &book); err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+// DeleteBook usuwa książkę bez aktywnych wypożyczeń (wymaga tokenu personelu)
+func (c *Client) DeleteBook(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/books/"+url.PathEscape(id), nil, nil, nil)
+}
@@ -0,0 +1,159 @@
+// Package client to klient Go dla JSON API biblioteki (/api/v1).
+//
+// Przykład użycia:
+//
+//	c := client.New("https://biblioteka.example.com")
+//	if _, err := c.Login(ctx, "czytelnik@example.com", "haslo"); err != nil {
+//		log.Fatal(err)
+//	}
+//	err := c.EachBook(ctx, client.BookListOptions{Query: "sapkowski"}, func(b client.Book) error {
+//		fmt.Println(b.Title)
+//		return nil
+//	})
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client wykonuje żądania do JSON API biblioteki.
+// Po Login (lub SetToken) kolejne żądania są uwierzytelniane tokenem.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	token      string
+}
+
+// Option konfiguruje klienta
+type Option func(*Client)
+
+// WithHTTPClient ustawia własnego klienta HTTP (np. z innym limitem czasu)
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithToken ustawia wcześniej uzyskany token dostępu
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// New tworzy klienta dla serwisu pod adresem baseURL (bez ścieżki /api/v1)
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/") + "/api/v1",
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Token zwraca bieżący token dostępu
+func (c *Client) Token() string {
+	return c.token
+}
+
+// SetToken ustawia token dostępu
+func (c *Client) SetToken(token string) {
+	c.token = token
+}
+
+// Error to błąd zwrócony przez API
+type Error struct {
+	StatusCode int
+	Code       string // Kod błędu z odpowiedzi, np. "not_found", "conflict", "validation_failed"
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("api: %d %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound sprawdza czy błąd oznacza brak zasobu
+func IsNotFound(err error) bool {
+	apiErr, ok := err.(*Error)
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+// IsConflict sprawdza czy operacja jest niemożliwa w obecnym stanie zasobu
+// (np. książka ma aktywne wypożyczenia albo osiągnięto limit przedłużeń)
+func IsConflict(err error) bool {
+	apiErr, ok := err.(*Error)
+	return ok && apiErr.StatusCode == http.StatusConflict
+}
+
+// IsUnauthorized sprawdza czy token jest nieprawidłowy lub wygasł
+// (tokeny wygasają po 24 godzinach i przy restarcie serwera - należy zalogować się ponownie)
+func IsUnauthorized(err error) bool {
+	apiErr, ok := err.(*Error)
+	return ok && apiErr.StatusCode == http.StatusUnauthorized
+}
+
+// do wykonuje żądanie i dekoduje odpowiedź JSON do out (jeśli nie jest nil)
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("błąd kodowania żądania: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return fmt.Errorf("błąd tworzenia żądania: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("błąd wykonania żądania: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		apiErr := &Error{StatusCode: resp.StatusCode, Message: resp.Status}
+		var payload struct {
+			Error string `json:"error"`
+			Code  string `json:"code"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&payload) == nil && payload.Error != "" {
+			apiErr.Message = payload.Error
+			apiErr.Code = payload.Code
+		}
+		return apiErr
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("błąd dekodowania odpowiedzi: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ListLoans zwraca stronę wypożyczeń zalogowanego użytkownika
+func (c *Client) ListLoans(ctx context.Context, opts ListOptions) (*Page[Loan], error) {
+	var page Page[Loan]
+	if err := c.do(ctx, http.MethodGet, "/loans", opts.values(), nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// Borrow zamawia książkę do odbioru; zwrócone wypożyczenie zawiera kod odbioru
+func (c *Client) Borrow(ctx context.Context, bookID string) (*Loan, error) {
+	var loan Loan
+	if err := c.do(ctx, http.MethodPost, "/books/"+url.PathEscape(bookID)+"/loans", nil, nil, &loan); err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+// GetLoan zwraca wypożyczenie
+func (c *Client) GetLoan(ctx context.Context, id string) (*Loan, error) {
+	var loan Loan
+	if err := c.do(ctx, http.MethodGet, "/loans/"+url.PathEscape(id), nil, nil, &loan); err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+// RenewLoan przedłuża termin zwrotu wypożyczenia zalogowanego użytkownika
+func (c *Client) RenewLoan(ctx context.Context, id string) (*Loan, error) {
+	var loan Loan
+	if err := c.do(ctx, http.MethodPost, "/loans/"+url.PathEscape(id)+"/renew", nil, nil, &loan); err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+// ReturnLoan przyjmuje zwrot wypożyczenia (wymaga tokenu personelu)
+func (c *Client) ReturnLoan(ctx context.Context, id string) (*ReturnResult, error) {
+	var result ReturnResult
+	if err := c.do(ctx, http.MethodPost, "/loans/"+url.PathEscape(id)+"/return", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// values zamienia parametry stronicowania na parametry zapytania
+func (o ListOptions) values() url.Values {
+	query := url.Values{}
+	if o.Page > 0 {
+		query.Set("page", strconv.Itoa(o.Page))
+	}
+	if o.PerPage > 0 {
+		query.Set("per_page", strconv.Itoa(o.PerPage))
+	}
+	return query
+}
@@ -0,0 +1,148 @@
+package client
+
+import "time"
+
+// User to profil użytkownika biblioteki
+type User struct {
+	ID           string    `json:"id"`
+	Email        string    `json:"email"`
+	FirstName    string    `json:"first_name"`
+	LastName     string    `json:"last_name"`
+	Role         string    `json:"role"`
+	IsActive     bool      `json:"is_active"`
+	MaxLoans     int       `json:"max_loans"`
+	CurrentLoans int       `json:"current_loans"`
+	TotalFines   float64   `json:"total_fines"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// UserInput to dane nowego konta zakładanego przez administratora
+type UserInput struct {
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Phone     string `json:"phone,omitempty"`
+	Role      string `json:"role,omitempty"` // reader (domyślnie), librarian lub admin
+	MaxLoans  int    `json:"max_loans,omitempty"`
+}
+
+// UserUpdate to zmiana konta przez personel (nil - pole bez zmian)
+type UserUpdate struct {
+	MaxLoans *int  `json:"max_loans,omitempty"`
+	IsActive *bool `json:"is_active,omitempty"`
+}
+
+// Book to pozycja katalogu
+type Book struct {
+	ID              string    `json:"id"`
+	ISBN            string    `json:"isbn"`
+	Title           string    `json:"title"`
+	Author          string    `json:"author"`
+	Publisher       string    `json:"publisher"`
+	PublicationYear int       `json:"publication_year"`
+	Category        string    `json:"category"`
+	Description     string    `json:"description"`
+	TotalCopies     int       `json:"total_copies"`
+	AvailableCopies int       `json:"available_copies"`
+	ShelfLocation   string    `json:"shelf_location"`
+	CoverImageURL   string    `json:"cover_image_url"`
+	ShortCode       string    `json:"short_code,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// BookInput to dane książki przy dodawaniu i edycji przez personel
+type BookInput struct {
+	ISBN            string `json:"isbn"`
+	Title           string `json:"title"`
+	Author          string `json:"author"`
+	Publisher       string `json:"publisher,omitempty"`
+	PublicationYear int    `json:"publication_year,omitempty"`
+	Category        string `json:"category,omitempty"`
+	CallNumber      string `json:"call_number,omitempty"`
+	Series          string `json:"series,omitempty"`
+	SeriesVolume    int    `json:"series_volume,omitempty"`
+	Description     string `json:"description,omitempty"`
+	TotalCopies     int    `json:"total_copies"`
+}
+
+// IsAvailable sprawdza czy książka ma wolne egzemplarze
+func (b Book) IsAvailable() bool {
+	return b.AvailableCopies > 0
+}
+
+// Statusy wypożyczeń
+const (
+	LoanStatusPendingPickup = "pending_pickup"
+	LoanStatusActive        = "active"
+	LoanStatusReturned      = "returned"
+	LoanStatusOverdue       = "overdue"
+)
+
+// Loan to wypożyczenie książki
+type Loan struct {
+	ID         string     `json:"id"`
+	BookID     string     `json:"book_id"`
+	BookTitle  string     `json:"book_title"`
+	PickupCode string     `json:"pickup_code"`
+	Status     string     `json:"status"`
+	LoanDate   time.Time  `json:"loan_date"`
+	DueDate    time.Time  `json:"due_date"`
+	ReturnDate *time.Time `json:"return_date,omitempty"`
+	FineAmount float64    `json:"fine_amount"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ReturnResult to wynik zwrotu przyjętego przez personel
+type ReturnResult struct {
+	Loan            Loan         `json:"loan"`
+	Fine            float64      `json:"fine"`             // Kara naliczona przy zwrocie
+	NextReservation *Reservation `json:"next_reservation"` // Rezerwacja gotowa do odbioru (nil, gdy książka wraca na półkę)
+}
+
+// Statusy rezerwacji
+const (
+	ReservationStatusPending   = "pending"
+	ReservationStatusReady     = "ready"
+	ReservationStatusCompleted = "completed"
+	ReservationStatusCancelled = "cancelled"
+	ReservationStatusExpired   = "expired"
+)
+
+// Reservation to rezerwacja książki
+type Reservation struct {
+	ID              string    `json:"id"`
+	BookID          string    `json:"book_id"`
+	BookTitle       string    `json:"book_title"`
+	Status          string    `json:"status"`
+	ReservationDate time.Time `json:"reservation_date"`
+	ExpiryDate      time.Time `json:"expiry_date"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// Page to strona wyników listy
+type Page[T any] struct {
+	Items   []T `json:"items"`
+	Page    int `json:"page"`
+	PerPage int `json:"per_page"`
+	Total   int `json:"total"`
+}
+
+// HasNext sprawdza czy istnieje kolejna strona wyników
+func (p *Page[T]) HasNext() bool {
+	return p.Page*p.PerPage < p.Total
+}
+
+// ListOptions to parametry stronicowania (zero oznacza wartości domyślne serwera)
+type ListOptions struct {
+	Page    int
+	PerPage int
+}
+
+// BookListOptions to parametry listy książek
+type BookListOptions struct {
+	ListOptions
+	Query    string
+	Category string
+}
@@ -0,0 +1,39 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// ListReservations zwraca stronę rezerwacji zalogowanego użytkownika
+func (c *Client) ListReservations(ctx context.Context, opts ListOptions) (*Page[Reservation], error) {
+	var page Page[Reservation]
+	if err := c.do(ctx, http.MethodGet, "/reservations", opts.values(), nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// GetReservation zwraca rezerwację
+func (c *Client) GetReservation(ctx context.Context, id string) (*Reservation, error) {
+	var reservation Reservation
+	if err := c.do(ctx, http.MethodGet, "/reservations/"+url.PathEscape(id), nil, nil, &reservation); err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// Reserve rezerwuje książkę
+func (c *Client) Reserve(ctx context.Context, bookID string) (*Reservation, error) {
+	var reservation Reservation
+	if err := c.do(ctx, http.MethodPost, "/books/"+url.PathEscape(bookID)+"/reservations", nil, nil, &reservation); err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// CancelReservation anuluje rezerwację
+func (c *Client) CancelReservation(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/reservations/"+url.PathEscape(id), nil, nil, nil)
+}
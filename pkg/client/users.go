@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// ListUsers zwraca stronę kont użytkowników (wymaga tokenu personelu)
+func (c *Client) ListUsers(ctx context.Context, opts ListOptions) (*Page[User], error) {
+	var page Page[User]
+	if err := c.do(ctx, http.MethodGet, "/users", opts.values(), nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// GetUser zwraca konto użytkownika (wymaga tokenu personelu)
+func (c *Client) GetUser(ctx context.Context, id string) (*User, error) {
+	var user User
+	if err := c.do(ctx, http.MethodGet, "/users/"+url.PathEscape(id), nil, nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// CreateUser zakłada konto użytkownika (wymaga tokenu administratora)
+func (c *Client) CreateUser(ctx context.Context, input UserInput) (*User, error) {
+	var user User
+	if err := c.do(ctx, http.MethodPost, "/users", nil, input, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UpdateUser zmienia limit wypożyczeń lub aktywność konta (wymaga tokenu personelu)
+func (c *Client) UpdateUser(ctx context.Context, id string, update UserUpdate) (*User, error) {
+	var user User
+	if err := c.do(ctx, http.MethodPut, "/users/"+url.PathEscape(id), nil, update, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// DeleteUser przenosi konto czytelnika do kosza (wymaga tokenu personelu)
+func (c *Client) DeleteUser(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/users/"+url.PathEscape(id), nil, nil, nil)
+}
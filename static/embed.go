@@ -0,0 +1,9 @@
+// Package static zawiera pliki statyczne (CSS, JS, ikony) wbudowane w plik wykonywalny.
+package static
+
+import "embed"
+
+// Files to wbudowane pliki statyczne. Nowy katalog z plikami trzeba dopisać do dyrektywy go:embed.
+//
+//go:embed css img js
+var Files embed.FS
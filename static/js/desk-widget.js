@@ -0,0 +1,21 @@
+// Panel wypożyczalni na dashboardzie: treść przychodzi z /staff/desk/stream (Server-Sent Events)
+// jako gotowy fragment HTML - od razu po połączeniu i po każdej zmianie wypożyczeń lub rezerwacji.
+(function () {
+    const widget = document.getElementById('desk-widget');
+    if (!widget || !window.EventSource) {
+        return;
+    }
+
+    const status = document.getElementById('desk-widget-status');
+    const source = new EventSource(widget.dataset.stream);
+
+    source.addEventListener('desk', function (event) {
+        widget.innerHTML = event.data;
+        status.classList.add('hidden');
+    });
+
+    // EventSource sam wznawia połączenie - do tego czasu panel pokazuje ostatni stan
+    source.addEventListener('error', function () {
+        status.classList.remove('hidden');
+    });
+})();
@@ -0,0 +1,64 @@
+// Włączanie powiadomień push na stronie /user/push: prosi o zgodę, zapisuje subskrypcję
+// w przeglądarce (przez service worker z pwa.js) i wysyła ją formularzem do serwera.
+(function () {
+    const form = document.getElementById('push-subscribe');
+    if (!form) {
+        return;
+    }
+    const button = form.querySelector('button');
+    const status = form.querySelector('[data-push-status]');
+
+    if (!('serviceWorker' in navigator) || !('PushManager' in window) || !('Notification' in window)) {
+        status.textContent = 'Ta przeglądarka nie obsługuje powiadomień push.';
+        return;
+    }
+    if (Notification.permission === 'denied') {
+        status.textContent = 'Powiadomienia są zablokowane w ustawieniach przeglądarki dla tej strony.';
+        return;
+    }
+
+    // Klucz VAPID serwera (base64url) jako bajty dla pushManager.subscribe
+    function applicationServerKey() {
+        const base64 = form.dataset.key.replace(/-/g, '+').replace(/_/g, '/');
+        const raw = atob(base64 + '='.repeat((4 - base64.length % 4) % 4));
+        return Uint8Array.from(raw, function (c) { return c.charCodeAt(0); });
+    }
+
+    navigator.serviceWorker.ready.then(function (registration) {
+        return registration.pushManager.getSubscription();
+    }).then(function (subscription) {
+        if (subscription) {
+            status.textContent = 'To urządzenie już otrzymuje powiadomienia. Włączenie ich ponownie odświeży subskrypcję.';
+        }
+        button.disabled = false;
+    });
+
+    form.addEventListener('submit', function (event) {
+        if (form.elements.endpoint.value) {
+            return;
+        }
+        event.preventDefault();
+        button.disabled = true;
+
+        Notification.requestPermission().then(function (permission) {
+            if (permission !== 'granted') {
+                throw new Error('Nie udzielono zgody na powiadomienia.');
+            }
+            return navigator.serviceWorker.ready;
+        }).then(function (registration) {
+            return registration.pushManager.subscribe({
+                userVisibleOnly: true,
+                applicationServerKey: applicationServerKey(),
+            });
+        }).then(function (subscription) {
+            const json = subscription.toJSON();
+            form.elements.endpoint.value = json.endpoint;
+            form.elements.p256dh.value = json.keys.p256dh;
+            form.elements.auth.value = json.keys.auth;
+            form.submit();
+        }).catch(function (err) {
+            status.textContent = err.message || 'Nie udało się włączyć powiadomień.';
+            button.disabled = false;
+        });
+    });
+})();
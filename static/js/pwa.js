@@ -0,0 +1,49 @@
+// Aplikacja instalowana (PWA): rejestruje service worker, zapamiętuje ostatnio oglądane
+// książki i zapisuje w pamięci przeglądarki zrzut offline - ostatnio oglądane książki
+// oraz wypożyczenia z kodami odbioru - z którego korzysta strona /offline bez połączenia.
+(function () {
+    const base = document.currentScript.dataset.base; // "/" albo prefiks aplikacji, np. "/biblioteka/"
+    const recentKey = 'offline-recent-books';
+    const maxRecent = 20;
+
+    function recentBooks() {
+        try {
+            return JSON.parse(localStorage.getItem(recentKey)) || [];
+        } catch (e) {
+            return [];
+        }
+    }
+
+    // Wylogowanie usuwa z urządzenia listę oglądanych książek (zrzut usuwa service worker)
+    document.addEventListener('submit', function (event) {
+        if (new URL(event.target.action, location.href).pathname === base + 'logout') {
+            localStorage.removeItem(recentKey);
+        }
+    });
+
+    if (!('serviceWorker' in navigator) || !('caches' in window)) {
+        return;
+    }
+    navigator.serviceWorker.register(base + 'sw.js', { scope: base });
+
+    const book = document.querySelector('meta[name="offline-book"]');
+    if (book) {
+        const ids = recentBooks().filter(function (id) { return id !== book.content; });
+        ids.unshift(book.content);
+        localStorage.setItem(recentKey, JSON.stringify(ids.slice(0, maxRecent)));
+    }
+
+    // Zrzut jest odświeżany na stronie książki i na stronach z kodami odbioru
+    if (book || document.querySelector('meta[name="offline-snapshot"]')) {
+        const url = base + 'offline/snapshot.json?ids=' + encodeURIComponent(recentBooks().join(','));
+        fetch(url, { credentials: 'same-origin' }).then(function (response) {
+            if (response.ok) {
+                return caches.open('offline-data').then(function (cache) {
+                    return cache.put(base + 'offline/snapshot.json', response);
+                });
+            }
+        }).catch(function () {
+            // Bez połączenia zostaje poprzedni zrzut
+        });
+    }
+})();
@@ -0,0 +1,63 @@
+// Układanie wierszy listy (pozycje listy lektur, bloki strony głównej): wiersze przeciąga
+// się myszą albo przesuwa przyciskami ↑ ↓ (klawiatura). Każdy wiersz zawiera ukryte pole,
+// więc formularz wysyła pozycje w kolejności z ekranu. Lista z atrybutem data-sortable
+// może wskazać w nim ID przycisku zapisu, który aktywuje się dopiero po zmianie.
+(function () {
+    const list = document.querySelector('[data-sortable]');
+    if (!list) {
+        return;
+    }
+
+    const save = list.dataset.sortable ? document.getElementById(list.dataset.sortable) : null;
+    let dragged = null;
+
+    function changed() {
+        if (!save) {
+            return;
+        }
+        save.disabled = false;
+        save.classList.remove('opacity-50', 'cursor-not-allowed');
+    }
+
+    list.addEventListener('dragstart', function (event) {
+        dragged = event.target.closest('li');
+        event.dataTransfer.effectAllowed = 'move';
+        dragged.classList.add('opacity-50');
+    });
+
+    list.addEventListener('dragend', function () {
+        dragged.classList.remove('opacity-50');
+        dragged = null;
+    });
+
+    list.addEventListener('dragover', function (event) {
+        const target = event.target.closest('li');
+        if (!dragged || !target || target === dragged) {
+            return;
+        }
+        event.preventDefault();
+
+        // Upuszczenie w dolnej połowie wiersza wstawia pozycję za nim
+        const box = target.getBoundingClientRect();
+        const after = event.clientY > box.top + box.height / 2;
+        list.insertBefore(dragged, after ? target.nextSibling : target);
+        changed();
+    });
+
+    list.addEventListener('click', function (event) {
+        const button = event.target.closest('[data-move]');
+        if (!button) {
+            return;
+        }
+        const row = button.closest('li');
+        if (button.dataset.move === 'up' && row.previousElementSibling) {
+            list.insertBefore(row, row.previousElementSibling);
+        } else if (button.dataset.move === 'down' && row.nextElementSibling) {
+            list.insertBefore(row.nextElementSibling, row);
+        } else {
+            return;
+        }
+        button.focus();
+        changed();
+    });
+})();
@@ -0,0 +1,107 @@
+// Szybkie wyszukiwanie personelu: Ctrl+K (lub "/" poza polami formularzy) otwiera okno,
+// wyniki przychodzą z /staff/search jako fragment HTML.
+(function () {
+    const dialog = document.getElementById('staff-search');
+    if (!dialog) {
+        return;
+    }
+
+    const form = dialog.querySelector('form');
+    const input = form.querySelector('input[name="q"]');
+    const results = document.getElementById('staff-search-results');
+    let timer = null;
+    let controller = null;
+
+    function open() {
+        if (!dialog.open) {
+            dialog.showModal();
+        }
+        input.focus();
+        input.select();
+    }
+
+    function resultLinks() {
+        return Array.from(results.querySelectorAll('a[data-result]'));
+    }
+
+    function load() {
+        const query = input.value.trim();
+        if (controller) {
+            controller.abort();
+        }
+        if (!query) {
+            results.innerHTML = '';
+            return;
+        }
+
+        controller = new AbortController();
+        fetch(form.action + '?q=' + encodeURIComponent(query), {
+            headers: { 'HX-Request': 'true' },
+            signal: controller.signal,
+        })
+            .then(function (response) { return response.text(); })
+            .then(function (html) { results.innerHTML = html; })
+            .catch(function (err) {
+                if (err.name !== 'AbortError') {
+                    results.innerHTML = '<p class="px-4 py-6 text-center text-red-600">Błąd wyszukiwania</p>';
+                }
+            });
+    }
+
+    input.addEventListener('input', function () {
+        clearTimeout(timer);
+        timer = setTimeout(load, 250);
+    });
+
+    // Enter otwiera pierwszy wynik (np. zaraz po zeskanowaniu karty), a gdy wyniki
+    // jeszcze nie dotarły - pełną stronę wyszukiwania
+    form.addEventListener('submit', function (e) {
+        const first = resultLinks()[0];
+        if (first) {
+            e.preventDefault();
+            window.location.href = first.href;
+        }
+    });
+
+    // Kliknięcie w tło zamyka okno
+    dialog.addEventListener('click', function (e) {
+        if (e.target === dialog) {
+            dialog.close();
+        }
+    });
+
+    document.querySelectorAll('[data-staff-search-open]').forEach(function (button) {
+        button.addEventListener('click', open);
+    });
+
+    document.addEventListener('keydown', function (e) {
+        const typing = /^(INPUT|TEXTAREA|SELECT)$/.test(e.target.tagName) || e.target.isContentEditable;
+
+        if ((e.ctrlKey || e.metaKey) && e.key.toLowerCase() === 'k') {
+            e.preventDefault();
+            open();
+            return;
+        }
+        if (!dialog.open) {
+            if (e.key === '/' && !typing) {
+                e.preventDefault();
+                open();
+            }
+            return;
+        }
+
+        const links = resultLinks();
+        const current = links.indexOf(document.activeElement);
+        if (e.key === 'ArrowDown' && links.length) {
+            e.preventDefault();
+            links[Math.min(current + 1, links.length - 1)].focus();
+        } else if (e.key === 'ArrowUp' && links.length) {
+            e.preventDefault();
+            if (current <= 0) {
+                input.focus();
+            } else {
+                links[current - 1].focus();
+            }
+        }
+    });
+})();
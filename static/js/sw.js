@@ -0,0 +1,106 @@
+// Service worker aplikacji instalowanej. Przy instalacji zapisuje stronę offline, a gdy
+// nie ma połączenia, pokazuje ją zamiast błędu przeglądarki. Strona offline wyświetla
+// zrzut (ostatnio oglądane książki i kody odbioru) zapisany przez pwa.js.
+// Wyświetla też powiadomienia push (gotowe rezerwacje, zbliżający się termin zwrotu).
+const SHELL_CACHE = 'offline-shell-v1';
+const DATA_CACHE = 'offline-data';
+const TAILWIND = 'https://cdn.tailwindcss.com';
+
+const scope = new URL(self.registration.scope);
+const offlineURL = new URL('offline', scope).href;
+const logoutPath = new URL('logout', scope).pathname;
+const hashedAsset = /\.[0-9a-f]{10}\.[a-z]+$/; // Nazwy z hashem treści (pakiet assets) nigdy się nie zmieniają
+
+self.addEventListener('install', function (event) {
+    event.waitUntil(caches.open(SHELL_CACHE).then(function (cache) {
+        return Promise.all([
+            // Strona offline bez cookie - nie może zawierać danych zalogowanego czytelnika
+            fetch(offlineURL, { credentials: 'omit' }).then(function (response) {
+                return cache.put(offlineURL, response);
+            }),
+            fetch(TAILWIND, { mode: 'no-cors' }).then(function (response) {
+                return cache.put(TAILWIND, response);
+            }),
+        ]);
+    }).then(function () {
+        return self.skipWaiting();
+    }));
+});
+
+self.addEventListener('activate', function (event) {
+    event.waitUntil(caches.keys().then(function (keys) {
+        return Promise.all(keys.filter(function (key) {
+            return key.startsWith('offline-shell-') && key !== SHELL_CACHE;
+        }).map(function (key) {
+            return caches.delete(key);
+        }));
+    }).then(function () {
+        return self.clients.claim();
+    }));
+});
+
+self.addEventListener('fetch', function (event) {
+    const request = event.request;
+    const url = new URL(request.url);
+
+    // Wylogowanie usuwa z urządzenia zrzut z kodami odbioru
+    if (request.method === 'POST' && url.pathname === logoutPath) {
+        event.waitUntil(caches.delete(DATA_CACHE));
+        return;
+    }
+    if (request.method !== 'GET') {
+        return;
+    }
+
+    // Strony zawsze z sieci, a bez połączenia - strona offline
+    if (request.mode === 'navigate') {
+        event.respondWith(fetch(request).catch(function () {
+            return caches.match(offlineURL);
+        }));
+        return;
+    }
+
+    // Tailwind i pliki statyczne z hashem - z pamięci, a przy pierwszym użyciu z sieci
+    if (request.url === TAILWIND || (url.origin === scope.origin && hashedAsset.test(url.pathname))) {
+        event.respondWith(caches.match(request).then(function (cached) {
+            return cached || fetch(request).then(function (response) {
+                const copy = response.clone();
+                caches.open(SHELL_CACHE).then(function (cache) {
+                    cache.put(request, copy);
+                });
+                return response;
+            });
+        }));
+    }
+});
+
+// Powiadomienie push: {"title", "body", "url"} zaszyfrowane przez serwer (pakiet webpush)
+self.addEventListener('push', function (event) {
+    let message = {};
+    try {
+        message = event.data ? event.data.json() : {};
+    } catch (e) {
+        // Treść nieczytelna - pokaż samo powiadomienie biblioteki
+    }
+    event.waitUntil(self.registration.showNotification(message.title || 'Biblioteka', {
+        body: message.body || '',
+        icon: new URL('static/img/icon.svg', scope).href,
+        data: { url: message.url || scope.href },
+    }));
+});
+
+// Kliknięcie powiadomienia otwiera jego stronę - w istniejącej karcie aplikacji, jeśli jest
+self.addEventListener('notificationclick', function (event) {
+    event.notification.close();
+    const url = event.notification.data.url;
+    event.waitUntil(self.clients.matchAll({ type: 'window' }).then(function (windows) {
+        for (const client of windows) {
+            if ('navigate' in client && client.url.startsWith(scope.href)) {
+                return client.focus().then(function () {
+                    return client.navigate(url);
+                });
+            }
+        }
+        return self.clients.openWindow(url);
+    }));
+});